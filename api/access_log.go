@@ -0,0 +1,88 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+)
+
+// title: set app access log
+// path: /apps/{app}/access-log
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Access log set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appAccessLogSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	config := &router.AccessLogConfig{Enabled: true, SampleRate: 1, Fields: r.Form["field"]}
+	if enabled := r.FormValue("enabled"); enabled != "" {
+		config.Enabled, err = strconv.ParseBool(enabled)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid value for enabled, it must be a boolean"}
+		}
+	}
+	if rate := r.FormValue("sampleRate"); rate != "" {
+		config.SampleRate, err = strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid sample rate, it must be a number"}
+		}
+	}
+	err = config.Validate()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return updateAppAccessLog(r, t, config)
+}
+
+// title: unset app access log
+// path: /apps/{app}/access-log
+// method: DELETE
+// responses:
+//   200: Access log removed
+//   401: Unauthorized
+//   404: App not found
+func appAccessLogUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	return updateAppAccessLog(r, t, nil)
+}
+
+func updateAppAccessLog(r *http.Request, t auth.Token, config *router.AccessLogConfig) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateAccessLog,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateAccessLog,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetAccessLog(config)
+}
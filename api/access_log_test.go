@@ -0,0 +1,80 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppAccessLogSet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-accesslog"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("sampleRate=0.25&field=path&field=status")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/access-log", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	expected := router.AccessLogConfig{Enabled: true, SampleRate: 0.25, Fields: []string{"path", "status"}}
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AccessLog, check.DeepEquals, &expected)
+	c.Assert(routertest.AccessLogRouter.Configs["myapp"], check.DeepEquals, expected)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.access-log",
+		StartCustomData: []map[string]interface{}{
+			{"name": "sampleRate", "value": "0.25"},
+			{"name": "field", "value": []interface{}{"path", "status"}},
+			{"name": ":app", "value": "myapp"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppAccessLogSetInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("field=cookie")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/access-log", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `Invalid access log field "cookie".*\n`)
+}
+
+func (s *S) TestAppAccessLogUnset(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-accesslog"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetAccessLog(&router.AccessLogConfig{Enabled: true, SampleRate: 1})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/access-log", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AccessLog, check.IsNil)
+	c.Assert(routertest.AccessLogRouter.Configs, check.HasLen, 0)
+}
@@ -66,7 +66,7 @@ func getApp(name string) (*app.App, error) {
 // title: remove app
 // path: /apps/{name}
 // method: DELETE
-// produce: application/x-json-stream
+// produce: application/x-json-stream, application/json
 // responses:
 //   200: App removed
 //   401: Unauthorized
@@ -83,6 +83,15 @@ func appDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if !canDelete {
 		return permission.ErrUnauthorized
 	}
+	if dry, _ := strconv.ParseBool(r.FormValue("dry")); dry {
+		var report *provision.DryRunReport
+		report, err = app.DeleteDry(&a)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(report)
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     appTarget(a.Name),
 		Kind:       permission.PermAppDelete,
@@ -205,6 +214,7 @@ func appList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		w.WriteHeader(http.StatusNoContent)
 		return nil
 	}
+	filter.UseReadPreference = true
 	apps, err := app.List(appFilterByContext(contexts, filter))
 	if err != nil {
 		return err
@@ -213,6 +223,10 @@ func appList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		w.WriteHeader(http.StatusNoContent)
 		return nil
 	}
+	err = app.LoadInheritedEnvs(apps)
+	if err != nil {
+		return err
+	}
 	appUnits, err := app.Units(apps)
 	if err != nil {
 		return err
@@ -252,14 +266,17 @@ func appInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 }
 
 type inputApp struct {
-	TeamOwner   string
-	Platform    string
-	Plan        string
-	Name        string
-	Description string
-	Pool        string
-	Router      string
-	RouterOpts  map[string]string
+	TeamOwner     string
+	Platform      string
+	Plan          string
+	Name          string
+	Description   string
+	Pool          string
+	Router        string
+	RouterOpts    map[string]string
+	OnCall        string
+	RunbookURL    string
+	RepositoryURL string
 }
 
 // title: app create
@@ -293,6 +310,11 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		RouterOpts:  ia.RouterOpts,
 		Router:      ia.Router,
 		Tags:        r.Form["tag"],
+		Ownership: appTypes.Ownership{
+			OnCall:        ia.OnCall,
+			RunbookURL:    ia.RunbookURL,
+			RepositoryURL: ia.RepositoryURL,
+		},
 	}
 	if a.TeamOwner == "" {
 		a.TeamOwner, err = permission.TeamForPermission(t, permission.PermAppCreate)
@@ -425,6 +447,11 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		Platform:       r.FormValue("platform"),
 		UpdatePlatform: imageReset,
 		RouterOpts:     ia.RouterOpts,
+		Ownership: appTypes.Ownership{
+			OnCall:        ia.OnCall,
+			RunbookURL:    ia.RunbookURL,
+			RepositoryURL: ia.RepositoryURL,
+		},
 	}
 	appName := r.URL.Query().Get(":appname")
 	a, err := getAppFromContext(appName, r)
@@ -438,6 +465,9 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if updateData.Description != "" {
 		wantedPerms = append(wantedPerms, permission.PermAppUpdateDescription)
 	}
+	if updateData.Ownership != (appTypes.Ownership{}) {
+		wantedPerms = append(wantedPerms, permission.PermAppUpdateOwnership)
+	}
 	if len(updateData.Tags) > 0 {
 		wantedPerms = append(wantedPerms, permission.PermAppUpdateTags)
 	}
@@ -458,7 +488,7 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		wantedPerms = append(wantedPerms, permission.PermAppUpdateImageReset)
 	}
 	if len(wantedPerms) == 0 {
-		msg := "Neither the description, plan, pool, team owner or platform were set. You must define at least one."
+		msg := "Neither the description, ownership, plan, pool, team owner or platform were set. You must define at least one."
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
 	}
 	for _, perm := range wantedPerms {
@@ -488,6 +518,9 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if err == appTypes.ErrPlanNotFound {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 	}
+	if _, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
 	if _, ok := err.(*router.ErrRouterNotFound); ok {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 	}
@@ -554,13 +587,14 @@ func addUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
-	return a.AddUnits(n, processName, writer)
+	evt.SetLogWriter(writer)
+	return a.AddUnits(n, processName, evt)
 }
 
 // title: remove units
 // path: /apps/{name}/units
 // method: DELETE
-// produce: application/x-json-stream
+// produce: application/x-json-stream, application/json
 // responses:
 //   200: Units removed
 //   400: Invalid data
@@ -583,6 +617,15 @@ func removeUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	if dry, _ := strconv.ParseBool(r.FormValue("dry")); dry {
+		var report *provision.DryRunReport
+		report, err = a.RemoveUnitsDry(n, processName)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(report)
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     appTarget(appName),
 		Kind:       permission.PermAppUpdateUnitRemove,
@@ -598,7 +641,8 @@ func removeUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
-	return a.RemoveUnits(n, processName, writer)
+	evt.SetLogWriter(writer)
+	return a.RemoveUnits(n, processName, evt)
 }
 
 // title: set unit status
@@ -777,6 +821,10 @@ func revokeAppAccess(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 	}
 }
 
+// exclusiveRunLockReason prefixes the reason of the app lock held by
+// exclusive runs.
+const exclusiveRunLockReason = "exclusive run"
+
 // title: run commands
 // path: /apps/{app}/run
 // consume: application/x-www-form-urlencoded
@@ -786,6 +834,7 @@ func revokeAppAccess(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 //   200: Ok
 //   401: Unauthorized
 //   404: App not found
+//   409: App locked
 func runCommand(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	msg := "You must provide the command to run"
 	command := r.FormValue("command")
@@ -795,6 +844,7 @@ func runCommand(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	appName := r.URL.Query().Get(":app")
 	once := r.FormValue("once")
 	isolated := r.FormValue("isolated")
+	exclusiveBool, _ := strconv.ParseBool(r.FormValue("exclusive"))
 	a, err := getAppFromContext(appName, r)
 	if err != nil {
 		return err
@@ -805,6 +855,28 @@ func runCommand(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	onceBool, _ := strconv.ParseBool(once)
+	if exclusiveBool {
+		// Exclusive runs hold the app lock until the command completes,
+		// blocking deploys and other run-once commands, as required by
+		// database migrations.
+		onceBool = true
+		owner := t.GetUserName()
+		if t.IsAppToken() {
+			owner = t.GetAppName()
+		}
+		var locked bool
+		locked, err = app.AcquireApplicationLockWait(a.Name, owner, fmt.Sprintf("%s %q", exclusiveRunLockReason, command), lockWaitDuration)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return appLockedError(a.Name)
+		}
+		defer app.ReleaseApplicationLock(a.Name)
+	} else if onceBool && a.Lock.Locked && strings.HasPrefix(a.Lock.Reason, exclusiveRunLockReason) {
+		return &errors.HTTP{Code: http.StatusConflict, Message: a.Lock.String()}
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     appTarget(appName),
 		Kind:       permission.PermAppRun,
@@ -820,12 +892,24 @@ func runCommand(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
-	onceBool, _ := strconv.ParseBool(once)
 	isolatedBool, _ := strconv.ParseBool(isolated)
 	args := provision.RunArgs{Once: onceBool, Isolated: isolatedBool}
 	return a.Run(command, writer, args)
 }
 
+// appLockedError returns the conflict error describing the current lock of
+// the app.
+func appLockedError(appName string) error {
+	a, err := app.GetByName(appName)
+	if err != nil {
+		return err
+	}
+	if !a.Lock.Locked {
+		return &errors.HTTP{Code: http.StatusConflict, Message: "Not locked anymore, please try again."}
+	}
+	return &errors.HTTP{Code: http.StatusConflict, Message: a.Lock.String()}
+}
+
 // title: get envs
 // path: /apps/{app}/env
 // method: GET
@@ -858,14 +942,15 @@ func getEnv(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 func writeEnvVars(w http.ResponseWriter, a *app.App, variables ...string) error {
 	var result []bind.EnvVar
 	w.Header().Set("Content-Type", "application/json")
+	envs := a.Envs()
 	if len(variables) > 0 {
 		for _, variable := range variables {
-			if v, ok := a.Env[variable]; ok {
+			if v, ok := envs[variable]; ok {
 				result = append(result, v)
 			}
 		}
 	} else {
-		for _, v := range a.Envs() {
+		for _, v := range envs {
 			result = append(result, v)
 		}
 	}
@@ -1118,7 +1203,17 @@ func appLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	unit := r.URL.Query().Get("unit")
 	follow := r.URL.Query().Get("follow")
 	appName := r.URL.Query().Get(":app")
-	filterLog := app.Applog{Source: source, Unit: unit}
+	var logTypes []string
+	for _, value := range r.URL.Query()["type"] {
+		for _, logType := range strings.Split(value, ",") {
+			if !app.ValidLogType(logType) {
+				msg := fmt.Sprintf("invalid log type %q, must be one of: %s, %s, %s", logType, app.LogTypeApp, app.LogTypeRouter, app.LogTypeSystem)
+				return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+			}
+			logTypes = append(logTypes, logType)
+		}
+	}
+	filterLog := app.Applog{Source: source, Unit: unit, Type: strings.Join(logTypes, ",")}
 	a, err := getAppFromContext(appName, r)
 	if err != nil {
 		return err
@@ -1129,7 +1224,7 @@ func appLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
-	logs, err := a.LastLogs(lines, filterLog)
+	logs, err := a.LastLogsForRead(lines, filterLog)
 	if err != nil {
 		return err
 	}
@@ -1631,6 +1726,7 @@ func stop(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 //   200: Ok
 //   401: Unauthorized
 //   404: App not found
+//   409: Lock in use by a running operation
 func forceDeleteLock(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	r.ParseForm()
 	appName := r.URL.Query().Get(":app")
@@ -1655,8 +1751,37 @@ func forceDeleteLock(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 		return err
 	}
 	defer func() { evt.Done(err) }()
-	app.ReleaseApplicationLock(a.Name)
-	return nil
+	err = app.ForceReleaseApplicationLock(a.Name, evt.UniqueID.Hex())
+	if _, ok := err.(app.ErrAppLockHeld); ok || err == app.ErrAppLockChanged {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app lock list
+// path: /locks/apps
+// method: GET
+// produce: application/json
+// responses:
+//   200: List locks
+//   204: No content
+//   401: Unauthorized
+func appLockList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	contexts := permission.ContextsForPermission(t, permission.PermAppRead)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	locks, err := app.ListLocks(appFilterByContext(contexts, &app.Filter{}))
+	if err != nil {
+		return err
+	}
+	if len(locks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(locks)
 }
 
 func isDeployAgentUA(r *http.Request) bool {
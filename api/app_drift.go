@@ -0,0 +1,94 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+)
+
+// title: app drift
+// path: /apps/{app}/drift
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   400: Not supported by the provisioner
+//   401: Unauthorized
+//   404: Not found
+func appDrift(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	drifts, err := a.Drift()
+	if err != nil {
+		if _, ok := err.(provision.ProvisionerNotSupported); ok {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	if len(drifts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(drifts)
+}
+
+// title: app drift reconcile
+// path: /apps/{app}/drift/reconcile
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: Ok
+//   400: Not supported by the provisioner
+//   401: Unauthorized
+//   404: Not found
+func appDriftReconcile(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateReconcile, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	drifts, err := a.Drift()
+	if err != nil {
+		if _, ok := err.(provision.ProvisionerNotSupported); ok {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateReconcile,
+		Owner:      t,
+		CustomData: drifts,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	return a.ReconcileDrift(writer)
+}
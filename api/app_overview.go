@@ -0,0 +1,135 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/quota"
+	"github.com/tsuru/tsuru/service"
+)
+
+// appOverview gathers in a single response the app info and the related
+// data usually fetched separately by dashboards. Parts the user isn't
+// allowed to read are omitted and parts that fail to load are reported in
+// Errors, without failing the whole request.
+type appOverview struct {
+	App          json.RawMessage              `json:"app"`
+	Quota        quota.Quota                  `json:"quota"`
+	Services     []appOverviewService         `json:"services"`
+	Certificates map[string]map[string]string `json:"certificates,omitempty"`
+	LastDeploy   *app.DeployData              `json:"lastDeploy,omitempty"`
+	Errors       map[string]string            `json:"errors,omitempty"`
+}
+
+type appOverviewService struct {
+	Service  string `json:"service"`
+	Instance string `json:"instance"`
+	Plan     string `json:"plan,omitempty"`
+}
+
+// title: app overview
+// path: /apps/{app}/overview
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func appOverviewHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	contexts := contextsForApp(&a)
+	if !permission.Check(t, permission.PermAppRead, contexts...) {
+		return permission.ErrUnauthorized
+	}
+	result := appOverview{
+		Quota:    a.GetQuota(),
+		Services: []appOverviewService{},
+	}
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	fetch := func(part string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if result.Errors == nil {
+					result.Errors = map[string]string{}
+				}
+				result.Errors[part] = err.Error()
+			}
+		}()
+	}
+	fetch("app", func() error {
+		data, err := json.Marshal(&a)
+		result.App = data
+		return err
+	})
+	fetch("services", func() error {
+		instances, err := service.GetServiceInstancesBoundToApp(a.Name)
+		if err != nil {
+			return err
+		}
+		services := make([]appOverviewService, 0, len(instances))
+		for i := range instances {
+			si := &instances[i]
+			if !permission.Check(t, permission.PermServiceInstanceRead, contextsForServiceInstance(si, si.ServiceName)...) {
+				continue
+			}
+			services = append(services, appOverviewService{
+				Service:  si.ServiceName,
+				Instance: si.Name,
+				Plan:     si.PlanName,
+			})
+		}
+		sort.Slice(services, func(i, j int) bool {
+			if services[i].Service != services[j].Service {
+				return services[i].Service < services[j].Service
+			}
+			return services[i].Instance < services[j].Instance
+		})
+		result.Services = services
+		return nil
+	})
+	if permission.Check(t, permission.PermAppReadCertificate, contexts...) {
+		fetch("certificates", func() error {
+			certs, err := a.GetCertificates()
+			result.Certificates = certs
+			return err
+		})
+	}
+	if permission.Check(t, permission.PermAppReadDeploy, contexts...) {
+		fetch("lastDeploy", func() error {
+			deploys, err := app.ListDeploys(&app.Filter{Name: a.Name}, 0, 1)
+			if err != nil {
+				return err
+			}
+			if len(deploys) > 0 {
+				result.LastDeploy = &deploys[0]
+			}
+			return nil
+		})
+	}
+	wg.Wait()
+	if result.App == nil {
+		return fmt.Errorf("unable to load app %q: %s", a.Name, result.Errors["app"])
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/service"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createOverviewApp(c *check.C) *app.App {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, CName: []string{"app.io"}, Router: "fake-tls"}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetCertificate("app.io", testCert, testKey)
+	c.Assert(err, check.IsNil)
+	err = s.conn.ServiceInstances().Insert(
+		service.ServiceInstance{Name: "my-mysql", ServiceName: "mysql", PlanName: "small", Teams: []string{s.team.Name}, Apps: []string{a.Name}},
+		service.ServiceInstance{Name: "other-mysql", ServiceName: "mysql", Teams: []string{"otherteam"}, Apps: []string{a.Name}},
+	)
+	c.Assert(err, check.IsNil)
+	insertDeploysAsEvents([]app.DeployData{
+		{App: a.Name, Commit: "abc", Timestamp: time.Now().Add(-time.Hour)},
+		{App: a.Name, Commit: "def", Timestamp: time.Now()},
+	}, c)
+	return &a
+}
+
+func (s *S) TestAppOverview(c *check.C) {
+	s.createOverviewApp(c)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/overview", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result appOverview
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Errors, check.IsNil)
+	var appData map[string]interface{}
+	err = json.Unmarshal(result.App, &appData)
+	c.Assert(err, check.IsNil)
+	c.Assert(appData["name"], check.Equals, "myapp")
+	c.Assert(appData["units"], check.NotNil)
+	c.Assert(result.Services, check.DeepEquals, []appOverviewService{
+		{Service: "mysql", Instance: "my-mysql", Plan: "small"},
+		{Service: "mysql", Instance: "other-mysql"},
+	})
+	c.Assert(result.Certificates["fake-tls"]["app.io"], check.Equals, testCert)
+	c.Assert(result.LastDeploy, check.NotNil)
+	c.Assert(result.LastDeploy.Commit, check.Equals, "def")
+}
+
+func (s *S) TestAppOverviewFiltersByPermission(c *check.C) {
+	s.createOverviewApp(c)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, "myapp"),
+	}, permission.Permission{
+		Scheme:  permission.PermServiceInstanceRead,
+		Context: permission.Context(permission.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/overview", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result appOverview
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Services, check.DeepEquals, []appOverviewService{
+		{Service: "mysql", Instance: "my-mysql", Plan: "small"},
+	})
+	c.Assert(result.Certificates, check.IsNil)
+	c.Assert(result.LastDeploy, check.IsNil)
+}
+
+func (s *S) TestAppOverviewWithoutPermission(c *check.C) {
+	s.createOverviewApp(c)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, "otherapp"),
+	})
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/overview", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -0,0 +1,351 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app/scaling"
+	"github.com/tsuru/tsuru/app/traffic"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: list app scaling schedules
+// path: /apps/{app}/scaling/schedules
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func listScalingSchedules(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	schedules, err := scaling.ListSchedules(a.Name)
+	if err != nil {
+		return err
+	}
+	if len(schedules) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(schedules)
+}
+
+// title: add app scaling schedule
+// path: /apps/{app}/scaling/schedules
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Schedule created
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func addScalingSchedule(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	units, err := strconv.ParseUint(r.FormValue("units"), 10, 32)
+	if err != nil {
+		return &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid number of units: the number must be an integer greater than or equal to 0.",
+		}
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateUnitSchedule,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateUnitSchedule,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	schedule := scaling.Schedule{
+		App:     a.Name,
+		Process: r.FormValue("process"),
+		Cron:    r.FormValue("cron"),
+		Units:   uint(units),
+	}
+	err = scaling.AddSchedule(&schedule)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(schedule)
+}
+
+// title: remove app scaling schedule
+// path: /apps/{app}/scaling/schedules/{id}
+// method: DELETE
+// responses:
+//   200: Schedule removed
+//   401: Unauthorized
+//   404: App or schedule not found
+func removeScalingSchedule(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateUnitSchedule,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateUnitSchedule,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = scaling.RemoveSchedule(a.Name, r.URL.Query().Get(":id"))
+	if err == scaling.ErrScheduleNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: list app autoscale configs
+// path: /apps/{app}/scaling/autoscale
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func listAutoScale(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	configs, err := scaling.ListAutoScale(a.Name)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(configs)
+}
+
+// title: set app autoscale config
+// path: /apps/{app}/scaling/autoscale
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Autoscale set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func setAutoScale(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	minUnits, err := strconv.ParseUint(r.FormValue("minUnits"), 10, 32)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid minUnits value"}
+	}
+	maxUnits, err := strconv.ParseUint(r.FormValue("maxUnits"), 10, 32)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid maxUnits value"}
+	}
+	targetRequests, err := strconv.ParseFloat(r.FormValue("targetRequests"), 64)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid targetRequests value"}
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateUnitAutoscale,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateUnitAutoscale,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = scaling.SetAutoScale(&scaling.AutoScale{
+		App:            a.Name,
+		Process:        r.FormValue("process"),
+		MinUnits:       uint(minUnits),
+		MaxUnits:       uint(maxUnits),
+		TargetRequests: targetRequests,
+	})
+	if err == scaling.ErrProcessRequired || err == scaling.ErrInvalidAutoScale {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: remove app autoscale config
+// path: /apps/{app}/scaling/autoscale/{process}
+// method: DELETE
+// responses:
+//   200: Autoscale removed
+//   401: Unauthorized
+//   404: App or autoscale config not found
+func removeAutoScale(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateUnitAutoscale,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateUnitAutoscale,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = scaling.RemoveAutoScale(a.Name, r.URL.Query().Get(":process"))
+	if err == scaling.ErrAutoScaleNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: set app unit placement
+// path: /apps/{app}/units/placement
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Placement set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func setUnitPlacement(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	minZones, err := strconv.Atoi(r.FormValue("minZones"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid minZones value"}
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateUnitPlacement,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateUnitPlacement,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.SetMinZones(minZones)
+	if _, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app traffic
+// path: /apps/{app}/traffic
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appTraffic(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadMetric,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	window := traffic.DefaultWindow
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		seconds, err := strconv.Atoi(windowStr)
+		if err != nil || seconds <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid window, it must be a positive number of seconds"}
+		}
+		window = time.Duration(seconds) * time.Second
+	}
+	summary, err := traffic.GetSummary(a.Name, window)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,289 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/scaling"
+	"github.com/tsuru/tsuru/app/traffic"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestAddScalingSchedule(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("process=web&cron=0+8+*+*+*&units=10")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/scaling/schedules", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var created scaling.Schedule
+	err = json.NewDecoder(recorder.Body).Decode(&created)
+	c.Assert(err, check.IsNil)
+	c.Assert(created.Units, check.Equals, uint(10))
+	schedules, err := scaling.ListSchedules(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules, check.HasLen, 1)
+	c.Assert(schedules[0].ID, check.Equals, created.ID)
+	c.Assert(schedules[0].Cron, check.Equals, "0 8 * * *")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.unit.schedule",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": a.Name},
+			{"name": "process", "value": "web"},
+			{"name": "cron", "value": "0 8 * * *"},
+			{"name": "units", "value": "10"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAddScalingScheduleInvalidCron(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("process=web&cron=0+25+*+*+*&units=10")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/scaling/schedules", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `invalid cron expression "0 25 \* \* \*".*\n`)
+}
+
+func (s *S) TestAddScalingScheduleForbidden(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, a.Name),
+	})
+	body := strings.NewReader("process=web&cron=0+8+*+*+*&units=10")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/scaling/schedules", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestListScalingSchedules(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/scaling/schedules", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	err = scaling.AddSchedule(&scaling.Schedule{App: a.Name, Process: "web", Cron: "0 22 * * *", Units: 2})
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var schedules []scaling.Schedule
+	err = json.NewDecoder(recorder.Body).Decode(&schedules)
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules, check.HasLen, 1)
+	c.Assert(schedules[0].Units, check.Equals, uint(2))
+}
+
+func (s *S) TestRemoveScalingSchedule(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	sched := scaling.Schedule{App: a.Name, Process: "web", Cron: "0 22 * * *", Units: 2}
+	err = scaling.AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/scaling/schedules/"+sched.ID.Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	schedules, err := scaling.ListSchedules(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules, check.HasLen, 0)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSetAutoScale(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("process=web&minUnits=1&maxUnits=5&targetRequests=10.5")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/scaling/autoscale", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	configs, err := scaling.ListAutoScale(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(configs, check.DeepEquals, []scaling.AutoScale{
+		{App: a.Name, Process: "web", MinUnits: 1, MaxUnits: 5, TargetRequests: 10.5},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.unit.autoscale",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": a.Name},
+			{"name": "process", "value": "web"},
+			{"name": "minUnits", "value": "1"},
+			{"name": "maxUnits", "value": "5"},
+			{"name": "targetRequests", "value": "10.5"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetAutoScaleInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("process=web&minUnits=6&maxUnits=5&targetRequests=10")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/scaling/autoscale", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, scaling.ErrInvalidAutoScale.Error()+"\n")
+}
+
+func (s *S) TestRemoveAutoScale(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = scaling.SetAutoScale(&scaling.AutoScale{App: a.Name, Process: "web", MinUnits: 1, MaxUnits: 5, TargetRequests: 10})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/scaling/autoscale/web", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	configs, err := scaling.ListAutoScale(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(configs, check.HasLen, 0)
+}
+
+func (s *S) TestSetUnitPlacement(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("minZones=2")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/units/placement", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.MinZones, check.Equals, 2)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.unit.placement",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": a.Name},
+			{"name": "minZones", "value": "2"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSetUnitPlacementInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	for _, value := range []string{"two", "-1"} {
+		body := strings.NewReader("minZones=" + value)
+		request, err := http.NewRequest("PUT", "/1.6/apps/myapp/units/placement", body)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	}
+}
+
+func (s *S) TestSetUnitPlacementNoPermission(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, a.Name),
+	})
+	body := strings.NewReader("minZones=2")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/units/placement", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppTraffic(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	err = traffic.AddSample(traffic.Sample{App: a.Name, Router: "fake", Time: now.Add(-time.Minute)})
+	c.Assert(err, check.IsNil)
+	err = traffic.AddSample(traffic.Sample{App: a.Name, Router: "fake", Time: now, Requests: 120, TotalLatency: 12 * time.Second})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/traffic?window=300", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var summary traffic.Summary
+	err = json.NewDecoder(recorder.Body).Decode(&summary)
+	c.Assert(err, check.IsNil)
+	c.Assert(summary.Window, check.Equals, 5*time.Minute)
+	c.Assert(summary.Requests, check.Equals, uint64(120))
+	c.Assert(summary.RequestsPerSecond, check.Equals, float64(2))
+	c.Assert(summary.AvgLatency, check.Equals, 100*time.Millisecond)
+}
+
+func (s *S) TestAppTrafficInvalidWindow(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/traffic?window=abc", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
@@ -699,6 +699,37 @@ func (s *S) TestDelete(c *check.C) {
 	c.Assert(err, check.NotNil)
 }
 
+func (s *S) TestDeleteDry(c *check.C) {
+	myApp := &app.App{
+		Name:      "myapptodelete",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+		CName:     []string{"my.cname.com"},
+	}
+	err := app.CreateApp(myApp, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(myApp, 2, "web", nil)
+	request, err := http.NewRequest("DELETE", "/apps/"+myApp.Name+"?dry=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var report provision.DryRunReport
+	err = json.Unmarshal(recorder.Body.Bytes(), &report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Units, check.HasLen, 2)
+	c.Assert(report.CNames, check.DeepEquals, []string{"my.cname.com"})
+	c.Assert(report.Routers, check.DeepEquals, []string{"fake"})
+	_, err = app.GetByName(myApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.GetUnits(myApp), check.HasLen, 2)
+	evts, err := event.List(&event.Filter{Target: appTarget(myApp.Name)})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 0)
+}
+
 func (s *S) TestDeleteShouldReturnForbiddenIfTheGivenUserDoesNotHaveAccessToTheApp(c *check.C) {
 	myApp := app.App{Name: "app-to-delete", Platform: "zend"}
 	err := s.conn.Apps().Insert(myApp)
@@ -1536,6 +1567,45 @@ func (s *S) TestUpdateAppWithDescriptionOnly(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestUpdateAppWithOwnershipOnly(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateOwnership,
+		Context: permission.Context(permission.CtxApp, a.Name),
+	})
+	b := strings.NewReader("oncall=ops@example.com&runbookURL=https://wiki.example.com/myapp")
+	request, err := http.NewRequest("PUT", "/apps/myapp", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var gotApp app.App
+	err = s.conn.Apps().Find(bson.M{"name": "myapp"}).One(&gotApp)
+	c.Assert(err, check.IsNil)
+	c.Assert(gotApp.Ownership, check.DeepEquals, appTypes.Ownership{
+		OnCall:     "ops@example.com",
+		RunbookURL: "https://wiki.example.com/myapp",
+	})
+}
+
+func (s *S) TestUpdateAppWithOwnershipInvalidURL(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	b := strings.NewReader("repositoryURL=ftp://example.com/myapp")
+	request, err := http.NewRequest("PUT", "/apps/myapp", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Invalid repository URL \\"ftp://example.com/myapp\\".*`)
+}
+
 func (s *S) TestUpdateAppPlatformOnly(c *check.C) {
 	s.setupMockForCreateApp(c, "heimerdinger")
 	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
@@ -1785,7 +1855,7 @@ func (s *S) TestUpdateAppWithoutFlag(c *check.C) {
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	recorder := httptest.NewRecorder()
 	s.testServer.ServeHTTP(recorder, request)
-	errorMessage := "Neither the description, plan, pool, team owner or platform were set. You must define at least one.\n"
+	errorMessage := "Neither the description, ownership, plan, pool, team owner or platform were set. You must define at least one.\n"
 	c.Check(recorder.Code, check.Equals, http.StatusBadRequest)
 	c.Check(recorder.Body.String(), check.Equals, errorMessage)
 }
@@ -2049,6 +2119,31 @@ func (s *S) TestRemoveUnits(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Equals, `{"Message":"removing 2 units"}`+"\n")
 }
 
+func (s *S) TestRemoveUnitsDry(c *check.C) {
+	a := app.App{Name: "velha", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(&a, 3, "web", nil)
+	request, err := http.NewRequest("DELETE", "/apps/velha/units?units=2&process=web&dry=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-type"), check.Equals, "application/json")
+	var report provision.DryRunReport
+	err = json.Unmarshal(recorder.Body.Bytes(), &report)
+	c.Assert(err, check.IsNil)
+	units := s.provisioner.GetUnits(&a)
+	c.Assert(units, check.HasLen, 3)
+	c.Assert(report.Units, check.HasLen, 2)
+	c.Assert(report.Units[0].ID, check.Equals, units[0].ID)
+	c.Assert(report.Units[1].ID, check.Equals, units[1].ID)
+	evts, err := event.List(&event.Filter{Target: appTarget("velha")})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 0)
+}
+
 func (s *S) TestRemoveUnitsReturns404IfAppDoesNotExist(c *check.C) {
 	request, err := http.NewRequest("DELETE", "/apps/fetisha/units?:app=fetisha&units=1&process=web", nil)
 	c.Assert(err, check.IsNil)
@@ -2689,6 +2784,68 @@ func (s *S) TestRunOnce(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestRunExclusive(c *check.C) {
+	s.provisioner.PrepareOutput([]byte("migrated"))
+	a := app.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(&a, 3, "web", nil)
+	url := fmt.Sprintf("/apps/%s/run", a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("command=migrate&exclusive=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, `{"Message":"migrated"}`+"\n")
+	expected := "[ -f /home/application/apprc ] && source /home/application/apprc;"
+	expected += " [ -d /home/application/current ] && cd /home/application/current;"
+	expected += " migrate"
+	cmds := s.provisioner.GetCmds(expected, &a)
+	c.Assert(cmds, check.HasLen, 1)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Lock.Locked, check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.run",
+		StartCustomData: []map[string]interface{}{
+			{"name": "command", "value": "migrate"},
+			{"name": "exclusive", "value": "true"},
+			{"name": ":app", "value": a.Name},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestRunExclusiveAppLocked(c *check.C) {
+	a := app.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(&a, 1, "web", nil)
+	locked, err := app.AcquireApplicationLock(a.Name, "someone", exclusiveRunLockReason+` "migrate"`)
+	c.Assert(err, check.IsNil)
+	c.Assert(locked, check.Equals, true)
+	oldDuration := lockWaitDuration
+	lockWaitDuration = 0
+	defer func() { lockWaitDuration = oldDuration }()
+	for _, body := range []string{"command=ls&exclusive=true", "command=ls&once=true"} {
+		request, err := http.NewRequest("POST", "/apps/secrets/run", strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "b "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+		c.Assert(recorder.Body.String(), check.Matches, `App locked by someone, running exclusive run "migrate".*\n`)
+	}
+	c.Assert(s.provisioner.GetCmds("", &a), check.HasLen, 0)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Lock.Locked, check.Equals, true)
+}
+
 func (s *S) TestRun(c *check.C) {
 	s.provisioner.PrepareOutput([]byte("lots of\nfiles"))
 	a := app.App{Name: "secrets", Platform: "zend", TeamOwner: s.team.Name}
@@ -2937,6 +3094,39 @@ func (s *S) TestGetEnvMultipleVariables(c *check.C) {
 	c.Assert(got, check.DeepEquals, expected)
 }
 
+func (s *S) TestGetEnvWithPoolEnvs(c *check.C) {
+	err := pool.SetEnvs(s.Pool, []bind.EnvVar{
+		{Name: "REGION", Value: "us-east", Public: true},
+		{Name: "DATABASE_HOST", Value: "pool-db", Public: true},
+	})
+	c.Assert(err, check.IsNil)
+	a := app.App{
+		Name:      "pool-envs",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+		Env: map[string]bind.EnvVar{
+			"DATABASE_HOST": {Name: "DATABASE_HOST", Value: "localhost", Public: true},
+		},
+	}
+	err = app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/env?env=DATABASE_HOST&env=REGION", a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	expected := []map[string]interface{}{
+		{"name": "DATABASE_HOST", "value": "localhost", "public": true},
+		{"name": "REGION", "value": "us-east", "public": true},
+	}
+	var got []map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	c.Assert(err, check.IsNil)
+	c.Assert(got, check.DeepEquals, expected)
+}
+
 func (s *S) TestGetEnvAppDoesNotExist(c *check.C) {
 	request, err := http.NewRequest("GET", "/apps/unknown/env", nil)
 	c.Assert(err, check.IsNil)
@@ -4057,6 +4247,46 @@ func (s *S) TestAppLogSelectBySource(c *check.C) {
 	c.Assert(logs[0].Source, check.Equals, "mars")
 }
 
+func (s *S) TestAppLogSelectByType(c *check.C) {
+	a := app.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	a.Log("web log", "web", "")
+	a.Log("router log", app.AccessLogSource, "")
+	a.Log("system log", "tsuru", "")
+	url := fmt.Sprintf("/apps/%s/log/?:app=%s&type=app,router&lines=10", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = appLog(recorder, request, s.token)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	logs := []app.Applog{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &logs)
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Assert(logs[0].Message, check.Equals, "web log")
+	c.Assert(logs[0].Type, check.Equals, app.LogTypeApp)
+	c.Assert(logs[1].Message, check.Equals, "router log")
+	c.Assert(logs[1].Type, check.Equals, app.LogTypeRouter)
+}
+
+func (s *S) TestAppLogInvalidType(c *check.C) {
+	a := app.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/log/?:app=%s&type=app,other&lines=10", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = appLog(recorder, request, s.token)
+	c.Assert(err, check.NotNil)
+	e, ok := err.(*errors.HTTP)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(e.Message, check.Equals, `invalid log type "other", must be one of: app, router, system`)
+}
+
 func (s *S) TestAppLogSelectByUnit(c *check.C) {
 	a := app.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
 	err := app.CreateApp(&a, s.user)
@@ -5468,6 +5698,79 @@ func (s *S) TestForceDeleteLockOnlyWithPermission(c *check.C) {
 	c.Assert(dbApp.Lock.Locked, check.Equals, true)
 }
 
+func (s *S) TestForceDeleteLockRunningOperation(c *check.C) {
+	a := app.App{Name: "locked", Lock: app.AppLock{Locked: true, Owner: "someone", Reason: "POST /apps/locked/restart"}}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:      appTarget(a.Name),
+		Kind:        permission.PermAppUpdateRestart,
+		Owner:       s.token,
+		Allowed:     event.Allowed(permission.PermAppReadEvents),
+		DisableLock: true,
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(nil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("DELETE", "/apps/locked/lock", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Matches, `app "locked" has a running app.update.restart operation \(event `+evt.UniqueID.Hex()+`\).*\n`)
+	var dbApp app.App
+	err = s.conn.Apps().Find(bson.M{"name": "locked"}).One(&dbApp)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Lock.Locked, check.Equals, true)
+}
+
+func (s *S) TestAppLockList(c *check.C) {
+	acquireDate := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+	a := app.App{Name: "locked", Lock: app.AppLock{Locked: true, Owner: "someone", Reason: "POST /apps/locked/restart", AcquireDate: acquireDate}}
+	err := s.conn.Apps().Insert(a, app.App{Name: "unlocked"})
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.6/locks/apps", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var locks []app.LockInfo
+	err = json.Unmarshal(recorder.Body.Bytes(), &locks)
+	c.Assert(err, check.IsNil)
+	c.Assert(locks, check.HasLen, 1)
+	c.Assert(locks[0].App, check.Equals, "locked")
+	c.Assert(locks[0].Owner, check.Equals, "someone")
+	c.Assert(locks[0].Reason, check.Equals, "POST /apps/locked/restart")
+	c.Assert(locks[0].AcquireDate.Equal(acquireDate), check.Equals, true)
+	c.Assert(locks[0].Age, check.Matches, `1h0m\d+s`)
+	c.Assert(locks[0].RunningEvents, check.DeepEquals, []string{})
+}
+
+func (s *S) TestAppLockListOnlyWithPermission(c *check.C) {
+	err := s.conn.Apps().Insert(
+		app.App{Name: "locked1", Lock: app.AppLock{Locked: true}, Teams: []string{s.team.Name}},
+		app.App{Name: "locked2", Lock: app.AppLock{Locked: true}, Teams: []string{"otherteam"}},
+	)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxTeam, s.team.Name),
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.6/locks/apps", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var locks []app.LockInfo
+	err = json.Unmarshal(recorder.Body.Bytes(), &locks)
+	c.Assert(err, check.IsNil)
+	c.Assert(locks, check.HasLen, 1)
+	c.Assert(locks[0].App, check.Equals, "locked1")
+}
+
 func (s *S) TestRegisterUnit(c *check.C) {
 	a := app.App{
 		Name:     "myappx",
@@ -0,0 +1,169 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/hibernation"
+	"github.com/tsuru/tsuru/app/scaling"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const (
+	timelineDeploy  = "deploy"
+	timelineEnv     = "env"
+	timelineScaling = "scaling"
+	timelineHealer  = "healer"
+	timelineBinding = "binding"
+
+	defaultTimelineLimit = 100
+)
+
+var timelineCategories = []string{timelineDeploy, timelineEnv, timelineScaling, timelineHealer, timelineBinding}
+
+// timelineKinds maps each timeline category to the event kinds included in
+// it.
+var timelineKinds = map[string][]string{
+	timelineDeploy: {
+		permission.PermAppDeploy.FullName(),
+		permission.PermAppUpdateDeployRollback.FullName(),
+		app.EventKindDeployTimeoutRollback,
+	},
+	timelineEnv: {
+		permission.PermAppUpdateEnvSet.FullName(),
+		permission.PermAppUpdateEnvUnset.FullName(),
+	},
+	timelineScaling: {
+		permission.PermAppUpdateUnitAdd.FullName(),
+		permission.PermAppUpdateUnitRemove.FullName(),
+		permission.PermAppUpdateUnitSchedule.FullName(),
+		permission.PermAppUpdateUnitAutoscale.FullName(),
+		permission.PermAppUpdateUnitPlacement.FullName(),
+		scaling.EventKindAutoScale,
+		scaling.EventKindSchedule,
+		hibernation.EventKind,
+	},
+	timelineHealer: {
+		"healer",
+	},
+	timelineBinding: {
+		permission.PermAppUpdateBind.FullName(),
+		permission.PermAppUpdateUnbind.FullName(),
+		permission.PermAppUpdateBindVolume.FullName(),
+		permission.PermAppUpdateUnbindVolume.FullName(),
+	},
+}
+
+type timelineEntry struct {
+	ID        string    `json:"id"`
+	Category  string    `json:"category"`
+	Kind      string    `json:"kind"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Owner     string    `json:"owner"`
+	Running   bool      `json:"running"`
+	Error     string    `json:"error"`
+}
+
+func timelineCategory(kind string) string {
+	for category, kinds := range timelineKinds {
+		for _, k := range kinds {
+			if k == kind {
+				return category
+			}
+		}
+	}
+	return ""
+}
+
+// title: app timeline
+// path: /apps/{app}/timeline
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appTimeline(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadEvents,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	categories := r.URL.Query()["category"]
+	if len(categories) == 0 {
+		categories = timelineCategories
+	}
+	var kinds []string
+	for _, category := range categories {
+		categoryKinds, ok := timelineKinds[category]
+		if !ok {
+			msg := fmt.Sprintf("Invalid category %q, possible values are: %s", category, strings.Join(timelineCategories, ", "))
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+		}
+		kinds = append(kinds, categoryKinds...)
+	}
+	limit := defaultTimelineLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > defaultTimelineLimit {
+			msg := fmt.Sprintf("Invalid limit, it must be a number between 1 and %d", defaultTimelineLimit)
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+		}
+	}
+	var skip int
+	if skipStr := r.URL.Query().Get("skip"); skipStr != "" {
+		skip, err = strconv.Atoi(skipStr)
+		if err != nil || skip < 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid skip, it must be a positive number"}
+		}
+	}
+	evts, err := event.List(&event.Filter{
+		Target:    event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		KindNames: kinds,
+		Limit:     limit,
+		Skip:      skip,
+	})
+	if err != nil {
+		return err
+	}
+	if len(evts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	entries := make([]timelineEntry, len(evts))
+	for i, evt := range evts {
+		entries[i] = timelineEntry{
+			ID:        evt.UniqueID.Hex(),
+			Category:  timelineCategory(evt.Kind.Name),
+			Kind:      evt.Kind.Name,
+			StartTime: evt.StartTime,
+			EndTime:   evt.EndTime,
+			Owner:     evt.Owner.Name,
+			Running:   evt.Running,
+			Error:     evt.Error,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createTimelineEvents(c *check.C, appName string) {
+	allowed := event.Allowed(permission.PermAppReadEvents, permission.Context(permission.CtxApp, appName))
+	for _, kind := range []*permission.PermissionScheme{
+		permission.PermAppDeploy,
+		permission.PermAppUpdateEnvSet,
+		permission.PermAppUpdateRestart,
+		permission.PermAppUpdateUnitAdd,
+	} {
+		evt, err := event.New(&event.Opts{
+			Target:  event.Target{Type: event.TargetTypeApp, Value: appName},
+			Owner:   s.token,
+			Kind:    kind,
+			Allowed: allowed,
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.Done(nil)
+		c.Assert(err, check.IsNil)
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target: event.Target{Type: event.TargetTypeContainer, Value: "c1"},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: event.TargetTypeApp, Value: appName}},
+		},
+		InternalKind: "healer",
+		Allowed:      allowed,
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestAppTimeline(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	s.createTimelineEvents(c, a.Name)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/timeline", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var entries []timelineEntry
+	err = json.NewDecoder(recorder.Body).Decode(&entries)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 4)
+	c.Assert(entries[0].Category, check.Equals, timelineHealer)
+	c.Assert(entries[1].Category, check.Equals, timelineScaling)
+	c.Assert(entries[1].Kind, check.Equals, "app.update.unit.add")
+	c.Assert(entries[2].Category, check.Equals, timelineEnv)
+	c.Assert(entries[3].Category, check.Equals, timelineDeploy)
+	for i := 1; i < len(entries); i++ {
+		c.Assert(entries[i-1].StartTime.Before(entries[i].StartTime), check.Equals, false)
+	}
+}
+
+func (s *S) TestAppTimelineCategoryAndPagination(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	s.createTimelineEvents(c, a.Name)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/timeline?category=deploy&category=env&limit=1&skip=1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var entries []timelineEntry
+	err = json.NewDecoder(recorder.Body).Decode(&entries)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].Category, check.Equals, timelineDeploy)
+}
+
+func (s *S) TestAppTimelineInvalidCategory(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/timeline?category=restart", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
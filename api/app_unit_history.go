@@ -0,0 +1,87 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const maxUnitHistoryLimit = 1000
+
+func parseHistoryTime(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid %s, it must be a RFC3339 time, e.g. 2018-01-02T15:04:05Z", name)
+		return time.Time{}, &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	return t, nil
+}
+
+// title: app unit history
+// path: /apps/{app}/units/history
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appUnitHistory(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadEvents,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	filter := app.UnitHistoryFilter{
+		App:     a.Name,
+		Process: r.URL.Query().Get("process"),
+		Limit:   maxUnitHistoryLimit,
+	}
+	filter.Since, err = parseHistoryTime(r, "since")
+	if err != nil {
+		return err
+	}
+	filter.Until, err = parseHistoryTime(r, "until")
+	if err != nil {
+		return err
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		filter.Limit, err = strconv.Atoi(limitStr)
+		if err != nil || filter.Limit <= 0 || filter.Limit > maxUnitHistoryLimit {
+			msg := fmt.Sprintf("Invalid limit, it must be a number between 1 and %d", maxUnitHistoryLimit)
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+		}
+	}
+	changes, err := app.UnitCountHistory(filter)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(changes)
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppUnitHistory(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Quota: quota.Unlimited}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	for _, method := range []string{"PUT", "DELETE"} {
+		body := strings.NewReader("units=2&process=web")
+		request, err := http.NewRequest(method, "/apps/myapp/units?units=2&process=web", body)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	}
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/units/history?process=web", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var changes []app.UnitCountChange
+	err = json.NewDecoder(recorder.Body).Decode(&changes)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 2)
+	c.Assert(changes[0].From, check.Equals, 0)
+	c.Assert(changes[0].To, check.Equals, 2)
+	c.Assert(changes[1].From, check.Equals, 2)
+	c.Assert(changes[1].To, check.Equals, 0)
+	for _, change := range changes {
+		c.Assert(change.Actor, check.Equals, s.token.GetUserName())
+		c.Assert(change.Reason, check.Equals, app.UnitChangeReasonManual)
+	}
+}
+
+func (s *S) TestAppUnitHistoryNoContent(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/units/history", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestAppUnitHistoryInvalidParams(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	for _, query := range []string{"since=yesterday", "until=2018-01-02", "limit=0", "limit=1001"} {
+		request, err := http.NewRequest("GET", "/1.6/apps/myapp/units/history?"+query, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Check(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("query: %s", query))
+	}
+}
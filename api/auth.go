@@ -15,6 +15,7 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
@@ -47,6 +48,8 @@ func handleAuthError(err error) error {
 		return &errors.HTTP{Code: http.StatusForbidden, Message: err.Error()}
 	case auth.AuthenticationFailure:
 		return &errors.HTTP{Code: http.StatusUnauthorized, Message: err.Error()}
+	case auth.LoginLockedError:
+		return &errors.HTTP{Code: http.StatusTooManyRequests, Message: err.Error()}
 	default:
 		return err
 	}
@@ -130,6 +133,8 @@ func login(w http.ResponseWriter, r *http.Request) (err error) {
 	for key := range r.Form {
 		params[key] = r.FormValue(key)
 	}
+	params[auth.SessionClientParam] = r.UserAgent()
+	params[auth.SessionIPParam] = requestIP(r)
 	token, err := app.AuthScheme.Login(params)
 	if err != nil {
 		return handleAuthError(err)
@@ -143,6 +148,9 @@ func login(w http.ResponseWriter, r *http.Request) (err error) {
 // responses:
 //   200: Ok
 func logout(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if _, ok := t.(auth.ImpersonatedToken); ok {
+		return auth.EndImpersonation(t.GetValue())
+	}
 	return app.AuthScheme.Logout(t.GetValue())
 }
 
@@ -234,6 +242,107 @@ func resetPassword(w http.ResponseWriter, r *http.Request) (err error) {
 	return managed.ResetPassword(u, token)
 }
 
+// title: unlock user logins
+// path: /users/{email}/lockout
+// method: DELETE
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func unlockUserLogins(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	lockoutScheme, ok := app.AuthScheme.(auth.LockoutScheme)
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: nonManagedSchemeMsg}
+	}
+	r.ParseForm()
+	email := r.URL.Query().Get(":email")
+	allowed := permission.Check(t, permission.PermUserUpdateLockout, permission.Context(permission.CtxUser, email))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = auth.GetUserByEmail(email)
+	if err != nil {
+		if err == authTypes.ErrUserNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserUpdateLockout,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return lockoutScheme.UnlockUser(email)
+}
+
+// title: unlock ip logins
+// path: /auth/lockouts/ip/{ip}
+// method: DELETE
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+func unlockIPLogins(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	lockoutScheme, ok := app.AuthScheme.(auth.LockoutScheme)
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: nonManagedSchemeMsg}
+	}
+	r.ParseForm()
+	if !permission.Check(t, permission.PermUserUpdateLockout) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeGlobal},
+		Kind:       permission.PermUserUpdateLockout,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermUserReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return lockoutScheme.UnlockIP(r.URL.Query().Get(":ip"))
+}
+
+// notifyLoginLockout records the lockout of a user, or of an IP, in an
+// internal event.
+func notifyLoginLockout(lockout native.Lockout) {
+	target := event.Target{Type: event.TargetTypeGlobal}
+	key := lockout.IP
+	var contexts []permission.PermissionContext
+	if lockout.Email != "" {
+		target = userTarget(lockout.Email)
+		key = lockout.Email
+		contexts = append(contexts, permission.Context(permission.CtxUser, lockout.Email))
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       target,
+		InternalKind: native.EventKindLoginLockout,
+		CustomData: map[string]interface{}{
+			"ip":          lockout.IP,
+			"failures":    lockout.Failures,
+			"lockedUntil": lockout.LockedUntil,
+		},
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermUserReadEvents, contexts...),
+	})
+	if err != nil {
+		log.Errorf("[login lockout] unable to create event for lockout of %q: %v", key, err)
+		return
+	}
+	evt.Done(nil)
+}
+
 var teamRenameFns = []func(oldName, newName string) error{
 	app.RenameTeam,
 	service.RenameServiceTeam,
@@ -547,6 +656,9 @@ func teamInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 //   401: Unauthorized
 //   409: Key already exists
 func addKeyToUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if auth.ImpersonatorOf(t) != "" {
+		return errImpersonationNotAllowed
+	}
 	key := repository.Key{
 		Body: r.FormValue("key"),
 		Name: r.FormValue("name"),
@@ -668,6 +780,9 @@ func listKeys(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 //   401: Unauthorized
 //   404: Not found
 func removeUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if auth.ImpersonatorOf(t) != "" {
+		return errImpersonationNotAllowed
+	}
 	r.ParseForm()
 	email := r.URL.Query().Get("user")
 	if email == "" {
@@ -694,6 +809,10 @@ func removeUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	if err != nil {
 		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
 	}
+	return deleteUser(u)
+}
+
+func deleteUser(u *auth.User) error {
 	appNames, err := deployableApps(u, make(map[string]*permission.Role))
 	if err != nil {
 		return err
@@ -708,6 +827,53 @@ func removeUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	return app.AuthScheme.Remove(u)
 }
 
+// deactivateUser disables the user, revoking its sessions, API key and
+// repository access. Roles are kept, so activating the user again restores
+// its access.
+func deactivateUser(u *auth.User) error {
+	if u.Disabled {
+		return nil
+	}
+	appNames, err := deployableApps(u, make(map[string]*permission.Role))
+	if err != nil {
+		return err
+	}
+	u.Disabled = true
+	u.APIKey = ""
+	err = u.Update()
+	if err != nil {
+		return err
+	}
+	err = auth.RevokeSessions(u.Email)
+	if err != nil {
+		return err
+	}
+	err = auth.RevokeImpersonations(u.Email)
+	if err != nil {
+		return err
+	}
+	manager := repository.Manager()
+	for _, name := range appNames {
+		err = manager.RevokeAccess(name, u.Email)
+		if err != nil {
+			log.Errorf("error revoking gandalf access for app %s, user %s: %s", name, u.Email, err)
+		}
+	}
+	return nil
+}
+
+func activateUser(u *auth.User) error {
+	if !u.Disabled {
+		return nil
+	}
+	u.Disabled = false
+	err := u.Update()
+	if err != nil {
+		return err
+	}
+	return syncRepositoryApps(u, nil, make(map[string]*permission.Role))
+}
+
 type schemeData struct {
 	Name string          `json:"name"`
 	Data auth.SchemeInfo `json:"data"`
@@ -729,6 +895,22 @@ func authScheme(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(data)
 }
 
+// title: get password policy
+// path: /auth/password-policy
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Scheme without password policy
+func passwordPolicy(w http.ResponseWriter, r *http.Request) error {
+	policyScheme, ok := app.AuthScheme.(auth.PasswordPolicyScheme)
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: nonManagedSchemeMsg}
+	}
+	w.Header().Add("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(policyScheme.PasswordPolicy())
+}
+
 // title: regenerate token
 // path: /users/api-key
 // method: POST
@@ -738,6 +920,9 @@ func authScheme(w http.ResponseWriter, r *http.Request) error {
 //   401: Unauthorized
 //   404: User not found
 func regenerateAPIToken(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if auth.ImpersonatorOf(t) != "" {
+		return errImpersonationNotAllowed
+	}
 	r.ParseForm()
 	email := r.URL.Query().Get("user")
 	if email == "" {
@@ -781,6 +966,9 @@ func regenerateAPIToken(w http.ResponseWriter, r *http.Request, t auth.Token) (e
 //   401: Unauthorized
 //   404: User not found
 func showAPIToken(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if auth.ImpersonatorOf(t) != "" {
+		return errImpersonationNotAllowed
+	}
 	u, err := t.User()
 	if err != nil {
 		return err
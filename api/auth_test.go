@@ -23,6 +23,7 @@ import (
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/db/dbtest"
 	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
@@ -369,6 +370,124 @@ func (s *AuthSuite) TestLogout(c *check.C) {
 	c.Assert(err, check.Equals, auth.ErrInvalidToken)
 }
 
+func (s *AuthSuite) TestLoginLockedOut(c *check.C) {
+	config.Set("auth:login-lockout:max-failures", 1)
+	defer config.Unset("auth:login-lockout:max-failures")
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(&u)
+	c.Assert(err, check.IsNil)
+	for _, expected := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		b := strings.NewReader("password=wrongpass")
+		request, err := http.NewRequest(http.MethodPost, "/users/nobody@globo.com/tokens", b)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, expected)
+	}
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeUser, Value: u.Email},
+		Kind:   native.EventKindLoginLockout,
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestLoginLockedOutIgnoresForwardedForFromClients(c *check.C) {
+	config.Set("auth:login-lockout:max-failures-per-ip", 1)
+	defer config.Unset("auth:login-lockout:max-failures-per-ip")
+	for i, expected := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		b := strings.NewReader("password=wrongpass")
+		request, err := http.NewRequest(http.MethodPost, "/users/"+s.user.Email+"/tokens", b)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("X-Forwarded-For", fmt.Sprintf("192.168.0.%d", i))
+		request.RemoteAddr = "10.0.0.1:43210"
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, expected)
+	}
+}
+
+func (s *AuthSuite) TestUnlockUserLogins(c *check.C) {
+	config.Set("auth:login-lockout:max-failures", 1)
+	defer config.Unset("auth:login-lockout:max-failures")
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(&u)
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(map[string]string{"email": u.Email, "password": "wrongpass"})
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	request, err := http.NewRequest(http.MethodDelete, "/users/nobody@globo.com/lockout", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = nativeScheme.Login(map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.update.lockout",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":email", "value": u.Email},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestUnlockUserLoginsRequiresPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	request, err := http.NewRequest(http.MethodDelete, "/users/"+s.user.Email+"/lockout", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *AuthSuite) TestUnlockUserLoginsUserNotFound(c *check.C) {
+	request, err := http.NewRequest(http.MethodDelete, "/users/nobody@globo.com/lockout", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *AuthSuite) TestUnlockIPLogins(c *check.C) {
+	config.Set("auth:login-lockout:max-failures-per-ip", 1)
+	defer config.Unset("auth:login-lockout:max-failures-per-ip")
+	_, err := nativeScheme.Login(map[string]string{"email": s.user.Email, "password": "wrongpass", auth.SessionIPParam: "10.0.0.1"})
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	request, err := http.NewRequest(http.MethodDelete, "/auth/lockouts/ip/10.0.0.1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = nativeScheme.Login(map[string]string{"email": s.user.Email, "password": "123456", auth.SessionIPParam: "10.0.0.1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeGlobal},
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.update.lockout",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":ip", "value": "10.0.0.1"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestUnlockIPLoginsRequiresPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone", permission.Permission{
+		Scheme:  permission.PermUserUpdateLockout,
+		Context: permission.Context(permission.CtxUser, "someone@groundcontrol.com"),
+	})
+	request, err := http.NewRequest(http.MethodDelete, "/auth/lockouts/ip/10.0.0.1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *AuthSuite) TestCreateTeam(c *check.C) {
 	teamName := "teamredbull"
 	s.mockTeamService.OnCreate = func(teamName string, _ *authTypes.User) error {
@@ -1135,6 +1254,33 @@ func (s *AuthSuite) TestAuthScheme(c *check.C) {
 	c.Assert(parsed["data"], check.DeepEquals, map[string]interface{}{"foo": "bar", "foo2": "bar2"})
 }
 
+func (s *AuthSuite) TestPasswordPolicy(c *check.C) {
+	config.Set("auth:password-policy:min-length", 10)
+	config.Set("auth:password-policy:require-digit", true)
+	defer config.Unset("auth:password-policy")
+	request, err := http.NewRequest(http.MethodGet, "/auth/password-policy", nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var policy auth.PasswordPolicy
+	err = json.NewDecoder(recorder.Body).Decode(&policy)
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, check.DeepEquals, auth.PasswordPolicy{MinLength: 10, MaxLength: 50, RequireDigit: true})
+}
+
+func (s *AuthSuite) TestPasswordPolicyUnsupportedScheme(c *check.C) {
+	oldScheme := app.AuthScheme
+	defer func() { app.AuthScheme = oldScheme }()
+	app.AuthScheme = TestScheme{}
+	request, err := http.NewRequest(http.MethodGet, "/auth/password-policy", nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
 func (s *AuthSuite) TestRegenerateAPITokenHandler(c *check.C) {
 	u := auth.User{Email: "zobomafoo@zimbabue.com", Password: "123456"}
 	_, err := nativeScheme.Create(&u)
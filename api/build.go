@@ -74,7 +74,7 @@ func build(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppBuild,
-		RawOwner:      event.Owner{Type: event.OwnerTypeUser, Name: userName},
+		RawOwner:      event.Owner{Type: event.OwnerTypeUser, Name: userName, Impersonator: auth.ImpersonatorOf(t)},
 		CustomData:    opts,
 		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
 		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(instance)...),
@@ -97,28 +97,35 @@ func build(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 }
 
 func prepareToBuild(r *http.Request) (opts app.DeployOptions, err error) {
-	var file multipart.File
+	var file io.ReadCloser
 	var fileSize int64
 	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
-		file, _, err = r.FormFile("file")
+		var formFile multipart.File
+		formFile, _, err = r.FormFile("file")
 		if err != nil {
 			return opts, &tsuruErrors.HTTP{
 				Code:    http.StatusBadRequest,
 				Message: err.Error(),
 			}
 		}
-		fileSize, err = file.Seek(0, io.SeekEnd)
+		fileSize, err = formFile.Seek(0, io.SeekEnd)
 		if err != nil {
 			return opts, errors.Wrap(err, "unable to find uploaded file size")
 		}
-		file.Seek(0, io.SeekStart)
+		formFile.Seek(0, io.SeekStart)
+		file = formFile
+	} else if uploadID := r.FormValue("upload"); uploadID != "" {
+		file, fileSize, err = uploadedFile(r.URL.Query().Get(":appname"), uploadID)
+		if err != nil {
+			return opts, err
+		}
 	}
 	archiveURL := r.FormValue("archive-url")
 	image := r.FormValue("image")
 	if image == "" && archiveURL == "" && file == nil {
 		return opts, &tsuruErrors.HTTP{
 			Code:    http.StatusBadRequest,
-			Message: "you must specify either the archive-url, a image url or upload a file.",
+			Message: "you must specify either the archive-url, a image url, upload a file or a completed upload session.",
 		}
 	}
 	var build bool
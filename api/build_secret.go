@@ -0,0 +1,149 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ajg/form"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+)
+
+// title: app build secrets list
+// path: /apps/{app}/build-secrets
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func appBuildSecretsList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadBuildSecret,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	names, err := a.BuildSecretNames()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(names)
+}
+
+// title: set app build secrets
+// path: /apps/{app}/build-secrets
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Build secrets set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appBuildSecretsSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var e apiTypes.Envs
+	dec := form.NewDecoder(nil)
+	dec.IgnoreUnknownKeys(true)
+	err = dec.DecodeValues(&e, r.Form)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if len(e.Envs) == 0 {
+		msg := "You must provide the list of build secrets"
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateBuildSecretSet,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	secrets := make(map[string]string, len(e.Envs))
+	for i, v := range e.Envs {
+		secrets[v.Name] = v.Value
+		r.Form.Set(fmt.Sprintf("Envs.%d.Value", i), "*****")
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateBuildSecretSet,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.SetBuildSecrets(secrets)
+	if verr, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: verr.Message}
+	}
+	return err
+}
+
+// title: unset app build secrets
+// path: /apps/{app}/build-secrets
+// method: DELETE
+// responses:
+//   200: Build secrets removed
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appBuildSecretsUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	names := r.Form["name"]
+	if len(names) == 0 {
+		msg := "You must provide the list of build secrets."
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateBuildSecretUnset,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateBuildSecretUnset,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.UnsetBuildSecrets(names)
+}
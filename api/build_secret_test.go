@@ -0,0 +1,137 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppBuildSecretsSet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("Envs.0.Name=NPM_TOKEN&Envs.0.Value=abc")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/build-secrets", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	secrets, err := a.BuildSecrets()
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.DeepEquals, map[string]string{"NPM_TOKEN": "abc"})
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env, check.HasLen, len(a.Env))
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.build-secret.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": "myapp"},
+			{"name": "Envs.0.Name", "value": "NPM_TOKEN"},
+			{"name": "Envs.0.Value", "value": "*****"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppBuildSecretsSetInvalidName(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("Envs.0.Name=NPM-TOKEN&Envs.0.Value=abc")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/build-secrets", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestAppBuildSecretsList(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/build-secrets", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	err = a.SetBuildSecrets(map[string]string{"NPM_TOKEN": "abc", "GIT_TOKEN": "xyz"})
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(recorder.Body.String(), check.Not(check.Matches), "(?s).*abc.*")
+	var names []string
+	err = json.NewDecoder(recorder.Body).Decode(&names)
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"GIT_TOKEN", "NPM_TOKEN"})
+}
+
+func (s *S) TestAppBuildSecretsListNoPermission(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppReadEnv,
+		Context: permission.Context(permission.CtxApp, "myapp"),
+	})
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/build-secrets", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppBuildSecretsUnset(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetBuildSecrets(map[string]string{"NPM_TOKEN": "abc", "GIT_TOKEN": "xyz"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/build-secrets?name=NPM_TOKEN", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	secrets, err := a.BuildSecrets()
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.DeepEquals, map[string]string{"GIT_TOKEN": "xyz"})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeApp, Value: "myapp"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.build-secret.unset",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": "myapp"},
+			{"name": "name", "value": "NPM_TOKEN"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppBuildSecretsUnsetNoNames(c *check.C) {
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/build-secrets", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
@@ -0,0 +1,280 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ajg/form"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+)
+
+const defaultBulkConcurrency = 5
+
+type bulkOperation func(a *app.App, evt *event.Event, w io.Writer) error
+
+// bulkStartData returns the start custom data of the event of each app, nil
+// functions use the request form.
+type bulkStartData func(a *app.App) interface{}
+
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (w *prefixWriter) Write(data []byte) (int, error) {
+	newData := bytes.TrimRight(data, "\n")
+	newData = bytes.Replace(newData, []byte("\n"), []byte("\n"+w.prefix), -1)
+	newData = append([]byte(w.prefix), append(newData, '\n')...)
+	_, err := w.w.Write(newData)
+	return len(data), err
+}
+
+func bulkFilter(r *http.Request) (*app.Filter, error) {
+	filter := &app.Filter{
+		TeamOwner: r.FormValue("teamOwner"),
+		Pool:      r.FormValue("pool"),
+		Tags:      r.Form["tag"],
+	}
+	if filter.TeamOwner == "" && filter.Pool == "" && len(filter.Tags) == 0 {
+		return nil, &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "You must select apps by at least one of tag, teamOwner or pool",
+		}
+	}
+	return filter, nil
+}
+
+func bulkConcurrency(r *http.Request) int {
+	maxConcurrency, _ := config.GetInt("bulk:max-concurrency")
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultBulkConcurrency
+	}
+	concurrency, _ := strconv.Atoi(r.FormValue("concurrency"))
+	if concurrency <= 0 || concurrency > maxConcurrency {
+		concurrency = maxConcurrency
+	}
+	return concurrency
+}
+
+// runBulk applies op to every app selected by the request filters that the
+// user is allowed to act on with the given permission scheme. Each app gets
+// its own event, while a parent event targeting all apps holds the
+// consolidated report.
+func runBulk(w http.ResponseWriter, r *http.Request, t auth.Token, scheme *permission.PermissionScheme, startData bulkStartData, op bulkOperation) (err error) {
+	filter, err := bulkFilter(r)
+	if err != nil {
+		return err
+	}
+	contexts := permission.ContextsForPermission(t, scheme)
+	if len(contexts) == 0 {
+		return permission.ErrUnauthorized
+	}
+	apps, err := app.List(appFilterByContext(contexts, filter))
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: "No apps matched the given filters"}
+	}
+	return runBulkOnApps(w, r, t, scheme, apps, event.Target{Type: event.TargetTypeGlobal}, event.Allowed(permission.PermAppReadEvents, contexts...), startData, op)
+}
+
+// runBulkOnApps applies op to the given apps, streaming the progress of each
+// app. The parent event has the given target and holds the consolidated
+// report.
+func runBulkOnApps(w http.ResponseWriter, r *http.Request, t auth.Token, scheme *permission.PermissionScheme, apps []app.App, target event.Target, allowed event.AllowedPermission, startData bulkStartData, op bulkOperation) (err error) {
+	extraTargets := make([]event.ExtraTarget, len(apps))
+	for i := range apps {
+		extraTargets[i] = event.ExtraTarget{Target: appTarget(apps[i].Name)}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       target,
+		ExtraTargets: extraTargets,
+		Kind:         scheme,
+		Owner:        t,
+		CustomData:   event.FormToCustomData(r.Form),
+		DisableLock:  true,
+		Allowed:      allowed,
+	})
+	if err != nil {
+		return err
+	}
+	report := apiTypes.BulkReport{
+		Total:   len(apps),
+		Results: make([]apiTypes.BulkResult, len(apps)),
+	}
+	defer func() { evt.DoneCustomData(err, report) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	fmt.Fprintf(writer, "---- Running %s on %d apps ----\n", scheme.FullName(), len(apps))
+	sem := make(chan struct{}, bulkConcurrency(r))
+	wg := sync.WaitGroup{}
+	for i := range apps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			a := &apps[i]
+			appWriter := &prefixWriter{w: writer, prefix: fmt.Sprintf("[%s] ", a.Name)}
+			report.Results[i].App = a.Name
+			opErr := runBulkForApp(a, r, t, scheme, startData, appWriter, op)
+			if opErr != nil {
+				report.Results[i].Error = opErr.Error()
+				fmt.Fprintf(appWriter, "ERROR: %s\n", opErr)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range report.Results {
+		if result.Error == "" {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+	fmt.Fprintf(writer, "---- Finished: %d succeeded, %d failed ----\n", report.Succeeded, report.Failed)
+	return json.NewEncoder(keepAliveWriter).Encode(report)
+}
+
+func runBulkForApp(a *app.App, r *http.Request, t auth.Token, scheme *permission.PermissionScheme, startData bulkStartData, w io.Writer, op bulkOperation) (err error) {
+	owner := t.GetUserName()
+	locked, err := app.AcquireApplicationLockWait(a.Name, owner, fmt.Sprintf("%s %s", r.Method, r.URL.Path), lockWaitDuration)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return app.ErrAppNotLocked{App: a.Name}
+	}
+	defer app.ReleaseApplicationLock(a.Name)
+	var customData interface{} = event.FormToCustomData(r.Form)
+	if startData != nil {
+		customData = startData(a)
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       scheme,
+		Owner:      t,
+		CustomData: customData,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return op(a, evt, w)
+}
+
+// title: bulk set envs
+// path: /bulk/apps/env
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Envs updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: No apps found
+func bulkSetEnv(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	err := r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var e apiTypes.Envs
+	dec := form.NewDecoder(nil)
+	dec.IgnoreUnknownKeys(true)
+	err = dec.DecodeValues(&e, r.Form)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if len(e.Envs) == 0 {
+		msg := "You must provide the list of environment variables"
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	if e.Private {
+		for i := 0; i < len(e.Envs); i++ {
+			r.Form.Set(fmt.Sprintf("Envs.%d.Value", i), "*****")
+		}
+	}
+	variables := make([]bind.EnvVar, len(e.Envs))
+	for i, v := range e.Envs {
+		variables[i] = bind.EnvVar{Name: v.Name, Value: v.Value, Public: !e.Private}
+	}
+	return runBulk(w, r, t, permission.PermAppUpdateEnvSet, nil, func(a *app.App, evt *event.Event, w io.Writer) error {
+		return a.SetEnvs(bind.SetEnvArgs{
+			Envs:          variables,
+			ShouldRestart: !e.NoRestart,
+			Writer:        w,
+		})
+	})
+}
+
+// title: bulk restart
+// path: /bulk/apps/restart
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: No apps found
+func bulkRestart(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	process := r.FormValue("process")
+	return runBulk(w, r, t, permission.PermAppUpdateRestart, nil, func(a *app.App, evt *event.Event, w io.Writer) error {
+		return a.Restart(process, w)
+	})
+}
+
+// title: bulk rebuild
+// path: /bulk/apps/rebuild
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: No apps found
+func bulkRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	deployOptions := func(a *app.App) app.DeployOptions {
+		return app.DeployOptions{
+			App:    a,
+			User:   t.GetUserName(),
+			Origin: "rebuild",
+			Kind:   app.DeployRebuild,
+		}
+	}
+	startData := func(a *app.App) interface{} {
+		return deployOptions(a)
+	}
+	return runBulk(w, r, t, permission.PermAppDeploy, startData, func(a *app.App, evt *event.Event, w io.Writer) error {
+		opts := deployOptions(a)
+		opts.OutputStream = w
+		opts.Event = evt
+		_, err := app.Deploy(opts)
+		return err
+	})
+}
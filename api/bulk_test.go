@@ -0,0 +1,161 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/ajg/form"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	"gopkg.in/check.v1"
+)
+
+func decodeBulkReport(c *check.C, body string) apiTypes.BulkReport {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	var report apiTypes.BulkReport
+	err := json.Unmarshal([]byte(lines[len(lines)-1]), &report)
+	c.Assert(err, check.IsNil)
+	return report
+}
+
+func (s *S) TestBulkRestartByTag(c *check.C) {
+	config.Set("docker:router", "fake")
+	defer config.Unset("docker:router")
+	a1 := app.App{Name: "bulk1", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"batch"}}
+	err := app.CreateApp(&a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := app.App{Name: "bulk2", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"batch"}}
+	err = app.CreateApp(&a2, s.user)
+	c.Assert(err, check.IsNil)
+	a3 := app.App{Name: "bulk3", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(&a3, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("tag=batch&process=web")
+	request, err := http.NewRequest("POST", "/1.6/bulk/apps/restart", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	report := decodeBulkReport(c, recorder.Body.String())
+	c.Assert(report.Total, check.Equals, 2)
+	c.Assert(report.Succeeded, check.Equals, 2)
+	c.Assert(report.Failed, check.Equals, 0)
+	c.Assert(s.provisioner.Restarts(&a1, "web"), check.Equals, 1)
+	c.Assert(s.provisioner.Restarts(&a2, "web"), check.Equals, 1)
+	c.Assert(s.provisioner.Restarts(&a3, "web"), check.Equals, 0)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a1.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.restart",
+		StartCustomData: []map[string]interface{}{
+			{"name": "tag", "value": "batch"},
+			{"name": "process", "value": "web"},
+		},
+	}, eventtest.HasEvent)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeGlobal},
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.restart",
+		StartCustomData: []map[string]interface{}{
+			{"name": "tag", "value": "batch"},
+			{"name": "process", "value": "web"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestBulkRebuildByTag(c *check.C) {
+	a1 := app.App{Name: "bulk1", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"batch"}}
+	err := app.CreateApp(&a1, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/bulk/apps/rebuild", strings.NewReader("tag=batch"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	report := decodeBulkReport(c, recorder.Body.String())
+	c.Assert(report.Total, check.Equals, 1)
+	c.Assert(report.Succeeded, check.Equals, 1)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a1.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.deploy",
+		StartCustomData: map[string]interface{}{
+			"kind":   "rebuild",
+			"origin": "rebuild",
+		},
+	}, eventtest.HasEvent)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeGlobal},
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.deploy",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestBulkSetEnvByTeamOwner(c *check.C) {
+	a1 := app.App{Name: "bulk1", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := app.App{Name: "bulk2", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(&a2, s.user)
+	c.Assert(err, check.IsNil)
+	d := apiTypes.Envs{
+		Envs: []struct{ Name, Value string }{
+			{"REGION", "east"},
+		},
+		NoRestart: true,
+	}
+	v, err := form.EncodeToValues(&d)
+	c.Assert(err, check.IsNil)
+	v.Set("teamOwner", s.team.Name)
+	request, err := http.NewRequest("POST", "/1.6/bulk/apps/env", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	report := decodeBulkReport(c, recorder.Body.String())
+	c.Assert(report.Total, check.Equals, 2)
+	c.Assert(report.Succeeded, check.Equals, 2)
+	for _, name := range []string{a1.Name, a2.Name} {
+		dbApp, err := app.GetByName(name)
+		c.Assert(err, check.IsNil)
+		c.Assert(dbApp.Env["REGION"], check.DeepEquals, bind.EnvVar{Name: "REGION", Value: "east", Public: true})
+	}
+}
+
+func (s *S) TestBulkRestartRequiresFilter(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.6/bulk/apps/restart", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "You must select apps by at least one of tag, teamOwner or pool\n")
+}
+
+func (s *S) TestBulkRestartNoAppsMatched(c *check.C) {
+	body := strings.NewReader("pool=unknown-pool")
+	request, err := http.NewRequest("POST", "/1.6/bulk/apps/restart", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
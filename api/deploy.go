@@ -11,12 +11,17 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ajg/form"
+	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/buildlog"
+	"github.com/tsuru/tsuru/app/buildqueue"
 	"github.com/tsuru/tsuru/auth"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/repository"
 )
 
@@ -96,7 +101,7 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppDeploy,
-		RawOwner:      event.Owner{Type: event.OwnerTypeUser, Name: userName},
+		RawOwner:      event.Owner{Type: event.OwnerTypeUser, Name: userName, Impersonator: auth.ImpersonatorOf(t)},
 		CustomData:    opts,
 		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
 		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(instance)...),
@@ -165,9 +170,7 @@ func diffDeploy(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
-	return evt.SetOtherCustomData(map[string]string{
-		"diff": diff,
-	})
+	return evt.SetOtherCustomDataField("diff", diff)
 }
 
 // title: rollback
@@ -301,6 +304,142 @@ func deployInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(deploy)
 }
 
+// title: deploy config diff
+// path: /deploys/{deploy}/diff
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func deployConfigDiff(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	notFound := &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "Deploy config diff not found."}
+	depID := r.URL.Query().Get(":deploy")
+	if !bson.IsObjectIdHex(depID) {
+		return notFound
+	}
+	deploy, err := app.GetDeploy(depID)
+	if err != nil {
+		if err == event.ErrEventNotFound {
+			return notFound
+		}
+		return err
+	}
+	dbApp, err := app.GetByName(deploy.App)
+	if err != nil {
+		if err == app.ErrAppNotFound {
+			return notFound
+		}
+		return err
+	}
+	canGet := permission.Check(t, permission.PermAppReadDeploy, contextsForApp(dbApp)...)
+	if !canGet || deploy.ConfigDiff == nil {
+		return notFound
+	}
+	w.Header().Add("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deploy.ConfigDiff)
+}
+
+var buildLogPollInterval = time.Second
+
+// title: deploy build log
+// path: /deploys/{deploy}/buildlog
+// method: GET
+// produce: text/plain
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func deployBuildLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	notFound := &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "Build log not found."}
+	depID := r.URL.Query().Get(":deploy")
+	if !bson.IsObjectIdHex(depID) {
+		return notFound
+	}
+	buildLog, err := buildlog.Get(bson.ObjectIdHex(depID))
+	if err != nil {
+		if err == buildlog.ErrBuildLogNotFound {
+			return notFound
+		}
+		return err
+	}
+	dbApp, err := app.GetByName(buildLog.App)
+	if err != nil {
+		if err == app.ErrAppNotFound {
+			return notFound
+		}
+		return err
+	}
+	canGet := permission.Check(t, permission.PermAppReadDeploy, contextsForApp(dbApp)...)
+	if !canGet {
+		return notFound
+	}
+	follow, _ := strconv.ParseBool(r.URL.Query().Get("follow"))
+	w.Header().Set("Content-Type", "text/plain")
+	var last bson.ObjectId
+	for {
+		entries, err := buildLog.Entries(last)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			w.Write(e.Data)
+			last = e.ID
+		}
+		if !follow || buildLog.Finished {
+			return nil
+		}
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-time.After(buildLogPollInterval):
+		}
+		buildLog, err = buildlog.Get(buildLog.Deploy)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type buildQueue struct {
+	Limits buildqueue.Limits  `json:"limits"`
+	Builds []buildqueue.Entry `json:"builds"`
+}
+
+// title: build queue
+// path: /builds/queue
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+func buildQueueList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	entries, err := buildqueue.List()
+	if err != nil {
+		return err
+	}
+	appContexts := map[string][]permission.PermissionContext{}
+	queue := buildQueue{Limits: buildqueue.GetLimits(), Builds: []buildqueue.Entry{}}
+	for _, e := range entries {
+		contexts, ok := appContexts[e.App]
+		if !ok {
+			a, err := app.GetByName(e.App)
+			if err != nil && err != app.ErrAppNotFound {
+				return err
+			}
+			if a != nil {
+				contexts = contextsForApp(a)
+			}
+			appContexts[e.App] = contexts
+		}
+		if contexts != nil && permission.Check(t, permission.PermAppReadDeploy, contexts...) {
+			queue.Builds = append(queue.Builds, e)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(queue)
+}
+
 // title: rebuild
 // path: /apps/{appname}/deploy/rebuild
 // method: POST
@@ -361,6 +500,232 @@ func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return nil
 }
 
+// title: deploy image diff
+// path: /apps/{appname}/deploy/diff
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Invalid data
+//   404: Not found
+func deployDiff(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":appname")
+	a, err := app.GetByName(appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	canGet := permission.Check(t, permission.PermAppReadDeploy, contextsForApp(a)...)
+	if !canGet {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if !bson.IsObjectIdHex(from) || !bson.IsObjectIdHex(to) {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "from and to must be valid deploy ids"}
+	}
+	diff, err := app.DiffDeploys(appName, from, to)
+	if err != nil {
+		switch err {
+		case event.ErrEventNotFound, app.ErrDeployFromOtherApp:
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "Deploy not found."}
+		case app.ErrDeployWithoutImage, app.ErrImageInspectMissing:
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(diff)
+}
+
+// title: deploy timeouts
+// path: /apps/{appname}/deploy/timeouts
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+func deployTimeouts(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadDeploy, contextsForApp(&a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	timeouts := map[provision.DeployPhase]int{}
+	for _, phase := range provision.DeployPhases {
+		timeouts[phase] = int(a.DeployPhaseTimeout(phase).Seconds())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(timeouts)
+}
+
+// title: set deploy timeouts
+// path: /apps/{appname}/deploy/timeouts
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Timeouts updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func setDeployTimeouts(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	err = r.ParseForm()
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	timeouts := map[string]int{}
+	for _, phase := range provision.DeployPhases {
+		value := r.Form.Get(string(phase))
+		if value == "" {
+			continue
+		}
+		timeouts[string(phase)], err = strconv.Atoi(value)
+		if err != nil {
+			return &tsuruErrors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid timeout for phase %q, it must be a number of seconds", phase),
+			}
+		}
+	}
+	if len(timeouts) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "You must set the timeout of at least one deploy phase."}
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateDeployTimeouts, contextsForApp(&a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateDeployTimeouts,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.SetDeployTimeouts(timeouts)
+	if _, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: deploy verification
+// path: /apps/{appname}/deploy/verification
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func deployVerification(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadDeploy, contextsForApp(&a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	if a.DeployVerification == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(a.DeployVerification)
+}
+
+// title: set deploy verification
+// path: /apps/{appname}/deploy/verification
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Verification updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func setDeployVerification(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	err = r.ParseForm()
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var verification app.DeployVerification
+	dec := form.NewDecoder(nil)
+	dec.IgnoreCase(true)
+	dec.IgnoreUnknownKeys(true)
+	err = dec.DecodeValues(&verification, r.Form)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateDeployVerification, contextsForApp(&a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateDeployVerification,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.SetDeployVerification(&verification)
+	if _, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: remove deploy verification
+// path: /apps/{appname}/deploy/verification
+// method: DELETE
+// responses:
+//   200: Verification removed
+//   401: Unauthorized
+//   404: App not found
+func removeDeployVerification(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateDeployVerification, contextsForApp(&a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  appTarget(appName),
+		Kind:    permission.PermAppUpdateDeployVerification,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetDeployVerification(nil)
+}
+
 // title: rollback update
 // path: /apps/{appname}/deploy/rollback/update
 // method: PUT
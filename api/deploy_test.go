@@ -6,6 +6,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -19,6 +20,8 @@ import (
 	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/buildlog"
+	"github.com/tsuru/tsuru/app/buildqueue"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/builder"
@@ -796,7 +799,7 @@ func (s *DeploySuite) TestDeployWithoutArchiveURL(c *check.C) {
 	server.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
 	message := recorder.Body.String()
-	c.Assert(message, check.Equals, "you must specify either the archive-url, a image url or upload a file.\n")
+	c.Assert(message, check.Equals, "you must specify either the archive-url, a image url, upload a file or a completed upload session.\n")
 }
 
 func (s *DeploySuite) TestPermSchemeForDeploy(c *check.C) {
@@ -1602,3 +1605,411 @@ func (s *DeploySuite) TestRollbackUpdateErrNoPerms(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Equals, "User does not have permission to do this action in this app\n")
 	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
 }
+
+func (s *DeploySuite) TestDeployDiffInvalidID(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/g1/deploy/diff?from=abc&to=def", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "from and to must be valid deploy ids\n")
+}
+
+func (s *DeploySuite) TestDeployDiffDeployFromOtherApp(c *check.C) {
+	user, _ := s.token.User()
+	for _, name := range []string{"g1", "g2"} {
+		a := app.App{Name: name, Platform: "python", TeamOwner: s.team.Name}
+		err := app.CreateApp(&a, user)
+		c.Assert(err, check.IsNil)
+	}
+	evts := insertDeploysAsEvents([]app.DeployData{
+		{App: "g1", Timestamp: time.Now(), Image: "tsuru/app-g1:v1"},
+		{App: "g2", Timestamp: time.Now(), Image: "tsuru/app-g2:v1"},
+	}, c)
+	url := fmt.Sprintf("/apps/g1/deploy/diff?from=%s&to=%s", evts[0].UniqueID.Hex(), evts[1].UniqueID.Hex())
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "Deploy not found.\n")
+}
+
+func (s *DeploySuite) TestDeployDiffWithoutPermission(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "otherapp", permission.Permission{
+		Scheme:  permission.PermAppReadDeploy,
+		Context: permission.Context(permission.CtxApp, "other"),
+	})
+	request, err := http.NewRequest("GET", "/apps/g1/deploy/diff", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployBuildLog(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	deployID := bson.NewObjectId()
+	w, err := buildlog.NewWriter(a.Name, deployID)
+	c.Assert(err, check.IsNil)
+	fmt.Fprintln(w, "step 1")
+	fmt.Fprintln(w, "step 2")
+	request, err := http.NewRequest("GET", "/deploys/"+deployID.Hex()+"/buildlog", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/plain")
+	c.Assert(recorder.Body.String(), check.Equals, "step 1\nstep 2\n")
+}
+
+func (s *DeploySuite) TestDeployBuildLogFollow(c *check.C) {
+	oldInterval := buildLogPollInterval
+	buildLogPollInterval = 10 * time.Millisecond
+	defer func() { buildLogPollInterval = oldInterval }()
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	deployID := bson.NewObjectId()
+	w, err := buildlog.NewWriter(a.Name, deployID)
+	c.Assert(err, check.IsNil)
+	fmt.Fprintln(w, "step 1")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprintln(w, "step 2")
+		w.Close(nil)
+	}()
+	request, err := http.NewRequest("GET", "/deploys/"+deployID.Hex()+"/buildlog?follow=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "step 1\nstep 2\n")
+}
+
+func (s *DeploySuite) TestDeployBuildLogNotFound(c *check.C) {
+	for _, id := range []string{"invalid", bson.NewObjectId().Hex()} {
+		request, err := http.NewRequest("GET", "/deploys/"+id+"/buildlog", nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+		c.Assert(recorder.Body.String(), check.Equals, "Build log not found.\n")
+	}
+}
+
+func (s *DeploySuite) TestBuildQueueList(c *check.C) {
+	config.Set("deploy:max-concurrent-builds", 2)
+	defer config.Unset("deploy:max-concurrent-builds")
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	deployID := bson.NewObjectId()
+	release, err := buildqueue.Acquire(context.Background(), deployID, a.Name, a.TeamOwner, ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	defer release()
+	otherRelease, err := buildqueue.Acquire(context.Background(), bson.NewObjectId(), "otherapp", "otherteam", ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	defer otherRelease()
+	request, err := http.NewRequest("GET", "/1.6/builds/queue", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var queue buildQueue
+	err = json.Unmarshal(recorder.Body.Bytes(), &queue)
+	c.Assert(err, check.IsNil)
+	c.Assert(queue.Limits, check.DeepEquals, buildqueue.Limits{Global: 2})
+	c.Assert(queue.Builds, check.HasLen, 1)
+	c.Assert(queue.Builds[0].Deploy, check.Equals, deployID)
+	c.Assert(queue.Builds[0].App, check.Equals, a.Name)
+	c.Assert(queue.Builds[0].Running, check.Equals, true)
+}
+
+func (s *DeploySuite) TestDeployBuildLogWithoutPermission(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	deployID := bson.NewObjectId()
+	_, err = buildlog.NewWriter(a.Name, deployID)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "otherapp", permission.Permission{
+		Scheme:  permission.PermAppReadDeploy,
+		Context: permission.Context(permission.CtxApp, "other"),
+	})
+	request, err := http.NewRequest("GET", "/deploys/"+deployID.Hex()+"/buildlog", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployConfigDiff(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	evts := insertDeploysAsEvents([]app.DeployData{{App: "g1", Timestamp: time.Now()}}, c)
+	diff := app.DeployConfigDiff{
+		Previous:  bson.NewObjectId(),
+		Env:       []app.ValueChange{{Name: "DATABASE", From: "mysql", To: "postgres"}},
+		Plan:      []app.ValueChange{},
+		Units:     []app.ValueChange{{Name: "web", From: "1", To: "2"}},
+		TsuruYaml: []app.ValueChange{},
+	}
+	err = evts[0].SetOtherCustomDataField("configDiff", diff)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/deploys/"+evts[0].UniqueID.Hex()+"/diff", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result app.DeployConfigDiff
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, diff)
+}
+
+func (s *DeploySuite) TestDeployConfigDiffNotRecorded(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	evts := insertDeploysAsEvents([]app.DeployData{{App: "g1", Timestamp: time.Now()}}, c)
+	request, err := http.NewRequest("GET", "/deploys/"+evts[0].UniqueID.Hex()+"/diff", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployConfigDiffNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/deploys/"+bson.NewObjectId().Hex()+"/diff", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployConfigDiffWithoutPermission(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	evts := insertDeploysAsEvents([]app.DeployData{{App: "g1", Timestamp: time.Now()}}, c)
+	err = evts[0].SetOtherCustomDataField("configDiff", app.DeployConfigDiff{})
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "otherapp", permission.Permission{
+		Scheme:  permission.PermAppReadDeploy,
+		Context: permission.Context(permission.CtxApp, "other"),
+	})
+	request, err := http.NewRequest("GET", "/deploys/"+evts[0].UniqueID.Hex()+"/diff", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestSetDeployTimeouts(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("build=300&healthcheck=60")
+	request, err := http.NewRequest("PUT", "/apps/g1/deploy/timeouts", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployTimeouts, check.DeepEquals, map[string]int{"build": 300, "healthcheck": 60})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.deploy.timeouts",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":appname", "value": a.Name},
+			{"name": "build", "value": "300"},
+			{"name": "healthcheck", "value": "60"},
+		},
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("GET", "/apps/g1/deploy/timeouts", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var timeouts map[string]int
+	err = json.NewDecoder(recorder.Body).Decode(&timeouts)
+	c.Assert(err, check.IsNil)
+	c.Assert(timeouts, check.DeepEquals, map[string]int{
+		"build":       300,
+		"image-push":  0,
+		"unit-start":  0,
+		"healthcheck": 60,
+	})
+}
+
+func (s *DeploySuite) TestSetDeployTimeoutsInvalidValue(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	for _, body := range []string{"build=abc", "build=-1", "other=10"} {
+		request, err := http.NewRequest("PUT", "/apps/g1/deploy/timeouts", strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %s", body))
+	}
+}
+
+func (s *DeploySuite) TestSetDeployTimeoutsWithoutPermission(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "otherapp", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("PUT", "/apps/g1/deploy/timeouts", strings.NewReader("build=10"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *DeploySuite) TestSetDeployVerification(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("duration=300&maxErrorRate=5&maxLatency=500&minRequests=100")
+	request, err := http.NewRequest("PUT", "/apps/g1/deploy/verification", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	expected := &app.DeployVerification{Duration: 300, MaxErrorRate: 5, MaxLatency: 500, MinRequests: 100}
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployVerification, check.DeepEquals, expected)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.deploy.verification",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":appname", "value": a.Name},
+			{"name": "duration", "value": "300"},
+			{"name": "maxErrorRate", "value": "5"},
+			{"name": "maxLatency", "value": "500"},
+			{"name": "minRequests", "value": "100"},
+		},
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("GET", "/apps/g1/deploy/verification", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var verification app.DeployVerification
+	err = json.NewDecoder(recorder.Body).Decode(&verification)
+	c.Assert(err, check.IsNil)
+	c.Assert(&verification, check.DeepEquals, expected)
+}
+
+func (s *DeploySuite) TestSetDeployVerificationInvalid(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	for _, body := range []string{"duration=abc", "maxErrorRate=5", "duration=60", "duration=60&maxErrorRate=5&endpoint=myapp"} {
+		request, err := http.NewRequest("PUT", "/apps/g1/deploy/verification", strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %s", body))
+	}
+}
+
+func (s *DeploySuite) TestSetDeployVerificationWithoutPermission(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "otherapp", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("PUT", "/apps/g1/deploy/verification", strings.NewReader("duration=60&maxErrorRate=5"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *DeploySuite) TestRemoveDeployVerification(c *check.C) {
+	user, _ := s.token.User()
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployVerification(&app.DeployVerification{Duration: 60, MaxErrorRate: 5})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/apps/g1/deploy/verification", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployVerification, check.IsNil)
+	request, err = http.NewRequest("GET", "/apps/g1/deploy/verification", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
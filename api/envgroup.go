@@ -0,0 +1,359 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ajg/form"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/envgroup"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+)
+
+func envGroupTarget(name string) event.Target {
+	return event.Target{Type: event.TargetTypeEnvGroup, Value: name}
+}
+
+func getEnvGroup(name string) (*envgroup.EnvGroup, error) {
+	g, err := envgroup.Get(name)
+	if err == envgroup.ErrEnvGroupNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return g, err
+}
+
+func envGroupError(err error) error {
+	switch err {
+	case envgroup.ErrEnvGroupNotFound, envgroup.ErrAppNotSubscribed:
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case envgroup.ErrEnvGroupAlreadyExists, envgroup.ErrEnvGroupInUse:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if verr, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: verr.Message}
+	}
+	return err
+}
+
+// title: env group list
+// path: /env-groups
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func envGroupList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	contexts := permission.ContextsForPermission(t, permission.PermEnvGroupRead)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	teams := []string{}
+	for _, c := range contexts {
+		if c.CtxType == permission.CtxGlobal {
+			teams = nil
+			break
+		}
+		if c.CtxType == permission.CtxTeam {
+			teams = append(teams, c.Value)
+		}
+	}
+	groups, err := envgroup.List(teams)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(groups)
+}
+
+// title: env group create
+// path: /env-groups
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Env group created
+//   400: Invalid data
+//   401: Unauthorized
+//   409: Env group already exists
+func envGroupCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	name := r.FormValue("name")
+	team := r.FormValue("team")
+	allowed := permission.Check(t, permission.PermEnvGroupCreate,
+		permission.Context(permission.CtxTeam, team),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     envGroupTarget(name),
+		Kind:       permission.PermEnvGroupCreate,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermEnvGroupReadEvents, permission.Context(permission.CtxTeam, team)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	g, err := envgroup.Create(name, team)
+	if err != nil {
+		return envGroupError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(g)
+}
+
+// title: env group info
+// path: /env-groups/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Env group not found
+func envGroupInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	g, err := getEnvGroup(r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermEnvGroupRead,
+		permission.Context(permission.CtxTeam, g.Team),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(g)
+}
+
+// title: env group remove
+// path: /env-groups/{name}
+// method: DELETE
+// responses:
+//   200: Env group removed
+//   401: Unauthorized
+//   404: Env group not found
+//   409: Env group has subscribed apps
+func envGroupRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	g, err := getEnvGroup(r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermEnvGroupDelete,
+		permission.Context(permission.CtxTeam, g.Team),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     envGroupTarget(g.Name),
+		Kind:       permission.PermEnvGroupDelete,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermEnvGroupReadEvents, permission.Context(permission.CtxTeam, g.Team)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return envGroupError(envgroup.Remove(g.Name))
+}
+
+// title: set env group envs
+// path: /env-groups/{name}/env
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Envs updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Env group not found
+func envGroupEnvSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var e apiTypes.Envs
+	dec := form.NewDecoder(nil)
+	dec.IgnoreUnknownKeys(true)
+	err = dec.DecodeValues(&e, r.Form)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if len(e.Envs) == 0 {
+		msg := "You must provide the list of environment variables"
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	if e.Private {
+		for i := 0; i < len(e.Envs); i++ {
+			r.Form.Set(fmt.Sprintf("Envs.%d.Value", i), "*****")
+		}
+	}
+	variables := make([]bind.EnvVar, len(e.Envs))
+	for i, v := range e.Envs {
+		variables[i] = bind.EnvVar{Name: v.Name, Value: v.Value, Public: !e.Private}
+	}
+	return updateEnvGroupEnvs(w, r, t, permission.PermEnvGroupUpdateEnvSet, !e.NoRestart, func(name string) error {
+		return envgroup.SetEnvs(name, variables)
+	})
+}
+
+// title: unset env group envs
+// path: /env-groups/{name}/env
+// method: DELETE
+// produce: application/x-json-stream
+// responses:
+//   200: Envs removed
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Env group not found
+func envGroupEnvUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	variables := r.Form["env"]
+	if len(variables) == 0 {
+		msg := "You must provide the list of environment variables."
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	noRestart, _ := strconv.ParseBool(r.FormValue("noRestart"))
+	return updateEnvGroupEnvs(w, r, t, permission.PermEnvGroupUpdateEnvUnset, !noRestart, func(name string) error {
+		return envgroup.UnsetEnvs(name, variables)
+	})
+}
+
+// updateEnvGroupEnvs runs fn and rolls the change out to the apps subscribed
+// to the env group, restarting them one by one while streaming the progress.
+// The event of the env group holds the report of the restarted apps.
+func updateEnvGroupEnvs(w http.ResponseWriter, r *http.Request, t auth.Token, scheme *permission.PermissionScheme, restart bool, fn func(name string) error) (err error) {
+	g, err := getEnvGroup(r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	ctx := permission.Context(permission.CtxTeam, g.Team)
+	if !permission.Check(t, scheme, ctx) {
+		return permission.ErrUnauthorized
+	}
+	allowedEvents := event.Allowed(permission.PermEnvGroupReadEvents, ctx)
+	if !restart || len(g.Apps) == 0 {
+		var evt *event.Event
+		evt, err = event.New(&event.Opts{
+			Target:     envGroupTarget(g.Name),
+			Kind:       scheme,
+			Owner:      t,
+			CustomData: event.FormToCustomData(r.Form),
+			Allowed:    allowedEvents,
+		})
+		if err != nil {
+			return err
+		}
+		defer func() { evt.Done(err) }()
+		return envGroupError(fn(g.Name))
+	}
+	err = fn(g.Name)
+	if err != nil {
+		return envGroupError(err)
+	}
+	filter := &app.Filter{}
+	for _, name := range g.Apps {
+		filter.ExtraIn("name", name)
+	}
+	apps, err := app.List(filter)
+	if err != nil {
+		return err
+	}
+	return runBulkOnApps(w, r, t, scheme, apps, envGroupTarget(g.Name), allowedEvents, nil, func(a *app.App, evt *event.Event, w io.Writer) error {
+		return a.Restart("", w)
+	})
+}
+
+// title: subscribe app to env group
+// path: /apps/{app}/env-groups/{name}
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: App subscribed
+//   401: Unauthorized
+//   404: App or env group not found
+func appEnvGroupSubscribe(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return updateAppEnvGroup(w, r, t, permission.PermAppUpdateEnvGroupSubscribe, envgroup.Subscribe)
+}
+
+// title: unsubscribe app from env group
+// path: /apps/{app}/env-groups/{name}
+// method: DELETE
+// produce: application/x-json-stream
+// responses:
+//   200: App unsubscribed
+//   401: Unauthorized
+//   404: App or env group not found
+func appEnvGroupUnsubscribe(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return updateAppEnvGroup(w, r, t, permission.PermAppUpdateEnvGroupUnsubscribe, envgroup.Unsubscribe)
+}
+
+func updateAppEnvGroup(w http.ResponseWriter, r *http.Request, t auth.Token, scheme *permission.PermissionScheme, fn func(name, appName string) error) (err error) {
+	r.ParseForm()
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, scheme, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	g, err := getEnvGroup(r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermEnvGroupRead,
+		permission.Context(permission.CtxTeam, g.Team),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(appName),
+		ExtraTargets: []event.ExtraTarget{{Target: envGroupTarget(g.Name)}},
+		Kind:         scheme,
+		Owner:        t,
+		CustomData:   event.FormToCustomData(r.Form),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = fn(g.Name, a.Name)
+	if err != nil {
+		return envGroupError(err)
+	}
+	noRestart, _ := strconv.ParseBool(r.FormValue("noRestart"))
+	if noRestart {
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	return a.Restart("", writer)
+}
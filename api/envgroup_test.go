@@ -0,0 +1,287 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/envgroup"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestEnvGroupCreate(c *check.C) {
+	body := strings.NewReader("name=shared&team=" + s.team.Name)
+	request, err := http.NewRequest("POST", "/1.6/env-groups", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	g, err := envgroup.Get("shared")
+	c.Assert(err, check.IsNil)
+	c.Assert(g.Team, check.Equals, s.team.Name)
+	c.Assert(eventtest.EventDesc{
+		Target: envGroupTarget("shared"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "env-group.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": "shared"},
+			{"name": "team", "value": s.team.Name},
+		},
+	}, eventtest.HasEvent)
+	body = strings.NewReader("name=shared&team=" + s.team.Name)
+	request, err = http.NewRequest("POST", "/1.6/env-groups", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestEnvGroupCreateUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermEnvGroupCreate,
+		Context: permission.Context(permission.CtxTeam, "otherteam"),
+	})
+	body := strings.NewReader("name=shared&team=" + s.team.Name)
+	request, err := http.NewRequest("POST", "/1.6/env-groups", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestEnvGroupList(c *check.C) {
+	_, err := envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/env-groups", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var groups []envgroup.EnvGroup
+	err = json.NewDecoder(recorder.Body).Decode(&groups)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 1)
+	c.Assert(groups[0].Name, check.Equals, "shared")
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermEnvGroupRead,
+		Context: permission.Context(permission.CtxTeam, "otherteam"),
+	})
+	request, err = http.NewRequest("GET", "/1.6/env-groups", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestEnvGroupInfo(c *check.C) {
+	_, err := envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	err = envgroup.SetEnvs("shared", []bind.EnvVar{{Name: "A", Value: "1", Public: true}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/env-groups/shared", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var g envgroup.EnvGroup
+	err = json.NewDecoder(recorder.Body).Decode(&g)
+	c.Assert(err, check.IsNil)
+	c.Assert(g.Envs, check.DeepEquals, []bind.EnvVar{{Name: "A", Value: "1", Public: true}})
+	request, err = http.NewRequest("GET", "/1.6/env-groups/other", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestEnvGroupRemove(c *check.C) {
+	_, err := envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	err = envgroup.Subscribe("shared", "myapp")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/env-groups/shared", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	err = envgroup.Unsubscribe("shared", "myapp")
+	c.Assert(err, check.IsNil)
+	request, err = http.NewRequest("DELETE", "/1.6/env-groups/shared", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = envgroup.Get("shared")
+	c.Assert(err, check.Equals, envgroup.ErrEnvGroupNotFound)
+}
+
+func (s *S) TestEnvGroupEnvSetRollsOutToApps(c *check.C) {
+	a1 := app.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := app.App{Name: "app2", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(&a2, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	err = envgroup.Subscribe("shared", a1.Name)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("Envs.0.Name=DATABASE_HOST&Envs.0.Value=db1")
+	request, err := http.NewRequest("POST", "/1.6/env-groups/shared/env", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	report := decodeBulkReport(c, recorder.Body.String())
+	c.Assert(report.Total, check.Equals, 1)
+	c.Assert(report.Succeeded, check.Equals, 1)
+	c.Assert(s.provisioner.Restarts(&a1, ""), check.Equals, 1)
+	c.Assert(s.provisioner.Restarts(&a2, ""), check.Equals, 0)
+	dbApp, err := app.GetByName(a1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Envs()["DATABASE_HOST"], check.DeepEquals, bind.EnvVar{Name: "DATABASE_HOST", Value: "db1", Public: true})
+	c.Assert(eventtest.EventDesc{
+		Target: envGroupTarget("shared"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "env-group.update.env.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": "Envs.0.Name", "value": "DATABASE_HOST"},
+			{"name": "Envs.0.Value", "value": "db1"},
+		},
+	}, eventtest.HasEvent)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a1.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "env-group.update.env.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": "Envs.0.Name", "value": "DATABASE_HOST"},
+			{"name": "Envs.0.Value", "value": "db1"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestEnvGroupEnvUnsetNoRestart(c *check.C) {
+	a := app.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	err = envgroup.SetEnvs("shared", []bind.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}})
+	c.Assert(err, check.IsNil)
+	err = envgroup.Subscribe("shared", a.Name)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/env-groups/shared/env?env=A&noRestart=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+	g, err := envgroup.Get("shared")
+	c.Assert(err, check.IsNil)
+	c.Assert(g.Envs, check.DeepEquals, []bind.EnvVar{{Name: "B", Value: "2"}})
+	c.Assert(eventtest.EventDesc{
+		Target: envGroupTarget("shared"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "env-group.update.env.unset",
+		StartCustomData: []map[string]interface{}{
+			{"name": "env", "value": "A"},
+			{"name": "noRestart", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppEnvGroupSubscribe(c *check.C) {
+	a := app.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/apps/app1/env-groups/shared", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 1)
+	groups, err := envgroup.ListByApp(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 1)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.env-group.subscribe",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppEnvGroupUnsubscribe(c *check.C) {
+	a := app.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	err = envgroup.Subscribe("shared", a.Name)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/app1/env-groups/shared?noRestart=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+	groups, err := envgroup.ListByApp(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
+	request, err = http.NewRequest("DELETE", "/1.6/apps/app1/env-groups/shared", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppEnvGroupSubscribeWithoutGroupPermission(c *check.C) {
+	a := app.App{Name: "app1", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateEnvGroupSubscribe,
+		Context: permission.Context(permission.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("POST", "/1.6/apps/app1/env-groups/shared", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	groups, err := envgroup.ListByApp(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
+}
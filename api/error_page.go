@@ -0,0 +1,149 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+)
+
+func errorPageStatusCode(r *http.Request) (int, error) {
+	statusCode, err := strconv.Atoi(r.URL.Query().Get(":status"))
+	if err != nil {
+		return 0, &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid status code, it must be a number"}
+	}
+	return statusCode, nil
+}
+
+// title: app error page
+// path: /apps/{app}/error-pages/{status}
+// method: GET
+// responses:
+//   200: OK
+//   400: Invalid status code
+//   401: Unauthorized
+//   404: App or error page not found
+func appErrorPageGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	statusCode, err := errorPageStatusCode(r)
+	if err != nil {
+		return err
+	}
+	page, err := a.ErrorPage(statusCode)
+	if err == app.ErrErrorPageNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", page.ContentType)
+	_, err = w.Write([]byte(page.Body))
+	return err
+}
+
+// title: set app error page
+// path: /apps/{app}/error-pages/{status}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Error page set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appErrorPageSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	statusCode, err := errorPageStatusCode(r)
+	if err != nil {
+		return err
+	}
+	page := router.ErrorPage{
+		StatusCode:  statusCode,
+		ContentType: r.FormValue("contentType"),
+		Body:        r.FormValue("content"),
+	}
+	err = page.Validate()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return updateAppErrorPage(r, t, func(a *app.App) error {
+		return a.SetErrorPage(page)
+	})
+}
+
+// title: unset app error page
+// path: /apps/{app}/error-pages/{status}
+// method: DELETE
+// responses:
+//   200: Error page removed
+//   400: Invalid status code
+//   401: Unauthorized
+//   404: App or error page not found
+func appErrorPageUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	statusCode, err := errorPageStatusCode(r)
+	if err != nil {
+		return err
+	}
+	return updateAppErrorPage(r, t, func(a *app.App) error {
+		err := a.RemoveErrorPage(statusCode)
+		if err == app.ErrErrorPageNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	})
+}
+
+func updateAppErrorPage(r *http.Request, t auth.Token, update func(*app.App) error) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateErrorPage,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	// The page content is left out of the event, as it may be large.
+	form := url.Values{}
+	for k, v := range r.Form {
+		if k != "content" {
+			form[k] = v
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateErrorPage,
+		Owner:      t,
+		CustomData: event.FormToCustomData(form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return update(&a)
+}
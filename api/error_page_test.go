@@ -0,0 +1,105 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppErrorPageSet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-errorpage"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(url.Values{"content": {"<h1>maintenance</h1>"}}.Encode())
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/error-pages/503", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	expected := []router.ErrorPage{{StatusCode: 503, ContentType: router.DefaultErrorPageContentType, Body: "<h1>maintenance</h1>"}}
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ErrorPages, check.DeepEquals, expected)
+	c.Assert(routertest.ErrorPageRouter.Pages["myapp"], check.DeepEquals, expected)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.error-page",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": "myapp"},
+			{"name": ":status", "value": "503"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppErrorPageSetInvalidStatus(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("content=oops")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/error-pages/500", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "Invalid status code 500, error pages are only available for [502 503]\n")
+}
+
+func (s *S) TestAppErrorPageGet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 502, ContentType: "text/plain", Body: "bad gateway"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/error-pages/502", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/plain")
+	c.Assert(recorder.Body.String(), check.Equals, "bad gateway")
+	request, err = http.NewRequest("GET", "/1.6/apps/myapp/error-pages/503", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppErrorPageUnset(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-errorpage"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 503, Body: "unavailable"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/error-pages/503", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ErrorPages, check.HasLen, 0)
+	c.Assert(routertest.ErrorPageRouter.Pages, check.HasLen, 0)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
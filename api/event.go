@@ -44,6 +44,7 @@ func eventList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
+	filter.UseReadPreference = true
 	events, err := event.List(filter)
 	if err != nil {
 		return err
@@ -76,6 +77,42 @@ func kindList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(kinds)
 }
 
+// title: event custom data schema list
+// path: /events/schemas
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+func eventSchemaList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	schemas := event.CustomDataSchemas()
+	if len(schemas) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Add("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(schemas)
+}
+
+// title: event custom data schema info
+// path: /events/schemas/{kind}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   404: Not found
+func eventSchemaInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	schema, err := event.GetCustomDataSchema(r.URL.Query().Get(":kind"))
+	if err == event.ErrSchemaNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Add("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(schema)
+}
+
 // title: event info
 // path: /events/{uuid}
 // method: GET
@@ -266,6 +266,53 @@ func (s *EventSuite) TestKindListNoContent(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
 }
 
+func (s *EventSuite) TestEventSchemaList(c *check.C) {
+	request, err := http.NewRequest("GET", "/events/schemas", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result []event.SchemaDescription
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	var kinds []string
+	for _, schema := range result {
+		kinds = append(kinds, schema.Kind)
+	}
+	c.Assert(kinds, check.DeepEquals, []string{"app.build", "app.deploy"})
+}
+
+func (s *EventSuite) TestEventSchemaInfo(c *check.C) {
+	request, err := http.NewRequest("GET", "/events/schemas/app.deploy", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result event.SchemaDescription
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Kind, check.Equals, "app.deploy")
+	c.Assert(result.Version, check.Equals, 1)
+	c.Assert(result.Start.Type, check.Equals, "object")
+	c.Assert(result.End, check.DeepEquals, &event.SchemaField{Type: "map", Elem: &event.SchemaField{Type: "string"}})
+	c.Assert(result.Other, check.IsNil)
+}
+
+func (s *EventSuite) TestEventSchemaInfoNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/events/schemas/app.unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *EventSuite) TestEventInfoInvalidObjectID(c *check.C) {
 	u := fmt.Sprintf("/events/%s", "123")
 	request, err := http.NewRequest("GET", u, nil)
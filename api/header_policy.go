@@ -0,0 +1,150 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+)
+
+type appRouterPolicy struct {
+	Policy  *router.HeaderPolicy `json:"policy"`
+	Routers []string             `json:"routers"`
+}
+
+// headerPolicyFromForm reads a header policy from the fields header, in the
+// "Name: value" format, cors.origin, cors.method, cors.allowHeader,
+// cors.exposeHeader, cors.allowCredentials and cors.maxAge.
+func headerPolicyFromForm(r *http.Request) (*router.HeaderPolicy, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	policy := &router.HeaderPolicy{}
+	for _, header := range r.Form["header"] {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid header %q, it must be in the format Name: value", header)}
+		}
+		if policy.Headers == nil {
+			policy.Headers = make(map[string]string)
+		}
+		policy.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	if origins := r.Form["cors.origin"]; len(origins) > 0 {
+		policy.CORS = &router.CORSPolicy{
+			AllowedOrigins: origins,
+			AllowedMethods: r.Form["cors.method"],
+			AllowedHeaders: r.Form["cors.allowHeader"],
+			ExposedHeaders: r.Form["cors.exposeHeader"],
+		}
+		policy.CORS.AllowCredentials, _ = strconv.ParseBool(r.FormValue("cors.allowCredentials"))
+		if maxAge := r.FormValue("cors.maxAge"); maxAge != "" {
+			policy.CORS.MaxAge, err = strconv.Atoi(maxAge)
+			if err != nil {
+				return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid CORS max-age, it must be a number of seconds"}
+			}
+		}
+	}
+	err = policy.Validate()
+	if err != nil {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return policy, nil
+}
+
+// title: app router policy
+// path: /apps/{app}/router-policy
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+func appRouterPolicyGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadRouterPolicy,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	routers, err := a.HeaderPolicyRouters()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(appRouterPolicy{
+		Policy:  a.HeaderPolicy,
+		Routers: routers,
+	})
+}
+
+// title: set app router policy
+// path: /apps/{app}/router-policy
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Router policy set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appRouterPolicySet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	policy, err := headerPolicyFromForm(r)
+	if err != nil {
+		return err
+	}
+	return updateAppRouterPolicy(r, t, policy)
+}
+
+// title: unset app router policy
+// path: /apps/{app}/router-policy
+// method: DELETE
+// responses:
+//   200: Router policy removed
+//   401: Unauthorized
+//   404: App not found
+func appRouterPolicyUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	return updateAppRouterPolicy(r, t, nil)
+}
+
+func updateAppRouterPolicy(r *http.Request, t auth.Token, policy *router.HeaderPolicy) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateRouterPolicy,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateRouterPolicy,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetHeaderPolicy(policy)
+}
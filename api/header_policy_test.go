@@ -0,0 +1,120 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppRouterPolicySet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("header=X-Frame-Options:%20DENY&cors.origin=https://example.com&cors.method=GET&cors.method=POST&cors.maxAge=600")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/router-policy", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	expected := &router.HeaderPolicy{
+		CORS: &router.CORSPolicy{
+			AllowedOrigins: []string{"https://example.com"},
+			AllowedMethods: []string{"GET", "POST"},
+			MaxAge:         600,
+		},
+		Headers: map[string]string{"X-Frame-Options": "DENY"},
+	}
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.HeaderPolicy, check.DeepEquals, expected)
+	c.Assert(routertest.TLSRouter.Headers["myapp"], check.DeepEquals, expected)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.router-policy",
+		StartCustomData: []map[string]interface{}{
+			{"name": "header", "value": "X-Frame-Options: DENY"},
+			{"name": "cors.origin", "value": "https://example.com"},
+			{"name": "cors.method", "value": []string{"GET", "POST"}},
+			{"name": "cors.maxAge", "value": "600"},
+			{"name": ":app", "value": "myapp"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppRouterPolicySetInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		body string
+		msg  string
+	}{
+		{"header=X-Frame-Options", "Invalid header \"X-Frame-Options\", it must be in the format Name: value\n"},
+		{"header=Transfer-Encoding:chunked", "Header \"Transfer-Encoding\" is managed by the router and cannot be set\n"},
+		{"cors.origin=*&cors.allowCredentials=true", "CORS policy cannot allow credentials for any origin\n"},
+		{"cors.origin=*&cors.maxAge=a", "Invalid CORS max-age, it must be a number of seconds\n"},
+	}
+	for _, tt := range tests {
+		request, err := http.NewRequest("PUT", "/1.6/apps/myapp/router-policy", strings.NewReader(tt.body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf(tt.body))
+		c.Assert(recorder.Body.String(), check.Equals, tt.msg)
+	}
+}
+
+func (s *S) TestAppRouterPolicyGet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake"}, {Name: "fake-tls"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	policy := &router.HeaderPolicy{Headers: map[string]string{"X-Content-Type-Options": "nosniff"}}
+	err = a.SetHeaderPolicy(policy)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/router-policy", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result appRouterPolicy
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, appRouterPolicy{Policy: policy, Routers: []string{"fake-tls"}})
+}
+
+func (s *S) TestAppRouterPolicyUnset(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetHeaderPolicy(&router.HeaderPolicy{Headers: map[string]string{"X-Frame-Options": "DENY"}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/router-policy", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.HeaderPolicy, check.IsNil)
+	c.Assert(routertest.TLSRouter.Headers, check.HasLen, 0)
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/hibernation"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const defaultWakeTimeout = 5 * time.Minute
+
+func wakeTimeout() time.Duration {
+	timeout, _ := config.GetInt("hibernation:wake-timeout")
+	if timeout <= 0 {
+		return defaultWakeTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// title: wake up hibernated app
+// path: /apps/{app}/wakeup
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: App awake
+//   204: App not hibernated
+//   401: Unauthorized
+//   404: App not found
+//   504: Timeout waiting for app to wake up
+func wakeApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateStart,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	hibernated, err := hibernation.IsHibernated(a.Name)
+	if err != nil {
+		return err
+	}
+	if !hibernated {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateStart,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); !ok {
+			return err
+		}
+		// Another request is already waking up the app, the caller must
+		// be held until it finishes.
+		err = hibernation.WaitAwake(a.Name, wakeTimeout())
+		if err == hibernation.ErrWakeTimeout {
+			return &errors.HTTP{Code: http.StatusGatewayTimeout, Message: err.Error()}
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	return hibernation.Wake(&a, writer)
+}
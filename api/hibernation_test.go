@@ -0,0 +1,51 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/hibernation"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestWakeAppNotHibernated(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/wakeup", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	c.Assert(s.provisioner.Starts(&a, ""), check.Equals, 0)
+}
+
+func (s *S) TestWakeAppHibernated(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.conn.Collection("app_hibernation").Insert(hibernation.State{App: a.Name, Hibernated: true})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/wakeup", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(s.provisioner.Starts(&a, ""), check.Equals, 1)
+	hibernated, err := hibernation.IsHibernated(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(hibernated, check.Equals, false)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.start",
+	}, eventtest.HasEvent)
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+const (
+	defaultImpersonationDuration    = 15 * time.Minute
+	defaultImpersonationMaxDuration = time.Hour
+)
+
+// errImpersonationNotAllowed is returned by actions that would give the
+// impersonator access to the user beyond the impersonation token, like
+// API tokens and SSH keys.
+var errImpersonationNotAllowed = &errors.HTTP{
+	Code:    http.StatusForbidden,
+	Message: "This action is not allowed while impersonating a user.",
+}
+
+func impersonationDuration(r *http.Request) (time.Duration, error) {
+	maxDuration := defaultImpersonationMaxDuration
+	if seconds, _ := config.GetInt("auth:impersonation:max-duration"); seconds > 0 {
+		maxDuration = time.Duration(seconds) * time.Second
+	}
+	duration := defaultImpersonationDuration
+	if value := r.FormValue("duration"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return 0, &errors.HTTP{Code: http.StatusBadRequest, Message: "duration must be a positive number of seconds"}
+		}
+		duration = time.Duration(seconds) * time.Second
+	}
+	if duration > maxDuration {
+		duration = maxDuration
+	}
+	return duration, nil
+}
+
+// title: impersonate user
+// path: /users/{email}/impersonate
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Token created
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   404: User not found
+func impersonateUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	email := r.URL.Query().Get(":email")
+	allowed := permission.Check(t, permission.PermUserImpersonate,
+		permission.Context(permission.CtxUser, email),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	if t.IsAppToken() || auth.ImpersonatorOf(t) != "" {
+		return errImpersonationNotAllowed
+	}
+	reason := r.FormValue("reason")
+	if reason == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "a reason is required to impersonate users"}
+	}
+	duration, err := impersonationDuration(r)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserImpersonate,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	impersonator, err := t.User()
+	if err != nil {
+		return err
+	}
+	user, err := auth.GetUserByEmail(email)
+	if err == authTypes.ErrUserNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	// Impersonation must not grant access the impersonator doesn't
+	// already have, so users with more permissions can't be impersonated.
+	impersonatorPerms, err := t.Permissions()
+	if err != nil {
+		return err
+	}
+	userPerms, err := user.Permissions()
+	if err != nil {
+		return err
+	}
+	for _, p := range userPerms {
+		if !permission.CheckFromPermList(impersonatorPerms, p.Scheme, p.Context) {
+			return &errors.HTTP{
+				Code:    http.StatusForbidden,
+				Message: fmt.Sprintf("unable to impersonate user with permissions you don't have: %s", p.String()),
+			}
+		}
+	}
+	token, err := auth.Impersonate(impersonator, user, reason, duration)
+	if err != nil {
+		return handleAuthError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(token)
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"gopkg.in/check.v1"
+)
+
+func (s *AuthSuite) impersonate(c *check.C, token auth.Token, email, body string) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(http.MethodPost, "/users/"+email+"/impersonate", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *AuthSuite) TestImpersonateUser(c *check.C) {
+	user, _ := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxGlobal, ""),
+	})
+	recorder := s.impersonate(c, s.token, user.Email, "reason=ticket+123")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var token auth.ImpersonationToken
+	err := json.NewDecoder(recorder.Body).Decode(&token)
+	c.Assert(err, check.IsNil)
+	c.Assert(token.UserEmail, check.Equals, user.Email)
+	c.Assert(token.Impersonator, check.Equals, s.user.Email)
+	c.Assert(token.Expires.Sub(token.Creation), check.Equals, defaultImpersonationDuration)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(user.Email),
+		Owner:  s.user.Email,
+		Kind:   "user.impersonate",
+		StartCustomData: []map[string]interface{}{
+			{"name": "reason", "value": "ticket 123"},
+			{"name": ":email", "value": user.Email},
+		},
+	}, eventtest.HasEvent)
+	request, err := http.NewRequest(http.MethodDelete, "/users/sessions/unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.Token)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	evts, err := event.List(&event.Filter{Impersonator: s.user.Email})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Owner, check.DeepEquals, event.Owner{
+		Type:         event.OwnerTypeUser,
+		Name:         user.Email,
+		Impersonator: s.user.Email,
+	})
+}
+
+func (s *AuthSuite) TestImpersonateUserDuration(c *check.C) {
+	config.Set("auth:impersonation:max-duration", 60)
+	defer config.Unset("auth:impersonation:max-duration")
+	user, _ := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	recorder := s.impersonate(c, s.token, user.Email, "reason=ticket&duration=3600")
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var token auth.ImpersonationToken
+	err := json.NewDecoder(recorder.Body).Decode(&token)
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Expires.Sub(token.Creation), check.Equals, time.Minute)
+	recorder = s.impersonate(c, s.token, user.Email, "reason=ticket&duration=abc")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *AuthSuite) TestImpersonateUserRequiresReason(c *check.C) {
+	user, _ := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	recorder := s.impersonate(c, s.token, user.Email, "")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *AuthSuite) TestImpersonateUserRequiresPermission(c *check.C) {
+	user, _ := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "support")
+	recorder := s.impersonate(c, token, user.Email, "reason=ticket")
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *AuthSuite) TestImpersonateUserWithMorePermissions(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "support", permission.Permission{
+		Scheme:  permission.PermUserImpersonate,
+		Context: permission.Context(permission.CtxGlobal, ""),
+	})
+	recorder := s.impersonate(c, token, s.user.Email, "reason=ticket")
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Matches, "unable to impersonate user with permissions you don't have.*\n")
+}
+
+func (s *AuthSuite) TestImpersonationTokenRestrictions(c *check.C) {
+	user, _ := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	token, err := auth.Impersonate(s.user, user, "ticket", time.Minute)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/users/api-key", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.Token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	recorder = s.impersonate(c, token, "other@globo.com", "reason=ticket")
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *AuthSuite) TestLogoutEndsImpersonation(c *check.C) {
+	user, _ := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	token, err := auth.Impersonate(s.user, user, "ticket", time.Minute)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/users/tokens", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.Token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = auth.ImpersonationAuth(token.Token)
+	c.Assert(err, check.Equals, auth.ErrInvalidToken)
+}
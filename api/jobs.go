@@ -0,0 +1,174 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/buildlog"
+	"github.com/tsuru/tsuru/app/orphan"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// registerJobs registers the platform jobs not owned by provisioners.
+func registerJobs() error {
+	for _, register := range []func() error{
+		buildlog.RegisterPruneJob,
+		orphan.RegisterImageGCJob,
+		app.RegisterCertificatesCheckJob,
+	} {
+		err := register()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// title: list jobs
+// path: /jobs
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func jobList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermJobRead) {
+		return permission.ErrUnauthorized
+	}
+	lst, err := jobs.List()
+	if err != nil {
+		return err
+	}
+	if len(lst) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(lst)
+}
+
+// title: job info
+// path: /jobs/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Job not found
+func jobInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermJobRead) {
+		return permission.ErrUnauthorized
+	}
+	info, err := jobs.Get(r.URL.Query().Get(":name"))
+	if err == jobs.ErrJobNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(info)
+}
+
+// title: update job
+// path: /jobs/{name}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Job not found
+func jobUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermJobUpdate) {
+		return permission.ErrUnauthorized
+	}
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	name := r.URL.Query().Get(":name")
+	var opts jobs.UpdateOptions
+	if value := r.FormValue("enabled"); value != "" {
+		enabled, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "enabled must be a boolean"}
+		}
+		opts.Enabled = &enabled
+	}
+	if _, ok := r.Form["schedule"]; ok {
+		schedule := r.FormValue("schedule")
+		opts.Schedule = &schedule
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     jobs.Target(name),
+		Kind:       permission.PermJobUpdate,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermJobReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = jobs.Update(name, opts)
+	if err == jobs.ErrJobNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: run job
+// path: /jobs/{name}/run
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Job not found
+//   409: Job already running
+func jobRun(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermJobRun) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":name")
+	_, err = jobs.Get(name)
+	if err == jobs.ErrJobNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  jobs.Target(name),
+		Kind:    permission.PermJobRun,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermJobReadEvents),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 15*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return jobs.Run(r.Context(), name, evt)
+}
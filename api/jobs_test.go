@@ -0,0 +1,175 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) registerTestJob(c *check.C, runErr error) {
+	err := jobs.Register(jobs.Job{
+		Name:        "test-job",
+		Description: "my test job",
+		Schedule:    "@hourly",
+		Disabled:    true,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			evt.Logf("running test job")
+			return runErr
+		},
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestJobList(c *check.C) {
+	s.registerTestJob(c, nil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermJobRead,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/jobs", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var lst []jobs.Info
+	err = json.NewDecoder(recorder.Body).Decode(&lst)
+	c.Assert(err, check.IsNil)
+	var found *jobs.Info
+	for i := range lst {
+		if lst[i].Name == "test-job" {
+			found = &lst[i]
+		}
+	}
+	c.Assert(found, check.NotNil)
+	c.Assert(found.Description, check.Equals, "my test job")
+	c.Assert(found.Schedule, check.Equals, "@hourly")
+	c.Assert(found.Enabled, check.Equals, false)
+}
+
+func (s *S) TestJobListWithoutPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/jobs", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestJobInfo(c *check.C) {
+	s.registerTestJob(c, nil)
+	request, err := http.NewRequest("GET", "/jobs/test-job", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var info jobs.Info
+	err = json.NewDecoder(recorder.Body).Decode(&info)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Name, check.Equals, "test-job")
+}
+
+func (s *S) TestJobInfoNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/jobs/unknown-job", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestJobUpdate(c *check.C) {
+	s.registerTestJob(c, nil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermJobUpdate,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	body := strings.NewReader("enabled=true&schedule=@daily")
+	request, err := http.NewRequest("PUT", "/jobs/test-job", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	info, err := jobs.Get("test-job")
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Enabled, check.Equals, true)
+	c.Assert(info.Schedule, check.Equals, "@daily")
+	c.Assert(eventtest.EventDesc{
+		Target: jobs.Target("test-job"),
+		Owner:  token.GetUserName(),
+		Kind:   "job.update",
+		StartCustomData: []map[string]interface{}{
+			{"name": "enabled", "value": "true"},
+			{"name": "schedule", "value": "@daily"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestJobUpdateInvalidSchedule(c *check.C) {
+	s.registerTestJob(c, nil)
+	body := strings.NewReader("schedule=invalid")
+	request, err := http.NewRequest("PUT", "/jobs/test-job", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestJobRun(c *check.C) {
+	s.registerTestJob(c, errors.New("my job error"))
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermJobRun,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("POST", "/jobs/test-job/run", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*running test job.*my job error.*`)
+	info, err := jobs.Get("test-job")
+	c.Assert(err, check.IsNil)
+	c.Assert(info.LastError, check.Equals, "my job error")
+	c.Assert(eventtest.EventDesc{
+		Target:       jobs.Target("test-job"),
+		Owner:        token.GetUserName(),
+		Kind:         "job.run",
+		LogMatches:   "running test job",
+		ErrorMatches: "my job error",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestJobRunNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/jobs/unknown-job/run", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -95,16 +95,16 @@ loop:
 	c.Assert(err, check.IsNil)
 	sort.Sort(LogList(logs))
 	compareLogs(c, logs, []app.Applog{
-		{Date: baseTime, Message: "msg1", Source: "web", AppName: "myapp1", Unit: "unit1"},
-		{Date: baseTime.Add(2 * time.Second), Message: "msg3", Source: "web", AppName: "myapp1", Unit: "unit3"},
-		{Date: baseTime.Add(4 * time.Second), Message: "msg5", Source: "worker", AppName: "myapp1", Unit: "unit3"},
+		{Date: baseTime, Message: "msg1", Source: "web", AppName: "myapp1", Unit: "unit1", Type: "app"},
+		{Date: baseTime.Add(2 * time.Second), Message: "msg3", Source: "web", AppName: "myapp1", Unit: "unit3", Type: "app"},
+		{Date: baseTime.Add(4 * time.Second), Message: "msg5", Source: "worker", AppName: "myapp1", Unit: "unit3", Type: "app"},
 	})
 	logs, err = a2.LastLogs(2, app.Applog{})
 	c.Assert(err, check.IsNil)
 	sort.Sort(LogList(logs))
 	compareLogs(c, logs, []app.Applog{
-		{Date: baseTime.Add(time.Second), Message: "msg2", Source: "web", AppName: "myapp2", Unit: "unit2"},
-		{Date: baseTime.Add(3 * time.Second), Message: "msg4", Source: "web", AppName: "myapp2", Unit: "unit4"},
+		{Date: baseTime.Add(time.Second), Message: "msg2", Source: "web", AppName: "myapp2", Unit: "unit2", Type: "app"},
+		{Date: baseTime.Add(3 * time.Second), Message: "msg4", Source: "web", AppName: "myapp2", Unit: "unit4", Type: "app"},
 	})
 }
 
@@ -168,7 +168,7 @@ loop:
 	logs, err := a1.LastLogs(1, app.Applog{})
 	c.Assert(err, check.IsNil)
 	compareLogs(c, logs, []app.Applog{
-		{Date: baseTime, Message: "msg1", Source: "web", AppName: "myapp1", Unit: "unit1"},
+		{Date: baseTime, Message: "msg1", Source: "web", AppName: "myapp1", Unit: "unit1", Type: "app"},
 	})
 }
 
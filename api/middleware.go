@@ -38,7 +38,10 @@ func validate(token string, r *http.Request) (auth.Token, error) {
 	if err != nil {
 		t, err = auth.APIAuth(token)
 		if err != nil {
-			return nil, err
+			t, err = auth.ImpersonationAuth(token)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 	if t.IsAppToken() {
@@ -0,0 +1,94 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+)
+
+// title: set app traffic mirror
+// path: /apps/{app}/mirror
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Mirror set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appMirrorSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	mirror := &router.Mirror{Target: r.FormValue("target")}
+	mirror.Percentage, err = strconv.Atoi(r.FormValue("percentage"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid mirror percentage, it must be between 1 and 100"}
+	}
+	err = mirror.Validate()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	target, err := app.GetByName(mirror.Target)
+	if err != nil {
+		if err == app.ErrAppNotFound {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	canReadTarget := permission.Check(t, permission.PermAppRead,
+		contextsForApp(target)...,
+	)
+	if !canReadTarget {
+		return permission.ErrUnauthorized
+	}
+	return updateAppMirror(r, t, mirror)
+}
+
+// title: unset app traffic mirror
+// path: /apps/{app}/mirror
+// method: DELETE
+// responses:
+//   200: Mirror removed
+//   401: Unauthorized
+//   404: App not found
+func appMirrorUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	return updateAppMirror(r, t, nil)
+}
+
+func updateAppMirror(r *http.Request, t auth.Token, mirror *router.Mirror) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateMirror,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateMirror,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetMirror(mirror)
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppMirrorSet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	target := app.App{Name: "myapp-next", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err = app.CreateApp(&target, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("target=myapp-next&percentage=10")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/mirror", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	expected := router.Mirror{Target: "myapp-next", Percentage: 10}
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Mirror, check.DeepEquals, &expected)
+	c.Assert(routertest.MirrorRouter.Mirrors["myapp"], check.DeepEquals, expected)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.mirror",
+		StartCustomData: []map[string]interface{}{
+			{"name": "target", "value": "myapp-next"},
+			{"name": "percentage", "value": "10"},
+			{"name": ":app", "value": "myapp"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppMirrorSetInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		body string
+		msg  string
+	}{
+		{"target=myapp-next", "Invalid mirror percentage, it must be between 1 and 100\n"},
+		{"percentage=10", "Mirror target is required\n"},
+		{"target=unknown&percentage=10", "App not found.\n"},
+		{"target=myapp&percentage=10", "An app cannot mirror traffic to itself\n"},
+	}
+	for _, tt := range tests {
+		request, err := http.NewRequest("PUT", "/1.6/apps/myapp/mirror", strings.NewReader(tt.body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf(tt.body))
+		c.Assert(recorder.Body.String(), check.Equals, tt.msg)
+	}
+}
+
+func (s *S) TestAppMirrorSetTargetForbidden(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	target := app.App{Name: "myapp-next", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err = app.CreateApp(&target, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateMirror,
+		Context: permission.Context(permission.CtxApp, "myapp"),
+	})
+	body := strings.NewReader("target=myapp-next&percentage=10")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/mirror", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(routertest.MirrorRouter.Mirrors, check.HasLen, 0)
+}
+
+func (s *S) TestAppMirrorUnset(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	target := app.App{Name: "myapp-next", Platform: "zend", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err = app.CreateApp(&target, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetMirror(&router.Mirror{Target: "myapp-next", Percentage: 5})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/myapp/mirror", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Mirror, check.IsNil)
+	c.Assert(routertest.MirrorRouter.Mirrors, check.HasLen, 0)
+}
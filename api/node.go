@@ -25,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
 	"github.com/tsuru/tsuru/provision/pool"
 	apiTypes "github.com/tsuru/tsuru/types/api"
 )
@@ -196,6 +197,16 @@ func removeNodeHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 	if !allowedNodeRemove {
 		return permission.ErrUnauthorized
 	}
+	noRebalance, _ := strconv.ParseBool(r.URL.Query().Get("no-rebalance"))
+	if dry, _ := strconv.ParseBool(r.URL.Query().Get("dry")); dry {
+		var report *provision.DryRunReport
+		report, err = provision.RemoveNodeDry(node, !noRebalance)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(report)
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     event.Target{Type: event.TargetTypeNode, Value: node.Address()},
 		Kind:       permission.PermNodeDelete,
@@ -207,7 +218,6 @@ func removeNodeHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 		return err
 	}
 	defer func() { evt.Done(err) }()
-	noRebalance, _ := strconv.ParseBool(r.URL.Query().Get("no-rebalance"))
 	err = nodeProv.RemoveNode(provision.RemoveNodeOptions{
 		Address:   address,
 		Rebalance: !noRebalance,
@@ -725,6 +735,127 @@ func infoNodeHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error
 		Status: nodeStatus,
 		Units:  units,
 	}
+	if breakerNode, ok := node.(provision.NodeCircuitBreakerChecker); ok {
+		breaker := breakerNode.CircuitBreaker()
+		response.Breaker = &breaker
+	}
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(response)
 }
+
+// title: cluster topology
+// path: /node/topology
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func nodeTopologyHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	pools, err := permission.ListContextValues(t, permission.PermNodeRead, false)
+	if err != nil {
+		return err
+	}
+	provs, err := provision.Registry()
+	if err != nil {
+		return err
+	}
+	nodeContainers, err := loadNodeContainerPools()
+	if err != nil {
+		return err
+	}
+	statuses, err := healer.HealerInstance.ListNodeStatusData()
+	if err != nil {
+		return err
+	}
+	poolSet := map[string]struct{}{}
+	for _, p := range pools {
+		poolSet[p] = struct{}{}
+	}
+	topology := apiTypes.ClusterTopology{Timestamp: time.Now().UTC()}
+	for _, prov := range provs {
+		nodeProv, ok := prov.(provision.NodeProvisioner)
+		if !ok {
+			continue
+		}
+		var nodes []provision.Node
+		nodes, err = nodeProv.ListNodes(nil)
+		if err != nil {
+			return err
+		}
+		var unitsByNode map[string][]provision.Unit
+		if unitsProv, ok := prov.(provision.NodeUnitsProvisioner); ok {
+			unitsByNode, err = unitsProv.UnitsByNode()
+			if err != nil {
+				return err
+			}
+		}
+		for _, n := range nodes {
+			if pools != nil {
+				if _, ok := poolSet[n.Pool()]; !ok {
+					continue
+				}
+			}
+			var units []provision.Unit
+			if unitsByNode != nil {
+				units = unitsByNode[n.Address()]
+			} else {
+				units, err = n.Units()
+				if err != nil {
+					return err
+				}
+			}
+			topology.Nodes = append(topology.Nodes, apiTypes.TopologyNode{
+				Node:           provision.NodeToSpec(n),
+				Status:         statuses[n.Address()],
+				Units:          units,
+				NodeContainers: nodeContainersForPool(nodeContainers, n.Pool()),
+			})
+		}
+	}
+	if len(topology.Nodes) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(topology)
+}
+
+type nodeContainerPools struct {
+	name  string
+	pools map[string]nodecontainer.NodeContainerConfig
+}
+
+func loadNodeContainerPools() ([]nodeContainerPools, error) {
+	names, err := nodecontainer.AllNodeContainersNames()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]nodeContainerPools, len(names))
+	for i, name := range names {
+		confs, err := nodecontainer.LoadNodeContainersForPoolsMerge(name, true)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = nodeContainerPools{name: name, pools: confs}
+	}
+	return result, nil
+}
+
+func nodeContainersForPool(all []nodeContainerPools, pool string) []apiTypes.TopologyNodeContainer {
+	var result []apiTypes.TopologyNodeContainer
+	for _, nc := range all {
+		conf, ok := nc.pools[pool]
+		if !ok {
+			conf, ok = nc.pools[""]
+		}
+		if !ok || !conf.Valid() {
+			continue
+		}
+		result = append(result, apiTypes.TopologyNodeContainer{
+			Name:  nc.name,
+			Image: conf.Image(),
+		})
+	}
+	return result
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ajg/form"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+)
+
+// bulkNodeUpdateOptions holds the metadata set in every node selected either
+// by address or by the metadata in Filter. Empty metadata values remove the
+// metadata from the nodes.
+type bulkNodeUpdateOptions struct {
+	Address   []string `form:"-"`
+	Filter    map[string]string
+	Metadata  map[string]string
+	Rebalance bool
+}
+
+type bulkNode struct {
+	prov provision.NodeProvisioner
+	node provision.Node
+	pool string
+	evt  *event.Event
+}
+
+// bulkSelectNodes returns the nodes with the given addresses and the ones
+// whose metadata match the filter.
+func bulkSelectNodes(opts bulkNodeUpdateOptions) ([]bulkNode, error) {
+	var selected []bulkNode
+	seen := map[string]bool{}
+	for _, address := range opts.Address {
+		prov, node, err := provision.FindNode(address)
+		if err != nil {
+			if err == provision.ErrNodeNotFound {
+				return nil, &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("node %q not found", address)}
+			}
+			return nil, err
+		}
+		if !seen[node.Address()] {
+			seen[node.Address()] = true
+			selected = append(selected, bulkNode{prov: prov.(provision.NodeProvisioner), node: node, pool: node.Pool()})
+		}
+	}
+	if len(opts.Filter) == 0 {
+		return selected, nil
+	}
+	provs, err := provision.Registry()
+	if err != nil {
+		return nil, err
+	}
+	for _, prov := range provs {
+		nodeProv, ok := prov.(provision.NodeProvisioner)
+		if !ok {
+			continue
+		}
+		nodes, err := nodeProv.ListNodes(nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if seen[node.Address()] || !matchesNodeMetadata(node, opts.Filter) {
+				continue
+			}
+			seen[node.Address()] = true
+			selected = append(selected, bulkNode{prov: nodeProv, node: node, pool: node.Pool()})
+		}
+	}
+	return selected, nil
+}
+
+// bulkCheckPoolConstraints ensures the apps with units in nodes moving to
+// the given pool are allowed by its constraints. Units are only kept in
+// these nodes when no rebalance is requested, otherwise they are moved back
+// to nodes in their apps' pools.
+func bulkCheckPoolConstraints(nodes []bulkNode, p *pool.Pool) error {
+	checked := map[string]bool{}
+	for _, n := range nodes {
+		if n.pool == p.Name {
+			continue
+		}
+		units, err := n.node.Units()
+		if err != nil {
+			return err
+		}
+		for _, u := range units {
+			if checked[u.AppName] {
+				continue
+			}
+			checked[u.AppName] = true
+			a, err := app.GetByName(u.AppName)
+			if err != nil {
+				return err
+			}
+			err = a.ValidatePoolConstraints(p)
+			if err != nil {
+				return &tsuruErrors.HTTP{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("node %s has units of app %q not allowed in pool %q: %v", n.node.Address(), a.Name, p.Name, err),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func matchesNodeMetadata(node provision.Node, filter map[string]string) bool {
+	metadata := provision.NodeToSpec(node).Metadata
+	for k, v := range filter {
+		if k == provision.PoolMetadataName {
+			if node.Pool() != v {
+				return false
+			}
+			continue
+		}
+		if metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// title: bulk update node metadata
+// path: /node/bulk/metadata
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+//   409: Node locked
+func bulkUpdateNodeMetadata(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var opts bulkNodeUpdateOptions
+	dec := form.NewDecoder(nil)
+	dec.IgnoreCase(true)
+	dec.IgnoreUnknownKeys(true)
+	err = dec.DecodeValues(&opts, r.Form)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	opts.Address = r.Form["address"]
+	if len(opts.Address) == 0 && len(opts.Filter) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "You must select nodes by address or by a metadata filter"}
+	}
+	if len(opts.Metadata) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "metadata is required"}
+	}
+	newPool, poolChanged := opts.Metadata[provision.PoolMetadataName]
+	if poolChanged {
+		delete(opts.Metadata, provision.PoolMetadataName)
+		if newPool == "" {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "pool cannot be removed from nodes"}
+		}
+	}
+	if _, ok := opts.Metadata[provision.IaaSIDMetadataName]; ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "iaas-id cannot be changed"}
+	}
+	nodes, err := bulkSelectNodes(opts)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "no nodes match the given filter"}
+	}
+	var newPoolProv string
+	var targetPool *pool.Pool
+	if poolChanged {
+		targetPool, err = pool.GetPoolByName(newPool)
+		if err != nil {
+			if err == pool.ErrPoolNotFound {
+				return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("pool %q not found", newPool)}
+			}
+			return err
+		}
+		var prov provision.Provisioner
+		prov, err = targetPool.GetProvisioner()
+		if err != nil {
+			return err
+		}
+		newPoolProv = prov.GetName()
+	}
+	poolSet := map[string]struct{}{}
+	for _, n := range nodes {
+		if poolChanged && n.prov.GetName() != newPoolProv {
+			return &tsuruErrors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("node %s is managed by the %s provisioner, pool %q uses %s", n.node.Address(), n.prov.GetName(), newPool, newPoolProv),
+			}
+		}
+		poolSet[n.pool] = struct{}{}
+	}
+	if poolChanged {
+		poolSet[newPool] = struct{}{}
+	}
+	pools := make([]string, 0, len(poolSet))
+	for p := range poolSet {
+		pools = append(pools, p)
+	}
+	sort.Strings(pools)
+	for _, p := range pools {
+		ctx := permission.Context(permission.CtxPool, p)
+		if !permission.Check(t, permission.PermNodeUpdate, ctx) {
+			return permission.ErrUnauthorized
+		}
+		if opts.Rebalance && !permission.Check(t, permission.PermNodeUpdateRebalance, ctx) {
+			return permission.ErrUnauthorized
+		}
+	}
+	if poolChanged && !opts.Rebalance {
+		err = bulkCheckPoolConstraints(nodes, targetPool)
+		if err != nil {
+			return err
+		}
+	}
+	// Every node is locked before the first update so a node being changed
+	// by another operation aborts the whole batch instead of part of it.
+	for i := range nodes {
+		permContexts := []permission.PermissionContext{permission.Context(permission.CtxPool, nodes[i].pool)}
+		if poolChanged && nodes[i].pool != newPool {
+			permContexts = append(permContexts, permission.Context(permission.CtxPool, newPool))
+		}
+		nodes[i].evt, err = event.New(&event.Opts{
+			Target:     event.Target{Type: event.TargetTypeNode, Value: nodes[i].node.Address()},
+			Kind:       permission.PermNodeUpdate,
+			Owner:      t,
+			CustomData: event.FormToCustomData(r.Form),
+			Allowed:    event.Allowed(permission.PermPoolReadEvents, permContexts...),
+		})
+		if err != nil {
+			for _, n := range nodes[:i] {
+				n.evt.Abort()
+			}
+			if _, ok := err.(event.ErrEventLocked); ok {
+				return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+			}
+			return err
+		}
+	}
+	// The event of each node is finished with its own error, nodes updated
+	// successfully are not marked as failed by errors in other nodes.
+	nodeErrs := make(map[string]error, len(nodes))
+	updated := map[string]bool{}
+	defer func() {
+		for _, n := range nodes {
+			evtErr, ok := nodeErrs[n.node.Address()]
+			if !ok && !updated[n.node.Address()] {
+				evtErr = err
+			}
+			n.evt.Done(evtErr)
+		}
+	}()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 15*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	for _, n := range nodes {
+		n.evt.SetLogWriter(writer)
+	}
+	multiErr := tsuruErrors.NewMultiError()
+	var updatedAddrs []string
+	for _, n := range nodes {
+		metadata := make(map[string]string, len(opts.Metadata))
+		for k, v := range opts.Metadata {
+			metadata[k] = v
+		}
+		updateErr := n.prov.UpdateNode(provision.UpdateNodeOptions{
+			Address:  n.node.Address(),
+			Pool:     newPool,
+			Metadata: metadata,
+		})
+		if updateErr != nil {
+			fmt.Fprintf(n.evt, "unable to update node %s: %v\n", n.node.Address(), updateErr)
+			nodeErrs[n.node.Address()] = updateErr
+			multiErr.Add(errors.Wrapf(updateErr, "unable to update node %s", n.node.Address()))
+			continue
+		}
+		updated[n.node.Address()] = true
+		updatedAddrs = append(updatedAddrs, n.node.Address())
+		fmt.Fprintf(n.evt, "node %s updated\n", n.node.Address())
+	}
+	if multiErr.Len() > 0 {
+		if len(updatedAddrs) > 0 {
+			multiErr.Add(errors.Errorf("nodes already updated: %s", strings.Join(updatedAddrs, ", ")))
+		}
+		return multiErr.ToError()
+	}
+	if !opts.Rebalance {
+		return nil
+	}
+	rebalanced := map[string]bool{}
+	for _, n := range nodes {
+		rebalanceProv, ok := n.prov.(provision.NodeRebalanceProvisioner)
+		if !ok {
+			continue
+		}
+		for _, p := range []string{n.pool, newPool} {
+			key := n.prov.GetName() + "/" + p
+			if p == "" || rebalanced[key] {
+				continue
+			}
+			rebalanced[key] = true
+			fmt.Fprintf(n.evt, "rebalancing units in pool %s\n", p)
+			_, err = rebalanceProv.RebalanceNodes(provision.RebalanceNodesOptions{
+				Event: n.evt,
+				Pool:  p,
+				Force: true,
+			})
+			if err != nil {
+				err = errors.Wrapf(err, "Error trying to rebalance units in pool %s", p)
+				nodeErrs[n.node.Address()] = err
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) bulkNodeRequest(c *check.C, v url.Values) *httptest.ResponseRecorder {
+	request, err := http.NewRequest("POST", "/1.6/node/bulk/metadata", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *S) addBulkNodes(c *check.C) {
+	for _, name := range []string{"pool1", "pool2", "pool3"} {
+		err := pool.AddPool(pool.AddPoolOptions{Name: name})
+		c.Assert(err, check.IsNil)
+	}
+	nodes := []provision.AddNodeOptions{
+		{Address: "n1", Pool: "pool1", Metadata: map[string]string{"zone": "z0"}},
+		{Address: "n2", Pool: "pool1", Metadata: map[string]string{"zone": "z0"}},
+		{Address: "n3", Pool: "pool2", Metadata: map[string]string{"zone": "z0"}},
+	}
+	for _, opts := range nodes {
+		err := s.provisioner.AddNode(opts)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *S) TestBulkUpdateNodeMetadataByAddress(c *check.C) {
+	s.addBulkNodes(c)
+	v := url.Values{}
+	v.Add("address", "n1")
+	v.Add("address", "n2")
+	v.Set("Metadata.pool", "pool3")
+	v.Set("Metadata.zone", "z1")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*node n1 updated.*node n2 updated.*`)
+	for _, addr := range []string{"n1", "n2"} {
+		node, err := s.provisioner.GetNode(addr)
+		c.Assert(err, check.IsNil)
+		c.Assert(node.Pool(), check.Equals, "pool3")
+		c.Assert(node.Metadata()["zone"], check.Equals, "z1")
+	}
+	node, err := s.provisioner.GetNode("n3")
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Pool(), check.Equals, "pool2")
+	c.Assert(node.Metadata()["zone"], check.Equals, "z0")
+	for _, addr := range []string{"n1", "n2"} {
+		c.Assert(eventtest.EventDesc{
+			Target: event.Target{Type: event.TargetTypeNode, Value: addr},
+			Owner:  s.token.GetUserName(),
+			Kind:   "node.update",
+		}, eventtest.HasEvent)
+	}
+}
+
+func (s *S) TestBulkUpdateNodeMetadataByFilterWithRebalance(c *check.C) {
+	s.addBulkNodes(c)
+	v := url.Values{}
+	v.Set("Filter.pool", "pool1")
+	v.Set("Metadata.zone", "z2")
+	v.Set("Rebalance", "true")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*node n1 updated.*node n2 updated.*rebalancing units in pool pool1.*rebalancing - dry: false, force: true.*`)
+	for _, addr := range []string{"n1", "n2"} {
+		node, err := s.provisioner.GetNode(addr)
+		c.Assert(err, check.IsNil)
+		c.Assert(node.Pool(), check.Equals, "pool1")
+		c.Assert(node.Metadata()["zone"], check.Equals, "z2")
+	}
+	node, err := s.provisioner.GetNode("n3")
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Metadata()["zone"], check.Equals, "z0")
+}
+
+func (s *S) TestBulkUpdateNodeMetadataInvalidPool(c *check.C) {
+	s.addBulkNodes(c)
+	v := url.Values{}
+	v.Add("address", "n1")
+	v.Set("Metadata.pool", "unknown")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "pool \"unknown\" not found\n")
+	node, err := s.provisioner.GetNode("n1")
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Pool(), check.Equals, "pool1")
+}
+
+func (s *S) TestBulkUpdateNodeMetadataPoolConstraint(c *check.C) {
+	s.addBulkNodes(c)
+	err := pool.AddTeamsToPool("pool1", []string{s.team.Name})
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolConstraint(&pool.PoolConstraint{PoolExpr: "pool3", Field: pool.ConstraintTypeTeam, Values: []string{"other-team"}})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Pool: "pool1"}
+	err = app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = s.provisioner.AddUnitsToNode(&a, 1, "web", nil, "n2")
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("Filter.pool", "pool1")
+	v.Set("Metadata.pool", "pool3")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `node n2 has units of app "myapp" not allowed in pool "pool3": .*`)
+	for _, addr := range []string{"n1", "n2"} {
+		node, err := s.provisioner.GetNode(addr)
+		c.Assert(err, check.IsNil)
+		c.Assert(node.Pool(), check.Equals, "pool1")
+	}
+	v.Set("Rebalance", "true")
+	recorder = s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+}
+
+func (s *S) TestBulkUpdateNodeMetadataNodeLocked(c *check.C) {
+	s.addBulkNodes(c)
+	evt, err := event.New(&event.Opts{
+		Target:  event.Target{Type: event.TargetTypeNode, Value: "n2"},
+		Kind:    permission.PermNodeUpdate,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermPoolReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(nil)
+	v := url.Values{}
+	v.Add("address", "n1")
+	v.Add("address", "n2")
+	v.Set("Metadata.zone", "z1")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Matches, `event locked: .*`)
+	for _, addr := range []string{"n1", "n2"} {
+		node, err := s.provisioner.GetNode(addr)
+		c.Assert(err, check.IsNil)
+		c.Assert(node.Metadata()["zone"], check.Equals, "z0")
+	}
+}
+
+func (s *S) TestBulkUpdateNodeMetadataPartialFailure(c *check.C) {
+	s.addBulkNodes(c)
+	s.provisioner.PrepareFailure("UpdateNode", errors.New("update failed"))
+	v := url.Values{}
+	v.Add("address", "n1")
+	v.Add("address", "n2")
+	v.Set("Metadata.zone", "z1")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*unable to update node n1: update failed.*node n2 updated.*nodes already updated: n2.*`)
+	node, err := s.provisioner.GetNode("n2")
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Metadata()["zone"], check.Equals, "z1")
+	c.Assert(eventtest.EventDesc{
+		Target:       event.Target{Type: event.TargetTypeNode, Value: "n1"},
+		Owner:        s.token.GetUserName(),
+		Kind:         "node.update",
+		ErrorMatches: "update failed",
+	}, eventtest.HasEvent)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNode, Value: "n2"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "node.update",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestBulkUpdateNodeMetadataNodeNotFound(c *check.C) {
+	s.addBulkNodes(c)
+	v := url.Values{}
+	v.Add("address", "n1")
+	v.Add("address", "n9")
+	v.Set("Metadata.zone", "z1")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	node, err := s.provisioner.GetNode("n1")
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Metadata()["zone"], check.Equals, "z0")
+}
+
+func (s *S) TestBulkUpdateNodeMetadataNoSelection(c *check.C) {
+	v := url.Values{}
+	v.Set("Metadata.zone", "z1")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "You must select nodes by address or by a metadata filter\n")
+}
+
+func (s *S) TestBulkUpdateNodeMetadataNoMetadata(c *check.C) {
+	v := url.Values{}
+	v.Add("address", "n1")
+	recorder := s.bulkNodeRequest(c, v)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "metadata is required\n")
+}
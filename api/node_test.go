@@ -16,6 +16,8 @@ import (
 	"strings"
 
 	"github.com/ajg/form"
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/event"
@@ -25,6 +27,8 @@ import (
 	iaasTesting "github.com/tsuru/tsuru/iaas/testing"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"github.com/tsuru/tsuru/provision/nodehook"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	apiTypes "github.com/tsuru/tsuru/types/api"
@@ -335,6 +339,36 @@ func (s *S) TestRemoveNodeHandler(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestRemoveNodeHandlerDry(c *check.C) {
+	err := s.provisioner.AddNode(provision.AddNodeOptions{
+		Address: "http://host.com:2375",
+	})
+	c.Assert(err, check.IsNil)
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	_, err = s.provisioner.AddUnitsToNode(a, 2, "web", nil, "http://host.com:2375")
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("DELETE", "/node/http:%2F%2Fhost.com:2375?dry=true", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "application/json")
+	var report provision.DryRunReport
+	err = json.Unmarshal(rec.Body.Bytes(), &report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Nodes, check.DeepEquals, []string{"http://host.com:2375"})
+	c.Assert(report.Moved, check.HasLen, 2)
+	c.Assert(report.Units, check.HasLen, 0)
+	nodes, err := s.provisioner.ListNodes(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	evts, err := event.List(&event.Filter{Target: event.Target{Type: event.TargetTypeNode, Value: "http://host.com:2375"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 0)
+}
+
 func (s *S) TestRemoveNodeHandlerNoRebalance(c *check.C) {
 	err := s.provisioner.AddNode(provision.AddNodeOptions{
 		Address: "host.com:2375",
@@ -736,6 +770,60 @@ func (s *S) TestUpdateNodeDisableNodeHandler(c *check.C) {
 	c.Assert(nodes[0].Status(), check.Equals, "disabled")
 }
 
+func (s *S) TestUpdateNodeDisableNodeHandlerCallsNodeHooks(c *check.C) {
+	var payloads []nodehook.Payload
+	hookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p nodehook.Payload
+		json.NewDecoder(r.Body).Decode(&p)
+		payloads = append(payloads, p)
+	}))
+	defer hookServer.Close()
+	config.Set("node-hooks:webhooks", []string{hookServer.URL})
+	defer config.Unset("node-hooks")
+	err := s.provisioner.AddNode(provision.AddNodeOptions{
+		Address:  "localhost:1999",
+		Pool:     "pool1",
+		Metadata: map[string]string{"m1": "v1"},
+	})
+	c.Assert(err, check.IsNil)
+	nodehook.Wait()
+	c.Assert(payloads, check.HasLen, 1)
+	c.Assert(payloads[0].Event, check.Equals, nodehook.EventAdd)
+	payloads = nil
+	params := provision.UpdateNodeOptions{
+		Address: "localhost:1999",
+		Disable: true,
+	}
+	v, err := form.EncodeToValues(&params)
+	c.Assert(err, check.IsNil)
+	b := strings.NewReader(v.Encode())
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("PUT", "/node", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	nodehook.Wait()
+	c.Assert(payloads, check.HasLen, 1)
+	c.Assert(payloads[0].Event, check.Equals, nodehook.EventDisable)
+	c.Assert(payloads[0].Node.Address, check.Equals, "localhost:1999")
+	c.Assert(payloads[0].Node.Pool, check.Equals, "pool1")
+	c.Assert(payloads[0].Node.Status, check.Equals, "disabled")
+	c.Assert(payloads[0].Node.Metadata["m1"], check.Equals, "v1")
+	request, err = http.NewRequest("DELETE", "/node/localhost:1999", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	nodehook.Wait()
+	c.Assert(payloads, check.HasLen, 2)
+	c.Assert(payloads[1].Event, check.Equals, nodehook.EventRemove)
+	c.Assert(payloads[1].Node.Address, check.Equals, "localhost:1999")
+}
+
 func (s *S) TestUpdateNodeEnableNodeHandler(c *check.C) {
 	err := s.provisioner.AddNode(provision.AddNodeOptions{
 		Address: "localhost:1999",
@@ -1147,3 +1235,88 @@ func (s *S) TestInfoNodeHandler(c *check.C) {
 	c.Assert(result.Status.Checks[0].Checks, check.DeepEquals, checks)
 	c.Assert(result.Units, check.DeepEquals, []provision.Unit{unit})
 }
+
+func (s *S) TestNodeTopologyHandler(c *check.C) {
+	err := s.provisioner.AddNode(provision.AddNodeOptions{Address: "host1.com:2375", Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{Address: "host2.com:2375", Pool: "pool2"})
+	c.Assert(err, check.IsNil)
+	node, err := s.provisioner.GetNode("host1.com:2375")
+	c.Assert(err, check.IsNil)
+	checks := []provision.NodeCheckResult{{Name: "ok1", Successful: true}}
+	err = (&healer.NodeHealer{}).UpdateNodeData(node, checks)
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "tsuru/bs"},
+	})
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.AddNewContainer("pool2", &nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "tsuru/bs:v2"},
+	})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "fake", TeamOwner: s.team.Name}
+	err = app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	unit := provision.Unit{ID: "a834h983j498j", AppName: "fake", Address: &url.URL{Host: "host1.com:2375"}}
+	s.provisioner.AddUnit(&a, unit)
+	req, err := http.NewRequest("GET", "/node/topology", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	req.Header.Set("Authorization", s.token.GetValue())
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result apiTypes.ClusterTopology
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Timestamp.IsZero(), check.Equals, false)
+	c.Assert(result.Nodes, check.HasLen, 2)
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Node.Address < result.Nodes[j].Node.Address
+	})
+	c.Assert(result.Nodes[0].Node.Pool, check.Equals, "pool1")
+	c.Assert(result.Nodes[0].Status.Checks, check.HasLen, 1)
+	c.Assert(result.Nodes[0].Status.Checks[0].Checks, check.DeepEquals, checks)
+	c.Assert(result.Nodes[0].Units, check.DeepEquals, []provision.Unit{unit})
+	c.Assert(result.Nodes[0].NodeContainers, check.DeepEquals, []apiTypes.TopologyNodeContainer{
+		{Name: "bs", Image: "tsuru/bs"},
+	})
+	c.Assert(result.Nodes[1].Node.Pool, check.Equals, "pool2")
+	c.Assert(result.Nodes[1].Units, check.HasLen, 0)
+	c.Assert(result.Nodes[1].NodeContainers, check.DeepEquals, []apiTypes.TopologyNodeContainer{
+		{Name: "bs", Image: "tsuru/bs:v2"},
+	})
+}
+
+func (s *S) TestNodeTopologyHandlerFilteredByPool(c *check.C) {
+	err := s.provisioner.AddNode(provision.AddNodeOptions{Address: "host1.com:2375", Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{Address: "host2.com:2375", Pool: "pool2"})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNodeRead,
+		Context: permission.Context(permission.CtxPool, "pool2"),
+	})
+	req, err := http.NewRequest("GET", "/node/topology", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	req.Header.Set("Authorization", token.GetValue())
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var result apiTypes.ClusterTopology
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Nodes, check.HasLen, 1)
+	c.Assert(result.Nodes[0].Node.Address, check.Equals, "host2.com:2375")
+}
+
+func (s *S) TestNodeTopologyHandlerNoContent(c *check.C) {
+	req, err := http.NewRequest("GET", "/node/topology", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	req.Header.Set("Authorization", s.token.GetValue())
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNoContent)
+}
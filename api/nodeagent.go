@@ -0,0 +1,113 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodeagent"
+)
+
+func nodeAgentClient(r *http.Request, t auth.Token, perm *permission.PermissionScheme) (provision.Node, *nodeagent.Client, error) {
+	address := r.URL.Query().Get(":address")
+	_, node, err := provision.FindNode(address)
+	if err != nil {
+		if err == provision.ErrNodeNotFound {
+			return nil, nil, &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return nil, nil, err
+	}
+	if !permission.Check(t, perm, permission.Context(permission.CtxPool, node.Pool())) {
+		return nil, nil, permission.ErrUnauthorized
+	}
+	client, err := nodeagent.NewClient(node.Address())
+	if err == nodeagent.ErrAgentDisabled {
+		return nil, nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return node, client, nil
+}
+
+// title: node kernel logs
+// path: /node/{address}/agent/kernel-logs
+// method: GET
+// produce: text/plain
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+func nodeAgentKernelLogs(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	_, client, err := nodeAgentClient(r, t, permission.PermNodeReadAgent)
+	if err != nil {
+		return err
+	}
+	var lines int
+	if l := r.URL.Query().Get("lines"); l != "" {
+		lines, err = strconv.Atoi(l)
+		if err != nil || lines < 0 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "lines must be a positive integer"}
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	return client.KernelLogs(w, lines)
+}
+
+// title: node disks health
+// path: /node/{address}/agent/disks
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+func nodeAgentDisks(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	_, client, err := nodeAgentClient(r, t, permission.PermNodeReadAgent)
+	if err != nil {
+		return err
+	}
+	disks, err := client.DiskHealth()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(disks)
+}
+
+// title: restart node docker daemon
+// path: /node/{address}/agent/docker/restart
+// method: POST
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+func nodeAgentRestartDocker(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	node, client, err := nodeAgentClient(r, t, permission.PermNodeUpdateAgent)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  event.Target{Type: event.TargetTypeNode, Value: node.Address()},
+		Kind:    permission.PermNodeUpdateAgent,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermPoolReadEvents, permission.Context(permission.CtxPool, node.Pool())),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return client.RestartDocker()
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodeagent"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) startNodeAgent(c *check.C) (*httptest.Server, *[]string) {
+	var paths []string
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/kernel-logs":
+			fmt.Fprintf(w, "kernel: %s lines\n", r.URL.Query().Get("lines"))
+		case "/disks":
+			w.Write([]byte(`[{"device":"/dev/sda","healthy":false,"status":"FAILED"}]`))
+		}
+	}))
+	agentURL, err := url.Parse(agent.URL)
+	c.Assert(err, check.IsNil)
+	config.Set("node-agent:port", agentURL.Port())
+	config.Set("node-agent:scheme", "http")
+	err = s.provisioner.AddNode(provision.AddNodeOptions{
+		Address: "http://127.0.0.1:2375",
+		Pool:    "pool1",
+	})
+	c.Assert(err, check.IsNil)
+	return agent, &paths
+}
+
+func (s *S) TestNodeAgentKernelLogs(c *check.C) {
+	agent, paths := s.startNodeAgent(c)
+	defer agent.Close()
+	defer config.Unset("node-agent")
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermNodeReadAgent,
+		Context: permission.Context(permission.CtxPool, "pool1"),
+	})
+	req, err := http.NewRequest("GET", "/node/http://127.0.0.1:2375/agent/kernel-logs?lines=5", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "text/plain")
+	c.Assert(rec.Body.String(), check.Equals, "kernel: 5 lines\n")
+	c.Assert(*paths, check.DeepEquals, []string{"GET /kernel-logs"})
+}
+
+func (s *S) TestNodeAgentDisks(c *check.C) {
+	agent, _ := s.startNodeAgent(c)
+	defer agent.Close()
+	defer config.Unset("node-agent")
+	req, err := http.NewRequest("GET", "/node/http://127.0.0.1:2375/agent/disks", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var disks []nodeagent.DiskHealth
+	err = json.Unmarshal(rec.Body.Bytes(), &disks)
+	c.Assert(err, check.IsNil)
+	c.Assert(disks, check.DeepEquals, []nodeagent.DiskHealth{
+		{Device: "/dev/sda", Healthy: false, Status: "FAILED"},
+	})
+}
+
+func (s *S) TestNodeAgentRestartDocker(c *check.C) {
+	agent, paths := s.startNodeAgent(c)
+	defer agent.Close()
+	defer config.Unset("node-agent")
+	req, err := http.NewRequest("POST", "/node/http://127.0.0.1:2375/agent/docker/restart", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(*paths, check.DeepEquals, []string{"POST /docker/restart"})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNode, Value: "http://127.0.0.1:2375"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "node.update.agent",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestNodeAgentRestartDockerWithoutPermission(c *check.C) {
+	agent, paths := s.startNodeAgent(c)
+	defer agent.Close()
+	defer config.Unset("node-agent")
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermNodeReadAgent,
+		Context: permission.Context(permission.CtxPool, "pool1"),
+	})
+	req, err := http.NewRequest("POST", "/node/http://127.0.0.1:2375/agent/docker/restart", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+	c.Assert(*paths, check.HasLen, 0)
+}
+
+func (s *S) TestNodeAgentDisabled(c *check.C) {
+	err := s.provisioner.AddNode(provision.AddNodeOptions{
+		Address: "http://127.0.0.1:2375",
+		Pool:    "pool1",
+	})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("GET", "/node/http://127.0.0.1:2375/agent/disks", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, nodeagent.ErrAgentDisabled.Error()+"\n")
+}
@@ -8,12 +8,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ajg/form"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -64,6 +66,7 @@ func nodeContainerList(w http.ResponseWriter, r *http.Request, t auth.Token) err
 // path: /docker/nodecontainers
 // method: POST
 // consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
 // responses:
 //   200: Ok
 //   400: Invald data
@@ -89,6 +92,10 @@ func nodeContainerCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (
 	if !permission.Check(t, permission.PermNodecontainerCreate, ctxs...) {
 		return permission.ErrUnauthorized
 	}
+	err = nodecontainer.CheckForbiddenEnvs(&config)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     event.Target{Type: event.TargetTypeNodeContainer, Value: config.Name},
 		Kind:       permission.PermNodecontainerCreate,
@@ -100,6 +107,10 @@ func nodeContainerCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (
 		return err
 	}
 	defer func() { evt.Done(err) }()
+	before, err := autoUpgradeSnapshot(config.Name)
+	if err != nil {
+		return err
+	}
 	err = nodecontainer.AddNewContainer(poolName, &config)
 	if err != nil {
 		if _, ok := err.(nodecontainer.ValidationErr); ok {
@@ -110,7 +121,7 @@ func nodeContainerCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (
 		}
 		return err
 	}
-	return nil
+	return autoUpgradeNodeContainer(w, evt, config.Name, poolName, before)
 }
 
 // title: node container info
@@ -159,6 +170,7 @@ func nodeContainerInfo(w http.ResponseWriter, r *http.Request, t auth.Token) err
 // path: /docker/nodecontainers/{name}
 // method: POST
 // consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
 // responses:
 //   200: Ok
 //   400: Invald data
@@ -185,6 +197,10 @@ func nodeContainerUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (
 	if !permission.Check(t, permission.PermNodecontainerUpdate, ctxs...) {
 		return permission.ErrUnauthorized
 	}
+	err = nodecontainer.CheckForbiddenEnvs(&config)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     event.Target{Type: event.TargetTypeNodeContainer, Value: config.Name},
 		Kind:       permission.PermNodecontainerUpdate,
@@ -197,6 +213,10 @@ func nodeContainerUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (
 	}
 	defer func() { evt.Done(err) }()
 	config.Name = r.URL.Query().Get(":name")
+	before, err := autoUpgradeSnapshot(config.Name)
+	if err != nil {
+		return err
+	}
 	err = nodecontainer.UpdateContainer(poolName, &config)
 	if err != nil {
 		if err == nodecontainer.ErrNodeContainerNotFound {
@@ -213,9 +233,106 @@ func nodeContainerUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (
 		}
 		return err
 	}
+	return autoUpgradeNodeContainer(w, evt, config.Name, poolName, before)
+}
+
+// autoUpgradeSnapshot returns the stored configs of the node container when
+// its containers are automatically recreated on changes, to be compared with
+// the configs after the change.
+func autoUpgradeSnapshot(name string) (map[string]nodecontainer.NodeContainerConfig, error) {
+	if !nodecontainer.AutoUpgradeEnabled(name) {
+		return nil, nil
+	}
+	return nodecontainer.LoadNodeContainersForPoolsMerge(name, false)
+}
+
+// autoUpgradeNodeContainer recreates the containers of the node container in
+// the pool, streaming the progress, when automatic upgrades are enabled and
+// its stored configs changed. Containers are recreated by the provisioners
+// just as in the upgrade handler, following their rolling controls.
+func autoUpgradeNodeContainer(w http.ResponseWriter, evt *event.Event, name, pool string, before map[string]nodecontainer.NodeContainerConfig) error {
+	if !nodecontainer.AutoUpgradeEnabled(name) {
+		return nil
+	}
+	after, err := nodecontainer.LoadNodeContainersForPoolsMerge(name, false)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	provs, err := provision.Registry()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 15*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	fmt.Fprintf(evt, "---- %s config changed, recreating containers ----\n", name)
+	var allErrors []string
+	for _, prov := range provs {
+		ncProv, ok := prov.(provision.NodeContainerProvisioner)
+		if !ok {
+			continue
+		}
+		err = ncProv.UpgradeNodeContainer(name, pool, evt)
+		if err != nil {
+			allErrors = append(allErrors, err.Error())
+		}
+	}
+	if len(allErrors) > 0 {
+		return errors.Errorf("config saved, errors recreating containers: %s", strings.Join(allErrors, "; "))
+	}
 	return nil
 }
 
+// title: node container validate
+// path: /nodecontainers/{name}/validate
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   204: Valid
+//   400: Invalid data
+//   401: Unauthorized
+func nodeContainerValidate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	err := r.ParseForm()
+	if err != nil {
+		return err
+	}
+	dec := form.NewDecoder(nil)
+	dec.IgnoreUnknownKeys(true)
+	dec.IgnoreCase(true)
+	var config nodecontainer.NodeContainerConfig
+	err = dec.DecodeValues(&config, r.Form)
+	if err != nil {
+		return err
+	}
+	config.Name = r.URL.Query().Get(":name")
+	poolName := r.FormValue("pool")
+	var ctxs []permission.PermissionContext
+	if poolName != "" {
+		ctxs = append(ctxs, permission.Context(permission.CtxPool, poolName))
+	}
+	if !permission.Check(t, permission.PermNodecontainerCreate, ctxs...) &&
+		!permission.Check(t, permission.PermNodecontainerUpdate, ctxs...) {
+		return permission.ErrUnauthorized
+	}
+	errs, err := nodecontainer.Validate(poolName, &config)
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	return json.NewEncoder(w).Encode(errs)
+}
+
 // title: remove node container
 // path: /docker/nodecontainers/{name}
 // method: DELETE
@@ -313,7 +430,12 @@ func nodeContainerUpgrade(w http.ResponseWriter, r *http.Request, t auth.Token)
 		return err
 	}
 	defer func() { evt.Done(err) }()
-	err = nodecontainer.UpgradeContainer(poolName, name)
+	dry, _ := strconv.ParseBool(r.FormValue("dry"))
+	if dry {
+		_, err = nodecontainer.LoadNodeContainersForPools(name)
+	} else {
+		err = nodecontainer.UpgradeContainer(poolName, name)
+	}
 	if err != nil {
 		if err == nodecontainer.ErrNodeContainerNotFound {
 			return &tsuruErrors.HTTP{
@@ -332,18 +454,285 @@ func nodeContainerUpgrade(w http.ResponseWriter, r *http.Request, t auth.Token)
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	var allErrors []string
+	for _, prov := range provs {
+		if dry {
+			dryProv, ok := prov.(provision.NodeContainerDryRunProvisioner)
+			if !ok {
+				if _, ok = prov.(provision.NodeContainerProvisioner); ok {
+					fmt.Fprintf(writer, "dry run is not supported by the %s provisioner\n", prov.GetName())
+				}
+				continue
+			}
+			err = dryProv.UpgradeNodeContainerDry(name, poolName, writer)
+		} else {
+			ncProv, ok := prov.(provision.NodeContainerProvisioner)
+			if !ok {
+				continue
+			}
+			err = ncProv.UpgradeNodeContainer(name, poolName, writer)
+		}
+		if err != nil {
+			allErrors = append(allErrors, err.Error())
+		}
+	}
+	if len(allErrors) > 0 {
+		return errors.Errorf("multiple errors upgrading nodes: %s", strings.Join(allErrors, "; "))
+	}
+	return nil
+}
+
+// title: node container status
+// path: /nodecontainers/{name}/status
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   204: No content
+//   401: Unauthorized
+//   404: Not found
+func nodeContainerStatus(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	name := r.URL.Query().Get(":name")
+	poolName := r.URL.Query().Get("pool")
+	pools, err := permission.ListContextValues(t, permission.PermNodecontainerRead, true)
+	if err != nil {
+		return err
+	}
+	var poolMap map[string]struct{}
+	if pools != nil {
+		poolMap = map[string]struct{}{}
+		for _, p := range pools {
+			poolMap[p] = struct{}{}
+		}
+		if _, ok := poolMap[poolName]; poolName != "" && !ok {
+			return permission.ErrUnauthorized
+		}
+	}
+	_, err = nodecontainer.LoadNodeContainer(poolName, name)
+	if err != nil {
+		if err == nodecontainer.ErrNodeContainerNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	provs, err := provision.Registry()
+	if err != nil {
+		return err
+	}
+	result := []provision.NodeContainerStatus{}
+	for _, prov := range provs {
+		statusProv, ok := prov.(provision.NodeContainerStatusProvisioner)
+		if !ok {
+			continue
+		}
+		statuses, err := statusProv.NodeContainerStatus(name, poolName)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			if poolMap != nil {
+				if _, ok := poolMap[st.Pool]; !ok {
+					continue
+				}
+			}
+			result = append(result, st)
+		}
+	}
+	if len(result) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// title: big-sibling token rotate
+// path: /nodecontainers/big-sibling/token
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Not found
+func nodeContainerTokenRotate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermNodecontainerUpdateToken) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  event.Target{Type: event.TargetTypeNodeContainer, Value: nodecontainer.BsDefaultName},
+		Kind:    permission.PermNodecontainerUpdateToken,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermPoolReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	oldToken, err := nodecontainer.RotateBSToken(app.AuthScheme, app.InternalAppName)
+	if err != nil {
+		if err == nodecontainer.ErrNodeContainerNotFound || err == nodecontainer.ErrBSTokenNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	provs, err := provision.Registry()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 15*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	var allErrors []string
 	for _, prov := range provs {
 		ncProv, ok := prov.(provision.NodeContainerProvisioner)
 		if !ok {
 			continue
 		}
-		err = ncProv.UpgradeNodeContainer(name, poolName, writer)
+		err = ncProv.UpgradeNodeContainer(nodecontainer.BsDefaultName, "", writer)
 		if err != nil {
 			allErrors = append(allErrors, err.Error())
 		}
 	}
 	if len(allErrors) > 0 {
-		return errors.Errorf("multiple errors upgrading nodes: %s", strings.Join(allErrors, "; "))
+		return errors.Errorf("previous token kept valid, errors recreating containers: %s", strings.Join(allErrors, "; "))
 	}
-	return nil
+	return app.AuthScheme.AppLogout(oldToken)
+}
+
+// title: node container logs
+// path: /nodecontainers/{name}/logs
+// method: GET
+// produce: text/plain
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+func nodeContainerLogs(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	name := r.URL.Query().Get(":name")
+	address := r.URL.Query().Get("node")
+	if address == "" {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "node address is required"}
+	}
+	prov, node, err := provision.FindNode(address)
+	if err != nil {
+		if err == provision.ErrNodeNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	if !permission.Check(t, permission.PermNodecontainerReadLogs, permission.Context(permission.CtxPool, node.Pool())) {
+		return permission.ErrUnauthorized
+	}
+	logsProv, ok := prov.(provision.NodeContainerLogsProvisioner)
+	if !ok {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("provisioner %q does not support node container logs", prov.GetName()),
+		}
+	}
+	var lines int
+	if l := r.URL.Query().Get("lines"); l != "" {
+		lines, err = strconv.Atoi(l)
+		if err != nil || lines < 0 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "lines must be a positive integer"}
+		}
+	}
+	opts := provision.NodeContainerLogsOptions{
+		Writer: w,
+		Lines:  lines,
+		Follow: r.URL.Query().Get("follow") == "1",
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	err = logsProv.NodeContainerLogs(node, name, opts)
+	if err == nodecontainer.ErrNodeContainerNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: node container secret list
+// path: /nodecontainers/secrets
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   204: No content
+//   401: Unauthorized
+func nodeContainerSecretList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermNodecontainerRead) {
+		return permission.ErrUnauthorized
+	}
+	names, err := nodecontainer.SecretNames()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(names)
+}
+
+// title: node container secret set
+// path: /nodecontainers/secrets
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+func nodeContainerSecretSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	name := r.FormValue("name")
+	value := r.FormValue("value")
+	if !permission.Check(t, permission.PermNodecontainerUpdateSecret) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeGlobal},
+		Kind:       permission.PermNodecontainerUpdateSecret,
+		Owner:      t,
+		CustomData: map[string]interface{}{"name": name},
+		Allowed:    event.Allowed(permission.PermPoolReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = nodecontainer.SetSecret(name, value)
+	if _, ok := err.(nodecontainer.ValidationErr); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: node container secret remove
+// path: /nodecontainers/secrets/{name}
+// method: DELETE
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Not found
+func nodeContainerSecretRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	name := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermNodecontainerUpdateSecret) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeGlobal},
+		Kind:       permission.PermNodecontainerUpdateSecret,
+		Owner:      t,
+		CustomData: map[string]interface{}{"name": name, "remove": true},
+		Allowed:    event.Allowed(permission.PermPoolReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = nodecontainer.RemoveSecret(name)
+	if err == nodecontainer.ErrSecretNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
 }
@@ -15,9 +15,12 @@ import (
 
 	"github.com/ajg/form"
 	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
 	"gopkg.in/check.v1"
 )
@@ -203,6 +206,65 @@ func (s *S) TestNodeContainerInfoLimited(c *check.C) {
 	})
 }
 
+func (s *S) TestNodeContainerValidate(c *check.C) {
+	values, err := form.EncodeToValues(nodecontainer.NodeContainerConfig{
+		Config: docker.Config{Image: "img1", Env: []string{"A=1", "B-C=2"}},
+		HostConfig: docker.HostConfig{
+			Binds: []string{"/x:y"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	values.Del("Disabled")
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/c1/validate", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var errs []nodecontainer.FieldError
+	err = json.Unmarshal(recorder.Body.Bytes(), &errs)
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.DeepEquals, []nodecontainer.FieldError{
+		{Field: "Config.Env[1]", Message: `invalid env var name "B-C"`},
+		{Field: "HostConfig.Binds[0]", Message: `invalid bind destination "y", must be an absolute path`},
+	})
+	all, err := nodecontainer.AllNodeContainers()
+	c.Assert(err, check.IsNil)
+	c.Assert(all, check.HasLen, 0)
+}
+
+func (s *S) TestNodeContainerValidateValid(c *check.C) {
+	values, err := form.EncodeToValues(nodecontainer.NodeContainerConfig{
+		Config: docker.Config{Image: "tsuru/bs:v2", Env: []string{"A=1"}},
+	})
+	c.Assert(err, check.IsNil)
+	values.Del("Disabled")
+	values.Set("pool", "pool1")
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/c1/validate", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestNodeContainerValidateNoPermission(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNodecontainerUpdate,
+		Context: permission.Context(permission.CtxPool, "pool1"),
+	})
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/c1/validate", strings.NewReader("pool=pool2&Config.Image=img1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *S) TestNodeContainerCreate(c *check.C) {
 	doReq := func(cont nodecontainer.NodeContainerConfig, expected []nodecontainer.NodeContainerConfigGroup, pool ...string) {
 		values, err := form.EncodeToValues(cont)
@@ -318,6 +380,26 @@ func (s *S) TestNodeContainerCreateInvalid(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Matches, "node container config image cannot be empty\n")
 }
 
+func (s *S) TestNodeContainerCreateForbiddenEnv(c *check.C) {
+	values, err := form.EncodeToValues(nodecontainer.NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1", Env: []string{"TSURU_TOKEN=abc"}},
+	})
+	c.Assert(err, check.IsNil)
+	reader := strings.NewReader(values.Encode())
+	request, err := http.NewRequest("POST", "/1.2/nodecontainers", reader)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "env var \"TSURU_TOKEN\" is managed by tsuru and cannot be set\n")
+	_, err = nodecontainer.LoadNodeContainersForPools("c1")
+	c.Assert(err, check.Equals, nodecontainer.ErrNodeContainerNotFound)
+}
+
 func (s *S) TestNodeContainerCreateLimited(c *check.C) {
 	t := userWithPermission(c, permission.Permission{
 		Scheme:  permission.PermNodecontainerCreate,
@@ -644,6 +726,92 @@ func (s *S) TestNodeContainerUpgrade(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestNodeContainerUpdateBSAutoUpgrade(c *check.C) {
+	config.Set("docker:bs:auto-upgrade", true)
+	defer config.Unset("docker:bs:auto-upgrade")
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "tsuru/bs:v1"},
+	})
+	c.Assert(err, check.IsNil)
+	doReq := func(values url.Values) *httptest.ResponseRecorder {
+		request, err := http.NewRequest("POST", "/1.2/nodecontainers/big-sibling", strings.NewReader(values.Encode()))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		server := RunServer(true)
+		server.ServeHTTP(recorder, request)
+		return recorder
+	}
+	recorder := doReq(url.Values{"Name": []string{nodecontainer.BsDefaultName}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(s.provisioner.HasNodeContainer(nodecontainer.BsDefaultName, ""), check.Equals, false)
+	recorder = doReq(url.Values{"Config.Image": []string{"tsuru/bs:v2"}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*big-sibling config changed, recreating containers.*`)
+	c.Assert(s.provisioner.HasNodeContainer(nodecontainer.BsDefaultName, ""), check.Equals, true)
+}
+
+func (s *S) TestNodeContainerUpdateBSAutoUpgradeDisabled(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "tsuru/bs:v1"},
+	})
+	c.Assert(err, check.IsNil)
+	values := url.Values{"Config.Image": []string{"tsuru/bs:v2"}}
+	request, err := http.NewRequest("POST", "/1.2/nodecontainers/big-sibling", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(s.provisioner.HasNodeContainer(nodecontainer.BsDefaultName, ""), check.Equals, false)
+}
+
+func (s *S) TestNodeContainerTokenRotate(c *check.C) {
+	_, err := nodecontainer.InitializeBS(app.AuthScheme, app.InternalAppName)
+	c.Assert(err, check.IsNil)
+	bs, err := nodecontainer.LoadNodeContainer("", nodecontainer.BsDefaultName)
+	c.Assert(err, check.IsNil)
+	oldToken := strings.TrimPrefix(bs.Config.Env[0], "TSURU_TOKEN=")
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/big-sibling/token", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(s.provisioner.HasNodeContainer(nodecontainer.BsDefaultName, ""), check.Equals, true)
+	bs, err = nodecontainer.LoadNodeContainer("", nodecontainer.BsDefaultName)
+	c.Assert(err, check.IsNil)
+	newToken := strings.TrimPrefix(bs.Config.Env[0], "TSURU_TOKEN=")
+	c.Assert(newToken, check.Not(check.Equals), oldToken)
+	_, err = app.AuthScheme.Auth(newToken)
+	c.Assert(err, check.IsNil)
+	_, err = app.AuthScheme.Auth(oldToken)
+	c.Assert(err, check.NotNil)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNodeContainer, Value: nodecontainer.BsDefaultName},
+		Owner:  s.token.GetUserName(),
+		Kind:   "nodecontainer.update.token",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestNodeContainerTokenRotateNotFound(c *check.C) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/big-sibling/token", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestNodeContainerUpgradeLimited(c *check.C) {
 	err := nodecontainer.AddNewContainer("p1", &nodecontainer.NodeContainerConfig{
 		Name:        "c1",
@@ -683,6 +851,54 @@ func (s *S) TestNodeContainerUpgradeLimited(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestNodeContainerUpgradeDry(c *check.C) {
+	config := nodecontainer.NodeContainerConfig{
+		Name:        "c1",
+		PinnedImage: "tsuru/c1@sha256:abcef384829283eff",
+		Config: docker.Config{
+			Image: "img1",
+			Env:   []string{"A=1"},
+		},
+	}
+	err := nodecontainer.AddNewContainer("", &config)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/docker/nodecontainers/c1/upgrade", strings.NewReader("dry=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*would upgrade node container \\"c1\\" in pool \\"\\".*`)
+	c.Assert(s.provisioner.HasNodeContainer("c1", ""), check.Equals, false)
+	all, err := nodecontainer.AllNodeContainers()
+	c.Assert(err, check.IsNil)
+	c.Assert(all, check.DeepEquals, []nodecontainer.NodeContainerConfigGroup{
+		{Name: "c1", ConfigPools: map[string]nodecontainer.NodeContainerConfig{"": config}},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNodeContainer, Value: "c1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "nodecontainer.update.upgrade",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "c1"},
+			{"name": "dry", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestNodeContainerUpgradeDryNotFound(c *check.C) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/docker/nodecontainers/c1/upgrade", strings.NewReader("dry=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestNodeContainerUpgradeNotFound(c *check.C) {
 	err := nodecontainer.AddNewContainer("otherpool", &nodecontainer.NodeContainerConfig{
 		Name:        "c2",
@@ -715,3 +931,220 @@ func (s *S) TestNodeContainerUpgradeNotFound(c *check.C) {
 		c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
 	}
 }
+
+func (s *S) TestNodeContainerLogs(c *check.C) {
+	err := s.provisioner.AddNode(provision.AddNodeOptions{Address: "http://node1:2375", Pool: "p1"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/bs/logs?node=http://node1:2375&lines=10&follow=1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/plain")
+	c.Assert(recorder.Body.String(), check.Equals, "logs for bs in http://node1:2375, lines: 10, follow: true\n")
+}
+
+func (s *S) TestNodeContainerLogsNodeRequired(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/bs/logs", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "node address is required\n")
+}
+
+func (s *S) TestNodeContainerLogsNodeNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/bs/logs?node=http://node1:2375", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestNodeContainerLogsContainerNotFound(c *check.C) {
+	err := s.provisioner.AddNode(provision.AddNodeOptions{Address: "http://node1:2375", Pool: "p1"})
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareFailure("NodeContainerLogs", nodecontainer.ErrNodeContainerNotFound)
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/bs/logs?node=http://node1:2375", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestNodeContainerStatus(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1"},
+	})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{Address: "http://node1:2375", Pool: "p1"})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{Address: "http://node2:2375", Pool: "p2"})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.UpgradeNodeContainer("c1", "", nil)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/c1/status", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result []provision.NodeContainerStatus
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, []provision.NodeContainerStatus{
+		{Node: "http://node1:2375", Pool: "p1", Exists: true, ConfigMatches: true},
+		{Node: "http://node2:2375", Pool: "p2", Exists: true, ConfigMatches: true},
+	})
+}
+
+func (s *S) TestNodeContainerStatusLimited(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1"},
+	})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{Address: "http://node1:2375", Pool: "p1"})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{Address: "http://node2:2375", Pool: "p2"})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNodecontainerRead,
+		Context: permission.Context(permission.CtxPool, "p1"),
+	})
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/c1/status", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result []provision.NodeContainerStatus
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, []provision.NodeContainerStatus{
+		{Node: "http://node1:2375", Pool: "p1"},
+	})
+	request, err = http.NewRequest("GET", "/1.6/nodecontainers/c1/status?pool=p2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestNodeContainerStatusNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/c1/status", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestNodeContainerLogsLimited(c *check.C) {
+	err := s.provisioner.AddNode(provision.AddNodeOptions{Address: "http://node1:2375", Pool: "p1"})
+	c.Assert(err, check.IsNil)
+	t := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNodecontainerReadLogs,
+		Context: permission.Context(permission.CtxPool, "p2"),
+	})
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/bs/logs?node=http://node1:2375", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+t.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestNodeContainerSecretSetAndList(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/secrets", strings.NewReader("name=bs-token&value=abc123"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	envs, err := nodecontainer.ResolveEnvs([]string{"T=secret://tsuru/bs-token"})
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []string{"T=abc123"})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeGlobal},
+		Owner:  s.token.GetUserName(),
+		Kind:   "nodecontainer.update.secret",
+		StartCustomData: map[string]interface{}{
+			"name": "bs-token",
+		},
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("GET", "/1.6/nodecontainers/secrets", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "[\"bs-token\"]\n")
+}
+
+func (s *S) TestNodeContainerSecretSetInvalid(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/secrets", strings.NewReader("value=abc123"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "node container secret name cannot be empty\n")
+}
+
+func (s *S) TestNodeContainerSecretListEmpty(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.6/nodecontainers/secrets", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestNodeContainerSecretRemove(c *check.C) {
+	err := nodecontainer.SetSecret("bs-token", "abc123")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/nodecontainers/secrets/bs-token", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	names, err := nodecontainer.SecretNames()
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.HasLen, 0)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestNodeContainerSecretSetNoPermission(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNodecontainerRead,
+		Context: permission.Context(permission.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest("POST", "/1.6/nodecontainers/secrets", strings.NewReader("name=a&value=b"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app/orphan"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: list orphan resources
+// path: /orphans
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func orphanList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermOrphanRead) {
+		return permission.ErrUnauthorized
+	}
+	resources, err := orphan.List()
+	if err != nil {
+		return err
+	}
+	if len(resources) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resources)
+}
+
+// title: remove orphan resources
+// path: /orphans
+// method: DELETE
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Resource not found
+func orphanClean(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	if !permission.Check(t, permission.PermOrphanDelete) {
+		return permission.ErrUnauthorized
+	}
+	ids := r.Form["id"]
+	if len(ids) == 0 {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "at least one orphan resource id is required"}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeGlobal},
+		Kind:       permission.PermOrphanDelete,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermOrphanRead),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = orphan.Clean(ids)
+	if err == orphan.ErrResourceNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
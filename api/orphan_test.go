@@ -0,0 +1,112 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/app/orphan"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestOrphanList(c *check.C) {
+	s.provisioner.AddOrphanResource(provision.OrphanResource{Kind: "unit", Name: "cont1", App: "removed-app"})
+	_, err := orphan.Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermOrphanRead,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/orphans", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var resources []orphan.Resource
+	err = json.NewDecoder(recorder.Body).Decode(&resources)
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	c.Assert(resources[0].Kind, check.Equals, "unit")
+	c.Assert(resources[0].Name, check.Equals, "cont1")
+	c.Assert(resources[0].App, check.Equals, "removed-app")
+	c.Assert(resources[0].Provisioner, check.Equals, "fake")
+}
+
+func (s *S) TestOrphanListEmpty(c *check.C) {
+	request, err := http.NewRequest("GET", "/orphans", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestOrphanListUnauthorized(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermOrphanDelete,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/orphans", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestOrphanClean(c *check.C) {
+	s.provisioner.AddOrphanResource(provision.OrphanResource{Kind: "unit", Name: "cont1", App: "removed-app"})
+	resources, err := orphan.Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	request, err := http.NewRequest("DELETE", "/orphans?id="+resources[0].ID, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	orphans, err := s.provisioner.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 0)
+	stored, err := orphan.List()
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.HasLen, 0)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeGlobal},
+		Owner:  s.token.GetUserName(),
+		Kind:   "orphan.delete",
+		StartCustomData: []map[string]interface{}{
+			{"name": "id", "value": resources[0].ID},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestOrphanCleanNotFound(c *check.C) {
+	request, err := http.NewRequest("DELETE", "/orphans?id=unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestOrphanCleanNoID(c *check.C) {
+	request, err := http.NewRequest("DELETE", "/orphans", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
@@ -169,6 +169,9 @@ func roleInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 }
 
 func deployableApps(u *auth.User, rolesCache map[string]*permission.Role) ([]string, error) {
+	if u.Disabled {
+		return nil, nil
+	}
 	var perms []permission.Permission
 	for _, roleData := range u.Roles {
 		role := rolesCache[roleData.Name]
@@ -0,0 +1,349 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ajg/form"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/pipeline"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: app pipeline info
+// path: /apps/{app}/pipeline
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or pipeline not found
+func pipelineInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadPipeline,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	p, err := pipeline.Get(a.Name)
+	if err == pipeline.ErrPipelineNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(p)
+}
+
+// title: set app pipeline
+// path: /apps/{app}/pipeline
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Pipeline set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func pipelineSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var p pipeline.Pipeline
+	dec := form.NewDecoder(nil)
+	dec.IgnoreUnknownKeys(true)
+	err = dec.DecodeValues(&p, r.Form)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdatePipelineSet,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	p.App = a.Name
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdatePipelineSet,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pipeline.Save(&p)
+	if verr, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: verr.Message}
+	}
+	return err
+}
+
+// title: unset app pipeline
+// path: /apps/{app}/pipeline
+// method: DELETE
+// responses:
+//   200: Pipeline removed
+//   401: Unauthorized
+//   404: App or pipeline not found
+func pipelineUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdatePipelineUnset,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  appTarget(appName),
+		Kind:    permission.PermAppUpdatePipelineUnset,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pipeline.Remove(a.Name)
+	if err == pipeline.ErrPipelineNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: run app pipeline
+// path: /apps/{app}/pipeline/runs
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Run started
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App or pipeline not found
+//   409: Run in progress
+func pipelineRun(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdatePipelineRun,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	p, err := pipeline.Get(a.Name)
+	if err == pipeline.ErrPipelineNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	// Stages run on behalf of the user starting the run, so the user must
+	// be allowed to act on every app of the pipeline.
+	for _, s := range p.Stages {
+		var perm *permission.PermissionScheme
+		switch s.Kind {
+		case pipeline.StageBuild:
+			perm = permission.PermAppBuild
+		case pipeline.StageTest:
+			perm = permission.PermAppRun
+		case pipeline.StageDeploy:
+			perm = permission.PermAppDeploy
+		default:
+			continue
+		}
+		stageApp, err := app.GetByName(s.App)
+		if err != nil {
+			if err == app.ErrAppNotFound {
+				return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+			}
+			return err
+		}
+		if !permission.Check(t, perm, contextsForApp(stageApp)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	run, err := pipeline.Start(a.Name, pipeline.StartOpts{
+		ArchiveURL: r.FormValue("archive-url"),
+		Owner:      t.GetUserName(),
+	})
+	switch err {
+	case nil:
+	case pipeline.ErrRunInProgress:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	default:
+		if verr, ok := err.(*errors.ValidationError); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: verr.Message}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(run)
+}
+
+// title: app pipeline runs list
+// path: /apps/{app}/pipeline/runs
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func pipelineRunsList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadPipeline,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "limit must be a positive integer"}
+		}
+	}
+	runs, err := pipeline.ListRuns(a.Name, limit)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(runs)
+}
+
+func getPipelineRun(r *http.Request) (*pipeline.Run, error) {
+	run, err := pipeline.GetRun(r.URL.Query().Get(":id"))
+	if err == pipeline.ErrRunNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if run.App != r.URL.Query().Get(":app") {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: pipeline.ErrRunNotFound.Error()}
+	}
+	return run, nil
+}
+
+// title: app pipeline run info
+// path: /apps/{app}/pipeline/runs/{id}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or run not found
+func pipelineRunInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadPipeline,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	run, err := getPipelineRun(r)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(run)
+}
+
+// title: approve app pipeline run
+// path: /apps/{app}/pipeline/runs/{id}/approve
+// method: POST
+// responses:
+//   200: Run approved
+//   401: Unauthorized
+//   404: App or run not found
+//   409: Run not waiting approval
+func pipelineRunApprove(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return pipelineRunDecide(r, t, true)
+}
+
+// title: reject app pipeline run
+// path: /apps/{app}/pipeline/runs/{id}/reject
+// method: POST
+// responses:
+//   200: Run rejected
+//   401: Unauthorized
+//   404: App or run not found
+//   409: Run not waiting approval
+func pipelineRunReject(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return pipelineRunDecide(r, t, false)
+}
+
+func pipelineRunDecide(r *http.Request, t auth.Token, approved bool) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdatePipelineApprove,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	run, err := getPipelineRun(r)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdatePipelineApprove,
+		Owner:      t,
+		CustomData: map[string]interface{}{"run": run.ID.Hex(), "approved": approved},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = run.Approve(t.GetUserName(), approved)
+	if err == pipeline.ErrNotWaitingApproval {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/pipeline"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) doPipelineRequest(c *check.C, method, url, body, token string) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *S) TestPipelineSetAndInfo(c *check.C) {
+	for _, name := range []string{"myapp", "myapp-prod"} {
+		a := app.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+		err := app.CreateApp(&a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	recorder := s.doPipelineRequest(c, "GET", "/1.6/apps/myapp/pipeline", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	body := "Stages.0.Name=unit&Stages.0.Kind=test&Stages.0.Command=make+test&" +
+		"Stages.1.Name=approve&Stages.1.Kind=approval&" +
+		"Stages.2.Name=prod&Stages.2.Kind=deploy&Stages.2.App=myapp-prod"
+	recorder = s.doPipelineRequest(c, "PUT", "/1.6/apps/myapp/pipeline", body, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.pipeline.set",
+	}, eventtest.HasEvent)
+	recorder = s.doPipelineRequest(c, "GET", "/1.6/apps/myapp/pipeline", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var p pipeline.Pipeline
+	err := json.NewDecoder(recorder.Body).Decode(&p)
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, pipeline.Pipeline{App: "myapp", Stages: []pipeline.Stage{
+		{Name: "unit", Kind: pipeline.StageTest, App: "myapp", Command: "make test"},
+		{Name: "approve", Kind: pipeline.StageApproval},
+		{Name: "prod", Kind: pipeline.StageDeploy, App: "myapp-prod"},
+	}})
+}
+
+func (s *S) TestPipelineSetInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := "Stages.0.Name=prod&Stages.0.Kind=deploy"
+	recorder := s.doPipelineRequest(c, "PUT", "/1.6/apps/myapp/pipeline", body, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "deploy stage \"prod\" has no app\n")
+}
+
+func (s *S) TestPipelineUnset(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := s.doPipelineRequest(c, "DELETE", "/1.6/apps/myapp/pipeline", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	err = pipeline.Save(&pipeline.Pipeline{App: "myapp", Stages: []pipeline.Stage{{Name: "approve", Kind: pipeline.StageApproval}}})
+	c.Assert(err, check.IsNil)
+	recorder = s.doPipelineRequest(c, "DELETE", "/1.6/apps/myapp/pipeline", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = pipeline.Get("myapp")
+	c.Assert(err, check.Equals, pipeline.ErrPipelineNotFound)
+}
+
+func (s *S) TestPipelineRunRequiresPermissionOnStageApps(c *check.C) {
+	for _, name := range []string{"myapp", "myapp-prod"} {
+		a := app.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+		err := app.CreateApp(&a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	err := pipeline.Save(&pipeline.Pipeline{App: "myapp", Stages: []pipeline.Stage{
+		{Name: "prod", Kind: pipeline.StageDeploy, App: "myapp-prod"},
+	}})
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppUpdatePipelineRun,
+		Context: permission.Context(permission.CtxApp, "myapp"),
+	}, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, "myapp"),
+	})
+	recorder := s.doPipelineRequest(c, "POST", "/1.6/apps/myapp/pipeline/runs", "", token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	runs, err := pipeline.ListRuns("myapp", 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 0)
+}
+
+func (s *S) TestPipelineRunAndApprove(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = pipeline.Save(&pipeline.Pipeline{App: "myapp", Stages: []pipeline.Stage{
+		{Name: "approve", Kind: pipeline.StageApproval},
+	}})
+	c.Assert(err, check.IsNil)
+	recorder := s.doPipelineRequest(c, "POST", "/1.6/apps/myapp/pipeline/runs", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var run pipeline.Run
+	err = json.NewDecoder(recorder.Body).Decode(&run)
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Owner, check.Equals, s.token.GetUserName())
+	timeout := time.After(5 * time.Second)
+	for {
+		dbRun, err := pipeline.GetRun(run.ID.Hex())
+		c.Assert(err, check.IsNil)
+		if dbRun.Status == pipeline.StatusWaitingApproval {
+			break
+		}
+		select {
+		case <-timeout:
+			c.Fatalf("timeout waiting for run to wait approval, status: %s", dbRun.Status)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	recorder = s.doPipelineRequest(c, "POST", "/1.6/apps/myapp/pipeline/runs", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	recorder = s.doPipelineRequest(c, "POST", "/1.6/apps/myapp/pipeline/runs/"+run.ID.Hex()+"/approve", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.pipeline.approve",
+		StartCustomData: map[string]interface{}{
+			"run":      run.ID.Hex(),
+			"approved": true,
+		},
+	}, eventtest.HasEvent)
+	recorder = s.doPipelineRequest(c, "POST", "/1.6/apps/myapp/pipeline/runs/"+run.ID.Hex()+"/reject", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	recorder = s.doPipelineRequest(c, "GET", "/1.6/apps/myapp/pipeline/runs/"+run.ID.Hex(), "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var dbRun pipeline.Run
+	err = json.NewDecoder(recorder.Body).Decode(&dbRun)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, pipeline.StatusSucceeded)
+	c.Assert(dbRun.Stages[0].ApprovedBy, check.Equals, s.token.GetUserName())
+	recorder = s.doPipelineRequest(c, "GET", "/1.6/apps/myapp/pipeline/runs", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var runs []pipeline.Run
+	err = json.NewDecoder(recorder.Body).Decode(&runs)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+}
+
+func (s *S) TestPipelineRunsListEmpty(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := s.doPipelineRequest(c, "GET", "/1.6/apps/myapp/pipeline/runs", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	recorder = s.doPipelineRequest(c, "GET", "/1.6/apps/myapp/pipeline/runs/invalid", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -7,8 +7,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -18,6 +20,36 @@ import (
 	appTypes "github.com/tsuru/tsuru/types/app"
 )
 
+// platformOptionsFromRequest reads the platform options sent in the request.
+// The Dockerfile may be uploaded or sent inline in the dockerfile_content
+// field and build args are sent in buildArgs.NAME fields. The returned
+// function closes the uploaded Dockerfile.
+func platformOptionsFromRequest(r *http.Request, name string) (appTypes.PlatformOptions, func()) {
+	opts := appTypes.PlatformOptions{
+		Name: name,
+		Args: make(map[string]string),
+	}
+	closeInput := func() {}
+	file, _, _ := r.FormFile("dockerfile_content")
+	if file != nil {
+		opts.Input = file
+		closeInput = func() { file.Close() }
+	} else if content := r.FormValue("dockerfile_content"); content != "" {
+		opts.Input = strings.NewReader(content)
+	}
+	for key, values := range r.Form {
+		opts.Args[key] = values[0]
+		if strings.HasPrefix(key, "buildArgs.") {
+			if opts.BuildArgs == nil {
+				opts.BuildArgs = make(map[string]string)
+			}
+			opts.BuildArgs[strings.TrimPrefix(key, "buildArgs.")] = values[0]
+		}
+	}
+	opts.Proxy = r.FormValue("proxy")
+	return opts, closeInput
+}
+
 // title: add platform
 // path: /platforms
 // method: POST
@@ -29,14 +61,8 @@ import (
 //   401: Unauthorized
 func platformAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	name := r.FormValue("name")
-	file, _, _ := r.FormFile("dockerfile_content")
-	if file != nil {
-		defer file.Close()
-	}
-	args := make(map[string]string)
-	for key, values := range r.Form {
-		args[key] = values[0]
-	}
+	opts, closeInput := platformOptionsFromRequest(r, name)
+	defer closeInput()
 	canCreatePlatform := permission.Check(t, permission.PermPlatformCreate)
 	if !canCreatePlatform {
 		return permission.ErrUnauthorized
@@ -56,12 +82,8 @@ func platformAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 		return err
 	}
 	defer func() { evt.Done(err) }()
-	err = servicemanager.Platform.Create(appTypes.PlatformOptions{
-		Name:   name,
-		Args:   args,
-		Input:  file,
-		Output: writer,
-	})
+	opts.Output = writer
+	err = servicemanager.Platform.Create(opts)
 	if err != nil {
 		return err
 	}
@@ -80,14 +102,8 @@ func platformAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 func platformUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	r.ParseForm()
 	name := r.URL.Query().Get(":name")
-	file, _, _ := r.FormFile("dockerfile_content")
-	if file != nil {
-		defer file.Close()
-	}
-	args := make(map[string]string)
-	for key, values := range r.Form {
-		args[key] = values[0]
-	}
+	opts, closeInput := platformOptionsFromRequest(r, name)
+	defer closeInput()
 	canUpdatePlatform := permission.Check(t, permission.PermPlatformUpdate)
 	if !canUpdatePlatform {
 		return permission.ErrUnauthorized
@@ -107,12 +123,8 @@ func platformUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err e
 		return err
 	}
 	defer func() { evt.Done(err) }()
-	err = servicemanager.Platform.Update(appTypes.PlatformOptions{
-		Name:   name,
-		Args:   args,
-		Input:  file,
-		Output: writer,
-	})
+	opts.Output = writer
+	err = servicemanager.Platform.Update(opts)
 	if err == appTypes.ErrPlatformNotFound {
 		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
 	}
@@ -177,3 +189,51 @@ func platformList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(platforms)
 }
+
+// title: deprecated platforms report
+// path: /platforms/deprecated/apps
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func platformDeprecatedApps(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	contexts := permission.ContextsForPermission(t, permission.PermAppRead)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	platforms, err := servicemanager.Platform.List(false)
+	if err != nil {
+		return err
+	}
+	var report []appTypes.DeprecatedPlatformApps
+	for _, p := range platforms {
+		if !p.Deprecated {
+			continue
+		}
+		apps, err := app.List(appFilterByContext(contexts, &app.Filter{Platform: p.Name}))
+		if err != nil {
+			return err
+		}
+		if len(apps) == 0 {
+			continue
+		}
+		entry := appTypes.DeprecatedPlatformApps{Platform: p.Name}
+		for _, a := range apps {
+			entry.Apps = append(entry.Apps, appTypes.PlatformApp{
+				Name:      a.Name,
+				TeamOwner: a.TeamOwner,
+				Pool:      a.Pool,
+			})
+		}
+		report = append(report, entry)
+	}
+	if len(report) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(report)
+}
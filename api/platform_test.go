@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -123,6 +124,36 @@ func (s *PlatformSuite) TestPlatformAdd(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *PlatformSuite) TestPlatformAddWithBuildArgsAndProxy(c *check.C) {
+	s.mockService.Platform.OnCreate = func(opts appTypes.PlatformOptions) error {
+		c.Assert(opts.Name, check.Equals, "test")
+		c.Assert(opts.BuildArgs, check.DeepEquals, map[string]string{"JAVA_VERSION": "8"})
+		c.Assert(opts.Proxy, check.Equals, "http://proxy.internal:3128")
+		c.Assert(opts.Input, check.NotNil)
+		data, err := ioutil.ReadAll(opts.Input)
+		c.Assert(err, check.IsNil)
+		c.Assert(string(data), check.Equals, "FROM tsuru/java")
+		return nil
+	}
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("name", "test")
+	writer.WriteField("dockerfile_content", "FROM tsuru/java")
+	writer.WriteField("buildArgs.JAVA_VERSION", "8")
+	writer.WriteField("proxy", "http://proxy.internal:3128")
+	writer.Close()
+	request, _ := http.NewRequest("POST", "/platforms", &buf)
+	request.Header.Add("Content-Type", writer.FormDataContentType())
+	token := createToken(c)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var msg io.SimpleJsonMessage
+	json.Unmarshal(recorder.Body.Bytes(), &msg)
+	c.Assert(errors.New(msg.Error), check.ErrorMatches, "")
+}
+
 func (s *PlatformSuite) TestPlatformAddError(c *check.C) {
 	name := "Invalid_Name"
 	dockerfileURL := "http://localhost/Dockerfile"
@@ -185,6 +216,34 @@ func (s *PlatformSuite) TestPlatformUpdate(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *PlatformSuite) TestPlatformUpdateWithBuildArgs(c *check.C) {
+	name := "wat"
+	dockerfileURL := "http://localhost/Dockerfile"
+	s.mockService.Platform.OnUpdate = func(opts appTypes.PlatformOptions) error {
+		c.Assert(opts.Name, check.Equals, name)
+		c.Assert(opts.Args["dockerfile"], check.Equals, dockerfileURL)
+		c.Assert(opts.BuildArgs, check.DeepEquals, map[string]string{"VERSION": "1.0", "MIRROR": "local"})
+		c.Assert(opts.Proxy, check.Equals, "")
+		return nil
+	}
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("dockerfile", dockerfileURL)
+	writer.WriteField("buildArgs.VERSION", "1.0")
+	writer.WriteField("buildArgs.MIRROR", "local")
+	writer.Close()
+	request, _ := http.NewRequest("PUT", "/platforms/"+name, &buf)
+	request.Header.Add("Content-Type", writer.FormDataContentType())
+	token := createToken(c)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var msg io.SimpleJsonMessage
+	json.Unmarshal(recorder.Body.Bytes(), &msg)
+	c.Assert(errors.New(msg.Error), check.ErrorMatches, "")
+}
+
 func (s *PlatformSuite) TestPlatformUpdateOnlyDisableTrue(c *check.C) {
 	name := "wat"
 	s.mockService.Platform.OnUpdate = func(opts appTypes.PlatformOptions) error {
@@ -421,3 +480,77 @@ func (s *PlatformSuite) TestPlatformListNoContent(c *check.C) {
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
 }
+
+func (s *PlatformSuite) TestPlatformDeprecatedApps(c *check.C) {
+	s.mockService.Platform.OnList = func(enabledOnly bool) ([]appTypes.Platform, error) {
+		c.Assert(enabledOnly, check.Equals, false)
+		return []appTypes.Platform{
+			{Name: "python"},
+			{Name: "ruby", Deprecated: true},
+			{Name: "static", Deprecated: true},
+		}, nil
+	}
+	err := s.conn.Apps().Insert(
+		app.App{Name: "app1", Platform: "python", TeamOwner: "team1", Pool: "pool1"},
+		app.App{Name: "app2", Platform: "ruby", TeamOwner: "team1", Pool: "pool1"},
+		app.App{Name: "app3", Platform: "ruby", TeamOwner: "team2", Pool: "pool2"},
+	)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/platforms/deprecated/apps", nil)
+	c.Assert(err, check.IsNil)
+	token := createToken(c)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var got []appTypes.DeprecatedPlatformApps
+	err = json.NewDecoder(recorder.Body).Decode(&got)
+	c.Assert(err, check.IsNil)
+	c.Assert(got, check.DeepEquals, []appTypes.DeprecatedPlatformApps{
+		{Platform: "ruby", Apps: []appTypes.PlatformApp{
+			{Name: "app2", TeamOwner: "team1", Pool: "pool1"},
+			{Name: "app3", TeamOwner: "team2", Pool: "pool2"},
+		}},
+	})
+}
+
+func (s *PlatformSuite) TestPlatformDeprecatedAppsFilteredByPermission(c *check.C) {
+	s.mockService.Platform.OnList = func(enabledOnly bool) ([]appTypes.Platform, error) {
+		return []appTypes.Platform{{Name: "ruby", Deprecated: true}}, nil
+	}
+	err := s.conn.Apps().Insert(
+		app.App{Name: "app1", Platform: "ruby", TeamOwner: "team1", Teams: []string{"team1"}},
+		app.App{Name: "app2", Platform: "ruby", TeamOwner: "team2", Teams: []string{"team2"}},
+	)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/platforms/deprecated/apps", nil)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxTeam, "team2"),
+	})
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var got []appTypes.DeprecatedPlatformApps
+	err = json.NewDecoder(recorder.Body).Decode(&got)
+	c.Assert(err, check.IsNil)
+	c.Assert(got, check.DeepEquals, []appTypes.DeprecatedPlatformApps{
+		{Platform: "ruby", Apps: []appTypes.PlatformApp{{Name: "app2", TeamOwner: "team2"}}},
+	})
+}
+
+func (s *PlatformSuite) TestPlatformDeprecatedAppsNoContent(c *check.C) {
+	s.mockService.Platform.OnList = func(enabledOnly bool) ([]appTypes.Platform, error) {
+		return []appTypes.Platform{{Name: "python"}}, nil
+	}
+	request, err := http.NewRequest("GET", "/platforms/deprecated/apps", nil)
+	c.Assert(err, check.IsNil)
+	token := createToken(c)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
@@ -6,16 +6,20 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/ajg/form"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/auth"
 	terrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
+	apiTypes "github.com/tsuru/tsuru/types/api"
 )
 
 // title: pool list
@@ -168,6 +172,17 @@ func removePoolHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 	if len(apps) > 0 {
 		return &terrors.HTTP{Code: http.StatusForbidden, Message: "This pool has apps, you need to migrate or remove them before removing the pool"}
 	}
+	if dry, _ := strconv.ParseBool(r.FormValue("dry")); dry {
+		_, err = pool.GetPoolByName(poolName)
+		if err == pool.ErrPoolNotFound {
+			return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(provision.DryRunReport{Pools: []string{poolName}})
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
 		Kind:       permission.PermPoolDelete,
@@ -316,6 +331,168 @@ func poolUpdateHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 	return err
 }
 
+// title: set pool envs
+// path: /pools/{name}/env
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Envs updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Pool not found
+func poolEnvSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var e apiTypes.Envs
+	dec := form.NewDecoder(nil)
+	dec.IgnoreUnknownKeys(true)
+	err = dec.DecodeValues(&e, r.Form)
+	if err != nil {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if len(e.Envs) == 0 {
+		msg := "You must provide the list of environment variables"
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	if e.Private {
+		for i := 0; i < len(e.Envs); i++ {
+			r.Form.Set(fmt.Sprintf("Envs.%d.Value", i), "*****")
+		}
+	}
+	variables := make([]bind.EnvVar, len(e.Envs))
+	for i, v := range e.Envs {
+		variables[i] = bind.EnvVar{Name: v.Name, Value: v.Value, Public: !e.Private}
+	}
+	return updatePoolEnvs(r, t, func(poolName string) error {
+		return pool.SetEnvs(poolName, variables)
+	})
+}
+
+// title: unset pool envs
+// path: /pools/{name}/env
+// method: DELETE
+// responses:
+//   200: Envs removed
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Pool not found
+func poolEnvUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	variables := r.Form["env"]
+	if len(variables) == 0 {
+		msg := "You must provide the list of environment variables."
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	return updatePoolEnvs(r, t, func(poolName string) error {
+		return pool.UnsetEnvs(poolName, variables)
+	})
+}
+
+// updatePoolEnvs runs fn within an event. Env var changes only reach the
+// units of the apps in the pool once they're restarted or deployed again.
+func updatePoolEnvs(r *http.Request, t auth.Token, fn func(poolName string) error) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(t, permission.PermPoolUpdateEnv,
+		permission.Context(permission.CtxPool, poolName),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateEnv,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permission.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = fn(poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if verr, ok := err.(*terrors.ValidationError); ok {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: verr.Message}
+	}
+	return err
+}
+
+// title: set pool dns config
+// path: /pools/{name}/dns
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: DNS config set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Pool not found
+func poolDNSSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	dns := pool.DNSConfig{
+		Nameservers: r.Form["nameserver"],
+		Searches:    r.Form["search"],
+	}
+	if ndotsStr := r.FormValue("ndots"); ndotsStr != "" {
+		ndots, errConv := strconv.Atoi(ndotsStr)
+		if errConv != nil {
+			return &terrors.HTTP{Code: http.StatusBadRequest, Message: "ndots must be an integer"}
+		}
+		dns.Ndots = &ndots
+	}
+	return updatePoolDNS(r, t, &dns)
+}
+
+// title: unset pool dns config
+// path: /pools/{name}/dns
+// method: DELETE
+// responses:
+//   200: DNS config removed
+//   401: Unauthorized
+//   404: Pool not found
+func poolDNSUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	return updatePoolDNS(r, t, nil)
+}
+
+// updatePoolDNS sets the DNS config of the pool within an event. As with
+// envs, units only use the new config once they're restarted or deployed
+// again.
+func updatePoolDNS(r *http.Request, t auth.Token, dns *pool.DNSConfig) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(t, permission.PermPoolUpdateDns,
+		permission.Context(permission.CtxPool, poolName),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateDns,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permission.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pool.SetDNSConfig(poolName, dns)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if verr, ok := err.(*terrors.ValidationError); ok {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: verr.Message}
+	}
+	return err
+}
+
 // title: pool constraints list
 // path: /constraints
 // method: GET
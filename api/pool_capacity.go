@@ -0,0 +1,71 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app/capacity"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: pool capacity report
+// path: /pools/capacity
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func poolCapacityReport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	contexts := permission.ContextsForPermission(t, permission.PermPoolReadCapacity)
+	if len(contexts) == 0 {
+		return permission.ErrUnauthorized
+	}
+	isGlobal := false
+	allowed := map[string]struct{}{}
+	for _, c := range contexts {
+		if c.CtxType == permission.CtxGlobal {
+			isGlobal = true
+			break
+		}
+		if c.CtxType == permission.CtxPool {
+			allowed[c.Value] = struct{}{}
+		}
+	}
+	requested := r.URL.Query()["pool"]
+	var poolNames []string
+	if isGlobal {
+		poolNames = requested
+	} else {
+		if len(requested) == 0 {
+			for name := range allowed {
+				requested = append(requested, name)
+			}
+		}
+		for _, name := range requested {
+			if _, ok := allowed[name]; ok {
+				poolNames = append(poolNames, name)
+			}
+		}
+		if len(poolNames) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+	}
+	report, err := capacity.Report(poolNames, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if len(report) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(report)
+}
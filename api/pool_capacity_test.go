@@ -0,0 +1,95 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/capacity"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolCapacityReport(c *check.C) {
+	config.Set("docker:scheduler:total-memory-metadata", "totalMemory")
+	defer config.Unset("docker:scheduler:total-memory-metadata")
+	err := pool.AddPool(pool.AddPoolOptions{Name: "pool2"})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{
+		Address:  "http://n1:2375",
+		Pool:     "test1",
+		Metadata: map[string]string{"totalMemory": "1024"},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/1.6/pools/capacity", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var report []capacity.PoolCapacity
+	err = json.NewDecoder(recorder.Body).Decode(&report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, []capacity.PoolCapacity{
+		{Pool: "pool2"},
+		{Pool: "test1", Nodes: 1, MemoryCapacity: 1024, MemoryHeadroom: 1024},
+	})
+}
+
+func (s *S) TestPoolCapacityReportFilterPool(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "pool2"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/1.6/pools/capacity?pool=pool2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var report []capacity.PoolCapacity
+	err = json.NewDecoder(recorder.Body).Decode(&report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, []capacity.PoolCapacity{{Pool: "pool2"}})
+}
+
+func (s *S) TestPoolCapacityReportPoolPermission(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "pool2"})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermPoolReadCapacity,
+		Context: permission.Context(permission.CtxPool, "pool2"),
+	})
+	request, err := http.NewRequest(http.MethodGet, "/1.6/pools/capacity", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var report []capacity.PoolCapacity
+	err = json.NewDecoder(recorder.Body).Decode(&report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, []capacity.PoolCapacity{{Pool: "pool2"}})
+	request, err = http.NewRequest(http.MethodGet, "/1.6/pools/capacity?pool=test1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestPoolCapacityReportUnauthorized(c *check.C) {
+	token := userWithPermission(c)
+	request, err := http.NewRequest(http.MethodGet, "/1.6/pools/capacity", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -13,10 +13,12 @@ import (
 
 	"github.com/ajg/form"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
 	"github.com/tsuru/tsuru/provision/pool"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	"gopkg.in/check.v1"
@@ -34,6 +36,18 @@ func (s *S) TestAddPoolNameIsRequired(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Equals, pool.ErrPoolNameIsRequired.Error()+"\n")
 }
 
+func (s *S) TestAddPoolDedicatedPublic(c *check.C) {
+	b := bytes.NewBufferString("name=pool1&dedicated=true&public=true")
+	request, err := http.NewRequest(http.MethodPost, "/pools", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, pool.ErrDedicatedPoolPublic.Error()+"\n")
+}
+
 func (s *S) TestAddPoolDefaultPoolAlreadyExists(c *check.C) {
 	b := bytes.NewBufferString("name=pool1&default=true")
 	req, err := http.NewRequest(http.MethodPost, "/pools", b)
@@ -160,6 +174,36 @@ func (s *S) TestRemovePoolHandler(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestRemovePoolHandlerDry(c *check.C) {
+	opts := pool.AddPoolOptions{
+		Name: "pool1",
+	}
+	err := pool.AddPool(opts)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/pools/pool1?dry=true", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(rec.Body.String(), check.Equals, `{"pools":["pool1"]}`+"\n")
+	_, err = pool.GetPoolByName("pool1")
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(&event.Filter{Target: event.Target{Type: event.TargetTypePool, Value: "pool1"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 0)
+}
+
+func (s *S) TestRemovePoolHandlerDryNotFound(c *check.C) {
+	req, err := http.NewRequest(http.MethodDelete, "/pools/not-found?dry=true", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestRemovePoolHandlerWithApp(c *check.C) {
 	s.mockService.Team.OnList = func() ([]authTypes.Team, error) {
 		return []authTypes.Team{{Name: s.team.Name}}, nil
@@ -651,6 +695,144 @@ func (s *S) TestPoolUpdateNotFound(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
 }
 
+func (s *S) TestPoolEnvSet(c *check.C) {
+	b := strings.NewReader("Envs.0.Name=REGION&Envs.0.Value=us-east&Envs.1.Name=TOKEN&Envs.1.Value=abc&Private=true")
+	request, err := http.NewRequest(http.MethodPost, "/1.6/pools/test1/env", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	p, err := pool.GetPoolByName("test1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Envs, check.DeepEquals, []bind.EnvVar{
+		{Name: "REGION", Value: "us-east"},
+		{Name: "TOKEN", Value: "abc"},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePool, Value: "test1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.env",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "test1"},
+			{"name": "Envs.0.Name", "value": "REGION"},
+			{"name": "Envs.0.Value", "value": "*****"},
+			{"name": "Envs.1.Name", "value": "TOKEN"},
+			{"name": "Envs.1.Value", "value": "*****"},
+			{"name": "Private", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPoolDNSSet(c *check.C) {
+	b := strings.NewReader("nameserver=10.0.0.10&nameserver=10.0.0.11&search=corp.example.com&ndots=2")
+	request, err := http.NewRequest(http.MethodPut, "/1.6/pools/test1/dns", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	p, err := pool.GetPoolByName("test1")
+	c.Assert(err, check.IsNil)
+	ndots := 2
+	c.Assert(p.DNS, check.DeepEquals, &pool.DNSConfig{
+		Nameservers: []string{"10.0.0.10", "10.0.0.11"},
+		Searches:    []string{"corp.example.com"},
+		Ndots:       &ndots,
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePool, Value: "test1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.dns",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "test1"},
+			{"name": "nameserver", "value": []interface{}{"10.0.0.10", "10.0.0.11"}},
+			{"name": "search", "value": "corp.example.com"},
+			{"name": "ndots", "value": "2"},
+		},
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest(http.MethodDelete, "/1.6/pools/test1/dns", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	p, err = pool.GetPoolByName("test1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.DNS, check.IsNil)
+}
+
+func (s *S) TestPoolDNSSetInvalid(c *check.C) {
+	b := strings.NewReader("nameserver=dns.corp")
+	request, err := http.NewRequest(http.MethodPut, "/1.6/pools/test1/dns", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid dns nameserver \"dns.corp\", must be an IP address\n")
+}
+
+func (s *S) TestPoolDNSSetNotFound(c *check.C) {
+	b := strings.NewReader("nameserver=10.0.0.10")
+	request, err := http.NewRequest(http.MethodPut, "/1.6/pools/not-found/dns", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPoolEnvSetNotFound(c *check.C) {
+	b := strings.NewReader("Envs.0.Name=REGION&Envs.0.Value=us-east")
+	request, err := http.NewRequest(http.MethodPost, "/1.6/pools/not-found/env", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPoolEnvSetNoPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermPoolUpdateEnv,
+		Context: permission.Context(permission.CtxPool, "otherpool"),
+	})
+	b := strings.NewReader("Envs.0.Name=REGION&Envs.0.Value=us-east")
+	request, err := http.NewRequest(http.MethodPost, "/1.6/pools/test1/env", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestPoolEnvUnset(c *check.C) {
+	err := pool.SetEnvs("test1", []bind.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/1.6/pools/test1/env?env=A", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	p, err := pool.GetPoolByName("test1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Envs, check.DeepEquals, []bind.EnvVar{{Name: "B", Value: "2"}})
+	request, err = http.NewRequest(http.MethodDelete, "/1.6/pools/test1/env", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
 func (s *S) TestPoolConstraint(c *check.C) {
 	err := pool.SetPoolConstraint(&pool.PoolConstraint{PoolExpr: "*", Field: pool.ConstraintTypeRouter, Values: []string{"*"}})
 	c.Assert(err, check.IsNil)
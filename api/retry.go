@@ -0,0 +1,84 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/retry"
+)
+
+// title: list retry operations
+// path: /retry/operations
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func retryOperationList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermRetryOperationRead) {
+		return permission.ErrUnauthorized
+	}
+	ops, err := retry.List(r.URL.Query().Get("status"))
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(ops)
+}
+
+// title: requeue retry operation
+// path: /retry/operations/{id}/requeue
+// method: POST
+// responses:
+//   200: OK
+//   400: Operation is not stuck
+//   401: Unauthorized
+//   404: Operation not found
+//   409: Operation already queued
+func retryOperationRequeue(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermRetryOperationUpdateRequeue) {
+		return permission.ErrUnauthorized
+	}
+	op, err := retry.Get(r.URL.Query().Get(":id"))
+	if err == retry.ErrOperationNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target: event.Target{Type: event.TargetTypeRetryOperation, Value: op.ID.Hex()},
+		Kind:   permission.PermRetryOperationUpdateRequeue,
+		Owner:  t,
+		CustomData: []map[string]interface{}{
+			{"name": "ID", "value": op.ID.Hex()},
+			{"name": "Kind", "value": op.Kind},
+		},
+		Allowed: event.Allowed(permission.PermRetryOperationRead),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = op.Requeue()
+	if err == retry.ErrNotStuck {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err == retry.ErrAlreadyQueued {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/retry"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) addStuckOperation(c *check.C) *retry.Operation {
+	config.Set("retry:max-attempts", 1)
+	defer config.Unset("retry:max-attempts")
+	retry.RegisterHandler("test-kind", func(params map[string]string) error {
+		return errors.New("my error")
+	})
+	op, err := retry.Enqueue("test-kind", map[string]string{"app": "myapp"})
+	c.Assert(err, check.IsNil)
+	err = retry.RunPending()
+	c.Assert(err, check.IsNil)
+	op, err = retry.Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, retry.StatusStuck)
+	return op
+}
+
+func (s *S) TestRetryOperationList(c *check.C) {
+	op := s.addStuckOperation(c)
+	_, err := retry.Enqueue("test-kind", map[string]string{"app": "otherapp"})
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermRetryOperationRead,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/retry/operations?status=stuck", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var ops []retry.Operation
+	err = json.NewDecoder(recorder.Body).Decode(&ops)
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 1)
+	c.Assert(ops[0].ID, check.Equals, op.ID)
+	c.Assert(ops[0].Params, check.DeepEquals, map[string]string{"app": "myapp"})
+	c.Assert(ops[0].LastError, check.Equals, "my error")
+}
+
+func (s *S) TestRetryOperationListEmpty(c *check.C) {
+	request, err := http.NewRequest("GET", "/retry/operations", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestRetryOperationListWithoutPermission(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/retry/operations", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestRetryOperationRequeue(c *check.C) {
+	op := s.addStuckOperation(c)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermRetryOperationUpdateRequeue,
+		Context: permission.PermissionContext{CtxType: permission.CtxGlobal},
+	})
+	request, err := http.NewRequest("POST", fmt.Sprintf("/retry/operations/%s/requeue", op.ID.Hex()), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	op, err = retry.Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, retry.StatusPending)
+	c.Assert(op.Attempts, check.Equals, 0)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeRetryOperation, Value: op.ID.Hex()},
+		Owner:  token.GetUserName(),
+		Kind:   "retry-operation.update.requeue",
+		StartCustomData: []map[string]interface{}{
+			{"name": "ID", "value": op.ID.Hex()},
+			{"name": "Kind", "value": "test-kind"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestRetryOperationRequeueNotStuck(c *check.C) {
+	op, err := retry.Enqueue("test-kind", nil)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/retry/operations/%s/requeue", op.ID.Hex()), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, retry.ErrNotStuck.Error()+"\n")
+}
+
+func (s *S) TestRetryOperationRequeueNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/retry/operations/invalid/requeue", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
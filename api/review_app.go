@@ -0,0 +1,178 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/review"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/quota"
+)
+
+// title: create review app
+// path: /apps/{app}/review-apps
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   200: Review app expiration extended
+//   201: Review app created
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Quota exceeded
+//   404: App not found
+//   409: App already exists
+func reviewAppCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateReviewAppCreate,
+		contextsForApp(&a)...,
+	) && permission.Check(t, permission.PermAppCreate,
+		permission.Context(permission.CtxTeam, a.TeamOwner),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var ttl time.Duration
+	if v := r.FormValue("ttl"); v != "" {
+		seconds, errAtoi := strconv.Atoi(v)
+		if errAtoi != nil || seconds <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "ttl must be a positive number of seconds"}
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateReviewAppCreate,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	reviewApp, created, err := review.Create(&a, review.CreateOpts{
+		Ref:    r.FormValue("ref"),
+		TTL:    ttl,
+		User:   u,
+		Writer: evt,
+	})
+	if err != nil {
+		if verr, ok := err.(*errors.ValidationError); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: verr.Message}
+		}
+		if e, ok := err.(*app.AppCreationError); ok {
+			if e.Err == app.ErrAppAlreadyExists {
+				return &errors.HTTP{Code: http.StatusConflict, Message: e.Error()}
+			}
+			if _, ok := e.Err.(*quota.QuotaExceededError); ok {
+				return &errors.HTTP{Code: http.StatusForbidden, Message: "Quota exceeded"}
+			}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return json.NewEncoder(w).Encode(reviewApp)
+}
+
+// title: review apps list
+// path: /apps/{app}/review-apps
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func reviewAppList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadReviewApp,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	reviewApps, err := review.List(a.Name)
+	if err != nil {
+		return err
+	}
+	if len(reviewApps) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(reviewApps)
+}
+
+// title: remove review app
+// path: /apps/{app}/review-apps/{name}
+// method: DELETE
+// produce: application/x-json-stream
+// responses:
+//   200: Review app removed
+//   401: Unauthorized
+//   404: App or review app not found
+func reviewAppRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateReviewAppRemove,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	reviewApp, err := review.Get(r.URL.Query().Get(":name"))
+	if err == review.ErrReviewAppNotFound || (err == nil && reviewApp.Parent != a.Name) {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: review.ErrReviewAppNotFound.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  appTarget(reviewApp.Name),
+		Kind:    permission.PermAppUpdateReviewAppRemove,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	return reviewApp.Remove(evt, requestIDHeader(r))
+}
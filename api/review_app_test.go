@@ -0,0 +1,133 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/review"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestReviewAppCreate(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("ref=feature/login&ttl=3600")
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/review-apps", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var reviewApp review.ReviewApp
+	err = json.NewDecoder(recorder.Body).Decode(&reviewApp)
+	c.Assert(err, check.IsNil)
+	c.Assert(reviewApp.Name, check.Equals, "myapp-feature-login")
+	c.Assert(reviewApp.Ref, check.Equals, "feature/login")
+	c.Assert(reviewApp.ExpiresAt.Sub(reviewApp.CreatedAt), check.Equals, time.Hour)
+	_, err = app.GetByName("myapp-feature-login")
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.review-app.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":app", "value": "myapp"},
+			{"name": "ref", "value": "feature/login"},
+			{"name": "ttl", "value": "3600"},
+		},
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("POST", "/1.6/apps/myapp/review-apps", strings.NewReader("ref=feature/login"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestReviewAppCreateInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	for _, body := range []string{"ttl=60", "ref=pr-1&ttl=-1", "ref=//"} {
+		request, err := http.NewRequest("POST", "/1.6/apps/myapp/review-apps", strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("body: %s", body))
+	}
+}
+
+func (s *S) TestReviewAppCreateRequiresAppCreatePermission(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppUpdateReviewAppCreate,
+		Context: permission.Context(permission.CtxApp, "myapp"),
+	}, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, "myapp"),
+	})
+	request, err := http.NewRequest("POST", "/1.6/apps/myapp/review-apps", strings.NewReader("ref=pr-1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestReviewAppListAndRemove(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/review-apps", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	_, _, err = review.Create(&a, review.CreateOpts{Ref: "pr-1", User: s.user})
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var reviewApps []review.ReviewApp
+	err = json.NewDecoder(recorder.Body).Decode(&reviewApps)
+	c.Assert(err, check.IsNil)
+	c.Assert(reviewApps, check.HasLen, 1)
+	c.Assert(reviewApps[0].Name, check.Equals, "myapp-pr-1")
+	request, err = http.NewRequest("DELETE", "/1.6/apps/myapp/review-apps/myapp-pr-1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = app.GetByName("myapp-pr-1")
+	c.Assert(err, check.Equals, app.ErrAppNotFound)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp-pr-1"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.review-app.remove",
+	}, eventtest.HasEvent)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -277,3 +277,30 @@ func listAppRouters(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	}
 	return json.NewEncoder(w).Encode(routers)
 }
+
+// title: app routers health
+// path: /apps/{app}/routers/health
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+func appRoutersHealth(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadRouter,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	health, err := a.RoutersHealth()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(health)
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 
 	"github.com/tsuru/config"
@@ -44,6 +45,12 @@ func (s *S) TestRoutersList(c *check.C) {
 	recorder := httptest.NewRecorder()
 	expected := []router.PlanRouter{
 		{Name: "fake", Type: "fake", Default: true},
+		{Name: "fake-accesslog", Type: "fake-accesslog"},
+		{Name: "fake-errorpage", Type: "fake-errorpage"},
+		{Name: "fake-mirror", Type: "fake-mirror"},
+		{Name: "fake-protocol", Type: "fake-protocol"},
+		{Name: "fake-status", Type: "fake-status"},
+		{Name: "fake-sticky", Type: "fake-sticky"},
 		{Name: "fake-tls", Type: "fake-tls"},
 		{Name: "router1", Type: "foo"},
 		{Name: "router2", Type: "bar"},
@@ -109,6 +116,12 @@ func (s *S) TestListRoutersWithInfo(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(routers, check.DeepEquals, []router.PlanRouter{
 		{Name: "fake", Type: "fake", Default: true},
+		{Name: "fake-accesslog", Type: "fake-accesslog"},
+		{Name: "fake-errorpage", Type: "fake-errorpage"},
+		{Name: "fake-mirror", Type: "fake-mirror"},
+		{Name: "fake-protocol", Type: "fake-protocol"},
+		{Name: "fake-status", Type: "fake-status"},
+		{Name: "fake-sticky", Type: "fake-sticky"},
 		{Name: "fake-tls", Type: "fake-tls"},
 		{Name: "my-fake-info", Type: "fake-info", Info: map[string]string{
 			"info1": "val1",
@@ -171,6 +184,52 @@ func (s *S) TestListAppRoutersWithStatus(c *check.C) {
 	})
 }
 
+func (s *S) TestAppRoutersHealth(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppReadRouter,
+		Context: permission.Context(permission.CtxTeam, "tsuruteam"),
+	})
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake"}, {Name: "fake-status"}}}
+	err := app.CreateApp(&myapp, s.user)
+	c.Assert(err, check.IsNil)
+	addr, err := url.Parse("http://10.0.0.1:8080")
+	c.Assert(err, check.IsNil)
+	err = routertest.StatusRouter.AddRoutes(myapp.Name, []*url.URL{addr})
+	c.Assert(err, check.IsNil)
+	routertest.StatusRouter.RouteStatus[addr.String()] = router.RouteStatus{Status: router.RouteHealthDown, LastFailure: "timeout"}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/routers/health", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var health []app.RouterHealth
+	err = json.Unmarshal(recorder.Body.Bytes(), &health)
+	c.Assert(err, check.IsNil)
+	c.Assert(health, check.DeepEquals, []app.RouterHealth{
+		{Router: "fake-status", Routes: []app.RouteHealth{
+			{RouteStatus: router.RouteStatus{Address: "http://10.0.0.1:8080", Status: router.RouteHealthDown, LastFailure: "timeout"}},
+		}},
+	})
+}
+
+func (s *S) TestAppRoutersHealthUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppReadRouter,
+		Context: permission.Context(permission.CtxTeam, "otherteam"),
+	})
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(&myapp, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.6/apps/myapp/routers/health", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *S) TestListAppRoutersEmpty(c *check.C) {
 	token := userWithPermission(c, permission.Permission{
 		Scheme:  permission.PermAppReadRouter,
@@ -0,0 +1,1035 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/validation"
+)
+
+// The SCIM 2.0 server (RFC 7643 and RFC 7644) lets identity providers
+// provision users and teams. Users are identified by their email and SCIM
+// groups map to teams, identified by their name. Members of a group are the
+// users holding the team roles in the context of the team: the role set in
+// scim:team-role or, by default, the roles granted on team creation.
+
+const (
+	scimContentType  = "application/scim+json"
+	scimUserSchema   = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema  = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema  = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimDefaultCount = 100
+)
+
+var scimFilterRegexp = regexp.MustCompile(`^\s*(\w+)\s+eq\s+"([^"]*)"\s*$`)
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimUser struct {
+	Schemas  []string    `json:"schemas"`
+	ID       string      `json:"id"`
+	UserName string      `json:"userName"`
+	Password string      `json:"password,omitempty"`
+	Active   *bool       `json:"active,omitempty"`
+	Emails   []scimValue `json:"emails,omitempty"`
+	Groups   []scimValue `json:"groups,omitempty"`
+	Meta     *scimMeta   `json:"meta,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	DisplayName string      `json:"displayName"`
+	Members     []scimValue `json:"members"`
+	Meta        *scimMeta   `json:"meta,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type scimPatch struct {
+	Operations []scimOperation `json:"Operations"`
+}
+
+type scimOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// scimHandler is the handler of SCIM endpoints. Callers must have the
+// user.provision permission and errors are written in the SCIM format.
+type scimHandler func(http.ResponseWriter, *http.Request, auth.Token) error
+
+func (fn scimHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var err error
+	t := context.GetAuthToken(r)
+	if t == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer realm=\"tsuru\" scope=\"tsuru\"")
+		err = tokenRequiredErr
+	} else if !permission.Check(t, permission.PermUserProvision) {
+		err = permission.ErrUnauthorized
+	} else {
+		err = fn(w, r, t)
+	}
+	if err != nil {
+		writeSCIMError(w, r, err)
+	}
+}
+
+func writeSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusInternalServerError
+	switch e := pkgErrors.Cause(err).(type) {
+	case *errors.ValidationError:
+		code = http.StatusBadRequest
+	case *errors.HTTP:
+		code = e.Code
+	}
+	if code >= http.StatusInternalServerError {
+		log.Errorf("failure running HTTP request %s %s (%d): %s", r.Method, r.URL.Path, code, err)
+	}
+	scimErr := scimError{
+		Schemas: []string{scimErrorSchema},
+		Status:  strconv.Itoa(code),
+		Detail:  err.Error(),
+	}
+	if code == http.StatusConflict {
+		scimErr.ScimType = "uniqueness"
+	}
+	writeSCIM(w, code, scimErr)
+}
+
+func writeSCIM(w http.ResponseWriter, code int, data interface{}) error {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(data)
+}
+
+func decodeSCIM(r *http.Request, data interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(data)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("unable to parse request: %s", err)}
+	}
+	return nil
+}
+
+// parseSCIMFilter parses the filter of list requests. Only equality on the
+// given attribute is supported, which is what identity providers use to look
+// up resources before provisioning them.
+func parseSCIMFilter(r *http.Request, attr string) (value string, filtered bool, err error) {
+	filter := r.URL.Query().Get("filter")
+	if filter == "" {
+		return "", false, nil
+	}
+	parts := scimFilterRegexp.FindStringSubmatch(filter)
+	if parts == nil || !strings.EqualFold(parts[1], attr) {
+		return "", false, &errors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf(`unsupported filter %q, only %s eq "value" is supported`, filter, attr),
+		}
+	}
+	return parts[2], true, nil
+}
+
+func scimPage(r *http.Request, resources []interface{}) scimListResponse {
+	startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil {
+		count = scimDefaultCount
+	}
+	if count < 0 {
+		count = 0
+	}
+	page := []interface{}{}
+	if startIndex <= len(resources) {
+		end := startIndex - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+		page = resources[startIndex-1 : end]
+	}
+	return scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+func parseSCIMBool(raw json.RawMessage) (bool, error) {
+	var value interface{}
+	err := json.Unmarshal(raw, &value)
+	if err == nil {
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			// Some identity providers send booleans as strings.
+			if b, parseErr := strconv.ParseBool(v); parseErr == nil {
+				return b, nil
+			}
+		}
+	}
+	return false, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid boolean value: %s", raw)}
+}
+
+// scimTeamRoles returns the roles granting membership on teams.
+func scimTeamRoles() ([]string, error) {
+	if roleName, _ := config.GetString("scim:team-role"); roleName != "" {
+		role, err := permission.FindRole(roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role.ContextType != permission.CtxTeam {
+			return nil, pkgErrors.Errorf("scim:team-role %q must have the %q context", roleName, permission.CtxTeam)
+		}
+		return []string{roleName}, nil
+	}
+	roles, err := permission.ListRolesForEvent(permission.RoleEventTeamCreate)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, pkgErrors.New("no role to grant team membership, set scim:team-role or a default role for team-create")
+	}
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+	return names, nil
+}
+
+func userTeams(u *auth.User, roles []string) []string {
+	teamSet := map[string]struct{}{}
+	for _, roleData := range u.Roles {
+		if roleData.ContextValue != "" && containsString(roles, roleData.Name) {
+			teamSet[roleData.ContextValue] = struct{}{}
+		}
+	}
+	teams := make([]string, 0, len(teamSet))
+	for team := range teamSet {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// teamMembers returns the emails of the members of each team.
+func teamMembers(roles []string) (map[string][]string, error) {
+	members := map[string][]string{}
+	seen := map[string]struct{}{}
+	for _, roleName := range roles {
+		users, err := auth.ListUsersWithRole(roleName)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			for _, roleData := range u.Roles {
+				key := roleData.ContextValue + "\x00" + u.Email
+				if roleData.Name != roleName || roleData.ContextValue == "" {
+					continue
+				}
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				members[roleData.ContextValue] = append(members[roleData.ContextValue], u.Email)
+			}
+		}
+	}
+	for team := range members {
+		sort.Strings(members[team])
+	}
+	return members, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// updateTeamMembers grants the team roles to the users in add and revokes
+// them from the users in remove, syncing their repository access.
+func updateTeamMembers(team string, roles []string, add, remove []string) error {
+	var users []auth.User
+	adding := map[string]bool{}
+	for _, email := range add {
+		adding[email] = true
+	}
+	for _, email := range remove {
+		if _, ok := adding[email]; !ok {
+			adding[email] = false
+		}
+	}
+	emails := make([]string, 0, len(adding))
+	for email := range adding {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	for _, email := range emails {
+		u, err := auth.GetUserByEmail(email)
+		if err == authTypes.ErrUserNotFound {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("User %q not found.", email)}
+		}
+		if err != nil {
+			return err
+		}
+		users = append(users, *u)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	return runWithPermSync(users, func() error {
+		for i := range users {
+			for _, roleName := range roles {
+				var err error
+				if adding[users[i].Email] {
+					err = users[i].AddRole(roleName, team)
+				} else {
+					err = users[i].RemoveRole(roleName, team)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func scimUserFromUser(u *auth.User, roles []string) scimUser {
+	active := !u.Disabled
+	user := scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       u.Email,
+		UserName: u.Email,
+		Active:   &active,
+		Emails:   []scimValue{{Value: u.Email, Primary: true}},
+		Meta:     &scimMeta{ResourceType: "User", Location: "/scim/v2/Users/" + u.Email},
+	}
+	for _, team := range userTeams(u, roles) {
+		user.Groups = append(user.Groups, scimValue{Value: team, Display: team})
+	}
+	return user
+}
+
+func scimGroupFromTeam(team string, members []string) scimGroup {
+	group := scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          team,
+		DisplayName: team,
+		Members:     []scimValue{},
+		Meta:        &scimMeta{ResourceType: "Group", Location: "/scim/v2/Groups/" + team},
+	}
+	for _, email := range members {
+		group.Members = append(group.Members, scimValue{Value: email, Display: email})
+	}
+	return group
+}
+
+func scimUserByID(id string) (*auth.User, error) {
+	u, err := auth.GetUserByEmail(id)
+	if err == authTypes.ErrUserNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("User %q not found.", id)}
+	}
+	return u, err
+}
+
+func scimTeamByID(id string) error {
+	_, err := servicemanager.Team.FindByName(id)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("Team %q not found.", id)}
+	}
+	return err
+}
+
+func setUserActive(u *auth.User, active bool) error {
+	if active {
+		return activateUser(u)
+	}
+	return deactivateUser(u)
+}
+
+// title: scim list users
+// path: /scim/v2/Users
+// method: GET
+// produce: application/scim+json
+// responses:
+//   200: OK
+//   400: Invalid filter
+//   401: Unauthorized
+//   403: Forbidden
+func scimListUsers(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	email, filtered, err := parseSCIMFilter(r, "userName")
+	if err != nil {
+		return err
+	}
+	var users []auth.User
+	if filtered {
+		u, err := auth.GetUserByEmail(email)
+		if err != nil && err != authTypes.ErrUserNotFound {
+			return err
+		}
+		if u != nil {
+			users = append(users, *u)
+		}
+	} else {
+		users, err = auth.ListUsers()
+		if err != nil {
+			return err
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	resources := make([]interface{}, len(users))
+	for i := range users {
+		resources[i] = scimUserFromUser(&users[i], roles)
+	}
+	return writeSCIM(w, http.StatusOK, scimPage(r, resources))
+}
+
+// title: scim get user
+// path: /scim/v2/Users/{id}
+// method: GET
+// produce: application/scim+json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func scimGetUser(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	u, err := scimUserByID(r.URL.Query().Get(":id"))
+	if err != nil {
+		return err
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	return writeSCIM(w, http.StatusOK, scimUserFromUser(u, roles))
+}
+
+// title: scim create user
+// path: /scim/v2/Users
+// method: POST
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//   201: User created
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   409: User already exists
+func scimCreateUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var data scimUser
+	err = decodeSCIM(r, &data)
+	if err != nil {
+		return err
+	}
+	email := data.UserName
+	if email == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "userName is required"}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserCreate,
+		Owner:      t,
+		CustomData: map[string]interface{}{"userName": email, "active": data.Active},
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	if _, err = auth.GetUserByEmail(email); err == nil {
+		return &errors.HTTP{Code: http.StatusConflict, Message: fmt.Sprintf("User %q already exists.", email)}
+	}
+	u := &auth.User{Email: email, Password: data.Password}
+	if data.Password == "" {
+		// Users provisioned by identity providers usually have no password,
+		// they log in through the provider or, in managed schemes, after
+		// resetting their passwords.
+		if !validation.ValidateEmail(email) {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "userName must be a valid email"}
+		}
+		err = u.Create()
+	} else {
+		u, err = app.AuthScheme.Create(u)
+	}
+	if err != nil {
+		return handleAuthError(err)
+	}
+	if data.Active != nil && !*data.Active {
+		err = deactivateUser(u)
+		if err != nil {
+			return err
+		}
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	return writeSCIM(w, http.StatusCreated, scimUserFromUser(u, roles))
+}
+
+// title: scim replace user
+// path: /scim/v2/Users/{id}
+// method: PUT
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//   200: User updated
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func scimReplaceUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var data scimUser
+	err = decodeSCIM(r, &data)
+	if err != nil {
+		return err
+	}
+	id := r.URL.Query().Get(":id")
+	if data.UserName != "" && data.UserName != id {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "userName can't be changed"}
+	}
+	u, err := scimUserByID(id)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     userTarget(u.Email),
+		Kind:       permission.PermUserProvision,
+		Owner:      t,
+		CustomData: map[string]interface{}{"active": data.Active},
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, u.Email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	if data.Active != nil {
+		err = setUserActive(u, *data.Active)
+		if err != nil {
+			return err
+		}
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	return writeSCIM(w, http.StatusOK, scimUserFromUser(u, roles))
+}
+
+// title: scim patch user
+// path: /scim/v2/Users/{id}
+// method: PATCH
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//   200: User updated
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func scimPatchUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var patch scimPatch
+	err = decodeSCIM(r, &patch)
+	if err != nil {
+		return err
+	}
+	u, err := scimUserByID(r.URL.Query().Get(":id"))
+	if err != nil {
+		return err
+	}
+	// Only the active attribute is stored, changes to other attributes
+	// are accepted and ignored.
+	var active *bool
+	for _, op := range patch.Operations {
+		if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+			continue
+		}
+		raw := op.Value
+		if op.Path == "" {
+			var values map[string]json.RawMessage
+			if json.Unmarshal(op.Value, &values) != nil {
+				continue
+			}
+			raw = nil
+			for key, value := range values {
+				if strings.EqualFold(key, "active") {
+					raw = value
+				}
+			}
+		} else if !strings.EqualFold(op.Path, "active") {
+			continue
+		}
+		if raw == nil {
+			continue
+		}
+		value, parseErr := parseSCIMBool(raw)
+		if parseErr != nil {
+			return parseErr
+		}
+		active = &value
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     userTarget(u.Email),
+		Kind:       permission.PermUserProvision,
+		Owner:      t,
+		CustomData: map[string]interface{}{"active": active},
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, u.Email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	if active != nil {
+		err = setUserActive(u, *active)
+		if err != nil {
+			return err
+		}
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	return writeSCIM(w, http.StatusOK, scimUserFromUser(u, roles))
+}
+
+// title: scim delete user
+// path: /scim/v2/Users/{id}
+// method: DELETE
+// responses:
+//   204: User removed
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func scimDeleteUser(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	u, err := scimUserByID(r.URL.Query().Get(":id"))
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  userTarget(u.Email),
+		Kind:    permission.PermUserDelete,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, u.Email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = deleteUser(u)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// title: scim list groups
+// path: /scim/v2/Groups
+// method: GET
+// produce: application/scim+json
+// responses:
+//   200: OK
+//   400: Invalid filter
+//   401: Unauthorized
+//   403: Forbidden
+func scimListGroups(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	name, filtered, err := parseSCIMFilter(r, "displayName")
+	if err != nil {
+		return err
+	}
+	var teams []string
+	if filtered {
+		err = scimTeamByID(name)
+		if err == nil {
+			teams = append(teams, name)
+		} else if e, ok := err.(*errors.HTTP); !ok || e.Code != http.StatusNotFound {
+			return err
+		}
+	} else {
+		allTeams, err := servicemanager.Team.List()
+		if err != nil {
+			return err
+		}
+		for _, team := range allTeams {
+			teams = append(teams, team.Name)
+		}
+		sort.Strings(teams)
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	members, err := teamMembers(roles)
+	if err != nil {
+		return err
+	}
+	resources := make([]interface{}, len(teams))
+	for i, team := range teams {
+		resources[i] = scimGroupFromTeam(team, members[team])
+	}
+	return writeSCIM(w, http.StatusOK, scimPage(r, resources))
+}
+
+// title: scim get group
+// path: /scim/v2/Groups/{id}
+// method: GET
+// produce: application/scim+json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func scimGetGroup(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	id := r.URL.Query().Get(":id")
+	err := scimTeamByID(id)
+	if err != nil {
+		return err
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	members, err := teamMembers(roles)
+	if err != nil {
+		return err
+	}
+	return writeSCIM(w, http.StatusOK, scimGroupFromTeam(id, members[id]))
+}
+
+func scimMemberEmails(members []scimValue) []string {
+	emails := make([]string, len(members))
+	for i, m := range members {
+		emails[i] = m.Value
+	}
+	return emails
+}
+
+// replaceTeamMembers updates the members of the team to the given users.
+func replaceTeamMembers(team string, roles []string, emails []string) error {
+	members, err := teamMembers(roles)
+	if err != nil {
+		return err
+	}
+	var add, remove []string
+	for _, email := range emails {
+		if !containsString(members[team], email) {
+			add = append(add, email)
+		}
+	}
+	for _, email := range members[team] {
+		if !containsString(emails, email) {
+			remove = append(remove, email)
+		}
+	}
+	return updateTeamMembers(team, roles, add, remove)
+}
+
+// title: scim create group
+// path: /scim/v2/Groups
+// method: POST
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//   201: Team created
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   409: Team already exists
+func scimCreateGroup(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var data scimGroup
+	err = decodeSCIM(r, &data)
+	if err != nil {
+		return err
+	}
+	name := data.DisplayName
+	if name == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: authTypes.ErrInvalidTeamName.Error()}
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     teamTarget(name),
+		Kind:       permission.PermTeamCreate,
+		Owner:      t,
+		CustomData: map[string]interface{}{"displayName": name, "members": scimMemberEmails(data.Members)},
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permission.CtxTeam, name)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	user := authTypes.User(*u)
+	err = servicemanager.Team.Create(name, &user)
+	switch err {
+	case authTypes.ErrInvalidTeamName:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case authTypes.ErrTeamAlreadyExists:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	emails := scimMemberEmails(data.Members)
+	err = updateTeamMembers(name, roles, emails, nil)
+	if err != nil {
+		return err
+	}
+	sort.Strings(emails)
+	return writeSCIM(w, http.StatusCreated, scimGroupFromTeam(name, emails))
+}
+
+// title: scim replace group
+// path: /scim/v2/Groups/{id}
+// method: PUT
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//   200: Team updated
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func scimReplaceGroup(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var data scimGroup
+	err = decodeSCIM(r, &data)
+	if err != nil {
+		return err
+	}
+	id := r.URL.Query().Get(":id")
+	if data.DisplayName != "" && data.DisplayName != id {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "teams can't be renamed"}
+	}
+	err = scimTeamByID(id)
+	if err != nil {
+		return err
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	emails := scimMemberEmails(data.Members)
+	evt, err := event.New(&event.Opts{
+		Target:     teamTarget(id),
+		Kind:       permission.PermUserProvision,
+		Owner:      t,
+		CustomData: map[string]interface{}{"members": emails},
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permission.CtxTeam, id)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = replaceTeamMembers(id, roles, emails)
+	if err != nil {
+		return err
+	}
+	return scimGetGroup(w, r, t)
+}
+
+var scimMemberPathRegexp = regexp.MustCompile(`^(?i:members)\[\s*(?i:value)\s+(?i:eq)\s+"([^"]*)"\s*\]$`)
+
+// title: scim patch group
+// path: /scim/v2/Groups/{id}
+// method: PATCH
+// consume: application/scim+json
+// produce: application/scim+json
+// responses:
+//   200: Team updated
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+//   404: Not found
+func scimPatchGroup(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var patch scimPatch
+	err = decodeSCIM(r, &patch)
+	if err != nil {
+		return err
+	}
+	id := r.URL.Query().Get(":id")
+	err = scimTeamByID(id)
+	if err != nil {
+		return err
+	}
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     teamTarget(id),
+		Kind:       permission.PermUserProvision,
+		Owner:      t,
+		CustomData: scimOperationsData(patch.Operations),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permission.CtxTeam, id)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	for _, op := range patch.Operations {
+		err = applySCIMGroupOperation(id, roles, op)
+		if err != nil {
+			return err
+		}
+	}
+	return scimGetGroup(w, r, t)
+}
+
+func scimOperationsData(ops []scimOperation) []map[string]string {
+	data := make([]map[string]string, len(ops))
+	for i, op := range ops {
+		data[i] = map[string]string{"op": op.Op, "path": op.Path, "value": string(op.Value)}
+	}
+	return data
+}
+
+func applySCIMGroupOperation(team string, roles []string, op scimOperation) error {
+	invalidOp := &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("unsupported operation %q on %q", op.Op, op.Path)}
+	var members []scimValue
+	if op.Path == "" {
+		var values struct {
+			DisplayName string      `json:"displayName"`
+			Members     []scimValue `json:"members"`
+		}
+		if json.Unmarshal(op.Value, &values) != nil {
+			return invalidOp
+		}
+		if values.DisplayName != "" && values.DisplayName != team {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "teams can't be renamed"}
+		}
+		if values.Members == nil {
+			return nil
+		}
+		members = values.Members
+	} else if parts := scimMemberPathRegexp.FindStringSubmatch(op.Path); parts != nil {
+		if !strings.EqualFold(op.Op, "remove") {
+			return invalidOp
+		}
+		return updateTeamMembers(team, roles, nil, []string{parts[1]})
+	} else if strings.EqualFold(op.Path, "members") {
+		if len(op.Value) > 0 && json.Unmarshal(op.Value, &members) != nil {
+			return invalidOp
+		}
+	} else {
+		return invalidOp
+	}
+	emails := scimMemberEmails(members)
+	switch strings.ToLower(op.Op) {
+	case "add":
+		return updateTeamMembers(team, roles, emails, nil)
+	case "remove":
+		if op.Path != "" && len(op.Value) == 0 {
+			return replaceTeamMembers(team, roles, nil)
+		}
+		return updateTeamMembers(team, roles, nil, emails)
+	case "replace":
+		return replaceTeamMembers(team, roles, emails)
+	}
+	return invalidOp
+}
+
+// title: scim delete group
+// path: /scim/v2/Groups/{id}
+// method: DELETE
+// responses:
+//   204: Team removed
+//   401: Unauthorized
+//   403: Team still in use
+//   404: Not found
+func scimDeleteGroup(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	id := r.URL.Query().Get(":id")
+	roles, err := scimTeamRoles()
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:  teamTarget(id),
+		Kind:    permission.PermTeamDelete,
+		Owner:   t,
+		Allowed: event.Allowed(permission.PermTeamReadEvents, permission.Context(permission.CtxTeam, id)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = servicemanager.Team.Remove(id)
+	if err != nil {
+		if _, ok := err.(*authTypes.ErrTeamStillUsed); ok {
+			msg := fmt.Sprintf("This team cannot be removed because there are still references to it:\n%s", err)
+			return &errors.HTTP{Code: http.StatusForbidden, Message: msg}
+		}
+		if err == authTypes.ErrTeamNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("Team %q not found.", id)}
+		}
+		return err
+	}
+	err = replaceTeamMembers(id, roles, nil)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"gopkg.in/check.v1"
+)
+
+func (s *AuthSuite) scimRequest(c *check.C, method, path, body string) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(method, path, strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", scimContentType)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *AuthSuite) setSCIMTeamRole(c *check.C) func() {
+	_, err := permission.NewRole("scim-member", "team", "")
+	c.Assert(err, check.IsNil)
+	config.Set("scim:team-role", "scim-member")
+	s.mockTeamService.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name != s.team.Name {
+			return nil, authTypes.ErrTeamNotFound
+		}
+		return s.team, nil
+	}
+	return func() {
+		config.Unset("scim:team-role")
+	}
+}
+
+func (s *AuthSuite) TestSCIMRequiresPermission(c *check.C) {
+	token := userWithPermission(c)
+	request, err := http.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, scimContentType)
+	var scimErr scimError
+	err = json.NewDecoder(recorder.Body).Decode(&scimErr)
+	c.Assert(err, check.IsNil)
+	c.Assert(scimErr.Schemas, check.DeepEquals, []string{scimErrorSchema})
+	c.Assert(scimErr.Status, check.Equals, "403")
+}
+
+func (s *AuthSuite) TestSCIMCreateUser(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	recorder := s.scimRequest(c, http.MethodPost, "/scim/v2/Users",
+		`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"nobody@globo.com","password":"123456","active":true}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var user scimUser
+	err := json.NewDecoder(recorder.Body).Decode(&user)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.ID, check.Equals, "nobody@globo.com")
+	c.Assert(*user.Active, check.Equals, true)
+	_, err = auth.GetUserByEmail("nobody@globo.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget("nobody@globo.com"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.create",
+	}, eventtest.HasEvent)
+	recorder = s.scimRequest(c, http.MethodPost, "/scim/v2/Users", `{"userName":"nobody@globo.com","password":"123456"}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *AuthSuite) TestSCIMCreateUserWithoutPassword(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	recorder := s.scimRequest(c, http.MethodPost, "/scim/v2/Users", `{"userName":"idp@globo.com","active":true}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	_, err := auth.GetUserByEmail("idp@globo.com")
+	c.Assert(err, check.IsNil)
+	recorder = s.scimRequest(c, http.MethodPost, "/scim/v2/Users", `{"userName":"not-an-email"}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *AuthSuite) TestSCIMListUsersWithFilter(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	recorder := s.scimRequest(c, http.MethodGet, `/scim/v2/Users?filter=userName+eq+%22`+s.user.Email+`%22`, "")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var list struct {
+		TotalResults int
+		Resources    []scimUser
+	}
+	err := json.NewDecoder(recorder.Body).Decode(&list)
+	c.Assert(err, check.IsNil)
+	c.Assert(list.TotalResults, check.Equals, 1)
+	c.Assert(list.Resources[0].UserName, check.Equals, s.user.Email)
+	recorder = s.scimRequest(c, http.MethodGet, `/scim/v2/Users?filter=userName+eq+%22unknown@globo.com%22`, "")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	err = json.NewDecoder(recorder.Body).Decode(&list)
+	c.Assert(err, check.IsNil)
+	c.Assert(list.TotalResults, check.Equals, 0)
+	recorder = s.scimRequest(c, http.MethodGet, `/scim/v2/Users?filter=name+co+%22x%22`, "")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *AuthSuite) TestSCIMDeactivateUser(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	u := auth.User{Email: "nobody@globo.com", Password: "123456", APIKey: "key"}
+	_, err := nativeScheme.Create(&u)
+	c.Assert(err, check.IsNil)
+	token, err := nativeScheme.Login(map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	recorder := s.scimRequest(c, http.MethodPatch, "/scim/v2/Users/nobody@globo.com",
+		`{"Operations":[{"op":"replace","value":{"active":false}}]}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbUser, err := auth.GetUserByEmail(u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbUser.Disabled, check.Equals, true)
+	c.Assert(dbUser.APIKey, check.Equals, "")
+	_, err = nativeScheme.Auth(token.GetValue())
+	c.Assert(err, check.NotNil)
+	_, err = nativeScheme.Login(map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.Equals, auth.ErrUserDisabled)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(u.Email),
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.provision",
+	}, eventtest.HasEvent)
+	recorder = s.scimRequest(c, http.MethodPatch, "/scim/v2/Users/nobody@globo.com",
+		`{"Operations":[{"op":"replace","path":"active","value":"True"}]}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = nativeScheme.Login(map[string]string{"email": u.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *AuthSuite) TestSCIMDeleteUser(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(&u)
+	c.Assert(err, check.IsNil)
+	recorder := s.scimRequest(c, http.MethodDelete, "/scim/v2/Users/nobody@globo.com", "")
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	_, err = auth.GetUserByEmail(u.Email)
+	c.Assert(err, check.Equals, authTypes.ErrUserNotFound)
+	recorder = s.scimRequest(c, http.MethodDelete, "/scim/v2/Users/nobody@globo.com", "")
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *AuthSuite) TestSCIMGroupMembers(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(&u)
+	c.Assert(err, check.IsNil)
+	recorder := s.scimRequest(c, http.MethodPatch, "/scim/v2/Groups/"+s.team.Name,
+		`{"Operations":[{"op":"add","path":"members","value":[{"value":"nobody@globo.com"}]}]}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var group scimGroup
+	err = json.NewDecoder(recorder.Body).Decode(&group)
+	c.Assert(err, check.IsNil)
+	c.Assert(group.DisplayName, check.Equals, s.team.Name)
+	c.Assert(group.Members, check.DeepEquals, []scimValue{{Value: u.Email, Display: u.Email}})
+	dbUser, err := auth.GetUserByEmail(u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbUser.Roles, check.DeepEquals, []authTypes.RoleInstance{{Name: "scim-member", ContextValue: s.team.Name}})
+	recorder = s.scimRequest(c, http.MethodGet, "/scim/v2/Users/nobody@globo.com", "")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var user scimUser
+	err = json.NewDecoder(recorder.Body).Decode(&user)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.Groups, check.DeepEquals, []scimValue{{Value: s.team.Name, Display: s.team.Name}})
+	recorder = s.scimRequest(c, http.MethodPatch, "/scim/v2/Groups/"+s.team.Name,
+		`{"Operations":[{"op":"remove","path":"members[value eq \"nobody@globo.com\"]"}]}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbUser, err = auth.GetUserByEmail(u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbUser.Roles, check.HasLen, 0)
+}
+
+func (s *AuthSuite) TestSCIMReplaceGroupUnknownMember(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	recorder := s.scimRequest(c, http.MethodPut, "/scim/v2/Groups/"+s.team.Name,
+		`{"displayName":"`+s.team.Name+`","members":[{"value":"unknown@globo.com"}]}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	recorder = s.scimRequest(c, http.MethodGet, "/scim/v2/Groups/unknown", "")
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *AuthSuite) TestSCIMCreateGroup(c *check.C) {
+	defer s.setSCIMTeamRole(c)()
+	var created string
+	s.mockTeamService.OnCreate = func(name string, _ *authTypes.User) error {
+		created = name
+		return nil
+	}
+	recorder := s.scimRequest(c, http.MethodPost, "/scim/v2/Groups",
+		`{"displayName":"newteam","members":[{"value":"`+s.user.Email+`"}]}`)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	c.Assert(created, check.Equals, "newteam")
+	user, err := auth.GetUserByEmail(s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(userTeams(user, []string{"scim-member"}), check.DeepEquals, []string{"newteam"})
+	c.Assert(eventtest.EventDesc{
+		Target: teamTarget("newteam"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.create",
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestSCIMPage(c *check.C) {
+	resources := []interface{}{1, 2, 3, 4, 5}
+	request, err := http.NewRequest(http.MethodGet, "/?startIndex=2&count=2", nil)
+	c.Assert(err, check.IsNil)
+	page := scimPage(request, resources)
+	c.Assert(page.TotalResults, check.Equals, 5)
+	c.Assert(page.StartIndex, check.Equals, 2)
+	c.Assert(page.Resources, check.DeepEquals, []interface{}{2, 3})
+	request, err = http.NewRequest(http.MethodGet, "/?startIndex=10", nil)
+	c.Assert(err, check.IsNil)
+	page = scimPage(request, resources)
+	c.Assert(page.Resources, check.HasLen, 0)
+}
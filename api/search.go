@@ -0,0 +1,327 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/validation"
+)
+
+const (
+	searchTypeApp             = "app"
+	searchTypeTeam            = "team"
+	searchTypeService         = "service"
+	searchTypeServiceInstance = "service-instance"
+	searchTypeEvent           = "event"
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+var searchTypes = []string{searchTypeApp, searchTypeTeam, searchTypeService, searchTypeServiceInstance, searchTypeEvent}
+
+type searchResult struct {
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// searchQuery holds the criteria of a search. Entities not supporting one of
+// the given criteria, like teams when an env var key is given, are not
+// searched.
+type searchQuery struct {
+	text   string
+	tag    string
+	envKey string
+	owner  string
+	limit  int
+}
+
+func (q *searchQuery) matchesText(values ...string) bool {
+	if q.text == "" {
+		return true
+	}
+	text := strings.ToLower(q.text)
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *searchQuery) matchesTag(tags []string) bool {
+	if q.tag == "" {
+		return true
+	}
+	for _, t := range tags {
+		if t == q.tag {
+			return true
+		}
+	}
+	return false
+}
+
+// title: search
+// path: /search
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+func search(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	query := searchQuery{
+		text:   strings.TrimSpace(r.URL.Query().Get("q")),
+		tag:    r.URL.Query().Get("tag"),
+		envKey: r.URL.Query().Get("envkey"),
+		owner:  r.URL.Query().Get("owner"),
+		limit:  defaultSearchLimit,
+	}
+	if query.text == "" && query.tag == "" && query.envKey == "" && query.owner == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "at least one of q, tag, envkey or owner is required"}
+	}
+	if query.envKey != "" && !validation.ValidateEnvName(query.envKey) {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid envkey %q", query.envKey)}
+	}
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "limit must be a positive integer"}
+		}
+		if limit > maxSearchLimit {
+			limit = maxSearchLimit
+		}
+		query.limit = limit
+	}
+	types := r.URL.Query()["type"]
+	if len(types) == 0 {
+		types = searchTypes
+	}
+	var results []searchResult
+	for _, typ := range types {
+		var (
+			typeResults []searchResult
+			err         error
+		)
+		switch typ {
+		case searchTypeApp:
+			typeResults, err = searchApps(t, &query)
+		case searchTypeTeam:
+			typeResults, err = searchTeams(t, &query)
+		case searchTypeService:
+			typeResults, err = searchServices(t, &query)
+		case searchTypeServiceInstance:
+			typeResults, err = searchServiceInstances(t, &query)
+		case searchTypeEvent:
+			typeResults, err = searchEvents(t, &query)
+		default:
+			return &errors.HTTP{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("invalid type %q, valid types are: %s", typ, strings.Join(searchTypes, ", ")),
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(typeResults) > query.limit {
+			typeResults = typeResults[:query.limit]
+		}
+		results = append(results, typeResults...)
+	}
+	if len(results) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(results)
+}
+
+func searchApps(t auth.Token, query *searchQuery) ([]searchResult, error) {
+	contexts := permission.ContextsForPermission(t, permission.PermAppRead)
+	if len(contexts) == 0 {
+		return nil, nil
+	}
+	newFilter := func() *app.Filter {
+		filter := appFilterByContext(contexts, nil)
+		filter.UserOwner = query.owner
+		filter.EnvKey = query.envKey
+		if query.tag != "" {
+			filter.Tags = []string{query.tag}
+		}
+		return filter
+	}
+	var filters []*app.Filter
+	if query.text == "" {
+		filters = append(filters, newFilter())
+	} else {
+		// Text search only matches whole words, so apps whose name starts
+		// with the text are included too.
+		textFilter := newFilter()
+		textFilter.Text = query.text
+		prefixFilter := newFilter()
+		prefixFilter.NameMatches = "^" + regexp.QuoteMeta(query.text)
+		filters = append(filters, prefixFilter, textFilter)
+	}
+	var results []searchResult
+	found := map[string]bool{}
+	for _, filter := range filters {
+		apps, err := app.List(filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range apps {
+			if found[a.Name] {
+				continue
+			}
+			found[a.Name] = true
+			results = append(results, searchResult{
+				Type:        searchTypeApp,
+				Name:        a.Name,
+				Description: a.Description,
+				Owner:       a.Owner,
+				Tags:        a.Tags,
+			})
+		}
+	}
+	return results, nil
+}
+
+func searchTeams(t auth.Token, query *searchQuery) ([]searchResult, error) {
+	if query.tag != "" || query.envKey != "" || query.owner != "" {
+		return nil, nil
+	}
+	teams, err := servicemanager.Team.List()
+	if err != nil {
+		return nil, err
+	}
+	perms, err := t.Permissions()
+	if err != nil {
+		return nil, err
+	}
+	permsForTeam := permission.PermissionRegistry.PermissionsWithContextType(permission.CtxTeam)
+	var results []searchResult
+	for _, team := range teams {
+		if !query.matchesText(team.Name) {
+			continue
+		}
+		teamCtx := permission.Context(permission.CtxTeam, team.Name)
+		for _, p := range permsForTeam {
+			if permission.CheckFromPermList(perms, p, teamCtx) {
+				results = append(results, searchResult{Type: searchTypeTeam, Name: team.Name})
+				break
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
+
+func searchServices(t auth.Token, query *searchQuery) ([]searchResult, error) {
+	if query.tag != "" || query.envKey != "" || query.owner != "" {
+		return nil, nil
+	}
+	contexts := permission.ContextsForPermission(t, permission.PermServiceRead)
+	services, err := provisionReadableServices(t, contexts)
+	if err != nil {
+		return nil, err
+	}
+	var results []searchResult
+	for _, s := range services {
+		if !query.matchesText(s.Name, s.Doc) {
+			continue
+		}
+		results = append(results, searchResult{Type: searchTypeService, Name: s.Name})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
+
+func searchServiceInstances(t auth.Token, query *searchQuery) ([]searchResult, error) {
+	if query.envKey != "" || query.owner != "" {
+		return nil, nil
+	}
+	contexts := permission.ContextsForPermission(t, permission.PermServiceInstanceRead)
+	if len(contexts) == 0 {
+		return nil, nil
+	}
+	instances, err := readableInstances(t, contexts, "", "")
+	if err != nil {
+		return nil, err
+	}
+	var results []searchResult
+	for i := range instances {
+		si := &instances[i]
+		if !query.matchesText(si.Name, si.Description) || !query.matchesTag(si.Tags) {
+			continue
+		}
+		results = append(results, searchResult{
+			Type:        searchTypeServiceInstance,
+			Name:        si.ServiceName + "/" + si.Name,
+			Description: si.Description,
+			Tags:        si.Tags,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
+
+func searchEvents(t auth.Token, query *searchQuery) ([]searchResult, error) {
+	if query.tag != "" || query.envKey != "" {
+		return nil, nil
+	}
+	perms, err := t.Permissions()
+	if err != nil {
+		return nil, err
+	}
+	filter := &event.Filter{
+		OwnerName:   query.owner,
+		Permissions: perms,
+		Limit:       query.limit,
+	}
+	if query.text != "" {
+		filter.Raw = bson.M{"$or": []bson.M{
+			{"target.value": query.text},
+			{"owner.name": query.text},
+		}}
+	}
+	events, err := event.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]searchResult, len(events))
+	for i, evt := range events {
+		results[i] = searchResult{
+			Type:        searchTypeEvent,
+			Name:        evt.UniqueID.Hex(),
+			Description: fmt.Sprintf("%s %s: %s", evt.Kind.Name, evt.Target.Type, evt.Target.Value),
+			Owner:       evt.Owner.Name,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/service"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) doSearch(c *check.C, token auth.Token, query string) ([]searchResult, *httptest.ResponseRecorder) {
+	request, err := http.NewRequest("GET", "/1.6/search?"+query, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	var results []searchResult
+	if recorder.Code == http.StatusOK {
+		c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+		err = json.NewDecoder(recorder.Body).Decode(&results)
+		c.Assert(err, check.IsNil)
+	}
+	return results, recorder
+}
+
+func (s *S) TestSearchApps(c *check.C) {
+	a1 := app.App{Name: "billing-api", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"payments"}}
+	err := app.CreateApp(&a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := app.App{Name: "frontend", Platform: "zend", TeamOwner: s.team.Name, Description: "shows billing reports"}
+	err = app.CreateApp(&a2, s.user)
+	c.Assert(err, check.IsNil)
+	a3 := app.App{Name: "other", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(&a3, s.user)
+	c.Assert(err, check.IsNil)
+	results, recorder := s.doSearch(c, s.token, "q=billing&type=app")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(results, check.HasLen, 2)
+	c.Assert(results[0].Name, check.Equals, "billing-api")
+	c.Assert(results[0].Type, check.Equals, searchTypeApp)
+	c.Assert(results[1].Name, check.Equals, "frontend")
+	results, recorder = s.doSearch(c, s.token, "tag=payments")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(results, check.DeepEquals, []searchResult{
+		{Type: searchTypeApp, Name: "billing-api", Owner: s.user.Email, Tags: []string{"payments"}},
+	})
+}
+
+func (s *S) TestSearchAppsByEnvKey(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{{Name: "DATABASE_URL", Value: "mysql://"}},
+	})
+	c.Assert(err, check.IsNil)
+	results, recorder := s.doSearch(c, s.token, "envkey=DATABASE_URL")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(results, check.HasLen, 1)
+	c.Assert(results[0].Name, check.Equals, "myapp")
+	_, recorder = s.doSearch(c, s.token, "envkey=OTHER")
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestSearchFiltersByPermission(c *check.C) {
+	a1 := app.App{Name: "billing-api", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := app.App{Name: "billing-worker", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(&a2, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.conn.ServiceInstances().Insert(
+		service.ServiceInstance{Name: "billing-db", ServiceName: "mysql", Teams: []string{s.team.Name}},
+		service.ServiceInstance{Name: "billing-cache", ServiceName: "redis", Teams: []string{"otherteam"}},
+	)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permission.CtxApp, "billing-api"),
+	}, permission.Permission{
+		Scheme:  permission.PermServiceInstanceRead,
+		Context: permission.Context(permission.CtxTeam, s.team.Name),
+	})
+	results, recorder := s.doSearch(c, token, "q=billing&type=app&type=service-instance")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(results, check.DeepEquals, []searchResult{
+		{Type: searchTypeApp, Name: "billing-api", Owner: s.user.Email},
+		{Type: searchTypeServiceInstance, Name: "mysql/billing-db"},
+	})
+}
+
+func (s *S) TestSearchTeams(c *check.C) {
+	results, recorder := s.doSearch(c, s.token, "q="+s.team.Name[:3]+"&type=team")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(results, check.DeepEquals, []searchResult{{Type: searchTypeTeam, Name: s.team.Name}})
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser")
+	_, recorder = s.doSearch(c, token, "q="+s.team.Name+"&type=team")
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestSearchLimit(c *check.C) {
+	for _, name := range []string{"app1", "app2", "app3"} {
+		a := app.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+		err := app.CreateApp(&a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	results, recorder := s.doSearch(c, s.token, "q=app&type=app&limit=2")
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(results, check.HasLen, 2)
+}
+
+func (s *S) TestSearchInvalidParams(c *check.C) {
+	_, recorder := s.doSearch(c, s.token, "")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "at least one of q, tag, envkey or owner is required\n")
+	_, recorder = s.doSearch(c, s.token, "q=x&type=unknown")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	_, recorder = s.doSearch(c, s.token, "q=x&limit=-1")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	_, recorder = s.doSearch(c, s.token, "envkey=DATABASE_URL.value")
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid envkey \"DATABASE_URL.value\"\n")
+}
@@ -22,10 +22,18 @@ import (
 	apiRouter "github.com/tsuru/tsuru/api/router"
 	"github.com/tsuru/tsuru/api/shutdown"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/accesslog"
 	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/capacity"
+	"github.com/tsuru/tsuru/app/hibernation"
 	"github.com/tsuru/tsuru/app/image/gc"
+	"github.com/tsuru/tsuru/app/orphan"
+	"github.com/tsuru/tsuru/app/review"
+	"github.com/tsuru/tsuru/app/scaling"
+	"github.com/tsuru/tsuru/app/traffic"
+	"github.com/tsuru/tsuru/app/usage"
 	"github.com/tsuru/tsuru/auth"
-	_ "github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/auth/native"
 	_ "github.com/tsuru/tsuru/auth/oauth"
 	_ "github.com/tsuru/tsuru/auth/saml"
 	"github.com/tsuru/tsuru/autoscale"
@@ -33,9 +41,11 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/hc"
 	"github.com/tsuru/tsuru/healer"
+	"github.com/tsuru/tsuru/jobs"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"github.com/tsuru/tsuru/retry"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/rebuild"
 	"github.com/tsuru/tsuru/service"
@@ -102,6 +112,7 @@ func RunServer(dry bool) http.Handler {
 		fatal(err)
 	}
 	setupServices()
+	native.LockoutNotifier = notifyLoginLockout
 
 	m := apiRouter.NewRouter()
 
@@ -122,6 +133,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", "Put", "/services/{service}/instances/{instance}/{app}", AuthorizationRequiredHandler(bindServiceInstance))
 	m.Add("1.0", "Delete", "/services/{service}/instances/{instance}/{app}", AuthorizationRequiredHandler(unbindServiceInstance))
 	m.Add("1.0", "Get", "/services/{service}/instances/{instance}/status", AuthorizationRequiredHandler(serviceInstanceStatus))
+	m.Add("1.6", "Post", "/services/{service}/instances/{instance}/reconcile", AuthorizationRequiredHandler(serviceInstanceReconcile))
+	m.Add("1.6", "Post", "/services/{service}/instances/{instance}/clone", AuthorizationRequiredHandler(serviceInstanceClone))
 	m.Add("1.0", "Put", "/services/{service}/instances/permission/{instance}/{team}", AuthorizationRequiredHandler(serviceInstanceGrantTeam))
 	m.Add("1.0", "Delete", "/services/{service}/instances/permission/{instance}/{team}", AuthorizationRequiredHandler(serviceInstanceRevokeTeam))
 
@@ -134,6 +147,7 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", "Delete", "/services/{name}", AuthorizationRequiredHandler(serviceDelete))
 	m.Add("1.0", "Get", "/services/{name}", AuthorizationRequiredHandler(serviceInfo))
 	m.Add("1.0", "Get", "/services/{name}/plans", AuthorizationRequiredHandler(servicePlans))
+	m.Add("1.6", "Get", "/services/{name}/schema", AuthorizationRequiredHandler(serviceParametersSchema))
 	m.Add("1.0", "Get", "/services/{name}/doc", AuthorizationRequiredHandler(serviceDoc))
 	m.Add("1.0", "Put", "/services/{name}/doc", AuthorizationRequiredHandler(serviceAddDoc))
 	m.Add("1.0", "Put", "/services/{service}/team/{team}", AuthorizationRequiredHandler(grantServiceAccess))
@@ -155,10 +169,27 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", "Get", "/apps/{app}/env", AuthorizationRequiredHandler(getEnv))
 	m.Add("1.0", "Post", "/apps/{app}/env", AuthorizationRequiredHandler(setEnv))
 	m.Add("1.0", "Delete", "/apps/{app}/env", AuthorizationRequiredHandler(unsetEnv))
+	m.Add("1.6", "Post", "/apps/{app}/env-groups/{name}", AuthorizationRequiredHandler(appEnvGroupSubscribe))
+	m.Add("1.6", "Delete", "/apps/{app}/env-groups/{name}", AuthorizationRequiredHandler(appEnvGroupUnsubscribe))
+	m.Add("1.6", "Get", "/apps/{app}/build-secrets", AuthorizationRequiredHandler(appBuildSecretsList))
+	m.Add("1.6", "Post", "/apps/{app}/build-secrets", AuthorizationRequiredHandler(appBuildSecretsSet))
+	m.Add("1.6", "Delete", "/apps/{app}/build-secrets", AuthorizationRequiredHandler(appBuildSecretsUnset))
+	m.Add("1.6", "Get", "/apps/{app}/pipeline", AuthorizationRequiredHandler(pipelineInfo))
+	m.Add("1.6", "Put", "/apps/{app}/pipeline", AuthorizationRequiredHandler(pipelineSet))
+	m.Add("1.6", "Delete", "/apps/{app}/pipeline", AuthorizationRequiredHandler(pipelineUnset))
+	m.Add("1.6", "Post", "/apps/{app}/pipeline/runs", AuthorizationRequiredHandler(pipelineRun))
+	m.Add("1.6", "Get", "/apps/{app}/pipeline/runs", AuthorizationRequiredHandler(pipelineRunsList))
+	m.Add("1.6", "Get", "/apps/{app}/pipeline/runs/{id}", AuthorizationRequiredHandler(pipelineRunInfo))
+	m.Add("1.6", "Post", "/apps/{app}/pipeline/runs/{id}/approve", AuthorizationRequiredHandler(pipelineRunApprove))
+	m.Add("1.6", "Post", "/apps/{app}/pipeline/runs/{id}/reject", AuthorizationRequiredHandler(pipelineRunReject))
+	m.Add("1.6", "Get", "/apps/{app}/review-apps", AuthorizationRequiredHandler(reviewAppList))
+	m.Add("1.6", "Post", "/apps/{app}/review-apps", AuthorizationRequiredHandler(reviewAppCreate))
+	m.Add("1.6", "Delete", "/apps/{app}/review-apps/{name}", AuthorizationRequiredHandler(reviewAppRemove))
 	m.Add("1.0", "Get", "/apps", AuthorizationRequiredHandler(appList))
 	m.Add("1.0", "Post", "/apps", AuthorizationRequiredHandler(createApp))
 	forceDeleteLockHandler := AuthorizationRequiredHandler(forceDeleteLock)
 	m.Add("1.0", "Delete", "/apps/{app}/lock", forceDeleteLockHandler)
+	m.Add("1.6", "Get", "/locks/apps", AuthorizationRequiredHandler(appLockList))
 	m.Add("1.0", "Put", "/apps/{app}/units", AuthorizationRequiredHandler(addUnits))
 	m.Add("1.0", "Delete", "/apps/{app}/units", AuthorizationRequiredHandler(removeUnits))
 	registerUnitHandler := AuthorizationRequiredHandler(registerUnit)
@@ -173,6 +204,32 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", "Post", "/apps/{appname}/deploy/rollback", AuthorizationRequiredHandler(deployRollback))
 	m.Add("1.4", "Put", "/apps/{appname}/deploy/rollback/update", AuthorizationRequiredHandler(deployRollbackUpdate))
 	m.Add("1.3", "Post", "/apps/{appname}/deploy/rebuild", AuthorizationRequiredHandler(deployRebuild))
+	m.Add("1.6", "Get", "/apps/{appname}/deploy/diff", AuthorizationRequiredHandler(deployDiff))
+	m.Add("1.6", "Get", "/apps/{appname}/deploy/timeouts", AuthorizationRequiredHandler(deployTimeouts))
+	m.Add("1.6", "Put", "/apps/{appname}/deploy/timeouts", AuthorizationRequiredHandler(setDeployTimeouts))
+	m.Add("1.6", "Get", "/apps/{appname}/deploy/verification", AuthorizationRequiredHandler(deployVerification))
+	m.Add("1.6", "Put", "/apps/{appname}/deploy/verification", AuthorizationRequiredHandler(setDeployVerification))
+	m.Add("1.6", "Delete", "/apps/{appname}/deploy/verification", AuthorizationRequiredHandler(removeDeployVerification))
+	m.Add("1.6", "Get", "/apps/{app}/tls-policy", AuthorizationRequiredHandler(appTLSPolicyGet))
+	m.Add("1.6", "Put", "/apps/{app}/tls-policy", AuthorizationRequiredHandler(appTLSPolicySet))
+	m.Add("1.6", "Delete", "/apps/{app}/tls-policy", AuthorizationRequiredHandler(appTLSPolicyUnset))
+	m.Add("1.6", "Put", "/apps/{app}/processes/{process}/protocol", AuthorizationRequiredHandler(appProcessProtocolSet))
+	m.Add("1.6", "Put", "/apps/{app}/sticky-session", AuthorizationRequiredHandler(appStickySessionSet))
+	m.Add("1.6", "Delete", "/apps/{app}/sticky-session", AuthorizationRequiredHandler(appStickySessionUnset))
+	m.Add("1.6", "Get", "/apps/{app}/router-policy", AuthorizationRequiredHandler(appRouterPolicyGet))
+	m.Add("1.6", "Put", "/apps/{app}/router-policy", AuthorizationRequiredHandler(appRouterPolicySet))
+	m.Add("1.6", "Delete", "/apps/{app}/router-policy", AuthorizationRequiredHandler(appRouterPolicyUnset))
+	m.Add("1.6", "Put", "/apps/{app}/mirror", AuthorizationRequiredHandler(appMirrorSet))
+	m.Add("1.6", "Delete", "/apps/{app}/mirror", AuthorizationRequiredHandler(appMirrorUnset))
+	m.Add("1.6", "Put", "/apps/{app}/access-log", AuthorizationRequiredHandler(appAccessLogSet))
+	m.Add("1.6", "Delete", "/apps/{app}/access-log", AuthorizationRequiredHandler(appAccessLogUnset))
+	m.Add("1.6", "Get", "/apps/{app}/error-pages/{status}", AuthorizationRequiredHandler(appErrorPageGet))
+	m.Add("1.6", "Put", "/apps/{app}/error-pages/{status}", AuthorizationRequiredHandler(appErrorPageSet))
+	m.Add("1.6", "Delete", "/apps/{app}/error-pages/{status}", AuthorizationRequiredHandler(appErrorPageUnset))
+	m.Add("1.6", "Post", "/apps/{appname}/deploy/uploads", AuthorizationRequiredHandler(createUploadSession))
+	m.Add("1.6", "Get", "/apps/{appname}/deploy/uploads/{id}", AuthorizationRequiredHandler(uploadSessionInfo))
+	m.Add("1.6", "Put", "/apps/{appname}/deploy/uploads/{id}", AuthorizationRequiredHandler(uploadChunk))
+	m.Add("1.6", "Delete", "/apps/{appname}/deploy/uploads/{id}", AuthorizationRequiredHandler(removeUploadSession))
 	m.Add("1.0", "Get", "/apps/{app}/metric/envs", AuthorizationRequiredHandler(appMetricEnvs))
 	m.Add("1.0", "Post", "/apps/{app}/routes", AuthorizationRequiredHandler(appRebuildRoutes))
 	m.Add("1.2", "Get", "/apps/{app}/certificate", AuthorizationRequiredHandler(listCertificates))
@@ -183,22 +240,62 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.5", "Put", "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(updateAppRouter))
 	m.Add("1.5", "Delete", "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(removeAppRouter))
 	m.Add("1.5", "Get", "/apps/{app}/routers", AuthorizationRequiredHandler(listAppRouters))
+	m.Add("1.6", "Get", "/apps/{app}/routers/health", AuthorizationRequiredHandler(appRoutersHealth))
+
+	m.Add("1.6", "Post", "/bulk/apps/env", AuthorizationRequiredHandler(bulkSetEnv))
+	m.Add("1.6", "Post", "/bulk/apps/restart", AuthorizationRequiredHandler(bulkRestart))
+	m.Add("1.6", "Post", "/bulk/apps/rebuild", AuthorizationRequiredHandler(bulkRebuild))
+	m.Add("1.6", "Get", "/apps/{app}/scaling/schedules", AuthorizationRequiredHandler(listScalingSchedules))
+	m.Add("1.6", "Post", "/apps/{app}/scaling/schedules", AuthorizationRequiredHandler(addScalingSchedule))
+	m.Add("1.6", "Delete", "/apps/{app}/scaling/schedules/{id}", AuthorizationRequiredHandler(removeScalingSchedule))
+	m.Add("1.6", "Post", "/apps/{app}/wakeup", AuthorizationRequiredHandler(wakeApp))
+	m.Add("1.6", "Get", "/apps/{app}/scaling/autoscale", AuthorizationRequiredHandler(listAutoScale))
+	m.Add("1.6", "Post", "/apps/{app}/scaling/autoscale", AuthorizationRequiredHandler(setAutoScale))
+	m.Add("1.6", "Delete", "/apps/{app}/scaling/autoscale/{process}", AuthorizationRequiredHandler(removeAutoScale))
+	m.Add("1.6", "Put", "/apps/{app}/units/placement", AuthorizationRequiredHandler(setUnitPlacement))
+	m.Add("1.6", "Get", "/apps/{app}/units/history", AuthorizationRequiredHandler(appUnitHistory))
+	m.Add("1.6", "Get", "/apps/{app}/traffic", AuthorizationRequiredHandler(appTraffic))
+	m.Add("1.6", "Get", "/apps/{app}/timeline", AuthorizationRequiredHandler(appTimeline))
+	m.Add("1.6", "Get", "/apps/{app}/overview", AuthorizationRequiredHandler(appOverviewHandler))
+	m.Add("1.6", "Get", "/apps/{app}/drift", AuthorizationRequiredHandler(appDrift))
+	m.Add("1.6", "Post", "/apps/{app}/drift/reconcile", AuthorizationRequiredHandler(appDriftReconcile))
+	m.Add("1.6", "Get", "/apps/{app}/shells", AuthorizationRequiredHandler(listShellSessions))
+	m.Add("1.6", "Delete", "/apps/{app}/shells/{uuid}", AuthorizationRequiredHandler(terminateShellSession))
+	m.Add("1.6", "Get", "/usage", AuthorizationRequiredHandler(usageReport))
+	m.Add("1.6", "Get", "/search", AuthorizationRequiredHandler(search))
 
 	m.Add("1.0", "Post", "/node/status", AuthorizationRequiredHandler(setNodeStatus))
 
 	m.Add("1.0", "Get", "/deploys", AuthorizationRequiredHandler(deploysList))
 	m.Add("1.0", "Get", "/deploys/{deploy}", AuthorizationRequiredHandler(deployInfo))
+	m.Add("1.6", "Get", "/deploys/{deploy}/buildlog", AuthorizationRequiredHandler(deployBuildLog))
+	m.Add("1.6", "Get", "/deploys/{deploy}/diff", AuthorizationRequiredHandler(deployConfigDiff))
+	m.Add("1.6", "Get", "/builds/queue", AuthorizationRequiredHandler(buildQueueList))
 
 	m.Add("1.1", "Get", "/events", AuthorizationRequiredHandler(eventList))
 	m.Add("1.3", "Get", "/events/blocks", AuthorizationRequiredHandler(eventBlockList))
 	m.Add("1.3", "Post", "/events/blocks", AuthorizationRequiredHandler(eventBlockAdd))
 	m.Add("1.3", "Delete", "/events/blocks/{uuid}", AuthorizationRequiredHandler(eventBlockRemove))
 	m.Add("1.1", "Get", "/events/kinds", AuthorizationRequiredHandler(kindList))
+	m.Add("1.6", "Get", "/events/schemas", AuthorizationRequiredHandler(eventSchemaList))
+	m.Add("1.6", "Get", "/events/schemas/{kind}", AuthorizationRequiredHandler(eventSchemaInfo))
 	m.Add("1.1", "Get", "/events/{uuid}", AuthorizationRequiredHandler(eventInfo))
 	m.Add("1.1", "Post", "/events/{uuid}/cancel", AuthorizationRequiredHandler(eventCancel))
 
+	m.Add("1.6", "Get", "/retry/operations", AuthorizationRequiredHandler(retryOperationList))
+	m.Add("1.6", "Post", "/retry/operations/{id}/requeue", AuthorizationRequiredHandler(retryOperationRequeue))
+
+	m.Add("1.6", "Get", "/orphans", AuthorizationRequiredHandler(orphanList))
+	m.Add("1.6", "Delete", "/orphans", AuthorizationRequiredHandler(orphanClean))
+
+	m.Add("1.6", "Get", "/jobs", AuthorizationRequiredHandler(jobList))
+	m.Add("1.6", "Get", "/jobs/{name}", AuthorizationRequiredHandler(jobInfo))
+	m.Add("1.6", "Put", "/jobs/{name}", AuthorizationRequiredHandler(jobUpdate))
+	m.Add("1.6", "Post", "/jobs/{name}/run", AuthorizationRequiredHandler(jobRun))
+
 	m.Add("1.0", "Get", "/platforms", AuthorizationRequiredHandler(platformList))
 	m.Add("1.0", "Post", "/platforms", AuthorizationRequiredHandler(platformAdd))
+	m.Add("1.6", "Get", "/platforms/deprecated/apps", AuthorizationRequiredHandler(platformDeprecatedApps))
 	m.Add("1.0", "Put", "/platforms/{name}", AuthorizationRequiredHandler(platformUpdate))
 	m.Add("1.0", "Delete", "/platforms/{name}", AuthorizationRequiredHandler(platformRemove))
 
@@ -229,6 +326,26 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", "Get", "/users/{email}/quota", AuthorizationRequiredHandler(getUserQuota))
 	m.Add("1.0", "Put", "/users/{email}/quota", AuthorizationRequiredHandler(changeUserQuota))
 	m.Add("1.0", "Delete", "/users/tokens", AuthorizationRequiredHandler(logout))
+	m.Add("1.6", "Get", "/users/sessions", AuthorizationRequiredHandler(listSessions))
+	m.Add("1.6", "Delete", "/users/sessions/{id}", AuthorizationRequiredHandler(revokeSession))
+	m.Add("1.6", "Delete", "/users/{email}/sessions", AuthorizationRequiredHandler(revokeUserSessions))
+	m.Add("1.6", "Delete", "/users/{email}/lockout", AuthorizationRequiredHandler(unlockUserLogins))
+	m.Add("1.6", "Post", "/users/{email}/impersonate", AuthorizationRequiredHandler(impersonateUser))
+	m.Add("1.6", "Delete", "/auth/lockouts/ip/{ip}", AuthorizationRequiredHandler(unlockIPLogins))
+	m.Add("1.6", "Get", "/auth/password-policy", Handler(passwordPolicy))
+
+	m.Add("1.6", "Get", "/scim/v2/Users", scimHandler(scimListUsers))
+	m.Add("1.6", "Post", "/scim/v2/Users", scimHandler(scimCreateUser))
+	m.Add("1.6", "Get", "/scim/v2/Users/{id}", scimHandler(scimGetUser))
+	m.Add("1.6", "Put", "/scim/v2/Users/{id}", scimHandler(scimReplaceUser))
+	m.Add("1.6", "Patch", "/scim/v2/Users/{id}", scimHandler(scimPatchUser))
+	m.Add("1.6", "Delete", "/scim/v2/Users/{id}", scimHandler(scimDeleteUser))
+	m.Add("1.6", "Get", "/scim/v2/Groups", scimHandler(scimListGroups))
+	m.Add("1.6", "Post", "/scim/v2/Groups", scimHandler(scimCreateGroup))
+	m.Add("1.6", "Get", "/scim/v2/Groups/{id}", scimHandler(scimGetGroup))
+	m.Add("1.6", "Put", "/scim/v2/Groups/{id}", scimHandler(scimReplaceGroup))
+	m.Add("1.6", "Patch", "/scim/v2/Groups/{id}", scimHandler(scimPatchGroup))
+	m.Add("1.6", "Delete", "/scim/v2/Groups/{id}", scimHandler(scimDeleteGroup))
 	m.Add("1.0", "Put", "/users/password", AuthorizationRequiredHandler(changePassword))
 	m.Add("1.0", "Delete", "/users", AuthorizationRequiredHandler(removeUser))
 	m.Add("1.0", "Get", "/users/keys", AuthorizationRequiredHandler(listKeys))
@@ -262,11 +379,26 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", "Delete", "/plans/{planname}", AuthorizationRequiredHandler(removePlan))
 
 	m.Add("1.0", "Get", "/pools", AuthorizationRequiredHandler(poolList))
+	m.Add("1.6", "Get", "/pools/capacity", AuthorizationRequiredHandler(poolCapacityReport))
 	m.Add("1.0", "Post", "/pools", AuthorizationRequiredHandler(addPoolHandler))
 	m.Add("1.0", "Delete", "/pools/{name}", AuthorizationRequiredHandler(removePoolHandler))
 	m.Add("1.0", "Put", "/pools/{name}", AuthorizationRequiredHandler(poolUpdateHandler))
 	m.Add("1.0", "Post", "/pools/{name}/team", AuthorizationRequiredHandler(addTeamToPoolHandler))
 	m.Add("1.0", "Delete", "/pools/{name}/team", AuthorizationRequiredHandler(removeTeamToPoolHandler))
+	m.Add("1.6", "Put", "/pools/{name}/tls-policy", AuthorizationRequiredHandler(poolTLSPolicySet))
+	m.Add("1.6", "Delete", "/pools/{name}/tls-policy", AuthorizationRequiredHandler(poolTLSPolicyUnset))
+	m.Add("1.6", "Post", "/pools/{name}/env", AuthorizationRequiredHandler(poolEnvSet))
+	m.Add("1.6", "Delete", "/pools/{name}/env", AuthorizationRequiredHandler(poolEnvUnset))
+	m.Add("1.6", "Put", "/pools/{name}/dns", AuthorizationRequiredHandler(poolDNSSet))
+	m.Add("1.6", "Delete", "/pools/{name}/dns", AuthorizationRequiredHandler(poolDNSUnset))
+	m.Add("1.6", "Get", "/tls-policy/report", AuthorizationRequiredHandler(tlsComplianceReport))
+
+	m.Add("1.6", "Get", "/env-groups", AuthorizationRequiredHandler(envGroupList))
+	m.Add("1.6", "Post", "/env-groups", AuthorizationRequiredHandler(envGroupCreate))
+	m.Add("1.6", "Get", "/env-groups/{name}", AuthorizationRequiredHandler(envGroupInfo))
+	m.Add("1.6", "Delete", "/env-groups/{name}", AuthorizationRequiredHandler(envGroupRemove))
+	m.Add("1.6", "Post", "/env-groups/{name}/env", AuthorizationRequiredHandler(envGroupEnvSet))
+	m.Add("1.6", "Delete", "/env-groups/{name}/env", AuthorizationRequiredHandler(envGroupEnvUnset))
 
 	m.Add("1.3", "Get", "/constraints", AuthorizationRequiredHandler(poolConstraintList))
 	m.Add("1.3", "Put", "/constraints", AuthorizationRequiredHandler(poolConstraintSet))
@@ -311,6 +443,11 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.2", "PUT", "/node", AuthorizationRequiredHandler(updateNodeHandler))
 	m.Add("1.2", "DELETE", "/node/{address:.*}", AuthorizationRequiredHandler(removeNodeHandler))
 	m.Add("1.3", "POST", "/node/rebalance", AuthorizationRequiredHandler(rebalanceNodesHandler))
+	m.Add("1.6", "GET", "/node/topology", AuthorizationRequiredHandler(nodeTopologyHandler))
+	m.Add("1.6", "POST", "/node/bulk/metadata", AuthorizationRequiredHandler(bulkUpdateNodeMetadata))
+	m.Add("1.6", "GET", "/node/{address:.*}/agent/kernel-logs", AuthorizationRequiredHandler(nodeAgentKernelLogs))
+	m.Add("1.6", "GET", "/node/{address:.*}/agent/disks", AuthorizationRequiredHandler(nodeAgentDisks))
+	m.Add("1.6", "POST", "/node/{address:.*}/agent/docker/restart", AuthorizationRequiredHandler(nodeAgentRestartDocker))
 	m.Add("1.6", "GET", "/node/{address:.*}", AuthorizationRequiredHandler(infoNodeHandler))
 
 	m.Add("1.2", "GET", "/nodecontainers", AuthorizationRequiredHandler(nodeContainerList))
@@ -319,6 +456,13 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.2", "DELETE", "/nodecontainers/{name}", AuthorizationRequiredHandler(nodeContainerDelete))
 	m.Add("1.2", "POST", "/nodecontainers/{name}", AuthorizationRequiredHandler(nodeContainerUpdate))
 	m.Add("1.2", "POST", "/nodecontainers/{name}/upgrade", AuthorizationRequiredHandler(nodeContainerUpgrade))
+	m.Add("1.6", "GET", "/nodecontainers/secrets", AuthorizationRequiredHandler(nodeContainerSecretList))
+	m.Add("1.6", "POST", "/nodecontainers/secrets", AuthorizationRequiredHandler(nodeContainerSecretSet))
+	m.Add("1.6", "DELETE", "/nodecontainers/secrets/{name}", AuthorizationRequiredHandler(nodeContainerSecretRemove))
+	m.Add("1.6", "GET", "/nodecontainers/{name}/logs", AuthorizationRequiredHandler(nodeContainerLogs))
+	m.Add("1.6", "GET", "/nodecontainers/{name}/status", AuthorizationRequiredHandler(nodeContainerStatus))
+	m.Add("1.6", "POST", "/nodecontainers/{name}/validate", AuthorizationRequiredHandler(nodeContainerValidate))
+	m.Add("1.6", "POST", "/nodecontainers/big-sibling/token", AuthorizationRequiredHandler(nodeContainerTokenRotate))
 
 	m.Add("1.2", "POST", "/install/hosts", AuthorizationRequiredHandler(installHostAdd))
 	m.Add("1.2", "GET", "/install/hosts", AuthorizationRequiredHandler(installHostList))
@@ -525,6 +669,13 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return err
 	}
+	internalServices, err := nodecontainer.InitializeInternalServices()
+	if err != nil {
+		return err
+	}
+	for _, name := range internalServices {
+		fmt.Printf("Internal service node container %q created.\n", name)
+	}
 	err = provision.InitializeAll()
 	if err != nil {
 		return err
@@ -545,10 +696,58 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize old image gc")
 	}
+	err = traffic.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize router metrics collector")
+	}
+	err = accesslog.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize router access log collector")
+	}
+	err = scaling.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize app scaling scheduler")
+	}
+	err = hibernation.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize app hibernation")
+	}
+	err = review.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize review apps cleaner")
+	}
+	err = capacity.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize pools capacity collector")
+	}
+	err = retry.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize failed operations retrier")
+	}
+	err = registerJobs()
+	if err != nil {
+		return errors.Wrap(err, "unable to register platform jobs")
+	}
+	err = jobs.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize platform jobs scheduler")
+	}
+	err = usage.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize usage metering")
+	}
+	err = orphan.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize orphan resources auditor")
+	}
 	err = service.InitializeSync(bindAppsLister)
 	if err != nil {
 		return err
 	}
+	err = service.InitializeDriftDetector()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize service instances drift detector")
+	}
 	fmt.Println("Checking components status:")
 	results := hc.Check("all")
 	for _, result := range results {
@@ -75,10 +75,11 @@ func serviceList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 //   409: Service already exists
 func serviceCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	s := service.Service{
-		Name:     r.FormValue("id"),
-		Username: r.FormValue("username"),
-		Endpoint: map[string]string{"production": r.FormValue("endpoint")},
-		Password: r.FormValue("password"),
+		Name:              r.FormValue("id"),
+		Username:          r.FormValue("username"),
+		Endpoint:          map[string]string{"production": r.FormValue("endpoint")},
+		Password:          r.FormValue("password"),
+		NetworkAccessHook: r.FormValue("network-access-hook"),
 	}
 	team := r.FormValue("team")
 	if team == "" {
@@ -136,10 +137,11 @@ func serviceCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 //   404: Service not found
 func serviceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	d := service.Service{
-		Username: r.FormValue("username"),
-		Endpoint: map[string]string{"production": r.FormValue("endpoint")},
-		Password: r.FormValue("password"),
-		Name:     r.URL.Query().Get(":name"),
+		Username:          r.FormValue("username"),
+		Endpoint:          map[string]string{"production": r.FormValue("endpoint")},
+		Password:          r.FormValue("password"),
+		Name:              r.URL.Query().Get(":name"),
+		NetworkAccessHook: r.FormValue("network-access-hook"),
 	}
 	team := r.FormValue("team")
 	s, err := getService(d.Name)
@@ -167,6 +169,7 @@ func serviceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err er
 	s.Endpoint = d.Endpoint
 	s.Password = d.Password
 	s.Username = d.Username
+	s.NetworkAccessHook = d.NetworkAccessHook
 	if team != "" {
 		s.OwnerTeams = []string{team}
 	}
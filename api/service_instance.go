@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -18,11 +19,13 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/service"
 )
 
@@ -59,6 +62,7 @@ func createServiceInstance(w http.ResponseWriter, r *http.Request, t auth.Token)
 		TeamOwner:   r.FormValue("owner"),
 		Description: r.FormValue("description"),
 		Tags:        r.Form["tag"],
+		Parameters:  instanceParametersFromForm(r.Form),
 	}
 	var teamOwner string
 	if instance.TeamOwner == "" {
@@ -134,6 +138,7 @@ func updateServiceInstance(w http.ResponseWriter, r *http.Request, t auth.Token)
 	teamOwner := r.FormValue("teamowner")
 	plan := r.FormValue("plan")
 	tags := r.Form["tag"]
+	parameters := instanceParametersFromForm(r.Form)
 	srv, err := getService(serviceName)
 	if err != nil {
 		return err
@@ -155,10 +160,13 @@ func updateServiceInstance(w http.ResponseWriter, r *http.Request, t auth.Token)
 	if plan != "" {
 		wantedPerms = append(wantedPerms, permission.PermServiceInstanceUpdatePlan)
 	}
+	if len(parameters) > 0 {
+		wantedPerms = append(wantedPerms, permission.PermServiceInstanceUpdateParameters)
+	}
 	if len(wantedPerms) == 0 {
 		return &tsuruErrors.HTTP{
 			Code:    http.StatusBadRequest,
-			Message: "Neither the description, team owner, tags or plan were set. You must define at least one.",
+			Message: "Neither the description, team owner, tags, plan or parameters were set. You must define at least one.",
 		}
 	}
 	for _, perm := range wantedPerms {
@@ -190,6 +198,16 @@ func updateServiceInstance(w http.ResponseWriter, r *http.Request, t auth.Token)
 	if tags != nil {
 		si.Tags = tags
 	}
+	if len(parameters) > 0 {
+		merged := make(map[string]interface{}, len(si.Parameters)+len(parameters))
+		for k, v := range si.Parameters {
+			merged[k] = v
+		}
+		for k, v := range parameters {
+			merged[k] = v
+		}
+		si.Parameters = merged
+	}
 	requestID := requestIDHeader(r)
 	return si.Update(srv, *si, evt, requestID)
 }
@@ -404,6 +422,8 @@ type serviceInstanceInfo struct {
 	PlanDescription string
 	CustomInfo      map[string]string
 	Tags            []string
+	Health          *service.InstanceHealth `json:",omitempty"`
+	Parameters      map[string]interface{}  `json:",omitempty"`
 }
 
 // title: service instance info
@@ -445,6 +465,8 @@ func serviceInstance(w http.ResponseWriter, r *http.Request, t auth.Token) error
 		PlanDescription: plan.Description,
 		CustomInfo:      info,
 		Tags:            serviceInstance.Tags,
+		Health:          serviceInstance.Health,
+		Parameters:      serviceInstance.Parameters,
 	}
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(sInfo)
@@ -542,6 +564,41 @@ func servicePlans(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(plans)
 }
 
+// title: service parameters schema
+// path: /services/{name}/schema
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: Service not found
+func serviceParametersSchema(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	serviceName := r.URL.Query().Get(":name")
+	s, err := getService(serviceName)
+	if err != nil {
+		return err
+	}
+	if s.IsRestricted {
+		allowed := permission.Check(t, permission.PermServiceReadSchema,
+			contextsForService(&s)...,
+		)
+		if !allowed {
+			return permission.ErrUnauthorized
+		}
+	}
+	schema, err := service.GetParametersSchema(serviceName, requestIDHeader(r))
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(schema)
+}
+
 func parseFormPreserveBody(r *http.Request) {
 	var buf bytes.Buffer
 	var readCloser struct {
@@ -601,6 +658,175 @@ func serviceInstanceProxy(w http.ResponseWriter, r *http.Request, t auth.Token)
 	return service.ProxyInstance(serviceInstance, path, evt, requestIDHeader(r), w, r)
 }
 
+// title: reconcile service instance
+// path: /services/{service}/instances/{instance}/reconcile
+// consume: application/x-www-form-urlencoded
+// method: POST
+// responses:
+//   200: Service instance reconciled
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Service instance not found
+func serviceInstanceReconcile(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	instanceName := r.URL.Query().Get(":instance")
+	serviceName := r.URL.Query().Get(":service")
+	serviceInstance, err := getServiceInstanceOrError(serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermServiceInstanceUpdateReconcile,
+		contextsForServiceInstance(serviceInstance, serviceName)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     serviceInstanceTarget(serviceName, instanceName),
+		Kind:       permission.PermServiceInstanceUpdateReconcile,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed: event.Allowed(permission.PermServiceInstanceReadEvents,
+			contextsForServiceInstance(serviceInstance, serviceName)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return serviceInstance.Reconcile(r.FormValue("action"), evt, requestIDHeader(r))
+}
+
+// title: clone service instance
+// path: /services/{service}/instances/{instance}/clone
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// method: POST
+// responses:
+//   201: Service instance cloned
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Service instance or app not found
+//   409: Service instance already exists
+func serviceInstanceClone(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return err
+	}
+	instanceName := r.URL.Query().Get(":instance")
+	serviceName := r.URL.Query().Get(":service")
+	source, err := getServiceInstanceOrError(serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermServiceInstanceReadClone,
+		contextsForServiceInstance(source, serviceName)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	instance := service.ServiceInstance{
+		Name:        r.FormValue("name"),
+		PlanName:    r.FormValue("plan"),
+		TeamOwner:   r.FormValue("owner"),
+		Description: r.FormValue("description"),
+		Tags:        r.Form["tag"],
+		Parameters:  instanceParametersFromForm(r.Form),
+	}
+	if instance.TeamOwner == "" {
+		instance.TeamOwner, err = permission.TeamForPermission(t, permission.PermServiceInstanceCreate)
+		if err != nil {
+			return err
+		}
+	}
+	allowed = permission.Check(t, permission.PermServiceInstanceCreate,
+		permission.Context(permission.CtxTeam, instance.TeamOwner),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	var a *app.App
+	appName := r.FormValue("app")
+	if appName != "" {
+		a, err = app.GetByName(appName)
+		if err != nil {
+			if err == app.ErrAppNotFound {
+				return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+			}
+			return err
+		}
+		allowed = permission.Check(t, permission.PermAppUpdateBind,
+			contextsForApp(a)...,
+		)
+		if !allowed {
+			return permission.ErrUnauthorized
+		}
+		err = a.ValidateService(serviceName)
+		if err != nil {
+			if err == pool.ErrPoolHasNoService {
+				return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+			}
+			return err
+		}
+	}
+	instance.Teams = []string{instance.TeamOwner}
+	evt, err := event.New(&event.Opts{
+		Target:       serviceInstanceTarget(serviceName, instance.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: serviceInstanceTarget(serviceName, source.Name)}},
+		Kind:         permission.PermServiceInstanceCreate,
+		Owner:        t,
+		CustomData:   event.FormToCustomData(r.Form),
+		Allowed: event.Allowed(permission.PermServiceInstanceReadEvents,
+			contextsForServiceInstance(&instance, serviceName)...),
+	})
+	if err != nil {
+		return err
+	}
+	requestID := requestIDHeader(r)
+	err = service.CloneServiceInstance(*source, instance, evt, requestID)
+	evt.Done(err)
+	switch err {
+	case nil:
+	case service.ErrInstanceNameAlreadyExists:
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	case service.ErrInvalidInstanceName, service.ErrCloneNotSupported:
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	default:
+		return err
+	}
+	if a == nil {
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	}
+	clone, err := service.GetServiceInstance(serviceName, instance.Name)
+	if err != nil {
+		return err
+	}
+	noRestart, _ := strconv.ParseBool(r.FormValue("noRestart"))
+	bindEvt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateBind,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { bindEvt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	w.WriteHeader(http.StatusCreated)
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	fmt.Fprintf(writer, "Instance %q cloned from %q.\n", clone.Name, source.Name)
+	err = clone.BindApp(a, !noRestart, writer, bindEvt, requestID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "Instance %q is now bound to the app %q.\n", clone.Name, a.Name)
+	return nil
+}
+
 // title: grant access to service instance
 // path: /services/{service}/instances/permission/{instance}/{team}
 // consume: application/x-www-form-urlencoded
@@ -676,6 +902,22 @@ func serviceInstanceRevokeTeam(w http.ResponseWriter, r *http.Request, t auth.To
 	return serviceInstance.Revoke(teamName)
 }
 
+// instanceParametersFromForm returns the instance parameters in the form,
+// sent as "parameters.<name>=<value>".
+func instanceParametersFromForm(form url.Values) map[string]interface{} {
+	var params map[string]interface{}
+	for k, v := range form {
+		if !strings.HasPrefix(k, "parameters.") || len(v) == 0 {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		params[strings.TrimPrefix(k, "parameters.")] = v[0]
+	}
+	return params
+}
+
 func contextsForServiceInstance(si *service.ServiceInstance, serviceName string) []permission.PermissionContext {
 	permissionValue := serviceIntancePermName(serviceName, si.Name)
 	return append(permission.Contexts(permission.CtxTeam, si.Teams),
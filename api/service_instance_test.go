@@ -805,7 +805,7 @@ func (s *ServiceInstanceSuite) TestUpdateServiceInstanceEmptyFields(c *check.C)
 	recorder, request := makeRequestToUpdateServiceInstance(params, "mysql", "brainsql", s.token.GetValue(), c)
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
-	c.Assert(recorder.Body.String(), check.Equals, "Neither the description, team owner, tags or plan were set. You must define at least one.\n")
+	c.Assert(recorder.Body.String(), check.Equals, "Neither the description, team owner, tags, plan or parameters were set. You must define at least one.\n")
 }
 
 func makeRequestToRemoveServiceInstance(service, instance string, c *check.C) (*httptest.ResponseRecorder, *http.Request) {
@@ -2091,3 +2091,214 @@ func (s *ServiceInstanceSuite) TestGrantRevokeServiceToTeamWithManyInstanceName(
 	c.Assert(err, check.IsNil)
 	c.Assert(sinst.Teams, check.DeepEquals, []string{s.team.Name})
 }
+
+func (s *ServiceInstanceSuite) TestServiceInstanceReconcileBroken(c *check.C) {
+	se := service.Service{Name: "go", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := se.Create()
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "si-test", ServiceName: "go", Teams: []string{s.team.Name}, Health: &service.InstanceHealth{Status: service.InstanceStatusDown}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("action=broken")
+	request, err := http.NewRequest("POST", "/1.6/services/go/instances/si-test/reconcile", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	sinst, err := service.GetServiceInstance(si.ServiceName, si.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(sinst.Health.Broken, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: serviceInstanceTarget("go", "si-test"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "service-instance.update.reconcile",
+		StartCustomData: []map[string]interface{}{
+			{"name": "action", "value": "broken"},
+			{"name": ":service", "value": "go"},
+			{"name": ":instance", "value": "si-test"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceReconcileRecreateNotGone(c *check.C) {
+	se := service.Service{Name: "go", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := se.Create()
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "si-test", ServiceName: "go", Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("action=recreate")
+	request, err := http.NewRequest("POST", "/1.6/services/go/instances/si-test/reconcile", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, service.ErrInstanceNotGone.Error()+"\n")
+}
+
+func (s *ServiceInstanceSuite) TestCreateInstanceWithParameters(c *check.C) {
+	var createParams url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/resources/schema" {
+			w.Write([]byte(`{"type": "object", "properties": {"size": {"type": "integer", "maximum": 10}}, "required": ["size"]}`))
+			return
+		}
+		r.ParseForm()
+		createParams = r.Form
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	se := service.Service{
+		Name:       "mysql",
+		OwnerTeams: []string{s.team.Name},
+		Endpoint:   map[string]string{"production": ts.URL},
+		Password:   "abcde",
+	}
+	err := se.Create()
+	c.Assert(err, check.IsNil)
+	params := map[string]interface{}{
+		"name":            "brainsql",
+		"service_name":    "mysql",
+		"owner":           s.team.Name,
+		"parameters.size": "20",
+	}
+	recorder, request := makeRequestToCreateServiceInstance(params, c)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "Invalid parameters: size: must be less than or equal to 10\n")
+	c.Assert(createParams, check.IsNil)
+	params = map[string]interface{}{
+		"name":            "brainsql",
+		"service_name":    "mysql",
+		"owner":           s.team.Name,
+		"parameters.size": "5",
+	}
+	recorder, request = makeRequestToCreateServiceInstance(params, c)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	c.Assert(createParams.Get("parameters.size"), check.Equals, "5")
+	si, err := service.GetServiceInstance("mysql", "brainsql")
+	c.Assert(err, check.IsNil)
+	c.Assert(si.Parameters, check.DeepEquals, map[string]interface{}{"size": int64(5)})
+}
+
+func (s *ServiceInstanceSuite) TestServiceParametersSchema(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type": "object", "properties": {"size": {"type": "integer", "description": "Cluster size"}}}`))
+	}))
+	defer ts.Close()
+	se := service.Service{
+		Name:       "mysql",
+		OwnerTeams: []string{s.team.Name},
+		Endpoint:   map[string]string{"production": ts.URL},
+		Password:   "abcde",
+	}
+	err := se.Create()
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/services/mysql/schema", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var schema service.ParametersSchema
+	err = json.NewDecoder(recorder.Body).Decode(&schema)
+	c.Assert(err, check.IsNil)
+	c.Assert(schema.Properties["size"], check.DeepEquals, &service.PropertySchema{Type: "integer", Description: "Cluster size"})
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceClone(c *check.C) {
+	var reqs []*http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/resources/schema" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		r.ParseForm()
+		reqs = append(reqs, r)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	se := service.Service{Name: "mysql", Endpoint: map[string]string{"production": ts.URL}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := se.Create()
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "prod-db", ServiceName: "mysql", PlanName: "large", TeamOwner: s.team.Name, Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("name=staging-db&owner=" + s.team.Name)
+	request, err := http.NewRequest("POST", "/1.6/services/mysql/instances/prod-db/clone", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].URL.Path, check.Equals, "/resources/prod-db/clone")
+	c.Assert(reqs[0].Form.Get("name"), check.Equals, "staging-db")
+	c.Assert(reqs[0].Form.Get("plan"), check.Equals, "large")
+	clone, err := service.GetServiceInstance("mysql", "staging-db")
+	c.Assert(err, check.IsNil)
+	c.Assert(clone.PlanName, check.Equals, "large")
+	c.Assert(clone.Teams, check.DeepEquals, []string{s.team.Name})
+	c.Assert(eventtest.EventDesc{
+		Target: serviceInstanceTarget("mysql", "staging-db"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "service-instance.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": "staging-db"},
+			{"name": "owner", "value": s.team.Name},
+			{"name": ":service", "value": "mysql"},
+			{"name": ":instance", "value": "prod-db"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceCloneNotSupported(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	se := service.Service{Name: "mysql", Endpoint: map[string]string{"production": ts.URL}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := se.Create()
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "prod-db", ServiceName: "mysql", TeamOwner: s.team.Name, Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("name=staging-db&owner=" + s.team.Name)
+	request, err := http.NewRequest("POST", "/1.6/services/mysql/instances/prod-db/clone", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, service.ErrCloneNotSupported.Error()+"\n")
+	_, err = service.GetServiceInstance("mysql", "staging-db")
+	c.Assert(err, check.Equals, service.ErrServiceInstanceNotFound)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceCloneAppNotFound(c *check.C) {
+	se := service.Service{Name: "mysql", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := se.Create()
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "prod-db", ServiceName: "mysql", TeamOwner: s.team.Name, Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("name=staging-db&app=unknown")
+	request, err := http.NewRequest("POST", "/1.6/services/mysql/instances/prod-db/clone", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "App unknown not found.\n")
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+// requestIP returns the address of the client of the request. The
+// X-Forwarded-For header is only considered when the request comes from one
+// of the proxies in server:trusted-proxies, as any client can set it. The
+// header is read from the right, skipping the trusted proxies, so addresses
+// prepended by the client are ignored.
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if forwarded == "" {
+		return host
+	}
+	proxies := trustedProxies()
+	if !isTrustedProxy(host, proxies) {
+		return host
+	}
+	addrs := strings.Split(forwarded, ",")
+	for i := len(addrs) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(addrs[i])
+		if addr == "" {
+			continue
+		}
+		host = addr
+		if !isTrustedProxy(addr, proxies) {
+			break
+		}
+	}
+	return host
+}
+
+// trustedProxies returns the networks of the proxies in front of the API,
+// configured as IPs or CIDRs in server:trusted-proxies.
+func trustedProxies() []*net.IPNet {
+	values, _ := config.GetList("server:trusted-proxies")
+	var proxies []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			log.Errorf("invalid trusted proxy %q: %v", value, err)
+			continue
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies
+}
+
+func isTrustedProxy(addr string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// title: list sessions
+// path: /users/sessions
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+func listSessions(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	sessions, err := auth.ListSessions(t.GetUserName(), t.GetValue())
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(sessions)
+}
+
+// title: revoke session
+// path: /users/sessions/{id}
+// method: DELETE
+// responses:
+//   200: Session revoked
+//   401: Unauthorized
+//   404: Session not found
+func revokeSession(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	email := t.GetUserName()
+	evt, err := event.New(&event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserUpdateSessions,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = auth.RevokeSession(email, r.URL.Query().Get(":id"))
+	if err == auth.ErrSessionNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: revoke user sessions
+// path: /users/{email}/sessions
+// method: DELETE
+// responses:
+//   200: Sessions revoked
+//   401: Unauthorized
+//   403: Forbidden
+//   404: User not found
+func revokeUserSessions(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	email := r.URL.Query().Get(":email")
+	allowed := permission.Check(t, permission.PermUserUpdateSessions, permission.Context(permission.CtxUser, email))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = auth.GetUserByEmail(email)
+	if err == authTypes.ErrUserNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     userTarget(email),
+		Kind:       permission.PermUserUpdateSessions,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, email)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return auth.RevokeSessions(email)
+}
@@ -0,0 +1,174 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"gopkg.in/check.v1"
+)
+
+func (s *AuthSuite) TestRequestIP(c *check.C) {
+	request, err := http.NewRequest(http.MethodGet, "/", nil)
+	c.Assert(err, check.IsNil)
+	request.RemoteAddr = "10.0.0.1:43210"
+	c.Assert(requestIP(request), check.Equals, "10.0.0.1")
+	request.Header.Set("X-Forwarded-For", "192.168.1.10, 10.0.0.2")
+	c.Assert(requestIP(request), check.Equals, "10.0.0.1")
+}
+
+func (s *AuthSuite) TestRequestIPTrustedProxies(c *check.C) {
+	config.Set("server:trusted-proxies", []interface{}{"10.0.0.1", "10.1.0.0/16"})
+	defer config.Unset("server:trusted-proxies")
+	request, err := http.NewRequest(http.MethodGet, "/", nil)
+	c.Assert(err, check.IsNil)
+	request.RemoteAddr = "10.0.0.1:43210"
+	c.Assert(requestIP(request), check.Equals, "10.0.0.1")
+	request.Header.Set("X-Forwarded-For", "1.1.1.1, 192.168.1.10, 10.1.2.3")
+	c.Assert(requestIP(request), check.Equals, "192.168.1.10")
+	request.Header.Set("X-Forwarded-For", "10.1.2.3")
+	c.Assert(requestIP(request), check.Equals, "10.1.2.3")
+	request.RemoteAddr = "10.0.0.9:43210"
+	request.Header.Set("X-Forwarded-For", "192.168.1.10")
+	c.Assert(requestIP(request), check.Equals, "10.0.0.9")
+}
+
+func (s *AuthSuite) TestLoginRecordsSessionInfo(c *check.C) {
+	u := auth.User{Email: "nobody@globo.com", Password: "123456"}
+	_, err := nativeScheme.Create(&u)
+	c.Assert(err, check.IsNil)
+	b := strings.NewReader("password=123456&session-ip=1.1.1.1")
+	request, err := http.NewRequest(http.MethodPost, "/users/nobody@globo.com/tokens", b)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("User-Agent", "tsuru-client/1.6.0")
+	request.RemoteAddr = "10.0.0.1:43210"
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	sessions, err := auth.ListSessions(u.Email, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 1)
+	c.Assert(sessions[0].Client, check.Equals, "tsuru-client/1.6.0")
+	c.Assert(sessions[0].IP, check.Equals, "10.0.0.1")
+}
+
+func (s *AuthSuite) TestListSessions(c *check.C) {
+	other, err := nativeScheme.Login(map[string]string{
+		"email":                 s.user.Email,
+		"password":              "123456",
+		auth.SessionClientParam: "tsuru-dashboard",
+		auth.SessionIPParam:     "10.0.0.1",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/users/sessions", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(recorder.Body.String(), check.Not(check.Matches), ".*"+s.token.GetValue()+".*")
+	var sessions []auth.Session
+	err = json.Unmarshal(recorder.Body.Bytes(), &sessions)
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 2)
+	c.Assert(sessions[0].ID, check.Equals, auth.SessionID(s.token.GetValue()))
+	c.Assert(sessions[0].Current, check.Equals, true)
+	c.Assert(sessions[1].ID, check.Equals, auth.SessionID(other.GetValue()))
+	c.Assert(sessions[1].Current, check.Equals, false)
+	c.Assert(sessions[1].Client, check.Equals, "tsuru-dashboard")
+	c.Assert(sessions[1].IP, check.Equals, "10.0.0.1")
+}
+
+func (s *AuthSuite) TestRevokeSession(c *check.C) {
+	other, err := nativeScheme.Login(map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	id := auth.SessionID(other.GetValue())
+	request, err := http.NewRequest(http.MethodDelete, "/users/sessions/"+id, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = nativeScheme.Auth(other.GetValue())
+	c.Assert(err, check.Equals, auth.ErrInvalidToken)
+	_, err = nativeScheme.Auth(s.token.GetValue())
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: userTarget(s.user.Email),
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.update.sessions",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":id", "value": id},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestRevokeSessionOfOtherUser(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	request, err := http.NewRequest(http.MethodDelete, "/users/sessions/"+auth.SessionID(token.GetValue()), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	_, err = nativeScheme.Auth(token.GetValue())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *AuthSuite) TestRevokeUserSessions(c *check.C) {
+	user, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	request, err := http.NewRequest(http.MethodDelete, "/users/"+user.Email+"/sessions", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = nativeScheme.Auth(token.GetValue())
+	c.Assert(err, check.Equals, auth.ErrInvalidToken)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeUser, Value: user.Email},
+		Owner:  s.token.GetUserName(),
+		Kind:   "user.update.sessions",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":email", "value": user.Email},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *AuthSuite) TestRevokeUserSessionsRequiresPermission(c *check.C) {
+	user, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "someone")
+	_, adminToken := permissiontest.CustomUserWithPermission(c, nativeScheme, "otheradmin", permission.Permission{
+		Scheme:  permission.PermUserUpdateSessions,
+		Context: permission.Context(permission.CtxUser, "nobody@globo.com"),
+	})
+	request, err := http.NewRequest(http.MethodDelete, "/users/"+user.Email+"/sessions", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+adminToken.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	_, err = nativeScheme.Auth(token.GetValue())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *AuthSuite) TestRevokeUserSessionsUserNotFound(c *check.C) {
+	request, err := http.NewRequest(http.MethodDelete, "/users/nobody@globo.com/sessions", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
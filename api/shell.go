@@ -6,6 +6,7 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,8 +15,11 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/globalsign/mgo/bson"
 	"github.com/gorilla/websocket"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
@@ -94,8 +98,95 @@ var upgrader = websocket.Upgrader{
 var (
 	pongWait     = 60 * time.Second
 	pingInterval = 20 * time.Second
+
+	idleCheckInterval = 10 * time.Second
 )
 
+// activityConn tracks the last time the client sent data through the shell
+// connection.
+type activityConn struct {
+	io.ReadWriteCloser
+	mu           sync.Mutex
+	lastActivity time.Time
+}
+
+func (c *activityConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	if n > 0 {
+		c.mu.Lock()
+		c.lastActivity = time.Now()
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+func (c *activityConn) idleTime() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Since(c.lastActivity)
+}
+
+func shellIdleTimeout() time.Duration {
+	seconds, _ := config.GetInt("shell:idle-timeout")
+	return time.Duration(seconds) * time.Second
+}
+
+func runningShells(filter event.Filter) ([]event.Event, error) {
+	running := true
+	filter.KindNames = []string{permission.PermAppRunShell.FullName()}
+	filter.Running = &running
+	return event.List(&filter)
+}
+
+// shellsStartedBefore returns the number of running shell sessions matching
+// the filter that were started before the session of the given event.
+func shellsStartedBefore(evt *event.Event, filter event.Filter) (int, error) {
+	sessions, err := runningShells(filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for _, s := range sessions {
+		if s.UniqueID < evt.UniqueID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// checkShellLimits returns an error if the shell session of the given event
+// exceeds the configured limits of concurrent sessions per app or per user.
+// The event of the session must already be running, so sessions started at
+// the same time see each other and only the ones with the newest events,
+// beyond the limits, are rejected.
+func checkShellLimits(evt *event.Event, appName string, t auth.Token) error {
+	if maxPerApp, _ := config.GetInt("shell:max-sessions-per-app"); maxPerApp > 0 {
+		count, err := shellsStartedBefore(evt, event.Filter{Target: appTarget(appName)})
+		if err != nil {
+			return err
+		}
+		if count >= maxPerApp {
+			return &errors.HTTP{
+				Code:    http.StatusTooManyRequests,
+				Message: fmt.Sprintf("app %q already has %d shell sessions, the maximum allowed", appName, count),
+			}
+		}
+	}
+	if maxPerUser, _ := config.GetInt("shell:max-sessions-per-user"); maxPerUser > 0 {
+		count, err := shellsStartedBefore(evt, event.Filter{OwnerName: t.GetUserName()})
+		if err != nil {
+			return err
+		}
+		if count >= maxPerUser {
+			return &errors.HTTP{
+				Code:    http.StatusTooManyRequests,
+				Message: fmt.Sprintf("user %q already has %d shell sessions, the maximum allowed", t.GetUserName(), count),
+			}
+		}
+	}
+	return nil
+}
+
 // title: app shell
 // path: /apps/{name}/shell
 // method: GET
@@ -156,12 +247,14 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 	height, _ := strconv.Atoi(r.URL.Query().Get("height"))
 	clientTerm := r.URL.Query().Get("term")
 	evt, err := event.New(&event.Opts{
-		Target:      appTarget(appName),
-		Kind:        permission.PermAppRunShell,
-		Owner:       token,
-		CustomData:  event.FormToCustomData(r.Form),
-		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
-		DisableLock: true,
+		Target:        appTarget(appName),
+		Kind:          permission.PermAppRunShell,
+		Owner:         token,
+		CustomData:    event.FormToCustomData(r.Form),
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+		DisableLock:   true,
+		Cancelable:    true,
+		AllowedCancel: event.Allowed(permission.PermAppAdminShell, contextsForApp(&a)...),
 	})
 	if err != nil {
 		httpErr = &errors.HTTP{
@@ -170,6 +263,19 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	err = checkShellLimits(evt, a.Name, token)
+	if err != nil {
+		evt.Abort()
+		if herr, ok := err.(*errors.HTTP); ok {
+			httpErr = herr
+		} else {
+			httpErr = &errors.HTTP{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			}
+		}
+		return
+	}
 	defer func() {
 		var finalErr error
 		if httpErr != nil {
@@ -204,14 +310,59 @@ func remoteShellHandler(w http.ResponseWriter, r *http.Request) {
 			ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(2*time.Second))
 		}
 	}()
+	conn := &activityConn{ReadWriteCloser: &wsReadWriteCloser{ws}, lastActivity: time.Now()}
+	var (
+		terminateMu     sync.Mutex
+		terminateReason string
+		finished        bool
+	)
+	terminate := func(reason string) {
+		terminateMu.Lock()
+		defer terminateMu.Unlock()
+		if finished || terminateReason != "" {
+			return
+		}
+		terminateReason = reason
+		ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason), time.Now().Add(2*time.Second))
+		ws.Close()
+	}
+	ctx, cancel := evt.CancelableContext(r.Context())
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		terminate("shell session terminated")
+	}()
+	if idleTimeout := shellIdleTimeout(); idleTimeout > 0 {
+		go func() {
+			for {
+				select {
+				case <-quit:
+					return
+				case <-time.After(idleCheckInterval):
+				}
+				if conn.idleTime() > idleTimeout {
+					terminate("shell session terminated after being idle for " + idleTimeout.String())
+					return
+				}
+			}
+		}()
+	}
 	opts := provision.ShellOptions{
-		Conn:   &cmdLogger{base: &wsReadWriteCloser{ws}, term: term},
+		Conn:   &cmdLogger{base: conn, term: term},
 		Width:  width,
 		Height: height,
 		Unit:   unitID,
 		Term:   clientTerm,
 	}
 	err = a.Shell(opts)
+	terminateMu.Lock()
+	finished = true
+	reason := terminateReason
+	terminateMu.Unlock()
+	if reason != "" {
+		evt.Logf(reason)
+		return
+	}
 	if err != nil {
 		httpErr = &errors.HTTP{
 			Code:    http.StatusInternalServerError,
@@ -238,3 +389,79 @@ func (c *wsReadWriteCloser) Read(p []byte) (n int, err error) {
 func (c *wsReadWriteCloser) Write(p []byte) (n int, err error) {
 	return len(p), c.Conn.WriteMessage(websocket.TextMessage, p)
 }
+
+// title: list app shell sessions
+// path: /apps/{app}/shells
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func listShellSessions(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppAdminShell, contextsForApp(&a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	sessions, err := runningShells(event.Filter{Target: appTarget(a.Name)})
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(sessions)
+}
+
+// title: terminate app shell session
+// path: /apps/{app}/shells/{uuid}
+// method: DELETE
+// responses:
+//   204: Session termination requested
+//   400: Invalid uuid
+//   401: Unauthorized
+//   404: App or session not found
+func terminateShellSession(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	uuid := r.URL.Query().Get(":uuid")
+	if !bson.IsObjectIdHex(uuid) {
+		msg := fmt.Sprintf("uuid parameter is not ObjectId: %s", uuid)
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
+	}
+	e, err := event.GetByID(bson.ObjectIdHex(uuid))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if e.Target != appTarget(a.Name) || e.Kind.Name != permission.PermAppRunShell.FullName() || !e.Running {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: "shell session not found"}
+	}
+	// Users are always allowed to terminate their own sessions.
+	if e.Owner.Name != t.GetUserName() {
+		allowed := permission.Check(t, permission.PermAppAdminShell, contextsForApp(&a)...)
+		if !allowed {
+			return permission.ErrUnauthorized
+		}
+	}
+	reason := r.FormValue("reason")
+	if reason == "" {
+		reason = "terminated by " + t.GetUserName()
+	}
+	err = e.TryCancel(reason, t.GetUserName())
+	if err != nil && err != event.ErrCancelAlreadyRequested {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -5,13 +5,19 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"time"
 
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/tsurutest"
@@ -204,3 +210,139 @@ func (s *S) TestAppShellGenericError(c *check.C) {
 	})
 	c.Assert(err, check.IsNil)
 }
+
+func (s *S) TestAppShellMaxSessionsPerApp(c *check.C) {
+	config.Set("shell:max-sessions-per-app", 1)
+	defer config.Unset("shell:max-sessions-per-app")
+	a := app.App{
+		Name:      "someapp",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+	}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddUnits(&a, 1, "web", nil)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:      appTarget(a.Name),
+		Kind:        permission.PermAppRunShell,
+		Owner:       s.token,
+		Allowed:     event.Allowed(permission.PermAppReadEvents),
+		DisableLock: true,
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(nil)
+	server := httptest.NewServer(s.testServer)
+	defer server.Close()
+	testServerURL, err := url.Parse(server.URL)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("ws://%s/apps/%s/shell?width=140&height=38&term=xterm", testServerURL.Host, a.Name)
+	wsConfig, err := websocket.NewConfig(url, "ws://localhost/")
+	c.Assert(err, check.IsNil)
+	wsConfig.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	wsConn, err := websocket.DialConfig(wsConfig)
+	c.Assert(err, check.IsNil)
+	defer wsConn.Close()
+	var result string
+	err = tsurutest.WaitCondition(5*time.Second, func() bool {
+		part, readErr := ioutil.ReadAll(wsConn)
+		if readErr != nil {
+			return false
+		}
+		result += string(part)
+		return result == "Error: app \"someapp\" already has 1 shell sessions, the maximum allowed\n"
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCheckShellLimitsConcurrentSessions(c *check.C) {
+	config.Set("shell:max-sessions-per-user", 1)
+	defer config.Unset("shell:max-sessions-per-user")
+	var evts []*event.Event
+	for i := 0; i < 2; i++ {
+		evt, err := event.New(&event.Opts{
+			Target:      appTarget("someapp"),
+			Kind:        permission.PermAppRunShell,
+			Owner:       s.token,
+			Allowed:     event.Allowed(permission.PermAppReadEvents),
+			DisableLock: true,
+		})
+		c.Assert(err, check.IsNil)
+		defer evt.Done(nil)
+		evts = append(evts, evt)
+	}
+	err := checkShellLimits(evts[1], "someapp", s.token)
+	c.Assert(err, check.DeepEquals, &errors.HTTP{
+		Code:    http.StatusTooManyRequests,
+		Message: fmt.Sprintf("user %q already has 1 shell sessions, the maximum allowed", s.token.GetUserName()),
+	})
+	err = checkShellLimits(evts[0], "someapp", s.token)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestListShellSessions(c *check.C) {
+	a := app.App{Name: "someapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/apps/someapp/shells", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	evt, err := event.New(&event.Opts{
+		Target:      appTarget(a.Name),
+		Kind:        permission.PermAppRunShell,
+		Owner:       s.token,
+		Allowed:     event.Allowed(permission.PermAppReadEvents),
+		DisableLock: true,
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(nil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var sessions []event.Event
+	err = json.NewDecoder(recorder.Body).Decode(&sessions)
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 1)
+	c.Assert(sessions[0].UniqueID, check.Equals, evt.UniqueID)
+}
+
+func (s *S) TestTerminateShellSession(c *check.C) {
+	a := app.App{Name: "someapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(a.Name),
+		Kind:          permission.PermAppRunShell,
+		Owner:         s.token,
+		Allowed:       event.Allowed(permission.PermAppReadEvents),
+		AllowedCancel: event.Allowed(permission.PermAppAdminShell),
+		Cancelable:    true,
+		DisableLock:   true,
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(nil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/someapp/shells/"+evt.UniqueID.Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	canceled, err := evt.AckCancel()
+	c.Assert(err, check.IsNil)
+	c.Assert(canceled, check.Equals, true)
+}
+
+func (s *S) TestTerminateShellSessionNotFound(c *check.C) {
+	a := app.App{Name: "someapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.6/apps/someapp/shells/"+bson.NewObjectId().Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+)
+
+// title: set app sticky session
+// path: /apps/{app}/sticky-session
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Sticky session set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appStickySessionSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	session := &router.StickySession{CookieName: r.FormValue("cookieName")}
+	if ttl := r.FormValue("ttl"); ttl != "" {
+		session.TTL, err = strconv.Atoi(ttl)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid TTL, it must be a number of seconds"}
+		}
+	}
+	err = session.Validate()
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return updateAppStickySession(r, t, session)
+}
+
+// title: unset app sticky session
+// path: /apps/{app}/sticky-session
+// method: DELETE
+// responses:
+//   200: Sticky session removed
+//   401: Unauthorized
+//   404: App not found
+func appStickySessionUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	return updateAppStickySession(r, t, nil)
+}
+
+func updateAppStickySession(r *http.Request, t auth.Token, session *router.StickySession) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateStickySession,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateStickySession,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetStickySession(session)
+}
@@ -100,6 +100,7 @@ func (s *S) SetUpSuite(c *check.C) {
 	err := config.ReadConfigFile("testdata/config.yaml")
 	c.Assert(err, check.IsNil)
 	config.Set("log:disable-syslog", true)
+	config.Set("docker:nodecontainer:secrets:key", "secret-store-key")
 	config.Set("database:driver", "mongodb")
 	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
 	config.Set("database:name", "tsuru_api_base_test")
@@ -110,8 +111,20 @@ func (s *S) SetUpSuite(c *check.C) {
 func (s *S) SetUpTest(c *check.C) {
 	config.Set("routers:fake:default", true)
 	config.Set("routers:fake-tls:type", "fake-tls")
+	config.Set("routers:fake-protocol:type", "fake-protocol")
+	config.Set("routers:fake-sticky:type", "fake-sticky")
+	config.Set("routers:fake-mirror:type", "fake-mirror")
+	config.Set("routers:fake-accesslog:type", "fake-accesslog")
+	config.Set("routers:fake-errorpage:type", "fake-errorpage")
+	config.Set("routers:fake-status:type", "fake-status")
 	routertest.FakeRouter.Reset()
 	routertest.TLSRouter.Reset()
+	routertest.ProtocolRouter.Reset()
+	routertest.StickyRouter.Reset()
+	routertest.MirrorRouter.Reset()
+	routertest.AccessLogRouter.Reset()
+	routertest.ErrorPageRouter.Reset()
+	routertest.StatusRouter.Reset()
 	repositorytest.Reset()
 	var err error
 	s.conn, err = db.Conn()
@@ -0,0 +1,223 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/router"
+)
+
+type appTLSPolicy struct {
+	Policy     *router.TLSPolicy `json:"policy"`
+	Source     string            `json:"source,omitempty"`
+	Weaknesses []string          `json:"weaknesses"`
+}
+
+// tlsPolicyFromForm reads a TLS policy from the fields minVersion, cipher,
+// hsts.maxAge, hsts.includeSubdomains and hsts.preload.
+func tlsPolicyFromForm(r *http.Request) (*router.TLSPolicy, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	policy := &router.TLSPolicy{
+		MinVersion:   r.FormValue("minVersion"),
+		CipherSuites: r.Form["cipher"],
+	}
+	if maxAge := r.FormValue("hsts.maxAge"); maxAge != "" {
+		policy.HSTS = &router.HSTSPolicy{}
+		policy.HSTS.MaxAge, err = strconv.Atoi(maxAge)
+		if err != nil {
+			return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid HSTS max-age, it must be a number of seconds"}
+		}
+		policy.HSTS.IncludeSubdomains, _ = strconv.ParseBool(r.FormValue("hsts.includeSubdomains"))
+		policy.HSTS.Preload, _ = strconv.ParseBool(r.FormValue("hsts.preload"))
+	}
+	err = policy.Validate()
+	if err != nil {
+		return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return policy, nil
+}
+
+// title: app tls policy
+// path: /apps/{app}/tls-policy
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+func appTLSPolicyGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadTlsPolicy,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	policy, source, err := a.EffectiveTLSPolicy()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(appTLSPolicy{
+		Policy:     policy,
+		Source:     source,
+		Weaknesses: policy.Weaknesses(),
+	})
+}
+
+// title: set app tls policy
+// path: /apps/{app}/tls-policy
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: TLS policy set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appTLSPolicySet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	policy, err := tlsPolicyFromForm(r)
+	if err != nil {
+		return err
+	}
+	return updateAppTLSPolicy(r, t, policy)
+}
+
+// title: unset app tls policy
+// path: /apps/{app}/tls-policy
+// method: DELETE
+// responses:
+//   200: TLS policy removed
+//   401: Unauthorized
+//   404: App not found
+func appTLSPolicyUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	return updateAppTLSPolicy(r, t, nil)
+}
+
+func updateAppTLSPolicy(r *http.Request, t auth.Token, policy *router.TLSPolicy) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateTlsPolicy,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateTlsPolicy,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetTLSPolicy(policy)
+}
+
+// title: set pool tls policy
+// path: /pools/{name}/tls-policy
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: TLS policy set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Pool not found
+func poolTLSPolicySet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	policy, err := tlsPolicyFromForm(r)
+	if err != nil {
+		return err
+	}
+	return updatePoolTLSPolicy(r, t, policy)
+}
+
+// title: unset pool tls policy
+// path: /pools/{name}/tls-policy
+// method: DELETE
+// responses:
+//   200: TLS policy removed
+//   401: Unauthorized
+//   404: Pool not found
+func poolTLSPolicyUnset(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	r.ParseForm()
+	return updatePoolTLSPolicy(r, t, nil)
+}
+
+func updatePoolTLSPolicy(r *http.Request, t auth.Token, policy *router.TLSPolicy) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	allowed := permission.Check(t, permission.PermPoolUpdateTlsPolicy,
+		permission.Context(permission.CtxPool, poolName),
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateTlsPolicy,
+		Owner:      t,
+		CustomData: event.FormToCustomData(r.Form),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permission.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = app.SetPoolTLSPolicy(poolName, policy)
+	if err == pool.ErrPoolNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: tls compliance report
+// path: /tls-policy/report
+// method: GET
+// produce: application/json
+// responses:
+//   200: Apps with weak TLS settings
+//   204: No content
+//   401: Unauthorized
+func tlsComplianceReport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	contexts := permission.ContextsForPermission(t, permission.PermAppReadTlsPolicy)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	filter := &app.Filter{
+		Pool:      r.URL.Query().Get("pool"),
+		TeamOwner: r.URL.Query().Get("teamOwner"),
+	}
+	entries, err := app.TLSComplianceReport(appFilterByContext(contexts, filter))
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppTLSPolicySet(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("minVersion=1.2&cipher=TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256&hsts.maxAge=31536000&hsts.includeSubdomains=true")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/tls-policy", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TLSPolicy, check.DeepEquals, &router.TLSPolicy{
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		HSTS:         &router.HSTSPolicy{MaxAge: 31536000, IncludeSubdomains: true},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.tls-policy",
+		StartCustomData: []map[string]interface{}{
+			{"name": "minVersion", "value": "1.2"},
+			{"name": ":app", "value": "myapp"},
+		},
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("GET", "/1.6/apps/myapp/tls-policy", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result appTLSPolicy
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Source, check.Equals, app.TLSPolicySourceApp)
+	c.Assert(result.Weaknesses, check.HasLen, 0)
+}
+
+func (s *S) TestAppTLSPolicySetInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("minVersion=1.2&cipher=NOT_A_CIPHER")
+	request, err := http.NewRequest("PUT", "/1.6/apps/myapp/tls-policy", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "Unknown cipher suite \"NOT_A_CIPHER\"\n")
+}
+
+func (s *S) TestPoolTLSPolicySet(c *check.C) {
+	body := strings.NewReader("minVersion=1.3&hsts.maxAge=31536000")
+	request, err := http.NewRequest("PUT", "/1.6/pools/test1/tls-policy", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	p, err := pool.GetPoolByName("test1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.TLSPolicy, check.DeepEquals, &router.TLSPolicy{MinVersion: "1.3", HSTS: &router.HSTSPolicy{MaxAge: 31536000}})
+}
+
+func (s *S) TestPoolTLSPolicySetPoolNotFound(c *check.C) {
+	body := strings.NewReader("minVersion=1.3")
+	request, err := http.NewRequest("PUT", "/1.6/pools/unknown/tls-policy", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestTLSComplianceReport(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/tls-policy/report", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var entries []app.TLSComplianceEntry
+	err = json.NewDecoder(recorder.Body).Decode(&entries)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].App, check.Equals, "myapp")
+	c.Assert(entries[0].Weaknesses, check.DeepEquals, []string{"no TLS policy"})
+}
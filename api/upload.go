@@ -0,0 +1,180 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/app/upload"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+)
+
+func uploadSessionFromRequest(r *http.Request, t auth.Token) (*upload.Session, error) {
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return nil, err
+	}
+	allowed := permission.Check(t, permission.PermAppDeployUpload, contextsForApp(&a)...)
+	if !allowed {
+		return nil, permission.ErrUnauthorized
+	}
+	session, err := upload.GetSession(r.URL.Query().Get(":id"))
+	if err == nil && session.App != a.Name {
+		err = upload.ErrSessionNotFound
+	}
+	if err == upload.ErrSessionNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return session, err
+}
+
+func writeUploadSession(w http.ResponseWriter, session *upload.Session, status int) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(session.Received, 10))
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(session)
+}
+
+// title: create deploy upload
+// path: /apps/{appname}/deploy/uploads
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Upload session created
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func createUploadSession(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get(":appname")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppDeployUpload, contextsForApp(&a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	size, err := strconv.ParseInt(r.FormValue("size"), 10, 64)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: upload.ErrInvalidSize.Error()}
+	}
+	session, err := upload.NewSession(a.Name, t.GetUserName(), size, r.FormValue("checksum"))
+	if err == upload.ErrInvalidSize || err == upload.ErrInvalidChecksum {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	return writeUploadSession(w, session, http.StatusCreated)
+}
+
+// title: deploy upload info
+// path: /apps/{appname}/deploy/uploads/{id}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or upload session not found
+func uploadSessionInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	session, err := uploadSessionFromRequest(r, t)
+	if err != nil {
+		return err
+	}
+	return writeUploadSession(w, session, http.StatusOK)
+}
+
+// title: upload deploy chunk
+// path: /apps/{appname}/deploy/uploads/{id}
+// method: PUT
+// consume: application/octet-stream
+// produce: application/json
+// responses:
+//   200: Chunk received
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App or upload session not found
+//   409: Invalid offset
+//   413: Chunk too large
+func uploadChunk(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	session, err := uploadSessionFromRequest(r, t)
+	if err != nil {
+		return err
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Upload-Offset header must be a number"}
+	}
+	maxSize := upload.MaxChunkSize()
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		return err
+	}
+	err = session.AddChunk(offset, data, r.Header.Get("Upload-Checksum"))
+	if err != nil {
+		if mismatch, ok := err.(upload.ErrOffsetMismatch); ok {
+			w.Header().Set("Upload-Offset", strconv.FormatInt(mismatch.Offset, 10))
+			return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+		}
+		switch err {
+		case upload.ErrChunkTooLarge:
+			return &errors.HTTP{Code: http.StatusRequestEntityTooLarge, Message: err.Error()}
+		case upload.ErrChunkOverflow, upload.ErrInvalidChecksum, upload.ErrChecksumMismatch:
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	return writeUploadSession(w, session, http.StatusOK)
+}
+
+// title: remove deploy upload
+// path: /apps/{appname}/deploy/uploads/{id}
+// method: DELETE
+// responses:
+//   200: Upload session removed
+//   401: Unauthorized
+//   404: App or upload session not found
+func removeUploadSession(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	session, err := uploadSessionFromRequest(r, t)
+	if err != nil {
+		return err
+	}
+	err = session.Remove()
+	if err == upload.ErrSessionNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// uploadedFile returns the data of a completed upload session to be used by
+// an upload deploy.
+func uploadedFile(appName, id string) (io.ReadCloser, int64, error) {
+	session, err := upload.GetSession(id)
+	if err == nil && session.App != appName {
+		err = upload.ErrSessionNotFound
+	}
+	if err == upload.ErrSessionNotFound {
+		return nil, 0, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	file, err := session.Open()
+	if err == upload.ErrIncomplete {
+		return nil, 0, &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return file, session.Size, nil
+}
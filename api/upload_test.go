@@ -0,0 +1,160 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/upload"
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *DeploySuite) createUploadApp(c *check.C, name string) {
+	user, _ := s.token.User()
+	a := app.App{Name: name, Platform: "python", Router: "fake", TeamOwner: s.team.Name}
+	err := app.CreateApp(&a, user)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *DeploySuite) uploadRequest(c *check.C, method, url string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(method, url, bytes.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *DeploySuite) TestChunkedUploadDeploy(c *check.C) {
+	var received []byte
+	s.builder.OnBuild = func(p provision.BuilderDeploy, a provision.App, evt *event.Event, opts *builder.BuildOpts) (string, error) {
+		var err error
+		received, err = ioutil.ReadAll(opts.ArchiveFile)
+		c.Assert(err, check.IsNil)
+		c.Assert(opts.ArchiveSize, check.Equals, int64(len(received)))
+		return "tsuruteam/app-myapp:mytag", nil
+	}
+	s.createUploadApp(c, "myapp")
+	data := []byte("a large deploy artifact")
+	form := url.Values{"size": {fmt.Sprint(len(data))}, "checksum": {sha256Hex(data)}}
+	recorder := s.uploadRequest(c, "POST", "/apps/myapp/deploy/uploads", []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var session upload.Session
+	err := json.Unmarshal(recorder.Body.Bytes(), &session)
+	c.Assert(err, check.IsNil)
+	sessionURL := "/apps/myapp/deploy/uploads/" + session.ID.Hex()
+	recorder = s.uploadRequest(c, "PUT", sessionURL, data[:10], map[string]string{
+		"Upload-Offset":   "0",
+		"Upload-Checksum": sha256Hex(data[:10]),
+	})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Upload-Offset"), check.Equals, "10")
+	recorder = s.uploadRequest(c, "GET", sessionURL, nil, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Upload-Offset"), check.Equals, "10")
+	recorder = s.uploadRequest(c, "PUT", sessionURL, data[10:], map[string]string{
+		"Upload-Offset":   "10",
+		"Upload-Checksum": sha256Hex(data[10:]),
+	})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	form = url.Values{"upload": {session.ID.Hex()}}
+	recorder = s.uploadRequest(c, "POST", "/apps/myapp/deploy", []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "Builder deploy called\nOK\n")
+	c.Assert(string(received), check.Equals, string(data))
+}
+
+func (s *DeploySuite) TestChunkedUploadInvalidOffset(c *check.C) {
+	s.createUploadApp(c, "myapp")
+	data := []byte("a large deploy artifact")
+	session, err := upload.NewSession("myapp", s.token.GetUserName(), int64(len(data)), sha256Hex(data))
+	c.Assert(err, check.IsNil)
+	recorder := s.uploadRequest(c, "PUT", "/apps/myapp/deploy/uploads/"+session.ID.Hex(), data[5:], map[string]string{
+		"Upload-Offset":   "5",
+		"Upload-Checksum": sha256Hex(data[5:]),
+	})
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Header().Get("Upload-Offset"), check.Equals, "0")
+}
+
+func (s *DeploySuite) TestChunkedUploadChecksumMismatch(c *check.C) {
+	s.createUploadApp(c, "myapp")
+	data := []byte("a large deploy artifact")
+	session, err := upload.NewSession("myapp", s.token.GetUserName(), int64(len(data)), sha256Hex(data))
+	c.Assert(err, check.IsNil)
+	recorder := s.uploadRequest(c, "PUT", "/apps/myapp/deploy/uploads/"+session.ID.Hex(), data[:5], map[string]string{
+		"Upload-Offset":   "0",
+		"Upload-Checksum": sha256Hex([]byte("other")),
+	})
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, upload.ErrChecksumMismatch.Error()+"\n")
+}
+
+func (s *DeploySuite) TestChunkedUploadSessionFromOtherApp(c *check.C) {
+	s.createUploadApp(c, "myapp")
+	s.createUploadApp(c, "otherapp")
+	data := []byte("a large deploy artifact")
+	session, err := upload.NewSession("otherapp", s.token.GetUserName(), int64(len(data)), sha256Hex(data))
+	c.Assert(err, check.IsNil)
+	recorder := s.uploadRequest(c, "GET", "/apps/myapp/deploy/uploads/"+session.ID.Hex(), nil, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	form := url.Values{"upload": {session.ID.Hex()}}
+	recorder = s.uploadRequest(c, "POST", "/apps/myapp/deploy", []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployIncompleteUpload(c *check.C) {
+	s.createUploadApp(c, "myapp")
+	data := []byte("a large deploy artifact")
+	session, err := upload.NewSession("myapp", s.token.GetUserName(), int64(len(data)), sha256Hex(data))
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(url.Values{"upload": {session.ID.Hex()}}.Encode())
+	request, err := http.NewRequest("POST", "/apps/myapp/deploy", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, upload.ErrIncomplete.Error()+"\n")
+}
+
+func (s *DeploySuite) TestRemoveUploadSession(c *check.C) {
+	s.createUploadApp(c, "myapp")
+	data := []byte("a large deploy artifact")
+	session, err := upload.NewSession("myapp", s.token.GetUserName(), int64(len(data)), sha256Hex(data))
+	c.Assert(err, check.IsNil)
+	recorder := s.uploadRequest(c, "DELETE", "/apps/myapp/deploy/uploads/"+session.ID.Hex(), nil, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = upload.GetSession(session.ID.Hex())
+	c.Assert(err, check.Equals, upload.ErrSessionNotFound)
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app/usage"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+)
+
+func usageFilterByContext(contexts []permission.PermissionContext, filter *usage.Filter) *usage.Filter {
+contextsLoop:
+	for _, c := range contexts {
+		switch c.CtxType {
+		case permission.CtxGlobal:
+			filter.Extra = nil
+			break contextsLoop
+		case permission.CtxTeam:
+			filter.ExtraIn("team", c.Value)
+		case permission.CtxApp:
+			filter.ExtraIn("app", c.Value)
+		case permission.CtxPool:
+			filter.ExtraIn("pool", c.Value)
+		}
+	}
+	return filter
+}
+
+// title: usage report
+// path: /usage
+// method: GET
+// produce: application/json, text/csv
+// responses:
+//   200: Usage report
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+func usageReport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	query := r.URL.Query()
+	filter := &usage.Filter{
+		Month: query.Get("month"),
+		Team:  query.Get("team"),
+		App:   query.Get("app"),
+	}
+	if filter.Month == "" {
+		filter.Month = time.Now().UTC().Format(usage.MonthLayout)
+	} else if _, err := time.Parse(usage.MonthLayout, filter.Month); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid month, it must be in the format YYYY-MM"}
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid format, possible values are json and csv"}
+	}
+	groupBy := query.Get("groupBy")
+	if groupBy != "" && groupBy != "app" && groupBy != "team" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid groupBy, possible values are app and team"}
+	}
+	contexts := permission.ContextsForPermission(t, permission.PermAppReadUsage)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	records, err := usage.Report(*usageFilterByContext(contexts, filter))
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	if groupBy == "team" {
+		records = usage.ByTeam(records)
+	}
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=usage-"+filter.Month+".csv")
+		return usage.WriteCSV(w, records)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(records)
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app/usage"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) insertUsageRecords(c *check.C) {
+	err := s.conn.Collection("app_usage").Insert(
+		usage.Record{App: "app1", Month: "2018-05", Team: "team1", Pool: "pool1", Plan: "small", UnitHours: 10, MemoryGBHours: 5, Deploys: 2},
+		usage.Record{App: "app2", Month: "2018-05", Team: "team2", Pool: "pool1", Plan: "small", UnitHours: 4, MemoryGBHours: 2, Deploys: 1},
+		usage.Record{App: "app3", Month: "2018-05", Team: "team2", Pool: "pool1", Plan: "small", UnitHours: 6, MemoryGBHours: 3, Deploys: 1},
+		usage.Record{App: "app1", Month: "2018-04", Team: "team1", Pool: "pool1", Plan: "small", UnitHours: 1},
+	)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestUsageReport(c *check.C) {
+	s.insertUsageRecords(c)
+	request, err := http.NewRequest("GET", "/1.6/usage?month=2018-05&groupBy=team", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var records []usage.Record
+	err = json.NewDecoder(recorder.Body).Decode(&records)
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 2)
+	c.Assert(records[0].Team, check.Equals, "team1")
+	c.Assert(records[0].UnitHours, check.Equals, float64(10))
+	c.Assert(records[1].Team, check.Equals, "team2")
+	c.Assert(records[1].UnitHours, check.Equals, float64(10))
+	c.Assert(records[1].Deploys, check.Equals, 2)
+}
+
+func (s *S) TestUsageReportCSVFilteredByPermission(c *check.C) {
+	s.insertUsageRecords(c)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "accountant", permission.Permission{
+		Scheme:  permission.PermAppReadUsage,
+		Context: permission.Context(permission.CtxTeam, "team1"),
+	})
+	request, err := http.NewRequest("GET", "/1.6/usage?month=2018-05&format=csv", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/csv")
+	c.Assert(recorder.Body.String(), check.Equals, `month,team,app,pool,plan,unit_hours,memory_gb_hours,deploys
+2018-05,team1,app1,pool1,small,10.00,5.00,2
+`)
+}
+
+func (s *S) TestUsageReportNoContent(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.6/usage?month=2018-05", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestUsageReportInvalidMonth(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.6/usage?month=May", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
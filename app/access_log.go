@@ -0,0 +1,60 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+)
+
+// AccessLogSource is the source of the app logs holding the requests logged
+// by routers.
+const AccessLogSource = "router"
+
+// SetAccessLog sets the access log settings of the app and pushes them to
+// the routers of the app able to log requests. A nil config stops the
+// logging.
+func (app *App) SetAccessLog(config *router.AccessLogConfig) error {
+	var update bson.M
+	if config == nil {
+		update = bson.M{"$unset": bson.M{"accesslog": ""}}
+	} else {
+		err := config.Validate()
+		if err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"accesslog": config}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.AccessLog = config
+	multi := tsuruErrors.NewMultiError()
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		logRouter, ok := r.(router.AccessLogRouter)
+		if !ok || !router.Supports(r, router.CapabilityAccessLog) {
+			continue
+		}
+		err = logRouter.SetAccessLog(app, config)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to set access log in router %q", appRouter.Name))
+		}
+	}
+	return multi.ToError()
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetAccessLog(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-accesslog"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	config := &router.AccessLogConfig{Enabled: true, SampleRate: 0.1, Fields: []string{"path", "status"}}
+	err = a.SetAccessLog(config)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.AccessLogRouter.Configs["myapp"], check.DeepEquals, *config)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AccessLog, check.DeepEquals, config)
+	err = a.SetAccessLog(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.AccessLogRouter.Configs, check.HasLen, 0)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AccessLog, check.IsNil)
+}
+
+func (s *S) TestSetAccessLogInvalid(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-accesslog"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetAccessLog(&router.AccessLogConfig{Enabled: true, SampleRate: 2})
+	c.Assert(err, check.ErrorMatches, "Invalid sample rate, it must be greater than 0 and at most 1")
+	c.Assert(routertest.AccessLogRouter.Configs, check.HasLen, 0)
+}
+
+func (s *S) TestAddRouterPushesAccessLog(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	config := &router.AccessLogConfig{Enabled: true, SampleRate: 1}
+	err = a.SetAccessLog(config)
+	c.Assert(err, check.IsNil)
+	err = a.AddRouter(appTypes.AppRouter{Name: "fake-accesslog"})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.AccessLogRouter.Configs["myapp"], check.DeepEquals, *config)
+}
+
+func (s *S) TestAddLogs(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	date := time.Date(2018, 5, 10, 12, 0, 0, 0, time.UTC)
+	err = a.AddLogs([]Applog{
+		{Date: date, Message: "method=GET", Source: AccessLogSource, Unit: "r1"},
+	})
+	c.Assert(err, check.IsNil)
+	logs, err := a.LastLogs(10, Applog{Source: AccessLogSource})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Assert(logs[0].Message, check.Equals, "method=GET")
+	c.Assert(logs[0].Date.Equal(date), check.Equals, true)
+	c.Assert(logs[0].AppName, check.Equals, "myapp")
+}
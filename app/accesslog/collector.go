@@ -0,0 +1,179 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package accesslog collects the requests logged by routers into the logs of
+// the apps, using the router log source.
+package accesslog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/router"
+)
+
+const defaultCollectInterval = time.Minute
+
+func collectInterval() time.Duration {
+	seconds, _ := config.GetInt("router-access-logs:collect-interval")
+	if seconds <= 0 {
+		return defaultCollectInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Initialize starts collecting access logs from routers supporting it.
+func Initialize() error {
+	c := &collector{once: &sync.Once{}}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type collector struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (c *collector) start() {
+	c.once.Do(func() {
+		c.stopCh = make(chan struct{})
+		go c.spin()
+	})
+}
+
+func (c *collector) Shutdown(ctx context.Context) error {
+	if c.stopCh == nil {
+		return nil
+	}
+	c.stopCh <- struct{}{}
+	c.stopCh = nil
+	c.once = &sync.Once{}
+	return nil
+}
+
+func (c *collector) spin() {
+	for {
+		err := collect(time.Now().UTC())
+		if err != nil {
+			log.Errorf("[router access logs] errors collecting access logs: %v", err)
+		}
+		select {
+		case <-c.stopCh:
+			return
+		case <-time.After(collectInterval()):
+		}
+	}
+}
+
+// cursor holds the time of the last entry collected for an app in a router.
+type cursor struct {
+	ID   string `bson:"_id"`
+	Time time.Time
+}
+
+func cursorID(appName, routerName string) string {
+	return appName + "/" + routerName
+}
+
+func lastCollected(appName, routerName string) (time.Time, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+	var c cursor
+	err = conn.Collection("router_access_log_cursors").FindId(cursorID(appName, routerName)).One(&c)
+	if err == mgo.ErrNotFound {
+		return time.Time{}, nil
+	}
+	return c.Time, err
+}
+
+func setLastCollected(appName, routerName string, t time.Time) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Collection("router_access_log_cursors").UpsertId(cursorID(appName, routerName), bson.M{"$set": bson.M{"time": t}})
+	return err
+}
+
+func collect(now time.Time) error {
+	apps, err := app.List(nil)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range apps {
+		a := &apps[i]
+		if a.AccessLog == nil || !a.AccessLog.Enabled {
+			continue
+		}
+		for _, appRouter := range a.GetRouters() {
+			err = collectApp(a, appRouter.Name, now)
+			if err != nil {
+				multi.Add(errors.Wrapf(err, "unable to collect access logs for app %q from router %q", a.Name, appRouter.Name))
+			}
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+func collectApp(a *app.App, routerName string, now time.Time) error {
+	r, err := router.Get(routerName)
+	if err != nil {
+		return err
+	}
+	logRouter, ok := r.(router.AccessLogRouter)
+	if !ok || !router.Supports(r, router.CapabilityAccessLog) {
+		return nil
+	}
+	since, err := lastCollected(a.Name, routerName)
+	if err != nil {
+		return err
+	}
+	if since.IsZero() {
+		// Logs are not backfilled, the first collection only sets the
+		// cursor.
+		return setLastCollected(a.Name, routerName, now)
+	}
+	entries, err := logRouter.AccessLogs(a.Name, since)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	fields := a.AccessLog.LoggedFields()
+	logs := make([]app.Applog, len(entries))
+	for i, entry := range entries {
+		logs[i] = app.Applog{
+			Date:    entry.Time.UTC(),
+			Message: entry.Format(fields),
+			Source:  app.AccessLogSource,
+			Unit:    routerName,
+			Type:    app.LogTypeRouter,
+		}
+	}
+	err = a.AddLogs(logs)
+	if err != nil {
+		return err
+	}
+	return setLastCollected(a.Name, routerName, entries[len(entries)-1].Time)
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accesslog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCollectorStartNothingToDo(c *check.C) {
+	col := &collector{once: &sync.Once{}}
+	col.start()
+	err := col.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) appLogs(c *check.C, appName string) []app.Applog {
+	conn, err := db.LogConn()
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	var logs []app.Applog
+	err = conn.Logs(appName).Find(nil).Sort("date").All(&logs)
+	c.Assert(err, check.IsNil)
+	return logs
+}
+
+func (s *S) TestCollect(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team, Router: "fake-accesslog"}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetAccessLog(&router.AccessLogConfig{Enabled: true, SampleRate: 1, Fields: []string{"method", "path", "status"}})
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	routertest.AccessLogRouter.Entries[a.Name] = []router.AccessLogEntry{
+		{Time: now.Add(-time.Minute), Fields: map[string]string{"method": "GET", "path": "/old", "status": "200"}},
+	}
+	err = collect(now)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.appLogs(c, a.Name), check.HasLen, 0)
+	routertest.AccessLogRouter.Entries[a.Name] = append(routertest.AccessLogRouter.Entries[a.Name],
+		router.AccessLogEntry{Time: now.Add(time.Second), Fields: map[string]string{"method": "GET", "path": "/", "status": "200"}},
+		router.AccessLogEntry{Time: now.Add(2 * time.Second), Fields: map[string]string{"method": "POST", "path": "/users", "status": "201"}},
+	)
+	err = collect(now.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	err = collect(now.Add(2 * time.Minute))
+	c.Assert(err, check.IsNil)
+	logs := s.appLogs(c, a.Name)
+	c.Assert(logs, check.HasLen, 2)
+	c.Assert(logs[0].Message, check.Equals, "method=GET path=/ status=200")
+	c.Assert(logs[0].Source, check.Equals, app.AccessLogSource)
+	c.Assert(logs[0].Unit, check.Equals, "fake-accesslog")
+	c.Assert(logs[0].Date.Equal(now.Add(time.Second)), check.Equals, true)
+	c.Assert(logs[1].Message, check.Equals, "method=POST path=/users status=201")
+}
+
+func (s *S) TestCollectIgnoresDisabledApps(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team, Router: "fake-accesslog"}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	err = collect(now)
+	c.Assert(err, check.IsNil)
+	routertest.AccessLogRouter.Entries[a.Name] = []router.AccessLogEntry{
+		{Time: now.Add(time.Second), Fields: map[string]string{"method": "GET"}},
+	}
+	err = collect(now.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(s.appLogs(c, a.Name), check.HasLen, 0)
+}
@@ -59,6 +59,11 @@ var reserveUserApp = action.Action{
 		if err := auth.ReserveApp(usr); err != nil {
 			return nil, err
 		}
+		// The quota may have been changed by concurrent reservations, so
+		// the usage after this one is read back.
+		if usr, err = auth.GetUserByEmail(user.Email); err == nil {
+			notifyUserQuotaThreshold(usr, usr.Quota.InUse-1, usr.Quota.InUse)
+		}
 		return map[string]string{"app": app.Name, "user": user.Email}, nil
 	},
 	Backward: func(ctx action.BWContext) {
@@ -241,6 +246,10 @@ var addRouterBackend = action.Action{
 			if err != nil {
 				return nil, err
 			}
+			err = router.CheckProtocolsSupport(r, app.Protocols)
+			if err != nil {
+				return nil, protocolError(err)
+			}
 			if optsRouter, ok := r.(router.OptsRouter); ok {
 				err = optsRouter.AddBackendOpts(app, appRouter.Opts)
 			} else {
@@ -249,6 +258,10 @@ var addRouterBackend = action.Action{
 			if err != nil {
 				return nil, err
 			}
+			err = app.configureRouterBackend(r)
+			if err != nil {
+				return nil, err
+			}
 		}
 		return app, nil
 	},
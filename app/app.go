@@ -21,6 +21,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tsuru/tsuru/action"
 	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/buildlog"
+	"github.com/tsuru/tsuru/app/envgroup"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/builder"
@@ -55,6 +57,7 @@ var (
 	ErrNoAccess          = errors.New("team does not have access to this app")
 	ErrCannotOrphanApp   = errors.New("cannot revoke access from this team, as it's the unique team with access to the app")
 	ErrDisabledPlatform  = errors.New("Disabled Platform, only admin users can create applications with the platform")
+	ErrNoTLSRouter       = errors.New("no router with tls support")
 )
 
 var (
@@ -66,6 +69,35 @@ var (
 
 func init() {
 	prometheus.MustRegister(counterNodesNotFound)
+	event.RegisterErrorContext(event.TargetTypeApp, appErrorContext)
+	for _, kind := range []*permission.PermissionScheme{permission.PermAppDeploy, permission.PermAppBuild} {
+		event.RegisterCustomDataSchema(event.CustomDataSchema{
+			Kind:       kind.FullName(),
+			Version:    1,
+			TargetType: event.TargetTypeApp,
+			Start:      DeployOptions{},
+			End:        map[string]string{},
+		})
+	}
+}
+
+// appErrorContext returns the ownership information of the app, which is
+// stored in its failed events.
+func appErrorContext(target event.Target) (interface{}, error) {
+	a, err := GetByName(target.Value)
+	if err != nil {
+		if err == ErrAppNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return map[string]interface{}{
+		"description":   a.Description,
+		"teamowner":     a.TeamOwner,
+		"oncall":        a.Ownership.OnCall,
+		"runbookURL":    a.Ownership.RunbookURL,
+		"repositoryURL": a.Ownership.RepositoryURL,
+	}, nil
 }
 
 const (
@@ -150,10 +182,31 @@ type App struct {
 	Tags           []string
 	Error          string
 	Routers        []appTypes.AppRouter
+	Ownership      appTypes.Ownership
+	DeployTimeouts map[string]int
+	TLSPolicy      *router.TLSPolicy       `bson:",omitempty"`
+	Protocols      map[string]string       `bson:",omitempty"`
+	StickySession  *router.StickySession   `bson:",omitempty"`
+	HeaderPolicy   *router.HeaderPolicy    `bson:",omitempty"`
+	Mirror         *router.Mirror          `bson:",omitempty"`
+	AccessLog      *router.AccessLogConfig `bson:",omitempty"`
+	ErrorPages     []router.ErrorPage      `bson:",omitempty"`
+
+	DeployVerification *DeployVerification `bson:",omitempty"`
+	MinZones           int                 `bson:",omitempty"`
 
 	quota.Quota
 	builder     builder.Builder
 	provisioner provision.Provisioner
+	inherited   *inheritedEnvs
+}
+
+// inheritedEnvs holds the env vars the app inherits from its pool and from
+// the env groups it's subscribed to, so they're loaded once per App value.
+type inheritedEnvs struct {
+	pool      string
+	poolEnvs  []bind.EnvVar
+	groupEnvs []bind.EnvVar
 }
 
 var (
@@ -225,6 +278,10 @@ func (app *App) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		errMsgs = append(errMsgs, fmt.Sprintf("unable to list app units: %+v", err))
 	}
+	err = app.fillUnitsPlacement(units)
+	if err != nil {
+		errMsgs = append(errMsgs, fmt.Sprintf("unable to get app units placement: %+v", err))
+	}
 	result["repository"] = repo.ReadWriteURL
 	plan := map[string]interface{}{
 		"name":     app.Plan.Name,
@@ -246,12 +303,41 @@ func (app *App) MarshalJSON() ([]byte, error) {
 	result["owner"] = app.Owner
 	result["pool"] = app.Pool
 	result["description"] = app.Description
+	result["ownership"] = app.Ownership
 	result["deploys"] = app.Deploys
 	result["teamowner"] = app.TeamOwner
 	result["plan"] = plan
 	result["lock"] = app.Lock
 	result["tags"] = app.Tags
 	result["routers"] = routers
+	if app.TLSPolicy != nil {
+		result["tlsPolicy"] = app.TLSPolicy
+	}
+	if len(app.Protocols) > 0 {
+		result["protocols"] = app.Protocols
+	}
+	if sticky := app.EffectiveStickySession(); sticky != nil {
+		result["stickySession"] = sticky
+	}
+	if app.HeaderPolicy != nil {
+		result["headerPolicy"] = app.HeaderPolicy
+	}
+	if app.Mirror != nil {
+		result["mirror"] = app.Mirror
+	}
+	if app.AccessLog != nil {
+		result["accessLog"] = app.AccessLog
+	}
+	if app.MinZones > 0 {
+		result["minZones"] = app.MinZones
+	}
+	if len(app.ErrorPages) > 0 {
+		codes := make([]int, len(app.ErrorPages))
+		for i, page := range app.ErrorPages {
+			codes[i] = page.StatusCode
+		}
+		result["errorPages"] = codes
+	}
 	if len(errMsgs) > 0 {
 		result["error"] = strings.Join(errMsgs, "\n")
 	}
@@ -266,6 +352,7 @@ type Applog struct {
 	Source  string
 	AppName string
 	Unit    string
+	Type    string
 }
 
 type ErrAppNotLocked struct {
@@ -473,6 +560,15 @@ func (app *App) Update(updateData App, w io.Writer) (err error) {
 	if description != "" {
 		app.Description = description
 	}
+	if updateData.Ownership.OnCall != "" {
+		app.Ownership.OnCall = updateData.Ownership.OnCall
+	}
+	if updateData.Ownership.RunbookURL != "" {
+		app.Ownership.RunbookURL = updateData.Ownership.RunbookURL
+	}
+	if updateData.Ownership.RepositoryURL != "" {
+		app.Ownership.RepositoryURL = updateData.Ownership.RepositoryURL
+	}
 	if poolName != "" {
 		app.Pool = poolName
 		app.provisioner = nil
@@ -606,6 +702,37 @@ func (app *App) unbindVolumes() error {
 	return nil
 }
 
+// DeleteDry reports what deleting the app would remove, without removing
+// anything.
+func DeleteDry(app *App) (*provision.DryRunReport, error) {
+	isSwapped, swappedWith, err := router.IsSwapped(app.GetName())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to check if app is swapped")
+	}
+	if isSwapped {
+		return nil, errors.Errorf("application is swapped with %q, cannot remove it", swappedWith)
+	}
+	units, err := app.Units()
+	if err != nil {
+		return nil, err
+	}
+	report := provision.DryRunReport{
+		Units:  units,
+		CNames: app.CName,
+	}
+	volumes, err := volume.ListByApp(app.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list volumes for unbind")
+	}
+	for _, v := range volumes {
+		report.Volumes = append(report.Volumes, v.Name)
+	}
+	for _, appRouter := range app.GetRouters() {
+		report.Routers = append(report.Routers, appRouter.Name)
+	}
+	return &report, nil
+}
+
 // Delete deletes an app.
 func Delete(app *App, evt *event.Event, requestID string) error {
 	w := evt
@@ -666,10 +793,30 @@ func Delete(app *App, evt *event.Event, requestID string) error {
 	if err != nil {
 		log.Errorf("failed to remove image names from storage for app %s: %s", appName, err)
 	}
+	err = buildlog.RemoveAppLogs(appName)
+	if err != nil {
+		log.Errorf("failed to remove build logs for app %s: %s", appName, err)
+	}
+	err = removeDeploySnapshots(appName)
+	if err != nil {
+		log.Errorf("failed to remove deploy snapshots for app %s: %s", appName, err)
+	}
+	err = removeBuildSecrets(appName)
+	if err != nil {
+		log.Errorf("failed to remove build secrets for app %s: %s", appName, err)
+	}
+	err = envgroup.UnsubscribeAll(appName)
+	if err != nil {
+		log.Errorf("failed to unsubscribe app %s from env groups: %s", appName, err)
+	}
 	err = app.unbind(evt, requestID)
 	if err != nil {
 		logErr("Unable to unbind app", err)
 	}
+	err = stopMirrorsTo(appName)
+	if err != nil {
+		logErr("Unable to stop traffic mirrored to app", err)
+	}
 	routers := app.GetRouters()
 	for _, appRouter := range routers {
 		var r router.Router
@@ -783,12 +930,15 @@ func (app *App) AddUnits(n uint, process string, w io.Writer) error {
 			return errors.New("Cannot add units to an app that has stopped or sleeping units")
 		}
 	}
+	before := unitCounts(units)
+	evtWriter := w
 	w = app.withLogWriter(w)
 	err = action.NewPipeline(
 		&reserveUnitsToAdd,
 		&provisionAddUnits,
 	).Execute(app, n, w, process)
 	rebuild.RoutesRebuildOrEnqueue(app.Name)
+	app.recordUnitCountChanges(before, evtWriter)
 	return err
 }
 
@@ -802,9 +952,15 @@ func (app *App) RemoveUnits(n uint, process string, w io.Writer) error {
 	if err != nil {
 		return err
 	}
+	before, err := app.UnitCounts()
+	if err != nil {
+		return err
+	}
+	evtWriter := w
 	w = app.withLogWriter(w)
 	err = prov.RemoveUnits(app, n, process, w)
 	rebuild.RoutesRebuildOrEnqueue(app.Name)
+	app.recordUnitCountChanges(before, evtWriter)
 	if err != nil {
 		return err
 	}
@@ -815,6 +971,24 @@ func (app *App) RemoveUnits(n uint, process string, w io.Writer) error {
 	return app.SetQuotaInUse(len(units))
 }
 
+// RemoveUnitsDry reports the units RemoveUnits would remove, without
+// removing them.
+func (app *App) RemoveUnitsDry(n uint, process string) (*provision.DryRunReport, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	dryProv, ok := prov.(provision.UnitsRemovalDryRunProvisioner)
+	if !ok {
+		return nil, errors.Errorf("dry run is not supported by the %s provisioner", prov.GetName())
+	}
+	units, err := dryProv.RemoveUnitsDry(app, n, process)
+	if err != nil {
+		return nil, err
+	}
+	return &provision.DryRunReport{Units: units}, nil
+}
+
 // SetUnitStatus changes the status of the given unit.
 func (app *App) SetUnitStatus(unitName string, status provision.Status) error {
 	units, err := app.Units()
@@ -1088,19 +1262,47 @@ func (app *App) validate() error {
 			"starting with a letter."
 		return &tsuruErrors.ValidationError{Message: msg}
 	}
+	err := app.validateOwnership()
+	if err != nil {
+		return err
+	}
 	return app.validatePool()
 }
 
+func (app *App) validateOwnership() error {
+	urls := []struct{ name, value string }{
+		{"runbook", app.Ownership.RunbookURL},
+		{"repository", app.Ownership.RepositoryURL},
+	}
+	for _, u := range urls {
+		if u.value == "" {
+			continue
+		}
+		parsed, err := url.Parse(u.value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			msg := fmt.Sprintf("Invalid %s URL %q, it must be an absolute http or https URL.", u.name, u.value)
+			return &tsuruErrors.ValidationError{Message: msg}
+		}
+	}
+	return nil
+}
+
 func (app *App) validatePool() error {
 	pool, err := pool.GetPoolByName(app.Pool)
 	if err != nil {
 		return err
 	}
-	err = app.validateTeamOwner(pool)
+	return app.ValidatePoolConstraints(pool)
+}
+
+// ValidatePoolConstraints checks whether the app team owner and routers are
+// allowed by the constraints of the given pool.
+func (app *App) ValidatePoolConstraints(p *pool.Pool) error {
+	err := app.validateTeamOwner(p)
 	if err != nil {
 		return err
 	}
-	return pool.ValidateRouters(app.GetRouters())
+	return p.ValidateRouters(app.GetRouters())
 }
 
 func (app *App) validateTeamOwner(p *pool.Pool) error {
@@ -1363,6 +1565,10 @@ func (app *App) SetQuotaInUse(inUse int) error {
 	if err == mgo.ErrNotFound {
 		return ErrAppNotFound
 	}
+	if err == nil {
+		notifyQuotaThreshold(app, app.Quota.InUse, inUse)
+		notifyTeamQuotaThreshold(app, inUse-app.Quota.InUse)
+	}
 	return err
 }
 
@@ -1386,9 +1592,26 @@ func (app *App) GetDeploys() uint {
 	return app.Deploys
 }
 
-// Envs returns a map representing the apps environment variables.
+// Envs returns a map representing the apps environment variables. Env vars
+// defined in the app pool and in the env groups the app is subscribed to are
+// included, unless overridden by the app.
 func (app *App) Envs() map[string]bind.EnvVar {
-	mergedEnvs := make(map[string]bind.EnvVar, len(app.Env)+len(app.ServiceEnvs)+1)
+	if app.inherited == nil || app.inherited.pool != app.Pool {
+		app.inherited = &inheritedEnvs{
+			pool:      app.Pool,
+			poolEnvs:  app.poolEnvs(),
+			groupEnvs: app.envGroupsEnvs(),
+		}
+	}
+	poolEnvs := app.inherited.poolEnvs
+	groupEnvs := app.inherited.groupEnvs
+	mergedEnvs := make(map[string]bind.EnvVar, len(poolEnvs)+len(groupEnvs)+len(app.Env)+len(app.ServiceEnvs)+1)
+	for _, e := range poolEnvs {
+		mergedEnvs[e.Name] = e
+	}
+	for _, e := range groupEnvs {
+		mergedEnvs[e.Name] = e
+	}
 	for _, e := range app.Env {
 		mergedEnvs[e.Name] = e
 	}
@@ -1399,6 +1622,70 @@ func (app *App) Envs() map[string]bind.EnvVar {
 	return mergedEnvs
 }
 
+func (app *App) poolEnvs() []bind.EnvVar {
+	if app.Pool == "" {
+		return nil
+	}
+	p, err := pool.GetPoolByName(app.Pool)
+	if err != nil {
+		if err != pool.ErrPoolNotFound {
+			log.Errorf("unable to get envs from pool %q: %s", app.Pool, err)
+		}
+		return nil
+	}
+	return p.Envs
+}
+
+// envGroupsEnvs returns the env vars of the env groups the app is subscribed
+// to. Groups are sorted by name, so the last group wins on conflicts.
+func (app *App) envGroupsEnvs() []bind.EnvVar {
+	groups, err := envgroup.ListByApp(app.Name)
+	if err != nil {
+		log.Errorf("unable to get envs from env groups of app %q: %s", app.Name, err)
+		return nil
+	}
+	var envs []bind.EnvVar
+	for _, g := range groups {
+		envs = append(envs, g.Envs...)
+	}
+	return envs
+}
+
+// LoadInheritedEnvs loads the env vars inherited from pools and env groups
+// by all the given apps at once, avoiding a lookup per app when Envs is
+// called on each of them.
+func LoadInheritedEnvs(apps []App) error {
+	if len(apps) == 0 {
+		return nil
+	}
+	pools, err := pool.ListAllPools()
+	if err != nil {
+		return err
+	}
+	poolEnvs := make(map[string][]bind.EnvVar, len(pools))
+	for _, p := range pools {
+		poolEnvs[p.Name] = p.Envs
+	}
+	groups, err := envgroup.List(nil)
+	if err != nil {
+		return err
+	}
+	groupEnvs := map[string][]bind.EnvVar{}
+	for _, g := range groups {
+		for _, appName := range g.Apps {
+			groupEnvs[appName] = append(groupEnvs[appName], g.Envs...)
+		}
+	}
+	for i := range apps {
+		apps[i].inherited = &inheritedEnvs{
+			pool:      apps[i].Pool,
+			poolEnvs:  poolEnvs[apps[i].Pool],
+			groupEnvs: groupEnvs[apps[i].Name],
+		}
+	}
+	return nil
+}
+
 // SetEnvs saves a list of environment variables in the app.
 func (app *App) SetEnvs(setEnvs bind.SetEnvArgs) error {
 	if len(setEnvs.Envs) == 0 {
@@ -1592,6 +1879,7 @@ func (app *App) Log(message, source, unit string) error {
 				Source:  source,
 				AppName: app.Name,
 				Unit:    unit,
+				Type:    LogTypeForSource(source),
 			}
 			logs = append(logs, l)
 		}
@@ -1607,9 +1895,41 @@ func (app *App) Log(message, source, unit string) error {
 	return nil
 }
 
+// AddLogs stores the given log entries of the app, keeping their dates.
+func (app *App) AddLogs(logs []Applog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(logs))
+	for i := range logs {
+		logs[i].AppName = app.Name
+		if logs[i].Type == "" {
+			logs[i].Type = LogTypeForSource(logs[i].Source)
+		}
+		docs[i] = logs[i]
+	}
+	conn, err := db.LogConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Logs(app.Name).Insert(docs...)
+}
+
 // LastLogs returns a list of the last `lines` log of the app, matching the
-// fields in the log instance received as an example.
+// fields in the log instance received as an example. Its Type may hold
+// several log types separated by commas.
 func (app *App) LastLogs(lines int, filterLog Applog) ([]Applog, error) {
+	return app.lastLogs(lines, filterLog, false)
+}
+
+// LastLogsForRead is like LastLogs, reading the logs with the read
+// preference of the logs endpoint, which may return stale results.
+func (app *App) LastLogsForRead(lines int, filterLog Applog) ([]Applog, error) {
+	return app.lastLogs(lines, filterLog, true)
+}
+
+func (app *App) lastLogs(lines int, filterLog Applog, useReadPreference bool) ([]Applog, error) {
 	prov, err := app.getProvisioner()
 	if err != nil {
 		return nil, err
@@ -1626,20 +1946,18 @@ func (app *App) LastLogs(lines int, filterLog Applog) ([]Applog, error) {
 			return nil, errors.New(doc)
 		}
 	}
-	conn, err := db.LogConn()
+	var conn *db.LogStorage
+	if useReadPreference {
+		conn, err = db.ReadLogConn("logs")
+	} else {
+		conn, err = db.LogConn()
+	}
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 	logs := []Applog{}
-	q := bson.M{}
-	if filterLog.Source != "" {
-		q["source"] = filterLog.Source
-	}
-	if filterLog.Unit != "" {
-		q["unit"] = filterLog.Unit
-	}
-	err = conn.Logs(app.Name).Find(q).Sort("-$natural").Limit(lines).All(&logs)
+	err = conn.Logs(app.Name).Find(logFilterQuery(filterLog)).Sort("-$natural").Limit(lines).All(&logs)
 	if err != nil {
 		return nil, err
 	}
@@ -1662,6 +1980,15 @@ type Filter struct {
 	Locked      bool
 	Tags        []string
 	Extra       map[string][]string
+	// Text matches whole words in the name, description and tags of the
+	// app, using the text index of the apps collection.
+	Text string
+	// EnvKey matches apps with an env var with the given name.
+	EnvKey string
+	// UseReadPreference lists the apps with the read preference of the apps
+	// endpoint, which may return stale results. It must only be set by
+	// callers that do not act on the listed apps.
+	UseReadPreference bool
 }
 
 func (f *Filter) ExtraIn(name string, value string) {
@@ -1713,6 +2040,17 @@ func (f *Filter) Query() bson.M {
 	if len(tags) > 0 {
 		query["tags"] = bson.M{"$all": tags}
 	}
+	if f.Text != "" {
+		query["$text"] = bson.M{"$search": f.Text}
+	}
+	if f.EnvKey != "" {
+		if validation.ValidateEnvName(f.EnvKey) {
+			query["env."+f.EnvKey] = bson.M{"$exists": true}
+		} else {
+			// Invalid names could point to other fields, so they match no app.
+			query["_id"] = bson.M{"$exists": false}
+		}
+	}
 	return query
 }
 
@@ -1782,7 +2120,13 @@ func Units(apps []App) (map[string]AppUnitsResponse, error) {
 func List(filter *Filter) ([]App, error) {
 	apps := []App{}
 	query := filter.Query()
-	conn, err := db.Conn()
+	var conn *db.Storage
+	var err error
+	if filter != nil && filter.UseReadPreference {
+		conn, err = db.ReadConn("apps")
+	} else {
+		conn, err = db.Conn()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1987,6 +2331,10 @@ func (app *App) AddRouter(appRouter appTypes.AppRouter) error {
 	if err != nil {
 		return err
 	}
+	err = router.CheckProtocolsSupport(r, app.Protocols)
+	if err != nil {
+		return protocolError(err)
+	}
 	if optsRouter, ok := r.(router.OptsRouter); ok {
 		err = optsRouter.AddBackendOpts(app, appRouter.Opts)
 	} else {
@@ -1995,6 +2343,14 @@ func (app *App) AddRouter(appRouter appTypes.AppRouter) error {
 	if err != nil {
 		return err
 	}
+	err = app.configureRouterBackend(r)
+	if err != nil {
+		rollbackErr := r.RemoveBackend(appRouter.Name)
+		if rollbackErr != nil {
+			log.Errorf("unable to remove router backend rolling back add router: %v", rollbackErr)
+		}
+		return err
+	}
 	routers := append(app.GetRouters(), appRouter)
 	err = app.updateRoutersDB(routers)
 	if err != nil {
@@ -2007,6 +2363,61 @@ func (app *App) AddRouter(appRouter appTypes.AppRouter) error {
 	return nil
 }
 
+// configureRouterBackend pushes the settings of the app kept by tsuru, such
+// as its TLS policy and backend protocols, to a newly created backend in the
+// router. Settings the router is unable to handle are skipped.
+func (app *App) configureRouterBackend(r router.Router) error {
+	if policyRouter, ok := r.(router.TLSPolicyRouter); ok {
+		tlsPolicy, _, err := app.EffectiveTLSPolicy()
+		if err != nil {
+			return err
+		}
+		if tlsPolicy != nil {
+			err = policyRouter.SetTLSPolicy(app, tlsPolicy)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if protoRouter, ok := r.(router.ProtocolRouter); ok && len(app.Protocols) > 0 {
+		err := protoRouter.SetBackendProtocols(app, app.Protocols)
+		if err != nil {
+			return err
+		}
+	}
+	if stickyRouter, ok := r.(router.StickySessionRouter); ok && app.StickySession != nil {
+		err := stickyRouter.SetStickySession(app, app.StickySession)
+		if err != nil {
+			return err
+		}
+	}
+	if headerRouter, ok := r.(router.HeaderPolicyRouter); ok && app.HeaderPolicy != nil {
+		err := headerRouter.SetHeaderPolicy(app, app.HeaderPolicy)
+		if err != nil {
+			return err
+		}
+	}
+	if mirrorRouter, ok := r.(router.MirrorRouter); ok && app.Mirror != nil && router.Supports(r, router.CapabilityMirror) {
+		err := mirrorRouter.SetMirror(app, app.Mirror)
+		if err != nil {
+			return err
+		}
+	}
+	if logRouter, ok := r.(router.AccessLogRouter); ok && app.AccessLog != nil && router.Supports(r, router.CapabilityAccessLog) {
+		err := logRouter.SetAccessLog(app, app.AccessLog)
+		if err != nil {
+			return err
+		}
+	}
+	if pageRouter, ok := r.(router.ErrorPageRouter); ok && len(app.ErrorPages) > 0 && router.Supports(r, router.CapabilityErrorPage) {
+		err := pageRouter.SetErrorPages(app, app.ErrorPages)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (app *App) UpdateRouter(appRouter appTypes.AppRouter) error {
 	var existing *appTypes.AppRouter
 	routers := app.GetRouters()
@@ -2199,7 +2610,7 @@ func (app *App) SetCertificate(name, certificate, key string) error {
 		}
 	}
 	if !addedAny {
-		return errors.New("no router with tls support")
+		return ErrNoTLSRouter
 	}
 	return nil
 }
@@ -2226,7 +2637,7 @@ func (app *App) RemoveCertificate(name string) error {
 		}
 	}
 	if !removedAny {
-		return errors.New("no router with tls support")
+		return ErrNoTLSRouter
 	}
 	return nil
 }
@@ -2276,7 +2687,7 @@ func (app *App) GetCertificates() (map[string]map[string]string, error) {
 		allCertificates[appRouter.Name] = certificates
 	}
 	if len(allCertificates) == 0 {
-		return nil, errors.New("no router with tls support")
+		return nil, ErrNoTLSRouter
 	}
 	return allCertificates, nil
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/envgroup"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/errors"
@@ -81,6 +82,10 @@ func (s *S) TestDelete(c *check.C) {
 	c.Assert(err, check.IsNil)
 	err = image.AppendAppImageName(app.Name, "testimage")
 	c.Assert(err, check.IsNil)
+	_, err = envgroup.Create("shared", s.team.Name)
+	c.Assert(err, check.IsNil)
+	err = envgroup.Subscribe("shared", app.Name)
+	c.Assert(err, check.IsNil)
 	evt, err := event.New(&event.Opts{
 		Target:   event.Target{Type: "app", Value: a.Name},
 		Kind:     permission.PermAppDelete,
@@ -107,6 +112,9 @@ func (s *S) TestDelete(c *check.C) {
 	imgs, err := image.ListAppImages(a.Name)
 	c.Assert(err, check.NotNil)
 	c.Assert(imgs, check.HasLen, 0)
+	groups, err := envgroup.ListByApp(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
 }
 
 func (s *S) TestDeleteWithEvents(c *check.C) {
@@ -2380,6 +2388,47 @@ func (s *S) TestLastLogsUnitFilter(c *check.C) {
 	}
 }
 
+func (s *S) TestLastLogsTypeFilter(c *check.C) {
+	app := App{
+		Name:      "app3",
+		Platform:  "vougan",
+		TeamOwner: s.team.Name,
+	}
+	err := CreateApp(&app, s.user)
+	c.Assert(err, check.IsNil)
+	app.Log("web log", "web", "u1")
+	app.Log("system log", "tsuru", "u1")
+	err = app.AddLogs([]Applog{{Date: time.Now(), Message: "router log", Source: AccessLogSource}})
+	c.Assert(err, check.IsNil)
+	err = insertLogs(app.Name, []interface{}{
+		Applog{Date: time.Now(), Message: "old web log", Source: "web", AppName: app.Name},
+		Applog{Date: time.Now(), Message: "old system log", Source: "tsuru", AppName: app.Name},
+	})
+	c.Assert(err, check.IsNil)
+	logs, err := app.LastLogs(10, Applog{Type: LogTypeApp})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Assert(logs[0].Message, check.Equals, "web log")
+	c.Assert(logs[0].Type, check.Equals, LogTypeApp)
+	c.Assert(logs[1].Message, check.Equals, "old web log")
+	logs, err = app.LastLogs(10, Applog{Type: LogTypeApp + "," + LogTypeRouter})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 3)
+	c.Assert(logs[1].Message, check.Equals, "router log")
+	c.Assert(logs[1].Type, check.Equals, LogTypeRouter)
+	logs, err = app.LastLogs(10, Applog{Type: LogTypeSystem, Unit: "u1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Assert(logs[0].Message, check.Equals, "system log")
+}
+
+func (s *S) TestLogTypeForSource(c *check.C) {
+	c.Assert(LogTypeForSource("web"), check.Equals, LogTypeApp)
+	c.Assert(LogTypeForSource("app-run"), check.Equals, LogTypeApp)
+	c.Assert(LogTypeForSource(AccessLogSource), check.Equals, LogTypeRouter)
+	c.Assert(LogTypeForSource("tsuru"), check.Equals, LogTypeSystem)
+}
+
 func (s *S) TestLastLogsEmpty(c *check.C) {
 	app := App{
 		Name:      "app33",
@@ -2459,6 +2508,7 @@ func (s *S) TestAppMarshalJSON(c *check.C) {
 		TeamOwner:   "myteam",
 		Routers:     []appTypes.AppRouter{{Name: "fake", Opts: map[string]string{"opt1": "val1"}}},
 		Tags:        []string{"tag a", "tag b"},
+		Ownership:   appTypes.Ownership{OnCall: "ops@example.com", RunbookURL: "https://wiki.example.com/name"},
 	}
 	err = routertest.FakeRouter.AddBackend(&app)
 	c.Assert(err, check.IsNil)
@@ -2476,6 +2526,7 @@ func (s *S) TestAppMarshalJSON(c *check.C) {
 		"description": "description",
 		"teamowner":   "myteam",
 		"lock":        s.zeroLock,
+		"ownership":   map[string]interface{}{"oncall": "ops@example.com", "runbookURL": "https://wiki.example.com/name"},
 		"plan": map[string]interface{}{
 			"name":     "myplan",
 			"memory":   float64(64),
@@ -2534,6 +2585,7 @@ func (s *S) TestAppMarshalJSONWithoutRepository(c *check.C) {
 		"description": "description",
 		"teamowner":   "myteam",
 		"lock":        s.zeroLock,
+		"ownership":   map[string]interface{}{},
 		"plan": map[string]interface{}{
 			"name":     "myplan",
 			"memory":   float64(64),
@@ -2584,6 +2636,7 @@ func (s *S) TestAppMarshalJSONUnitsError(c *check.C) {
 		"description": "",
 		"teamowner":   "",
 		"lock":        s.zeroLock,
+		"ownership":   map[string]interface{}{},
 		"plan": map[string]interface{}{
 			"name":     "",
 			"memory":   float64(0),
@@ -2763,6 +2816,112 @@ func (s *S) TestEnvs(c *check.C) {
 	c.Assert(env, check.DeepEquals, expected)
 }
 
+func (s *S) TestEnvsWithPoolEnvs(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "envpool"})
+	c.Assert(err, check.IsNil)
+	err = pool.SetEnvs("envpool", []bind.EnvVar{
+		{Name: "REGION", Value: "us-east", Public: true},
+		{Name: "http_proxy", Value: "http://poolproxy:3128/", Public: true},
+	})
+	c.Assert(err, check.IsNil)
+	app := App{
+		Name: "time",
+		Pool: "envpool",
+		Env: map[string]bind.EnvVar{
+			"http_proxy": {
+				Name:   "http_proxy",
+				Value:  "http://theirproxy.com:3128/",
+				Public: true,
+			},
+		},
+	}
+	expected := map[string]bind.EnvVar{
+		"REGION": {
+			Name:   "REGION",
+			Value:  "us-east",
+			Public: true,
+		},
+		"http_proxy": {
+			Name:   "http_proxy",
+			Value:  "http://theirproxy.com:3128/",
+			Public: true,
+		},
+		"TSURU_SERVICES": {
+			Name:  "TSURU_SERVICES",
+			Value: "{}",
+		},
+	}
+	env := app.Envs()
+	c.Assert(env, check.DeepEquals, expected)
+}
+
+func (s *S) TestEnvsWithEnvGroups(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "envpool"})
+	c.Assert(err, check.IsNil)
+	err = pool.SetEnvs("envpool", []bind.EnvVar{
+		{Name: "REGION", Value: "us-east", Public: true},
+		{Name: "LOG_LEVEL", Value: "info", Public: true},
+	})
+	c.Assert(err, check.IsNil)
+	for _, name := range []string{"g1", "g2"} {
+		_, err = envgroup.Create(name, s.team.Name)
+		c.Assert(err, check.IsNil)
+		err = envgroup.Subscribe(name, "time")
+		c.Assert(err, check.IsNil)
+	}
+	err = envgroup.SetEnvs("g1", []bind.EnvVar{
+		{Name: "LOG_LEVEL", Value: "debug", Public: true},
+		{Name: "DATABASE_HOST", Value: "db1"},
+		{Name: "CACHE_HOST", Value: "cache1"},
+	})
+	c.Assert(err, check.IsNil)
+	err = envgroup.SetEnvs("g2", []bind.EnvVar{{Name: "CACHE_HOST", Value: "cache2"}})
+	c.Assert(err, check.IsNil)
+	app := App{
+		Name: "time",
+		Pool: "envpool",
+		Env: map[string]bind.EnvVar{
+			"DATABASE_HOST": {Name: "DATABASE_HOST", Value: "mydb"},
+		},
+	}
+	expected := map[string]bind.EnvVar{
+		"REGION":         {Name: "REGION", Value: "us-east", Public: true},
+		"LOG_LEVEL":      {Name: "LOG_LEVEL", Value: "debug", Public: true},
+		"CACHE_HOST":     {Name: "CACHE_HOST", Value: "cache2"},
+		"DATABASE_HOST":  {Name: "DATABASE_HOST", Value: "mydb"},
+		"TSURU_SERVICES": {Name: "TSURU_SERVICES", Value: "{}"},
+	}
+	env := app.Envs()
+	c.Assert(env, check.DeepEquals, expected)
+}
+
+func (s *S) TestLoadInheritedEnvs(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "envpool"})
+	c.Assert(err, check.IsNil)
+	err = pool.SetEnvs("envpool", []bind.EnvVar{{Name: "REGION", Value: "us-east", Public: true}})
+	c.Assert(err, check.IsNil)
+	_, err = envgroup.Create("g1", s.team.Name)
+	c.Assert(err, check.IsNil)
+	err = envgroup.Subscribe("g1", "time")
+	c.Assert(err, check.IsNil)
+	err = envgroup.SetEnvs("g1", []bind.EnvVar{{Name: "CACHE_HOST", Value: "cache1"}})
+	c.Assert(err, check.IsNil)
+	apps := []App{{Name: "time", Pool: "envpool"}, {Name: "other", Pool: "envpool"}}
+	err = LoadInheritedEnvs(apps)
+	c.Assert(err, check.IsNil)
+	err = pool.SetEnvs("envpool", []bind.EnvVar{{Name: "REGION", Value: "sa-east", Public: true}})
+	c.Assert(err, check.IsNil)
+	c.Assert(apps[0].Envs(), check.DeepEquals, map[string]bind.EnvVar{
+		"REGION":         {Name: "REGION", Value: "us-east", Public: true},
+		"CACHE_HOST":     {Name: "CACHE_HOST", Value: "cache1"},
+		"TSURU_SERVICES": {Name: "TSURU_SERVICES", Value: "{}"},
+	})
+	c.Assert(apps[1].Envs(), check.DeepEquals, map[string]bind.EnvVar{
+		"REGION":         {Name: "REGION", Value: "us-east", Public: true},
+		"TSURU_SERVICES": {Name: "TSURU_SERVICES", Value: "{}"},
+	})
+}
+
 func (s *S) TestEnvsWithServiceEnvConflict(c *check.C) {
 	app := App{
 		Name: "time",
@@ -3182,6 +3341,27 @@ func (s *S) TestListFilteringByPlatform(c *check.C) {
 	c.Assert(apps, check.HasLen, 1)
 }
 
+func (s *S) TestListFilteringByEnvKey(c *check.C) {
+	a := App{
+		Name:      "testapp",
+		TeamOwner: s.team.Name,
+		Env:       map[string]bind.EnvVar{"DATABASE_URL": {Name: "DATABASE_URL", Value: "db"}},
+	}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.conn.Apps().Update(bson.M{"name": a.Name}, bson.M{"$set": bson.M{"env": a.Env}})
+	c.Assert(err, check.IsNil)
+	apps, err := List(&Filter{EnvKey: "DATABASE_URL"})
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.HasLen, 1)
+	apps, err = List(&Filter{EnvKey: "DATABASE_URL.value"})
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.HasLen, 0)
+	apps, err = List(&Filter{EnvKey: "$where"})
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.HasLen, 0)
+}
+
 func (s *S) TestListFilteringByOwner(c *check.C) {
 	a := App{
 		Name:  "testapp",
@@ -4327,6 +4507,65 @@ func (s *S) TestUpdateDescription(c *check.C) {
 	c.Assert(dbApp.Description, check.Equals, "bleble")
 }
 
+func (s *S) TestUpdateOwnership(c *check.C) {
+	app := App{Name: "example", Platform: "python", TeamOwner: s.team.Name, Ownership: appTypes.Ownership{OnCall: "ops@example.com"}}
+	err := CreateApp(&app, s.user)
+	c.Assert(err, check.IsNil)
+	updateData := App{Name: "example", Ownership: appTypes.Ownership{RunbookURL: "https://wiki.example.com/example"}}
+	err = app.Update(updateData, new(bytes.Buffer))
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Ownership, check.DeepEquals, appTypes.Ownership{
+		OnCall:     "ops@example.com",
+		RunbookURL: "https://wiki.example.com/example",
+	})
+}
+
+func (s *S) TestUpdateOwnershipInvalidURL(c *check.C) {
+	app := App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&app, s.user)
+	c.Assert(err, check.IsNil)
+	updateData := App{Name: "example", Ownership: appTypes.Ownership{RepositoryURL: "github.com/example"}}
+	err = app.Update(updateData, new(bytes.Buffer))
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `Invalid repository URL "github.com/example", it must be an absolute http or https URL.`)
+	dbApp, err := GetByName(app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Ownership, check.DeepEquals, appTypes.Ownership{})
+}
+
+func (s *S) TestAppErrorContext(c *check.C) {
+	app := App{Name: "example", Platform: "python", TeamOwner: s.team.Name, Description: "my app", Ownership: appTypes.Ownership{OnCall: "ops@example.com"}}
+	err := CreateApp(&app, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeApp, Value: app.Name},
+		Kind:     permission.PermAppUpdate,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(fmt.Errorf("something went wrong"))
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(&event.Filter{Target: event.Target{Type: event.TargetTypeApp, Value: app.Name}})
+	c.Assert(err, check.IsNil)
+	var errorContext map[string]interface{}
+	for _, e := range evts {
+		if e.Error != "" {
+			err = e.ErrorContextData(&errorContext)
+			c.Assert(err, check.IsNil)
+		}
+	}
+	c.Assert(errorContext, check.DeepEquals, map[string]interface{}{
+		"description":   "my app",
+		"teamowner":     s.team.Name,
+		"oncall":        "ops@example.com",
+		"runbookURL":    "",
+		"repositoryURL": "",
+	})
+}
+
 func (s *S) TestUpdatePlatformLanguage(c *check.C) {
 	app := App{Name: "example", Platform: "python", TeamOwner: s.team.Name}
 	err := CreateApp(&app, s.user)
@@ -0,0 +1,130 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+var buildSecretNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// buildSecrets are values available only while the image of an app is
+// built, like tokens used to fetch private dependencies. They're stored
+// apart from the app, so they never show up in its env vars.
+type buildSecrets struct {
+	App     string `bson:"_id"`
+	Secrets map[string]string
+}
+
+func buildSecretsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("app_build_secrets"), nil
+}
+
+// SetBuildSecrets adds the given build secrets to the app, replacing the
+// values of secrets already set.
+func (app *App) SetBuildSecrets(secrets map[string]string) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+	update := bson.M{}
+	for name, value := range secrets {
+		if !buildSecretNameRegexp.MatchString(name) {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("invalid build secret name %q, it must contain only letters, numbers and underscores, not starting with a number", name),
+			}
+		}
+		update["secrets."+name] = value
+	}
+	coll, err := buildSecretsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.UpsertId(app.Name, bson.M{"$set": update})
+	return err
+}
+
+// UnsetBuildSecrets removes the build secrets with the given names from the
+// app.
+func (app *App) UnsetBuildSecrets(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	update := bson.M{}
+	for _, name := range names {
+		update["secrets."+name] = ""
+	}
+	coll, err := buildSecretsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.UpdateId(app.Name, bson.M{"$unset": update})
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// BuildSecrets returns the build secrets of the app. Values must never be
+// exposed outside the build.
+func (app *App) BuildSecrets() (map[string]string, error) {
+	coll, err := buildSecretsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var result buildSecrets
+	err = coll.FindId(app.Name).One(&result)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Secrets) == 0 {
+		return nil, nil
+	}
+	return result.Secrets, nil
+}
+
+// BuildSecretNames returns the sorted names of the build secrets of the app.
+func (app *App) BuildSecretNames() ([]string, error) {
+	secrets, err := app.BuildSecrets()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func removeBuildSecrets(appName string) error {
+	coll, err := buildSecretsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.RemoveId(appName)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
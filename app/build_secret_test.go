@@ -0,0 +1,72 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetBuildSecrets(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetBuildSecrets(map[string]string{"NPM_TOKEN": "abc", "REGISTRY_PASSWORD": "xyz"})
+	c.Assert(err, check.IsNil)
+	err = a.SetBuildSecrets(map[string]string{"NPM_TOKEN": "def"})
+	c.Assert(err, check.IsNil)
+	secrets, err := a.BuildSecrets()
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.DeepEquals, map[string]string{"NPM_TOKEN": "def", "REGISTRY_PASSWORD": "xyz"})
+	names, err := a.BuildSecretNames()
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"NPM_TOKEN", "REGISTRY_PASSWORD"})
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	_, ok := dbApp.Envs()["NPM_TOKEN"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestSetBuildSecretsInvalidName(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := a.SetBuildSecrets(map[string]string{"1TOKEN": "abc"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = a.SetBuildSecrets(map[string]string{"A.B": "abc"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	secrets, err := a.BuildSecrets()
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.IsNil)
+}
+
+func (s *S) TestUnsetBuildSecrets(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := a.UnsetBuildSecrets([]string{"NPM_TOKEN"})
+	c.Assert(err, check.IsNil)
+	err = a.SetBuildSecrets(map[string]string{"NPM_TOKEN": "abc", "OTHER": "xyz"})
+	c.Assert(err, check.IsNil)
+	err = a.UnsetBuildSecrets([]string{"NPM_TOKEN"})
+	c.Assert(err, check.IsNil)
+	secrets, err := a.BuildSecrets()
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.DeepEquals, map[string]string{"OTHER": "xyz"})
+	err = a.UnsetBuildSecrets([]string{"OTHER"})
+	c.Assert(err, check.IsNil)
+	secrets, err = a.BuildSecrets()
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.IsNil)
+}
+
+func (s *S) TestRemoveBuildSecrets(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := a.SetBuildSecrets(map[string]string{"NPM_TOKEN": "abc"})
+	c.Assert(err, check.IsNil)
+	err = removeBuildSecrets(a.Name)
+	c.Assert(err, check.IsNil)
+	secrets, err := a.BuildSecrets()
+	c.Assert(err, check.IsNil)
+	c.Assert(secrets, check.IsNil)
+	err = removeBuildSecrets(a.Name)
+	c.Assert(err, check.IsNil)
+}
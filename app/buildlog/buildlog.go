@@ -0,0 +1,296 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package buildlog stores the output of app builds, so it can be retrieved
+// after the deploy that generated it has finished.
+package buildlog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	// PruneJobName is the name of the job registered by RegisterPruneJob.
+	PruneJobName = "build-logs-prune"
+
+	defaultMaxAge = 30 * 24 * time.Hour
+)
+
+var ErrBuildLogNotFound = errors.New("build log not found")
+
+// BuildLog describes the build log of a deploy, identified by the deploy
+// event id.
+type BuildLog struct {
+	Deploy    bson.ObjectId `bson:"_id" json:"deploy"`
+	App       string        `json:"app"`
+	StartTime time.Time     `json:"startTime"`
+	EndTime   time.Time     `json:"endTime"`
+	Finished  bool          `json:"finished"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Entry is a piece of the output of a build.
+type Entry struct {
+	ID     bson.ObjectId `bson:"_id"`
+	Deploy bson.ObjectId
+	App    string
+	Data   []byte
+}
+
+func logsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("build_logs")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func entriesCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("build_log_entries")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"deploy", "_id"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// Get returns the build log of a deploy.
+func Get(deployID bson.ObjectId) (*BuildLog, error) {
+	coll, err := logsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var l BuildLog
+	err = coll.FindId(deployID).One(&l)
+	if err == mgo.ErrNotFound {
+		return nil, ErrBuildLogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Entries returns the entries of the build log written after the entry with
+// the given id, or all entries if the id is empty.
+func (l *BuildLog) Entries(after bson.ObjectId) ([]Entry, error) {
+	coll, err := entriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	query := bson.M{"deploy": l.Deploy}
+	if after != "" {
+		query["_id"] = bson.M{"$gt": after}
+	}
+	var entries []Entry
+	err = coll.Find(query).Sort("_id").All(&entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AverageDuration returns the average duration of the last n finished
+// builds, or zero when no build has finished yet.
+func AverageDuration(n int) (time.Duration, error) {
+	coll, err := logsCollection()
+	if err != nil {
+		return 0, err
+	}
+	defer coll.Close()
+	var logs []BuildLog
+	err = coll.Find(bson.M{"finished": true}).Sort("-endtime").Limit(n).All(&logs)
+	if err != nil {
+		return 0, err
+	}
+	if len(logs) == 0 {
+		return 0, nil
+	}
+	var total time.Duration
+	for _, l := range logs {
+		total += l.EndTime.Sub(l.StartTime)
+	}
+	return total / time.Duration(len(logs)), nil
+}
+
+// RemoveAppLogs removes all build logs of an app.
+func RemoveAppLogs(appName string) error {
+	entries, err := entriesCollection()
+	if err != nil {
+		return err
+	}
+	defer entries.Close()
+	_, err = entries.RemoveAll(bson.M{"app": appName})
+	if err != nil {
+		return err
+	}
+	logs, err := logsCollection()
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	_, err = logs.RemoveAll(bson.M{"app": appName})
+	return err
+}
+
+// Prune removes the build logs of builds finished before the given time,
+// returning the number of removed build logs.
+func Prune(before time.Time) (int, error) {
+	logs, err := logsCollection()
+	if err != nil {
+		return 0, err
+	}
+	defer logs.Close()
+	var old []BuildLog
+	err = logs.Find(bson.M{"finished": true, "endtime": bson.M{"$lt": before}}).Select(bson.M{"_id": 1}).All(&old)
+	if err != nil {
+		return 0, err
+	}
+	if len(old) == 0 {
+		return 0, nil
+	}
+	ids := make([]bson.ObjectId, len(old))
+	for i := range old {
+		ids[i] = old[i].Deploy
+	}
+	entries, err := entriesCollection()
+	if err != nil {
+		return 0, err
+	}
+	defer entries.Close()
+	_, err = entries.RemoveAll(bson.M{"deploy": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	info, err := logs.RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	return info.Removed, nil
+}
+
+func maxAge() time.Duration {
+	seconds, _ := config.GetInt("buildlog:max-age")
+	if seconds <= 0 {
+		return defaultMaxAge
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// RegisterPruneJob registers the job that removes build logs older than the
+// configured max age. It's disabled by default.
+func RegisterPruneJob() error {
+	return jobs.Register(jobs.Job{
+		Name:        PruneJobName,
+		Description: "removes build logs of builds finished before the configured max age",
+		Schedule:    "@daily",
+		Disabled:    true,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			removed, err := Prune(time.Now().UTC().Add(-maxAge()))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(evt, "removed %d build logs\n", removed)
+			return nil
+		},
+	})
+}
+
+// Writer stores everything written to it as entries of a build log. Errors
+// storing entries are logged and never returned, so a failure persisting the
+// log doesn't interrupt the build.
+type Writer struct {
+	mu       sync.Mutex
+	buildLog BuildLog
+	failed   bool
+}
+
+// NewWriter starts the build log of a deploy.
+func NewWriter(appName string, deployID bson.ObjectId) (*Writer, error) {
+	coll, err := logsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	w := &Writer{buildLog: BuildLog{
+		Deploy:    deployID,
+		App:       appName,
+		StartTime: time.Now().UTC(),
+	}}
+	_, err = coll.UpsertId(deployID, w.buildLog)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failed {
+		return len(data), nil
+	}
+	coll, err := entriesCollection()
+	if err == nil {
+		defer coll.Close()
+		err = coll.Insert(Entry{
+			ID:     bson.NewObjectId(),
+			Deploy: w.buildLog.Deploy,
+			App:    w.buildLog.App,
+			Data:   append([]byte(nil), data...),
+		})
+	}
+	if err != nil {
+		w.failed = true
+		log.Errorf("[build log] unable to store build log of app %q: %v", w.buildLog.App, err)
+	}
+	return len(data), nil
+}
+
+// Close marks the build log as finished, with the given build error.
+func (w *Writer) Close(buildErr error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	coll, err := logsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	update := bson.M{"finished": true, "endtime": time.Now().UTC()}
+	if buildErr != nil {
+		update["error"] = buildErr.Error()
+	}
+	return coll.UpdateId(w.buildLog.Deploy, bson.M{"$set": update})
+}
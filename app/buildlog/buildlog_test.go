@@ -0,0 +1,146 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildlog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	check "gopkg.in/check.v1"
+)
+
+func entriesData(entries []Entry) string {
+	var data string
+	for _, e := range entries {
+		data += string(e.Data)
+	}
+	return data
+}
+
+func (s *S) TestWriter(c *check.C) {
+	deployID := bson.NewObjectId()
+	w, err := NewWriter("myapp", deployID)
+	c.Assert(err, check.IsNil)
+	fmt.Fprintln(w, "step 1")
+	fmt.Fprintln(w, "step 2")
+	l, err := Get(deployID)
+	c.Assert(err, check.IsNil)
+	c.Assert(l.App, check.Equals, "myapp")
+	c.Assert(l.Finished, check.Equals, false)
+	entries, err := l.Entries("")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 2)
+	c.Assert(entriesData(entries), check.Equals, "step 1\nstep 2\n")
+	err = w.Close(nil)
+	c.Assert(err, check.IsNil)
+	l, err = Get(deployID)
+	c.Assert(err, check.IsNil)
+	c.Assert(l.Finished, check.Equals, true)
+	c.Assert(l.Error, check.Equals, "")
+	c.Assert(l.EndTime.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestWriterCloseWithError(c *check.C) {
+	deployID := bson.NewObjectId()
+	w, err := NewWriter("myapp", deployID)
+	c.Assert(err, check.IsNil)
+	err = w.Close(errors.New("build failed"))
+	c.Assert(err, check.IsNil)
+	l, err := Get(deployID)
+	c.Assert(err, check.IsNil)
+	c.Assert(l.Finished, check.Equals, true)
+	c.Assert(l.Error, check.Equals, "build failed")
+}
+
+func (s *S) TestEntriesAfter(c *check.C) {
+	deployID := bson.NewObjectId()
+	w, err := NewWriter("myapp", deployID)
+	c.Assert(err, check.IsNil)
+	fmt.Fprint(w, "first")
+	l, err := Get(deployID)
+	c.Assert(err, check.IsNil)
+	entries, err := l.Entries("")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	fmt.Fprint(w, "second")
+	fmt.Fprint(w, "third")
+	entries, err = l.Entries(entries[0].ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(entriesData(entries), check.Equals, "secondthird")
+}
+
+func (s *S) TestGetNotFound(c *check.C) {
+	_, err := Get(bson.NewObjectId())
+	c.Assert(err, check.Equals, ErrBuildLogNotFound)
+}
+
+func (s *S) TestRemoveAppLogs(c *check.C) {
+	ids := []bson.ObjectId{bson.NewObjectId(), bson.NewObjectId()}
+	for i, appName := range []string{"myapp", "otherapp"} {
+		w, err := NewWriter(appName, ids[i])
+		c.Assert(err, check.IsNil)
+		fmt.Fprint(w, "building "+appName)
+	}
+	err := RemoveAppLogs("myapp")
+	c.Assert(err, check.IsNil)
+	_, err = Get(ids[0])
+	c.Assert(err, check.Equals, ErrBuildLogNotFound)
+	l, err := Get(ids[1])
+	c.Assert(err, check.IsNil)
+	entries, err := l.Entries("")
+	c.Assert(err, check.IsNil)
+	c.Assert(entriesData(entries), check.Equals, "building otherapp")
+	count, err := s.storage.Collection("build_log_entries").Find(bson.M{"app": "myapp"}).Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, 0)
+}
+
+func (s *S) TestAverageDuration(c *check.C) {
+	avg, err := AverageDuration(10)
+	c.Assert(err, check.IsNil)
+	c.Assert(avg, check.Equals, time.Duration(0))
+	now := time.Now().UTC()
+	coll := s.storage.Collection("build_logs")
+	err = coll.Insert(
+		BuildLog{Deploy: bson.NewObjectId(), App: "myapp", StartTime: now.Add(-time.Minute), EndTime: now, Finished: true},
+		BuildLog{Deploy: bson.NewObjectId(), App: "myapp", StartTime: now.Add(-3 * time.Minute), EndTime: now, Finished: true},
+		BuildLog{Deploy: bson.NewObjectId(), App: "myapp", StartTime: now.Add(-time.Hour)},
+	)
+	c.Assert(err, check.IsNil)
+	avg, err = AverageDuration(10)
+	c.Assert(err, check.IsNil)
+	c.Assert(avg, check.Equals, 2*time.Minute)
+}
+
+func (s *S) TestPrune(c *check.C) {
+	oldID := bson.NewObjectId()
+	w, err := NewWriter("myapp", oldID)
+	c.Assert(err, check.IsNil)
+	fmt.Fprintln(w, "old build")
+	err = w.Close(nil)
+	c.Assert(err, check.IsNil)
+	runningID := bson.NewObjectId()
+	w, err = NewWriter("myapp", runningID)
+	c.Assert(err, check.IsNil)
+	fmt.Fprintln(w, "running build")
+	removed, err := Prune(time.Now().UTC().Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(removed, check.Equals, 1)
+	_, err = Get(oldID)
+	c.Assert(err, check.Equals, ErrBuildLogNotFound)
+	entries, err := (&BuildLog{Deploy: oldID}).Entries("")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 0)
+	l, err := Get(runningID)
+	c.Assert(err, check.IsNil)
+	entries, err = l.Entries("")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	removed, err = Prune(time.Now().UTC().Add(-time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(removed, check.Equals, 0)
+}
@@ -0,0 +1,409 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package buildqueue limits the number of simultaneous app builds, globally
+// and per team, queueing the builds exceeding the limits in the order they
+// were requested.
+package buildqueue
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/buildlog"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	globalSlotsKey       = "global"
+	durationSampleSize   = 20
+	defaultPollInterval  = time.Second
+	defaultHeartbeatTime = 10 * time.Second
+	defaultMaxStale      = time.Minute
+)
+
+// ErrCanceled is returned when a build is canceled while waiting in the
+// queue.
+var ErrCanceled = errors.New("build canceled while waiting in the queue")
+
+var (
+	pollInterval      = defaultPollInterval
+	heartbeatInterval = defaultHeartbeatTime
+	maxStale          = defaultMaxStale
+)
+
+// Entry is a build waiting in the queue or running, identified by the id of
+// its deploy event. Position and EstimatedWait are only set for builds
+// waiting in the queue.
+type Entry struct {
+	Deploy        bson.ObjectId `bson:"_id" json:"deploy"`
+	App           string        `json:"app"`
+	Team          string        `json:"team"`
+	EnqueuedAt    time.Time     `json:"enqueuedAt"`
+	StartedAt     time.Time     `json:"startedAt"`
+	Running       bool          `json:"running"`
+	UpdatedAt     time.Time     `json:"-"`
+	Position      int           `bson:"-" json:"position,omitempty"`
+	EstimatedWait time.Duration `bson:"-" json:"estimatedWait,omitempty"`
+}
+
+// Limits are the maximum number of simultaneous builds, zero means
+// unlimited.
+type Limits struct {
+	Global  int `json:"global"`
+	PerTeam int `json:"perTeam"`
+}
+
+// GetLimits returns the build limits set in the config.
+func GetLimits() Limits {
+	global, _ := config.GetInt("deploy:max-concurrent-builds")
+	perTeam, _ := config.GetInt("deploy:max-concurrent-builds-per-team")
+	return Limits{Global: global, PerTeam: perTeam}
+}
+
+func (l Limits) enabled() bool {
+	return l.Global > 0 || l.PerTeam > 0
+}
+
+func queueCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("build_queue")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"running", "enqueuedat"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func slotsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("build_queue_slots"), nil
+}
+
+func teamSlotsKey(team string) string {
+	return "team:" + team
+}
+
+// Acquire waits until the build of the deploy may start according to the
+// build limits, writing the position of the build in the queue to w while
+// it waits. The returned function must be called when the build finishes.
+// Waiting stops with ErrCanceled when ctx is done.
+func Acquire(ctx context.Context, deployID bson.ObjectId, appName, team string, w io.Writer) (func(), error) {
+	limits := GetLimits()
+	if !limits.enabled() {
+		return func() {}, nil
+	}
+	now := time.Now().UTC()
+	entry := Entry{
+		Deploy:     deployID,
+		App:        appName,
+		Team:       team,
+		EnqueuedAt: now,
+		UpdatedAt:  now,
+	}
+	coll, err := queueCollection()
+	if err != nil {
+		return nil, err
+	}
+	err = coll.Insert(entry)
+	coll.Close()
+	if err != nil {
+		return nil, err
+	}
+	lastPosition := 0
+	for {
+		started, position, err := tryStart(&entry, limits)
+		if err != nil {
+			remove(entry.Deploy)
+			return nil, err
+		}
+		if started {
+			if lastPosition != 0 {
+				fmt.Fprintln(w, " ---> Build slot acquired, starting build")
+			}
+			return startHeartbeat(entry), nil
+		}
+		if position != lastPosition {
+			lastPosition = position
+			avg, err := buildlog.AverageDuration(durationSampleSize)
+			if err != nil {
+				log.Errorf("[build queue] unable to get average build duration: %v", err)
+			}
+			fmt.Fprintf(w, "---- Build queued, position %d, estimated wait %v ----\n", position, estimateWait(position, limits, avg))
+		}
+		select {
+		case <-ctx.Done():
+			remove(entry.Deploy)
+			return nil, ErrCanceled
+		case <-time.After(pollInterval):
+		}
+		err = touch(entry.Deploy)
+		if err != nil {
+			log.Errorf("[build queue] unable to update queued build of app %q: %v", appName, err)
+		}
+	}
+}
+
+// tryStart starts the build when it's the first waiting build allowed by the
+// limits and there are free slots for it, returning its position in the
+// queue otherwise. Slots are reserved atomically, so the limits hold even
+// when multiple tsuru API instances race for them.
+func tryStart(entry *Entry, limits Limits) (bool, int, error) {
+	err := removeStale()
+	if err != nil {
+		return false, 0, err
+	}
+	running, waiting, err := entries()
+	if err != nil {
+		return false, 0, err
+	}
+	teamRunning := map[string]int{}
+	for _, e := range running {
+		teamRunning[e.Team]++
+	}
+	globalRunning := len(running)
+	position := 0
+	turn := false
+	for i, e := range waiting {
+		if e.Deploy == entry.Deploy {
+			position = i + 1
+		}
+		if limits.Global > 0 && globalRunning >= limits.Global {
+			break
+		}
+		if limits.PerTeam > 0 && teamRunning[e.Team] >= limits.PerTeam {
+			continue
+		}
+		if e.Deploy == entry.Deploy {
+			turn = true
+			break
+		}
+		globalRunning++
+		teamRunning[e.Team]++
+	}
+	if position == 0 {
+		return false, 0, errors.Errorf("build of app %q was removed from the queue", entry.App)
+	}
+	if !turn {
+		return false, position, nil
+	}
+	acquired, err := acquireSlots(entry, limits)
+	if err != nil || !acquired {
+		return false, position, err
+	}
+	coll, err := queueCollection()
+	if err != nil {
+		releaseSlots(*entry)
+		return false, 0, err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	err = coll.UpdateId(entry.Deploy, bson.M{"$set": bson.M{"running": true, "startedat": now, "updatedat": now}})
+	if err != nil {
+		releaseSlots(*entry)
+		return false, 0, err
+	}
+	entry.Running = true
+	entry.StartedAt = now
+	return true, 0, nil
+}
+
+func acquireSlots(entry *Entry, limits Limits) (bool, error) {
+	coll, err := slotsCollection()
+	if err != nil {
+		return false, err
+	}
+	defer coll.Close()
+	acquired, err := acquireSlot(coll, teamSlotsKey(entry.Team), limits.PerTeam, entry.Deploy)
+	if err != nil || !acquired {
+		return false, err
+	}
+	acquired, err = acquireSlot(coll, globalSlotsKey, limits.Global, entry.Deploy)
+	if err != nil || !acquired {
+		coll.UpdateId(teamSlotsKey(entry.Team), bson.M{"$pull": bson.M{"elements": bson.M{"id": entry.Deploy}}})
+		return false, err
+	}
+	return true, nil
+}
+
+func acquireSlot(coll *storage.Collection, key string, limit int, id bson.ObjectId) (bool, error) {
+	if limit <= 0 {
+		return true, nil
+	}
+	_, err := coll.Upsert(bson.M{
+		"_id":                               key,
+		fmt.Sprintf("elements.%d", limit-1): bson.M{"$exists": false},
+	}, bson.M{
+		"$push": bson.M{"elements": bson.M{"id": id, "update": time.Now().UTC()}},
+	})
+	if mgo.IsDup(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func releaseSlots(entry Entry) {
+	coll, err := slotsCollection()
+	if err != nil {
+		log.Errorf("[build queue] unable to release build slots of app %q: %v", entry.App, err)
+		return
+	}
+	defer coll.Close()
+	_, err = coll.UpdateAll(nil, bson.M{"$pull": bson.M{"elements": bson.M{"id": entry.Deploy}}})
+	if err != nil {
+		log.Errorf("[build queue] unable to release build slots of app %q: %v", entry.App, err)
+	}
+}
+
+// startHeartbeat keeps the build and its slots from being considered stale
+// while it runs, returning the function that releases them.
+func startHeartbeat(entry Entry) func() {
+	quit := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-quit:
+				return
+			case <-time.After(heartbeatInterval):
+			}
+			err := touch(entry.Deploy)
+			if err != nil {
+				log.Errorf("[build queue] unable to update running build of app %q: %v", entry.App, err)
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		releaseSlots(entry)
+		remove(entry.Deploy)
+	}
+}
+
+func touch(deployID bson.ObjectId) error {
+	now := time.Now().UTC()
+	coll, err := queueCollection()
+	if err != nil {
+		return err
+	}
+	err = coll.UpdateId(deployID, bson.M{"$set": bson.M{"updatedat": now}})
+	coll.Close()
+	if err != nil {
+		return err
+	}
+	slots, err := slotsCollection()
+	if err != nil {
+		return err
+	}
+	defer slots.Close()
+	_, err = slots.UpdateAll(bson.M{"elements.id": deployID}, bson.M{"$set": bson.M{"elements.$.update": now}})
+	return err
+}
+
+func remove(deployID bson.ObjectId) {
+	coll, err := queueCollection()
+	if err != nil {
+		log.Errorf("[build queue] unable to remove build %q from queue: %v", deployID.Hex(), err)
+		return
+	}
+	defer coll.Close()
+	err = coll.RemoveId(deployID)
+	if err != nil && err != mgo.ErrNotFound {
+		log.Errorf("[build queue] unable to remove build %q from queue: %v", deployID.Hex(), err)
+	}
+}
+
+// removeStale removes the builds and slots not updated for a while, left
+// behind by tsuru API instances that stopped abruptly.
+func removeStale() error {
+	limit := time.Now().UTC().Add(-maxStale)
+	coll, err := queueCollection()
+	if err != nil {
+		return err
+	}
+	_, err = coll.RemoveAll(bson.M{"updatedat": bson.M{"$lt": limit}})
+	coll.Close()
+	if err != nil {
+		return err
+	}
+	slots, err := slotsCollection()
+	if err != nil {
+		return err
+	}
+	defer slots.Close()
+	_, err = slots.UpdateAll(nil, bson.M{"$pull": bson.M{"elements": bson.M{"update": bson.M{"$lt": limit}}}})
+	return err
+}
+
+func entries() (running []Entry, waiting []Entry, err error) {
+	coll, err := queueCollection()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer coll.Close()
+	var all []Entry
+	err = coll.Find(nil).Sort("enqueuedat", "_id").All(&all)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range all {
+		if e.Running {
+			running = append(running, e)
+		} else {
+			waiting = append(waiting, e)
+		}
+	}
+	return running, waiting, nil
+}
+
+// estimateWait estimates how long a build in the given position of the queue
+// waits, given the average duration of the last builds.
+func estimateWait(position int, limits Limits, avg time.Duration) time.Duration {
+	slots := limits.Global
+	if slots <= 0 {
+		slots = limits.PerTeam
+	}
+	if slots <= 0 {
+		return 0
+	}
+	rounds := (position-1)/slots + 1
+	return (avg * time.Duration(rounds)).Round(time.Second)
+}
+
+// List returns the running builds followed by the builds waiting in the
+// queue, in the order they are going to start.
+func List() ([]Entry, error) {
+	err := removeStale()
+	if err != nil {
+		return nil, err
+	}
+	running, waiting, err := entries()
+	if err != nil {
+		return nil, err
+	}
+	avg, err := buildlog.AverageDuration(durationSampleSize)
+	if err != nil {
+		return nil, err
+	}
+	limits := GetLimits()
+	for i := range waiting {
+		waiting[i].Position = i + 1
+		waiting[i].EstimatedWait = estimateWait(i+1, limits, avg)
+	}
+	return append(running, waiting...), nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildqueue
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/safe"
+	check "gopkg.in/check.v1"
+)
+
+type acquireResult struct {
+	release func()
+	err     error
+}
+
+func acquireAsync(deployID bson.ObjectId, appName, team string, w *safe.Buffer) chan acquireResult {
+	ch := make(chan acquireResult, 1)
+	go func() {
+		release, err := Acquire(context.Background(), deployID, appName, team, w)
+		ch <- acquireResult{release: release, err: err}
+	}()
+	return ch
+}
+
+func waitQueued(c *check.C, deployID bson.ObjectId) {
+	for i := 0; i < 500; i++ {
+		entries, err := List()
+		c.Assert(err, check.IsNil)
+		for _, e := range entries {
+			if e.Deploy == deployID {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatalf("build %q not queued", deployID.Hex())
+}
+
+func (s *S) TestAcquireWithoutLimits(c *check.C) {
+	var buf bytes.Buffer
+	release, err := Acquire(context.Background(), bson.NewObjectId(), "myapp", "myteam", &buf)
+	c.Assert(err, check.IsNil)
+	release()
+	c.Assert(buf.String(), check.Equals, "")
+	n, err := s.storage.Collection("build_queue").Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+}
+
+func (s *S) TestAcquireGlobalLimit(c *check.C) {
+	config.Set("deploy:max-concurrent-builds", 1)
+	first := bson.NewObjectId()
+	release, err := Acquire(context.Background(), first, "myapp", "myteam", ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	second := bson.NewObjectId()
+	var buf safe.Buffer
+	ch := acquireAsync(second, "otherapp", "otherteam", &buf)
+	waitQueued(c, second)
+	entries, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 2)
+	c.Assert(entries[0].Deploy, check.Equals, first)
+	c.Assert(entries[0].Running, check.Equals, true)
+	c.Assert(entries[0].Position, check.Equals, 0)
+	c.Assert(entries[1].Deploy, check.Equals, second)
+	c.Assert(entries[1].Running, check.Equals, false)
+	c.Assert(entries[1].Position, check.Equals, 1)
+	select {
+	case <-ch:
+		c.Fatal("second build should be queued")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	var result acquireResult
+	select {
+	case result = <-ch:
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for second build to start")
+	}
+	c.Assert(result.err, check.IsNil)
+	result.release()
+	c.Assert(buf.String(), check.Matches, `(?s)---- Build queued, position 1, estimated wait .* ----\n ---> Build slot acquired, starting build\n`)
+	entries, err = List()
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 0)
+}
+
+func (s *S) TestAcquirePerTeamLimit(c *check.C) {
+	config.Set("deploy:max-concurrent-builds-per-team", 1)
+	release, err := Acquire(context.Background(), bson.NewObjectId(), "myapp", "myteam", ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	defer release()
+	queued := bson.NewObjectId()
+	var buf safe.Buffer
+	ch := acquireAsync(queued, "myapp2", "myteam", &buf)
+	waitQueued(c, queued)
+	otherRelease, err := Acquire(context.Background(), bson.NewObjectId(), "otherapp", "otherteam", ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	otherRelease()
+	select {
+	case <-ch:
+		c.Fatal("build should be queued behind the build of the same team")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	result := <-ch
+	c.Assert(result.err, check.IsNil)
+	result.release()
+}
+
+func (s *S) TestAcquireCanceled(c *check.C) {
+	config.Set("deploy:max-concurrent-builds", 1)
+	release, err := Acquire(context.Background(), bson.NewObjectId(), "myapp", "myteam", ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	queued := bson.NewObjectId()
+	_, err = Acquire(ctx, queued, "otherapp", "myteam", ioutil.Discard)
+	c.Assert(err, check.Equals, ErrCanceled)
+	entries, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].Deploy, check.Not(check.Equals), queued)
+}
+
+func (s *S) TestAcquireRemovesStaleBuilds(c *check.C) {
+	config.Set("deploy:max-concurrent-builds", 1)
+	staleRelease, err := Acquire(context.Background(), bson.NewObjectId(), "myapp", "myteam", ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	defer staleRelease()
+	maxStale = time.Millisecond
+	time.Sleep(10 * time.Millisecond)
+	release, err := Acquire(context.Background(), bson.NewObjectId(), "otherapp", "myteam", ioutil.Discard)
+	c.Assert(err, check.IsNil)
+	release()
+}
+
+func (s *S) TestEstimateWait(c *check.C) {
+	c.Assert(estimateWait(1, Limits{Global: 2}, time.Minute), check.Equals, time.Minute)
+	c.Assert(estimateWait(2, Limits{Global: 2}, time.Minute), check.Equals, time.Minute)
+	c.Assert(estimateWait(3, Limits{Global: 2}, time.Minute), check.Equals, 2*time.Minute)
+	c.Assert(estimateWait(3, Limits{PerTeam: 1}, time.Minute), check.Equals, 3*time.Minute)
+	c.Assert(estimateWait(3, Limits{}, time.Minute), check.Equals, time.Duration(0))
+}
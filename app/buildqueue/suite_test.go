@@ -0,0 +1,47 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage *db.Storage
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_buildqueue_tests")
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	pollInterval = 10 * time.Millisecond
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("deploy:max-concurrent-builds")
+	config.Unset("deploy:max-concurrent-builds-per-team")
+	maxStale = defaultMaxStale
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	pollInterval = defaultPollInterval
+	s.storage.Apps().Database.DropDatabase()
+	s.storage.Close()
+}
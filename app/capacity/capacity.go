@@ -0,0 +1,287 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package capacity reports, per pool, the memory provided by nodes, the
+// memory reserved by the plans of running units and the memory actually in
+// use, projecting the growth of reservations from their daily history to
+// guide node purchases and autoscale limits.
+package capacity
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+)
+
+const (
+	growthWindow   = 30 * 24 * time.Hour
+	projectionDays = 30
+	dayLayout      = "2006-01-02"
+)
+
+// PoolCapacity holds the memory capacity of a pool, in bytes. MemoryUsed is
+// only set when the provisioner reports the real usage of every node in the
+// pool. Growth fields are only set when there's at least one day of history.
+type PoolCapacity struct {
+	Pool                string   `json:"pool"`
+	Nodes               int      `json:"nodes"`
+	NodesWithoutMemory  int      `json:"nodesWithoutMemory,omitempty"`
+	Units               int      `json:"units"`
+	MemoryCapacity      int64    `json:"memoryCapacity"`
+	MemoryReserved      int64    `json:"memoryReserved"`
+	MemoryUsed          *int64   `json:"memoryUsed,omitempty"`
+	MemoryHeadroom      int64    `json:"memoryHeadroom"`
+	ReservedGrowthDaily *float64 `json:"reservedGrowthDaily,omitempty"`
+	ProjectedReserved   *int64   `json:"projectedReserved,omitempty"`
+	DaysUntilFull       *float64 `json:"daysUntilFull,omitempty"`
+}
+
+// snapshot is the daily record of the reserved memory of a pool, used to
+// project its growth.
+type snapshot struct {
+	Pool           string
+	Day            string
+	MemoryReserved int64
+	Units          int
+	UpdatedAt      time.Time
+}
+
+func snapshotsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("pool_capacity_history")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"pool", "day"}, Unique: true})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func totalMemoryMetadata() string {
+	name, _ := config.GetString("docker:scheduler:total-memory-metadata")
+	return name
+}
+
+// Report returns the capacity of the pools with the given names, or of every
+// pool if no name is given, sorted by pool name.
+func Report(poolNames []string, now time.Time) ([]PoolCapacity, error) {
+	report, err := current(poolNames)
+	if err != nil {
+		return nil, err
+	}
+	for i := range report {
+		err = project(&report[i], now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func current(poolNames []string) ([]PoolCapacity, error) {
+	var pools []pool.Pool
+	var err error
+	if len(poolNames) == 0 {
+		pools, err = pool.ListAllPools()
+	} else {
+		pools, err = pool.ListPools(poolNames...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	byPool := make(map[string]*PoolCapacity, len(pools))
+	report := make([]PoolCapacity, len(pools))
+	names := make([]string, len(pools))
+	for i, p := range pools {
+		report[i].Pool = p.Name
+		names[i] = p.Name
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Pool < report[j].Pool })
+	for i := range report {
+		byPool[report[i].Pool] = &report[i]
+	}
+	err = addNodes(byPool)
+	if err != nil {
+		return nil, err
+	}
+	err = addReservations(byPool, names)
+	if err != nil {
+		return nil, err
+	}
+	for i := range report {
+		report[i].MemoryHeadroom = report[i].MemoryCapacity - report[i].MemoryReserved
+	}
+	return report, nil
+}
+
+func addNodes(byPool map[string]*PoolCapacity) error {
+	provs, err := provision.Registry()
+	if err != nil {
+		return err
+	}
+	memoryMetadata := totalMemoryMetadata()
+	usedByPool := map[string]int64{}
+	unmeasuredPools := map[string]bool{}
+	for _, prov := range provs {
+		nodeProv, ok := prov.(provision.NodeProvisioner)
+		if !ok {
+			continue
+		}
+		nodes, err := nodeProv.ListNodes(nil)
+		if err != nil {
+			return err
+		}
+		var addrs []string
+		for _, n := range nodes {
+			pc := byPool[n.Pool()]
+			if pc == nil {
+				continue
+			}
+			addrs = append(addrs, n.Address())
+			pc.Nodes++
+			memory, _ := strconv.ParseInt(n.Metadata()[memoryMetadata], 10, 64)
+			if memoryMetadata == "" || memory <= 0 {
+				pc.NodesWithoutMemory++
+				continue
+			}
+			pc.MemoryCapacity += memory
+		}
+		var usage map[string]int64
+		if usageProv, ok := prov.(provision.NodeUsageProvisioner); ok {
+			usage, err = usageProv.NodesMemoryUsage(addrs)
+			if err != nil {
+				return err
+			}
+		}
+		for _, n := range nodes {
+			if byPool[n.Pool()] == nil {
+				continue
+			}
+			used, ok := usage[n.Address()]
+			if !ok {
+				unmeasuredPools[n.Pool()] = true
+				continue
+			}
+			usedByPool[n.Pool()] += used
+		}
+	}
+	for name, pc := range byPool {
+		if pc.Nodes == 0 || unmeasuredPools[name] {
+			continue
+		}
+		used := usedByPool[name]
+		pc.MemoryUsed = &used
+	}
+	return nil
+}
+
+func addReservations(byPool map[string]*PoolCapacity, poolNames []string) error {
+	if len(poolNames) == 0 {
+		return nil
+	}
+	apps, err := app.List(&app.Filter{Pools: poolNames})
+	if err != nil {
+		return err
+	}
+	for i := range apps {
+		a := &apps[i]
+		pc := byPool[a.Pool]
+		if pc == nil {
+			continue
+		}
+		units, err := a.Units()
+		if err != nil {
+			return err
+		}
+		for _, u := range units {
+			if u.Status == provision.StatusStopped || u.Status == provision.StatusAsleep {
+				continue
+			}
+			pc.Units++
+			pc.MemoryReserved += a.Plan.Memory
+		}
+	}
+	return nil
+}
+
+// project fills the growth fields of the pool capacity from the oldest
+// snapshot in the growth window.
+func project(pc *PoolCapacity, now time.Time) error {
+	coll, err := snapshotsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	var oldest snapshot
+	err = coll.Find(bson.M{
+		"pool": pc.Pool,
+		"day":  bson.M{"$gte": now.Add(-growthWindow).Format(dayLayout)},
+	}).Sort("day").One(&oldest)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	start, err := time.Parse(dayLayout, oldest.Day)
+	if err != nil {
+		return err
+	}
+	days := now.Sub(start).Hours() / 24
+	if days < 1 {
+		return nil
+	}
+	growth := float64(pc.MemoryReserved-oldest.MemoryReserved) / days
+	projected := pc.MemoryReserved + int64(growth*projectionDays)
+	pc.ReservedGrowthDaily = &growth
+	pc.ProjectedReserved = &projected
+	if growth > 0 && pc.MemoryCapacity > 0 {
+		daysUntilFull := float64(pc.MemoryHeadroom) / growth
+		if daysUntilFull < 0 {
+			daysUntilFull = 0
+		}
+		pc.DaysUntilFull = &daysUntilFull
+	}
+	return nil
+}
+
+// takeSnapshot records the current reserved memory of every pool in the
+// history of the day.
+func takeSnapshot(now time.Time) error {
+	report, err := current(nil)
+	if err != nil {
+		return err
+	}
+	coll, err := snapshotsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	day := now.Format(dayLayout)
+	for _, pc := range report {
+		_, err = coll.Upsert(bson.M{"pool": pc.Pool, "day": day}, snapshot{
+			Pool:           pc.Pool,
+			Day:            day,
+			MemoryReserved: pc.MemoryReserved,
+			Units:          pc.Units,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
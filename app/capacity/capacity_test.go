@@ -0,0 +1,125 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capacity
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) addNode(c *check.C, addr, pool, memory string) {
+	metadata := map[string]string{}
+	if memory != "" {
+		metadata["totalMemory"] = memory
+	}
+	err := provisiontest.ProvisionerInstance.AddNode(provision.AddNodeOptions{
+		Address:  addr,
+		Pool:     pool,
+		Metadata: metadata,
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestReport(c *check.C) {
+	s.addNode(c, "http://n1:2375", "p1", "1024")
+	s.addNode(c, "http://n2:2375", "p1", "1024")
+	s.addNode(c, "http://n3:2375", "p2", "")
+	a := s.newApp(c, "myapp", "p1")
+	err := provisiontest.ProvisionerInstance.AddUnits(a, 3, "web", nil)
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	err = provisiontest.ProvisionerInstance.SetUnitStatus(units[0], provision.StatusStopped)
+	c.Assert(err, check.IsNil)
+	report, err := Report(nil, time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, []PoolCapacity{
+		{Pool: "p1", Nodes: 2, Units: 2, MemoryCapacity: 2048, MemoryReserved: 512, MemoryHeadroom: 1536},
+		{Pool: "p2", Nodes: 1, NodesWithoutMemory: 1},
+	})
+}
+
+func (s *S) TestReportFilterPools(c *check.C) {
+	s.addNode(c, "http://n1:2375", "p1", "1024")
+	s.addNode(c, "http://n2:2375", "p2", "2048")
+	report, err := Report([]string{"p2"}, time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, []PoolCapacity{
+		{Pool: "p2", Nodes: 1, MemoryCapacity: 2048, MemoryHeadroom: 2048},
+	})
+}
+
+func (s *S) TestReportProjection(c *check.C) {
+	s.addNode(c, "http://n1:2375", "p1", "4096")
+	a := s.newApp(c, "myapp", "p1")
+	err := provisiontest.ProvisionerInstance.AddUnits(a, 4, "web", nil)
+	c.Assert(err, check.IsNil)
+	now := time.Date(2018, 6, 11, 0, 0, 0, 0, time.UTC)
+	coll, err := snapshotsCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	for _, snap := range []snapshot{
+		{Pool: "p1", Day: "2018-04-01", MemoryReserved: 0},
+		{Pool: "p1", Day: "2018-06-01", MemoryReserved: 512},
+		{Pool: "p1", Day: "2018-06-05", MemoryReserved: 768},
+	} {
+		err = coll.Insert(snap)
+		c.Assert(err, check.IsNil)
+	}
+	report, err := Report([]string{"p1"}, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.HasLen, 1)
+	growth := float64(1024-512) / 10
+	projected := int64(1024 + growth*30)
+	daysUntilFull := float64(4096-1024) / growth
+	c.Assert(report[0], check.DeepEquals, PoolCapacity{
+		Pool:                "p1",
+		Nodes:               1,
+		Units:               4,
+		MemoryCapacity:      4096,
+		MemoryReserved:      1024,
+		MemoryHeadroom:      3072,
+		ReservedGrowthDaily: &growth,
+		ProjectedReserved:   &projected,
+		DaysUntilFull:       &daysUntilFull,
+	})
+}
+
+func (s *S) TestReportProjectionWithoutHistory(c *check.C) {
+	now := time.Now().UTC()
+	err := takeSnapshot(now)
+	c.Assert(err, check.IsNil)
+	report, err := Report([]string{"p1"}, now)
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, []PoolCapacity{{Pool: "p1"}})
+}
+
+func (s *S) TestTakeSnapshot(c *check.C) {
+	s.addNode(c, "http://n1:2375", "p1", "1024")
+	a := s.newApp(c, "myapp", "p1")
+	err := provisiontest.ProvisionerInstance.AddUnits(a, 1, "web", nil)
+	c.Assert(err, check.IsNil)
+	now := time.Date(2018, 6, 11, 10, 0, 0, 0, time.UTC)
+	err = takeSnapshot(now)
+	c.Assert(err, check.IsNil)
+	err = provisiontest.ProvisionerInstance.AddUnits(a, 1, "web", nil)
+	c.Assert(err, check.IsNil)
+	err = takeSnapshot(now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	coll, err := snapshotsCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	var snaps []snapshot
+	err = coll.Find(bson.M{"pool": "p1"}).All(&snaps)
+	c.Assert(err, check.IsNil)
+	c.Assert(snaps, check.HasLen, 1)
+	c.Assert(snaps[0].Day, check.Equals, "2018-06-11")
+	c.Assert(snaps[0].MemoryReserved, check.Equals, int64(512))
+	c.Assert(snaps[0].Units, check.Equals, 2)
+}
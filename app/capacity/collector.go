@@ -0,0 +1,70 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capacity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+)
+
+const defaultCollectInterval = time.Hour
+
+func collectInterval() time.Duration {
+	seconds, _ := config.GetInt("capacity:collect-interval")
+	if seconds <= 0 {
+		return defaultCollectInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Initialize starts recording the daily history of the pools reserved
+// memory, used to project their growth.
+func Initialize() error {
+	c := &collector{once: &sync.Once{}}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type collector struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (c *collector) start() {
+	c.once.Do(func() {
+		c.stopCh = make(chan struct{})
+		go c.spin()
+	})
+}
+
+func (c *collector) Shutdown(ctx context.Context) error {
+	if c.stopCh == nil {
+		return nil
+	}
+	c.stopCh <- struct{}{}
+	c.stopCh = nil
+	c.once = &sync.Once{}
+	return nil
+}
+
+func (c *collector) spin() {
+	for {
+		err := takeSnapshot(time.Now().UTC())
+		if err != nil {
+			log.Errorf("[capacity] unable to record pools capacity: %v", err)
+		}
+		select {
+		case <-c.stopCh:
+			return
+		case <-time.After(collectInterval()):
+		}
+	}
+}
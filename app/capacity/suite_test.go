@@ -0,0 +1,114 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capacity
+
+import (
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	team        string
+	mockService struct {
+		Team *authTypes.MockTeamService
+		Plan *appTypes.MockPlanService
+	}
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_capacity_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	config.Set("docker:scheduler:total-memory-metadata", "totalMemory")
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permission.CtxGlobal, ""),
+	})
+	s.team = "myteam"
+	err := pool.AddPool(pool.AddPoolOptions{
+		Name:    "p1",
+		Default: true,
+	})
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(pool.AddPoolOptions{Name: "p2", Public: true})
+	c.Assert(err, check.IsNil)
+	s.mockService.Team = &authTypes.MockTeamService{
+		OnList: func() ([]authTypes.Team, error) {
+			return []authTypes.Team{{Name: s.team}}, nil
+		},
+		OnFindByName: func(name string) (*authTypes.Team, error) {
+			return &authTypes.Team{Name: name}, nil
+		},
+	}
+	plan := appTypes.Plan{
+		Name:     "default",
+		Default:  true,
+		CpuShare: 100,
+		Memory:   256,
+	}
+	s.mockService.Plan = &appTypes.MockPlanService{
+		OnList: func() ([]appTypes.Plan, error) {
+			return []appTypes.Plan{plan}, nil
+		},
+		OnDefaultPlan: func() (*appTypes.Plan, error) {
+			return &plan, nil
+		},
+	}
+	servicemanager.Team = s.mockService.Team
+	servicemanager.Plan = s.mockService.Plan
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	s.storage.Apps().Database.DropDatabase()
+	s.storage.Close()
+}
+
+func (s *S) newApp(c *check.C, name, poolName string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: s.team, Router: "fake", Pool: poolName}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/jobs"
+)
+
+const (
+	// CertificatesCheckJobName is the name of the job registered by
+	// RegisterCertificatesCheckJob.
+	CertificatesCheckJobName = "certificates-check"
+
+	defaultCertificateExpirationThreshold = 30 * 24 * time.Hour
+)
+
+// RegisterCertificatesCheckJob registers the job that looks for app
+// certificates expired or about to expire, failing the run when any is
+// found. It's disabled by default.
+func RegisterCertificatesCheckJob() error {
+	return jobs.Register(jobs.Job{
+		Name:        CertificatesCheckJobName,
+		Description: "reports app certificates expired or about to expire",
+		Schedule:    "@daily",
+		Disabled:    true,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			return checkCertificates(evt, time.Now().UTC())
+		},
+	})
+}
+
+func certificateExpirationThreshold() time.Duration {
+	seconds, _ := config.GetInt("certificates:expiration-threshold")
+	if seconds <= 0 {
+		return defaultCertificateExpirationThreshold
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func checkCertificates(w io.Writer, now time.Time) error {
+	apps, err := List(nil)
+	if err != nil {
+		return err
+	}
+	limit := now.Add(certificateExpirationThreshold())
+	var expiring int
+	for i := range apps {
+		certs, err := apps[i].GetCertificates()
+		if err == ErrNoTLSRouter {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(w, "unable to get certificates of app %q: %v\n", apps[i].Name, err)
+			continue
+		}
+		for routerName, routerCerts := range certs {
+			for cname, data := range routerCerts {
+				if data == "" {
+					continue
+				}
+				notAfter, err := certificateNotAfter(data)
+				if err != nil {
+					fmt.Fprintf(w, "invalid certificate for %q in router %q of app %q: %v\n", cname, routerName, apps[i].Name, err)
+					continue
+				}
+				if notAfter.Before(limit) {
+					expiring++
+					fmt.Fprintf(w, "certificate for %q in router %q of app %q expires at %s\n", cname, routerName, apps[i].Name, notAfter.Format(time.RFC3339))
+				}
+			}
+		}
+	}
+	if expiring > 0 {
+		return errors.Errorf("%d certificates expired or about to expire", expiring)
+	}
+	return nil
+}
+
+func certificateNotAfter(data string) (time.Time, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return time.Time{}, errors.New("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCheckCertificates(c *check.C) {
+	cert, err := ioutil.ReadFile("testdata/certificate.crt")
+	c.Assert(err, check.IsNil)
+	key, err := ioutil.ReadFile("testdata/private.key")
+	c.Assert(err, check.IsNil)
+	a := App{Name: "my-test-app", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}, CName: []string{"app.io"}}
+	err = CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	other := App{Name: "other-app", TeamOwner: s.team.Name}
+	err = CreateApp(&other, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetCertificate("app.io", string(cert), string(key))
+	c.Assert(err, check.IsNil)
+	notAfter, err := certificateNotAfter(string(cert))
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = checkCertificates(&buf, notAfter.Add(-60*24*time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "")
+	err = checkCertificates(&buf, notAfter.Add(-24*time.Hour))
+	c.Assert(err, check.ErrorMatches, "1 certificates expired or about to expire")
+	c.Assert(buf.String(), check.Matches, `certificate for "app.io" in router "fake-tls" of app "my-test-app" expires at .*\n`)
+}
@@ -5,6 +5,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"regexp"
@@ -13,6 +14,8 @@ import (
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/buildlog"
+	"github.com/tsuru/tsuru/app/buildqueue"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db"
@@ -22,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
 )
 
@@ -54,6 +58,8 @@ type DeployData struct {
 	CanRollback bool
 	RemoveDate  time.Time `bson:",omitempty"`
 	Diff        string
+	FailedPhase string
+	ConfigDiff  *DeployConfigDiff `bson:",omitempty" json:",omitempty"`
 }
 
 func findValidImages(apps ...App) (set.Set, error) {
@@ -129,10 +135,16 @@ func eventToDeployData(evt *event.Event, validImages set.Set, full bool) *Deploy
 	}
 	if full {
 		data.Log = evt.Log
-		var otherData map[string]string
+		var otherData struct {
+			Diff        string
+			FailedPhase string            `bson:"failedPhase"`
+			ConfigDiff  *DeployConfigDiff `bson:"configDiff"`
+		}
 		err = evt.OtherData(&otherData)
 		if err == nil {
-			data.Diff = otherData["diff"]
+			data.Diff = otherData.Diff
+			data.FailedPhase = otherData.FailedPhase
+			data.ConfigDiff = otherData.ConfigDiff
 		}
 	}
 	var endData map[string]string
@@ -244,7 +256,7 @@ func Deploy(opts DeployOptions) (string, error) {
 	logWriter.Async()
 	defer logWriter.Close()
 	opts.Event.SetLogWriter(io.MultiWriter(&tsuruIo.NoErrorWriter{Writer: opts.OutputStream}, &logWriter))
-	imageID, err := deployToProvisioner(&opts, opts.Event)
+	imageID, err := deployWithPhaseTimeouts(&opts)
 	rebuild.RoutesRebuildOrEnqueue(opts.App.Name)
 	if err != nil {
 		return "", err
@@ -253,6 +265,7 @@ func Deploy(opts DeployOptions) (string, error) {
 	if err != nil {
 		log.Errorf("WARNING: couldn't increment deploy count, deploy opts: %#v", opts)
 	}
+	recordDeployConfigDiff(opts.App, opts.Event, imageID)
 	if opts.Kind == DeployImage || opts.Kind == DeployRollback {
 		if !opts.App.UpdatePlatform {
 			opts.App.SetUpdatePlatform(true)
@@ -263,6 +276,38 @@ func Deploy(opts DeployOptions) (string, error) {
 	return imageID, nil
 }
 
+// deployWithPhaseTimeouts runs the deploy enforcing the timeouts of its
+// phases and verifies it afterwards, rolling it back when it times out or
+// fails the verification. Rollback deploys are not watched, as there's
+// nothing to roll back to.
+func deployWithPhaseTimeouts(opts *DeployOptions) (string, error) {
+	if opts.Kind == "" {
+		opts.GetKind()
+	}
+	if opts.Kind == DeployRollback {
+		return deployToProvisioner(opts, opts.Event)
+	}
+	var previousImage string
+	images, err := image.ListValidAppImages(opts.App.Name)
+	if err != nil {
+		log.Errorf("unable to list images of app %q, deploy won't be rolled back on timeout: %v", opts.App.Name, err)
+	} else if len(images) > 0 {
+		previousImage = images[len(images)-1]
+	}
+	watchdog := newPhaseWatchdog(opts.App, opts.Event)
+	imageID, err := deployToProvisioner(opts, opts.Event)
+	if timeoutErr := watchdog.stop(); timeoutErr != nil {
+		return "", rollbackTimedOutDeploy(opts, previousImage, *timeoutErr)
+	}
+	if err != nil {
+		return "", err
+	}
+	if verifyErr := verifyDeploy(opts); verifyErr != nil {
+		return "", rollbackUnverifiedDeploy(opts, previousImage, verifyErr)
+	}
+	return imageID, nil
+}
+
 func RollbackUpdate(appName, imageID, reason string, disableRollback bool) error {
 	imgName, err := image.GetAppImageBySuffix(appName, imageID)
 	if err != nil {
@@ -271,6 +316,17 @@ func RollbackUpdate(appName, imageID, reason string, disableRollback bool) error
 	return image.UpdateAppImageRollback(imgName, reason, disableRollback)
 }
 
+func warnDeprecatedPlatform(app *App, w io.Writer) {
+	platform, err := servicemanager.Platform.FindByName(app.GetPlatform())
+	if err != nil {
+		log.Errorf("unable to find platform %q for app %q: %v", app.GetPlatform(), app.Name, err)
+		return
+	}
+	if platform != nil && platform.Deprecated {
+		fmt.Fprintf(w, "\n---- WARNING: platform %q is deprecated, please migrate your app to another platform ----\n\n", platform.Name)
+	}
+}
+
 func deployToProvisioner(opts *DeployOptions, evt *event.Event) (string, error) {
 	prov, err := opts.App.getProvisioner()
 	if err != nil {
@@ -282,6 +338,9 @@ func deployToProvisioner(opts *DeployOptions, evt *event.Event) (string, error)
 	if (opts.App.GetPlatform() == "") && ((opts.Kind != DeployImage) && (opts.Kind != DeployRollback)) {
 		return "", errors.Errorf("can't deploy app without platform, if it's not an image or rollback")
 	}
+	if opts.Kind != DeployImage && opts.Kind != DeployRollback {
+		warnDeprecatedPlatform(opts.App, evt)
+	}
 
 	if opts.Kind != DeployRollback {
 		if deployer, ok := prov.(provision.BuilderDeploy); ok {
@@ -300,6 +359,7 @@ func deployToProvisioner(opts *DeployOptions, evt *event.Event) (string, error)
 }
 
 func builderDeploy(prov provision.BuilderDeploy, opts *DeployOptions, evt *event.Event) (string, error) {
+	var err error
 	isRebuild := opts.Kind == DeployRebuild
 	buildOpts := builder.BuildOpts{
 		BuildFromFile: opts.Build,
@@ -310,11 +370,40 @@ func builderDeploy(prov provision.BuilderDeploy, opts *DeployOptions, evt *event
 		ImageID:       opts.Image,
 		Tag:           opts.BuildTag,
 	}
+	buildOpts.Secrets, err = opts.App.BuildSecrets()
+	if err != nil {
+		return "", err
+	}
 	builder, err := opts.App.getBuilder()
 	if err != nil {
 		return "", err
 	}
+	ctx, cancel := evt.CancelableContext(context.Background())
+	release, err := buildqueue.Acquire(ctx, evt.UniqueID, opts.App.Name, opts.App.TeamOwner, evt)
+	cancel()
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	buildLog, err := buildlog.NewWriter(opts.App.Name, evt.UniqueID)
+	if err != nil {
+		log.Errorf("unable to start build log for app %q: %v", opts.App.Name, err)
+	} else {
+		logWriter := evt.GetLogWriter()
+		if logWriter == nil {
+			evt.SetLogWriter(buildLog)
+		} else {
+			evt.SetLogWriter(io.MultiWriter(logWriter, buildLog))
+		}
+		defer evt.SetLogWriter(logWriter)
+	}
+	provision.StartDeployPhase(evt, provision.DeployPhaseBuild)
 	img, err := builder.Build(prov, opts.App, evt, &buildOpts)
+	if buildLog != nil {
+		if closeErr := buildLog.Close(err); closeErr != nil {
+			log.Errorf("unable to finish build log for app %q: %v", opts.App.Name, closeErr)
+		}
+	}
 	if buildOpts.IsTsuruBuilderImage {
 		opts.Kind = DeployBuildedImage
 	}
@@ -322,7 +411,7 @@ func builderDeploy(prov provision.BuilderDeploy, opts *DeployOptions, evt *event
 }
 
 func ValidateOrigin(origin string) bool {
-	originList := []string{"app-deploy", "git", "rollback", "drag-and-drop", "image", "rebuild"}
+	originList := []string{"app-deploy", "git", "rollback", "drag-and-drop", "image", "rebuild", "pipeline"}
 	for _, ol := range originList {
 		if ol == origin {
 			return true
@@ -0,0 +1,166 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+)
+
+var (
+	ErrDeployWithoutImage  = errors.New("deploy has no image")
+	ErrDeployFromOtherApp  = errors.New("deploy belongs to another app")
+	ErrImageInspectMissing = errors.New("provisioner doesn't support inspecting images")
+)
+
+// ValueChange describes a value that differs between two images.
+type ValueChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// DeployDiff describes the differences between the images of two deploys of
+// an app.
+type DeployDiff struct {
+	App           string        `json:"app"`
+	From          string        `json:"from"`
+	To            string        `json:"to"`
+	FromImage     string        `json:"fromImage"`
+	ToImage       string        `json:"toImage"`
+	AddedLayers   []string      `json:"addedLayers"`
+	RemovedLayers []string      `json:"removedLayers"`
+	SizeDelta     int64         `json:"sizeDelta"`
+	Env           []ValueChange `json:"env"`
+	Labels        []ValueChange `json:"labels"`
+}
+
+// DiffDeploys compares the images generated by two deploys of an app.
+func DiffDeploys(appName, fromID, toID string) (*DeployDiff, error) {
+	from, err := GetDeploy(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := GetDeploy(toID)
+	if err != nil {
+		return nil, err
+	}
+	if from.App != appName || to.App != appName {
+		return nil, ErrDeployFromOtherApp
+	}
+	if from.Image == "" || to.Image == "" {
+		return nil, ErrDeployWithoutImage
+	}
+	a, err := GetByName(appName)
+	if err != nil {
+		return nil, err
+	}
+	prov, err := a.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	dockerProv, ok := prov.(provision.BuilderDeployDockerClient)
+	if !ok {
+		return nil, ErrImageInspectMissing
+	}
+	client, err := dockerProv.GetClient(a)
+	if err != nil {
+		return nil, err
+	}
+	fromImg, err := client.InspectImage(from.Image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to inspect image %q", from.Image)
+	}
+	toImg, err := client.InspectImage(to.Image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to inspect image %q", to.Image)
+	}
+	diff := diffImages(fromImg, toImg)
+	diff.App = a.Name
+	diff.From = fromID
+	diff.To = toID
+	diff.FromImage = from.Image
+	diff.ToImage = to.Image
+	return diff, nil
+}
+
+func diffImages(from, to *docker.Image) *DeployDiff {
+	diff := &DeployDiff{
+		SizeDelta: to.Size - from.Size,
+	}
+	fromLayers, toLayers := imageLayers(from), imageLayers(to)
+	diff.AddedLayers = subtractLayers(toLayers, fromLayers)
+	diff.RemovedLayers = subtractLayers(fromLayers, toLayers)
+	diff.Env = diffValues(imageEnv(from), imageEnv(to))
+	diff.Labels = diffValues(imageLabels(from), imageLabels(to))
+	return diff
+}
+
+func imageLayers(img *docker.Image) []string {
+	if img.RootFS == nil {
+		return nil
+	}
+	return img.RootFS.Layers
+}
+
+func subtractLayers(layers, other []string) []string {
+	otherSet := make(map[string]struct{}, len(other))
+	for _, l := range other {
+		otherSet[l] = struct{}{}
+	}
+	result := []string{}
+	for _, l := range layers {
+		if _, ok := otherSet[l]; !ok {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func imageEnv(img *docker.Image) map[string]string {
+	env := map[string]string{}
+	if img.Config == nil {
+		return env
+	}
+	for _, e := range img.Config.Env {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		} else {
+			env[parts[0]] = ""
+		}
+	}
+	return env
+}
+
+func imageLabels(img *docker.Image) map[string]string {
+	if img.Config == nil || img.Config.Labels == nil {
+		return map[string]string{}
+	}
+	return img.Config.Labels
+}
+
+func diffValues(from, to map[string]string) []ValueChange {
+	changes := []ValueChange{}
+	for name, fromValue := range from {
+		toValue, ok := to[name]
+		if !ok || toValue != fromValue {
+			changes = append(changes, ValueChange{Name: name, From: fromValue, To: toValue})
+		}
+	}
+	for name, toValue := range to {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, ValueChange{Name: name, To: toValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDiffImages(c *check.C) {
+	from := &docker.Image{
+		Size:   100,
+		RootFS: &docker.RootFS{Layers: []string{"sha256:base", "sha256:deps", "sha256:code1"}},
+		Config: &docker.Config{
+			Env:    []string{"PATH=/usr/bin", "PYTHON_VERSION=3.6", "OLD=1"},
+			Labels: map[string]string{"maintainer": "tsuru"},
+		},
+	}
+	to := &docker.Image{
+		Size:   150,
+		RootFS: &docker.RootFS{Layers: []string{"sha256:base", "sha256:deps", "sha256:code2"}},
+		Config: &docker.Config{
+			Env:    []string{"PATH=/usr/bin", "PYTHON_VERSION=3.7", "NEW"},
+			Labels: map[string]string{"maintainer": "tsuru", "version": "2"},
+		},
+	}
+	diff := diffImages(from, to)
+	c.Assert(diff, check.DeepEquals, &DeployDiff{
+		AddedLayers:   []string{"sha256:code2"},
+		RemovedLayers: []string{"sha256:code1"},
+		SizeDelta:     50,
+		Env: []ValueChange{
+			{Name: "NEW"},
+			{Name: "OLD", From: "1"},
+			{Name: "PYTHON_VERSION", From: "3.6", To: "3.7"},
+		},
+		Labels: []ValueChange{
+			{Name: "version", To: "2"},
+		},
+	})
+}
+
+func (s *S) TestDiffImagesWithoutConfig(c *check.C) {
+	diff := diffImages(&docker.Image{Size: 10}, &docker.Image{Size: 5})
+	c.Assert(diff, check.DeepEquals, &DeployDiff{
+		AddedLayers:   []string{},
+		RemovedLayers: []string{},
+		SizeDelta:     -5,
+		Env:           []ValueChange{},
+		Labels:        []ValueChange{},
+	})
+}
+
+func (s *S) TestDiffDeploysFromOtherApp(c *check.C) {
+	var ids []string
+	for _, name := range []string{"myapp", "otherapp"} {
+		evt, err := event.New(&event.Opts{
+			Target:   event.Target{Type: "app", Value: name},
+			Kind:     permission.PermAppDeploy,
+			RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+			Allowed:  event.Allowed(permission.PermApp),
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.DoneCustomData(nil, map[string]string{"image": "tsuru/app-" + name + ":v1"})
+		c.Assert(err, check.IsNil)
+		ids = append(ids, evt.UniqueID.Hex())
+	}
+	_, err := DiffDeploys("myapp", ids[0], ids[1])
+	c.Assert(err, check.Equals, ErrDeployFromOtherApp)
+}
+
+func (s *S) TestDiffDeploysWithoutImage(c *check.C) {
+	var ids []string
+	for i := 0; i < 2; i++ {
+		evt, err := event.New(&event.Opts{
+			Target:   event.Target{Type: "app", Value: "myapp"},
+			Kind:     permission.PermAppDeploy,
+			RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+			Allowed:  event.Allowed(permission.PermApp),
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.Done(nil)
+		c.Assert(err, check.IsNil)
+		ids = append(ids, evt.UniqueID.Hex())
+	}
+	_, err := DiffDeploys("myapp", ids[0], ids[1])
+	c.Assert(err, check.Equals, ErrDeployWithoutImage)
+}
@@ -0,0 +1,300 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const privateEnvValue = "*** (private variable)"
+
+// DeployConfigDiff describes the configuration changes of an app between a
+// deploy and the previous successful deploy of the app. Values of private
+// env vars are never exposed.
+type DeployConfigDiff struct {
+	Previous  bson.ObjectId `json:"previous,omitempty" bson:",omitempty"`
+	Env       []ValueChange `json:"env"`
+	Plan      []ValueChange `json:"plan"`
+	Units     []ValueChange `json:"units"`
+	TsuruYaml []ValueChange `json:"tsuruYaml" bson:"tsuruyaml"`
+}
+
+// Empty returns whether the diff has no changes.
+func (d *DeployConfigDiff) Empty() bool {
+	return len(d.Env) == 0 && len(d.Plan) == 0 && len(d.Units) == 0 && len(d.TsuruYaml) == 0
+}
+
+// deploySnapshot is the configuration of an app right after a successful
+// deploy. Values of private env vars are stored as hashes, which are enough
+// to detect changes.
+type deploySnapshot struct {
+	Deploy    bson.ObjectId `bson:"_id"`
+	App       string
+	Timestamp time.Time
+	Env       []snapshotEnv
+	Plan      map[string]string
+	Units     []snapshotUnits
+	TsuruYaml provision.TsuruYamlData
+}
+
+type snapshotEnv struct {
+	Name    string
+	Value   string
+	Private bool
+}
+
+type snapshotUnits struct {
+	Process string
+	Units   int
+}
+
+func deploySnapshotsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_deploy_snapshots")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "-timestamp"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func takeDeploySnapshot(a *App, deployID bson.ObjectId, imageID string) (*deploySnapshot, error) {
+	snapshot := deploySnapshot{
+		Deploy:    deployID,
+		App:       a.Name,
+		Timestamp: time.Now().UTC(),
+		Plan: map[string]string{
+			"name":     a.Plan.Name,
+			"memory":   strconv.FormatInt(a.Plan.Memory, 10),
+			"swap":     strconv.FormatInt(a.Plan.Swap, 10),
+			"cpushare": strconv.Itoa(a.Plan.CpuShare),
+		},
+	}
+	for name, env := range a.Envs() {
+		value := env.Value
+		if !env.Public {
+			value = fmt.Sprintf("%x", sha256.Sum256([]byte(a.Name+"\x00"+value)))
+		}
+		snapshot.Env = append(snapshot.Env, snapshotEnv{Name: name, Value: value, Private: !env.Public})
+	}
+	units, err := a.Units()
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, u := range units {
+		counts[u.ProcessName]++
+	}
+	for process, count := range counts {
+		snapshot.Units = append(snapshot.Units, snapshotUnits{Process: process, Units: count})
+	}
+	snapshot.TsuruYaml, err = image.GetImageTsuruYamlData(imageID)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func lastDeploySnapshot(appName string, except bson.ObjectId) (*deploySnapshot, error) {
+	coll, err := deploySnapshotsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var snapshot deploySnapshot
+	err = coll.Find(bson.M{"app": appName, "_id": bson.M{"$ne": except}}).Sort("-timestamp").One(&snapshot)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func removeDeploySnapshots(appName string) error {
+	coll, err := deploySnapshotsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.RemoveAll(bson.M{"app": appName})
+	return err
+}
+
+func diffDeploySnapshots(from, to *deploySnapshot) *DeployConfigDiff {
+	if from == nil {
+		from = &deploySnapshot{}
+	}
+	diff := &DeployConfigDiff{Previous: from.Deploy}
+	fromEnv, toEnv := map[string]string{}, map[string]string{}
+	private := map[string]bool{}
+	for _, e := range from.Env {
+		fromEnv[e.Name] = e.Value
+		private[e.Name] = private[e.Name] || e.Private
+	}
+	for _, e := range to.Env {
+		toEnv[e.Name] = e.Value
+		private[e.Name] = private[e.Name] || e.Private
+	}
+	diff.Env = diffValues(fromEnv, toEnv)
+	for i, change := range diff.Env {
+		if !private[change.Name] {
+			continue
+		}
+		if change.From != "" {
+			diff.Env[i].From = privateEnvValue
+		}
+		if change.To != "" {
+			diff.Env[i].To = privateEnvValue
+		}
+	}
+	if from.Plan == nil {
+		from.Plan = map[string]string{}
+	}
+	diff.Plan = diffValues(from.Plan, to.Plan)
+	diff.Units = diffValues(unitsValues(from.Units), unitsValues(to.Units))
+	diff.TsuruYaml = diffValues(tsuruYamlValues(from.TsuruYaml), tsuruYamlValues(to.TsuruYaml))
+	return diff
+}
+
+func unitsValues(units []snapshotUnits) map[string]string {
+	values := map[string]string{}
+	for _, u := range units {
+		values[u.Process] = strconv.Itoa(u.Units)
+	}
+	return values
+}
+
+// tsuruYamlValues flattens the tsuru.yaml data in a map keyed by the path of
+// each value, like healthcheck.path.
+func tsuruYamlValues(data provision.TsuruYamlData) map[string]string {
+	values := map[string]string{}
+	raw, err := yaml.Marshal(data)
+	if err != nil {
+		return values
+	}
+	var tree map[interface{}]interface{}
+	err = yaml.Unmarshal(raw, &tree)
+	if err != nil {
+		return values
+	}
+	flattenYaml("", tree, values)
+	return values
+}
+
+func flattenYaml(prefix string, value interface{}, values map[string]string) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			key := fmt.Sprint(k)
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenYaml(key, item, values)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return
+		}
+		encoded, _ := json.Marshal(v)
+		values[prefix] = string(encoded)
+	case nil:
+	default:
+		str := fmt.Sprint(v)
+		if str != "" && str != "0" && str != "false" {
+			values[prefix] = str
+		}
+	}
+}
+
+// recordDeployConfigDiff stores the configuration of the app after a
+// successful deploy and attaches to the deploy event the changes since the
+// previous deploy, also writing a summary of them to the deploy output.
+// Failures are only logged, as they must not fail the deploy.
+func recordDeployConfigDiff(a *App, evt *event.Event, imageID string) {
+	dbApp, err := GetByName(a.Name)
+	if err != nil {
+		log.Errorf("[deploy diff] unable to get app %q: %v", a.Name, err)
+		return
+	}
+	snapshot, err := takeDeploySnapshot(dbApp, evt.UniqueID, imageID)
+	if err != nil {
+		log.Errorf("[deploy diff] unable to take snapshot of app %q: %v", a.Name, err)
+		return
+	}
+	previous, err := lastDeploySnapshot(a.Name, evt.UniqueID)
+	if err != nil {
+		log.Errorf("[deploy diff] unable to get previous snapshot of app %q: %v", a.Name, err)
+		return
+	}
+	coll, err := deploySnapshotsCollection()
+	if err != nil {
+		log.Errorf("[deploy diff] unable to store snapshot of app %q: %v", a.Name, err)
+		return
+	}
+	defer coll.Close()
+	_, err = coll.UpsertId(snapshot.Deploy, snapshot)
+	if err != nil {
+		log.Errorf("[deploy diff] unable to store snapshot of app %q: %v", a.Name, err)
+		return
+	}
+	diff := diffDeploySnapshots(previous, snapshot)
+	err = evt.SetOtherCustomDataField("configDiff", diff)
+	if err != nil {
+		log.Errorf("[deploy diff] unable to record config diff in event %s: %v", evt.UniqueID.Hex(), err)
+	}
+	if previous != nil {
+		writeDeployConfigDiff(evt, diff)
+	}
+}
+
+func writeDeployConfigDiff(w io.Writer, diff *DeployConfigDiff) {
+	if diff.Empty() {
+		return
+	}
+	fmt.Fprintln(w, "\n---- Changes since the previous deploy ----")
+	sections := []struct {
+		name    string
+		changes []ValueChange
+	}{
+		{"env", diff.Env},
+		{"plan", diff.Plan},
+		{"units", diff.Units},
+		{"tsuru.yaml", diff.TsuruYaml},
+	}
+	for _, section := range sections {
+		if len(section.changes) == 0 {
+			continue
+		}
+		names := make([]string, len(section.changes))
+		for i, change := range section.changes {
+			names[i] = change.Name
+		}
+		sort.Strings(names)
+		fmt.Fprintf(w, " ---> %s: %s\n", section.name, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDiffDeploySnapshots(c *check.C) {
+	from := &deploySnapshot{
+		Deploy: bson.NewObjectId(),
+		Env: []snapshotEnv{
+			{Name: "DATABASE", Value: "mysql"},
+			{Name: "PASSWORD", Value: "hash1", Private: true},
+			{Name: "OLD", Value: "1"},
+		},
+		Plan:  map[string]string{"name": "small", "memory": "512", "swap": "0", "cpushare": "100"},
+		Units: []snapshotUnits{{Process: "web", Units: 1}},
+		TsuruYaml: provision.TsuruYamlData{
+			Healthcheck: provision.TsuruYamlHealthcheck{Path: "/"},
+		},
+	}
+	to := &deploySnapshot{
+		Deploy: bson.NewObjectId(),
+		Env: []snapshotEnv{
+			{Name: "DATABASE", Value: "postgres"},
+			{Name: "PASSWORD", Value: "hash2", Private: true},
+			{Name: "TOKEN", Value: "hash3", Private: true},
+		},
+		Plan:  map[string]string{"name": "large", "memory": "1024", "swap": "0", "cpushare": "100"},
+		Units: []snapshotUnits{{Process: "web", Units: 2}, {Process: "worker", Units: 1}},
+		TsuruYaml: provision.TsuruYamlData{
+			Hooks:       provision.TsuruYamlHooks{Build: []string{"make"}},
+			Healthcheck: provision.TsuruYamlHealthcheck{Path: "/healthcheck", Status: 200},
+		},
+	}
+	diff := diffDeploySnapshots(from, to)
+	c.Assert(diff, check.DeepEquals, &DeployConfigDiff{
+		Previous: from.Deploy,
+		Env: []ValueChange{
+			{Name: "DATABASE", From: "mysql", To: "postgres"},
+			{Name: "OLD", From: "1"},
+			{Name: "PASSWORD", From: privateEnvValue, To: privateEnvValue},
+			{Name: "TOKEN", To: privateEnvValue},
+		},
+		Plan: []ValueChange{
+			{Name: "memory", From: "512", To: "1024"},
+			{Name: "name", From: "small", To: "large"},
+		},
+		Units: []ValueChange{
+			{Name: "web", From: "1", To: "2"},
+			{Name: "worker", To: "1"},
+		},
+		TsuruYaml: []ValueChange{
+			{Name: "healthcheck.path", From: "/", To: "/healthcheck"},
+			{Name: "healthcheck.status", To: "200"},
+			{Name: "hooks.build", To: `["make"]`},
+		},
+	})
+	c.Assert(diff.Empty(), check.Equals, false)
+}
+
+func (s *S) TestDiffDeploySnapshotsWithoutChanges(c *check.C) {
+	snapshot := &deploySnapshot{
+		Deploy: bson.NewObjectId(),
+		Env:    []snapshotEnv{{Name: "PASSWORD", Value: "hash1", Private: true}},
+		Plan:   map[string]string{"name": "small"},
+		Units:  []snapshotUnits{{Process: "web", Units: 1}},
+	}
+	diff := diffDeploySnapshots(snapshot, snapshot)
+	c.Assert(diff.Empty(), check.Equals, true)
+}
+
+func (s *S) TestWriteDeployConfigDiff(c *check.C) {
+	var buf bytes.Buffer
+	writeDeployConfigDiff(&buf, &DeployConfigDiff{
+		Env:   []ValueChange{{Name: "B", To: "1"}, {Name: "A", From: "2"}},
+		Units: []ValueChange{{Name: "web", From: "1", To: "2"}},
+	})
+	c.Assert(buf.String(), check.Equals, "\n---- Changes since the previous deploy ----\n ---> env: A, B\n ---> units: web\n")
+	buf.Reset()
+	writeDeployConfigDiff(&buf, &DeployConfigDiff{})
+	c.Assert(buf.String(), check.Equals, "")
+}
+
+func (s *S) TestDeployRecordsConfigDiff(c *check.C) {
+	a := App{
+		Name:      "otherapp",
+		Platform:  "zend",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	deploy := func() (*event.Event, string) {
+		evt, err := event.New(&event.Opts{
+			Target:   event.Target{Type: "app", Value: a.Name},
+			Kind:     permission.PermAppDeploy,
+			RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+			Allowed:  event.Allowed(permission.PermApp),
+		})
+		c.Assert(err, check.IsNil)
+		writer := &bytes.Buffer{}
+		_, err = Deploy(DeployOptions{
+			App:          &a,
+			Image:        "myimage",
+			OutputStream: writer,
+			Event:        evt,
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.Done(nil)
+		c.Assert(err, check.IsNil)
+		return evt, writer.String()
+	}
+	firstEvt, output := deploy()
+	c.Assert(output, check.Not(check.Matches), "(?s).*Changes since the previous deploy.*")
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{
+			{Name: "DATABASE", Value: "postgres", Public: true},
+			{Name: "PASSWORD", Value: "secret", Public: false},
+		},
+		ShouldRestart: false,
+	})
+	c.Assert(err, check.IsNil)
+	secondEvt, output := deploy()
+	c.Assert(output, check.Matches, "(?s).*Changes since the previous deploy.*env: DATABASE, PASSWORD.*")
+	data, err := GetDeploy(secondEvt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(data.ConfigDiff, check.NotNil)
+	c.Assert(data.ConfigDiff.Previous, check.Equals, firstEvt.UniqueID)
+	c.Assert(data.ConfigDiff.Env, check.DeepEquals, []ValueChange{
+		{Name: "DATABASE", To: "postgres"},
+		{Name: "PASSWORD", To: privateEnvValue},
+	})
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDelete,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = Delete(&a, evt, "")
+	c.Assert(err, check.IsNil)
+	snapshot, err := lastDeploySnapshot(a.Name, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshot, check.IsNil)
+}
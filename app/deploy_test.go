@@ -8,12 +8,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/app/buildlog"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/builder"
@@ -21,6 +23,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	"gopkg.in/check.v1"
 )
@@ -285,6 +288,48 @@ func (s *S) TestBuildApp(c *check.C) {
 	c.Assert(imgID, check.Equals, "registry.somewhere/"+a.TeamOwner+"/app-some-app:v1-builder")
 }
 
+func (s *S) TestBuildAppStoresBuildLog(c *check.C) {
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (string, error) {
+		fmt.Fprint(evt, "building image")
+		return "registry.somewhere/" + s.team.Name + "/app-some-app:v1-builder", nil
+	}
+	a := App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	buf := strings.NewReader("my file")
+	writer := &bytes.Buffer{}
+	_, err = Deploy(DeployOptions{
+		App:          &a,
+		OutputStream: writer,
+		File:         ioutil.NopCloser(buf),
+		FileSize:     int64(buf.Len()),
+		Event:        evt,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(writer.String(), check.Equals, "building imageBuilder deploy called")
+	buildLog, err := buildlog.Get(evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(buildLog.App, check.Equals, a.Name)
+	c.Assert(buildLog.Finished, check.Equals, true)
+	entries, err := buildLog.Entries("")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(string(entries[0].Data), check.Equals, "building image")
+}
+
 func (s *S) TestDeployAppUpload(c *check.C) {
 	a := App{
 		Name:      "some-app",
@@ -319,6 +364,39 @@ func (s *S) TestDeployAppUpload(c *check.C) {
 	c.Assert(updatedApp.UpdatePlatform, check.Equals, false)
 }
 
+func (s *S) TestDeployAppDeprecatedPlatform(c *check.C) {
+	s.mockService.Platform.OnFindByName = func(name string) (*appTypes.Platform, error) {
+		return &appTypes.Platform{Name: name, Deprecated: true}, nil
+	}
+	a := App{
+		Name:      "some-app",
+		Platform:  "django",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	buf := strings.NewReader("my file")
+	writer := &bytes.Buffer{}
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = Deploy(DeployOptions{
+		App:          &a,
+		File:         ioutil.NopCloser(buf),
+		FileSize:     int64(buf.Len()),
+		OutputStream: writer,
+		Event:        evt,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(writer.String(), check.Matches, `(?s).*WARNING: platform "django" is deprecated.*Builder deploy called`)
+}
+
 func (s *S) TestDeployAppImage(c *check.C) {
 	a := App{
 		Name:      "some-app",
@@ -642,6 +720,7 @@ func (s *S) TestValidateOrigin(c *check.C) {
 	c.Assert(ValidateOrigin("rollback"), check.Equals, true)
 	c.Assert(ValidateOrigin("drag-and-drop"), check.Equals, true)
 	c.Assert(ValidateOrigin("image"), check.Equals, true)
+	c.Assert(ValidateOrigin("pipeline"), check.Equals, true)
 	c.Assert(ValidateOrigin("invalid"), check.Equals, false)
 }
 
@@ -0,0 +1,246 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const EventKindDeployTimeoutRollback = "deploy-timeout-rollback"
+
+// ErrDeployPhaseTimeout is returned when a phase of a deploy takes longer
+// than the timeout configured for it.
+type ErrDeployPhaseTimeout struct {
+	Phase   provision.DeployPhase
+	Timeout time.Duration
+}
+
+func (e ErrDeployPhaseTimeout) Error() string {
+	return fmt.Sprintf("deploy phase %q exceeded its timeout of %v", e.Phase, e.Timeout)
+}
+
+func validDeployPhase(phase string) bool {
+	for _, p := range provision.DeployPhases {
+		if string(p) == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDeployTimeouts sets the timeouts, in seconds, of the deploy phases of
+// the app, overriding the ones configured for its pool. A timeout of 0
+// removes the override.
+func (app *App) SetDeployTimeouts(timeouts map[string]int) error {
+	update := bson.M{}
+	unset := bson.M{}
+	for phase, seconds := range timeouts {
+		if !validDeployPhase(phase) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid deploy phase %q", phase)}
+		}
+		if seconds < 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid timeout for deploy phase %q, it must not be negative", phase)}
+		}
+		if seconds == 0 {
+			unset["deploytimeouts."+phase] = ""
+		} else {
+			update["deploytimeouts."+phase] = seconds
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	query := bson.M{}
+	if len(update) > 0 {
+		query["$set"] = update
+	}
+	if len(unset) > 0 {
+		query["$unset"] = unset
+	}
+	if len(query) == 0 {
+		return nil
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, query)
+	if err != nil {
+		return err
+	}
+	if app.DeployTimeouts == nil {
+		app.DeployTimeouts = map[string]int{}
+	}
+	for phase, seconds := range timeouts {
+		if seconds == 0 {
+			delete(app.DeployTimeouts, phase)
+		} else {
+			app.DeployTimeouts[phase] = seconds
+		}
+	}
+	return nil
+}
+
+// DeployPhaseTimeout returns the maximum duration of a phase of the deploys
+// of the app. The timeout set in the app takes precedence over the one
+// configured for its pool, which takes precedence over the global one. A zero
+// duration means no timeout.
+func (app *App) DeployPhaseTimeout(phase provision.DeployPhase) time.Duration {
+	seconds, ok := app.DeployTimeouts[string(phase)]
+	if !ok {
+		var err error
+		seconds, err = config.GetInt(fmt.Sprintf("deploy:pools:%s:timeouts:%s", app.Pool, phase))
+		if err != nil {
+			seconds, _ = config.GetInt("deploy:timeouts:" + string(phase))
+		}
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// phaseWatchdog enforces the phase timeouts of a deploy. Once a phase times
+// out the deploy event is canceled, so provisioners stop at the next
+// cancellation check, and the deploy is considered failed even if it ends up
+// finishing.
+type phaseWatchdog struct {
+	app      *App
+	evt      *event.Event
+	mu       sync.Mutex
+	timer    *time.Timer
+	timedOut *ErrDeployPhaseTimeout
+	unwatch  func()
+}
+
+func newPhaseWatchdog(app *App, evt *event.Event) *phaseWatchdog {
+	w := &phaseWatchdog{app: app, evt: evt}
+	w.unwatch = provision.WatchDeployPhases(evt, w.start)
+	return w
+}
+
+func (w *phaseWatchdog) start(phase provision.DeployPhase) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.timedOut != nil {
+		return
+	}
+	timeout := w.app.DeployPhaseTimeout(phase)
+	if timeout <= 0 {
+		return
+	}
+	w.timer = time.AfterFunc(timeout, func() {
+		w.expire(ErrDeployPhaseTimeout{Phase: phase, Timeout: timeout})
+	})
+}
+
+func (w *phaseWatchdog) expire(timeoutErr ErrDeployPhaseTimeout) {
+	w.mu.Lock()
+	if w.timedOut != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.timedOut = &timeoutErr
+	w.mu.Unlock()
+	// The deploy is still using its own event instance, a fresh copy is used
+	// to request the cancellation.
+	evt, err := event.GetByID(w.evt.UniqueID)
+	if err == nil {
+		err = evt.TryCancel(timeoutErr.Error(), "tsuru")
+	}
+	if err != nil && err != event.ErrNotCancelable {
+		log.Errorf("[deploy timeout] unable to cancel deploy of app %q: %v", w.app.Name, err)
+	}
+}
+
+// stop stops watching the deploy phases, returning the phase timeout error
+// if any phase timed out.
+func (w *phaseWatchdog) stop() *ErrDeployPhaseTimeout {
+	w.unwatch()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	return w.timedOut
+}
+
+func recordFailedPhase(evt *event.Event, phase provision.DeployPhase) {
+	err := evt.SetOtherCustomDataField("failedPhase", string(phase))
+	if err != nil {
+		log.Errorf("[deploy timeout] unable to record failed phase in event %s: %v", evt.UniqueID.Hex(), err)
+	}
+}
+
+// rollbackTimedOutDeploy records the phase that timed out in the deploy event
+// and deploys the image running before the deploy started, returning the
+// error to be used as the result of the deploy.
+func rollbackTimedOutDeploy(opts *DeployOptions, previousImage string, timeoutErr ErrDeployPhaseTimeout) error {
+	recordFailedPhase(opts.Event, timeoutErr.Phase)
+	return rollbackFailedDeploy(opts, previousImage, timeoutErr, EventKindDeployTimeoutRollback, map[string]string{
+		"phase": string(timeoutErr.Phase),
+	})
+}
+
+// rollbackFailedDeploy deploys the image running before the deploy started,
+// in an internal event of the given kind, returning cause combined with any
+// error found during the rollback.
+func rollbackFailedDeploy(opts *DeployOptions, previousImage string, cause error, kind string, data map[string]string) error {
+	evt := opts.Event
+	if previousImage == "" {
+		return cause
+	}
+	prov, err := opts.App.getProvisioner()
+	if err != nil {
+		return tsuruErrors.NewMultiError(cause, err)
+	}
+	deployer, ok := prov.(provision.RollbackableDeployer)
+	if !ok {
+		return cause
+	}
+	fmt.Fprintf(evt, "\n**** %s, ROLLING BACK TO %s ****\n", cause, previousImage)
+	a := opts.App
+	customData := map[string]string{
+		"deploy": evt.UniqueID.Hex(),
+		"image":  previousImage,
+	}
+	for k, v := range data {
+		customData[k] = v
+	}
+	rollbackEvt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		InternalKind: kind,
+		CustomData:   customData,
+		DisableLock:  true,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, a.Teams),
+			permission.Context(permission.CtxApp, a.Name),
+			permission.Context(permission.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		return tsuruErrors.NewMultiError(cause, err)
+	}
+	rollbackEvt.SetLogWriter(evt)
+	_, err = deployer.Rollback(a, previousImage, rollbackEvt)
+	rollbackEvt.Done(err)
+	if err != nil {
+		fmt.Fprintf(evt, "\n**** ERROR DURING ROLLBACK ****\n ---> %s <---\n", err)
+		return tsuruErrors.NewMultiError(cause, err)
+	}
+	return cause
+}
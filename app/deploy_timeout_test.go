@@ -0,0 +1,96 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDeployPhaseTimeout(c *check.C) {
+	config.Set("deploy:timeouts:build", 600)
+	config.Set("deploy:timeouts:healthcheck", 60)
+	config.Set("deploy:pools:pool1:timeouts:build", 300)
+	defer config.Unset("deploy")
+	a := App{Name: "myapp", Pool: "pool1", DeployTimeouts: map[string]int{"healthcheck": 30}}
+	c.Assert(a.DeployPhaseTimeout(provision.DeployPhaseBuild), check.Equals, 5*time.Minute)
+	c.Assert(a.DeployPhaseTimeout(provision.DeployPhaseHealthcheck), check.Equals, 30*time.Second)
+	c.Assert(a.DeployPhaseTimeout(provision.DeployPhaseUnitStart), check.Equals, time.Duration(0))
+	a.Pool = "pool2"
+	c.Assert(a.DeployPhaseTimeout(provision.DeployPhaseBuild), check.Equals, 10*time.Minute)
+}
+
+func (s *S) TestSetDeployTimeouts(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployTimeouts(map[string]int{"build": 300, "healthcheck": 60})
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployTimeouts(map[string]int{"healthcheck": 0})
+	c.Assert(err, check.IsNil)
+	c.Assert(a.DeployTimeouts, check.DeepEquals, map[string]int{"build": 300})
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployTimeouts, check.DeepEquals, map[string]int{"build": 300})
+}
+
+func (s *S) TestSetDeployTimeoutsInvalid(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployTimeouts(map[string]int{"compile": 300})
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+	err = a.SetDeployTimeouts(map[string]int{"build": -1})
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+}
+
+func (s *S) TestPhaseWatchdogTimeout(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, DeployTimeouts: map[string]int{"build": 1}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		Kind:       permission.PermAppDeploy,
+		RawOwner:   event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Cancelable: true,
+		Allowed:    event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	watchdog := newPhaseWatchdog(&a, evt)
+	provision.StartDeployPhase(evt, provision.DeployPhaseBuild)
+	timeout := time.After(5 * time.Second)
+	for {
+		canceled, ackErr := evt.AckCancel()
+		c.Assert(ackErr, check.IsNil)
+		if canceled {
+			break
+		}
+		select {
+		case <-timeout:
+			c.Fatal("timeout waiting for deploy to be canceled")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	timeoutErr := watchdog.stop()
+	c.Assert(timeoutErr, check.DeepEquals, &ErrDeployPhaseTimeout{Phase: provision.DeployPhaseBuild, Timeout: time.Second})
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestPhaseWatchdogNoTimeout(c *check.C) {
+	a := App{Name: "myapp", DeployTimeouts: map[string]int{"build": 1}}
+	evt := &event.Event{}
+	watchdog := newPhaseWatchdog(&a, evt)
+	provision.StartDeployPhase(evt, provision.DeployPhaseBuild)
+	provision.StartDeployPhase(evt, provision.DeployPhaseUnitStart)
+	time.Sleep(1500 * time.Millisecond)
+	c.Assert(watchdog.stop(), check.IsNil)
+}
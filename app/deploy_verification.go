@@ -0,0 +1,293 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/router"
+)
+
+const (
+	EventKindDeployVerificationRollback = "deploy-verification-rollback"
+
+	deployVerificationPhase           = "verification"
+	defaultDeployVerificationInterval = 10 * time.Second
+)
+
+// DeployVerification holds the settings of the verification phase run after
+// the deploys of an app. During Duration seconds the error rate and the
+// average latency of the app are watched and the deploy is rolled back once
+// any of them exceeds its threshold. Metrics are reported by the routers of
+// the app or, when Endpoint is set, by the app itself.
+type DeployVerification struct {
+	Duration     int     `json:"duration"`
+	MaxErrorRate float64 `json:"maxErrorRate"`
+	MaxLatency   int     `json:"maxLatency"`
+	MinRequests  uint64  `json:"minRequests"`
+	Endpoint     string  `json:"endpoint,omitempty"`
+}
+
+// ErrDeployVerificationFailed is returned when the metrics of an app breach
+// the thresholds of its deploy verification.
+type ErrDeployVerificationFailed struct {
+	Reason string
+}
+
+func (e ErrDeployVerificationFailed) Error() string {
+	return fmt.Sprintf("deploy verification failed: %s", e.Reason)
+}
+
+// Validate checks whether the duration and thresholds are valid and the
+// endpoint, when set, is an http or https URL.
+func (v *DeployVerification) Validate() error {
+	if v.Duration <= 0 {
+		return &tsuruErrors.ValidationError{Message: "Invalid verification duration, it must be a positive number of seconds"}
+	}
+	if v.MaxErrorRate < 0 || v.MaxErrorRate > 100 {
+		return &tsuruErrors.ValidationError{Message: "Invalid max error rate, it must be a percentage between 0 and 100"}
+	}
+	if v.MaxLatency < 0 {
+		return &tsuruErrors.ValidationError{Message: "Invalid max latency, it must not be negative"}
+	}
+	if v.MaxErrorRate == 0 && v.MaxLatency == 0 {
+		return &tsuruErrors.ValidationError{Message: "You must set the max error rate or the max latency"}
+	}
+	if v.Endpoint != "" {
+		u, err := url.Parse(v.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid verification endpoint %q, it must be an http or https URL", v.Endpoint)}
+		}
+	}
+	return nil
+}
+
+// SetDeployVerification sets the verification run after the deploys of the
+// app. A nil verification disables it.
+func (app *App) SetDeployVerification(v *DeployVerification) error {
+	if v != nil {
+		err := v.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var update bson.M
+	if v == nil {
+		update = bson.M{"$unset": bson.M{"deployverification": ""}}
+	} else {
+		update = bson.M{"$set": bson.M{"deployverification": v}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.DeployVerification = v
+	return nil
+}
+
+func deployVerificationInterval() time.Duration {
+	seconds, _ := config.GetInt("deploy:verification:interval")
+	if seconds <= 0 {
+		return defaultDeployVerificationInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// verificationMeasure is the error rate, as a percentage, and the average
+// latency of the requests received by an app.
+type verificationMeasure struct {
+	Requests  uint64
+	ErrorRate float64
+	Latency   time.Duration
+}
+
+// endpointMeasure is the body expected from verification endpoints, with the
+// latency in milliseconds.
+type endpointMeasure struct {
+	Requests  uint64  `json:"requests"`
+	ErrorRate float64 `json:"errorRate"`
+	Latency   float64 `json:"latency"`
+}
+
+// deployVerifier measures the traffic of an app since the verification
+// started, either from the counters of its routers or from the app endpoint.
+type deployVerifier struct {
+	app      *App
+	routers  []router.MetricsRouter
+	baseline router.BackendMetrics
+}
+
+func newDeployVerifier(app *App) (*deployVerifier, error) {
+	v := &deployVerifier{app: app}
+	if app.DeployVerification.Endpoint != "" {
+		return v, nil
+	}
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			return nil, err
+		}
+		if metricsRouter, ok := r.(router.MetricsRouter); ok {
+			v.routers = append(v.routers, metricsRouter)
+		}
+	}
+	if len(v.routers) == 0 {
+		return nil, nil
+	}
+	var err error
+	v.baseline, err = v.routerMetrics()
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *deployVerifier) routerMetrics() (router.BackendMetrics, error) {
+	var total router.BackendMetrics
+	for _, r := range v.routers {
+		metrics, err := r.BackendMetrics(v.app.Name)
+		if err != nil {
+			return total, err
+		}
+		total.Requests += metrics.Requests
+		total.Errors += metrics.Errors
+		total.TotalLatency += metrics.TotalLatency
+	}
+	return total, nil
+}
+
+func (v *deployVerifier) measure() (verificationMeasure, error) {
+	if v.app.DeployVerification.Endpoint != "" {
+		return v.endpointMeasure()
+	}
+	current, err := v.routerMetrics()
+	if err != nil {
+		return verificationMeasure{}, err
+	}
+	var m verificationMeasure
+	if current.Requests < v.baseline.Requests {
+		// Counters were reset by the router, the verification starts over.
+		v.baseline = current
+		return m, nil
+	}
+	m.Requests = current.Requests - v.baseline.Requests
+	if m.Requests == 0 {
+		return m, nil
+	}
+	m.ErrorRate = float64(current.Errors-v.baseline.Errors) * 100 / float64(m.Requests)
+	m.Latency = (current.TotalLatency - v.baseline.TotalLatency) / time.Duration(m.Requests)
+	return m, nil
+}
+
+func (v *deployVerifier) endpointMeasure() (verificationMeasure, error) {
+	rsp, err := tsuruNet.Dial5Full60ClientNoKeepAlive.Get(v.app.DeployVerification.Endpoint)
+	if err != nil {
+		return verificationMeasure{}, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return verificationMeasure{}, errors.Errorf("verification endpoint returned status %d", rsp.StatusCode)
+	}
+	var data endpointMeasure
+	err = json.NewDecoder(rsp.Body).Decode(&data)
+	if err != nil {
+		return verificationMeasure{}, errors.Wrap(err, "invalid response from verification endpoint")
+	}
+	return verificationMeasure{
+		Requests:  data.Requests,
+		ErrorRate: data.ErrorRate,
+		Latency:   time.Duration(data.Latency * float64(time.Millisecond)),
+	}, nil
+}
+
+// breach returns the reason why the measure breaches the verification
+// thresholds, or an empty string if it doesn't. Measures with fewer requests
+// than the minimum are not considered.
+func (v *DeployVerification) breach(m verificationMeasure) string {
+	if m.Requests < v.MinRequests {
+		return ""
+	}
+	if v.MaxErrorRate > 0 && m.ErrorRate > v.MaxErrorRate {
+		return fmt.Sprintf("error rate of %.2f%% exceeds the maximum of %.2f%%", m.ErrorRate, v.MaxErrorRate)
+	}
+	maxLatency := time.Duration(v.MaxLatency) * time.Millisecond
+	if maxLatency > 0 && m.Latency > maxLatency {
+		return fmt.Sprintf("average latency of %v exceeds the maximum of %v", m.Latency, maxLatency)
+	}
+	return ""
+}
+
+// verifyDeploy watches the metrics of the app during the verification
+// duration, returning ErrDeployVerificationFailed as soon as they breach the
+// thresholds. Errors reaching the app endpoint are considered breaches, as
+// the app is expected to answer it.
+func verifyDeploy(opts *DeployOptions) error {
+	a := opts.App
+	verification := a.DeployVerification
+	if verification == nil || opts.Kind == DeployRollback {
+		return nil
+	}
+	evt := opts.Event
+	verifier, err := newDeployVerifier(a)
+	if err != nil {
+		log.Errorf("[deploy verification] unable to verify deploy of app %q: %v", a.Name, err)
+		fmt.Fprintf(evt, "\n---- WARNING: unable to verify deploy: %v ----\n", err)
+		return nil
+	}
+	if verifier == nil {
+		fmt.Fprintln(evt, "\n---- WARNING: no router of the app reports metrics, skipping deploy verification ----")
+		return nil
+	}
+	duration := time.Duration(verification.Duration) * time.Second
+	fmt.Fprintf(evt, "\n---- Verifying deploy for %v ----\n", duration)
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		wait := deployVerificationInterval()
+		if remaining := time.Until(deadline); remaining < wait {
+			wait = remaining
+		}
+		time.Sleep(wait)
+		m, err := verifier.measure()
+		if err != nil {
+			if verification.Endpoint != "" {
+				return ErrDeployVerificationFailed{Reason: err.Error()}
+			}
+			log.Errorf("[deploy verification] unable to get metrics of app %q: %v", a.Name, err)
+			continue
+		}
+		if reason := verification.breach(m); reason != "" {
+			return ErrDeployVerificationFailed{Reason: reason}
+		}
+		fmt.Fprintf(evt, " ---> %d requests, error rate %.2f%%, average latency %v\n", m.Requests, m.ErrorRate, m.Latency)
+	}
+	fmt.Fprintln(evt, " ---> Deploy verified")
+	return nil
+}
+
+// rollbackUnverifiedDeploy records the verification as the failed phase of
+// the deploy and deploys the image running before the deploy started.
+func rollbackUnverifiedDeploy(opts *DeployOptions, previousImage string, verifyErr error) error {
+	err := opts.Event.SetOtherCustomDataField("failedPhase", deployVerificationPhase)
+	if err != nil {
+		log.Errorf("[deploy verification] unable to record failed phase in event %s: %v", opts.Event.UniqueID.Hex(), err)
+	}
+	return rollbackFailedDeploy(opts, previousImage, verifyErr, EventKindDeployVerificationRollback, nil)
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDeployVerificationValidate(c *check.C) {
+	tests := []struct {
+		verification DeployVerification
+		valid        bool
+	}{
+		{DeployVerification{Duration: 60, MaxErrorRate: 5}, true},
+		{DeployVerification{Duration: 60, MaxLatency: 500, Endpoint: "https://myapp.example.com/metrics"}, true},
+		{DeployVerification{MaxErrorRate: 5}, false},
+		{DeployVerification{Duration: 60}, false},
+		{DeployVerification{Duration: 60, MaxErrorRate: 101}, false},
+		{DeployVerification{Duration: 60, MaxErrorRate: 5, MaxLatency: -1}, false},
+		{DeployVerification{Duration: 60, MaxErrorRate: 5, Endpoint: "ftp://myapp.example.com"}, false},
+	}
+	for i, tt := range tests {
+		err := tt.verification.Validate()
+		if tt.valid {
+			c.Check(err, check.IsNil, check.Commentf("test %d", i))
+		} else {
+			c.Check(err, check.FitsTypeOf, &errors.ValidationError{}, check.Commentf("test %d", i))
+		}
+	}
+}
+
+func (s *S) TestSetDeployVerification(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	verification := &DeployVerification{Duration: 300, MaxErrorRate: 5, MinRequests: 100}
+	err = a.SetDeployVerification(verification)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployVerification, check.DeepEquals, verification)
+	err = a.SetDeployVerification(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.DeployVerification, check.IsNil)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployVerification, check.IsNil)
+}
+
+func (s *S) TestSetDeployVerificationInvalid(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployVerification(&DeployVerification{Duration: 300})
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+}
+
+func (s *S) TestDeployVerificationBreach(c *check.C) {
+	v := DeployVerification{MaxErrorRate: 5, MaxLatency: 200, MinRequests: 10}
+	c.Assert(v.breach(verificationMeasure{Requests: 5, ErrorRate: 50}), check.Equals, "")
+	c.Assert(v.breach(verificationMeasure{Requests: 10, ErrorRate: 5, Latency: 200 * time.Millisecond}), check.Equals, "")
+	c.Assert(v.breach(verificationMeasure{Requests: 10, ErrorRate: 10}), check.Equals, "error rate of 10.00% exceeds the maximum of 5.00%")
+	c.Assert(v.breach(verificationMeasure{Requests: 10, Latency: time.Second}), check.Equals, "average latency of 1s exceeds the maximum of 200ms")
+}
+
+func (s *S) TestDeployVerifierRouterMetrics(c *check.C) {
+	a := App{
+		Name:               "myapp",
+		Platform:           "python",
+		TeamOwner:          s.team.Name,
+		Routers:            []appTypes.AppRouter{{Name: "fake-metrics"}},
+		DeployVerification: &DeployVerification{Duration: 60, MaxErrorRate: 5},
+	}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	routertest.MetricsRouter.Metrics[a.Name] = router.BackendMetrics{Requests: 100, Errors: 10, TotalLatency: time.Second}
+	verifier, err := newDeployVerifier(&a)
+	c.Assert(err, check.IsNil)
+	c.Assert(verifier, check.NotNil)
+	m, err := verifier.measure()
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.DeepEquals, verificationMeasure{})
+	routertest.MetricsRouter.Metrics[a.Name] = router.BackendMetrics{Requests: 150, Errors: 15, TotalLatency: 6 * time.Second}
+	m, err = verifier.measure()
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.DeepEquals, verificationMeasure{Requests: 50, ErrorRate: 10, Latency: 100 * time.Millisecond})
+}
+
+func (s *S) TestDeployVerifierWithoutMetricsRouter(c *check.C) {
+	a := App{
+		Name:               "myapp",
+		Platform:           "python",
+		TeamOwner:          s.team.Name,
+		DeployVerification: &DeployVerification{Duration: 60, MaxErrorRate: 5},
+	}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	verifier, err := newDeployVerifier(&a)
+	c.Assert(err, check.IsNil)
+	c.Assert(verifier, check.IsNil)
+}
+
+func (s *S) newVerificationDeployOpts(c *check.C, verification *DeployVerification) (*DeployOptions, *bytes.Buffer) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, DeployVerification: verification}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	evt.SetLogWriter(&buf)
+	return &DeployOptions{App: &a, Event: evt, Kind: DeployImage}, &buf
+}
+
+func (s *S) TestVerifyDeployEndpoint(c *check.C) {
+	config.Set("deploy:verification:interval", 1)
+	defer config.Unset("deploy:verification:interval")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"requests": 20, "errorRate": 1.5, "latency": 120}`))
+	}))
+	defer srv.Close()
+	opts, buf := s.newVerificationDeployOpts(c, &DeployVerification{Duration: 1, MaxErrorRate: 5, Endpoint: srv.URL})
+	err := verifyDeploy(opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, "(?s).*Verifying deploy for 1s.*20 requests, error rate 1.50%, average latency 120ms.*Deploy verified.*")
+}
+
+func (s *S) TestVerifyDeployEndpointBreach(c *check.C) {
+	config.Set("deploy:verification:interval", 1)
+	defer config.Unset("deploy:verification:interval")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"requests": 20, "errorRate": 50, "latency": 120}`))
+	}))
+	defer srv.Close()
+	opts, _ := s.newVerificationDeployOpts(c, &DeployVerification{Duration: 5, MaxErrorRate: 5, Endpoint: srv.URL})
+	err := verifyDeploy(opts)
+	c.Assert(err, check.DeepEquals, ErrDeployVerificationFailed{Reason: "error rate of 50.00% exceeds the maximum of 5.00%"})
+}
+
+func (s *S) TestVerifyDeployEndpointFailure(c *check.C) {
+	config.Set("deploy:verification:interval", 1)
+	defer config.Unset("deploy:verification:interval")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	opts, _ := s.newVerificationDeployOpts(c, &DeployVerification{Duration: 5, MaxErrorRate: 5, Endpoint: srv.URL})
+	err := verifyDeploy(opts)
+	c.Assert(err, check.DeepEquals, ErrDeployVerificationFailed{Reason: "verification endpoint returned status 500"})
+}
+
+func (s *S) TestVerifyDeploySkipsRollbacks(c *check.C) {
+	opts, buf := s.newVerificationDeployOpts(c, &DeployVerification{Duration: 60, MaxErrorRate: 5, Endpoint: "http://localhost:1"})
+	opts.Kind = DeployRollback
+	err := verifyDeploy(opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "")
+}
+
+func (s *S) TestVerifyDeployWithoutMetrics(c *check.C) {
+	opts, buf := s.newVerificationDeployOpts(c, &DeployVerification{Duration: 60, MaxErrorRate: 5})
+	err := verifyDeploy(opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, "(?s).*no router of the app reports metrics, skipping deploy verification.*")
+}
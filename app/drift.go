@@ -0,0 +1,56 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/tsuru/tsuru/provision"
+)
+
+// Drift returns the mismatches between the units tsuru expects for the app
+// and the ones actually running in its provisioner.
+func (app *App) Drift() ([]provision.AppDrift, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	driftProv, ok := prov.(provision.DriftProvisioner)
+	if !ok {
+		return nil, provision.ProvisionerNotSupported{Prov: prov, Action: "drift detection"}
+	}
+	return driftProv.AppDrift(app)
+}
+
+// ReconcileDrift restarts the processes of the app with units drifted from
+// the state tsuru expects, recreating them with the current image and envs.
+func (app *App) ReconcileDrift(w io.Writer) error {
+	drifts, err := app.Drift()
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		fmt.Fprintf(w, "No drift found in app %q\n", app.Name)
+		return nil
+	}
+	processSet := map[string]struct{}{}
+	for _, d := range drifts {
+		processSet[d.Process] = struct{}{}
+	}
+	processes := make([]string, 0, len(processSet))
+	for p := range processSet {
+		processes = append(processes, p)
+	}
+	sort.Strings(processes)
+	for _, p := range processes {
+		err = app.Restart(p, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestAppDrift(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	drifts, err := a.Drift()
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 0)
+	drift := provision.AppDrift{Unit: "u1", Process: "web", Kind: provision.DriftMissing}
+	s.provisioner.AddAppDrift(a.Name, drift)
+	drifts, err = a.Drift()
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.DeepEquals, []provision.AppDrift{drift})
+}
+
+func (s *S) TestAppReconcileDrift(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddAppDrift(a.Name, provision.AppDrift{Unit: "u1", Process: "web", Kind: provision.DriftImage})
+	s.provisioner.AddAppDrift(a.Name, provision.AppDrift{Unit: "u2", Process: "web", Kind: provision.DriftEnv})
+	s.provisioner.AddAppDrift(a.Name, provision.AppDrift{Unit: "u3", Process: "worker", Kind: provision.DriftStopped})
+	var buf bytes.Buffer
+	err = a.ReconcileDrift(&buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.Restarts(&a, "web"), check.Equals, 1)
+	c.Assert(s.provisioner.Restarts(&a, "worker"), check.Equals, 1)
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+}
+
+func (s *S) TestAppReconcileDriftNoDrift(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = a.ReconcileDrift(&buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "No drift found in app \"myapp\"\n")
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+}
@@ -0,0 +1,250 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package envgroup manages shared env groups, named sets of env vars owned by
+// a team that multiple apps may subscribe to.
+package envgroup
+
+import (
+	"sort"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/validation"
+)
+
+var (
+	ErrEnvGroupNotFound      = errors.New("env group not found")
+	ErrEnvGroupAlreadyExists = errors.New("env group already exists")
+	ErrEnvGroupInUse         = errors.New("env group has subscribed apps")
+	ErrAppNotSubscribed      = errors.New("app is not subscribed to the env group")
+)
+
+// EnvGroup is a named set of env vars shared by the apps subscribed to it.
+type EnvGroup struct {
+	Name string        `bson:"_id" json:"name"`
+	Team string        `json:"team"`
+	Envs []bind.EnvVar `json:"envs"`
+	Apps []string      `json:"apps"`
+}
+
+func collection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("env_groups")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"apps"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func (g *EnvGroup) validate() error {
+	if !validation.ValidateName(g.Name) {
+		msg := "Invalid env group name, env group name should have at most 63 " +
+			"characters, containing only lower case letters, numbers or dashes, " +
+			"starting with a letter."
+		return &tsuruErrors.ValidationError{Message: msg}
+	}
+	if g.Team == "" {
+		return &tsuruErrors.ValidationError{Message: "env group team is required"}
+	}
+	_, err := servicemanager.Team.FindByName(g.Team)
+	return err
+}
+
+// Create stores a new env group, without envs or subscribed apps.
+func Create(name, team string) (*EnvGroup, error) {
+	g := &EnvGroup{Name: name, Team: team}
+	err := g.validate()
+	if err != nil {
+		return nil, err
+	}
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	err = coll.Insert(g)
+	if mgo.IsDup(err) {
+		return nil, ErrEnvGroupAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Get returns the env group with the given name.
+func Get(name string) (*EnvGroup, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var g EnvGroup
+	err = coll.FindId(name).One(&g)
+	if err == mgo.ErrNotFound {
+		return nil, ErrEnvGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// List returns the env groups owned by the given teams, sorted by name. A nil
+// list of teams returns every env group.
+func List(teams []string) ([]EnvGroup, error) {
+	query := bson.M{}
+	if teams != nil {
+		query["team"] = bson.M{"$in": teams}
+	}
+	return list(query)
+}
+
+// ListByApp returns the env groups the app is subscribed to, sorted by name.
+func ListByApp(appName string) ([]EnvGroup, error) {
+	return list(bson.M{"apps": appName})
+}
+
+func list(query bson.M) ([]EnvGroup, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var groups []EnvGroup
+	err = coll.Find(query).Sort("_id").All(&groups)
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Remove removes the env group, refusing to do so while apps are subscribed
+// to it.
+func Remove(name string) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.Remove(bson.M{"_id": name, "apps.0": bson.M{"$exists": false}})
+	if err != mgo.ErrNotFound {
+		return err
+	}
+	_, err = Get(name)
+	if err != nil {
+		return err
+	}
+	return ErrEnvGroupInUse
+}
+
+// SetEnvs adds the given env vars to the env group, replacing the values of
+// env vars already set.
+func SetEnvs(name string, envs []bind.EnvVar) error {
+	if len(envs) == 0 {
+		return nil
+	}
+	g, err := Get(name)
+	if err != nil {
+		return err
+	}
+	merged := make(map[string]bind.EnvVar, len(g.Envs)+len(envs))
+	for _, e := range g.Envs {
+		merged[e.Name] = e
+	}
+	for _, e := range envs {
+		if e.Name == "" {
+			return &tsuruErrors.ValidationError{Message: "env var name is required"}
+		}
+		merged[e.Name] = e
+	}
+	return updateEnvs(name, merged)
+}
+
+// UnsetEnvs removes the env vars with the given names from the env group.
+func UnsetEnvs(name string, names []string) error {
+	g, err := Get(name)
+	if err != nil {
+		return err
+	}
+	merged := make(map[string]bind.EnvVar, len(g.Envs))
+	for _, e := range g.Envs {
+		merged[e.Name] = e
+	}
+	for _, n := range names {
+		delete(merged, n)
+	}
+	return updateEnvs(name, merged)
+}
+
+func updateEnvs(name string, envs map[string]bind.EnvVar) error {
+	list := make([]bind.EnvVar, 0, len(envs))
+	for _, e := range envs {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return update(name, bson.M{"$set": bson.M{"envs": list}})
+}
+
+// Subscribe adds the app to the apps sharing the env vars of the env group.
+func Subscribe(name, appName string) error {
+	return update(name, bson.M{"$addToSet": bson.M{"apps": appName}})
+}
+
+// Unsubscribe removes the app from the apps sharing the env vars of the env
+// group.
+func Unsubscribe(name, appName string) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.Update(bson.M{"_id": name, "apps": appName}, bson.M{"$pull": bson.M{"apps": appName}})
+	if err != mgo.ErrNotFound {
+		return err
+	}
+	_, err = Get(name)
+	if err != nil {
+		return err
+	}
+	return ErrAppNotSubscribed
+}
+
+// UnsubscribeAll removes the app from every env group it is subscribed to.
+func UnsubscribeAll(appName string) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.UpdateAll(bson.M{"apps": appName}, bson.M{"$pull": bson.M{"apps": appName}})
+	return err
+}
+
+func update(name string, update bson.M) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.UpdateId(name, update)
+	if err == mgo.ErrNotFound {
+		return ErrEnvGroupNotFound
+	}
+	return err
+}
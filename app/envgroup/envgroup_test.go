@@ -0,0 +1,156 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envgroup
+
+import (
+	"github.com/tsuru/tsuru/app/bind"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCreate(c *check.C) {
+	g, err := Create("shared", "myteam")
+	c.Assert(err, check.IsNil)
+	c.Assert(g, check.DeepEquals, &EnvGroup{Name: "shared", Team: "myteam"})
+	dbGroup, err := Get("shared")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbGroup.Name, check.Equals, "shared")
+	c.Assert(dbGroup.Team, check.Equals, "myteam")
+	_, err = Create("shared", "myteam")
+	c.Assert(err, check.Equals, ErrEnvGroupAlreadyExists)
+}
+
+func (s *S) TestCreateInvalid(c *check.C) {
+	_, err := Create("Shared Envs", "myteam")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = Create("shared", "")
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "env group team is required"})
+	_, err = Create("shared", "otherteam")
+	c.Assert(err, check.Equals, authTypes.ErrTeamNotFound)
+}
+
+func (s *S) TestGetNotFound(c *check.C) {
+	_, err := Get("shared")
+	c.Assert(err, check.Equals, ErrEnvGroupNotFound)
+}
+
+func (s *S) TestList(c *check.C) {
+	_, err := Create("g2", "myteam")
+	c.Assert(err, check.IsNil)
+	_, err = Create("g1", "myteam")
+	c.Assert(err, check.IsNil)
+	groups, err := List(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 2)
+	c.Assert(groups[0].Name, check.Equals, "g1")
+	c.Assert(groups[1].Name, check.Equals, "g2")
+	groups, err = List([]string{"myteam"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 2)
+	groups, err = List([]string{"otherteam"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
+}
+
+func (s *S) TestSetAndUnsetEnvs(c *check.C) {
+	_, err := Create("shared", "myteam")
+	c.Assert(err, check.IsNil)
+	err = SetEnvs("shared", []bind.EnvVar{
+		{Name: "B", Value: "2", Public: true},
+		{Name: "A", Value: "1"},
+	})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs("shared", []bind.EnvVar{{Name: "B", Value: "3", Public: true}})
+	c.Assert(err, check.IsNil)
+	g, err := Get("shared")
+	c.Assert(err, check.IsNil)
+	c.Assert(g.Envs, check.DeepEquals, []bind.EnvVar{
+		{Name: "A", Value: "1"},
+		{Name: "B", Value: "3", Public: true},
+	})
+	err = UnsetEnvs("shared", []string{"A"})
+	c.Assert(err, check.IsNil)
+	g, err = Get("shared")
+	c.Assert(err, check.IsNil)
+	c.Assert(g.Envs, check.DeepEquals, []bind.EnvVar{{Name: "B", Value: "3", Public: true}})
+	err = SetEnvs("shared", []bind.EnvVar{{Value: "x"}})
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "env var name is required"})
+	err = SetEnvs("other", []bind.EnvVar{{Name: "A", Value: "1"}})
+	c.Assert(err, check.Equals, ErrEnvGroupNotFound)
+}
+
+func (s *S) TestSubscribe(c *check.C) {
+	_, err := Create("g1", "myteam")
+	c.Assert(err, check.IsNil)
+	_, err = Create("g2", "myteam")
+	c.Assert(err, check.IsNil)
+	err = Subscribe("g2", "myapp")
+	c.Assert(err, check.IsNil)
+	err = Subscribe("g1", "myapp")
+	c.Assert(err, check.IsNil)
+	err = Subscribe("g1", "myapp")
+	c.Assert(err, check.IsNil)
+	groups, err := ListByApp("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 2)
+	c.Assert(groups[0].Name, check.Equals, "g1")
+	c.Assert(groups[0].Apps, check.DeepEquals, []string{"myapp"})
+	c.Assert(groups[1].Name, check.Equals, "g2")
+	err = Subscribe("g3", "myapp")
+	c.Assert(err, check.Equals, ErrEnvGroupNotFound)
+}
+
+func (s *S) TestUnsubscribe(c *check.C) {
+	_, err := Create("shared", "myteam")
+	c.Assert(err, check.IsNil)
+	err = Subscribe("shared", "myapp")
+	c.Assert(err, check.IsNil)
+	err = Unsubscribe("shared", "myapp")
+	c.Assert(err, check.IsNil)
+	groups, err := ListByApp("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
+	err = Unsubscribe("shared", "myapp")
+	c.Assert(err, check.Equals, ErrAppNotSubscribed)
+	err = Unsubscribe("other", "myapp")
+	c.Assert(err, check.Equals, ErrEnvGroupNotFound)
+}
+
+func (s *S) TestUnsubscribeAll(c *check.C) {
+	for _, name := range []string{"g1", "g2"} {
+		_, err := Create(name, "myteam")
+		c.Assert(err, check.IsNil)
+		err = Subscribe(name, "myapp")
+		c.Assert(err, check.IsNil)
+	}
+	err := Subscribe("g1", "otherapp")
+	c.Assert(err, check.IsNil)
+	err = UnsubscribeAll("myapp")
+	c.Assert(err, check.IsNil)
+	groups, err := ListByApp("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 0)
+	groups, err = ListByApp("otherapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 1)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	_, err := Create("shared", "myteam")
+	c.Assert(err, check.IsNil)
+	err = Subscribe("shared", "myapp")
+	c.Assert(err, check.IsNil)
+	err = Remove("shared")
+	c.Assert(err, check.Equals, ErrEnvGroupInUse)
+	err = Unsubscribe("shared", "myapp")
+	c.Assert(err, check.IsNil)
+	err = Remove("shared")
+	c.Assert(err, check.IsNil)
+	_, err = Get("shared")
+	c.Assert(err, check.Equals, ErrEnvGroupNotFound)
+	err = Remove("shared")
+	c.Assert(err, check.Equals, ErrEnvGroupNotFound)
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"sort"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+)
+
+var ErrErrorPageNotFound = errors.New("error page not found")
+
+// ErrorPage returns the custom error page of the app for the given status
+// code.
+func (app *App) ErrorPage(statusCode int) (*router.ErrorPage, error) {
+	for i := range app.ErrorPages {
+		if app.ErrorPages[i].StatusCode == statusCode {
+			return &app.ErrorPages[i], nil
+		}
+	}
+	return nil, ErrErrorPageNotFound
+}
+
+// SetErrorPage adds or replaces the custom error page of the app for the
+// status code of the page and pushes the pages of the app to its routers.
+func (app *App) SetErrorPage(page router.ErrorPage) error {
+	err := page.Validate()
+	if err != nil {
+		return err
+	}
+	if page.ContentType == "" {
+		page.ContentType = router.DefaultErrorPageContentType
+	}
+	pages := []router.ErrorPage{page}
+	for _, p := range app.ErrorPages {
+		if p.StatusCode != page.StatusCode {
+			pages = append(pages, p)
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].StatusCode < pages[j].StatusCode
+	})
+	return app.updateErrorPages(pages)
+}
+
+// RemoveErrorPage removes the custom error page of the app for the given
+// status code, making routers serve their default page again.
+func (app *App) RemoveErrorPage(statusCode int) error {
+	if _, err := app.ErrorPage(statusCode); err != nil {
+		return err
+	}
+	var pages []router.ErrorPage
+	for _, p := range app.ErrorPages {
+		if p.StatusCode != statusCode {
+			pages = append(pages, p)
+		}
+	}
+	return app.updateErrorPages(pages)
+}
+
+func (app *App) updateErrorPages(pages []router.ErrorPage) error {
+	var update bson.M
+	if len(pages) == 0 {
+		update = bson.M{"$unset": bson.M{"errorpages": ""}}
+	} else {
+		update = bson.M{"$set": bson.M{"errorpages": pages}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.ErrorPages = pages
+	return app.pushErrorPages()
+}
+
+// pushErrorPages sends the custom error pages of the app to its routers able
+// to serve them, other routers are ignored.
+func (app *App) pushErrorPages() error {
+	multi := tsuruErrors.NewMultiError()
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		pageRouter, ok := r.(router.ErrorPageRouter)
+		if !ok || !router.Supports(r, router.CapabilityErrorPage) {
+			continue
+		}
+		err = pageRouter.SetErrorPages(app, app.ErrorPages)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to set error pages in router %q", appRouter.Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
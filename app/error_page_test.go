@@ -0,0 +1,96 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetErrorPage(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-errorpage"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 503, Body: "<h1>maintenance</h1>"})
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 502, ContentType: "text/plain", Body: "bad gateway"})
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 503, Body: "<h1>back soon</h1>"})
+	c.Assert(err, check.IsNil)
+	expected := []router.ErrorPage{
+		{StatusCode: 502, ContentType: "text/plain", Body: "bad gateway"},
+		{StatusCode: 503, ContentType: router.DefaultErrorPageContentType, Body: "<h1>back soon</h1>"},
+	}
+	c.Assert(routertest.ErrorPageRouter.Pages["myapp"], check.DeepEquals, expected)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ErrorPages, check.DeepEquals, expected)
+	page, err := dbApp.ErrorPage(502)
+	c.Assert(err, check.IsNil)
+	c.Assert(page, check.DeepEquals, &expected[0])
+}
+
+func (s *S) TestSetErrorPageInvalid(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-errorpage"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 404, Body: "not found"})
+	c.Assert(err, check.ErrorMatches, "Invalid status code 404, .*")
+	c.Assert(routertest.ErrorPageRouter.Pages, check.HasLen, 0)
+}
+
+func (s *S) TestRemoveErrorPage(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-errorpage"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 502, Body: "bad gateway"})
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 503, Body: "unavailable"})
+	c.Assert(err, check.IsNil)
+	err = a.RemoveErrorPage(502)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ErrorPageRouter.Pages["myapp"], check.DeepEquals, []router.ErrorPage{
+		{StatusCode: 503, ContentType: router.DefaultErrorPageContentType, Body: "unavailable"},
+	})
+	err = a.RemoveErrorPage(503)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ErrorPageRouter.Pages, check.HasLen, 0)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.ErrorPages, check.HasLen, 0)
+	err = a.RemoveErrorPage(503)
+	c.Assert(err, check.Equals, ErrErrorPageNotFound)
+}
+
+func (s *S) TestAddRouterPushesErrorPages(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 503, Body: "unavailable"})
+	c.Assert(err, check.IsNil)
+	err = a.AddRouter(appTypes.AppRouter{Name: "fake-errorpage"})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ErrorPageRouter.Pages["myapp"], check.DeepEquals, []router.ErrorPage{
+		{StatusCode: 503, ContentType: router.DefaultErrorPageContentType, Body: "unavailable"},
+	})
+}
+
+func (s *S) TestAppMarshalJSONErrorPages(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetErrorPage(router.ErrorPage{StatusCode: 503, Body: "unavailable"})
+	c.Assert(err, check.IsNil)
+	data, err := a.MarshalJSON()
+	c.Assert(err, check.IsNil)
+	var result map[string]interface{}
+	err = json.Unmarshal(data, &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result["errorPages"], check.DeepEquals, []interface{}{float64(503)})
+}
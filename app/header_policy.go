@@ -0,0 +1,80 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+)
+
+// HeaderPolicyRouters returns the names of the routers of the app able to
+// apply header policies.
+func (app *App) HeaderPolicyRouters() ([]string, error) {
+	names := []string{}
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := r.(router.HeaderPolicyRouter); ok {
+			names = append(names, appRouter.Name)
+		}
+	}
+	return names, nil
+}
+
+// SetHeaderPolicy sets the response header policy of the app and pushes it
+// to the routers of the app. A nil policy removes it.
+func (app *App) SetHeaderPolicy(policy *router.HeaderPolicy) error {
+	var update bson.M
+	if policy == nil {
+		update = bson.M{"$unset": bson.M{"headerpolicy": ""}}
+	} else {
+		err := policy.Validate()
+		if err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"headerpolicy": policy}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.HeaderPolicy = policy
+	return app.PushHeaderPolicy()
+}
+
+// PushHeaderPolicy sends the header policy of the app to its routers able to
+// apply it, other routers are ignored.
+func (app *App) PushHeaderPolicy() error {
+	multi := tsuruErrors.NewMultiError()
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		headerRouter, ok := r.(router.HeaderPolicyRouter)
+		if !ok {
+			continue
+		}
+		err = headerRouter.SetHeaderPolicy(app, app.HeaderPolicy)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to set header policy in router %q", appRouter.Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetHeaderPolicy(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake"}, {Name: "fake-tls"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	policy := &router.HeaderPolicy{
+		CORS:    &router.CORSPolicy{AllowedOrigins: []string{"https://example.com"}},
+		Headers: map[string]string{"X-Frame-Options": "DENY"},
+	}
+	err = a.SetHeaderPolicy(policy)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Headers["myapp"], check.DeepEquals, policy)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.HeaderPolicy, check.DeepEquals, policy)
+	routers, err := a.HeaderPolicyRouters()
+	c.Assert(err, check.IsNil)
+	c.Assert(routers, check.DeepEquals, []string{"fake-tls"})
+	err = a.SetHeaderPolicy(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Headers, check.HasLen, 0)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.HeaderPolicy, check.IsNil)
+}
+
+func (s *S) TestSetHeaderPolicyInvalid(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetHeaderPolicy(&router.HeaderPolicy{Headers: map[string]string{"Content-Length": "10"}})
+	c.Assert(err, check.ErrorMatches, `Header "Content-Length" is managed by the router and cannot be set`)
+	c.Assert(routertest.TLSRouter.Headers, check.HasLen, 0)
+}
+
+func (s *S) TestAddRouterPushesHeaderPolicy(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	policy := &router.HeaderPolicy{Headers: map[string]string{"X-Content-Type-Options": "nosniff"}}
+	err = a.SetHeaderPolicy(policy)
+	c.Assert(err, check.IsNil)
+	err = a.AddRouter(appTypes.AppRouter{Name: "fake-tls"})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Headers["myapp"], check.DeepEquals, policy)
+}
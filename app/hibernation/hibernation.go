@@ -0,0 +1,175 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hibernation puts idle apps from selected pools to sleep and wakes
+// them up when they receive requests again.
+package hibernation
+
+import (
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/router"
+)
+
+const (
+	defaultIdleTimeout = 12 * time.Hour
+	defaultRunInterval = 5 * time.Minute
+	wakePollInterval   = time.Second
+)
+
+var ErrWakeTimeout = errors.New("timeout waiting for app to wake up")
+
+// State holds the hibernation state of an app.
+type State struct {
+	App          string    `bson:"_id" json:"app"`
+	Hibernated   bool      `json:"hibernated"`
+	HibernatedAt time.Time `json:"hibernatedAt"`
+	WokeAt       time.Time `json:"wokeAt"`
+	TrackedSince time.Time `json:"trackedSince"`
+}
+
+func stateCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("app_hibernation"), nil
+}
+
+// GetState returns the hibernation state of an app. Apps never seen by the
+// hibernator have an empty state.
+func GetState(appName string) (*State, error) {
+	coll, err := stateCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	state := State{App: appName}
+	err = coll.FindId(appName).One(&state)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (s *State) save() error {
+	coll, err := stateCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.UpsertId(s.App, s)
+	return err
+}
+
+// IsHibernated returns whether the app was put to sleep by the hibernator.
+func IsHibernated(appName string) (bool, error) {
+	state, err := GetState(appName)
+	if err != nil {
+		return false, err
+	}
+	return state.Hibernated, nil
+}
+
+// EnabledPools returns the list of pools where idle apps are hibernated.
+func EnabledPools() []string {
+	pools, _ := config.GetList("hibernation:pools")
+	return pools
+}
+
+func idleTimeout() time.Duration {
+	timeout, _ := config.GetInt("hibernation:idle-timeout")
+	if timeout <= 0 {
+		return defaultIdleTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+func runInterval() time.Duration {
+	interval, _ := config.GetInt("hibernation:run-interval")
+	if interval <= 0 {
+		return defaultRunInterval
+	}
+	return time.Duration(interval) * time.Second
+}
+
+func proxyURL() (*url.URL, error) {
+	proxy, err := config.GetString("hibernation:proxy-url")
+	if err != nil {
+		return nil, errors.Wrap(err, "hibernation proxy url not set")
+	}
+	return url.Parse(proxy)
+}
+
+// lastRequest returns the most recent request received by the app in any of
+// its routers. The boolean result is false if none of the routers is able to
+// report traffic.
+func lastRequest(a *app.App) (time.Time, bool, error) {
+	var last time.Time
+	var supported bool
+	for _, appRouter := range a.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		trafficRouter, ok := r.(router.TrafficRouter)
+		if !ok || !router.Supports(r, router.CapabilityTraffic) {
+			continue
+		}
+		supported = true
+		routerLast, err := trafficRouter.LastRequest(a.Name)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if routerLast.After(last) {
+			last = routerLast
+		}
+	}
+	return last, supported, nil
+}
+
+// Wake starts an app put to sleep by the hibernator.
+func Wake(a *app.App, w io.Writer) error {
+	state, err := GetState(a.Name)
+	if err != nil {
+		return err
+	}
+	if !state.Hibernated {
+		return nil
+	}
+	err = a.Start(w, "")
+	if err != nil {
+		return err
+	}
+	state.Hibernated = false
+	state.WokeAt = time.Now().UTC()
+	return state.save()
+}
+
+// WaitAwake blocks until the app is no longer hibernated, it's used when
+// another request is already waking up the app.
+func WaitAwake(appName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		hibernated, err := IsHibernated(appName)
+		if err != nil {
+			return err
+		}
+		if !hibernated {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrWakeTimeout
+		}
+		time.Sleep(wakePollInterval)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hibernation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const EventKind = "app-hibernate"
+
+// Initialize starts the hibernator if hibernation is enabled in at least one
+// pool.
+func Initialize() error {
+	if len(EnabledPools()) == 0 {
+		return nil
+	}
+	if _, err := proxyURL(); err != nil {
+		return err
+	}
+	h := &hibernator{once: &sync.Once{}}
+	h.start()
+	shutdown.Register(h)
+	return nil
+}
+
+type hibernator struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (h *hibernator) start() {
+	h.once.Do(func() {
+		h.stopCh = make(chan struct{})
+		go h.spin()
+	})
+}
+
+func (h *hibernator) Shutdown(ctx context.Context) error {
+	if h.stopCh == nil {
+		return nil
+	}
+	h.stopCh <- struct{}{}
+	h.stopCh = nil
+	h.once = &sync.Once{}
+	return nil
+}
+
+func (h *hibernator) spin() {
+	for {
+		err := hibernateIdleApps(time.Now().UTC())
+		if err != nil {
+			log.Errorf("[hibernation] errors hibernating apps: %v", err)
+		}
+		select {
+		case <-h.stopCh:
+			return
+		case <-time.After(runInterval()):
+		}
+	}
+}
+
+func hibernateIdleApps(now time.Time) error {
+	pools := EnabledPools()
+	if len(pools) == 0 {
+		return nil
+	}
+	apps, err := app.List(&app.Filter{Pools: pools})
+	if err != nil {
+		return err
+	}
+	timeout := idleTimeout()
+	multi := tsuruErrors.NewMultiError()
+	for i := range apps {
+		a := &apps[i]
+		err = hibernateIfIdle(a, now, timeout)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to hibernate app %q", a.Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+func hibernateIfIdle(a *app.App, now time.Time, timeout time.Duration) error {
+	state, err := GetState(a.Name)
+	if err != nil {
+		return err
+	}
+	units, err := a.Units()
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 {
+		return nil
+	}
+	running := true
+	for _, u := range units {
+		if u.Status == provision.StatusStopped || u.Status == provision.StatusAsleep {
+			running = false
+			break
+		}
+	}
+	if state.Hibernated {
+		if running {
+			// The app was started by other means than a wake up request.
+			state.Hibernated = false
+			state.WokeAt = now
+			return state.save()
+		}
+		return nil
+	}
+	if !running {
+		return nil
+	}
+	last, supported, err := lastRequest(a)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		log.Debugf("[hibernation] no router for app %q reports traffic, ignoring", a.Name)
+		return nil
+	}
+	if state.TrackedSince.IsZero() {
+		state.TrackedSince = now
+		return state.save()
+	}
+	for _, t := range []time.Time{state.WokeAt, state.TrackedSince} {
+		if t.After(last) {
+			last = t
+		}
+	}
+	if now.Sub(last) < timeout {
+		return nil
+	}
+	return hibernate(a, state, last)
+}
+
+func hibernate(a *app.App, state *State, lastRequest time.Time) (err error) {
+	proxy, err := proxyURL()
+	if err != nil {
+		return err
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		InternalKind: EventKind,
+		CustomData:   map[string]interface{}{"lastRequest": lastRequest},
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, a.Teams),
+			permission.Context(permission.CtxApp, a.Name),
+			permission.Context(permission.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			log.Debugf("[hibernation] skipping app %q: event locked", a.Name)
+			return nil
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.Logf("app %q idle since %s, hibernating", a.Name, lastRequest.Format(time.RFC3339))
+	err = a.Sleep(evt, "", proxy)
+	if err != nil {
+		return err
+	}
+	state.Hibernated = true
+	state.HibernatedAt = time.Now().UTC()
+	return state.save()
+}
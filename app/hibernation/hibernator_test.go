@@ -0,0 +1,118 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hibernation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestHibernatorStartNothingToDo(c *check.C) {
+	h := &hibernator{once: &sync.Once{}}
+	h.start()
+	err := h.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestHibernateIdleApps(c *check.C) {
+	a := s.newApp(c, "myapp")
+	now := time.Now().UTC()
+	err := hibernateIdleApps(now.Add(-24 * time.Hour))
+	c.Assert(err, check.IsNil)
+	routertest.TrafficRouter.LastRequests[a.Name] = now.Add(-13 * time.Hour)
+	err = hibernateIdleApps(now)
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Sleeps(a, ""), check.Equals, 1)
+	c.Assert(routertest.TrafficRouter.HasRoute(a.Name, "http://wakeproxy.example.com"), check.Equals, true)
+	state, err := GetState(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(state.Hibernated, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target:     event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		Kind:       EventKind,
+		LogMatches: `app "myapp" idle since .*, hibernating`,
+	}, eventtest.HasEvent)
+	err = hibernateIdleApps(now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Sleeps(a, ""), check.Equals, 1)
+}
+
+func (s *S) TestHibernateIdleAppsRecentTraffic(c *check.C) {
+	a := s.newApp(c, "myapp")
+	now := time.Now().UTC()
+	err := hibernateIdleApps(now.Add(-24 * time.Hour))
+	c.Assert(err, check.IsNil)
+	routertest.TrafficRouter.LastRequests[a.Name] = now.Add(-time.Hour)
+	err = hibernateIdleApps(now)
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Sleeps(a, ""), check.Equals, 0)
+}
+
+func (s *S) TestHibernateIdleAppsStartsTrackingWithoutTraffic(c *check.C) {
+	a := s.newApp(c, "myapp")
+	now := time.Now().UTC()
+	err := hibernateIdleApps(now)
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Sleeps(a, ""), check.Equals, 0)
+	err = hibernateIdleApps(now.Add(11 * time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Sleeps(a, ""), check.Equals, 0)
+	err = hibernateIdleApps(now.Add(13 * time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Sleeps(a, ""), check.Equals, 1)
+}
+
+func (s *S) TestHibernateIdleAppsPoolNotEnabled(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "prod", Public: true})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team, Pool: "prod", Router: "fake-traffic"}
+	err = app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(1, "web", nil)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	err = hibernateIdleApps(now.Add(-24 * time.Hour))
+	c.Assert(err, check.IsNil)
+	err = hibernateIdleApps(now)
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Sleeps(&a, ""), check.Equals, 0)
+	state, err := GetState(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(state.TrackedSince.IsZero(), check.Equals, true)
+}
+
+func (s *S) TestWake(c *check.C) {
+	a := s.newApp(c, "myapp")
+	err := hibernate(a, &State{App: a.Name}, time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	err = Wake(a, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Starts(a, ""), check.Equals, 1)
+	state, err := GetState(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(state.Hibernated, check.Equals, false)
+	c.Assert(state.WokeAt.IsZero(), check.Equals, false)
+	err = Wake(a, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.Starts(a, ""), check.Equals, 1)
+}
+
+func (s *S) TestWaitAwake(c *check.C) {
+	err := WaitAwake("myapp", time.Second)
+	c.Assert(err, check.IsNil)
+	err = (&State{App: "myapp", Hibernated: true}).save()
+	c.Assert(err, check.IsNil)
+	err = WaitAwake("myapp", 0)
+	c.Assert(err, check.Equals, ErrWakeTimeout)
+}
@@ -116,6 +116,9 @@ func customDataToImageMetadata(imageName string, customData map[string]interface
 		}
 		delete(customData, "procfile")
 	}
+	if err := validateDependencies(customData, processes); err != nil {
+		return nil, err
+	}
 	data := ImageMetadata{
 		Name:       imageName,
 		Processes:  processes,
@@ -127,6 +130,30 @@ func customDataToImageMetadata(imageName string, customData map[string]interface
 	return &data, nil
 }
 
+// validateDependencies checks the dependencies between processes declared
+// in tsuru.yaml, failing the deploy before any unit is started when they
+// reference unknown processes or form a cycle.
+func validateDependencies(customData map[string]interface{}, processes map[string][]string) error {
+	deps, ok := customData["dependencies"]
+	if !ok || len(processes) == 0 {
+		return nil
+	}
+	raw, err := bson.Marshal(bson.M{"dependencies": deps})
+	if err != nil {
+		return errors.Wrap(err, "invalid dependencies in tsuru.yaml")
+	}
+	var yamlData provision.TsuruYamlData
+	err = bson.Unmarshal(raw, &yamlData)
+	if err != nil {
+		return errors.Wrap(err, "invalid dependencies in tsuru.yaml")
+	}
+	names := make([]string, 0, len(processes))
+	for name := range processes {
+		names = append(names, name)
+	}
+	return yamlData.ValidateDependencies(names)
+}
+
 func SaveImageCustomData(imageName string, customData map[string]interface{}) error {
 	data, err := customDataToImageMetadata(imageName, customData)
 	if err != nil {
@@ -540,6 +567,17 @@ func appBasicImageName(appName string) string {
 	return fmt.Sprintf("%s/app-%s", basicImageName("tsuru"), appName)
 }
 
+// AppNameFromImage returns the name of the app of an image built by tsuru,
+// reporting false for images of platforms or not built by tsuru.
+func AppNameFromImage(imageName string) (string, bool) {
+	repo, _ := SplitImageName(imageName)
+	prefix := basicImageName("tsuru") + "/app-"
+	if !strings.HasPrefix(repo, prefix) || repo == prefix {
+		return "", false
+	}
+	return strings.TrimPrefix(repo, prefix), true
+}
+
 func appBasicBuilderImageName(appName, teamName string) string {
 	if teamName == "" {
 		teamName = "tsuru"
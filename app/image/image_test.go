@@ -329,6 +329,55 @@ func (s *S) TestSaveImageCustomDataProcfile(c *check.C) {
 	})
 }
 
+func (s *S) TestSaveImageCustomDataDependencies(c *check.C) {
+	img1 := "tsuru/app-myapp:v1"
+	customData1 := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web":     "python myapp.py",
+			"worker":  "someworker",
+			"migrate": "migrate-runner",
+		},
+		"dependencies": map[string]interface{}{
+			"web":    []interface{}{"migrate"},
+			"worker": []interface{}{"web"},
+		},
+	}
+	err := SaveImageCustomData(img1, customData1)
+	c.Assert(err, check.IsNil)
+	yamlData, err := GetImageTsuruYamlData(img1)
+	c.Assert(err, check.IsNil)
+	c.Assert(yamlData.Dependencies, check.DeepEquals, map[string][]string{
+		"web":    {"migrate"},
+		"worker": {"web"},
+	})
+}
+
+func (s *S) TestSaveImageCustomDataDependenciesCycle(c *check.C) {
+	img1 := "tsuru/app-myapp:v1"
+	customData1 := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web":    "python myapp.py",
+			"worker": "someworker",
+		},
+		"dependencies": map[string]interface{}{
+			"web":    []interface{}{"worker"},
+			"worker": []interface{}{"web"},
+		},
+	}
+	err := SaveImageCustomData(img1, customData1)
+	c.Assert(err, check.ErrorMatches, "cycle in process dependencies: web -> worker -> web")
+	customData2 := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+		"dependencies": map[string]interface{}{
+			"web": []interface{}{"unknown"},
+		},
+	}
+	err = SaveImageCustomData(img1, customData2)
+	c.Assert(err, check.ErrorMatches, `process "web" depends on unknown process "unknown"`)
+}
+
 func (s *S) TestSaveImageCustomDataProcessList(c *check.C) {
 	img1 := "tsuru/app-myapp:v1"
 	customData1 := map[string]interface{}{
@@ -525,3 +574,25 @@ func (s *S) TestListAllAppImages(c *check.C) {
 		},
 	})
 }
+
+func (s *S) TestAppNameFromImage(c *check.C) {
+	config.Set("docker:registry", "localhost:3030")
+	defer config.Unset("docker:registry")
+	tests := []struct {
+		image string
+		app   string
+		ok    bool
+	}{
+		{"localhost:3030/tsuru/app-myapp:v1", "myapp", true},
+		{"localhost:3030/tsuru/app-myapp:v1-builder", "myapp", true},
+		{"localhost:3030/tsuru/app-myapp", "myapp", true},
+		{"localhost:3030/tsuru/python:latest", "", false},
+		{"localhost:3030/tsuru/app-:v1", "", false},
+		{"tsuru/app-myapp:v1", "", false},
+	}
+	for _, tt := range tests {
+		appName, ok := AppNameFromImage(tt.image)
+		c.Check(ok, check.Equals, tt.ok, check.Commentf("image %q", tt.image))
+		c.Check(appName, check.Equals, tt.app, check.Commentf("image %q", tt.image))
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/event"
+)
+
+// ErrAppLockChanged is returned by ForceReleaseApplicationLock when the lock
+// is released or acquired again while checking its holder.
+var ErrAppLockChanged = errors.New("app lock changed while checking its holder, try again")
+
+// ErrAppLockHeld is returned by ForceReleaseApplicationLock when there is a
+// running event in the app, meaning the operation holding the lock may still
+// be alive.
+type ErrAppLockHeld struct {
+	App     string
+	EventID string
+	Kind    string
+}
+
+func (e ErrAppLockHeld) Error() string {
+	return fmt.Sprintf("app %q has a running %s operation (event %s), the lock may still be in use", e.App, e.Kind, e.EventID)
+}
+
+// LockInfo describes a lock held on an app, along with the events still
+// running in the app.
+type LockInfo struct {
+	App           string    `json:"app"`
+	Owner         string    `json:"owner"`
+	Reason        string    `json:"reason"`
+	AcquireDate   time.Time `json:"acquireDate"`
+	Age           string    `json:"age"`
+	RunningEvents []string  `json:"runningEvents"`
+}
+
+// ListLocks returns the locks held on the apps matching the filter.
+func ListLocks(filter *Filter) ([]LockInfo, error) {
+	if filter == nil {
+		filter = &Filter{}
+	}
+	filter.Locked = true
+	apps, err := List(filter)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	locks := make([]LockInfo, 0, len(apps))
+	for _, a := range apps {
+		evts, err := runningAppEvents(a.Name)
+		if err != nil {
+			return nil, err
+		}
+		info := LockInfo{
+			App:           a.Name,
+			Owner:         a.Lock.Owner,
+			Reason:        a.Lock.Reason,
+			AcquireDate:   a.Lock.AcquireDate,
+			Age:           now.Sub(a.Lock.AcquireDate).Truncate(time.Second).String(),
+			RunningEvents: []string{},
+		}
+		for _, evt := range evts {
+			info.RunningEvents = append(info.RunningEvents, evt.UniqueID.Hex())
+		}
+		locks = append(locks, info)
+	}
+	return locks, nil
+}
+
+// ForceReleaseApplicationLock releases the lock held on the app, as long as
+// there are no running events in the app other than the ignored ones. The
+// lock is only released if it's still the one checked, so a lock acquired
+// by a new operation in the meantime is kept. Releasing an unlocked app is
+// a no-op.
+func ForceReleaseApplicationLock(appName string, ignoredEventIDs ...string) error {
+	a, err := GetByName(appName)
+	if err != nil {
+		return err
+	}
+	if !a.Lock.Locked {
+		return nil
+	}
+	evts, err := runningAppEvents(appName)
+	if err != nil {
+		return err
+	}
+	ignored := make(map[string]struct{}, len(ignoredEventIDs))
+	for _, id := range ignoredEventIDs {
+		ignored[id] = struct{}{}
+	}
+	for _, evt := range evts {
+		id := evt.UniqueID.Hex()
+		if _, ok := ignored[id]; ok {
+			continue
+		}
+		return ErrAppLockHeld{App: appName, EventID: id, Kind: evt.Kind.Name}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(
+		bson.M{"name": appName, "lock.locked": true, "lock.acquiredate": a.Lock.AcquireDate},
+		bson.M{"$set": bson.M{"lock": AppLock{}}},
+	)
+	if err == mgo.ErrNotFound {
+		return ErrAppLockChanged
+	}
+	return err
+}
+
+// runningAppEvents returns the running events of the app, ignoring expired
+// events whose operation is dead.
+func runningAppEvents(appName string) ([]event.Event, error) {
+	running := true
+	evts, err := event.List(&event.Filter{
+		Target:  event.Target{Type: event.TargetTypeApp, Value: appName},
+		Running: &running,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := evts[:0]
+	for _, evt := range evts {
+		if !evt.Expired() {
+			result = append(result, evt)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestListLocks(c *check.C) {
+	acquireDate := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+	err := s.conn.Apps().Insert(
+		App{Name: "locked", Pool: "pool1", Lock: AppLock{Locked: true, Owner: "me", Reason: "deploy", AcquireDate: acquireDate}},
+		App{Name: "locked2", Pool: "pool2", Lock: AppLock{Locked: true, Owner: "me", Reason: "restart", AcquireDate: acquireDate}},
+		App{Name: "unlocked", Pool: "pool1"},
+	)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeApp, Value: "locked"},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(nil)
+	locks, err := ListLocks(&Filter{Pool: "pool1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(locks, check.HasLen, 1)
+	c.Assert(locks[0].App, check.Equals, "locked")
+	c.Assert(locks[0].Owner, check.Equals, "me")
+	c.Assert(locks[0].Reason, check.Equals, "deploy")
+	c.Assert(locks[0].AcquireDate.Equal(acquireDate), check.Equals, true)
+	c.Assert(locks[0].RunningEvents, check.DeepEquals, []string{evt.UniqueID.Hex()})
+	locks, err = ListLocks(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(locks, check.HasLen, 2)
+}
+
+func (s *S) TestForceReleaseApplicationLock(c *check.C) {
+	err := s.conn.Apps().Insert(App{Name: "locked", Lock: AppLock{Locked: true, Owner: "me", AcquireDate: time.Now()}})
+	c.Assert(err, check.IsNil)
+	err = ForceReleaseApplicationLock("locked")
+	c.Assert(err, check.IsNil)
+	a, err := GetByName("locked")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Lock.Locked, check.Equals, false)
+	err = ForceReleaseApplicationLock("locked")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestForceReleaseApplicationLockRunningEvent(c *check.C) {
+	err := s.conn.Apps().Insert(App{Name: "locked", Lock: AppLock{Locked: true, Owner: "me", AcquireDate: time.Now()}})
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeApp, Value: "locked"},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = ForceReleaseApplicationLock("locked")
+	c.Assert(err, check.DeepEquals, ErrAppLockHeld{App: "locked", EventID: evt.UniqueID.Hex(), Kind: "app.deploy"})
+	a, err := GetByName("locked")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Lock.Locked, check.Equals, true)
+	err = ForceReleaseApplicationLock("locked", evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	a, err = GetByName("locked")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Lock.Locked, check.Equals, false)
+	evt.Done(nil)
+}
+
+func (s *S) TestForceReleaseApplicationLockExpiredEvent(c *check.C) {
+	err := s.conn.Apps().Insert(App{Name: "locked", Lock: AppLock{Locked: true, Owner: "me", AcquireDate: time.Now()}})
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeApp, Value: "locked"},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	defer evt.Done(nil)
+	err = s.conn.Events().Update(bson.M{"uniqueid": evt.UniqueID}, bson.M{"$set": bson.M{"lockupdatetime": time.Now().UTC().Add(-time.Hour)}})
+	c.Assert(err, check.IsNil)
+	err = ForceReleaseApplicationLock("locked")
+	c.Assert(err, check.IsNil)
+	a, err := GetByName("locked")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Lock.Locked, check.Equals, false)
+}
+
+func (s *S) TestForceReleaseApplicationLockAppNotFound(c *check.C) {
+	err := ForceReleaseApplicationLock("unknown")
+	c.Assert(err, check.Equals, ErrAppNotFound)
+}
@@ -88,6 +88,69 @@ func init() {
 	prometheus.MustRegister(logsMongoLatency)
 }
 
+// Types of log entries, telling apart the output of the app units, the
+// requests logged by routers and the messages from tsuru itself.
+const (
+	LogTypeApp    = "app"
+	LogTypeRouter = "router"
+	LogTypeSystem = "system"
+
+	systemLogSource = "tsuru"
+)
+
+// LogTypeForSource returns the type of the log entries with the given
+// source.
+func LogTypeForSource(source string) string {
+	switch source {
+	case AccessLogSource:
+		return LogTypeRouter
+	case systemLogSource:
+		return LogTypeSystem
+	}
+	return LogTypeApp
+}
+
+// ValidLogType returns whether t is one of the known log types.
+func ValidLogType(t string) bool {
+	return t == LogTypeApp || t == LogTypeRouter || t == LogTypeSystem
+}
+
+// logFilterQuery returns the query matching the logs with the fields set in
+// filterLog. Its Type may hold several types separated by commas. Entries
+// stored before types were recorded are matched by their source.
+func logFilterQuery(filterLog Applog) bson.M {
+	q := bson.M{}
+	if filterLog.Source != "" {
+		q["source"] = filterLog.Source
+	}
+	if filterLog.Unit != "" {
+		q["unit"] = filterLog.Unit
+	}
+	if filterLog.Type == "" {
+		return q
+	}
+	var or []bson.M
+	for _, t := range strings.Split(filterLog.Type, ",") {
+		untyped := bson.M{"type": bson.M{"$exists": false}}
+		switch t {
+		case LogTypeRouter:
+			untyped["source"] = AccessLogSource
+		case LogTypeSystem:
+			untyped["source"] = systemLogSource
+		case LogTypeApp:
+			untyped["source"] = bson.M{"$nin": []string{AccessLogSource, systemLogSource}}
+		default:
+			continue
+		}
+		or = append(or, bson.M{"type": t}, untyped)
+	}
+	if len(or) == 0 {
+		or = append(or, bson.M{"type": filterLog.Type})
+	}
+	q["$or"] = or
+	return q
+}
+
 type LogListener struct {
 	c       <-chan Applog
 	logConn *db.LogStorage
@@ -131,15 +194,8 @@ func NewLogListener(a *App, filterLog Applog) (*LogListener, error) {
 	}
 	lastId := lastLog.MongoID
 	mkQuery := func() bson.M {
-		m := bson.M{
-			"_id": bson.M{"$gt": lastId},
-		}
-		if filterLog.Source != "" {
-			m["source"] = filterLog.Source
-		}
-		if filterLog.Unit != "" {
-			m["unit"] = filterLog.Unit
-		}
+		m := logFilterQuery(filterLog)
+		m["_id"] = bson.M{"$gt": lastId}
 		return m
 	}
 	query := coll.Find(mkQuery())
@@ -257,6 +313,9 @@ func (d *LogDispatcher) Send(msg *Applog) error {
 	if atomic.LoadInt32(&d.shuttingDown) == 1 {
 		return errors.New("log dispatcher is shutting down")
 	}
+	if msg.Type == "" {
+		msg.Type = LogTypeForSource(msg.Source)
+	}
 	logsInQueue.Inc()
 	logsEnqueued.Inc()
 	msgExtra := &msgWithTS{msg: msg, arriveTime: time.Now()}
@@ -0,0 +1,112 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+)
+
+// mirrorRouters returns the routers of the app able to mirror traffic.
+func (app *App) mirrorRouters() ([]router.MirrorRouter, []string, error) {
+	var routers []router.MirrorRouter
+	var names []string
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if mirrorRouter, ok := r.(router.MirrorRouter); ok && router.Supports(r, router.CapabilityMirror) {
+			routers = append(routers, mirrorRouter)
+			names = append(names, appRouter.Name)
+		}
+	}
+	return routers, names, nil
+}
+
+// SetMirror starts copying a percentage of the requests sent to the app to
+// the target app, whose responses are discarded. Both apps must share the
+// routers mirroring the traffic. A nil mirror stops the mirroring.
+func (app *App) SetMirror(mirror *router.Mirror) error {
+	routers, names, err := app.mirrorRouters()
+	if err != nil {
+		return err
+	}
+	var update bson.M
+	if mirror == nil {
+		update = bson.M{"$unset": bson.M{"mirror": ""}}
+	} else {
+		err = mirror.Validate()
+		if err != nil {
+			return err
+		}
+		if mirror.Target == app.Name {
+			return &tsuruErrors.ValidationError{Message: "An app cannot mirror traffic to itself"}
+		}
+		if len(routers) == 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("None of the routers of app %q supports traffic mirroring", app.Name)}
+		}
+		var target *App
+		target, err = GetByName(mirror.Target)
+		if err != nil {
+			return err
+		}
+		targetRouters := make(map[string]bool)
+		for _, r := range target.GetRouters() {
+			targetRouters[r.Name] = true
+		}
+		for _, name := range names {
+			if !targetRouters[name] {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Mirror target %q must use router %q", target.Name, name)}
+			}
+		}
+		update = bson.M{"$set": bson.M{"mirror": mirror}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Mirror = mirror
+	multi := tsuruErrors.NewMultiError()
+	for i, r := range routers {
+		err = r.SetMirror(app, mirror)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to set mirror in router %q", names[i]))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+// stopMirrorsTo stops the mirroring of every app sending traffic to the
+// given target.
+func stopMirrorsTo(target string) error {
+	filter := &Filter{}
+	filter.ExtraIn("mirror.target", target)
+	apps, err := List(filter)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range apps {
+		err = apps[i].SetMirror(nil)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to stop mirroring from app %q", apps[i].Name))
+		}
+	}
+	return multi.ToError()
+}
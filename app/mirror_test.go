@@ -0,0 +1,80 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetMirror(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake"}, {Name: "fake-mirror"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	target := App{Name: "myapp-next", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err = CreateApp(&target, s.user)
+	c.Assert(err, check.IsNil)
+	mirror := &router.Mirror{Target: "myapp-next", Percentage: 25}
+	err = a.SetMirror(mirror)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.MirrorRouter.Mirrors["myapp"], check.DeepEquals, *mirror)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Mirror, check.DeepEquals, mirror)
+	err = a.SetMirror(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.MirrorRouter.Mirrors, check.HasLen, 0)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Mirror, check.IsNil)
+}
+
+func (s *S) TestSetMirrorInvalid(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	other := App{Name: "otherapp", TeamOwner: s.team.Name}
+	err = CreateApp(&other, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetMirror(&router.Mirror{Target: "otherapp", Percentage: 0})
+	c.Assert(err, check.ErrorMatches, "Invalid mirror percentage, it must be between 1 and 100")
+	err = a.SetMirror(&router.Mirror{Target: "myapp", Percentage: 10})
+	c.Assert(err, check.ErrorMatches, "An app cannot mirror traffic to itself")
+	err = a.SetMirror(&router.Mirror{Target: "unknown", Percentage: 10})
+	c.Assert(err, check.Equals, ErrAppNotFound)
+	err = a.SetMirror(&router.Mirror{Target: "otherapp", Percentage: 10})
+	c.Assert(err, check.ErrorMatches, `Mirror target "otherapp" must use router "fake-mirror"`)
+	err = other.SetMirror(&router.Mirror{Target: "myapp", Percentage: 10})
+	c.Assert(err, check.ErrorMatches, `None of the routers of app "otherapp" supports traffic mirroring`)
+	c.Assert(routertest.MirrorRouter.Mirrors, check.HasLen, 0)
+}
+
+func (s *S) TestDeleteStopsMirrorsToApp(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	target := App{Name: "myapp-next", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-mirror"}}}
+	err = CreateApp(&target, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetMirror(&router.Mirror{Target: "myapp-next", Percentage: 50})
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: target.Name},
+		Kind:     permission.PermAppDelete,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = Delete(&target, evt, "")
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Mirror, check.IsNil)
+	c.Assert(routertest.MirrorRouter.Mirrors, check.HasLen, 0)
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orphan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	// ImageGCJobName is the name of the job registered by
+	// RegisterImageGCJob.
+	ImageGCJobName = "images-gc"
+
+	defaultAuditInterval = time.Hour
+)
+
+func auditInterval() time.Duration {
+	seconds, _ := config.GetInt("orphan:audit-interval")
+	if seconds <= 0 {
+		return defaultAuditInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Initialize starts auditing orphan resources periodically.
+func Initialize() error {
+	a := &auditor{once: &sync.Once{}}
+	a.start()
+	shutdown.Register(a)
+	return nil
+}
+
+type auditor struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (a *auditor) start() {
+	a.once.Do(func() {
+		a.stopCh = make(chan struct{})
+		go a.spin()
+	})
+}
+
+func (a *auditor) Shutdown(ctx context.Context) error {
+	if a.stopCh == nil {
+		return nil
+	}
+	a.stopCh <- struct{}{}
+	a.stopCh = nil
+	a.once = &sync.Once{}
+	return nil
+}
+
+func (a *auditor) spin() {
+	for {
+		resources, err := Audit(time.Now().UTC())
+		if err != nil {
+			log.Errorf("[orphan auditor] errors auditing orphan resources: %v", err)
+		}
+		if len(resources) > 0 {
+			log.Debugf("[orphan auditor] %d orphan resources found", len(resources))
+		}
+		select {
+		case <-a.stopCh:
+			return
+		case <-time.After(auditInterval()):
+		}
+	}
+}
+
+// RegisterImageGCJob registers the job that audits orphan resources and
+// removes the registry images found orphan. It's disabled by default.
+func RegisterImageGCJob() error {
+	return jobs.Register(jobs.Job{
+		Name:        ImageGCJobName,
+		Description: "removes registry images not used by any app",
+		Schedule:    "@daily",
+		Disabled:    true,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			return removeOrphanImages(evt)
+		},
+	})
+}
+
+func removeOrphanImages(w io.Writer) error {
+	multi := tsuruErrors.NewMultiError()
+	resources, err := Audit(time.Now().UTC())
+	if err != nil {
+		multi.Add(err)
+	}
+	for _, r := range resources {
+		if r.Kind != KindImage {
+			continue
+		}
+		fmt.Fprintf(w, "removing orphan image %s\n", r.Name)
+		err = Clean([]string{r.ID})
+		if err != nil {
+			multi.Add(err)
+		}
+	}
+	return multi.ToError()
+}
@@ -0,0 +1,437 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package orphan detects resources left behind by tsuru, like units of
+// removed apps, routes pointing to addresses no unit uses and registry
+// images not recorded for any deploy, including images of removed apps.
+// Detected resources are kept in a report and are only removed when
+// explicitly asked to.
+package orphan
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/registry"
+	"github.com/tsuru/tsuru/router"
+)
+
+const (
+	KindRoute = "route"
+	KindImage = "image"
+)
+
+var (
+	ErrResourceNotFound  = errors.New("orphan resource not found")
+	ErrResourceNotOrphan = errors.New("resource is no longer orphan")
+)
+
+// Resource is a resource detected as orphan. Kinds other than KindRoute and
+// KindImage are managed by the provisioner named in Provisioner.
+type Resource struct {
+	ID          string    `json:"id" bson:"_id"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	App         string    `json:"app,omitempty"`
+	Node        string    `json:"node,omitempty"`
+	Router      string    `json:"router,omitempty"`
+	Provisioner string    `json:"provisioner,omitempty"`
+	DetectedAt  time.Time `json:"detectedAt"`
+}
+
+func (r *Resource) setID() {
+	key := strings.Join([]string{r.Kind, r.Provisioner, r.Router, r.App, r.Node, r.Name}, "\x00")
+	r.ID = fmt.Sprintf("%x", sha1.Sum([]byte(key)))[:16]
+}
+
+func collection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("orphan_resources"), nil
+}
+
+// List returns the orphan resources found by the last audit.
+func List() ([]Resource, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var resources []Resource
+	err = coll.Find(nil).Sort("kind", "name").All(&resources)
+	return resources, err
+}
+
+// Audit looks for orphan resources and replaces the stored report with the
+// ones found. Resources already in the previous report keep the time they
+// were first detected. Resources found before an error are still stored.
+func Audit(now time.Time) ([]Resource, error) {
+	multi := tsuruErrors.NewMultiError()
+	var found []Resource
+	for _, fn := range []func() ([]Resource, error){provisionerResources, orphanRoutes, orphanImages} {
+		resources, err := fn()
+		if err != nil {
+			multi.Add(err)
+		}
+		found = append(found, resources...)
+	}
+	previous, err := List()
+	if err != nil {
+		return nil, err
+	}
+	detectedAt := make(map[string]time.Time, len(previous))
+	for _, r := range previous {
+		detectedAt[r.ID] = r.DetectedAt
+	}
+	for i := range found {
+		found[i].setID()
+		found[i].DetectedAt = now
+		if t, ok := detectedAt[found[i].ID]; ok {
+			found[i].DetectedAt = t
+		}
+	}
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	_, err = coll.RemoveAll(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range found {
+		_, err = coll.UpsertId(r.ID, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return found, multi.ToError()
+}
+
+// Clean removes the orphan resources with the given ids, which must be in
+// the report of the last audit. Each resource is checked again right before
+// being removed, resources no longer orphan are kept, dropped from the report
+// and reported in the returned error.
+func Clean(ids []string) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	var resources []Resource
+	err = coll.Find(bson.M{"_id": bson.M{"$in": ids}}).All(&resources)
+	if err != nil {
+		return err
+	}
+	if len(resources) != len(ids) {
+		return ErrResourceNotFound
+	}
+	multi := tsuruErrors.NewMultiError()
+	checker := orphanChecker{}
+	for _, r := range resources {
+		var orphan bool
+		orphan, err = checker.isOrphan(r)
+		if err != nil {
+			return errors.Wrapf(err, "unable to check %s %q", r.Kind, r.Name)
+		}
+		if orphan {
+			err = clean(r)
+			if err != nil {
+				return errors.Wrapf(err, "unable to remove %s %q", r.Kind, r.Name)
+			}
+		} else {
+			multi.Add(errors.Wrapf(ErrResourceNotOrphan, "%s %q", r.Kind, r.Name))
+		}
+		err = coll.RemoveId(r.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return multi.ToError()
+}
+
+// orphanChecker checks whether resources found by an audit are still
+// orphan. Orphan resources of provisioners are listed once per provisioner.
+type orphanChecker struct {
+	provResources map[string][]provision.OrphanResource
+}
+
+func (c *orphanChecker) isOrphan(r Resource) (bool, error) {
+	switch r.Kind {
+	case KindRoute:
+		routes, err := appOrphanRoutes(r.App)
+		if err != nil {
+			return false, err
+		}
+		return containsResource(routes, r), nil
+	case KindImage:
+		images, err := appOrphanImages(r.App)
+		if err != nil {
+			return false, err
+		}
+		return containsResource(images, r), nil
+	}
+	if c.provResources == nil {
+		c.provResources = map[string][]provision.OrphanResource{}
+	}
+	resources, ok := c.provResources[r.Provisioner]
+	if !ok {
+		prov, err := provision.Get(r.Provisioner)
+		if err != nil {
+			return false, err
+		}
+		orphanProv, ok := prov.(provision.OrphanProvisioner)
+		if !ok {
+			return false, errors.Errorf("provisioner %q does not manage orphan resources", r.Provisioner)
+		}
+		resources, err = orphanProv.OrphanResources()
+		if err != nil {
+			return false, err
+		}
+		c.provResources[r.Provisioner] = resources
+	}
+	for _, pr := range resources {
+		if pr.Kind == r.Kind && pr.Name == r.Name && pr.App == r.App && pr.Node == r.Node {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func containsResource(resources []Resource, r Resource) bool {
+	for _, candidate := range resources {
+		if candidate.Kind == r.Kind && candidate.Name == r.Name && candidate.App == r.App && candidate.Router == r.Router {
+			return true
+		}
+	}
+	return false
+}
+
+func clean(r Resource) error {
+	switch r.Kind {
+	case KindRoute:
+		rt, err := router.Get(r.Router)
+		if err != nil {
+			return err
+		}
+		u, err := url.Parse(r.Name)
+		if err != nil {
+			return err
+		}
+		return rt.RemoveRoutes(r.App, []*url.URL{u})
+	case KindImage:
+		return registry.RemoveImage(r.Name)
+	}
+	prov, err := provision.Get(r.Provisioner)
+	if err != nil {
+		return err
+	}
+	orphanProv, ok := prov.(provision.OrphanProvisioner)
+	if !ok {
+		return errors.Errorf("provisioner %q does not manage orphan resources", r.Provisioner)
+	}
+	return orphanProv.RemoveOrphanResource(provision.OrphanResource{
+		Kind: r.Kind,
+		Name: r.Name,
+		App:  r.App,
+		Node: r.Node,
+	})
+}
+
+func provisionerResources() ([]Resource, error) {
+	provs, err := provision.Registry()
+	if err != nil {
+		return nil, err
+	}
+	multi := tsuruErrors.NewMultiError()
+	var result []Resource
+	for _, prov := range provs {
+		orphanProv, ok := prov.(provision.OrphanProvisioner)
+		if !ok {
+			continue
+		}
+		resources, err := orphanProv.OrphanResources()
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to list orphan resources in %s provisioner", prov.GetName()))
+		}
+		for _, r := range resources {
+			result = append(result, Resource{
+				Kind:        r.Kind,
+				Name:        r.Name,
+				App:         r.App,
+				Node:        r.Node,
+				Provisioner: prov.GetName(),
+			})
+		}
+	}
+	return result, multi.ToError()
+}
+
+// orphanRoutes returns the routes of each app pointing to addresses not
+// routable to any of its units. Swapped and locked apps are skipped, as
+// their routes may be legitimately changing.
+func orphanRoutes() ([]Resource, error) {
+	apps, err := app.List(nil)
+	if err != nil {
+		return nil, err
+	}
+	multi := tsuruErrors.NewMultiError()
+	var result []Resource
+	for i := range apps {
+		routes, err := orphanRoutesForApp(&apps[i])
+		if err != nil {
+			multi.Add(err)
+		}
+		result = append(result, routes...)
+	}
+	return result, multi.ToError()
+}
+
+// appOrphanRoutes returns the orphan routes of the app with the given name,
+// none if the app no longer exists.
+func appOrphanRoutes(appName string) ([]Resource, error) {
+	a, err := app.GetByName(appName)
+	if err == app.ErrAppNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return orphanRoutesForApp(a)
+}
+
+func orphanRoutesForApp(a *app.App) ([]Resource, error) {
+	if a.Lock.Locked {
+		return nil, nil
+	}
+	swapped, _, err := router.IsSwapped(a.Name)
+	if err != nil {
+		return nil, err
+	}
+	if swapped {
+		return nil, nil
+	}
+	addresses, err := a.RoutableAddresses()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get addresses of app %q", a.Name)
+	}
+	expected := make(map[string]struct{}, len(addresses))
+	for _, addr := range addresses {
+		expected[addr.Host] = struct{}{}
+	}
+	multi := tsuruErrors.NewMultiError()
+	var result []Resource
+	for _, appRouter := range a.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		routes, err := r.Routes(a.Name)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to get routes of app %q in router %q", a.Name, appRouter.Name))
+			continue
+		}
+		for _, route := range routes {
+			if _, ok := expected[route.Host]; ok {
+				continue
+			}
+			result = append(result, Resource{
+				Kind:   KindRoute,
+				Name:   route.String(),
+				App:    a.Name,
+				Router: appRouter.Name,
+			})
+		}
+	}
+	return result, multi.ToError()
+}
+
+// orphanImages returns the app images in the registry which are not
+// recorded as deploy or build images of their app, including every image of
+// apps no longer registered in tsuru.
+func orphanImages() ([]Resource, error) {
+	apps, err := app.List(nil)
+	if err != nil {
+		return nil, err
+	}
+	registryApps, err := registry.ListAppNames()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list apps in the registry")
+	}
+	appNames := make([]string, 0, len(apps)+len(registryApps))
+	seen := make(map[string]struct{}, len(apps)+len(registryApps))
+	for _, a := range apps {
+		seen[a.Name] = struct{}{}
+		appNames = append(appNames, a.Name)
+	}
+	for _, name := range registryApps {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			appNames = append(appNames, name)
+		}
+	}
+	multi := tsuruErrors.NewMultiError()
+	var result []Resource
+	for _, name := range appNames {
+		images, err := appOrphanImages(name)
+		if err != nil {
+			multi.Add(err)
+		}
+		result = append(result, images...)
+	}
+	return result, multi.ToError()
+}
+
+// appOrphanImages returns the images of the app in the registry which are not
+// recorded as deploy or build images of the app. Apps that no longer exist
+// have no recorded images.
+func appOrphanImages(appName string) ([]Resource, error) {
+	registryImages, err := registry.ListAppImages(appName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list registry images of app %q", appName)
+	}
+	if len(registryImages) == 0 {
+		return nil, nil
+	}
+	known, err := image.ListAppImages(appName)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	builderImages, err := image.ListAppBuilderImages(appName)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	known = append(known, builderImages...)
+	knownMap := make(map[string]struct{}, len(known))
+	for _, img := range known {
+		knownMap[img] = struct{}{}
+	}
+	var result []Resource
+	for _, img := range registryImages {
+		if _, ok := knownMap[img]; ok {
+			continue
+		}
+		result = append(result, Resource{
+			Kind: KindImage,
+			Name: img,
+			App:  appName,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orphan
+
+import (
+	"bytes"
+	"net/url"
+	"time"
+
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	registrytest "github.com/tsuru/tsuru/registry/testing"
+	"github.com/tsuru/tsuru/router/routertest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAuditProvisionerResources(c *check.C) {
+	provisiontest.ProvisionerInstance.AddOrphanResource(provision.OrphanResource{
+		Kind: "unit",
+		Name: "cont1",
+		App:  "removed-app",
+		Node: "10.0.0.1",
+	})
+	now := time.Date(2018, 5, 10, 12, 0, 0, 0, time.UTC)
+	resources, err := Audit(now)
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	c.Assert(resources[0].ID, check.Not(check.Equals), "")
+	expected := Resource{
+		ID:          resources[0].ID,
+		Kind:        "unit",
+		Name:        "cont1",
+		App:         "removed-app",
+		Node:        "10.0.0.1",
+		Provisioner: "fake",
+		DetectedAt:  now,
+	}
+	c.Assert(resources[0], check.DeepEquals, expected)
+	resources, err = Audit(now.Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	c.Assert(resources[0].DetectedAt.Equal(now), check.Equals, true)
+	stored, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.HasLen, 1)
+	c.Assert(stored[0].ID, check.Equals, expected.ID)
+	c.Assert(stored[0].DetectedAt.Equal(now), check.Equals, true)
+}
+
+func (s *S) TestAuditRemovesFixedResources(c *check.C) {
+	r := provision.OrphanResource{Kind: "unit", Name: "cont1", App: "removed-app"}
+	provisiontest.ProvisionerInstance.AddOrphanResource(r)
+	_, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	err = provisiontest.ProvisionerInstance.RemoveOrphanResource(r)
+	c.Assert(err, check.IsNil)
+	resources, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 0)
+	stored, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.HasLen, 0)
+}
+
+func (s *S) TestAuditRoutes(c *check.C) {
+	a := s.newApp(c, "myapp")
+	units, err := provisiontest.ProvisionerInstance.AddUnitsToNode(a, 2, "web", nil, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+	err = routertest.FakeRouter.AddRoutes(a.Name, []*url.URL{{Scheme: "http", Host: "10.10.10.10:8080"}})
+	c.Assert(err, check.IsNil)
+	resources, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	c.Assert(resources[0].Kind, check.Equals, KindRoute)
+	c.Assert(resources[0].Name, check.Equals, "http://10.10.10.10:8080")
+	c.Assert(resources[0].App, check.Equals, "myapp")
+	c.Assert(resources[0].Router, check.Equals, "fake")
+}
+
+func (s *S) TestAuditImages(c *check.C) {
+	a := s.newApp(c, "myapp")
+	s.registry.AddRepo(registrytest.Repository{Name: "tsuru/app-myapp", Tags: map[string]string{"v1": "abcdefg", "v2": "hijklmn"}})
+	err := image.AppendAppImageName(a.Name, s.registry.Addr()+"/tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	resources, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	c.Assert(resources[0].Kind, check.Equals, KindImage)
+	c.Assert(resources[0].Name, check.Equals, s.registry.Addr()+"/tsuru/app-myapp:v2")
+	c.Assert(resources[0].App, check.Equals, "myapp")
+}
+
+func (s *S) TestAuditImagesOfRemovedApps(c *check.C) {
+	s.registry.AddRepo(registrytest.Repository{Name: "tsuru/app-removed", Tags: map[string]string{"v1": "abcdefg"}})
+	s.registry.AddRepo(registrytest.Repository{Name: "tsuru/python", Tags: map[string]string{"latest": "hijklmn"}})
+	resources, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	c.Assert(resources[0].Kind, check.Equals, KindImage)
+	c.Assert(resources[0].Name, check.Equals, s.registry.Addr()+"/tsuru/app-removed:v1")
+	c.Assert(resources[0].App, check.Equals, "removed")
+}
+
+func (s *S) TestRemoveOrphanImages(c *check.C) {
+	a := s.newApp(c, "myapp")
+	s.registry.AddRepo(registrytest.Repository{Name: "tsuru/app-myapp", Tags: map[string]string{"v1": "abcdefg", "v2": "hijklmn"}})
+	err := image.AppendAppImageName(a.Name, s.registry.Addr()+"/tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	err = routertest.FakeRouter.AddRoutes(a.Name, []*url.URL{{Scheme: "http", Host: "10.10.10.10:8080"}})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = removeOrphanImages(&buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "removing orphan image "+s.registry.Addr()+"/tsuru/app-myapp:v2\n")
+	c.Assert(s.registry.Repos[0].Tags, check.DeepEquals, map[string]string{"v1": "abcdefg"})
+	c.Assert(routertest.FakeRouter.HasRoute(a.Name, "http://10.10.10.10:8080"), check.Equals, true)
+	stored, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.HasLen, 1)
+	c.Assert(stored[0].Kind, check.Equals, KindRoute)
+}
+
+func (s *S) TestClean(c *check.C) {
+	a := s.newApp(c, "myapp")
+	err := routertest.FakeRouter.AddRoutes(a.Name, []*url.URL{{Scheme: "http", Host: "10.10.10.10:8080"}})
+	c.Assert(err, check.IsNil)
+	provisiontest.ProvisionerInstance.AddOrphanResource(provision.OrphanResource{Kind: "unit", Name: "cont1"})
+	resources, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 2)
+	err = Clean([]string{resources[0].ID, resources[1].ID})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.HasRoute(a.Name, "http://10.10.10.10:8080"), check.Equals, false)
+	orphans, err := provisiontest.ProvisionerInstance.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 0)
+	stored, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.HasLen, 0)
+}
+
+func (s *S) TestCleanSkipsResourcesNoLongerOrphan(c *check.C) {
+	a := s.newApp(c, "myapp")
+	s.registry.AddRepo(registrytest.Repository{Name: "tsuru/app-myapp", Tags: map[string]string{"v1": "abcdefg", "v2": "hijklmn"}})
+	err := image.AppendAppImageName(a.Name, s.registry.Addr()+"/tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	r := provision.OrphanResource{Kind: "unit", Name: "cont1"}
+	provisiontest.ProvisionerInstance.AddOrphanResource(r)
+	resources, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 2)
+	err = image.AppendAppImageName(a.Name, s.registry.Addr()+"/tsuru/app-myapp:v2")
+	c.Assert(err, check.IsNil)
+	err = provisiontest.ProvisionerInstance.RemoveOrphanResource(r)
+	c.Assert(err, check.IsNil)
+	err = Clean([]string{resources[0].ID, resources[1].ID})
+	c.Assert(err, check.ErrorMatches, `(?s).*resource is no longer orphan.*`)
+	c.Assert(s.registry.Repos[0].Tags, check.HasLen, 2)
+	stored, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.HasLen, 0)
+}
+
+func (s *S) TestCleanNotFound(c *check.C) {
+	provisiontest.ProvisionerInstance.AddOrphanResource(provision.OrphanResource{Kind: "unit", Name: "cont1"})
+	resources, err := Audit(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 1)
+	err = Clean([]string{resources[0].ID, "unknown"})
+	c.Assert(err, check.Equals, ErrResourceNotFound)
+	orphans, err := provisiontest.ProvisionerInstance.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 1)
+}
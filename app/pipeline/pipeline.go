@@ -0,0 +1,601 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pipeline runs deploy pipelines defined by apps. A pipeline is an
+// ordered list of stages, like building the app image, running tests in an
+// ephemeral unit, deploying the image to other apps and waiting for manual
+// approvals. Each stage that acts on an app is recorded as an event of the
+// app, tagged with the pipeline run and stage.
+package pipeline
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const (
+	StageBuild    = "build"
+	StageTest     = "test"
+	StageDeploy   = "deploy"
+	StageApproval = "approval"
+
+	StatusPending         = "pending"
+	StatusRunning         = "running"
+	StatusWaitingApproval = "waiting-approval"
+	StatusSucceeded       = "succeeded"
+	StatusFailed          = "failed"
+	StatusRejected        = "rejected"
+	StatusSkipped         = "skipped"
+
+	deployOrigin = "pipeline"
+)
+
+var (
+	ErrPipelineNotFound   = errors.New("pipeline not found")
+	ErrRunNotFound        = errors.New("pipeline run not found")
+	ErrRunInProgress      = errors.New("there's already a run in progress for this pipeline")
+	ErrNotWaitingApproval = errors.New("pipeline run is not waiting for approval")
+
+	// runStages runs the stages of a pipeline run starting from the given
+	// index, it's replaced in tests to run synchronously.
+	runStages = func(r *Run, from int) { go r.execute(from) }
+
+	// heartbeatInterval is how often a running stage refreshes the heartbeat
+	// of its run. Runs whose heartbeat is older than abandonTimeout were
+	// abandoned, e.g. by a restart of the API, and are failed by Start.
+	heartbeatInterval = 30 * time.Second
+	abandonTimeout    = 5 * time.Minute
+)
+
+// Stage is a step of a pipeline. Build stages build the image of the
+// pipeline app, test stages run Command in an ephemeral unit of App, deploy
+// stages deploy the built image, or the current image of the pipeline app
+// when there's no build stage, to App and approval stages wait for a user to
+// approve the run.
+type Stage struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	App     string `json:"app,omitempty"`
+	Command string `json:"command,omitempty"`
+}
+
+// Pipeline holds the stages of the pipeline defined by an app.
+type Pipeline struct {
+	App    string  `bson:"_id" json:"app"`
+	Stages []Stage `json:"stages"`
+}
+
+// StageRun is the state of a stage in a pipeline run. Event is the id of the
+// event recording the stage, if any.
+type StageRun struct {
+	Stage      `bson:",inline"`
+	Status     string    `json:"status"`
+	Event      string    `json:"event,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	ApprovedBy string    `json:"approvedBy,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+}
+
+// Run is an execution of a pipeline.
+type Run struct {
+	ID         bson.ObjectId `bson:"_id" json:"id"`
+	App        string        `json:"app"`
+	Status     string        `json:"status"`
+	Owner      string        `json:"owner"`
+	ArchiveURL string        `json:"archiveURL,omitempty"`
+	Image      string        `json:"image,omitempty"`
+	Stages     []StageRun    `json:"stages"`
+	StartTime  time.Time     `json:"startTime"`
+	EndTime    time.Time     `json:"endTime"`
+	Heartbeat  time.Time     `json:"heartbeat"`
+	// Active holds the app name while the run isn't finished, it's
+	// unique so only one run per app is active at a time.
+	Active string `bson:",omitempty" json:"-"`
+}
+
+// StartOpts are the options used to start a pipeline run. ArchiveURL is
+// required by pipelines with a build stage.
+type StartOpts struct {
+	ArchiveURL string
+	Owner      string
+}
+
+func pipelinesCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("app_pipelines"), nil
+}
+
+func runsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_pipeline_runs")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "-starttime"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"active"}, Unique: true, Sparse: true})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func (p *Pipeline) validate() error {
+	if len(p.Stages) == 0 {
+		return &tsuruErrors.ValidationError{Message: "pipeline must have at least one stage"}
+	}
+	names := map[string]bool{}
+	var hasBuild, hasDeploy bool
+	for i := range p.Stages {
+		s := &p.Stages[i]
+		if s.Name == "" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("stage %d has no name", i)}
+		}
+		if names[s.Name] {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("duplicated stage name %q", s.Name)}
+		}
+		names[s.Name] = true
+		switch s.Kind {
+		case StageBuild:
+			if hasBuild {
+				return &tsuruErrors.ValidationError{Message: "pipeline can have only one build stage"}
+			}
+			if hasDeploy {
+				return &tsuruErrors.ValidationError{Message: "build stage must come before deploy stages"}
+			}
+			if s.App != "" && s.App != p.App {
+				return &tsuruErrors.ValidationError{Message: "build stage can only build the pipeline app"}
+			}
+			hasBuild = true
+			s.App = p.App
+		case StageTest:
+			if s.Command == "" {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("test stage %q has no command", s.Name)}
+			}
+			if s.App == "" {
+				s.App = p.App
+			}
+		case StageDeploy:
+			if s.App == "" {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("deploy stage %q has no app", s.Name)}
+			}
+			hasDeploy = true
+		case StageApproval:
+			if s.App != "" || s.Command != "" {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("approval stage %q can't have an app or command", s.Name)}
+			}
+		default:
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("invalid kind %q for stage %q, valid kinds are: %s, %s, %s, %s", s.Kind, s.Name, StageBuild, StageTest, StageDeploy, StageApproval),
+			}
+		}
+	}
+	for _, s := range p.Stages {
+		if s.App == "" || s.App == p.App {
+			continue
+		}
+		_, err := app.GetByName(s.App)
+		if err == app.ErrAppNotFound {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("app %q of stage %q not found", s.App, s.Name)}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) hasStage(kind string) bool {
+	for _, s := range p.Stages {
+		if s.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Apps returns the names of the apps the stages of the pipeline act on.
+func (p *Pipeline) Apps() []string {
+	var apps []string
+	seen := map[string]bool{}
+	for _, s := range p.Stages {
+		if s.App != "" && !seen[s.App] {
+			seen[s.App] = true
+			apps = append(apps, s.App)
+		}
+	}
+	return apps
+}
+
+// Save validates and stores the pipeline, replacing any pipeline previously
+// defined by the app.
+func Save(p *Pipeline) error {
+	err := p.validate()
+	if err != nil {
+		return err
+	}
+	coll, err := pipelinesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.UpsertId(p.App, p)
+	return err
+}
+
+// Get returns the pipeline defined by the app.
+func Get(appName string) (*Pipeline, error) {
+	coll, err := pipelinesCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var p Pipeline
+	err = coll.FindId(appName).One(&p)
+	if err == mgo.ErrNotFound {
+		return nil, ErrPipelineNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Remove removes the pipeline defined by the app. Runs are kept.
+func Remove(appName string) error {
+	coll, err := pipelinesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.RemoveId(appName)
+	if err == mgo.ErrNotFound {
+		return ErrPipelineNotFound
+	}
+	return err
+}
+
+// Start starts a run of the pipeline defined by the app. Stages run in
+// background and the run may be followed with GetRun.
+func Start(appName string, opts StartOpts) (*Run, error) {
+	p, err := Get(appName)
+	if err != nil {
+		return nil, err
+	}
+	run := &Run{
+		ID:         bson.NewObjectId(),
+		App:        appName,
+		Status:     StatusRunning,
+		Owner:      opts.Owner,
+		ArchiveURL: opts.ArchiveURL,
+		StartTime:  time.Now().UTC(),
+		Heartbeat:  time.Now().UTC(),
+		Active:     appName,
+	}
+	if p.hasStage(StageBuild) {
+		if opts.ArchiveURL == "" {
+			return nil, &tsuruErrors.ValidationError{Message: "archive URL is required by the build stage"}
+		}
+	} else if p.hasStage(StageDeploy) {
+		run.Image, err = image.AppCurrentImageName(appName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get current image of app %q", appName)
+		}
+	}
+	for _, s := range p.Stages {
+		run.Stages = append(run.Stages, StageRun{Stage: s, Status: StatusPending})
+	}
+	coll, err := runsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	err = failAbandonedRuns(coll, appName)
+	if err != nil {
+		return nil, err
+	}
+	err = coll.Insert(run)
+	if mgo.IsDup(err) {
+		return nil, ErrRunInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+	runStages(run, 0)
+	return run, nil
+}
+
+// failAbandonedRuns fails the running runs of the app that stopped sending
+// heartbeats, releasing the app for a new run.
+func failAbandonedRuns(coll *storage.Collection, appName string) error {
+	var runs []Run
+	err := coll.Find(bson.M{
+		"app":       appName,
+		"status":    StatusRunning,
+		"heartbeat": bson.M{"$lt": time.Now().UTC().Add(-abandonTimeout)},
+	}).All(&runs)
+	if err != nil {
+		return err
+	}
+	for _, r := range runs {
+		lastHeartbeat := r.Heartbeat
+		for i := range r.Stages {
+			s := &r.Stages[i]
+			if s.Status != StatusRunning && s.Status != StatusPending {
+				continue
+			}
+			s.Status = StatusFailed
+			s.Error = fmt.Sprintf("pipeline run abandoned, no progress since %s", lastHeartbeat.Format(time.RFC3339))
+			s.EndTime = time.Now().UTC()
+			r.finish(StatusFailed, i+1)
+			break
+		}
+		if r.Status != StatusFailed {
+			r.finish(StatusFailed, len(r.Stages))
+		}
+		r.Heartbeat = time.Now().UTC()
+		err = coll.Update(bson.M{"_id": r.ID, "status": StatusRunning, "heartbeat": lastHeartbeat}, &r)
+		if err != nil && err != mgo.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRun returns the pipeline run with the given id.
+func GetRun(id string) (*Run, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, ErrRunNotFound
+	}
+	coll, err := runsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var run Run
+	err = coll.FindId(bson.ObjectIdHex(id)).One(&run)
+	if err == mgo.ErrNotFound {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRuns returns the last runs of the pipeline defined by the app, newest
+// first.
+func ListRuns(appName string, limit int) ([]Run, error) {
+	coll, err := runsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	query := coll.Find(bson.M{"app": appName}).Sort("-starttime")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var runs []Run
+	err = query.All(&runs)
+	return runs, err
+}
+
+// Approve resumes a run waiting for approval. A rejected run is finished
+// and its remaining stages are skipped.
+func (r *Run) Approve(user string, approved bool) error {
+	coll, err := runsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	newStatus := StatusRunning
+	if !approved {
+		newStatus = StatusRejected
+	}
+	err = coll.Update(
+		bson.M{"_id": r.ID, "status": StatusWaitingApproval},
+		bson.M{"$set": bson.M{"status": newStatus}},
+	)
+	if err == mgo.ErrNotFound {
+		return ErrNotWaitingApproval
+	}
+	if err != nil {
+		return err
+	}
+	r.Status = newStatus
+	for i := range r.Stages {
+		s := &r.Stages[i]
+		if s.Status != StatusWaitingApproval {
+			continue
+		}
+		s.ApprovedBy = user
+		s.EndTime = time.Now().UTC()
+		if !approved {
+			s.Status = StatusRejected
+			r.finish(StatusRejected, i+1)
+			return r.save()
+		}
+		s.Status = StatusSucceeded
+		err = r.save()
+		if err != nil {
+			return err
+		}
+		runStages(r, i+1)
+		return nil
+	}
+	return r.save()
+}
+
+func (r *Run) save() error {
+	coll, err := runsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	r.Heartbeat = time.Now().UTC()
+	return coll.UpdateId(r.ID, r)
+}
+
+// keepAlive refreshes the heartbeat of the run until the returned channel is
+// closed.
+func (r *Run) keepAlive() chan<- struct{} {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			coll, err := runsCollection()
+			if err != nil {
+				log.Errorf("[pipeline] unable to refresh heartbeat of run %s: %v", r.ID.Hex(), err)
+				continue
+			}
+			err = coll.Update(
+				bson.M{"_id": r.ID, "status": StatusRunning},
+				bson.M{"$set": bson.M{"heartbeat": time.Now().UTC()}},
+			)
+			coll.Close()
+			if err != nil {
+				log.Errorf("[pipeline] unable to refresh heartbeat of run %s: %v", r.ID.Hex(), err)
+			}
+		}
+	}()
+	return stop
+}
+
+// finish sets the final status of the run, skipping the stages starting from
+// the given index.
+func (r *Run) finish(status string, from int) {
+	for i := from; i < len(r.Stages); i++ {
+		r.Stages[i].Status = StatusSkipped
+	}
+	r.Status = status
+	r.EndTime = time.Now().UTC()
+	r.Active = ""
+}
+
+func (r *Run) execute(from int) {
+	for i := from; i < len(r.Stages); i++ {
+		s := &r.Stages[i]
+		s.StartTime = time.Now().UTC()
+		if s.Kind == StageApproval {
+			s.Status = StatusWaitingApproval
+			r.Status = StatusWaitingApproval
+			r.logSaveError(r.save())
+			return
+		}
+		s.Status = StatusRunning
+		r.logSaveError(r.save())
+		stop := r.keepAlive()
+		err := r.runStage(s)
+		close(stop)
+		s.EndTime = time.Now().UTC()
+		if err != nil {
+			s.Status = StatusFailed
+			s.Error = err.Error()
+			r.finish(StatusFailed, i+1)
+			r.logSaveError(r.save())
+			return
+		}
+		s.Status = StatusSucceeded
+	}
+	r.finish(StatusSucceeded, len(r.Stages))
+	r.logSaveError(r.save())
+}
+
+func (r *Run) logSaveError(err error) {
+	if err != nil {
+		log.Errorf("[pipeline] unable to save run %s of app %q: %v", r.ID.Hex(), r.App, err)
+	}
+}
+
+func (r *Run) runStage(s *StageRun) (err error) {
+	a, err := app.GetByName(s.App)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("pipeline run %s, stage %q", r.ID.Hex(), s.Name)
+	deployOpts := app.DeployOptions{
+		App:          a,
+		User:         r.Owner,
+		Origin:       deployOrigin,
+		Message:      message,
+		OutputStream: ioutil.Discard,
+	}
+	var (
+		kind       *permission.PermissionScheme
+		customData interface{}
+	)
+	switch s.Kind {
+	case StageBuild:
+		kind = permission.PermAppBuild
+		deployOpts.ArchiveURL = r.ArchiveURL
+		deployOpts.Kind = app.DeployArchiveURL
+		customData = deployOpts
+	case StageDeploy:
+		kind = permission.PermAppDeploy
+		deployOpts.Image = r.Image
+		deployOpts.Kind = app.DeployImage
+		customData = deployOpts
+	case StageTest:
+		kind = permission.PermAppRun
+		customData = map[string]string{"command": s.Command, "message": message}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		Kind:       kind,
+		RawOwner:   event.Owner{Type: event.OwnerTypeUser, Name: r.Owner},
+		CustomData: customData,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, a.Teams),
+			permission.Context(permission.CtxApp, a.Name),
+			permission.Context(permission.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		return err
+	}
+	var imageID string
+	defer func() { evt.DoneCustomData(err, map[string]string{"image": imageID}) }()
+	s.Event = evt.UniqueID.Hex()
+	r.logSaveError(r.save())
+	deployOpts.Event = evt
+	switch s.Kind {
+	case StageBuild:
+		imageID, err = app.Build(deployOpts)
+		if err == nil {
+			r.Image = imageID
+		}
+	case StageDeploy:
+		imageID, err = app.Deploy(deployOpts)
+	case StageTest:
+		err = a.Run(s.Command, evt, provision.RunArgs{Isolated: true})
+	}
+	return err
+}
@@ -0,0 +1,272 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pipeline
+
+import (
+	"errors"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/builder"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSaveAndGet(c *check.C) {
+	s.newApp(c, "myapp")
+	s.newApp(c, "myapp-prod")
+	p := Pipeline{App: "myapp", Stages: []Stage{
+		{Name: "build", Kind: StageBuild},
+		{Name: "unit", Kind: StageTest, Command: "make test"},
+		{Name: "approve", Kind: StageApproval},
+		{Name: "prod", Kind: StageDeploy, App: "myapp-prod"},
+	}}
+	err := Save(&p)
+	c.Assert(err, check.IsNil)
+	dbPipeline, err := Get("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbPipeline, check.DeepEquals, &Pipeline{App: "myapp", Stages: []Stage{
+		{Name: "build", Kind: StageBuild, App: "myapp"},
+		{Name: "unit", Kind: StageTest, App: "myapp", Command: "make test"},
+		{Name: "approve", Kind: StageApproval},
+		{Name: "prod", Kind: StageDeploy, App: "myapp-prod"},
+	}})
+	c.Assert(dbPipeline.Apps(), check.DeepEquals, []string{"myapp", "myapp-prod"})
+	err = Remove("myapp")
+	c.Assert(err, check.IsNil)
+	_, err = Get("myapp")
+	c.Assert(err, check.Equals, ErrPipelineNotFound)
+	err = Remove("myapp")
+	c.Assert(err, check.Equals, ErrPipelineNotFound)
+}
+
+func (s *S) TestSaveInvalid(c *check.C) {
+	s.newApp(c, "myapp")
+	tests := []struct {
+		stages []Stage
+		msg    string
+	}{
+		{nil, "pipeline must have at least one stage"},
+		{[]Stage{{Kind: StageBuild}}, "stage 0 has no name"},
+		{[]Stage{{Name: "a", Kind: StageApproval}, {Name: "a", Kind: StageApproval}}, `duplicated stage name "a"`},
+		{[]Stage{{Name: "a", Kind: "lint"}}, `invalid kind "lint" for stage "a", valid kinds are: build, test, deploy, approval`},
+		{[]Stage{{Name: "a", Kind: StageTest}}, `test stage "a" has no command`},
+		{[]Stage{{Name: "a", Kind: StageDeploy}}, `deploy stage "a" has no app`},
+		{[]Stage{{Name: "a", Kind: StageBuild}, {Name: "b", Kind: StageBuild}}, "pipeline can have only one build stage"},
+		{[]Stage{{Name: "a", Kind: StageDeploy, App: "myapp"}, {Name: "b", Kind: StageBuild}}, "build stage must come before deploy stages"},
+		{[]Stage{{Name: "a", Kind: StageBuild, App: "other"}}, "build stage can only build the pipeline app"},
+		{[]Stage{{Name: "a", Kind: StageApproval, Command: "ls"}}, `approval stage "a" can't have an app or command`},
+		{[]Stage{{Name: "a", Kind: StageDeploy, App: "unknown"}}, `app "unknown" of stage "a" not found`},
+	}
+	for _, tt := range tests {
+		err := Save(&Pipeline{App: "myapp", Stages: tt.stages})
+		c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: tt.msg})
+	}
+}
+
+func (s *S) TestStartRunsStages(c *check.C) {
+	a := s.newApp(c, "myapp")
+	s.newApp(c, "myapp-staging")
+	var buildOpts *builder.BuildOpts
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (string, error) {
+		buildOpts = opts
+		return "registry.example.com/tsuru/app-myapp:v1-builder", nil
+	}
+	err := Save(&Pipeline{App: "myapp", Stages: []Stage{
+		{Name: "build", Kind: StageBuild},
+		{Name: "unit", Kind: StageTest, Command: "make test"},
+		{Name: "staging", Kind: StageDeploy, App: "myapp-staging"},
+	}})
+	c.Assert(err, check.IsNil)
+	provisiontest.ProvisionerInstance.PrepareOutput([]byte("ok"))
+	run, err := Start("myapp", StartOpts{ArchiveURL: "http://example.com/myapp.tar.gz", Owner: s.user.Email})
+	c.Assert(err, check.IsNil)
+	c.Assert(buildOpts, check.NotNil)
+	c.Assert(buildOpts.ArchiveURL, check.Equals, "http://example.com/myapp.tar.gz")
+	dbRun, err := GetRun(run.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, StatusSucceeded)
+	c.Assert(dbRun.Image, check.Equals, "registry.example.com/tsuru/app-myapp:v1-builder")
+	c.Assert(dbRun.Stages, check.HasLen, 3)
+	for _, stage := range dbRun.Stages {
+		c.Assert(stage.Status, check.Equals, StatusSucceeded)
+		c.Assert(stage.Event, check.Not(check.Equals), "")
+		evt, err := event.GetByID(bson.ObjectIdHex(stage.Event))
+		c.Assert(err, check.IsNil)
+		c.Assert(evt.Target, check.DeepEquals, event.Target{Type: event.TargetTypeApp, Value: stage.App})
+		c.Assert(evt.Owner, check.DeepEquals, event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email})
+	}
+	cmds := provisiontest.ProvisionerInstance.GetCmds("", a)
+	c.Assert(cmds, check.HasLen, 1)
+	c.Assert(cmds[0].Cmd, check.Matches, `.*make test$`)
+}
+
+func (s *S) TestStartFailedStageSkipsRemaining(c *check.C) {
+	s.newApp(c, "myapp")
+	s.newApp(c, "myapp-staging")
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (string, error) {
+		return "registry.example.com/tsuru/app-myapp:v1-builder", nil
+	}
+	err := Save(&Pipeline{App: "myapp", Stages: []Stage{
+		{Name: "build", Kind: StageBuild},
+		{Name: "unit", Kind: StageTest, Command: "make test"},
+		{Name: "staging", Kind: StageDeploy, App: "myapp-staging"},
+	}})
+	c.Assert(err, check.IsNil)
+	provisiontest.ProvisionerInstance.PrepareFailure("ExecuteCommandIsolated", errors.New("tests failed"))
+	run, err := Start("myapp", StartOpts{ArchiveURL: "http://example.com/myapp.tar.gz", Owner: s.user.Email})
+	c.Assert(err, check.IsNil)
+	dbRun, err := GetRun(run.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, StatusFailed)
+	c.Assert(dbRun.Stages[0].Status, check.Equals, StatusSucceeded)
+	c.Assert(dbRun.Stages[1].Status, check.Equals, StatusFailed)
+	c.Assert(dbRun.Stages[1].Error, check.Equals, "tests failed")
+	c.Assert(dbRun.Stages[2].Status, check.Equals, StatusSkipped)
+	c.Assert(dbRun.Stages[2].Event, check.Equals, "")
+}
+
+func (s *S) TestStartRequiresArchiveURLForBuild(c *check.C) {
+	s.newApp(c, "myapp")
+	err := Save(&Pipeline{App: "myapp", Stages: []Stage{{Name: "build", Kind: StageBuild}}})
+	c.Assert(err, check.IsNil)
+	_, err = Start("myapp", StartOpts{Owner: s.user.Email})
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "archive URL is required by the build stage"})
+	_, err = Start("otherapp", StartOpts{Owner: s.user.Email})
+	c.Assert(err, check.Equals, ErrPipelineNotFound)
+}
+
+func (s *S) TestStartFailsAbandonedRun(c *check.C) {
+	s.newApp(c, "myapp")
+	err := Save(&Pipeline{App: "myapp", Stages: []Stage{
+		{Name: "unit", Kind: StageTest, Command: "make test"},
+		{Name: "lint", Kind: StageTest, Command: "make lint"},
+	}})
+	c.Assert(err, check.IsNil)
+	coll, err := runsCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	abandoned := Run{
+		ID:        bson.NewObjectId(),
+		App:       "myapp",
+		Status:    StatusRunning,
+		StartTime: time.Now().UTC().Add(-time.Hour),
+		Heartbeat: time.Now().UTC().Add(-time.Hour),
+		Active:    "myapp",
+		Stages: []StageRun{
+			{Stage: Stage{Name: "unit", Kind: StageTest, Command: "make test"}, Status: StatusRunning},
+			{Stage: Stage{Name: "lint", Kind: StageTest, Command: "make lint"}, Status: StatusPending},
+		},
+	}
+	err = coll.Insert(abandoned)
+	c.Assert(err, check.IsNil)
+	provisiontest.ProvisionerInstance.PrepareOutput([]byte("ok"))
+	provisiontest.ProvisionerInstance.PrepareOutput([]byte("ok"))
+	run, err := Start("myapp", StartOpts{Owner: s.user.Email})
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Status, check.Equals, StatusSucceeded)
+	dbRun, err := GetRun(abandoned.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, StatusFailed)
+	c.Assert(dbRun.Active, check.Equals, "")
+	c.Assert(dbRun.Stages[0].Status, check.Equals, StatusFailed)
+	c.Assert(dbRun.Stages[0].Error, check.Matches, "pipeline run abandoned, no progress since .*")
+	c.Assert(dbRun.Stages[1].Status, check.Equals, StatusSkipped)
+}
+
+func (s *S) TestStartRunInProgress(c *check.C) {
+	s.newApp(c, "myapp")
+	err := Save(&Pipeline{App: "myapp", Stages: []Stage{{Name: "unit", Kind: StageTest, Command: "make test"}}})
+	c.Assert(err, check.IsNil)
+	coll, err := runsCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	running := Run{
+		ID:        bson.NewObjectId(),
+		App:       "myapp",
+		Status:    StatusRunning,
+		StartTime: time.Now().UTC(),
+		Heartbeat: time.Now().UTC(),
+		Active:    "myapp",
+		Stages:    []StageRun{{Stage: Stage{Name: "unit", Kind: StageTest, Command: "make test"}, Status: StatusRunning}},
+	}
+	err = coll.Insert(running)
+	c.Assert(err, check.IsNil)
+	_, err = Start("myapp", StartOpts{Owner: s.user.Email})
+	c.Assert(err, check.Equals, ErrRunInProgress)
+	dbRun, err := GetRun(running.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, StatusRunning)
+}
+
+func (s *S) TestApprove(c *check.C) {
+	s.newApp(c, "myapp")
+	s.newApp(c, "myapp-prod")
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (string, error) {
+		return "registry.example.com/tsuru/app-myapp:v1-builder", nil
+	}
+	err := Save(&Pipeline{App: "myapp", Stages: []Stage{
+		{Name: "build", Kind: StageBuild},
+		{Name: "approve", Kind: StageApproval},
+		{Name: "prod", Kind: StageDeploy, App: "myapp-prod"},
+	}})
+	c.Assert(err, check.IsNil)
+	run, err := Start("myapp", StartOpts{ArchiveURL: "http://example.com/myapp.tar.gz", Owner: s.user.Email})
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Status, check.Equals, StatusWaitingApproval)
+	c.Assert(run.Stages[1].Status, check.Equals, StatusWaitingApproval)
+	c.Assert(run.Stages[2].Status, check.Equals, StatusPending)
+	_, err = Start("myapp", StartOpts{ArchiveURL: "http://example.com/myapp.tar.gz", Owner: s.user.Email})
+	c.Assert(err, check.Equals, ErrRunInProgress)
+	dbRun, err := GetRun(run.ID.Hex())
+	c.Assert(err, check.IsNil)
+	err = dbRun.Approve("approver@example.com", true)
+	c.Assert(err, check.IsNil)
+	dbRun, err = GetRun(run.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, StatusSucceeded)
+	c.Assert(dbRun.Stages[1].Status, check.Equals, StatusSucceeded)
+	c.Assert(dbRun.Stages[1].ApprovedBy, check.Equals, "approver@example.com")
+	c.Assert(dbRun.Stages[2].Status, check.Equals, StatusSucceeded)
+	err = dbRun.Approve("approver@example.com", true)
+	c.Assert(err, check.Equals, ErrNotWaitingApproval)
+}
+
+func (s *S) TestReject(c *check.C) {
+	s.newApp(c, "myapp")
+	s.newApp(c, "myapp-prod")
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (string, error) {
+		return "registry.example.com/tsuru/app-myapp:v1-builder", nil
+	}
+	err := Save(&Pipeline{App: "myapp", Stages: []Stage{
+		{Name: "build", Kind: StageBuild},
+		{Name: "approve", Kind: StageApproval},
+		{Name: "prod", Kind: StageDeploy, App: "myapp-prod"},
+	}})
+	c.Assert(err, check.IsNil)
+	run, err := Start("myapp", StartOpts{ArchiveURL: "http://example.com/myapp.tar.gz", Owner: s.user.Email})
+	c.Assert(err, check.IsNil)
+	err = run.Approve("approver@example.com", false)
+	c.Assert(err, check.IsNil)
+	dbRun, err := GetRun(run.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, StatusRejected)
+	c.Assert(dbRun.Stages[1].Status, check.Equals, StatusRejected)
+	c.Assert(dbRun.Stages[2].Status, check.Equals, StatusSkipped)
+	runs, err := ListRuns("myapp", 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].ID, check.Equals, run.ID)
+}
+
+func (s *S) TestGetRunNotFound(c *check.C) {
+	_, err := GetRun("invalid")
+	c.Assert(err, check.Equals, ErrRunNotFound)
+	_, err = GetRun("5b4e1e1f2d8b4c5e3a9f0c11")
+	c.Assert(err, check.Equals, ErrRunNotFound)
+}
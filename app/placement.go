@@ -0,0 +1,73 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+)
+
+// SetMinZones sets the minimum number of distinct availability zones the
+// units of each process of the app must be spread across. Zero removes the
+// constraint.
+func (app *App) SetMinZones(n int) error {
+	if n < 0 {
+		return &tsuruErrors.ValidationError{Message: "minimum number of zones must not be negative"}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var update bson.M
+	if n == 0 {
+		update = bson.M{"$unset": bson.M{"minzones": ""}}
+	} else {
+		update = bson.M{"$set": bson.M{"minzones": n}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.MinZones = n
+	return nil
+}
+
+// fillUnitsPlacement sets the node and the availability zone of the units,
+// matching their addresses with the nodes of the app provisioner.
+func (app *App) fillUnitsPlacement(units []provision.Unit) error {
+	if len(units) == 0 {
+		return nil
+	}
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return err
+	}
+	nodeProv, ok := prov.(provision.NodeProvisioner)
+	if !ok {
+		return nil
+	}
+	nodes, err := nodeProv.ListNodes(nil)
+	if err != nil {
+		return err
+	}
+	nodeMap := make(map[string]provision.Node, len(nodes))
+	for _, n := range nodes {
+		nodeMap[net.URLToHost(n.Address())] = n
+	}
+	zoneMetadata := provision.ZoneMetadataName()
+	for i := range units {
+		n, ok := nodeMap[units[i].IP]
+		if !ok {
+			continue
+		}
+		units[i].Node = n.Address()
+		units[i].Zone = n.Metadata()[zoneMetadata]
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetMinZones(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetMinZones(2)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.MinZones, check.Equals, 2)
+	err = a.SetMinZones(0)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.MinZones, check.Equals, 0)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.MinZones, check.Equals, 0)
+}
+
+func (s *S) TestSetMinZonesInvalid(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetMinZones(-1)
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+}
+
+func (s *S) TestAppMarshalJSONUnitsPlacement(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, MinZones: 2}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(provision.AddNodeOptions{
+		Address:  "http://addr1:2375",
+		Metadata: map[string]string{"zone": "zone-a"},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = s.provisioner.AddUnitsToNode(&a, 1, "web", nil, "http://addr1:2375")
+	c.Assert(err, check.IsNil)
+	data, err := a.MarshalJSON()
+	c.Assert(err, check.IsNil)
+	var result struct {
+		MinZones int `json:"minZones"`
+		Units    []struct {
+			Node string
+			Zone string
+		}
+	}
+	err = json.Unmarshal(data, &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.MinZones, check.Equals, 2)
+	c.Assert(result.Units, check.HasLen, 1)
+	c.Assert(result.Units[0].Node, check.Equals, "http://addr1:2375")
+	c.Assert(result.Units[0].Zone, check.Equals, "zone-a")
+}
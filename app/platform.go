@@ -5,14 +5,21 @@
 package app
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
 	"strconv"
 
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	"github.com/tsuru/tsuru/validation"
@@ -43,6 +50,9 @@ func (s *platformService) Create(opts appTypes.PlatformOptions) error {
 	if err := s.validate(p); err != nil {
 		return err
 	}
+	if err := validatePlatformBuild(opts); err != nil {
+		return err
+	}
 	err := s.storage.Insert(p)
 	if err != nil {
 		return err
@@ -58,6 +68,7 @@ func (s *platformService) Create(opts appTypes.PlatformOptions) error {
 		}
 		return err
 	}
+	prepullPlatformImage(opts)
 	return nil
 }
 
@@ -88,15 +99,20 @@ func (s *platformService) Update(opts appTypes.PlatformOptions) error {
 		return err
 	}
 	defer conn.Close()
-	_, err = s.FindByName(opts.Name)
+	p, err := s.FindByName(opts.Name)
 	if err != nil {
 		return err
 	}
 	if opts.Args["dockerfile"] != "" || opts.Input != nil {
+		err = validatePlatformBuild(opts)
+		if err != nil {
+			return err
+		}
 		err = builder.PlatformUpdate(opts)
 		if err != nil {
 			return err
 		}
+		prepullPlatformImage(opts)
 		var apps []App
 		err = conn.Apps().Find(bson.M{"framework": opts.Name}).All(&apps)
 		if err != nil {
@@ -106,14 +122,22 @@ func (s *platformService) Update(opts appTypes.PlatformOptions) error {
 			app.SetUpdatePlatform(true)
 		}
 	}
+	if opts.Args["disabled"] == "" && opts.Args["deprecated"] == "" {
+		return nil
+	}
 	if opts.Args["disabled"] != "" {
-		disableBool, err := strconv.ParseBool(opts.Args["disabled"])
+		p.Disabled, err = strconv.ParseBool(opts.Args["disabled"])
 		if err != nil {
 			return err
 		}
-		return s.storage.Update(appTypes.Platform{Name: opts.Name, Disabled: disableBool})
 	}
-	return nil
+	if opts.Args["deprecated"] != "" {
+		p.Deprecated, err = strconv.ParseBool(opts.Args["deprecated"])
+		if err != nil {
+			return err
+		}
+	}
+	return s.storage.Update(*p)
 }
 
 // Remove implements Remove method of PlatformService interface
@@ -146,3 +170,54 @@ func (s *platformService) validate(p appTypes.Platform) error {
 	}
 	return nil
 }
+
+var buildArgNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validatePlatformBuild(opts appTypes.PlatformOptions) error {
+	for name := range opts.BuildArgs {
+		if !buildArgNameRegexp.MatchString(name) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid build arg name %q", name)}
+		}
+	}
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &tsuruErrors.ValidationError{Message: "Invalid proxy, it must be an http or https URL"}
+		}
+	}
+	return nil
+}
+
+// prepullPlatformImage pulls the newly built platform image in the nodes of
+// every pool whose provisioner supports it, so the next deploys don't have to
+// wait for the image download. Failures are only reported, as the image is
+// pulled again on deploy.
+func prepullPlatformImage(opts appTypes.PlatformOptions) {
+	w := opts.Output
+	if w == nil {
+		w = ioutil.Discard
+	}
+	pools, err := pool.ListAllPools()
+	if err != nil {
+		log.Errorf("[platform prepull] unable to list pools: %s", err)
+		return
+	}
+	imgName := image.PlatformImageName(opts.Name)
+	for _, p := range pools {
+		prov, err := p.GetProvisioner()
+		if err != nil {
+			log.Errorf("[platform prepull] unable to get provisioner for pool %q: %s", p.Name, err)
+			continue
+		}
+		prepullProv, ok := prov.(provision.ImagePrepullProvisioner)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "\n---- Pre-pulling platform image in pool %q ----\n", p.Name)
+		err = prepullProv.PrepullImage(p.Name, imgName, w)
+		if err != nil {
+			log.Errorf("[platform prepull] unable to pre-pull image %q in pool %q: %s", imgName, p.Name, err)
+			fmt.Fprintf(w, " ---> Unable to pre-pull image, it will be pulled on deploy: %s\n", err)
+		}
+	}
+}
@@ -6,12 +6,14 @@ package app
 
 import (
 	"bytes"
+	"regexp"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/repository/repositorytest"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	"gopkg.in/check.v1"
@@ -88,6 +90,56 @@ func (s *PlatformSuite) TestPlatformCreateValidatesPlatformName(c *check.C) {
 	}
 }
 
+func (s *PlatformSuite) TestPlatformCreateWithBuildArgsAndProxy(c *check.C) {
+	var buildOpts appTypes.PlatformOptions
+	s.builder.OnPlatformAdd = func(opts appTypes.PlatformOptions) error {
+		buildOpts = opts
+		return nil
+	}
+	ps := &platformService{
+		storage: &appTypes.MockPlatformStorage{
+			OnInsert: func(_ appTypes.Platform) error {
+				return nil
+			},
+		},
+	}
+	opts := appTypes.PlatformOptions{
+		Name:      "java",
+		Args:      map[string]string{"dockerfile": "http://localhost/Dockerfile"},
+		BuildArgs: map[string]string{"JAVA_VERSION": "8"},
+		Proxy:     "http://proxy.internal:3128",
+	}
+	err := ps.Create(opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(buildOpts.BuildArgs, check.DeepEquals, opts.BuildArgs)
+	c.Assert(buildOpts.Proxy, check.Equals, opts.Proxy)
+}
+
+func (s *PlatformSuite) TestPlatformCreateValidatesBuildOptions(c *check.C) {
+	ps := &platformService{
+		storage: &appTypes.MockPlatformStorage{
+			OnInsert: func(_ appTypes.Platform) error {
+				c.Fatal("platform should not be inserted")
+				return nil
+			},
+		},
+	}
+	tt := []struct {
+		opts        appTypes.PlatformOptions
+		expectedErr string
+	}{
+		{appTypes.PlatformOptions{Name: "java", BuildArgs: map[string]string{"1VERSION": "8"}}, `Invalid build arg name "1VERSION"`},
+		{appTypes.PlatformOptions{Name: "java", BuildArgs: map[string]string{"JAVA-VERSION": "8"}}, `Invalid build arg name "JAVA-VERSION"`},
+		{appTypes.PlatformOptions{Name: "java", Proxy: "proxy.internal:3128"}, "Invalid proxy, it must be an http or https URL"},
+		{appTypes.PlatformOptions{Name: "java", Proxy: "ftp://proxy.internal"}, "Invalid proxy, it must be an http or https URL"},
+	}
+	for _, t := range tt {
+		err := ps.Create(t.opts)
+		c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Assert(err, check.ErrorMatches, regexp.QuoteMeta(t.expectedErr))
+	}
+}
+
 func (s *PlatformSuite) TestPlatformCreateWithStorageError(c *check.C) {
 	ps := &platformService{
 		storage: &appTypes.MockPlatformStorage{
@@ -206,6 +258,43 @@ func (s *PlatformSuite) TestPlatformUpdate(c *check.C) {
 	c.Assert(err, check.Equals, appTypes.ErrInvalidPlatform)
 }
 
+func (s *PlatformSuite) TestPlatformUpdateDeprecated(c *check.C) {
+	name := "test-platform-update"
+	var updated *appTypes.Platform
+	ps := &platformService{
+		storage: &appTypes.MockPlatformStorage{
+			OnFindByName: func(n string) (*appTypes.Platform, error) {
+				return &appTypes.Platform{Name: name, Disabled: true}, nil
+			},
+			OnUpdate: func(p appTypes.Platform) error {
+				updated = &p
+				return nil
+			},
+		},
+	}
+	args := map[string]string{"deprecated": "true"}
+	err := ps.Update(appTypes.PlatformOptions{Name: name, Args: args})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated, check.DeepEquals, &appTypes.Platform{Name: name, Disabled: true, Deprecated: true})
+}
+
+func (s *PlatformSuite) TestPlatformUpdateDeprecatedInvalid(c *check.C) {
+	ps := &platformService{
+		storage: &appTypes.MockPlatformStorage{
+			OnFindByName: func(n string) (*appTypes.Platform, error) {
+				return &appTypes.Platform{Name: n}, nil
+			},
+			OnUpdate: func(p appTypes.Platform) error {
+				c.Fatal("update should not be called")
+				return nil
+			},
+		},
+	}
+	args := map[string]string{"deprecated": "maybe"}
+	err := ps.Update(appTypes.PlatformOptions{Name: "python", Args: args})
+	c.Assert(err, check.NotNil)
+}
+
 func (s *PlatformSuite) TestPlatformUpdateDisableTrueWithDockerfile(c *check.C) {
 	name := "test-platform-update"
 	ps := &platformService{
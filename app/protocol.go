@@ -0,0 +1,112 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+)
+
+// SetProcessProtocol declares the protocol used by the backend of the given
+// process, making sure every router of the app is able to handle it. Setting
+// it to HTTP removes the declaration.
+func (app *App) SetProcessProtocol(process, protocol string) error {
+	if process == "" || strings.ContainsAny(process, ".$") {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid process name %q", process)}
+	}
+	err := router.ValidateProtocol(protocol)
+	if err != nil {
+		return err
+	}
+	processes, err := image.AllAppProcesses(app.Name)
+	if err == nil && len(processes) > 0 {
+		var found bool
+		for _, p := range processes {
+			if p == process {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("process %q not found in app %q", process, app.Name)}
+		}
+	}
+	protocols := make(map[string]string, len(app.Protocols)+1)
+	for k, v := range app.Protocols {
+		protocols[k] = v
+	}
+	var update bson.M
+	if protocol == router.ProtocolHTTP {
+		delete(protocols, process)
+		update = bson.M{"$unset": bson.M{"protocols." + process: ""}}
+	} else {
+		protocols[process] = protocol
+		update = bson.M{"$set": bson.M{"protocols." + process: protocol}}
+	}
+	for _, appRouter := range app.GetRouters() {
+		var r router.Router
+		r, err = router.Get(appRouter.Name)
+		if err != nil {
+			return err
+		}
+		err = router.CheckProtocolsSupport(r, protocols)
+		if err != nil {
+			return protocolError(err)
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	if len(protocols) == 0 {
+		protocols = nil
+	}
+	app.Protocols = protocols
+	return app.PushProtocols()
+}
+
+// PushProtocols sends the backend protocols of the processes of the app to
+// its routers able to handle them.
+func (app *App) PushProtocols() error {
+	multi := tsuruErrors.NewMultiError()
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		protoRouter, ok := r.(router.ProtocolRouter)
+		if !ok {
+			continue
+		}
+		err = protoRouter.SetBackendProtocols(app, app.Protocols)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to set backend protocols in router %q", appRouter.Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+func protocolError(err error) error {
+	if e, ok := err.(*router.ErrProtocolNotSupported); ok {
+		return &tsuruErrors.ValidationError{Message: e.Error()}
+	}
+	return err
+}
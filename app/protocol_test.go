@@ -0,0 +1,81 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetProcessProtocol(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-protocol"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetProcessProtocol("web", "grpc")
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ProtocolRouter.Protocols["myapp"], check.DeepEquals, map[string]string{"web": "grpc"})
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Protocols, check.DeepEquals, map[string]string{"web": "grpc"})
+	err = a.SetProcessProtocol("web", "http")
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ProtocolRouter.Protocols["myapp"], check.IsNil)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Protocols, check.HasLen, 0)
+}
+
+func (s *S) TestSetProcessProtocolInvalid(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-protocol"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetProcessProtocol("web", "spdy")
+	c.Assert(err, check.ErrorMatches, `Invalid protocol "spdy".*`)
+	err = a.SetProcessProtocol("we.b", "h2c")
+	c.Assert(err, check.ErrorMatches, `invalid process name "we.b"`)
+}
+
+func (s *S) TestSetProcessProtocolUnknownProcess(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-protocol"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = image.AppendAppImageName(a.Name, "tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	err = image.SaveImageCustomData("tsuru/app-myapp:v1", map[string]interface{}{
+		"processes": map[string]interface{}{"web": "python app.py"},
+	})
+	c.Assert(err, check.IsNil)
+	err = a.SetProcessProtocol("worker", "h2c")
+	c.Assert(err, check.ErrorMatches, `process "worker" not found in app "myapp"`)
+	err = a.SetProcessProtocol("web", "h2c")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSetProcessProtocolRouterNotSupported(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetProcessProtocol("web", "h2c")
+	c.Assert(err, check.ErrorMatches, `router "fake" does not support the "h2c" protocol`)
+	routertest.ProtocolRouter.Supported = []string{"http", "websocket"}
+	b := App{Name: "otherapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-protocol"}}}
+	err = CreateApp(&b, s.user)
+	c.Assert(err, check.IsNil)
+	err = b.SetProcessProtocol("web", "grpc")
+	c.Assert(err, check.ErrorMatches, `router "fake" does not support the "grpc" protocol`)
+}
+
+func (s *S) TestAddRouterValidatesProtocols(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-protocol"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetProcessProtocol("web", "websocket")
+	c.Assert(err, check.IsNil)
+	err = a.AddRouter(appTypes.AppRouter{Name: "fake-tls"})
+	c.Assert(err, check.ErrorMatches, `router "fake" does not support the "websocket" protocol`)
+	c.Assert(a.GetRouters(), check.DeepEquals, []appTypes.AppRouter{{Name: "fake-protocol"}})
+}
@@ -8,7 +8,11 @@ import (
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/quota"
 )
 
@@ -36,9 +40,109 @@ func reserveUnits(app *App, quantity int) error {
 			bson.M{"$inc": bson.M{"quota.inuse": quantity}},
 		)
 	}
+	if err == nil {
+		notifyQuotaThreshold(app, app.Quota.InUse, app.Quota.InUse+quantity)
+		notifyTeamQuotaThreshold(app, quantity)
+	}
 	return err
 }
 
+// notifyQuotaThreshold creates an event warning the teams of the app when
+// its units quota crosses one of the warning thresholds.
+func notifyQuotaThreshold(app *App, before, after int) {
+	threshold := quota.CrossedThreshold(app.Quota.Limit, before, after)
+	if threshold == 0 {
+		return
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: app.Name},
+		InternalKind: quota.EventKindThreshold,
+		CustomData: map[string]interface{}{
+			"resource":  "units",
+			"threshold": threshold,
+			"limit":     app.Quota.Limit,
+			"inUse":     after,
+		},
+		DisableLock: true,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, app.Teams),
+			permission.Context(permission.CtxApp, app.Name),
+			permission.Context(permission.CtxPool, app.Pool),
+		)...),
+	})
+	if err != nil {
+		log.Errorf("[quota] unable to create event for units quota of app %q: %v", app.Name, err)
+		return
+	}
+	evt.Done(nil)
+}
+
+// notifyTeamQuotaThreshold creates events warning the team owning the app
+// when the units, or the memory they reserve according to the plan of each
+// app, summed over the apps of the team with limited quotas cross one of the
+// warning thresholds. added is the number of units just reserved in the app.
+func notifyTeamQuotaThreshold(app *App, added int) {
+	if app.TeamOwner == "" || app.Quota.Unlimited() || added <= 0 {
+		return
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		log.Errorf("[quota] unable to get quota usage of team %q: %v", app.TeamOwner, err)
+		return
+	}
+	var apps []App
+	err = conn.Apps().Find(bson.M{
+		"teamowner":   app.TeamOwner,
+		"quota.limit": bson.M{"$gt": 0},
+	}).Select(bson.M{"name": 1, "quota": 1, "plan": 1}).All(&apps)
+	conn.Close()
+	if err != nil {
+		log.Errorf("[quota] unable to get quota usage of team %q: %v", app.TeamOwner, err)
+		return
+	}
+	const mb = 1024 * 1024
+	var units, unitsLimit, memory, memoryLimit, addedMemory int
+	for _, a := range apps {
+		unitMemory := int(a.Plan.Memory / mb)
+		units += a.Quota.InUse
+		unitsLimit += a.Quota.Limit
+		memory += a.Quota.InUse * unitMemory
+		memoryLimit += a.Quota.Limit * unitMemory
+		if a.Name == app.Name {
+			addedMemory = added * unitMemory
+		}
+	}
+	usages := []struct {
+		resource             string
+		limit, before, after int
+	}{
+		{"units", unitsLimit, units - added, units},
+		{"memory", memoryLimit, memory - addedMemory, memory},
+	}
+	for _, u := range usages {
+		threshold := quota.CrossedThreshold(u.limit, u.before, u.after)
+		if threshold == 0 {
+			continue
+		}
+		evt, err := event.NewInternal(&event.Opts{
+			Target:       event.Target{Type: event.TargetTypeTeam, Value: app.TeamOwner},
+			InternalKind: quota.EventKindThreshold,
+			CustomData: map[string]interface{}{
+				"resource":  u.resource,
+				"threshold": threshold,
+				"limit":     u.limit,
+				"inUse":     u.after,
+			},
+			DisableLock: true,
+			Allowed:     event.Allowed(permission.PermTeamReadEvents, permission.Context(permission.CtxTeam, app.TeamOwner)),
+		})
+		if err != nil {
+			log.Errorf("[quota] unable to create event for %s quota of team %q: %v", u.resource, app.TeamOwner, err)
+			continue
+		}
+		evt.Done(nil)
+	}
+}
+
 func checkAppLimit(name string, quantity int) (*App, error) {
 	app, err := GetByName(name)
 	if err != nil {
@@ -116,3 +220,29 @@ func ChangeQuota(app *App, limit int) error {
 	app.Quota.Limit = limit
 	return nil
 }
+
+// notifyUserQuotaThreshold creates an event warning the user when its apps
+// quota crosses one of the warning thresholds.
+func notifyUserQuotaThreshold(user *auth.User, before, after int) {
+	threshold := quota.CrossedThreshold(user.Quota.Limit, before, after)
+	if threshold == 0 {
+		return
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeUser, Value: user.Email},
+		InternalKind: quota.EventKindThreshold,
+		CustomData: map[string]interface{}{
+			"resource":  "apps",
+			"threshold": threshold,
+			"limit":     user.Quota.Limit,
+			"inUse":     after,
+		},
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermUserReadEvents, permission.Context(permission.CtxUser, user.Email)),
+	})
+	if err != nil {
+		log.Errorf("[quota] unable to create event for apps quota of user %q: %v", user.Email, err)
+		return
+	}
+	evt.Done(nil)
+}
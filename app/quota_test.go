@@ -10,7 +10,9 @@ import (
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/quota"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	"gopkg.in/check.v1"
 )
 
@@ -29,6 +31,64 @@ func (s *S) TestReserveUnits(c *check.C) {
 	c.Assert(app.Quota.InUse, check.Equals, 6)
 }
 
+func (s *S) TestReserveUnitsQuotaThresholdEvent(c *check.C) {
+	app := &App{
+		Name:   "together",
+		Quota:  quota.Quota{Limit: 10, InUse: 7},
+		Router: "fake",
+	}
+	s.conn.Apps().Insert(app)
+	defer s.conn.Apps().Remove(bson.M{"name": app.Name})
+	err := reserveUnits(app, 1)
+	c.Assert(err, check.IsNil)
+	err = reserveUnits(app, 1)
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(&event.Filter{Target: event.Target{Type: event.TargetTypeApp, Value: app.Name}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Kind.Name, check.Equals, quota.EventKindThreshold)
+	var data map[string]interface{}
+	err = evts[0].StartData(&data)
+	c.Assert(err, check.IsNil)
+	c.Assert(data, check.DeepEquals, map[string]interface{}{
+		"resource":  "units",
+		"threshold": 80,
+		"limit":     10,
+		"inUse":     8,
+	})
+}
+
+func (s *S) TestReserveUnitsTeamQuotaThresholdEvents(c *check.C) {
+	plan := appTypes.Plan{Name: "large", Memory: 1024 * 1024 * 1024}
+	apps := []App{
+		{Name: "together", TeamOwner: "tsuruteam", Plan: plan, Quota: quota.Quota{Limit: 5, InUse: 3}, Router: "fake"},
+		{Name: "apart", TeamOwner: "tsuruteam", Plan: plan, Quota: quota.Quota{Limit: 5, InUse: 4}, Router: "fake"},
+		{Name: "unlimited", TeamOwner: "tsuruteam", Plan: plan, Quota: quota.Quota{Limit: -1}, Router: "fake"},
+	}
+	for i := range apps {
+		err := s.conn.Apps().Insert(apps[i])
+		c.Assert(err, check.IsNil)
+		defer s.conn.Apps().Remove(bson.M{"name": apps[i].Name})
+	}
+	err := reserveUnits(&apps[0], 1)
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(&event.Filter{Target: event.Target{Type: event.TargetTypeTeam, Value: "tsuruteam"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 2)
+	resources := map[string]map[string]interface{}{}
+	for _, evt := range evts {
+		c.Assert(evt.Kind.Name, check.Equals, quota.EventKindThreshold)
+		var data map[string]interface{}
+		err = evt.StartData(&data)
+		c.Assert(err, check.IsNil)
+		resources[data["resource"].(string)] = data
+	}
+	c.Assert(resources, check.DeepEquals, map[string]map[string]interface{}{
+		"units":  {"resource": "units", "threshold": 80, "limit": 10, "inUse": 8},
+		"memory": {"resource": "memory", "threshold": 80, "limit": 10240, "inUse": 8192},
+	})
+}
+
 func (s *S) TestReserveUnitsAppNotFound(c *check.C) {
 	app := App{
 		Name:   "together",
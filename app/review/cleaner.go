@@ -0,0 +1,119 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package review
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const EventKind = "review-app-expire"
+
+// Initialize starts the routine removing expired review apps.
+func Initialize() error {
+	c := &cleaner{once: &sync.Once{}}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type cleaner struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (c *cleaner) start() {
+	c.once.Do(func() {
+		c.stopCh = make(chan struct{})
+		go c.spin()
+	})
+}
+
+func (c *cleaner) Shutdown(ctx context.Context) error {
+	if c.stopCh == nil {
+		return nil
+	}
+	c.stopCh <- struct{}{}
+	c.stopCh = nil
+	c.once = &sync.Once{}
+	return nil
+}
+
+func (c *cleaner) spin() {
+	for {
+		err := removeExpired(time.Now().UTC())
+		if err != nil {
+			log.Errorf("[review-apps] errors removing expired review apps: %v", err)
+		}
+		select {
+		case <-c.stopCh:
+			return
+		case <-time.After(cleanupInterval()):
+		}
+	}
+}
+
+func removeExpired(now time.Time) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	var expired []ReviewApp
+	err = coll.Find(bson.M{"expiresat": bson.M{"$lte": now}}).All(&expired)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range expired {
+		err = expire(&expired[i])
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to remove review app %q", expired[i].Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+func expire(r *ReviewApp) (err error) {
+	a, err := app.GetByName(r.Name)
+	if err != nil && err != app.ErrAppNotFound {
+		return err
+	}
+	if a == nil || !r.owns(a) {
+		return r.Remove(nil, "")
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		InternalKind: EventKind,
+		CustomData:   r,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, a.Teams),
+			permission.Context(permission.CtxApp, a.Name),
+			permission.Context(permission.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			log.Debugf("[review-apps] skipping app %q: event locked", a.Name)
+			return nil
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.Logf("review app %q expired at %s, removing", r.Name, r.ExpiresAt.Format(time.RFC3339))
+	return r.Remove(evt, "")
+}
@@ -0,0 +1,297 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package review manages review apps, short-lived copies of an app created
+// for an external reference, like a branch or a pull request, and removed
+// automatically once they expire.
+package review
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	Tag = "review"
+
+	defaultTTL             = 72 * time.Hour
+	defaultMaxTTL          = 14 * 24 * time.Hour
+	defaultCleanupInterval = 10 * time.Minute
+	maxAppNameLength       = 63
+)
+
+var (
+	ErrReviewAppNotFound = errors.New("review app not found")
+
+	invalidRefChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+	// internalEnvs are set by tsuru on every app, so they're not copied from
+	// the parent app.
+	internalEnvs = map[string]bool{
+		"TSURU_APPNAME":   true,
+		"TSURU_APPDIR":    true,
+		"TSURU_APP_TOKEN": true,
+	}
+)
+
+// ReviewApp links a review app to its parent app and the external reference
+// it was created for.
+type ReviewApp struct {
+	Name      string    `bson:"_id" json:"name"`
+	Parent    string    `json:"parent"`
+	Ref       string    `json:"ref"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateOpts are the options used to create a review app. A zero TTL uses
+// the configured default.
+type CreateOpts struct {
+	Ref    string
+	TTL    time.Duration
+	User   *auth.User
+	Writer io.Writer
+}
+
+func collection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_reviews")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"parent", "ref"}, Unique: true})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func durationConfig(key string, def time.Duration) time.Duration {
+	seconds, _ := config.GetInt(key)
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func defaultAppTTL() time.Duration {
+	return durationConfig("review-apps:default-ttl", defaultTTL)
+}
+
+func maxAppTTL() time.Duration {
+	return durationConfig("review-apps:max-ttl", defaultMaxTTL)
+}
+
+func cleanupInterval() time.Duration {
+	return durationConfig("review-apps:cleanup-interval", defaultCleanupInterval)
+}
+
+// AppName returns the name of the review app of the parent app for the given
+// reference.
+func AppName(parent, ref string) (string, error) {
+	suffix := strings.Trim(invalidRefChars.ReplaceAllString(strings.ToLower(ref), "-"), "-")
+	if suffix == "" {
+		return "", &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid reference %q", ref)}
+	}
+	name := parent + "-" + suffix
+	if len(name) > maxAppNameLength {
+		name = strings.TrimRight(name[:maxAppNameLength], "-")
+	}
+	return name, nil
+}
+
+// Create creates the review app of the parent app for the reference in
+// opts, copying the parent config and env vars. Creating an already existing
+// review app only extends its expiration.
+func Create(parent *app.App, opts CreateOpts) (*ReviewApp, bool, error) {
+	if opts.Ref == "" {
+		return nil, false, &tsuruErrors.ValidationError{Message: "reference is required"}
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = defaultAppTTL()
+	}
+	if ttl < 0 || ttl > maxAppTTL() {
+		return nil, false, &tsuruErrors.ValidationError{
+			Message: fmt.Sprintf("ttl must be between 1 second and %s", maxAppTTL()),
+		}
+	}
+	name, err := AppName(parent.Name, opts.Ref)
+	if err != nil {
+		return nil, false, err
+	}
+	coll, err := collection()
+	if err != nil {
+		return nil, false, err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	var existing ReviewApp
+	err = coll.Find(bson.M{"parent": parent.Name, "ref": opts.Ref}).One(&existing)
+	if err == nil {
+		existing.ExpiresAt = now.Add(ttl)
+		err = coll.UpdateId(existing.Name, bson.M{"$set": bson.M{"expiresat": existing.ExpiresAt}})
+		return &existing, false, err
+	}
+	if err != mgo.ErrNotFound {
+		return nil, false, err
+	}
+	reviewApp := ReviewApp{
+		Name:      name,
+		Parent:    parent.Name,
+		Ref:       opts.Ref,
+		Owner:     opts.User.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	err = coll.Insert(&reviewApp)
+	if err != nil {
+		return nil, false, err
+	}
+	err = createApp(parent, &reviewApp, opts)
+	if err != nil {
+		coll.RemoveId(reviewApp.Name)
+		return nil, false, err
+	}
+	return &reviewApp, true, nil
+}
+
+func createApp(parent *app.App, reviewApp *ReviewApp, opts CreateOpts) error {
+	a := app.App{
+		Name:        reviewApp.Name,
+		Platform:    parent.Platform,
+		Plan:        parent.Plan,
+		Pool:        parent.Pool,
+		TeamOwner:   parent.TeamOwner,
+		Router:      parent.Router,
+		RouterOpts:  parent.RouterOpts,
+		Ownership:   parent.Ownership,
+		Description: fmt.Sprintf("review app of %q for %q", parent.Name, reviewApp.Ref),
+		Tags:        append(append([]string{}, parent.Tags...), Tag),
+	}
+	if opts.Writer != nil {
+		fmt.Fprintf(opts.Writer, "---- Creating review app %q ----\n", a.Name)
+	}
+	err := app.CreateApp(&a, opts.User)
+	if err != nil {
+		return err
+	}
+	var envs []bind.EnvVar
+	for _, env := range parent.Env {
+		if !internalEnvs[env.Name] {
+			envs = append(envs, env)
+		}
+	}
+	return a.SetEnvs(bind.SetEnvArgs{Envs: envs, Writer: opts.Writer})
+}
+
+// Get returns the review app with the given name.
+func Get(name string) (*ReviewApp, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var reviewApp ReviewApp
+	err = coll.FindId(name).One(&reviewApp)
+	if err == mgo.ErrNotFound {
+		return nil, ErrReviewAppNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reviewApp, nil
+}
+
+// GetByRef returns the review app of the parent app for the given reference.
+func GetByRef(parent, ref string) (*ReviewApp, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var reviewApp ReviewApp
+	err = coll.Find(bson.M{"parent": parent, "ref": ref}).One(&reviewApp)
+	if err == mgo.ErrNotFound {
+		return nil, ErrReviewAppNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reviewApp, nil
+}
+
+// List returns the active review apps of the parent app, sorted by name.
+func List(parent string) ([]ReviewApp, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var reviewApps []ReviewApp
+	err = coll.Find(bson.M{"parent": parent}).Sort("_id").All(&reviewApps)
+	return reviewApps, err
+}
+
+// owns reports whether the tsuru app is the one created for the review app,
+// an app with the same name created after the review app was removed
+// outside tsuru must be kept.
+func (r *ReviewApp) owns(a *app.App) bool {
+	if a.Owner != r.Owner {
+		return false
+	}
+	for _, tag := range a.Tags {
+		if tag == Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the review app along with its tsuru app. The tsuru app is
+// only removed if it was created for the review app.
+func (r *ReviewApp) Remove(evt *event.Event, requestID string) error {
+	a, err := app.GetByName(r.Name)
+	if err != nil && err != app.ErrAppNotFound {
+		return err
+	}
+	if a != nil && !r.owns(a) {
+		log.Errorf("[review-apps] app %q wasn't created for the review app, keeping it", a.Name)
+		a = nil
+	}
+	if a != nil {
+		err = app.Delete(a, evt, requestID)
+		if err != nil {
+			return err
+		}
+	}
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.RemoveId(r.Name)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package review
+
+import (
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppName(c *check.C) {
+	tests := []struct {
+		ref      string
+		expected string
+	}{
+		{"feature/Login-Page", "myapp-feature-login-page"},
+		{"pr-42", "myapp-pr-42"},
+		{"#42", "myapp-42"},
+		{strings.Repeat("a", 70), "myapp-" + strings.Repeat("a", 57)},
+		{strings.Repeat("a", 56) + "/b", "myapp-" + strings.Repeat("a", 56)},
+	}
+	for _, tt := range tests {
+		name, err := AppName("myapp", tt.ref)
+		c.Assert(err, check.IsNil)
+		c.Assert(name, check.Equals, tt.expected)
+	}
+	_, err := AppName("myapp", "//")
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: `invalid reference "//"`})
+}
+
+func (s *S) TestCreate(c *check.C) {
+	parent := s.newApp(c, "myapp")
+	err := parent.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DATABASE_URL", Value: "mysql://db", Public: true},
+		{Name: "SECRET", Value: "abc"},
+	}})
+	c.Assert(err, check.IsNil)
+	parent, err = app.GetByName("myapp")
+	c.Assert(err, check.IsNil)
+	reviewApp, created, err := Create(parent, CreateOpts{Ref: "feature/login", TTL: time.Hour, User: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.Equals, true)
+	c.Assert(reviewApp.Name, check.Equals, "myapp-feature-login")
+	c.Assert(reviewApp.Parent, check.Equals, "myapp")
+	c.Assert(reviewApp.Ref, check.Equals, "feature/login")
+	c.Assert(reviewApp.Owner, check.Equals, s.user.Email)
+	c.Assert(reviewApp.ExpiresAt.Sub(reviewApp.CreatedAt), check.Equals, time.Hour)
+	a, err := app.GetByName("myapp-feature-login")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Platform, check.Equals, parent.Platform)
+	c.Assert(a.Pool, check.Equals, parent.Pool)
+	c.Assert(a.TeamOwner, check.Equals, parent.TeamOwner)
+	c.Assert(a.Plan, check.DeepEquals, parent.Plan)
+	c.Assert(a.Tags, check.DeepEquals, []string{"web", Tag})
+	c.Assert(a.Env["DATABASE_URL"], check.DeepEquals, bind.EnvVar{Name: "DATABASE_URL", Value: "mysql://db", Public: true})
+	c.Assert(a.Env["SECRET"], check.DeepEquals, bind.EnvVar{Name: "SECRET", Value: "abc"})
+	c.Assert(a.Env["TSURU_APPNAME"].Value, check.Equals, "myapp-feature-login")
+	dbReviewApp, err := GetByRef("myapp", "feature/login")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbReviewApp.Name, check.Equals, reviewApp.Name)
+}
+
+func (s *S) TestCreateExistingExtendsExpiration(c *check.C) {
+	parent := s.newApp(c, "myapp")
+	reviewApp, created, err := Create(parent, CreateOpts{Ref: "pr-1", TTL: time.Hour, User: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.Equals, true)
+	again, created, err := Create(parent, CreateOpts{Ref: "pr-1", TTL: 2 * time.Hour, User: s.user})
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.Equals, false)
+	c.Assert(again.Name, check.Equals, reviewApp.Name)
+	dbReviewApp, err := Get(reviewApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbReviewApp.ExpiresAt.After(reviewApp.ExpiresAt), check.Equals, true)
+}
+
+func (s *S) TestCreateInvalid(c *check.C) {
+	parent := s.newApp(c, "myapp")
+	_, _, err := Create(parent, CreateOpts{User: s.user})
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "reference is required"})
+	_, _, err = Create(parent, CreateOpts{Ref: "pr-1", TTL: 30 * 24 * time.Hour, User: s.user})
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: "ttl must be between 1 second and 336h0m0s"})
+	reviewApps, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(reviewApps, check.HasLen, 0)
+}
+
+func (s *S) TestCreateAppAlreadyExists(c *check.C) {
+	parent := s.newApp(c, "myapp")
+	s.newApp(c, "myapp-pr-1")
+	_, _, err := Create(parent, CreateOpts{Ref: "pr-1", User: s.user})
+	c.Assert(err, check.NotNil)
+	e, ok := err.(*app.AppCreationError)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.Err, check.Equals, app.ErrAppAlreadyExists)
+	_, err = GetByRef("myapp", "pr-1")
+	c.Assert(err, check.Equals, ErrReviewAppNotFound)
+}
+
+func (s *S) TestList(c *check.C) {
+	parent := s.newApp(c, "myapp")
+	other := s.newApp(c, "other")
+	for _, ref := range []string{"pr-2", "pr-1"} {
+		_, _, err := Create(parent, CreateOpts{Ref: ref, User: s.user})
+		c.Assert(err, check.IsNil)
+	}
+	_, _, err := Create(other, CreateOpts{Ref: "pr-1", User: s.user})
+	c.Assert(err, check.IsNil)
+	reviewApps, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(reviewApps, check.HasLen, 2)
+	c.Assert(reviewApps[0].Name, check.Equals, "myapp-pr-1")
+	c.Assert(reviewApps[1].Name, check.Equals, "myapp-pr-2")
+}
+
+func (s *S) TestRemoveExpired(c *check.C) {
+	parent := s.newApp(c, "myapp")
+	expired, _, err := Create(parent, CreateOpts{Ref: "pr-1", TTL: time.Minute, User: s.user})
+	c.Assert(err, check.IsNil)
+	active, _, err := Create(parent, CreateOpts{Ref: "pr-2", TTL: time.Hour, User: s.user})
+	c.Assert(err, check.IsNil)
+	err = removeExpired(time.Now().UTC().Add(10 * time.Minute))
+	c.Assert(err, check.IsNil)
+	_, err = app.GetByName(expired.Name)
+	c.Assert(err, check.Equals, app.ErrAppNotFound)
+	_, err = Get(expired.Name)
+	c.Assert(err, check.Equals, ErrReviewAppNotFound)
+	_, err = app.GetByName(active.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeApp, Value: expired.Name},
+		Kind:   EventKind,
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestRemoveExpiredKeepsUnrelatedApp(c *check.C) {
+	parent := s.newApp(c, "myapp")
+	expired, _, err := Create(parent, CreateOpts{Ref: "pr-1", TTL: time.Minute, User: s.user})
+	c.Assert(err, check.IsNil)
+	err = s.storage.Apps().Remove(bson.M{"name": expired.Name})
+	c.Assert(err, check.IsNil)
+	s.newApp(c, expired.Name)
+	err = removeExpired(time.Now().UTC().Add(10 * time.Minute))
+	c.Assert(err, check.IsNil)
+	_, err = Get(expired.Name)
+	c.Assert(err, check.Equals, ErrReviewAppNotFound)
+	_, err = app.GetByName(expired.Name)
+	c.Assert(err, check.IsNil)
+}
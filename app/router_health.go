@@ -0,0 +1,67 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/url"
+
+	"github.com/tsuru/tsuru/router"
+)
+
+// RouteHealth is the health of a route of the app reported by a router,
+// along with the ID of the unit behind the route, when known.
+type RouteHealth struct {
+	router.RouteStatus
+	Unit string `json:"unit,omitempty"`
+}
+
+// RouterHealth holds the health of the routes of the app in a router. Error
+// is filled when the router failed to report it.
+type RouterHealth struct {
+	Router string        `json:"router"`
+	Routes []RouteHealth `json:"routes"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// RoutersHealth returns the health of the routes of the app as seen by each
+// of its routers able to report it. A router failing to report its status
+// does not prevent the others from being listed.
+func (app *App) RoutersHealth() ([]RouterHealth, error) {
+	units, err := app.Units()
+	if err != nil {
+		return nil, err
+	}
+	unitsByHost := make(map[string]string, len(units))
+	for _, u := range units {
+		if u.Address != nil {
+			unitsByHost[u.Address.Host] = u.ID
+		}
+	}
+	result := []RouterHealth{}
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			return nil, err
+		}
+		reporter, ok := r.(router.StatusReporter)
+		if !ok || !router.Supports(r, router.CapabilityStatusReporter) {
+			continue
+		}
+		health := RouterHealth{Router: appRouter.Name, Routes: []RouteHealth{}}
+		routes, err := reporter.RoutesStatus(app.Name)
+		if err != nil {
+			health.Error = err.Error()
+		}
+		for _, route := range routes {
+			routeHealth := RouteHealth{RouteStatus: route}
+			if u, parseErr := url.Parse(route.Address); parseErr == nil {
+				routeHealth.Unit = unitsByHost[u.Host]
+			}
+			health.Routes = append(health.Routes, routeHealth)
+		}
+		result = append(result, health)
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestRoutersHealth(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake"}, {Name: "fake-status"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(2, "web", nil)
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+	err = routertest.StatusRouter.AddRoutes(a.Name, []*url.URL{units[0].Address, units[1].Address})
+	c.Assert(err, check.IsNil)
+	failedAt := time.Date(2018, 5, 10, 12, 0, 0, 0, time.UTC)
+	routertest.StatusRouter.RouteStatus[units[1].Address.String()] = router.RouteStatus{
+		Status:        router.RouteHealthDown,
+		LastFailure:   "connection refused",
+		LastFailureAt: &failedAt,
+		ResponseTime:  10 * time.Millisecond,
+	}
+	health, err := a.RoutersHealth()
+	c.Assert(err, check.IsNil)
+	c.Assert(health, check.DeepEquals, []RouterHealth{
+		{Router: "fake-status", Routes: []RouteHealth{
+			{
+				RouteStatus: router.RouteStatus{Address: units[0].Address.String(), Status: router.RouteHealthUp},
+				Unit:        units[0].ID,
+			},
+			{
+				RouteStatus: router.RouteStatus{
+					Address:       units[1].Address.String(),
+					Status:        router.RouteHealthDown,
+					LastFailure:   "connection refused",
+					LastFailureAt: &failedAt,
+					ResponseTime:  10 * time.Millisecond,
+				},
+				Unit: units[1].ID,
+			},
+		}},
+	})
+}
+
+func (s *S) TestRoutersHealthRouterError(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-status"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = routertest.StatusRouter.RemoveBackend(a.Name)
+	c.Assert(err, check.IsNil)
+	health, err := a.RoutersHealth()
+	c.Assert(err, check.IsNil)
+	c.Assert(health, check.DeepEquals, []RouterHealth{
+		{Router: "fake-status", Routes: []RouteHealth{}, Error: router.ErrBackendNotFound.Error()},
+	})
+}
+
+func (s *S) TestRoutersHealthNoReporter(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	health, err := a.RoutersHealth()
+	c.Assert(err, check.IsNil)
+	c.Assert(health, check.HasLen, 0)
+}
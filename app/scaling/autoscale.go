@@ -0,0 +1,216 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scaling
+
+import (
+	"math"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/traffic"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const (
+	EventKindAutoScale = "app-scaling-autoscale"
+
+	// scaleDownCooldown is the minimum time between a scale operation and a
+	// following scale down, avoiding flapping on traffic oscillations.
+	scaleDownCooldown = 5 * time.Minute
+)
+
+var (
+	ErrAutoScaleNotFound = errors.New("autoscale config not found")
+	ErrInvalidAutoScale  = errors.New("invalid autoscale config: min units must be at least 1 and not greater than max units, and target requests per unit must be greater than 0")
+)
+
+// AutoScale configures the metric based autoscaler for an app process. The
+// number of units is set so each unit receives at most TargetRequests
+// requests per second, within the MinUnits and MaxUnits bounds.
+type AutoScale struct {
+	App            string    `json:"app"`
+	Process        string    `json:"process"`
+	MinUnits       uint      `json:"minUnits"`
+	MaxUnits       uint      `json:"maxUnits"`
+	TargetRequests float64   `json:"targetRequests"`
+	LastScale      time.Time `json:"lastScale"`
+}
+
+func autoScaleCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_scaling_autoscale")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "process"}, Unique: true})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// SetAutoScale creates or replaces the autoscale config of an app process.
+func SetAutoScale(as *AutoScale) error {
+	if as.Process == "" {
+		return ErrProcessRequired
+	}
+	if as.MinUnits == 0 || as.MaxUnits < as.MinUnits || as.TargetRequests <= 0 {
+		return ErrInvalidAutoScale
+	}
+	coll, err := autoScaleCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.Upsert(bson.M{"app": as.App, "process": as.Process}, as)
+	return err
+}
+
+// ListAutoScale returns the autoscale configs of an app.
+func ListAutoScale(appName string) ([]AutoScale, error) {
+	return listAutoScale(bson.M{"app": appName})
+}
+
+func listAutoScale(query bson.M) ([]AutoScale, error) {
+	coll, err := autoScaleCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var configs []AutoScale
+	err = coll.Find(query).Sort("app", "process").All(&configs)
+	if err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// RemoveAutoScale disables the autoscaler for an app process.
+func RemoveAutoScale(appName, process string) error {
+	coll, err := autoScaleCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.Remove(bson.M{"app": appName, "process": process})
+	if err == mgo.ErrNotFound {
+		return ErrAutoScaleNotFound
+	}
+	return err
+}
+
+func (as *AutoScale) setLastScale(t time.Time) error {
+	coll, err := autoScaleCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	as.LastScale = t
+	return coll.Update(bson.M{"app": as.App, "process": as.Process}, bson.M{"$set": bson.M{"lastscale": t}})
+}
+
+// desiredUnits calculates the number of units needed to handle the given
+// requests rate. Scheduled scaling takes precedence over traffic based
+// decisions, the units set by the last executed schedule work as a floor.
+func (as *AutoScale) desiredUnits(requestsPerSecond float64) (uint, error) {
+	desired := uint(math.Ceil(requestsPerSecond / as.TargetRequests))
+	if desired < as.MinUnits {
+		desired = as.MinUnits
+	}
+	if desired > as.MaxUnits {
+		desired = as.MaxUnits
+	}
+	scheduled, found, err := ScheduledUnits(as.App, as.Process)
+	if err != nil {
+		return 0, err
+	}
+	if found && scheduled > desired {
+		desired = scheduled
+	}
+	return desired, nil
+}
+
+func runAutoScale(now time.Time) error {
+	configs, err := listAutoScale(bson.M{})
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range configs {
+		err = autoScaleProcess(&configs[i], now)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to autoscale process %q of app %q", configs[i].Process, configs[i].App))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+func autoScaleProcess(as *AutoScale, now time.Time) (err error) {
+	a, err := app.GetByName(as.App)
+	if err != nil {
+		if err == app.ErrAppNotFound {
+			log.Debugf("[autoscale] app %q not found, removing its autoscale config", as.App)
+			return RemoveAutoScale(as.App, as.Process)
+		}
+		return err
+	}
+	summary, err := traffic.GetSummary(a.Name, traffic.DefaultWindow)
+	if err != nil {
+		return err
+	}
+	if len(summary.Routers) == 0 {
+		log.Debugf("[autoscale] no traffic metrics for app %q, ignoring", a.Name)
+		return nil
+	}
+	desired, err := as.desiredUnits(summary.RequestsPerSecond)
+	if err != nil {
+		return err
+	}
+	current, err := processUnits(a, as.Process)
+	if err != nil {
+		return err
+	}
+	if desired == current {
+		return nil
+	}
+	if desired < current && now.Sub(as.LastScale) < scaleDownCooldown {
+		return nil
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		InternalKind: EventKindAutoScale,
+		CustomData:   map[string]interface{}{"config": as, "traffic": summary},
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, a.Teams),
+			permission.Context(permission.CtxApp, a.Name),
+			permission.Context(permission.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			log.Debugf("[autoscale] skipping app %q: event locked", a.Name)
+			return nil
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.Logf("app %q receiving %.2f requests per second", a.Name, summary.RequestsPerSecond)
+	err = SetUnits(a, as.Process, desired, evt)
+	if err != nil {
+		return err
+	}
+	return as.setLastScale(now)
+}
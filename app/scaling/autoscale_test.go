@@ -0,0 +1,143 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scaling
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/traffic"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) addTraffic(c *check.C, appName string, requestsPerSecond uint64) {
+	now := time.Now().UTC()
+	err := traffic.AddSample(traffic.Sample{App: appName, Router: "fake", Time: now.Add(-time.Minute)})
+	c.Assert(err, check.IsNil)
+	err = traffic.AddSample(traffic.Sample{App: appName, Router: "fake", Time: now, Requests: requestsPerSecond * 60})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSetAutoScale(c *check.C) {
+	err := SetAutoScale(&AutoScale{App: "myapp", Process: "web", MinUnits: 1, MaxUnits: 5, TargetRequests: 10})
+	c.Assert(err, check.IsNil)
+	err = SetAutoScale(&AutoScale{App: "myapp", Process: "web", MinUnits: 2, MaxUnits: 8, TargetRequests: 20})
+	c.Assert(err, check.IsNil)
+	configs, err := ListAutoScale("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(configs, check.DeepEquals, []AutoScale{
+		{App: "myapp", Process: "web", MinUnits: 2, MaxUnits: 8, TargetRequests: 20},
+	})
+	err = RemoveAutoScale("myapp", "web")
+	c.Assert(err, check.IsNil)
+	err = RemoveAutoScale("myapp", "web")
+	c.Assert(err, check.Equals, ErrAutoScaleNotFound)
+}
+
+func (s *S) TestSetAutoScaleInvalid(c *check.C) {
+	tests := []AutoScale{
+		{App: "myapp", MinUnits: 1, MaxUnits: 5, TargetRequests: 10},
+		{App: "myapp", Process: "web", MinUnits: 0, MaxUnits: 5, TargetRequests: 10},
+		{App: "myapp", Process: "web", MinUnits: 6, MaxUnits: 5, TargetRequests: 10},
+		{App: "myapp", Process: "web", MinUnits: 1, MaxUnits: 5, TargetRequests: 0},
+	}
+	for _, tt := range tests {
+		err := SetAutoScale(&tt)
+		c.Check(err, check.NotNil, check.Commentf("config: %#v", tt))
+	}
+}
+
+func (s *S) TestRunAutoScaleScalesUp(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(1, "web", nil)
+	c.Assert(err, check.IsNil)
+	err = SetAutoScale(&AutoScale{App: a.Name, Process: "web", MinUnits: 1, MaxUnits: 5, TargetRequests: 10})
+	c.Assert(err, check.IsNil)
+	s.addTraffic(c, a.Name, 35)
+	err = runAutoScale(time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 4)
+}
+
+func (s *S) TestRunAutoScaleRespectsMaxUnits(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(1, "web", nil)
+	c.Assert(err, check.IsNil)
+	err = SetAutoScale(&AutoScale{App: a.Name, Process: "web", MinUnits: 1, MaxUnits: 3, TargetRequests: 10})
+	c.Assert(err, check.IsNil)
+	s.addTraffic(c, a.Name, 100)
+	err = runAutoScale(time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 3)
+}
+
+func (s *S) TestRunAutoScaleScaleDownCooldown(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(4, "web", nil)
+	c.Assert(err, check.IsNil)
+	as := AutoScale{App: a.Name, Process: "web", MinUnits: 1, MaxUnits: 5, TargetRequests: 10}
+	err = SetAutoScale(&as)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	err = as.setLastScale(now.Add(-time.Minute))
+	c.Assert(err, check.IsNil)
+	s.addTraffic(c, a.Name, 5)
+	err = runAutoScale(now)
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 4)
+	err = runAutoScale(now.Add(scaleDownCooldown))
+	c.Assert(err, check.IsNil)
+	units, err = a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 1)
+}
+
+func (s *S) TestRunAutoScaleScheduledFloor(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(6, "web", nil)
+	c.Assert(err, check.IsNil)
+	sched := Schedule{App: a.Name, Process: "web", Cron: "0 8 * * *", Units: 6}
+	err = AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	_, err = sched.claim(time.Now().UTC().Add(-time.Hour))
+	c.Assert(err, check.IsNil)
+	err = SetAutoScale(&AutoScale{App: a.Name, Process: "web", MinUnits: 1, MaxUnits: 10, TargetRequests: 10})
+	c.Assert(err, check.IsNil)
+	s.addTraffic(c, a.Name, 5)
+	err = runAutoScale(time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 6)
+}
+
+func (s *S) TestRunAutoScaleNoMetrics(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(3, "web", nil)
+	c.Assert(err, check.IsNil)
+	err = SetAutoScale(&AutoScale{App: a.Name, Process: "web", MinUnits: 1, MaxUnits: 10, TargetRequests: 10})
+	c.Assert(err, check.IsNil)
+	err = runAutoScale(time.Now().UTC())
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 3)
+}
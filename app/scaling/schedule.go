@@ -0,0 +1,185 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scaling implements automatic changes in the number of units of
+// app processes, either based on time schedules or on collected metrics.
+//
+// When both are configured for the same process, the number of units set by
+// the last executed schedule works as a lower bound for the metric based
+// autoscaler.
+package scaling
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/cron"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("scaling schedule not found")
+	ErrProcessRequired  = errors.New("process is required")
+)
+
+// Schedule represents a time based change in the number of units of an app
+// process. Every time the cron expression fires the process is scaled to the
+// given number of units.
+type Schedule struct {
+	ID        bson.ObjectId `bson:"_id" json:"id"`
+	App       string        `json:"app"`
+	Process   string        `json:"process"`
+	Cron      string        `json:"cron"`
+	Units     uint          `json:"units"`
+	CreatedAt time.Time     `json:"createdAt"`
+	LastRun   time.Time     `json:"lastRun"`
+}
+
+func schedulesCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_scaling_schedules")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "process"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func (s *Schedule) validate() error {
+	if s.Process == "" {
+		return ErrProcessRequired
+	}
+	_, err := cron.Parse(s.Cron)
+	return err
+}
+
+// AddSchedule validates and stores a new scaling schedule.
+func AddSchedule(s *Schedule) error {
+	err := s.validate()
+	if err != nil {
+		return err
+	}
+	coll, err := schedulesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	s.ID = bson.NewObjectId()
+	s.CreatedAt = time.Now().UTC()
+	s.LastRun = time.Time{}
+	return coll.Insert(s)
+}
+
+// ListSchedules returns the scaling schedules registered for an app.
+func ListSchedules(appName string) ([]Schedule, error) {
+	return listSchedules(bson.M{"app": appName})
+}
+
+func listSchedules(query bson.M) ([]Schedule, error) {
+	coll, err := schedulesCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var schedules []Schedule
+	err = coll.Find(query).Sort("_id").All(&schedules)
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// RemoveSchedule removes a scaling schedule from an app.
+func RemoveSchedule(appName, id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrScheduleNotFound
+	}
+	coll, err := schedulesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.Remove(bson.M{"_id": bson.ObjectIdHex(id), "app": appName})
+	if err == mgo.ErrNotFound {
+		return ErrScheduleNotFound
+	}
+	return err
+}
+
+// RemoveAppSchedules removes all scaling schedules from an app.
+func RemoveAppSchedules(appName string) error {
+	coll, err := schedulesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.RemoveAll(bson.M{"app": appName})
+	return err
+}
+
+// ScheduledUnits returns the number of units set by the schedule that ran
+// last for the app process. The metric based autoscaler uses this value as
+// a floor, so a scale down decision never undoes a scheduled scale up.
+func ScheduledUnits(appName, process string) (uint, bool, error) {
+	coll, err := schedulesCollection()
+	if err != nil {
+		return 0, false, err
+	}
+	defer coll.Close()
+	var s Schedule
+	err = coll.Find(bson.M{
+		"app":     appName,
+		"process": process,
+		"lastrun": bson.M{"$gt": time.Time{}},
+	}).Sort("-lastrun").One(&s)
+	if err == mgo.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return s.Units, true, nil
+}
+
+// claim marks the schedule as run at the given time. It returns false if the
+// schedule was already claimed, possibly by another tsuru API instance.
+func (s *Schedule) claim(runAt time.Time) (bool, error) {
+	coll, err := schedulesCollection()
+	if err != nil {
+		return false, err
+	}
+	defer coll.Close()
+	err = coll.Update(bson.M{"_id": s.ID, "lastrun": s.LastRun}, bson.M{"$set": bson.M{"lastrun": runAt}})
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s.LastRun = runAt
+	return true, nil
+}
+
+// unclaim restores the last run time of a schedule that could not be
+// applied, so it is retried in the next scheduler run.
+func (s *Schedule) unclaim(lastRun time.Time) error {
+	coll, err := schedulesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.Update(bson.M{"_id": s.ID, "lastrun": s.LastRun}, bson.M{"$set": bson.M{"lastrun": lastRun}})
+	if err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	s.LastRun = lastRun
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scaling
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddSchedule(c *check.C) {
+	sched := Schedule{App: "myapp", Process: "web", Cron: "0 8 * * *", Units: 10}
+	err := AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	c.Assert(sched.ID.Valid(), check.Equals, true)
+	schedules, err := ListSchedules("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules, check.HasLen, 1)
+	c.Assert(schedules[0].ID, check.Equals, sched.ID)
+	c.Assert(schedules[0].Process, check.Equals, "web")
+	c.Assert(schedules[0].Cron, check.Equals, "0 8 * * *")
+	c.Assert(schedules[0].Units, check.Equals, uint(10))
+	c.Assert(schedules[0].LastRun.IsZero(), check.Equals, true)
+}
+
+func (s *S) TestAddScheduleInvalid(c *check.C) {
+	err := AddSchedule(&Schedule{App: "myapp", Cron: "0 8 * * *", Units: 10})
+	c.Assert(err, check.Equals, ErrProcessRequired)
+	err = AddSchedule(&Schedule{App: "myapp", Process: "web", Cron: "0 25 * * *", Units: 10})
+	c.Assert(err, check.ErrorMatches, `invalid cron expression "0 25 \* \* \*".*`)
+	schedules, err := ListSchedules("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules, check.HasLen, 0)
+}
+
+func (s *S) TestRemoveSchedule(c *check.C) {
+	sched := Schedule{App: "myapp", Process: "web", Cron: "0 8 * * *", Units: 10}
+	err := AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	err = RemoveSchedule("otherapp", sched.ID.Hex())
+	c.Assert(err, check.Equals, ErrScheduleNotFound)
+	err = RemoveSchedule("myapp", "invalid")
+	c.Assert(err, check.Equals, ErrScheduleNotFound)
+	err = RemoveSchedule("myapp", sched.ID.Hex())
+	c.Assert(err, check.IsNil)
+	schedules, err := ListSchedules("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules, check.HasLen, 0)
+}
+
+func (s *S) TestScheduledUnits(c *check.C) {
+	_, found, err := ScheduledUnits("myapp", "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, false)
+	morning := Schedule{App: "myapp", Process: "web", Cron: "0 8 * * *", Units: 10}
+	err = AddSchedule(&morning)
+	c.Assert(err, check.IsNil)
+	night := Schedule{App: "myapp", Process: "web", Cron: "0 22 * * *", Units: 2}
+	err = AddSchedule(&night)
+	c.Assert(err, check.IsNil)
+	_, found, err = ScheduledUnits("myapp", "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, false)
+	claimed, err := morning.claim(time.Date(2018, 3, 14, 8, 0, 0, 0, time.UTC))
+	c.Assert(err, check.IsNil)
+	c.Assert(claimed, check.Equals, true)
+	claimed, err = night.claim(time.Date(2018, 3, 13, 22, 0, 0, 0, time.UTC))
+	c.Assert(err, check.IsNil)
+	c.Assert(claimed, check.Equals, true)
+	units, found, err := ScheduledUnits("myapp", "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, true)
+	c.Assert(units, check.Equals, uint(10))
+}
+
+func (s *S) TestScheduleClaimOnlyOnce(c *check.C) {
+	sched := Schedule{App: "myapp", Process: "web", Cron: "0 8 * * *", Units: 10}
+	err := AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	other := sched
+	runAt := time.Date(2018, 3, 14, 8, 0, 0, 0, time.UTC)
+	claimed, err := sched.claim(runAt)
+	c.Assert(err, check.IsNil)
+	c.Assert(claimed, check.Equals, true)
+	claimed, err = other.claim(runAt)
+	c.Assert(err, check.IsNil)
+	c.Assert(claimed, check.Equals, false)
+	schedules, err := listSchedules(bson.M{"_id": sched.ID})
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules[0].LastRun.Equal(runAt), check.Equals, true)
+}
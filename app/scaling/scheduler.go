@@ -0,0 +1,199 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scaling
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/cron"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const (
+	EventKindSchedule = "app-scaling-schedule"
+
+	schedulerRunInterval = time.Minute
+	// maxCatchUp limits how far in the past the scheduler looks for missed
+	// executions, e.g. when the API was down.
+	maxCatchUp = 24 * time.Hour
+)
+
+func Initialize() error {
+	s := &scheduler{once: &sync.Once{}}
+	s.start()
+	shutdown.Register(s)
+	return nil
+}
+
+type scheduler struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (s *scheduler) start() {
+	s.once.Do(func() {
+		s.stopCh = make(chan struct{})
+		go s.spin()
+	})
+}
+
+func (s *scheduler) Shutdown(ctx context.Context) error {
+	if s.stopCh == nil {
+		return nil
+	}
+	s.stopCh <- struct{}{}
+	s.stopCh = nil
+	s.once = &sync.Once{}
+	return nil
+}
+
+func (s *scheduler) spin() {
+	for {
+		now := time.Now().UTC()
+		err := runSchedules(now)
+		if err != nil {
+			log.Errorf("[scaling scheduler] errors running schedules: %v", err)
+		}
+		err = runAutoScale(now)
+		if err != nil {
+			log.Errorf("[autoscale] errors running autoscale: %v", err)
+		}
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(schedulerRunInterval):
+		}
+	}
+}
+
+type dueSchedule struct {
+	schedule *Schedule
+	runAt    time.Time
+}
+
+// runSchedules applies every schedule that fired since its last execution.
+// When more than one schedule fired for the same app process only the most
+// recent one is applied.
+func runSchedules(now time.Time) error {
+	schedules, err := listSchedules(bson.M{})
+	if err != nil {
+		return err
+	}
+	due := map[[2]string]dueSchedule{}
+	for i := range schedules {
+		s := &schedules[i]
+		parsed, err := cron.Parse(s.Cron)
+		if err != nil {
+			log.Errorf("[scaling scheduler] ignoring invalid schedule %s for app %q: %v", s.ID.Hex(), s.App, err)
+			continue
+		}
+		since := s.LastRun
+		if since.IsZero() {
+			since = s.CreatedAt
+		}
+		if limit := now.Add(-maxCatchUp); since.Before(limit) {
+			since = limit
+		}
+		runAt := parsed.Prev(now, since)
+		if runAt.IsZero() {
+			continue
+		}
+		key := [2]string{s.App, s.Process}
+		if current, ok := due[key]; !ok || runAt.After(current.runAt) {
+			due[key] = dueSchedule{schedule: s, runAt: runAt}
+		}
+	}
+	multi := tsuruErrors.NewMultiError()
+	for _, d := range due {
+		lastRun := d.schedule.LastRun
+		claimed, err := d.schedule.claim(d.runAt)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		err = applySchedule(d.schedule)
+		if _, ok := err.(event.ErrEventLocked); ok {
+			log.Debugf("[scaling scheduler] app %q locked, schedule %s will be retried", d.schedule.App, d.schedule.ID.Hex())
+			err = d.schedule.unclaim(lastRun)
+		}
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to apply schedule %s for app %q", d.schedule.ID.Hex(), d.schedule.App))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+func applySchedule(s *Schedule) (err error) {
+	a, err := app.GetByName(s.App)
+	if err != nil {
+		if err == app.ErrAppNotFound {
+			log.Debugf("[scaling scheduler] app %q not found, removing its schedules", s.App)
+			return RemoveAppSchedules(s.App)
+		}
+		return err
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		InternalKind: EventKindSchedule,
+		CustomData:   s,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, a.Teams),
+			permission.Context(permission.CtxApp, a.Name),
+			permission.Context(permission.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return SetUnits(a, s.Process, s.Units, evt)
+}
+
+// SetUnits adds or removes units from the app process until it has exactly
+// the given number of units.
+func SetUnits(a *app.App, process string, units uint, evt *event.Event) error {
+	current, err := processUnits(a, process)
+	if err != nil {
+		return err
+	}
+	switch {
+	case current < units:
+		evt.Logf("scaling process %q of app %q from %d to %d units", process, a.Name, current, units)
+		return a.AddUnits(units-current, process, evt)
+	case current > units:
+		evt.Logf("scaling process %q of app %q from %d to %d units", process, a.Name, current, units)
+		return a.RemoveUnits(current-units, process, evt)
+	}
+	evt.Logf("process %q of app %q already has %d units", process, a.Name, units)
+	return nil
+}
+
+func processUnits(a *app.App, process string) (uint, error) {
+	units, err := a.Units()
+	if err != nil {
+		return 0, err
+	}
+	var count uint
+	for _, u := range units {
+		if u.ProcessName == process {
+			count++
+		}
+	}
+	return count, nil
+}
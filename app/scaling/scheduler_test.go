@@ -0,0 +1,113 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scaling
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setScheduleCreation(c *check.C, sched *Schedule, createdAt time.Time) {
+	coll, err := schedulesCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	err = coll.UpdateId(sched.ID, bson.M{"$set": bson.M{"createdat": createdAt}})
+	c.Assert(err, check.IsNil)
+	sched.CreatedAt = createdAt
+}
+
+func (s *S) TestSchedulerStartNothingToDo(c *check.C) {
+	sched := &scheduler{once: &sync.Once{}}
+	sched.start()
+	err := sched.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestRunSchedulesScalesUp(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(2, "web", nil)
+	c.Assert(err, check.IsNil)
+	sched := Schedule{App: a.Name, Process: "web", Cron: "0 8 * * *", Units: 5}
+	err = AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	now := time.Date(2018, 3, 14, 8, 0, 30, 0, time.UTC)
+	s.setScheduleCreation(c, &sched, now.Add(-time.Hour))
+	err = runSchedules(now)
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 5)
+	c.Assert(eventtest.EventDesc{
+		Target:     event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		Kind:       EventKindSchedule,
+		LogMatches: `scaling process "web" of app "myapp" from 2 to 5 units`,
+	}, eventtest.HasEvent)
+	err = runSchedules(now.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(provisiontest.ProvisionerInstance.GetUnits(&a), check.HasLen, 5)
+}
+
+func (s *S) TestRunSchedulesScalesDown(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(5, "web", nil)
+	c.Assert(err, check.IsNil)
+	sched := Schedule{App: a.Name, Process: "web", Cron: "0 22 * * *", Units: 2}
+	err = AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	now := time.Date(2018, 3, 14, 22, 1, 0, 0, time.UTC)
+	s.setScheduleCreation(c, &sched, now.Add(-time.Hour))
+	err = runSchedules(now)
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+}
+
+func (s *S) TestRunSchedulesOnlyMostRecent(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	morning := Schedule{App: a.Name, Process: "web", Cron: "0 8 * * *", Units: 10}
+	err = AddSchedule(&morning)
+	c.Assert(err, check.IsNil)
+	night := Schedule{App: a.Name, Process: "web", Cron: "0 22 * * *", Units: 2}
+	err = AddSchedule(&night)
+	c.Assert(err, check.IsNil)
+	now := time.Date(2018, 3, 14, 23, 0, 0, 0, time.UTC)
+	s.setScheduleCreation(c, &morning, now.Add(-20*time.Hour))
+	s.setScheduleCreation(c, &night, now.Add(-20*time.Hour))
+	err = runSchedules(now)
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+	scheduled, found, err := ScheduledUnits(a.Name, "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, true)
+	c.Assert(scheduled, check.Equals, uint(2))
+}
+
+func (s *S) TestRunSchedulesAppNotFound(c *check.C) {
+	sched := Schedule{App: "unknown", Process: "web", Cron: "* * * * *", Units: 2}
+	err := AddSchedule(&sched)
+	c.Assert(err, check.IsNil)
+	err = runSchedules(time.Now().UTC().Add(2 * time.Minute))
+	c.Assert(err, check.IsNil)
+	schedules, err := ListSchedules("unknown")
+	c.Assert(err, check.IsNil)
+	c.Assert(schedules, check.HasLen, 0)
+}
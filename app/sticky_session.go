@@ -0,0 +1,93 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+)
+
+// EffectiveStickySession describes the session affinity applied to an app,
+// along with the routers of the app enforcing it.
+type EffectiveStickySession struct {
+	router.StickySession
+	Routers []string `json:"routers"`
+}
+
+// EffectiveStickySession returns the sticky session settings of the app with
+// defaults applied, or nil if the app has no session affinity.
+func (app *App) EffectiveStickySession() *EffectiveStickySession {
+	if app.StickySession == nil {
+		return nil
+	}
+	effective := EffectiveStickySession{
+		StickySession: app.StickySession.WithDefaults(),
+		Routers:       []string{},
+	}
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			continue
+		}
+		if _, ok := r.(router.StickySessionRouter); ok {
+			effective.Routers = append(effective.Routers, appRouter.Name)
+		}
+	}
+	return &effective
+}
+
+// SetStickySession sets the session affinity settings of the app and pushes
+// them to the routers of the app. A nil session disables the affinity.
+func (app *App) SetStickySession(session *router.StickySession) error {
+	var update bson.M
+	if session == nil {
+		update = bson.M{"$unset": bson.M{"stickysession": ""}}
+	} else {
+		err := session.Validate()
+		if err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"stickysession": session}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.StickySession = session
+	return app.PushStickySession()
+}
+
+// PushStickySession sends the sticky session settings of the app to its
+// routers supporting session affinity, other routers are ignored.
+func (app *App) PushStickySession() error {
+	multi := tsuruErrors.NewMultiError()
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		stickyRouter, ok := r.(router.StickySessionRouter)
+		if !ok {
+			continue
+		}
+		err = stickyRouter.SetStickySession(app, app.StickySession)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to set sticky session in router %q", appRouter.Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
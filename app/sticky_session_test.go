@@ -0,0 +1,83 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetStickySession(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-sticky"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	session := &router.StickySession{CookieName: "mycookie", TTL: 3600}
+	err = a.SetStickySession(session)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.StickyRouter.Sessions["myapp"], check.DeepEquals, *session)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.StickySession, check.DeepEquals, session)
+	err = a.SetStickySession(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.StickyRouter.Sessions, check.HasLen, 0)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.StickySession, check.IsNil)
+}
+
+func (s *S) TestSetStickySessionInvalid(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-sticky"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetStickySession(&router.StickySession{TTL: -10})
+	c.Assert(err, check.ErrorMatches, "Invalid TTL, it must not be negative")
+	c.Assert(routertest.StickyRouter.Sessions, check.HasLen, 0)
+}
+
+func (s *S) TestSetStickySessionIgnoresUnsupportedRouters(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetStickySession(&router.StickySession{TTL: 60})
+	c.Assert(err, check.IsNil)
+	c.Assert(a.EffectiveStickySession(), check.DeepEquals, &EffectiveStickySession{
+		StickySession: router.StickySession{CookieName: router.DefaultStickyCookieName, TTL: 60},
+		Routers:       []string{},
+	})
+}
+
+func (s *S) TestAddRouterPushesStickySession(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetStickySession(&router.StickySession{CookieName: "mycookie"})
+	c.Assert(err, check.IsNil)
+	err = a.AddRouter(appTypes.AppRouter{Name: "fake-sticky"})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.StickyRouter.Sessions["myapp"], check.DeepEquals, router.StickySession{CookieName: "mycookie"})
+}
+
+func (s *S) TestAppMarshalJSONStickySession(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake"}, {Name: "fake-sticky"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetStickySession(&router.StickySession{TTL: 120})
+	c.Assert(err, check.IsNil)
+	data, err := a.MarshalJSON()
+	c.Assert(err, check.IsNil)
+	var result map[string]interface{}
+	err = json.Unmarshal(data, &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result["stickySession"], check.DeepEquals, map[string]interface{}{
+		"cookieName": router.DefaultStickyCookieName,
+		"ttl":        float64(120),
+		"routers":    []interface{}{"fake-sticky"},
+	})
+}
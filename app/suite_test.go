@@ -102,6 +102,13 @@ func (s *S) SetUpSuite(c *check.C) {
 	config.Set("queue:mongo-polling-interval", 0.01)
 	config.Set("docker:registry", "registry.somewhere")
 	config.Set("routers:fake-tls:type", "fake-tls")
+	config.Set("routers:fake-protocol:type", "fake-protocol")
+	config.Set("routers:fake-sticky:type", "fake-sticky")
+	config.Set("routers:fake-mirror:type", "fake-mirror")
+	config.Set("routers:fake-accesslog:type", "fake-accesslog")
+	config.Set("routers:fake-errorpage:type", "fake-errorpage")
+	config.Set("routers:fake-status:type", "fake-status")
+	config.Set("routers:fake-metrics:type", "fake-metrics")
 	config.Set("auth:hash-cost", bcrypt.MinCost)
 	s.conn, err = db.Conn()
 	c.Assert(err, check.IsNil)
@@ -130,11 +137,25 @@ func (s *S) SetUpTest(c *check.C) {
 	routertest.FakeRouter.Reset()
 	routertest.HCRouter.Reset()
 	routertest.TLSRouter.Reset()
+	routertest.ProtocolRouter.Reset()
+	routertest.StickyRouter.Reset()
+	routertest.MirrorRouter.Reset()
+	routertest.AccessLogRouter.Reset()
+	routertest.ErrorPageRouter.Reset()
+	routertest.StatusRouter.Reset()
+	routertest.MetricsRouter.Reset()
 	routertest.OptsRouter.Reset()
 	queue.ResetQueue()
 	routertest.FakeRouter.Reset()
 	routertest.HCRouter.Reset()
 	routertest.TLSRouter.Reset()
+	routertest.ProtocolRouter.Reset()
+	routertest.StickyRouter.Reset()
+	routertest.MirrorRouter.Reset()
+	routertest.AccessLogRouter.Reset()
+	routertest.ErrorPageRouter.Reset()
+	routertest.StatusRouter.Reset()
+	routertest.MetricsRouter.Reset()
 	routertest.OptsRouter.Reset()
 	pool.ResetCache()
 	err := rebuild.RegisterTask(func(appName string) (rebuild.RebuildApp, error) {
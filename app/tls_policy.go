@@ -0,0 +1,162 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/router"
+)
+
+const (
+	TLSPolicySourceApp  = "app"
+	TLSPolicySourcePool = "pool"
+)
+
+// TLSComplianceEntry describes an app whose effective TLS policy has weak
+// settings.
+type TLSComplianceEntry struct {
+	App        string            `json:"app"`
+	Pool       string            `json:"pool"`
+	TeamOwner  string            `json:"teamOwner"`
+	Source     string            `json:"source,omitempty"`
+	Policy     *router.TLSPolicy `json:"policy,omitempty"`
+	Weaknesses []string          `json:"weaknesses"`
+}
+
+// EffectiveTLSPolicy returns the TLS policy enforced for the app, either its
+// own or the one of its pool, along with where it comes from.
+func (app *App) EffectiveTLSPolicy() (*router.TLSPolicy, string, error) {
+	if app.TLSPolicy != nil {
+		return app.TLSPolicy, TLSPolicySourceApp, nil
+	}
+	p, err := pool.GetPoolByName(app.Pool)
+	if err != nil {
+		if err == pool.ErrPoolNotFound {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	if p.TLSPolicy != nil {
+		return p.TLSPolicy, TLSPolicySourcePool, nil
+	}
+	return nil, "", nil
+}
+
+// SetTLSPolicy sets the TLS policy of the app, overriding the one of its
+// pool, and pushes it to the routers of the app. A nil policy removes it.
+func (app *App) SetTLSPolicy(policy *router.TLSPolicy) error {
+	var update bson.M
+	if policy == nil {
+		update = bson.M{"$unset": bson.M{"tlspolicy": ""}}
+	} else {
+		err := policy.Validate()
+		if err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"tlspolicy": policy}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.TLSPolicy = policy
+	return app.PushTLSPolicy()
+}
+
+// PushTLSPolicy sends the effective TLS policy of the app to its routers
+// able to enforce it, other routers are ignored.
+func (app *App) PushTLSPolicy() error {
+	policy, _, err := app.EffectiveTLSPolicy()
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(appRouter.Name)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		policyRouter, ok := r.(router.TLSPolicyRouter)
+		if !ok {
+			continue
+		}
+		err = policyRouter.SetTLSPolicy(app, policy)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to set TLS policy in router %q", appRouter.Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+// SetPoolTLSPolicy sets the TLS policy of the pool, pushing it to the routers
+// of the apps in the pool without a policy of their own.
+func SetPoolTLSPolicy(poolName string, policy *router.TLSPolicy) error {
+	err := pool.SetTLSPolicy(poolName, policy)
+	if err != nil {
+		return err
+	}
+	apps, err := List(&Filter{Pool: poolName})
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range apps {
+		if apps[i].TLSPolicy != nil {
+			continue
+		}
+		err = apps[i].PushTLSPolicy()
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to push TLS policy to app %q", apps[i].Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+// TLSComplianceReport lists the apps matching the filter whose effective TLS
+// policy has weak settings.
+func TLSComplianceReport(filter *Filter) ([]TLSComplianceEntry, error) {
+	apps, err := List(filter)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := pool.ListAllPools()
+	if err != nil {
+		return nil, err
+	}
+	poolPolicies := make(map[string]*router.TLSPolicy, len(pools))
+	for _, p := range pools {
+		poolPolicies[p.Name] = p.TLSPolicy
+	}
+	var entries []TLSComplianceEntry
+	for _, a := range apps {
+		entry := TLSComplianceEntry{App: a.Name, Pool: a.Pool, TeamOwner: a.TeamOwner}
+		if a.TLSPolicy != nil {
+			entry.Policy, entry.Source = a.TLSPolicy, TLSPolicySourceApp
+		} else if policy := poolPolicies[a.Pool]; policy != nil {
+			entry.Policy, entry.Source = policy, TLSPolicySourcePool
+		}
+		entry.Weaknesses = entry.Policy.Weaknesses()
+		if len(entry.Weaknesses) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSetTLSPolicy(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	policy := &router.TLSPolicy{MinVersion: "1.2", HSTS: &router.HSTSPolicy{MaxAge: router.RecommendedHSTSMaxAge}}
+	err = a.SetTLSPolicy(policy)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Policies["myapp"], check.DeepEquals, policy)
+	dbApp, err := GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TLSPolicy, check.DeepEquals, policy)
+	err = a.SetTLSPolicy(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Policies["myapp"], check.IsNil)
+	dbApp, err = GetByName(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.TLSPolicy, check.IsNil)
+}
+
+func (s *S) TestSetTLSPolicyInvalid(c *check.C) {
+	a := App{Name: "myapp", TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetTLSPolicy(&router.TLSPolicy{MinVersion: "2.0"})
+	c.Assert(err, check.ErrorMatches, `Invalid minimum TLS version "2.0".*`)
+}
+
+func (s *S) TestSetPoolTLSPolicy(c *check.C) {
+	a1 := App{Name: "app1", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err := CreateApp(&a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := App{Name: "app2", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err = CreateApp(&a2, s.user)
+	c.Assert(err, check.IsNil)
+	appPolicy := &router.TLSPolicy{MinVersion: "1.3"}
+	err = a2.SetTLSPolicy(appPolicy)
+	c.Assert(err, check.IsNil)
+	poolPolicy := &router.TLSPolicy{MinVersion: "1.2"}
+	err = SetPoolTLSPolicy(s.Pool, poolPolicy)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Policies["app1"], check.DeepEquals, poolPolicy)
+	c.Assert(routertest.TLSRouter.Policies["app2"], check.DeepEquals, appPolicy)
+	policy, source, err := a1.EffectiveTLSPolicy()
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, check.DeepEquals, poolPolicy)
+	c.Assert(source, check.Equals, TLSPolicySourcePool)
+}
+
+func (s *S) TestCreateAppPushesPoolTLSPolicy(c *check.C) {
+	poolPolicy := &router.TLSPolicy{MinVersion: "1.2"}
+	err := SetPoolTLSPolicy(s.Pool, poolPolicy)
+	c.Assert(err, check.IsNil)
+	a := App{Name: "myapp", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err = CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Policies["myapp"], check.DeepEquals, poolPolicy)
+}
+
+func (s *S) TestTLSComplianceReport(c *check.C) {
+	strong := &router.TLSPolicy{MinVersion: "1.2", HSTS: &router.HSTSPolicy{MaxAge: router.RecommendedHSTSMaxAge}}
+	err := SetPoolTLSPolicy(s.Pool, strong)
+	c.Assert(err, check.IsNil)
+	compliant := App{Name: "compliant", TeamOwner: s.team.Name}
+	err = CreateApp(&compliant, s.user)
+	c.Assert(err, check.IsNil)
+	weak := App{Name: "weak", TeamOwner: s.team.Name}
+	err = CreateApp(&weak, s.user)
+	c.Assert(err, check.IsNil)
+	err = weak.SetTLSPolicy(&router.TLSPolicy{MinVersion: "1.0"})
+	c.Assert(err, check.IsNil)
+	entries, err := TLSComplianceReport(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.DeepEquals, []TLSComplianceEntry{
+		{
+			App:        "weak",
+			Pool:       s.Pool,
+			TeamOwner:  s.team.Name,
+			Source:     TLSPolicySourceApp,
+			Policy:     &router.TLSPolicy{MinVersion: "1.0"},
+			Weaknesses: []string{"minimum TLS version below 1.2", "HSTS disabled"},
+		},
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package traffic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/router"
+)
+
+const defaultCollectInterval = time.Minute
+
+func collectInterval() time.Duration {
+	seconds, _ := config.GetInt("router-metrics:collect-interval")
+	if seconds <= 0 {
+		return defaultCollectInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Initialize starts collecting metrics from routers supporting it.
+func Initialize() error {
+	c := &collector{once: &sync.Once{}}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type collector struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (c *collector) start() {
+	c.once.Do(func() {
+		c.stopCh = make(chan struct{})
+		go c.spin()
+	})
+}
+
+func (c *collector) Shutdown(ctx context.Context) error {
+	if c.stopCh == nil {
+		return nil
+	}
+	c.stopCh <- struct{}{}
+	c.stopCh = nil
+	c.once = &sync.Once{}
+	return nil
+}
+
+func (c *collector) spin() {
+	for {
+		err := collect(time.Now().UTC())
+		if err != nil {
+			log.Errorf("[router metrics] errors collecting metrics: %v", err)
+		}
+		select {
+		case <-c.stopCh:
+			return
+		case <-time.After(collectInterval()):
+		}
+	}
+}
+
+func collect(now time.Time) error {
+	apps, err := app.List(nil)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for _, a := range apps {
+		for _, appRouter := range a.GetRouters() {
+			r, err := router.Get(appRouter.Name)
+			if err != nil {
+				multi.Add(err)
+				continue
+			}
+			metricsRouter, ok := r.(router.MetricsRouter)
+			if !ok {
+				continue
+			}
+			metrics, err := metricsRouter.BackendMetrics(a.Name)
+			if err != nil {
+				multi.Add(errors.Wrapf(err, "unable to get metrics for app %q from router %q", a.Name, appRouter.Name))
+				continue
+			}
+			err = AddSample(Sample{
+				App:          a.Name,
+				Router:       appRouter.Name,
+				Time:         now,
+				Requests:     metrics.Requests,
+				Errors:       metrics.Errors,
+				TotalLatency: metrics.TotalLatency,
+			})
+			if err != nil {
+				multi.Add(err)
+			}
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
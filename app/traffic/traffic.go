@@ -0,0 +1,151 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package traffic collects request metrics reported by routers and
+// summarizes the traffic received by apps.
+package traffic
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+const (
+	defaultRetention = 24 * time.Hour
+	DefaultWindow    = 5 * time.Minute
+)
+
+// Sample is a snapshot of the cumulative request counters of an app backend
+// in a router.
+type Sample struct {
+	App          string
+	Router       string
+	Time         time.Time
+	Requests     uint64
+	Errors       uint64
+	TotalLatency time.Duration
+}
+
+// sampleDoc is a sample as stored in the database. Its expiration is set
+// when it's stored, so the TTL index never changes along with the retention.
+type sampleDoc struct {
+	Sample    `bson:",inline"`
+	ExpiresAt time.Time
+}
+
+// Summary describes the traffic received by an app in a time window.
+type Summary struct {
+	App               string        `json:"app"`
+	Window            time.Duration `json:"window"`
+	Requests          uint64        `json:"requests"`
+	Errors            uint64        `json:"errors"`
+	RequestsPerSecond float64       `json:"requestsPerSecond"`
+	ErrorsPerSecond   float64       `json:"errorsPerSecond"`
+	AvgLatency        time.Duration `json:"avgLatency"`
+	Routers           []string      `json:"routers"`
+}
+
+func retention() time.Duration {
+	seconds, _ := config.GetInt("router-metrics:retention")
+	if seconds <= 0 {
+		return defaultRetention
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func samplesCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_traffic_samples")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "router", "time"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"expiresat"}, ExpireAfter: time.Second})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// AddSample stores a new metrics sample.
+func AddSample(s Sample) error {
+	coll, err := samplesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	return coll.Insert(sampleDoc{Sample: s, ExpiresAt: s.Time.Add(retention())})
+}
+
+// GetSummary summarizes the traffic received by the app in the given window
+// ending now, combining the samples collected from all its routers.
+func GetSummary(appName string, window time.Duration) (*Summary, error) {
+	coll, err := samplesCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	since := now.Add(-window)
+	// Samples stored before the retention was reduced are only removed once
+	// they expire, so they're ignored here.
+	if cutoff := now.Add(-retention()); since.Before(cutoff) {
+		since = cutoff
+	}
+	var samples []Sample
+	err = coll.Find(bson.M{"app": appName, "time": bson.M{"$gte": since}}).Sort("time").All(&samples)
+	if err != nil {
+		return nil, err
+	}
+	summary := Summary{App: appName, Window: window, Routers: []string{}}
+	byRouter := map[string][]Sample{}
+	for _, s := range samples {
+		if _, ok := byRouter[s.Router]; !ok {
+			summary.Routers = append(summary.Routers, s.Router)
+		}
+		byRouter[s.Router] = append(byRouter[s.Router], s)
+	}
+	var totalLatency time.Duration
+	var elapsed time.Duration
+	for _, routerSamples := range byRouter {
+		for i := 1; i < len(routerSamples); i++ {
+			prev, cur := routerSamples[i-1], routerSamples[i]
+			if cur.Requests < prev.Requests {
+				// Counters were reset, the router was probably restarted.
+				prev = Sample{Time: prev.Time}
+			}
+			summary.Requests += cur.Requests - prev.Requests
+			if cur.Errors >= prev.Errors {
+				summary.Errors += cur.Errors - prev.Errors
+			}
+			if cur.TotalLatency >= prev.TotalLatency {
+				totalLatency += cur.TotalLatency - prev.TotalLatency
+			}
+		}
+		if len(routerSamples) > 1 {
+			routerElapsed := routerSamples[len(routerSamples)-1].Time.Sub(routerSamples[0].Time)
+			if routerElapsed > elapsed {
+				elapsed = routerElapsed
+			}
+		}
+	}
+	if elapsed > 0 {
+		summary.RequestsPerSecond = float64(summary.Requests) / elapsed.Seconds()
+		summary.ErrorsPerSecond = float64(summary.Errors) / elapsed.Seconds()
+	}
+	if summary.Requests > 0 {
+		summary.AvgLatency = totalLatency / time.Duration(summary.Requests)
+	}
+	return &summary, nil
+}
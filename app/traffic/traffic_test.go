@@ -0,0 +1,93 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package traffic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestGetSummary(c *check.C) {
+	now := time.Now().UTC()
+	samples := []Sample{
+		{App: "myapp", Router: "r1", Time: now.Add(-10 * time.Minute), Requests: 10},
+		{App: "myapp", Router: "r1", Time: now.Add(-2 * time.Minute), Requests: 100, Errors: 1, TotalLatency: time.Second},
+		{App: "myapp", Router: "r1", Time: now.Add(-time.Minute), Requests: 160, Errors: 2, TotalLatency: 2 * time.Second},
+		{App: "myapp", Router: "r2", Time: now.Add(-110 * time.Second), Requests: 500},
+		{App: "myapp", Router: "r2", Time: now.Add(-time.Minute), Requests: 20},
+		{App: "otherapp", Router: "r1", Time: now.Add(-time.Minute), Requests: 1000},
+	}
+	for _, sample := range samples {
+		err := AddSample(sample)
+		c.Assert(err, check.IsNil)
+	}
+	summary, err := GetSummary("myapp", 5*time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(summary.Routers, check.DeepEquals, []string{"r1", "r2"})
+	c.Assert(summary.Requests, check.Equals, uint64(80))
+	c.Assert(summary.Errors, check.Equals, uint64(1))
+	c.Assert(summary.RequestsPerSecond, check.Equals, float64(80)/60)
+	c.Assert(summary.AvgLatency, check.Equals, time.Second/80)
+}
+
+func (s *S) TestGetSummaryRetentionChanged(c *check.C) {
+	now := time.Now().UTC()
+	err := AddSample(Sample{App: "myapp", Router: "r1", Time: now.Add(-3 * time.Minute), Requests: 10})
+	c.Assert(err, check.IsNil)
+	config.Set("router-metrics:retention", 150)
+	defer config.Unset("router-metrics:retention")
+	err = AddSample(Sample{App: "myapp", Router: "r1", Time: now.Add(-2 * time.Minute), Requests: 100})
+	c.Assert(err, check.IsNil)
+	err = AddSample(Sample{App: "myapp", Router: "r1", Time: now.Add(-time.Minute), Requests: 160})
+	c.Assert(err, check.IsNil)
+	summary, err := GetSummary("myapp", 5*time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(summary.Requests, check.Equals, uint64(60))
+}
+
+func (s *S) TestGetSummaryNoSamples(c *check.C) {
+	summary, err := GetSummary("myapp", 5*time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(summary.Routers, check.HasLen, 0)
+	c.Assert(summary.RequestsPerSecond, check.Equals, float64(0))
+}
+
+func (s *S) TestCollectorStartNothingToDo(c *check.C) {
+	col := &collector{once: &sync.Once{}}
+	col.start()
+	err := col.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCollect(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team, Router: "fake-metrics"}
+	err := app.CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	other := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team, Router: "fake"}
+	err = app.CreateApp(&other, s.user)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	routertest.MetricsRouter.Metrics[a.Name] = router.BackendMetrics{Requests: 10}
+	err = collect(now.Add(-time.Minute))
+	c.Assert(err, check.IsNil)
+	routertest.MetricsRouter.Metrics[a.Name] = router.BackendMetrics{Requests: 130, TotalLatency: time.Second}
+	err = collect(now)
+	c.Assert(err, check.IsNil)
+	summary, err := GetSummary(a.Name, 5*time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(summary.Routers, check.DeepEquals, []string{"fake-metrics"})
+	c.Assert(summary.Requests, check.Equals, uint64(120))
+	c.Assert(summary.RequestsPerSecond, check.Equals, float64(2))
+	summary, err = GetSummary(other.Name, 5*time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(summary.Routers, check.HasLen, 0)
+}
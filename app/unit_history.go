@@ -0,0 +1,182 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+)
+
+// UnitChangeReasonManual is the reason of unit count changes requested by
+// users or app tokens. Changes made by tsuru itself, like the ones from the
+// autoscaler or the healer, use the kind of their event as reason.
+const UnitChangeReasonManual = "manual"
+
+// UnitCountChange is a change in the number of units of an app process,
+// along with who made it and why.
+type UnitCountChange struct {
+	App     string    `json:"app"`
+	Process string    `json:"process"`
+	Time    time.Time `json:"time"`
+	From    int       `json:"from"`
+	To      int       `json:"to"`
+	Actor   string    `json:"actor"`
+	Reason  string    `json:"reason"`
+	EventID string    `json:"eventID,omitempty"`
+}
+
+// UnitHistoryFilter selects the unit count changes of an app returned by
+// UnitCountHistory. Zero values don't filter.
+type UnitHistoryFilter struct {
+	App     string
+	Process string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+func (f *UnitHistoryFilter) query() bson.M {
+	query := bson.M{"app": f.App}
+	if f.Process != "" {
+		query["process"] = f.Process
+	}
+	timeQuery := bson.M{}
+	if !f.Since.IsZero() {
+		timeQuery["$gte"] = f.Since
+	}
+	if !f.Until.IsZero() {
+		timeQuery["$lte"] = f.Until
+	}
+	if len(timeQuery) > 0 {
+		query["time"] = timeQuery
+	}
+	return query
+}
+
+func unitHistoryCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_unit_history")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "process", "time"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// UnitCountHistory returns the unit count changes matching the filter, sorted
+// by time. When a limit is set, the latest changes are returned.
+func UnitCountHistory(f UnitHistoryFilter) ([]UnitCountChange, error) {
+	coll, err := unitHistoryCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	query := coll.Find(f.query()).Sort("-time")
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+	var changes []UnitCountChange
+	err = query.All(&changes)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+	return changes, nil
+}
+
+// UnitCounts returns the number of units of each process of the app.
+func (app *App) UnitCounts() (map[string]int, error) {
+	units, err := app.Units()
+	if err != nil {
+		return nil, err
+	}
+	return unitCounts(units), nil
+}
+
+func unitCounts(units []provision.Unit) map[string]int {
+	counts := map[string]int{}
+	for _, u := range units {
+		counts[u.ProcessName]++
+	}
+	return counts
+}
+
+// RecordUnitCountChanges compares the given unit counts, taken before an
+// operation, with the current ones and records a change for each process
+// whose number of units changed. The actor and reason of the changes are
+// taken from the event of the operation.
+func (app *App) RecordUnitCountChanges(before map[string]int, evt *event.Event) error {
+	after, err := app.UnitCounts()
+	if err != nil {
+		return err
+	}
+	processes := map[string]struct{}{}
+	for p := range before {
+		processes[p] = struct{}{}
+	}
+	for p := range after {
+		processes[p] = struct{}{}
+	}
+	now := time.Now().UTC()
+	var changes []interface{}
+	for p := range processes {
+		if before[p] == after[p] {
+			continue
+		}
+		change := UnitCountChange{
+			App:     app.Name,
+			Process: p,
+			Time:    now,
+			From:    before[p],
+			To:      after[p],
+			Actor:   evt.Owner.Name,
+			Reason:  UnitChangeReasonManual,
+			EventID: evt.UniqueID.Hex(),
+		}
+		if evt.Owner.Type == event.OwnerTypeInternal {
+			change.Actor = string(event.OwnerTypeInternal)
+			change.Reason = evt.Kind.Name
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	coll, err := unitHistoryCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	return coll.Insert(changes...)
+}
+
+// recordUnitCountChanges records the unit count changes made by an operation
+// running as part of the event w, if any. Failures are only logged, as the
+// history must not fail the operation itself.
+func (app *App) recordUnitCountChanges(before map[string]int, w io.Writer) {
+	evt, ok := w.(*event.Event)
+	if !ok || evt == nil {
+		return
+	}
+	err := app.RecordUnitCountChanges(before, evt)
+	if err != nil {
+		log.Errorf("[unit history] unable to record unit count changes of app %q: %s", app.Name, err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddAndRemoveUnitsRecordHistory(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", Quota: quota.Unlimited, TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppUpdateUnitAdd,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(3, "web", evt)
+	c.Assert(err, check.IsNil)
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+	internalEvt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: "app", Value: a.Name},
+		InternalKind: "app-scaling-autoscale",
+		Allowed:      event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = a.RemoveUnits(2, "web", internalEvt)
+	c.Assert(err, check.IsNil)
+	err = internalEvt.Done(nil)
+	c.Assert(err, check.IsNil)
+	changes, err := UnitCountHistory(UnitHistoryFilter{App: a.Name})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 2)
+	for i := range changes {
+		c.Assert(changes[i].Time.IsZero(), check.Equals, false)
+		changes[i].Time = time.Time{}
+	}
+	c.Assert(changes, check.DeepEquals, []UnitCountChange{
+		{App: a.Name, Process: "web", From: 0, To: 3, Actor: s.user.Email, Reason: UnitChangeReasonManual, EventID: evt.UniqueID.Hex()},
+		{App: a.Name, Process: "web", From: 3, To: 1, Actor: "internal", Reason: "app-scaling-autoscale", EventID: internalEvt.UniqueID.Hex()},
+	})
+}
+
+func (s *S) TestAddUnitsWithoutEventDoesntRecordHistory(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", Quota: quota.Unlimited, TeamOwner: s.team.Name}
+	err := CreateApp(&a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(2, "web", nil)
+	c.Assert(err, check.IsNil)
+	changes, err := UnitCountHistory(UnitHistoryFilter{App: a.Name})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 0)
+}
+
+func (s *S) TestUnitCountHistoryFilter(c *check.C) {
+	coll, err := unitHistoryCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	base := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, p := range []string{"web", "worker", "web", "web"} {
+		err = coll.Insert(UnitCountChange{App: "myapp", Process: p, Time: base.Add(time.Duration(i) * time.Hour), From: i, To: i + 1})
+		c.Assert(err, check.IsNil)
+	}
+	err = coll.Insert(UnitCountChange{App: "other", Process: "web", Time: base, To: 1})
+	c.Assert(err, check.IsNil)
+	changes, err := UnitCountHistory(UnitHistoryFilter{App: "myapp", Process: "web"})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 3)
+	c.Assert(changes[0].From, check.Equals, 0)
+	c.Assert(changes[2].From, check.Equals, 3)
+	changes, err = UnitCountHistory(UnitHistoryFilter{App: "myapp", Since: base.Add(time.Hour), Until: base.Add(2 * time.Hour)})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 2)
+	c.Assert(changes[0].Process, check.Equals, "worker")
+	c.Assert(changes[1].Process, check.Equals, "web")
+	changes, err = UnitCountHistory(UnitHistoryFilter{App: "myapp", Limit: 2})
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 2)
+	c.Assert(changes[0].From, check.Equals, 2)
+	c.Assert(changes[1].From, check.Equals, 3)
+}
@@ -0,0 +1,355 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package upload implements resumable chunked uploads of deploy artifacts.
+//
+// An upload session is created with the total size and the sha256 checksum
+// of the artifact. Chunks are then sent in order, each one with its own
+// checksum, and may be retried until accepted. Once all chunks are received
+// the session can be used as the file of an upload deploy. Sessions and
+// their chunks are removed after expiring.
+package upload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+const (
+	defaultSessionExpiry = time.Hour
+	defaultMaxChunkSize  = 8 * 1024 * 1024
+)
+
+var (
+	ErrSessionNotFound  = errors.New("upload session not found")
+	ErrInvalidSize      = errors.New("upload size must be greater than 0")
+	ErrInvalidChecksum  = errors.New("checksum must be a hex encoded sha256 sum")
+	ErrChecksumMismatch = errors.New("checksum doesn't match the uploaded data")
+	ErrChunkTooLarge    = errors.New("chunk exceeds the maximum chunk size")
+	ErrChunkOverflow    = errors.New("chunk exceeds the upload size")
+	ErrIncomplete       = errors.New("upload is not complete")
+)
+
+// ErrOffsetMismatch is returned when a chunk doesn't start where the data
+// already received ends. Offset is the position the next chunk must start.
+type ErrOffsetMismatch struct {
+	Offset int64
+}
+
+func (e ErrOffsetMismatch) Error() string {
+	return fmt.Sprintf("invalid chunk offset, next chunk must start at %d", e.Offset)
+}
+
+// Session is an upload of a deploy artifact in progress.
+type Session struct {
+	ID        bson.ObjectId `bson:"_id" json:"id"`
+	App       string        `json:"app"`
+	User      string        `json:"user"`
+	Size      int64         `json:"size"`
+	Checksum  string        `json:"checksum"`
+	Received  int64         `json:"received"`
+	Chunks    int           `json:"chunks"`
+	CreatedAt time.Time     `json:"createdAt"`
+	ExpiresAt time.Time     `json:"expiresAt"`
+	HashState []byte        `json:"-"`
+}
+
+type chunk struct {
+	Session   bson.ObjectId
+	Index     int
+	Data      []byte
+	ExpiresAt time.Time
+}
+
+func sessionExpiry() time.Duration {
+	seconds, _ := config.GetInt("upload:session-expiry")
+	if seconds <= 0 {
+		return defaultSessionExpiry
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// MaxChunkSize returns the maximum size, in bytes, accepted for a chunk.
+func MaxChunkSize() int64 {
+	size, _ := config.GetInt("upload:max-chunk-size")
+	if size <= 0 {
+		return defaultMaxChunkSize
+	}
+	return int64(size)
+}
+
+func sessionsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("deploy_upload_sessions")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"expiresat"}, ExpireAfter: time.Second})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func chunksCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("deploy_upload_chunks")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"session", "index"}, Unique: true})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"expiresat"}, ExpireAfter: time.Second})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func validChecksum(checksum string) bool {
+	data, err := hex.DecodeString(checksum)
+	return err == nil && len(data) == sha256.Size
+}
+
+// NewSession starts a new upload session for an artifact of the given size
+// and sha256 checksum.
+func NewSession(appName, user string, size int64, checksum string) (*Session, error) {
+	if size <= 0 {
+		return nil, ErrInvalidSize
+	}
+	if !validChecksum(checksum) {
+		return nil, ErrInvalidChecksum
+	}
+	state, err := sha256.New().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	s := Session{
+		ID:        bson.NewObjectId(),
+		App:       appName,
+		User:      user,
+		Size:      size,
+		Checksum:  checksum,
+		CreatedAt: now,
+		ExpiresAt: now.Add(sessionExpiry()),
+		HashState: state,
+	}
+	coll, err := sessionsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	err = coll.Insert(s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSession returns an upload session that has not expired.
+func GetSession(id string) (*Session, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, ErrSessionNotFound
+	}
+	coll, err := sessionsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var s Session
+	err = coll.FindId(bson.ObjectIdHex(id)).One(&s)
+	if err == mgo.ErrNotFound || (err == nil && time.Now().After(s.ExpiresAt)) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Complete indicates whether all the data of the upload was received.
+func (s *Session) Complete() bool {
+	return s.Received == s.Size
+}
+
+func (s *Session) hash() (hash.Hash, error) {
+	h := sha256.New()
+	err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(s.HashState)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid upload hash state")
+	}
+	return h, nil
+}
+
+// AddChunk stores a chunk of data starting at the given offset. The offset
+// must match the amount of data already received and the checksum must be
+// the sha256 sum of the chunk. Adding a chunk extends the session expiry.
+func (s *Session) AddChunk(offset int64, data []byte, checksum string) error {
+	if offset != s.Received {
+		return ErrOffsetMismatch{Offset: s.Received}
+	}
+	if int64(len(data)) > MaxChunkSize() {
+		return ErrChunkTooLarge
+	}
+	if offset+int64(len(data)) > s.Size {
+		return ErrChunkOverflow
+	}
+	if !validChecksum(checksum) {
+		return ErrInvalidChecksum
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return ErrChecksumMismatch
+	}
+	h, err := s.hash()
+	if err != nil {
+		return err
+	}
+	h.Write(data)
+	received := offset + int64(len(data))
+	if received == s.Size && hex.EncodeToString(h.Sum(nil)) != s.Checksum {
+		return ErrChecksumMismatch
+	}
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().UTC().Add(sessionExpiry())
+	sessions, err := sessionsCollection()
+	if err != nil {
+		return err
+	}
+	defer sessions.Close()
+	// The session is updated before storing the chunk, so only the request
+	// that moved the offset forward writes the chunk data, matching the
+	// hash state kept in the session.
+	err = sessions.Update(bson.M{"_id": s.ID, "received": offset}, bson.M{
+		"$set": bson.M{"received": received, "hashstate": state, "expiresat": expiresAt},
+		"$inc": bson.M{"chunks": 1},
+	})
+	if err == mgo.ErrNotFound {
+		// Another request added a chunk concurrently.
+		current, getErr := GetSession(s.ID.Hex())
+		if getErr != nil {
+			return getErr
+		}
+		return ErrOffsetMismatch{Offset: current.Received}
+	}
+	if err != nil {
+		return err
+	}
+	chunks, err := chunksCollection()
+	if err == nil {
+		defer chunks.Close()
+		_, err = chunks.Upsert(bson.M{"session": s.ID, "index": s.Chunks}, chunk{
+			Session:   s.ID,
+			Index:     s.Chunks,
+			Data:      data,
+			ExpiresAt: expiresAt,
+		})
+	}
+	if err != nil {
+		// Give the offset back, so the chunk can be sent again.
+		rollbackErr := sessions.Update(bson.M{"_id": s.ID, "received": received}, bson.M{
+			"$set": bson.M{"received": offset, "hashstate": s.HashState},
+			"$inc": bson.M{"chunks": -1},
+		})
+		if rollbackErr != nil {
+			return errors.Wrapf(err, "unable to restore upload offset: %v", rollbackErr)
+		}
+		return err
+	}
+	_, err = chunks.UpdateAll(bson.M{"session": s.ID}, bson.M{"$set": bson.M{"expiresat": expiresAt}})
+	if err != nil {
+		return err
+	}
+	s.Received = received
+	s.HashState = state
+	s.ExpiresAt = expiresAt
+	s.Chunks++
+	return nil
+}
+
+// Open returns a reader with the uploaded data, the upload must be complete.
+func (s *Session) Open() (io.ReadCloser, error) {
+	if !s.Complete() {
+		return nil, ErrIncomplete
+	}
+	return &sessionReader{session: s}, nil
+}
+
+// Remove discards the session and its data.
+func (s *Session) Remove() error {
+	chunks, err := chunksCollection()
+	if err != nil {
+		return err
+	}
+	defer chunks.Close()
+	_, err = chunks.RemoveAll(bson.M{"session": s.ID})
+	if err != nil {
+		return err
+	}
+	sessions, err := sessionsCollection()
+	if err != nil {
+		return err
+	}
+	defer sessions.Close()
+	err = sessions.RemoveId(s.ID)
+	if err == mgo.ErrNotFound {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+// sessionReader reads the chunks of a session one at a time, avoiding
+// loading the whole artifact in memory.
+type sessionReader struct {
+	session *Session
+	index   int
+	buf     bytes.Reader
+}
+
+func (r *sessionReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.index >= r.session.Chunks {
+			return 0, io.EOF
+		}
+		coll, err := chunksCollection()
+		if err != nil {
+			return 0, err
+		}
+		var c chunk
+		err = coll.Find(bson.M{"session": r.session.ID, "index": r.index}).One(&c)
+		coll.Close()
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable to read chunk %d of upload %s", r.index, r.session.ID.Hex())
+		}
+		r.buf.Reset(c.Data)
+		r.index++
+	}
+	return r.buf.Read(p)
+}
+
+func (r *sessionReader) Close() error {
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *S) TestNewSession(c *check.C) {
+	data := []byte("my artifact")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	c.Assert(session.ExpiresAt.Sub(session.CreatedAt), check.Equals, time.Hour)
+	dbSession, err := GetSession(session.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbSession.App, check.Equals, "myapp")
+	c.Assert(dbSession.User, check.Equals, "me@example.com")
+	c.Assert(dbSession.Size, check.Equals, int64(len(data)))
+	c.Assert(dbSession.Received, check.Equals, int64(0))
+	c.Assert(dbSession.Complete(), check.Equals, false)
+}
+
+func (s *S) TestNewSessionInvalid(c *check.C) {
+	_, err := NewSession("myapp", "me@example.com", 0, checksum([]byte("x")))
+	c.Assert(err, check.Equals, ErrInvalidSize)
+	_, err = NewSession("myapp", "me@example.com", 10, "abc")
+	c.Assert(err, check.Equals, ErrInvalidChecksum)
+}
+
+func (s *S) TestGetSessionNotFound(c *check.C) {
+	_, err := GetSession("invalid")
+	c.Assert(err, check.Equals, ErrSessionNotFound)
+	_, err = GetSession("5b1a2d3e4f5a6b7c8d9e0f1a")
+	c.Assert(err, check.Equals, ErrSessionNotFound)
+}
+
+func (s *S) TestGetSessionExpired(c *check.C) {
+	data := []byte("my artifact")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	err = s.storage.Collection("deploy_upload_sessions").UpdateId(session.ID, map[string]interface{}{
+		"$set": map[string]interface{}{"expiresat": time.Now().Add(-time.Minute)},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = GetSession(session.ID.Hex())
+	c.Assert(err, check.Equals, ErrSessionNotFound)
+}
+
+func (s *S) TestAddChunksAndOpen(c *check.C) {
+	data := []byte("first part,second part,third")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	parts := [][]byte{data[:11], data[11:23], data[23:]}
+	var offset int64
+	for _, p := range parts {
+		err = session.AddChunk(offset, p, checksum(p))
+		c.Assert(err, check.IsNil)
+		offset += int64(len(p))
+	}
+	dbSession, err := GetSession(session.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbSession.Complete(), check.Equals, true)
+	c.Assert(dbSession.Chunks, check.Equals, 3)
+	file, err := dbSession.Open()
+	c.Assert(err, check.IsNil)
+	defer file.Close()
+	got, err := ioutil.ReadAll(file)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(got), check.Equals, string(data))
+}
+
+func (s *S) TestAddChunkResume(c *check.C) {
+	data := []byte("first part,second part")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	err = session.AddChunk(0, data[:11], checksum(data[:11]))
+	c.Assert(err, check.IsNil)
+	resumed, err := GetSession(session.ID.Hex())
+	c.Assert(err, check.IsNil)
+	err = resumed.AddChunk(0, data[:11], checksum(data[:11]))
+	c.Assert(err, check.DeepEquals, ErrOffsetMismatch{Offset: 11})
+	err = resumed.AddChunk(resumed.Received, data[11:], checksum(data[11:]))
+	c.Assert(err, check.IsNil)
+	c.Assert(resumed.Complete(), check.Equals, true)
+}
+
+func (s *S) TestAddChunkStaleSession(c *check.C) {
+	data := []byte("first part,second part")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	stale, err := GetSession(session.ID.Hex())
+	c.Assert(err, check.IsNil)
+	err = session.AddChunk(0, data[:11], checksum(data[:11]))
+	c.Assert(err, check.IsNil)
+	err = stale.AddChunk(0, data[:5], checksum(data[:5]))
+	c.Assert(err, check.DeepEquals, ErrOffsetMismatch{Offset: 11})
+	coll, err := chunksCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	var stored chunk
+	err = coll.Find(bson.M{"session": session.ID, "index": 0}).One(&stored)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(stored.Data), check.Equals, string(data[:11]))
+}
+
+func (s *S) TestAddChunkInvalid(c *check.C) {
+	config.Set("upload:max-chunk-size", 4)
+	data := []byte("some data")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	err = session.AddChunk(0, data[:5], checksum(data[:5]))
+	c.Assert(err, check.Equals, ErrChunkTooLarge)
+	err = session.AddChunk(0, data[:4], checksum([]byte("other")))
+	c.Assert(err, check.Equals, ErrChecksumMismatch)
+	err = session.AddChunk(0, data[:4], "xyz")
+	c.Assert(err, check.Equals, ErrInvalidChecksum)
+	config.Unset("upload:max-chunk-size")
+	err = session.AddChunk(0, append(data, 'x'), checksum(append(data, 'x')))
+	c.Assert(err, check.Equals, ErrChunkOverflow)
+	c.Assert(session.Received, check.Equals, int64(0))
+}
+
+func (s *S) TestAddChunkChecksumMismatchOnCompletion(c *check.C) {
+	data := []byte("some data")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum([]byte("other data")))
+	c.Assert(err, check.IsNil)
+	err = session.AddChunk(0, data, checksum(data))
+	c.Assert(err, check.Equals, ErrChecksumMismatch)
+	c.Assert(session.Complete(), check.Equals, false)
+}
+
+func (s *S) TestOpenIncomplete(c *check.C) {
+	data := []byte("some data")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	_, err = session.Open()
+	c.Assert(err, check.Equals, ErrIncomplete)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	data := []byte("some data")
+	session, err := NewSession("myapp", "me@example.com", int64(len(data)), checksum(data))
+	c.Assert(err, check.IsNil)
+	err = session.AddChunk(0, data, checksum(data))
+	c.Assert(err, check.IsNil)
+	err = session.Remove()
+	c.Assert(err, check.IsNil)
+	_, err = GetSession(session.ID.Hex())
+	c.Assert(err, check.Equals, ErrSessionNotFound)
+	count, err := s.storage.Collection("deploy_upload_chunks").Find(nil).Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, 0)
+	err = session.Remove()
+	c.Assert(err, check.Equals, ErrSessionNotFound)
+}
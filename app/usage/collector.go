@@ -0,0 +1,167 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const bytesPerGB = 1 << 30
+
+func collectInterval() time.Duration {
+	seconds, _ := config.GetInt("usage:collect-interval")
+	return time.Duration(seconds) * time.Second
+}
+
+// Initialize starts metering apps usage if a collect interval is configured.
+func Initialize() error {
+	if collectInterval() <= 0 {
+		return nil
+	}
+	c := &collector{once: &sync.Once{}}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type collector struct {
+	once   *sync.Once
+	stopCh chan struct{}
+}
+
+func (c *collector) start() {
+	c.once.Do(func() {
+		c.stopCh = make(chan struct{})
+		go c.spin()
+	})
+}
+
+func (c *collector) Shutdown(ctx context.Context) error {
+	if c.stopCh == nil {
+		return nil
+	}
+	c.stopCh <- struct{}{}
+	c.stopCh = nil
+	c.once = &sync.Once{}
+	return nil
+}
+
+func (c *collector) spin() {
+	for {
+		err := collect(time.Now().UTC(), collectInterval())
+		if err != nil {
+			log.Errorf("[usage] errors collecting usage: %v", err)
+		}
+		select {
+		case <-c.stopCh:
+			return
+		case <-time.After(collectInterval()):
+		}
+	}
+}
+
+// collect accounts the usage of every app since its last collection. Gaps
+// longer than two intervals, like when the API is down, are not accounted,
+// only the time of a single interval is.
+func collect(now time.Time, interval time.Duration) error {
+	apps, err := app.List(nil)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range apps {
+		err = collectApp(&apps[i], now, interval)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to collect usage for app %q", apps[i].Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+func collectApp(a *app.App, now time.Time, interval time.Duration) error {
+	units, err := a.Units()
+	if err != nil {
+		return err
+	}
+	var running int
+	for _, u := range units {
+		if u.Status != provision.StatusStopped && u.Status != provision.StatusAsleep {
+			running++
+		}
+	}
+	coll, err := recordsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	var last Record
+	err = coll.Find(bson.M{"app": a.Name}).Sort("-lastupdate").One(&last)
+	if err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	var elapsed time.Duration
+	if !last.LastUpdate.IsZero() && now.After(last.LastUpdate) {
+		elapsed = now.Sub(last.LastUpdate)
+		if elapsed > 2*interval {
+			elapsed = interval
+		}
+	}
+	month := now.Format(MonthLayout)
+	deploys, err := countDeploys(a.Name, now)
+	if err != nil {
+		return err
+	}
+	unitHours := float64(running) * elapsed.Hours()
+	_, err = coll.Upsert(bson.M{"app": a.Name, "month": month}, bson.M{
+		"$set": bson.M{
+			"team":       a.TeamOwner,
+			"pool":       a.Pool,
+			"plan":       a.Plan.Name,
+			"deploys":    deploys,
+			"lastupdate": now,
+		},
+		"$inc": bson.M{
+			"unithours":     unitHours,
+			"memorygbhours": unitHours * float64(a.Plan.Memory) / bytesPerGB,
+		},
+	})
+	return err
+}
+
+// countDeploys returns the number of deploys, successful or not, of the app
+// in the month of the given time.
+func countDeploys(appName string, now time.Time) (int, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return conn.Events().Find(bson.M{
+		"target.type":  event.TargetTypeApp,
+		"target.value": appName,
+		"kind.type":    event.KindTypePermission,
+		"kind.name":    permission.PermAppDeploy.FullName(),
+		"starttime":    bson.M{"$gte": start, "$lt": start.AddDate(0, 1, 0)},
+	}).Count()
+}
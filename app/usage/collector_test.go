@@ -0,0 +1,79 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package usage
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCollect(c *check.C) {
+	a := s.newApp(c, "myapp", 2)
+	now := time.Date(2018, time.May, 10, 12, 0, 0, 0, time.UTC)
+	s.insertDeploy(c, a.Name, now.Add(-time.Hour), "")
+	s.insertDeploy(c, a.Name, now.Add(-time.Hour), "failed")
+	s.insertDeploy(c, a.Name, now.AddDate(0, -1, 0), "")
+	err := collect(now.Add(-time.Hour), time.Hour)
+	c.Assert(err, check.IsNil)
+	err = collect(now, time.Hour)
+	c.Assert(err, check.IsNil)
+	records, err := Report(Filter{Month: "2018-05"})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 1)
+	c.Assert(records[0].App, check.Equals, a.Name)
+	c.Assert(records[0].Team, check.Equals, s.team)
+	c.Assert(records[0].Pool, check.Equals, "p1")
+	c.Assert(records[0].Plan, check.Equals, "default")
+	c.Assert(records[0].UnitHours, check.Equals, float64(2))
+	c.Assert(records[0].MemoryGBHours, check.Equals, float64(4))
+	c.Assert(records[0].Deploys, check.Equals, 2)
+	c.Assert(records[0].LastUpdate.Equal(now), check.Equals, true)
+}
+
+func (s *S) TestCollectIgnoresLongGaps(c *check.C) {
+	a := s.newApp(c, "myapp", 1)
+	now := time.Date(2018, time.May, 10, 12, 0, 0, 0, time.UTC)
+	err := collect(now.Add(-5*time.Hour), time.Hour)
+	c.Assert(err, check.IsNil)
+	err = collect(now, time.Hour)
+	c.Assert(err, check.IsNil)
+	records, err := Report(Filter{Month: "2018-05", App: a.Name})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 1)
+	c.Assert(records[0].UnitHours, check.Equals, float64(1))
+}
+
+func (s *S) TestCollectMonthBoundary(c *check.C) {
+	a := s.newApp(c, "myapp", 1)
+	now := time.Date(2018, time.June, 1, 0, 30, 0, 0, time.UTC)
+	err := collect(now.Add(-time.Hour), time.Hour)
+	c.Assert(err, check.IsNil)
+	err = collect(now, time.Hour)
+	c.Assert(err, check.IsNil)
+	records, err := Report(Filter{Month: "2018-06", App: a.Name})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 1)
+	c.Assert(records[0].UnitHours, check.Equals, float64(1))
+	records, err = Report(Filter{Month: "2018-05", App: a.Name})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 1)
+	c.Assert(records[0].UnitHours, check.Equals, float64(0))
+}
+
+func (s *S) insertDeploy(c *check.C, appName string, start time.Time, errMsg string) {
+	evt := event.Event{}
+	evt.UniqueID = bson.NewObjectId()
+	evt.Target = event.Target{Type: event.TargetTypeApp, Value: appName}
+	evt.Kind = event.Kind{Type: event.KindTypePermission, Name: permission.PermAppDeploy.FullName()}
+	evt.StartTime = start
+	evt.EndTime = start.Add(time.Minute)
+	evt.Error = errMsg
+	err := evt.RawInsert(nil, nil, nil)
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package usage meters the resources consumed by apps, recording unit-hours,
+// memory-hours and deploys per app and month, so they can be exported in
+// chargeback reports.
+package usage
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+// MonthLayout is the layout used to identify the month of usage records.
+const MonthLayout = "2006-01"
+
+var csvHeader = []string{"month", "team", "app", "pool", "plan", "unit_hours", "memory_gb_hours", "deploys"}
+
+// Record holds the resources consumed by an app in a month. Memory-hours are
+// measured in gigabytes of the app plan memory limit.
+type Record struct {
+	App           string    `json:"app"`
+	Month         string    `json:"month"`
+	Team          string    `json:"team"`
+	Pool          string    `json:"pool"`
+	Plan          string    `json:"plan"`
+	UnitHours     float64   `json:"unitHours"`
+	MemoryGBHours float64   `json:"memoryGBHours"`
+	Deploys       int       `json:"deploys"`
+	LastUpdate    time.Time `json:"lastUpdate"`
+}
+
+// Filter selects the records included in a report.
+type Filter struct {
+	Month string
+	Team  string
+	App   string
+	Extra map[string][]string
+}
+
+func (f *Filter) ExtraIn(name string, value string) {
+	if f.Extra == nil {
+		f.Extra = make(map[string][]string)
+	}
+	f.Extra[name] = append(f.Extra[name], value)
+}
+
+func (f *Filter) query() bson.M {
+	query := bson.M{"month": f.Month}
+	if f.Team != "" {
+		query["team"] = f.Team
+	}
+	if f.App != "" {
+		query["app"] = f.App
+	}
+	if f.Extra != nil {
+		var orBlock []bson.M
+		for field, values := range f.Extra {
+			orBlock = append(orBlock, bson.M{field: bson.M{"$in": values}})
+		}
+		query["$or"] = orBlock
+	}
+	return query
+}
+
+func recordsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("app_usage")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "month"}, Unique: true})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"month", "team"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// Report returns the usage records matching the filter, sorted by team and
+// app.
+func Report(f Filter) ([]Record, error) {
+	coll, err := recordsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var records []Record
+	err = coll.Find(f.query()).Sort("team", "app").All(&records)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ByTeam sums the records of each team, returning one record per team with
+// an empty app.
+func ByTeam(records []Record) []Record {
+	teams := map[string]int{}
+	var result []Record
+	for _, r := range records {
+		i, ok := teams[r.Team]
+		if !ok {
+			i = len(result)
+			teams[r.Team] = i
+			result = append(result, Record{Month: r.Month, Team: r.Team})
+		}
+		result[i].UnitHours += r.UnitHours
+		result[i].MemoryGBHours += r.MemoryGBHours
+		result[i].Deploys += r.Deploys
+		if r.LastUpdate.After(result[i].LastUpdate) {
+			result[i].LastUpdate = r.LastUpdate
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Team < result[j].Team
+	})
+	return result
+}
+
+// WriteCSV writes the records in CSV format, preceded by a header line.
+func WriteCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		err = writer.Write([]string{
+			r.Month,
+			r.Team,
+			r.App,
+			r.Pool,
+			r.Plan,
+			strconv.FormatFloat(r.UnitHours, 'f', 2, 64),
+			strconv.FormatFloat(r.MemoryGBHours, 'f', 2, 64),
+			strconv.Itoa(r.Deploys),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
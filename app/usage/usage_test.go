@@ -0,0 +1,55 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package usage
+
+import (
+	"bytes"
+
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestReportFilterExtra(c *check.C) {
+	coll, err := recordsCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	err = coll.Insert(
+		Record{App: "a1", Month: "2018-05", Team: "t1", Pool: "p1"},
+		Record{App: "a2", Month: "2018-05", Team: "t2", Pool: "p2"},
+		Record{App: "a3", Month: "2018-05", Team: "t3", Pool: "p3"},
+		Record{App: "a1", Month: "2018-04", Team: "t1", Pool: "p1"},
+	)
+	c.Assert(err, check.IsNil)
+	f := Filter{Month: "2018-05"}
+	f.ExtraIn("team", "t1")
+	f.ExtraIn("pool", "p2")
+	records, err := Report(f)
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 2)
+	c.Assert(records[0].App, check.Equals, "a1")
+	c.Assert(records[1].App, check.Equals, "a2")
+}
+
+func (s *S) TestByTeam(c *check.C) {
+	records := []Record{
+		{App: "a1", Month: "2018-05", Team: "t2", UnitHours: 1, MemoryGBHours: 2, Deploys: 1},
+		{App: "a2", Month: "2018-05", Team: "t1", UnitHours: 3, MemoryGBHours: 1, Deploys: 2},
+		{App: "a3", Month: "2018-05", Team: "t2", UnitHours: 2, MemoryGBHours: 4, Deploys: 3},
+	}
+	c.Assert(ByTeam(records), check.DeepEquals, []Record{
+		{Month: "2018-05", Team: "t1", UnitHours: 3, MemoryGBHours: 1, Deploys: 2},
+		{Month: "2018-05", Team: "t2", UnitHours: 3, MemoryGBHours: 6, Deploys: 4},
+	})
+}
+
+func (s *S) TestWriteCSV(c *check.C) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Record{
+		{App: "a1", Month: "2018-05", Team: "t1", Pool: "p1", Plan: "small", UnitHours: 1.5, MemoryGBHours: 0.375, Deploys: 3},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, `month,team,app,pool,plan,unit_hours,memory_gb_hours,deploys
+2018-05,t1,a1,p1,small,1.50,0.38,3
+`)
+}
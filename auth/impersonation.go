@@ -0,0 +1,195 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+var ErrImpersonateSelf = &tsuruErrors.ValidationError{Message: "users can't impersonate themselves"}
+
+// ImpersonatedToken is implemented by tokens of users acting as other users.
+// GetUserName returns the impersonated user and GetImpersonator the user
+// acting as them.
+type ImpersonatedToken interface {
+	Token
+	GetImpersonator() string
+}
+
+// ImpersonatorOf returns the user acting through the token when it's an
+// impersonation token, or an empty string otherwise.
+func ImpersonatorOf(t Token) string {
+	if it, ok := t.(ImpersonatedToken); ok {
+		return it.GetImpersonator()
+	}
+	return ""
+}
+
+// ImpersonationToken is a short-lived token that lets a user act as another
+// one, with the permissions of the impersonated user.
+type ImpersonationToken struct {
+	Token        string    `json:"token" bson:"_id"`
+	UserEmail    string    `json:"email"`
+	Impersonator string    `json:"impersonator"`
+	Reason       string    `json:"reason"`
+	Creation     time.Time `json:"creation"`
+	Expires      time.Time `json:"expires"`
+}
+
+func (t *ImpersonationToken) GetValue() string {
+	return t.Token
+}
+
+func (t *ImpersonationToken) User() (*User, error) {
+	return GetUserByEmail(t.UserEmail)
+}
+
+func (t *ImpersonationToken) IsAppToken() bool {
+	return false
+}
+
+func (t *ImpersonationToken) GetUserName() string {
+	return t.UserEmail
+}
+
+func (t *ImpersonationToken) GetAppName() string {
+	return ""
+}
+
+func (t *ImpersonationToken) GetImpersonator() string {
+	return t.Impersonator
+}
+
+func (t *ImpersonationToken) Permissions() ([]permission.Permission, error) {
+	return BaseTokenPermission(t)
+}
+
+func impersonationTokensCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("impersonation_tokens"), nil
+}
+
+// Impersonate issues a token for the impersonator to act as the user for
+// the given duration.
+func Impersonate(impersonator, user *User, reason string, duration time.Duration) (*ImpersonationToken, error) {
+	if impersonator.Email == user.Email {
+		return nil, ErrImpersonateSelf
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+	value := make([]byte, 32)
+	_, err := rand.Read(value)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	t := ImpersonationToken{
+		Token:        hex.EncodeToString(value),
+		UserEmail:    user.Email,
+		Impersonator: impersonator.Email,
+		Reason:       reason,
+		Creation:     now,
+		Expires:      now.Add(duration),
+	}
+	coll, err := impersonationTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	err = coll.Insert(t)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ImpersonationAuth returns the impersonation token in the header, failing
+// with ErrInvalidToken when it's unknown or expired, or when the
+// impersonator is no longer enabled and allowed to impersonate the user.
+func ImpersonationAuth(header string) (*ImpersonationToken, error) {
+	value, err := ParseToken(header)
+	if err != nil {
+		return nil, err
+	}
+	coll, err := impersonationTokensCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var t ImpersonationToken
+	err = coll.Find(bson.M{"_id": value, "expires": bson.M{"$gt": time.Now().UTC()}}).One(&t)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	err = checkImpersonator(&t)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func checkImpersonator(t *ImpersonationToken) error {
+	impersonator, err := GetUserByEmail(t.Impersonator)
+	if err == authTypes.ErrUserNotFound {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	if impersonator.Disabled {
+		return ErrInvalidToken
+	}
+	perms, err := impersonator.Permissions()
+	if err != nil {
+		return err
+	}
+	if !permission.CheckFromPermList(perms, permission.PermUserImpersonate, permission.Context(permission.CtxUser, t.UserEmail)) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// EndImpersonation revokes the impersonation token.
+func EndImpersonation(token string) error {
+	coll, err := impersonationTokensCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.RemoveId(token)
+	if err == mgo.ErrNotFound {
+		return ErrInvalidToken
+	}
+	return err
+}
+
+// RevokeImpersonations revokes the impersonation tokens acting as the user
+// and the ones issued to the user to act as others.
+func RevokeImpersonations(email string) error {
+	coll, err := impersonationTokensCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.RemoveAll(bson.M{"$or": []bson.M{{"useremail": email}, {"impersonator": email}}})
+	return err
+}
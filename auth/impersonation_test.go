@@ -0,0 +1,123 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/permission"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) createImpersonator(c *check.C) *User {
+	role, err := permission.NewRole("impersonator", "global", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions("user.impersonate")
+	c.Assert(err, check.IsNil)
+	admin := &User{Email: "admin@globo.com", Password: "123456"}
+	err = admin.Create()
+	c.Assert(err, check.IsNil)
+	err = admin.AddRole(role.Name, "")
+	c.Assert(err, check.IsNil)
+	return admin
+}
+
+func (s *S) TestImpersonate(c *check.C) {
+	admin := s.createImpersonator(c)
+	t, err := Impersonate(admin, s.user, "support ticket", 10*time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.GetUserName(), check.Equals, s.user.Email)
+	c.Assert(t.GetImpersonator(), check.Equals, admin.Email)
+	c.Assert(ImpersonatorOf(t), check.Equals, admin.Email)
+	c.Assert(t.Expires.Sub(t.Creation), check.Equals, 10*time.Minute)
+	authToken, err := ImpersonationAuth("bearer " + t.GetValue())
+	c.Assert(err, check.IsNil)
+	c.Assert(authToken.UserEmail, check.Equals, s.user.Email)
+	c.Assert(authToken.Reason, check.Equals, "support ticket")
+	u, err := authToken.User()
+	c.Assert(err, check.IsNil)
+	c.Assert(u.Email, check.Equals, s.user.Email)
+}
+
+func (s *S) TestImpersonateSelf(c *check.C) {
+	_, err := Impersonate(s.user, s.user, "test", time.Minute)
+	c.Assert(err, check.Equals, ErrImpersonateSelf)
+}
+
+func (s *S) TestImpersonateDisabledUser(c *check.C) {
+	s.user.Disabled = true
+	_, err := Impersonate(&User{Email: "admin@globo.com"}, s.user, "test", time.Minute)
+	c.Assert(err, check.Equals, ErrUserDisabled)
+}
+
+func (s *S) TestImpersonationAuthExpired(c *check.C) {
+	admin := s.createImpersonator(c)
+	t, err := Impersonate(admin, s.user, "test", -time.Second)
+	c.Assert(err, check.IsNil)
+	_, err = ImpersonationAuth(t.GetValue())
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) TestEndImpersonation(c *check.C) {
+	admin := s.createImpersonator(c)
+	t, err := Impersonate(admin, s.user, "test", time.Minute)
+	c.Assert(err, check.IsNil)
+	err = EndImpersonation(t.GetValue())
+	c.Assert(err, check.IsNil)
+	_, err = ImpersonationAuth(t.GetValue())
+	c.Assert(err, check.Equals, ErrInvalidToken)
+	err = EndImpersonation(t.GetValue())
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) TestRevokeImpersonations(c *check.C) {
+	admin := s.createImpersonator(c)
+	other := &User{Email: "other@globo.com"}
+	t1, err := Impersonate(admin, s.user, "test", time.Minute)
+	c.Assert(err, check.IsNil)
+	t2, err := Impersonate(admin, other, "test", time.Minute)
+	c.Assert(err, check.IsNil)
+	err = RevokeImpersonations(s.user.Email)
+	c.Assert(err, check.IsNil)
+	_, err = ImpersonationAuth(t1.GetValue())
+	c.Assert(err, check.Equals, ErrInvalidToken)
+	_, err = ImpersonationAuth(t2.GetValue())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestRevokeImpersonationsOfImpersonator(c *check.C) {
+	admin := s.createImpersonator(c)
+	t, err := Impersonate(admin, s.user, "test", time.Minute)
+	c.Assert(err, check.IsNil)
+	err = RevokeImpersonations(admin.Email)
+	c.Assert(err, check.IsNil)
+	_, err = ImpersonationAuth(t.GetValue())
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) TestImpersonationAuthDisabledImpersonator(c *check.C) {
+	admin := s.createImpersonator(c)
+	t, err := Impersonate(admin, s.user, "test", time.Minute)
+	c.Assert(err, check.IsNil)
+	admin.Disabled = true
+	err = admin.Update()
+	c.Assert(err, check.IsNil)
+	_, err = ImpersonationAuth(t.GetValue())
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) TestImpersonationAuthImpersonatorWithoutPermission(c *check.C) {
+	admin := s.createImpersonator(c)
+	t, err := Impersonate(admin, s.user, "test", time.Minute)
+	c.Assert(err, check.IsNil)
+	err = admin.RemoveRole("impersonator", "")
+	c.Assert(err, check.IsNil)
+	_, err = ImpersonationAuth(t.GetValue())
+	c.Assert(err, check.Equals, ErrInvalidToken)
+}
+
+func (s *S) TestImpersonatorOfOtherTokens(c *check.C) {
+	c.Assert(ImpersonatorOf(&APIToken{UserEmail: s.user.Email}), check.Equals, "")
+}
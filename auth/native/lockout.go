@@ -0,0 +1,238 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	EventKindLoginLockout = "login-lockout"
+
+	defaultLockoutWindow   = 10 * time.Minute
+	defaultLockoutDuration = 15 * time.Minute
+)
+
+// lockoutConfig holds the limits of failed logins. Failures are counted per
+// user and per IP within the window, and reaching the maximum of either
+// blocks logins for the lockout duration. A maximum of zero disables the
+// corresponding limit.
+type lockoutConfig struct {
+	maxUserFailures int
+	maxIPFailures   int
+	window          time.Duration
+	duration        time.Duration
+}
+
+func getLockoutConfig() lockoutConfig {
+	cfg := lockoutConfig{
+		window:   defaultLockoutWindow,
+		duration: defaultLockoutDuration,
+	}
+	cfg.maxUserFailures, _ = config.GetInt("auth:login-lockout:max-failures")
+	cfg.maxIPFailures, _ = config.GetInt("auth:login-lockout:max-failures-per-ip")
+	if seconds, _ := config.GetInt("auth:login-lockout:window"); seconds > 0 {
+		cfg.window = time.Duration(seconds) * time.Second
+	}
+	if seconds, _ := config.GetInt("auth:login-lockout:duration"); seconds > 0 {
+		cfg.duration = time.Duration(seconds) * time.Second
+	}
+	return cfg
+}
+
+// Lockout describes logins blocked after repeated failures, either of the
+// user with the given email or, when Email is empty, from the IP.
+type Lockout struct {
+	Email       string
+	IP          string
+	Failures    int
+	LockedUntil time.Time
+}
+
+// LockoutNotifier is called whenever logins get blocked. The API sets it to
+// record the lockouts as events.
+var LockoutNotifier func(Lockout)
+
+func (c lockoutConfig) enabled() bool {
+	return c.maxUserFailures > 0 || c.maxIPFailures > 0
+}
+
+// loginFailures counts the failed logins of a user or an IP since the start
+// of the current window.
+type loginFailures struct {
+	Key         string `bson:"_id"`
+	Failures    int
+	Since       time.Time
+	LockedUntil time.Time
+}
+
+func userLockoutKey(email string) string {
+	return "user:" + email
+}
+
+func ipLockoutKey(ip string) string {
+	return "ip:" + ip
+}
+
+func loginFailuresCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("login_failures"), nil
+}
+
+// checkLockout returns auth.LoginLockedError when logins of the user or from
+// the IP are blocked.
+func checkLockout(email, ip string) error {
+	if !getLockoutConfig().enabled() {
+		return nil
+	}
+	coll, err := loginFailuresCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	keys := []string{userLockoutKey(email)}
+	if ip != "" {
+		keys = append(keys, ipLockoutKey(ip))
+	}
+	var failures []loginFailures
+	err = coll.Find(bson.M{"_id": bson.M{"$in": keys}, "lockeduntil": bson.M{"$gt": time.Now().UTC()}}).All(&failures)
+	if err != nil {
+		return err
+	}
+	var until time.Time
+	for _, f := range failures {
+		if f.LockedUntil.After(until) {
+			until = f.LockedUntil
+		}
+	}
+	if until.IsZero() {
+		return nil
+	}
+	return auth.LoginLockedError{Until: until}
+}
+
+// recordLoginFailure counts a failed login of the user from the IP, locking
+// them out once they reach the limits. The user is only counted when it
+// exists, so failures for unknown emails only count against the IP.
+// Failures are only logged, as they must not change the login error.
+func recordLoginFailure(email, ip string, userExists bool) {
+	cfg := getLockoutConfig()
+	if userExists && cfg.maxUserFailures > 0 {
+		err := incLoginFailures(userLockoutKey(email), cfg.maxUserFailures, cfg, func(f *loginFailures) {
+			notifyLockout(Lockout{Email: email, IP: ip, Failures: f.Failures, LockedUntil: f.LockedUntil})
+		})
+		if err != nil {
+			log.Errorf("[login lockout] unable to record failed login of user %q: %v", email, err)
+		}
+	}
+	if ip != "" && cfg.maxIPFailures > 0 {
+		err := incLoginFailures(ipLockoutKey(ip), cfg.maxIPFailures, cfg, func(f *loginFailures) {
+			notifyLockout(Lockout{IP: ip, Failures: f.Failures, LockedUntil: f.LockedUntil})
+		})
+		if err != nil {
+			log.Errorf("[login lockout] unable to record failed login from %q: %v", ip, err)
+		}
+	}
+}
+
+func incLoginFailures(key string, max int, cfg lockoutConfig, onLockout func(*loginFailures)) error {
+	coll, err := loginFailuresCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	err = coll.Update(
+		bson.M{"_id": key, "since": bson.M{"$lt": now.Add(-cfg.window)}},
+		bson.M{"$set": bson.M{"failures": 0, "since": now}},
+	)
+	if err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	var failures loginFailures
+	_, err = coll.FindId(key).Apply(mgo.Change{
+		Update: bson.M{
+			"$inc":         bson.M{"failures": 1},
+			"$setOnInsert": bson.M{"since": now},
+		},
+		Upsert:    true,
+		ReturnNew: true,
+	}, &failures)
+	if err != nil {
+		return err
+	}
+	if failures.Failures < max {
+		return nil
+	}
+	lockedUntil := now.Add(cfg.duration)
+	// Only the request reaching the limit locks, so concurrent failures
+	// don't extend the lockout or notify it more than once.
+	err = coll.Update(
+		bson.M{"_id": key, "failures": failures.Failures},
+		bson.M{"$set": bson.M{"failures": 0, "since": now, "lockeduntil": lockedUntil}},
+	)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	failures.LockedUntil = lockedUntil
+	onLockout(&failures)
+	return nil
+}
+
+func notifyLockout(lockout Lockout) {
+	if LockoutNotifier != nil {
+		LockoutNotifier(lockout)
+	}
+}
+
+// clearLoginFailures resets the failed logins of the user after a successful
+// login. Failures of the IP are kept, so one valid account can't be used to
+// reset the count while guessing the passwords of others.
+func clearLoginFailures(email string) {
+	if !getLockoutConfig().enabled() {
+		return
+	}
+	err := unlock(userLockoutKey(email))
+	if err != nil {
+		log.Errorf("[login lockout] unable to reset failed logins of user %q: %v", email, err)
+	}
+}
+
+func unlock(key string) error {
+	coll, err := loginFailuresCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.RemoveId(key)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// UnlockUser removes the lockout and the failed logins of the user.
+func (s NativeScheme) UnlockUser(email string) error {
+	return unlock(userLockoutKey(email))
+}
+
+// UnlockIP removes the lockout and the failed logins from the IP.
+func (s NativeScheme) UnlockIP(ip string) error {
+	return unlock(ipLockoutKey(ip))
+}
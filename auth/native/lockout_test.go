@@ -0,0 +1,107 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) setLockoutConfig(maxUser, maxIP int) func() {
+	config.Set("auth:login-lockout:max-failures", maxUser)
+	config.Set("auth:login-lockout:max-failures-per-ip", maxIP)
+	return func() {
+		config.Unset("auth:login-lockout:max-failures")
+		config.Unset("auth:login-lockout:max-failures-per-ip")
+	}
+}
+
+func (s *S) captureLockouts() (*[]Lockout, func()) {
+	var lockouts []Lockout
+	LockoutNotifier = func(l Lockout) {
+		lockouts = append(lockouts, l)
+	}
+	return &lockouts, func() { LockoutNotifier = nil }
+}
+
+func (s *S) login(email, password, ip string) (auth.Token, error) {
+	return nativeScheme.Login(map[string]string{
+		"email":             email,
+		"password":          password,
+		auth.SessionIPParam: ip,
+	})
+}
+
+func (s *S) TestLoginLockoutPerUser(c *check.C) {
+	defer s.setLockoutConfig(3, 0)()
+	lockouts, restore := s.captureLockouts()
+	defer restore()
+	for i := 0; i < 3; i++ {
+		_, err := s.login(s.user.Email, "wrongpass", "10.0.0.1")
+		c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	}
+	_, err := s.login(s.user.Email, "123456", "10.0.0.2")
+	c.Assert(err, check.FitsTypeOf, auth.LoginLockedError{})
+	c.Assert(*lockouts, check.HasLen, 1)
+	c.Assert((*lockouts)[0].Email, check.Equals, s.user.Email)
+	c.Assert((*lockouts)[0].IP, check.Equals, "10.0.0.1")
+	c.Assert((*lockouts)[0].Failures, check.Equals, 3)
+	err = nativeScheme.UnlockUser(s.user.Email)
+	c.Assert(err, check.IsNil)
+	_, err = s.login(s.user.Email, "123456", "10.0.0.2")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestLoginLockoutSuccessResetsUserFailures(c *check.C) {
+	defer s.setLockoutConfig(2, 0)()
+	_, err := s.login(s.user.Email, "wrongpass", "10.0.0.1")
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	_, err = s.login(s.user.Email, "123456", "10.0.0.1")
+	c.Assert(err, check.IsNil)
+	_, err = s.login(s.user.Email, "wrongpass", "10.0.0.1")
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	_, err = s.login(s.user.Email, "123456", "10.0.0.1")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestLoginLockoutPerIP(c *check.C) {
+	defer s.setLockoutConfig(0, 2)()
+	lockouts, restore := s.captureLockouts()
+	defer restore()
+	_, err := s.login("unknown@globo.com", "123456", "10.0.0.1")
+	c.Assert(err, check.Equals, authTypes.ErrUserNotFound)
+	_, err = s.login(s.user.Email, "wrongpass", "10.0.0.1")
+	c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	_, err = s.login(s.user.Email, "123456", "10.0.0.1")
+	c.Assert(err, check.FitsTypeOf, auth.LoginLockedError{})
+	_, err = s.login(s.user.Email, "123456", "10.0.0.2")
+	c.Assert(err, check.IsNil)
+	c.Assert(*lockouts, check.DeepEquals, []Lockout{{IP: "10.0.0.1", Failures: 2, LockedUntil: (*lockouts)[0].LockedUntil}})
+	err = nativeScheme.UnlockIP("10.0.0.1")
+	c.Assert(err, check.IsNil)
+	_, err = s.login(s.user.Email, "123456", "10.0.0.1")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestLoginLockoutDisabled(c *check.C) {
+	for i := 0; i < 5; i++ {
+		_, err := s.login(s.user.Email, "wrongpass", "10.0.0.1")
+		c.Assert(err, check.FitsTypeOf, auth.AuthenticationFailure{})
+	}
+	_, err := s.login(s.user.Email, "123456", "10.0.0.1")
+	c.Assert(err, check.IsNil)
+	n, err := s.conn.Collection("login_failures").Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+}
+
+func (s *S) TestUnlockWithoutLockout(c *check.C) {
+	err := nativeScheme.UnlockUser(s.user.Email)
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.UnlockIP("10.0.0.1")
+	c.Assert(err, check.IsNil)
+}
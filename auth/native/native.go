@@ -8,6 +8,7 @@ import (
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/errors"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 	"github.com/tsuru/tsuru/validation"
 )
 
@@ -35,14 +36,26 @@ func (s NativeScheme) Login(params map[string]string) (auth.Token, error) {
 	if !ok {
 		return nil, ErrMissingPasswordError
 	}
+	client, ip := auth.SessionInfoFromParams(params)
+	err := checkLockout(email, ip)
+	if err != nil {
+		return nil, err
+	}
 	user, err := auth.GetUserByEmail(email)
 	if err != nil {
+		if err == authTypes.ErrUserNotFound {
+			recordLoginFailure(email, ip, false)
+		}
 		return nil, err
 	}
-	token, err := createToken(user, password)
+	token, err := createToken(user, password, client, ip)
 	if err != nil {
+		if _, ok := err.(auth.AuthenticationFailure); ok {
+			recordLoginFailure(email, ip, true)
+		}
 		return nil, err
 	}
+	clearLoginFailures(email)
 	return token, nil
 }
 
@@ -66,13 +79,15 @@ func (s NativeScheme) Create(user *auth.User) (*auth.User, error) {
 	if !validation.ValidateEmail(user.Email) {
 		return nil, ErrInvalidEmail
 	}
-	if !validation.ValidateLength(user.Password, passwordMinLen, passwordMaxLen) {
-		return nil, ErrInvalidPassword
+	if err := validatePassword(getPasswordPolicy(), user.Password); err != nil {
+		return nil, err
 	}
 	if _, err := auth.GetUserByEmail(user.Email); err == nil {
 		return nil, ErrEmailRegistered
 	}
-	if err := hashPassword(user); err != nil {
+	password := user.Password
+	user.Password = ""
+	if err := setPassword(user, password); err != nil {
 		return nil, err
 	}
 	if err := user.Create(); err != nil {
@@ -89,11 +104,9 @@ func (s NativeScheme) ChangePassword(token auth.Token, oldPassword string, newPa
 	if err = checkPassword(user.Password, oldPassword); err != nil {
 		return ErrPasswordMismatch
 	}
-	if !validation.ValidateLength(newPassword, passwordMinLen, passwordMaxLen) {
-		return ErrInvalidPassword
+	if err = setPassword(user, newPassword); err != nil {
+		return err
 	}
-	user.Password = newPassword
-	hashPassword(user)
 	return user.Update()
 }
 
@@ -125,9 +138,10 @@ func (s NativeScheme) ResetPassword(user *auth.User, resetToken string) error {
 	if passToken.UserEmail != user.Email {
 		return auth.ErrInvalidToken
 	}
-	password := generatePassword(12)
-	user.Password = password
-	hashPassword(user)
+	password := generatePolicyPassword()
+	if err = setPassword(user, password); err != nil {
+		return err
+	}
 	go sendNewPassword(user, password)
 	passToken.Used = true
 	conn.PasswordTokens().UpdateId(passToken.Token, passToken)
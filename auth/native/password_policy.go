@@ -0,0 +1,197 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/validation"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	generatedPasswordLen      = 12
+	generatedPasswordAttempts = 100
+)
+
+var ErrPasswordExpired = &errors.NotAuthorizedError{Message: "your password has expired, reset it to log in again"}
+
+func (s NativeScheme) PasswordPolicy() auth.PasswordPolicy {
+	return getPasswordPolicy()
+}
+
+func getPasswordPolicy() auth.PasswordPolicy {
+	policy := auth.PasswordPolicy{
+		MinLength: passwordMinLen,
+		MaxLength: passwordMaxLen,
+	}
+	if minLength, _ := config.GetInt("auth:password-policy:min-length"); minLength > 0 {
+		policy.MinLength = minLength
+	}
+	if maxLength, _ := config.GetInt("auth:password-policy:max-length"); maxLength > 0 {
+		policy.MaxLength = maxLength
+	}
+	if policy.MaxLength < policy.MinLength {
+		policy.MaxLength = policy.MinLength
+	}
+	policy.RequireUppercase, _ = config.GetBool("auth:password-policy:require-uppercase")
+	policy.RequireLowercase, _ = config.GetBool("auth:password-policy:require-lowercase")
+	policy.RequireDigit, _ = config.GetBool("auth:password-policy:require-digit")
+	policy.RequireSymbol, _ = config.GetBool("auth:password-policy:require-symbol")
+	policy.History, _ = config.GetInt("auth:password-policy:history")
+	policy.MaxAge, _ = config.GetInt("auth:password-policy:max-age-days")
+	return policy
+}
+
+// validatePassword checks the length and the characters of the password
+// against the policy.
+func validatePassword(policy auth.PasswordPolicy, password string) error {
+	if !validation.ValidateLength(password, policy.MinLength, policy.MaxLength) {
+		if policy.MinLength == passwordMinLen && policy.MaxLength == passwordMaxLen {
+			return ErrInvalidPassword
+		}
+		return &errors.ValidationError{
+			Message: fmt.Sprintf("password length should be least %d characters and at most %d characters", policy.MinLength, policy.MaxLength),
+		}
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	var missing []string
+	if policy.RequireUppercase && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if policy.RequireLowercase && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if policy.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	msg := missing[len(missing)-1]
+	if len(missing) > 1 {
+		msg = strings.Join(missing[:len(missing)-1], ", ") + " and " + msg
+	}
+	return &errors.ValidationError{Message: "password must contain " + msg}
+}
+
+// reusedPassword returns whether the password is the current password of the
+// user or one of the previous ones kept by the policy history.
+func reusedPassword(policy auth.PasswordPolicy, u *auth.User, password string) bool {
+	if policy.History <= 0 {
+		return false
+	}
+	hashes := []string{u.Password}
+	for i := 0; i < len(u.PasswordHistory) && i < policy.History-1; i++ {
+		hashes = append(hashes, u.PasswordHistory[i])
+	}
+	for _, hash := range hashes {
+		if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// setPassword validates the password against the policy and sets it as the
+// new password of the user, keeping the hash of the current one in the
+// history. The user is not saved.
+func setPassword(u *auth.User, password string) error {
+	policy := getPasswordPolicy()
+	err := validatePassword(policy, password)
+	if err != nil {
+		return err
+	}
+	if reusedPassword(policy, u, password) {
+		return &errors.ValidationError{
+			Message: fmt.Sprintf("password must be different from the last %d passwords", policy.History),
+		}
+	}
+	if policy.History > 1 && u.Password != "" {
+		u.PasswordHistory = append([]string{u.Password}, u.PasswordHistory...)
+		if len(u.PasswordHistory) > policy.History-1 {
+			u.PasswordHistory = u.PasswordHistory[:policy.History-1]
+		}
+	} else {
+		u.PasswordHistory = nil
+	}
+	u.Password = password
+	err = hashPassword(u)
+	if err != nil {
+		return err
+	}
+	u.PasswordChangedAt = time.Now().UTC()
+	return nil
+}
+
+// checkPasswordAge returns ErrPasswordExpired when the password of the user
+// is older than the maximum age of the policy. Users without the date of the
+// last password change have it set to now, so their passwords expire after
+// the maximum age from the first login with the policy in place.
+func checkPasswordAge(u *auth.User) error {
+	policy := getPasswordPolicy()
+	if policy.MaxAge <= 0 {
+		return nil
+	}
+	if u.PasswordChangedAt.IsZero() {
+		conn, err := db.Conn()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		u.PasswordChangedAt = time.Now().UTC()
+		return conn.Users().Update(bson.M{"email": u.Email}, bson.M{"$set": bson.M{"passwordchangedat": u.PasswordChangedAt}})
+	}
+	maxAge := time.Duration(policy.MaxAge) * 24 * time.Hour
+	if time.Since(u.PasswordChangedAt) > maxAge {
+		return ErrPasswordExpired
+	}
+	return nil
+}
+
+// generatePolicyPassword generates a random password following the policy.
+// Passwords are generated until one has all the required characters, giving
+// up after a few attempts on policies that can't be met.
+func generatePolicyPassword() string {
+	policy := getPasswordPolicy()
+	length := generatedPasswordLen
+	if length < policy.MinLength {
+		length = policy.MinLength
+	}
+	if length > policy.MaxLength {
+		length = policy.MaxLength
+	}
+	var password string
+	for i := 0; i < generatedPasswordAttempts; i++ {
+		password = generatePassword(length)
+		if validatePassword(policy, password) == nil {
+			break
+		}
+	}
+	return password
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package native
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) setPasswordPolicy(values map[string]interface{}) func() {
+	for key, value := range values {
+		config.Set("auth:password-policy:"+key, value)
+	}
+	return func() {
+		config.Unset("auth:password-policy")
+	}
+}
+
+func (s *S) TestGetPasswordPolicyDefaults(c *check.C) {
+	c.Assert(getPasswordPolicy(), check.DeepEquals, auth.PasswordPolicy{MinLength: 6, MaxLength: 50})
+}
+
+func (s *S) TestGetPasswordPolicy(c *check.C) {
+	defer s.setPasswordPolicy(map[string]interface{}{
+		"min-length":        10,
+		"require-uppercase": true,
+		"require-symbol":    true,
+		"history":           3,
+		"max-age-days":      90,
+	})()
+	c.Assert(NativeScheme{}.PasswordPolicy(), check.DeepEquals, auth.PasswordPolicy{
+		MinLength:        10,
+		MaxLength:        50,
+		RequireUppercase: true,
+		RequireSymbol:    true,
+		History:          3,
+		MaxAge:           90,
+	})
+}
+
+func (s *S) TestValidatePassword(c *check.C) {
+	policy := auth.PasswordPolicy{
+		MinLength:        8,
+		MaxLength:        20,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+	tests := []struct {
+		password string
+		message  string
+	}{
+		{"Secret-123", ""},
+		{"Sec-1", "password length should be least 8 characters and at most 20 characters"},
+		{"secret-123", "password must contain an uppercase letter"},
+		{"SECRET-123", "password must contain a lowercase letter"},
+		{"secretpass", "password must contain an uppercase letter, a digit and a symbol"},
+	}
+	for _, tt := range tests {
+		err := validatePassword(policy, tt.password)
+		if tt.message == "" {
+			c.Check(err, check.IsNil, check.Commentf("password %q", tt.password))
+			continue
+		}
+		c.Check(err, check.FitsTypeOf, &errors.ValidationError{}, check.Commentf("password %q", tt.password))
+		c.Check(err, check.ErrorMatches, tt.message, check.Commentf("password %q", tt.password))
+	}
+	err := validatePassword(getPasswordPolicy(), "123")
+	c.Assert(err, check.Equals, ErrInvalidPassword)
+}
+
+func (s *S) TestCreateEnforcesPasswordPolicy(c *check.C) {
+	defer s.setPasswordPolicy(map[string]interface{}{"require-digit": true})()
+	_, err := nativeScheme.Create(&auth.User{Email: "x@x.com", Password: "secretpass"})
+	c.Assert(err, check.ErrorMatches, "password must contain a digit")
+	user := &auth.User{Email: "x@x.com", Password: "secretpass1"}
+	_, err = nativeScheme.Create(user)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.PasswordChangedAt.IsZero(), check.Equals, false)
+	c.Assert(user.PasswordHistory, check.HasLen, 0)
+}
+
+func (s *S) TestChangePasswordEnforcesPasswordPolicy(c *check.C) {
+	defer s.setPasswordPolicy(map[string]interface{}{"min-length": 8})()
+	err := nativeScheme.ChangePassword(s.token, "123456", "1234567")
+	c.Assert(err, check.ErrorMatches, "password length should be least 8 characters and at most 50 characters")
+}
+
+func (s *S) TestChangePasswordHistory(c *check.C) {
+	defer s.setPasswordPolicy(map[string]interface{}{"history": 3})()
+	err := nativeScheme.ChangePassword(s.token, "123456", "123456")
+	c.Assert(err, check.ErrorMatches, "password must be different from the last 3 passwords")
+	err = nativeScheme.ChangePassword(s.token, "123456", "password1")
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.ChangePassword(s.token, "password1", "password2")
+	c.Assert(err, check.IsNil)
+	user, err := auth.GetUserByEmail(s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.PasswordHistory, check.HasLen, 2)
+	err = nativeScheme.ChangePassword(s.token, "password2", "123456")
+	c.Assert(err, check.ErrorMatches, "password must be different from the last 3 passwords")
+	err = nativeScheme.ChangePassword(s.token, "password2", "password3")
+	c.Assert(err, check.IsNil)
+	err = nativeScheme.ChangePassword(s.token, "password3", "123456")
+	c.Assert(err, check.IsNil)
+	user, err = auth.GetUserByEmail(s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.PasswordHistory, check.HasLen, 2)
+}
+
+func (s *S) TestLoginPasswordExpired(c *check.C) {
+	defer s.setPasswordPolicy(map[string]interface{}{"max-age-days": 30})()
+	_, err := nativeScheme.Login(map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.IsNil)
+	user, err := auth.GetUserByEmail(s.user.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.PasswordChangedAt.IsZero(), check.Equals, false)
+	err = s.conn.Users().Update(bson.M{"email": s.user.Email}, bson.M{
+		"$set": bson.M{"passwordchangedat": time.Now().Add(-31 * 24 * time.Hour)},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(map[string]string{"email": s.user.Email, "password": "123456"})
+	c.Assert(err, check.Equals, ErrPasswordExpired)
+	err = nativeScheme.ChangePassword(s.token, "123456", "654321")
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Login(map[string]string{"email": s.user.Email, "password": "654321"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestGeneratePolicyPassword(c *check.C) {
+	defer s.setPasswordPolicy(map[string]interface{}{
+		"min-length":        16,
+		"require-uppercase": true,
+		"require-lowercase": true,
+		"require-digit":     true,
+		"require-symbol":    true,
+	})()
+	password := generatePolicyPassword()
+	c.Assert(password, check.HasLen, 16)
+	c.Assert(validatePassword(getPasswordPolicy(), password), check.IsNil)
+}
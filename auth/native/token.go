@@ -10,14 +10,13 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/auth"
-	"github.com/tsuru/tsuru/db"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/storage"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 	"github.com/tsuru/tsuru/validation"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -41,6 +40,9 @@ type Token struct {
 	Expires   time.Duration `json:"expires"`
 	UserEmail string        `json:"email"`
 	AppName   string        `json:"app"`
+	Client    string        `json:"client,omitempty"`
+	IP        string        `json:"ip,omitempty"`
+	LastUse   time.Time     `json:"lastUse"`
 }
 
 func (t *Token) GetValue() string {
@@ -128,39 +130,39 @@ func newUserToken(u *auth.User) (*Token, error) {
 }
 
 func removeOldTokens(userEmail string) error {
-	conn, err := db.Conn()
+	limit, err := config.GetInt("auth:max-simultaneous-sessions")
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	var limit int
-	if limit, err = config.GetInt("auth:max-simultaneous-sessions"); err != nil {
+	tokenStorage, err := storage.GetTokenStorage()
+	if err != nil {
 		return err
 	}
-	count, err := conn.Tokens().Find(bson.M{"useremail": userEmail}).Count()
+	tokens, err := tokenStorage.FindByUserEmail(userEmail)
 	if err != nil {
 		return err
 	}
-	diff := count - limit
+	diff := len(tokens) - limit
 	if diff < 1 {
 		return nil
 	}
-	var tokens []map[string]interface{}
-	err = conn.Tokens().Find(bson.M{"useremail": userEmail}).
-		Select(bson.M{"_id": 1}).Sort("creation").Limit(diff).All(&tokens)
-	if err != nil {
-		return nil
-	}
-	ids := make([]interface{}, 0, len(tokens))
-	for _, token := range tokens {
-		ids = append(ids, token["_id"])
+	for _, t := range tokens[:diff] {
+		err = tokenStorage.Delete(t)
+		if err != nil && err != authTypes.ErrTokenNotFound {
+			return err
+		}
 	}
-	_, err = conn.Tokens().RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
-	return err
+	return nil
 }
 
 func checkPassword(passwordHash string, password string) error {
-	if !validation.ValidateLength(password, passwordMinLen, passwordMaxLen) {
+	// Passwords set before the policy changed must still be accepted, so
+	// only the default lengths are enforced here.
+	maxLen := passwordMaxLen
+	if policyMax := getPasswordPolicy().MaxLength; policyMax > maxLen {
+		maxLen = policyMax
+	}
+	if !validation.ValidateLength(password, passwordMinLen, maxLen) {
 		return &tsuruErrors.ValidationError{Message: passwordError}
 	}
 	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) == nil {
@@ -169,83 +171,88 @@ func checkPassword(passwordHash string, password string) error {
 	return auth.AuthenticationFailure{Message: "Authentication failed, wrong password."}
 }
 
-func createToken(u *auth.User, password string) (*Token, error) {
+func createToken(u *auth.User, password, client, ip string) (*Token, error) {
 	if u.Email == "" {
 		return nil, errors.New("User does not have an email")
 	}
 	if err := checkPassword(u.Password, password); err != nil {
 		return nil, err
 	}
-	conn, err := db.Conn()
+	if u.Disabled {
+		return nil, auth.ErrUserDisabled
+	}
+	if err := checkPasswordAge(u); err != nil {
+		return nil, err
+	}
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 	token, err := newUserToken(u)
 	if err != nil {
 		return nil, err
 	}
-	err = conn.Tokens().Insert(token)
+	token.Client = client
+	token.IP = ip
+	err = tokenStorage.Insert(authTypes.Token(*token))
 	go removeOldTokens(u.Email)
 	return token, err
 }
 
 func getToken(header string) (*Token, error) {
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
-	var t Token
 	token, err := auth.ParseToken(header)
 	if err != nil {
 		return nil, err
 	}
-	err = conn.Tokens().Find(bson.M{"token": token}).One(&t)
+	stored, err := tokenStorage.FindByToken(token)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == authTypes.ErrTokenNotFound {
 			return nil, auth.ErrInvalidToken
 		}
 		return nil, err
 	}
+	t := Token(*stored)
 	if t.Expires > 0 && time.Until(t.Creation.Add(t.Expires)) < 1 {
 		return nil, auth.ErrInvalidToken
 	}
+	if t.UserEmail != "" {
+		auth.TouchSession(*stored)
+	}
 	return &t, nil
 }
 
 func deleteToken(token string) error {
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	return conn.Tokens().Remove(bson.M{"token": token})
+	return tokenStorage.Delete(authTypes.Token{Token: token})
 }
 
 func deleteAllTokens(email string) error {
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	_, err = conn.Tokens().RemoveAll(bson.M{"useremail": email})
-	return err
+	return tokenStorage.DeleteByUserEmail(email)
 }
 
 func createApplicationToken(appName string) (*Token, error) {
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 	t := Token{
 		Token:    token(appName, crypto.SHA1),
 		Creation: time.Now(),
 		Expires:  0,
 		AppName:  appName,
 	}
-	err = conn.Tokens().Insert(t)
+	err = tokenStorage.Insert(authTypes.Token(t))
 	if err != nil {
 		return nil, err
 	}
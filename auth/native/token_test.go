@@ -202,7 +202,7 @@ func (s *S) TestCreateTokenShouldSaveTheTokenInTheDatabase(c *check.C) {
 	_, err := nativeScheme.Create(&u)
 	c.Assert(err, check.IsNil)
 	defer u.Delete()
-	_, err = createToken(&u, "123456")
+	_, err = createToken(&u, "123456", "", "")
 	c.Assert(err, check.IsNil)
 	var result Token
 	err = s.conn.Tokens().Find(bson.M{"useremail": u.Email}).One(&result)
@@ -210,6 +210,20 @@ func (s *S) TestCreateTokenShouldSaveTheTokenInTheDatabase(c *check.C) {
 	c.Assert(result.Token, check.NotNil)
 }
 
+func (s *S) TestCreateTokenSessionInfo(c *check.C) {
+	u := auth.User{Email: "wolverine@xmen.com", Password: "123456"}
+	_, err := nativeScheme.Create(&u)
+	c.Assert(err, check.IsNil)
+	defer u.Delete()
+	_, err = createToken(&u, "123456", "tsuru-client/1.6.0", "10.0.0.1")
+	c.Assert(err, check.IsNil)
+	var result Token
+	err = s.conn.Tokens().Find(bson.M{"useremail": u.Email}).One(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Client, check.Equals, "tsuru-client/1.6.0")
+	c.Assert(result.IP, check.Equals, "10.0.0.1")
+}
+
 func (s *S) TestCreateTokenRemoveOldTokens(c *check.C) {
 	config.Set("auth:max-simultaneous-sessions", 2)
 	u := auth.User{Email: "para@xmen.com", Password: "123456"}
@@ -223,7 +237,7 @@ func (s *S) TestCreateTokenRemoveOldTokens(c *check.C) {
 	t2.Token += "aa"
 	err = s.conn.Tokens().Insert(t1, t2)
 	c.Assert(err, check.IsNil)
-	_, err = createToken(&u, "123456")
+	_, err = createToken(&u, "123456", "", "")
 	c.Assert(err, check.IsNil)
 	ok := make(chan bool, 1)
 	go func() {
@@ -254,13 +268,13 @@ func (s *S) TestCreateTokenUsesDefaultCostWhenHasCostIsUndefined(c *check.C) {
 	defer u.Delete()
 	cost = 0
 	tokenExpire = 0
-	_, err = createToken(&u, "123456")
+	_, err = createToken(&u, "123456", "", "")
 	c.Assert(err, check.IsNil)
 }
 
 func (s *S) TestCreateTokenShouldReturnErrorIfTheProvidedUserDoesNotHaveEmailDefined(c *check.C) {
 	u := auth.User{Password: "123"}
-	_, err := createToken(&u, "123")
+	_, err := createToken(&u, "123", "", "")
 	c.Assert(err, check.NotNil)
 	c.Assert(err, check.ErrorMatches, "^User does not have an email$")
 }
@@ -270,7 +284,7 @@ func (s *S) TestCreateTokenShouldValidateThePassword(c *check.C) {
 	_, err := nativeScheme.Create(&u)
 	c.Assert(err, check.IsNil)
 	defer u.Delete()
-	_, err = createToken(&u, "123")
+	_, err = createToken(&u, "123", "", "")
 	c.Assert(err, check.NotNil)
 }
 
@@ -280,6 +294,16 @@ func (s *S) TestGetToken(c *check.C) {
 	c.Assert(t.Token, check.Equals, s.token.GetValue())
 }
 
+func (s *S) TestGetTokenRecordsLastUse(c *check.C) {
+	before := time.Now().Add(-time.Second)
+	_, err := getToken("bearer " + s.token.GetValue())
+	c.Assert(err, check.IsNil)
+	var result Token
+	err = s.conn.Tokens().Find(bson.M{"token": s.token.GetValue()}).One(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.LastUse.After(before), check.Equals, true)
+}
+
 func (s *S) TestGetTokenEmptyToken(c *check.C) {
 	u, err := getToken("bearer tokenthatdoesnotexist")
 	c.Assert(u, check.IsNil)
@@ -153,7 +153,7 @@ func (s *OAuthScheme) handleToken(t *oauth2.Token) (*Token, error) {
 	if email == "" {
 		return nil, ErrEmptyUserEmail
 	}
-	_, err = auth.GetUserByEmail(email)
+	user, err := auth.GetUserByEmail(email)
 	if err != nil {
 		if err != authTypes.ErrUserNotFound {
 			return nil, err
@@ -162,12 +162,15 @@ func (s *OAuthScheme) handleToken(t *oauth2.Token) (*Token, error) {
 		if !registrationEnabled {
 			return nil, err
 		}
-		user := &auth.User{Email: email}
+		user = &auth.User{Email: email}
 		err = user.Create()
 		if err != nil {
 			return nil, err
 		}
 	}
+	if user.Disabled {
+		return nil, auth.ErrUserDisabled
+	}
 	token := Token{*t, email}
 	err = token.save()
 	if err != nil {
@@ -256,6 +259,11 @@ func (s *OAuthScheme) Create(user *auth.User) (*auth.User, error) {
 	return user, nil
 }
 
+// RevokeUserSessions removes the OAuth tokens of the user.
+func (s *OAuthScheme) RevokeUserSessions(email string) error {
+	return deleteAllTokens(email)
+}
+
 func (s *OAuthScheme) Remove(u *auth.User) error {
 	err := deleteAllTokens(u.Email)
 	if err != nil {
@@ -60,6 +60,11 @@ func getToken(header string) (*Token, error) {
 		}
 		return nil, err
 	}
+	// Tokens of disabled users are rejected even if revoking them failed.
+	u, err := t.User()
+	if err == nil && u.Disabled {
+		return nil, auth.ErrInvalidToken
+	}
 	return &t, nil
 }
 
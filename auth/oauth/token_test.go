@@ -68,3 +68,30 @@ func (s *S) TestDelete(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(tokens, check.HasLen, 0)
 }
+
+func (s *S) TestGetTokenDisabledUser(c *check.C) {
+	u := auth.User{Email: "x@x.com", Disabled: true}
+	err := u.Create()
+	c.Assert(err, check.IsNil)
+	existing := Token{Token: oauth2.Token{AccessToken: "myvalidtoken"}, UserEmail: u.Email}
+	err = existing.save()
+	c.Assert(err, check.IsNil)
+	t, err := getToken("bearer myvalidtoken")
+	c.Assert(t, check.IsNil)
+	c.Assert(err, check.Equals, auth.ErrInvalidToken)
+}
+
+func (s *S) TestRevokeSessionsRemovesTokens(c *check.C) {
+	existing := Token{Token: oauth2.Token{AccessToken: "myvalidtoken"}, UserEmail: "x@x.com"}
+	err := existing.save()
+	c.Assert(err, check.IsNil)
+	other := Token{Token: oauth2.Token{AccessToken: "othertoken"}, UserEmail: "y@y.com"}
+	err = other.save()
+	c.Assert(err, check.IsNil)
+	err = auth.RevokeSessions("x@x.com")
+	c.Assert(err, check.IsNil)
+	_, err = getToken("bearer myvalidtoken")
+	c.Assert(err, check.Equals, auth.ErrInvalidToken)
+	_, err = getToken("bearer othertoken")
+	c.Assert(err, check.IsNil)
+}
@@ -174,7 +174,11 @@ func (s *SAMLAuthScheme) Login(params map[string]string) (auth.Token, error) {
 			return nil, err
 		}
 	}
-	token, err := createToken(user)
+	if user.Disabled {
+		return nil, auth.ErrUserDisabled
+	}
+	client, ip := auth.SessionInfoFromParams(params)
+	token, err := createToken(user, client, ip)
 	if err != nil {
 		return nil, err
 	}
@@ -443,7 +443,7 @@ func (s *S) TestSamlAuthInfo(c *check.C) {
 
 func (s *S) TestSamlAuth(c *check.C) {
 	user := auth.User{Email: "x@x.com"}
-	token, _ := createToken(&user)
+	token, _ := createToken(&user, "", "")
 	scheme := SAMLAuthScheme{}
 	strtoken, err := scheme.Auth("bearer " + token.GetValue())
 	c.Assert(err, check.IsNil)
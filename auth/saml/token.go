@@ -10,13 +10,12 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/auth"
-	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/storage"
+	authTypes "github.com/tsuru/tsuru/types/auth"
 )
 
 const (
@@ -32,6 +31,9 @@ type Token struct {
 	Expires   time.Duration `json:"expires"`
 	UserEmail string        `json:"email"`
 	AppName   string        `json:"app"`
+	Client    string        `json:"client,omitempty"`
+	IP        string        `json:"ip,omitempty"`
+	LastUse   time.Time     `json:"lastUse"`
 }
 
 func (t *Token) GetValue() string {
@@ -103,94 +105,88 @@ func newUserToken(u *auth.User) (*Token, error) {
 }
 
 func removeOldTokens(userEmail string) error {
-	conn, err := db.Conn()
+	limit, err := config.GetInt("auth:max-simultaneous-sessions")
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	var limit int
-	if limit, err = config.GetInt("auth:max-simultaneous-sessions"); err != nil {
+	tokenStorage, err := storage.GetTokenStorage()
+	if err != nil {
 		return err
 	}
-	count, err := conn.Tokens().Find(bson.M{"useremail": userEmail}).Count()
+	tokens, err := tokenStorage.FindByUserEmail(userEmail)
 	if err != nil {
 		return err
 	}
-	diff := count - limit
+	diff := len(tokens) - limit
 	if diff < 1 {
 		return nil
 	}
-	var tokens []map[string]interface{}
-	err = conn.Tokens().Find(bson.M{"useremail": userEmail}).
-		Select(bson.M{"_id": 1}).Sort("creation").Limit(diff).All(&tokens)
-	if err != nil {
-		return nil
-	}
-	ids := make([]interface{}, 0, len(tokens))
-	for _, token := range tokens {
-		ids = append(ids, token["_id"])
+	for _, t := range tokens[:diff] {
+		err = tokenStorage.Delete(t)
+		if err != nil && err != authTypes.ErrTokenNotFound {
+			return err
+		}
 	}
-	_, err = conn.Tokens().RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
-	return err
+	return nil
 }
 
-func createToken(u *auth.User) (*Token, error) {
+func createToken(u *auth.User, client, ip string) (*Token, error) {
 	if u.Email == "" {
 		return nil, errors.New("User does not have an email")
 	}
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 	token, err := newUserToken(u)
 	if err != nil {
 		return nil, err
 	}
-	err = conn.Tokens().Insert(token)
+	token.Client = client
+	token.IP = ip
+	err = tokenStorage.Insert(authTypes.Token(*token))
 	go removeOldTokens(u.Email)
 	return token, err
 }
 
 func getToken(header string) (*Token, error) {
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
-	var t Token
 	token, err := auth.ParseToken(header)
 	if err != nil {
 		return nil, err
 	}
-	err = conn.Tokens().Find(bson.M{"token": token}).One(&t)
+	stored, err := tokenStorage.FindByToken(token)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == authTypes.ErrTokenNotFound {
 			return nil, auth.ErrInvalidToken
 		}
 		return nil, err
 	}
+	t := Token(*stored)
 	if t.Expires > 0 && time.Until(t.Creation.Add(t.Expires)) < 1 {
 		return nil, auth.ErrInvalidToken
 	}
+	if t.UserEmail != "" {
+		auth.TouchSession(*stored)
+	}
 	return &t, nil
 }
 
 func deleteToken(token string) error {
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	return conn.Tokens().Remove(bson.M{"token": token})
+	return tokenStorage.Delete(authTypes.Token{Token: token})
 }
 
 func deleteAllTokens(email string) error {
-	conn, err := db.Conn()
+	tokenStorage, err := storage.GetTokenStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	_, err = conn.Tokens().RemoveAll(bson.M{"useremail": email})
-	return err
+	return tokenStorage.DeleteByUserEmail(email)
 }
@@ -12,7 +12,7 @@ import (
 
 func (s *S) TestGetToken(c *check.C) {
 	user := &auth.User{Email: "x@x.com"}
-	token, err := createToken(user)
+	token, err := createToken(user, "", "")
 	c.Assert(err, check.IsNil)
 	count, err := s.conn.Tokens().Find(bson.M{"useremail": "x@x.com"}).Count()
 	c.Assert(err, check.IsNil)
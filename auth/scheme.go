@@ -4,7 +4,12 @@
 
 package auth
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 type SchemeInfo map[string]interface{}
 
@@ -27,6 +32,56 @@ type ManagedScheme interface {
 	ChangePassword(token Token, oldPassword string, newPassword string) error
 }
 
+// PasswordPolicy describes the rules passwords must follow. History is the
+// number of previous passwords that can't be reused and MaxAge the number of
+// days after which passwords expire, zero meaning they never do.
+type PasswordPolicy struct {
+	MinLength        int  `json:"minLength"`
+	MaxLength        int  `json:"maxLength"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSymbol    bool `json:"requireSymbol"`
+	History          int  `json:"history"`
+	MaxAge           int  `json:"maxAge"`
+}
+
+// PasswordPolicyScheme is implemented by managed schemes enforcing a password
+// policy.
+type PasswordPolicyScheme interface {
+	ManagedScheme
+	PasswordPolicy() PasswordPolicy
+}
+
+// SessionStoreScheme is implemented by schemes keeping their tokens out of
+// the token storage, allowing the sessions of users to be revoked.
+type SessionStoreScheme interface {
+	Scheme
+	RevokeUserSessions(email string) error
+}
+
+// LockoutScheme is implemented by schemes that block logins after repeated
+// failures, allowing admins to lift the blocks.
+type LockoutScheme interface {
+	Scheme
+	UnlockUser(email string) error
+	UnlockIP(ip string) error
+}
+
+// LoginLockedError is returned by schemes when logins are blocked after too
+// many failures.
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e LoginLockedError) Error() string {
+	wait := time.Until(e.Until).Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return fmt.Sprintf("Too many failed login attempts, try again in %v.", wait)
+}
+
 type AuthenticationFailure struct {
 	Message string
 }
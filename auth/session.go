@@ -0,0 +1,158 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/storage"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+// Login params set by the API with the details of the login request, used by
+// schemes to fill the client and the IP of the sessions they open.
+const (
+	SessionClientParam = "session-client"
+	SessionIPParam     = "session-ip"
+)
+
+// lastUseInterval is how often the last use of a session is recorded, so
+// requests don't write to the token storage every time they authenticate.
+const lastUseInterval = time.Minute
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is an active login session of a user. Sessions are identified by a
+// hash of their tokens, so listing them never exposes the tokens.
+type Session struct {
+	ID       string    `json:"id"`
+	Client   string    `json:"client"`
+	IP       string    `json:"ip"`
+	Creation time.Time `json:"creation"`
+	Expires  time.Time `json:"expires"`
+	LastUse  time.Time `json:"lastUse"`
+	Current  bool      `json:"current"`
+}
+
+// SessionID returns the ID of the session of the given token.
+func SessionID(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))[:32]
+}
+
+// SessionInfoFromParams returns the client and the IP set in the login params
+// by the API.
+func SessionInfoFromParams(params map[string]string) (client, ip string) {
+	return params[SessionClientParam], params[SessionIPParam]
+}
+
+// TouchSession records the last use of the session of the token, at most once
+// every minute. Failures are only logged, as they must not fail requests.
+func TouchSession(t authTypes.Token) {
+	now := time.Now()
+	if now.Sub(t.LastUse) < lastUseInterval {
+		return
+	}
+	tokenStorage, err := storage.GetTokenStorage()
+	if err == nil {
+		err = tokenStorage.UpdateLastUse(t.Token, now)
+	}
+	if err != nil && err != authTypes.ErrTokenNotFound {
+		log.Errorf("[sessions] unable to record last use of session of user %q: %v", t.UserEmail, err)
+	}
+}
+
+// ListSessions returns the active sessions of the user, sorted by creation.
+// The session of the current token is flagged as current.
+func ListSessions(email, current string) ([]Session, error) {
+	tokens, err := activeTokens(email)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, len(tokens))
+	for i, t := range tokens {
+		sessions[i] = Session{
+			ID:       SessionID(t.Token),
+			Client:   t.Client,
+			IP:       t.IP,
+			Creation: t.Creation,
+			LastUse:  t.LastUse,
+			Current:  t.Token == current,
+		}
+		if t.Expires > 0 {
+			sessions[i].Expires = t.Creation.Add(t.Expires)
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession removes the session of the user with the given ID, returning
+// ErrSessionNotFound if there's no such active session.
+func RevokeSession(email, id string) error {
+	tokens, err := activeTokens(email)
+	if err != nil {
+		return err
+	}
+	tokenStorage, err := storage.GetTokenStorage()
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		if SessionID(t.Token) != id {
+			continue
+		}
+		err = tokenStorage.Delete(t)
+		if err == authTypes.ErrTokenNotFound {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return ErrSessionNotFound
+}
+
+// RevokeSessions removes all the sessions of the user, including the ones
+// kept by registered schemes outside the token storage, so sessions opened
+// before a change of scheme are revoked too.
+func RevokeSessions(email string) error {
+	tokenStorage, err := storage.GetTokenStorage()
+	if err != nil {
+		return err
+	}
+	err = tokenStorage.DeleteByUserEmail(email)
+	if err != nil {
+		return err
+	}
+	for name, scheme := range schemes {
+		if storeScheme, ok := scheme.(SessionStoreScheme); ok {
+			err = storeScheme.RevokeUserSessions(email)
+			if err != nil {
+				return errors.Wrapf(err, "unable to revoke sessions of scheme %s", name)
+			}
+		}
+	}
+	return nil
+}
+
+func activeTokens(email string) ([]authTypes.Token, error) {
+	tokenStorage, err := storage.GetTokenStorage()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenStorage.FindByUserEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	active := tokens[:0]
+	for _, t := range tokens {
+		if t.Expires > 0 && time.Until(t.Creation.Add(t.Expires)) < 1 {
+			continue
+		}
+		active = append(active, t)
+	}
+	return active, nil
+}
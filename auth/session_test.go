@@ -0,0 +1,115 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/storage"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) insertTokens(c *check.C, tokens ...authTypes.Token) {
+	tokenStorage, err := storage.GetTokenStorage()
+	c.Assert(err, check.IsNil)
+	for _, t := range tokens {
+		err = tokenStorage.Insert(t)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *S) TestSessionID(c *check.C) {
+	id := SessionID("mytoken")
+	c.Assert(id, check.HasLen, 32)
+	c.Assert(id, check.Equals, SessionID("mytoken"))
+	c.Assert(id, check.Not(check.Equals), SessionID("othertoken"))
+}
+
+func (s *S) TestSessionInfoFromParams(c *check.C) {
+	client, ip := SessionInfoFromParams(map[string]string{
+		"email":            "me@example.com",
+		SessionClientParam: "tsuru-client/1.6.0",
+		SessionIPParam:     "10.0.0.1",
+	})
+	c.Assert(client, check.Equals, "tsuru-client/1.6.0")
+	c.Assert(ip, check.Equals, "10.0.0.1")
+}
+
+func (s *S) TestListSessions(c *check.C) {
+	now := time.Now().Truncate(time.Second)
+	s.insertTokens(c,
+		authTypes.Token{Token: "t1", Creation: now.Add(-time.Minute), Expires: time.Hour, UserEmail: s.user.Email, Client: "tsuru-client", IP: "10.0.0.1"},
+		authTypes.Token{Token: "t2", Creation: now, Expires: time.Hour, UserEmail: s.user.Email, LastUse: now},
+		authTypes.Token{Token: "expired", Creation: now.Add(-2 * time.Hour), Expires: time.Hour, UserEmail: s.user.Email},
+		authTypes.Token{Token: "other", Creation: now, Expires: time.Hour, UserEmail: "other@example.com"},
+	)
+	sessions, err := ListSessions(s.user.Email, "t2")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 2)
+	c.Assert(sessions[0].ID, check.Equals, SessionID("t1"))
+	c.Assert(sessions[0].Client, check.Equals, "tsuru-client")
+	c.Assert(sessions[0].IP, check.Equals, "10.0.0.1")
+	c.Assert(sessions[0].Expires.Equal(now.Add(59*time.Minute)), check.Equals, true)
+	c.Assert(sessions[0].Current, check.Equals, false)
+	c.Assert(sessions[1].ID, check.Equals, SessionID("t2"))
+	c.Assert(sessions[1].LastUse.Equal(now), check.Equals, true)
+	c.Assert(sessions[1].Current, check.Equals, true)
+}
+
+func (s *S) TestRevokeSession(c *check.C) {
+	s.insertTokens(c,
+		authTypes.Token{Token: "t1", Creation: time.Now(), Expires: time.Hour, UserEmail: s.user.Email},
+		authTypes.Token{Token: "t2", Creation: time.Now(), Expires: time.Hour, UserEmail: s.user.Email},
+	)
+	err := RevokeSession(s.user.Email, SessionID("t1"))
+	c.Assert(err, check.IsNil)
+	sessions, err := ListSessions(s.user.Email, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 1)
+	c.Assert(sessions[0].ID, check.Equals, SessionID("t2"))
+	err = RevokeSession(s.user.Email, SessionID("t1"))
+	c.Assert(err, check.Equals, ErrSessionNotFound)
+}
+
+func (s *S) TestRevokeSessionOfOtherUser(c *check.C) {
+	s.insertTokens(c, authTypes.Token{Token: "other", Creation: time.Now(), Expires: time.Hour, UserEmail: "other@example.com"})
+	err := RevokeSession(s.user.Email, SessionID("other"))
+	c.Assert(err, check.Equals, ErrSessionNotFound)
+	sessions, err := ListSessions("other@example.com", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 1)
+}
+
+func (s *S) TestRevokeSessions(c *check.C) {
+	s.insertTokens(c,
+		authTypes.Token{Token: "t1", Creation: time.Now(), Expires: time.Hour, UserEmail: s.user.Email},
+		authTypes.Token{Token: "t2", Creation: time.Now(), Expires: time.Hour, UserEmail: s.user.Email},
+		authTypes.Token{Token: "other", Creation: time.Now(), Expires: time.Hour, UserEmail: "other@example.com"},
+	)
+	err := RevokeSessions(s.user.Email)
+	c.Assert(err, check.IsNil)
+	sessions, err := ListSessions(s.user.Email, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 0)
+	sessions, err = ListSessions("other@example.com", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions, check.HasLen, 1)
+}
+
+func (s *S) TestTouchSession(c *check.C) {
+	recent := time.Now().Add(-10 * time.Second).Truncate(time.Second)
+	t := authTypes.Token{Token: "t1", Creation: time.Now(), Expires: time.Hour, UserEmail: s.user.Email, LastUse: recent}
+	s.insertTokens(c, t)
+	TouchSession(t)
+	sessions, err := ListSessions(s.user.Email, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions[0].LastUse.Equal(recent), check.Equals, true)
+	t.LastUse = time.Now().Add(-time.Hour)
+	TouchSession(t)
+	sessions, err = ListSessions(s.user.Email, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(sessions[0].LastUse.After(recent), check.Equals, true)
+}
@@ -30,8 +30,19 @@ type User struct {
 	Password string
 	APIKey   string
 	Roles    []authTypes.RoleInstance `bson:",omitempty"`
+
+	// PasswordChangedAt and PasswordHistory are kept by schemes enforcing
+	// password expiration and reuse rules. The history holds the hashes of
+	// previous passwords, newest first.
+	PasswordChangedAt time.Time `bson:",omitempty"`
+	PasswordHistory   []string  `bson:",omitempty"`
+
+	// Disabled users are kept, along with their roles, but can't log in.
+	Disabled bool `bson:",omitempty"`
 }
 
+var ErrUserDisabled = &tsuruErrors.NotAuthorizedError{Message: "user is disabled"}
+
 func listUsers(filter bson.M) ([]User, error) {
 	conn, err := db.Conn()
 	if err != nil {
@@ -16,6 +16,8 @@ import (
 
 var DefaultBuilder = "docker"
 
+var ErrBuildSecretsNotSupported = errors.New("build secrets not supported by this provisioner")
+
 type BuildOpts struct {
 	BuildFromFile       bool
 	Rebuild             bool
@@ -27,6 +29,9 @@ type BuildOpts struct {
 	ArchiveSize         int64
 	ImageID             string
 	Tag                 string
+	// Secrets are available only to the commands building the image, they
+	// must never be stored in the image or in the app.
+	Secrets map[string]string
 }
 
 // Builder is the basic interface of this package.
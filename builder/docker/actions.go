@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"time"
@@ -35,6 +36,7 @@ type runContainerActionsArgs struct {
 	exposedPort   string
 	event         *event.Event
 	tarFile       io.Reader
+	secretsFile   []byte
 }
 
 func checkCanceled(evt *event.Event) error {
@@ -116,6 +118,16 @@ var uploadToContainer = action.Action{
 			log.Errorf("error on upload tarfile to container %s - %s", c.ID, err)
 			return nil, err
 		}
+		if args.secretsFile != nil {
+			err = args.client.UploadToContainer(c.ID, docker.UploadToContainerOptions{
+				InputStream: bytes.NewReader(args.secretsFile),
+				Path:        buildSecretsDirPath,
+			})
+			if err != nil {
+				log.Errorf("error on upload build secrets to container %s - %s", c.ID, err)
+				return nil, err
+			}
+		}
 		return c, nil
 	},
 	Backward: func(ctx action.BWContext) {
@@ -72,6 +72,17 @@ func (b *dockerBuilder) Build(prov provision.BuilderDeploy, app provision.App, e
 	if err != nil {
 		return "", err
 	}
+	if slotClient, ok := client.(provision.BuildSlotDockerClient); ok {
+		var w io.Writer = evt
+		if evt == nil {
+			w = ioutil.Discard
+		}
+		release, err := slotClient.AcquireBuildSlot(w)
+		if err != nil {
+			return "", err
+		}
+		defer release()
+	}
 	var tarFile io.ReadCloser
 	if opts.ArchiveFile != nil && opts.ArchiveSize != 0 {
 		tarFile = dockercommon.AddDeployTarFile(opts.ArchiveFile, opts.ArchiveSize, defaultArchiveName)
@@ -93,7 +104,7 @@ func (b *dockerBuilder) Build(prov provision.BuilderDeploy, app provision.App, e
 		return "", errors.New("no valid files found")
 	}
 	defer tarFile.Close()
-	imageID, err := b.buildPipeline(p, client, app, tarFile, evt, opts.Tag)
+	imageID, err := b.buildPipeline(p, client, app, tarFile, evt, opts.Tag, opts.Secrets)
 	if err != nil {
 		return "", err
 	}
@@ -174,6 +185,7 @@ func pushImageToRegistry(client provision.BuilderDockerClient, app provision.App
 	if err != nil {
 		return "", err
 	}
+	provision.StartDeployPhase(evt, provision.DeployPhaseImagePush)
 	fmt.Fprintf(evt, "---- Pushing image %q to tsuru ----\n", newImage)
 	pushOpts := docker.PushImageOptions{
 		Name:              repo,
@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"archive/tar"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -109,6 +110,71 @@ func (s *S) TestBuilderArchiveFile(c *check.C) {
 	c.Assert(imgID, check.Equals, s.team.Name+"/app-myapp:v1-builder")
 }
 
+func (s *S) TestBuilderArchiveFileWithSecrets(c *check.C) {
+	stopCh := s.stopContainers(s.server.URL(), 1)
+	defer func() { <-stopCh }()
+	opts := provision.AddNodeOptions{Address: s.server.URL()}
+	err := s.provisioner.AddNode(opts)
+	c.Assert(err, check.IsNil)
+	a := &app.App{Name: "myapp", Platform: "whitespace", TeamOwner: s.team.Name}
+	err = app.CreateApp(a, s.user)
+	c.Assert(err, check.IsNil)
+	var (
+		uploadPaths []string
+		createdCfg  docker.Config
+	)
+	s.server.CustomHandler("/containers/.*/archive", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			uploadPaths = append(uploadPaths, r.URL.Query().Get("path"))
+		}
+		s.server.DefaultHandler().ServeHTTP(w, r)
+	}))
+	s.server.CustomHandler("/containers/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &createdCfg)
+		r.Body = ioutil.NopCloser(bytes.NewReader(data))
+		s.server.DefaultHandler().ServeHTTP(w, r)
+	}))
+	evt, err := event.New(&event.Opts{
+		Target:  event.Target{Type: event.TargetTypeApp, Value: a.GetName()},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+	buf := strings.NewReader("my upload data")
+	bopts := builder.BuildOpts{
+		ArchiveFile: ioutil.NopCloser(buf),
+		ArchiveSize: int64(buf.Len()),
+		Secrets:     map[string]string{"NPM_TOKEN": "supersecret"},
+	}
+	imgID, err := s.b.Build(s.provisioner, a, evt, &bopts)
+	c.Assert(err, check.IsNil)
+	c.Assert(imgID, check.Equals, s.team.Name+"/app-myapp:v1-builder")
+	c.Assert(uploadPaths, check.DeepEquals, []string{"/home/application", "/tmp"})
+	c.Assert(createdCfg.Cmd, check.HasLen, 3)
+	c.Assert(createdCfg.Cmd[2], check.Matches, `set -a && \. /tmp/tsuru-build-secrets/env && set \+a && rm -f /tmp/tsuru-build-secrets/env && tsuru_unit_agent .*`)
+	for _, env := range createdCfg.Env {
+		c.Assert(env, check.Not(check.Matches), ".*supersecret.*")
+	}
+}
+
+func (s *S) TestBuildSecretsTarFile(c *check.C) {
+	data, err := buildSecretsTarFile(map[string]string{"B": "it's", "A": "1"})
+	c.Assert(err, check.IsNil)
+	tr := tar.NewReader(bytes.NewReader(data))
+	header, err := tr.Next()
+	c.Assert(err, check.IsNil)
+	c.Assert(header.Name, check.Equals, "tsuru-build-secrets/")
+	c.Assert(header.Typeflag, check.Equals, byte(tar.TypeDir))
+	header, err = tr.Next()
+	c.Assert(err, check.IsNil)
+	c.Assert(header.Name, check.Equals, "tsuru-build-secrets/env")
+	content, err := ioutil.ReadAll(tr)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(content), check.Equals, "A='1'\nB='it'\\''s'\n")
+}
+
 func (s *S) TestBuilderImageID(c *check.C) {
 	opts := provision.AddNodeOptions{Address: s.server.URL()}
 	err := s.provisioner.AddNode(opts)
@@ -5,11 +5,16 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"crypto"
 	"crypto/rand"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -25,9 +30,13 @@ import (
 const (
 	archiveDirPath  = "/home/application"
 	archiveFileName = "archive.tar.gz"
+
+	buildSecretsDirPath  = "/tmp"
+	buildSecretsDirName  = "tsuru-build-secrets"
+	buildSecretsFileName = "env"
 )
 
-func (b *dockerBuilder) buildPipeline(p provision.BuilderDeployDockerClient, client provision.BuilderDockerClient, app provision.App, tarFile io.Reader, evt *event.Event, imageTag string) (string, error) {
+func (b *dockerBuilder) buildPipeline(p provision.BuilderDeployDockerClient, client provision.BuilderDockerClient, app provision.App, tarFile io.Reader, evt *event.Event, imageTag string, secrets map[string]string) (string, error) {
 	actions := []*action.Action{
 		&createContainer,
 		&uploadToContainer,
@@ -43,6 +52,14 @@ func (b *dockerBuilder) buildPipeline(p provision.BuilderDeployDockerClient, cli
 	}
 	archiveFileURI := fmt.Sprintf("file://%s/%s", archiveDirPath, archiveFileName)
 	cmds := dockercommon.ArchiveBuildCmds(app, archiveFileURI)
+	var secretsFile []byte
+	if len(secrets) > 0 {
+		secretsFile, err = buildSecretsTarFile(secrets)
+		if err != nil {
+			return "", err
+		}
+		cmds = buildSecretsCmds(cmds)
+	}
 	var writer io.Writer = evt
 	if evt == nil {
 		writer = ioutil.Discard
@@ -57,6 +74,7 @@ func (b *dockerBuilder) buildPipeline(p provision.BuilderDeployDockerClient, cli
 		event:         evt,
 		provisioner:   p,
 		tarFile:       tarFile,
+		secretsFile:   secretsFile,
 		isDeploy:      true,
 	}
 	err = container.RunPipelineWithRetry(pipeline, args)
@@ -73,3 +91,59 @@ func randomString() string {
 	io.CopyN(h, rand.Reader, 10)
 	return fmt.Sprintf("%x", h.Sum(nil))[:20]
 }
+
+// buildSecretsTarFile returns a tar file with a shell script exporting the
+// build secrets, to be uploaded to the build container. The directory is
+// writable by everyone, so the file can be removed by the user running the
+// build.
+func buildSecretsTarFile(secrets map[string]string) ([]byte, error) {
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var script bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&script, "%s='%s'\n", name, strings.Replace(secrets[name], "'", `'\''`, -1))
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	err := tw.WriteHeader(&tar.Header{
+		Name:     buildSecretsDirName + "/",
+		Mode:     0777,
+		Typeflag: tar.TypeDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:     buildSecretsDirName + "/" + buildSecretsFileName,
+		Mode:     0644,
+		Size:     int64(script.Len()),
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = tw.Write(script.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// buildSecretsCmds changes the build commands to export the build secrets
+// and remove their file before building, so they're neither part of the
+// container config nor of the committed image.
+func buildSecretsCmds(cmds []string) []string {
+	secretsFile := path.Join(buildSecretsDirPath, buildSecretsDirName, buildSecretsFileName)
+	result := make([]string, len(cmds))
+	copy(result, cmds)
+	last := len(result) - 1
+	result[last] = fmt.Sprintf("set -a && . %[1]s && set +a && rm -f %[1]s && %[2]s", secretsFile, result[last])
+	return result
+}
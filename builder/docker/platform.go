@@ -11,6 +11,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/url"
+	"sort"
 
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/tsuru/tsuru/app/image"
@@ -28,18 +29,18 @@ import (
 var _ builder.Builder = &dockerBuilder{}
 
 func (b *dockerBuilder) PlatformAdd(opts appTypes.PlatformOptions) error {
-	return b.buildPlatform(opts.Name, opts.Args, opts.Output, opts.Input)
+	return b.buildPlatform(opts)
 }
 
 func (b *dockerBuilder) PlatformUpdate(opts appTypes.PlatformOptions) error {
-	return b.buildPlatform(opts.Name, opts.Args, opts.Output, opts.Input)
+	return b.buildPlatform(opts)
 }
 
-func (b *dockerBuilder) buildPlatform(name string, args map[string]string, w io.Writer, r io.Reader) error {
+func (b *dockerBuilder) buildPlatform(opts appTypes.PlatformOptions) error {
 	var inputStream io.Reader
 	var dockerfileURL string
-	if r != nil {
-		data, err := ioutil.ReadAll(r)
+	if opts.Input != nil {
+		data, err := ioutil.ReadAll(opts.Input)
 		if err != nil {
 			return err
 		}
@@ -54,7 +55,7 @@ func (b *dockerBuilder) buildPlatform(name string, args map[string]string, w io.
 		writer.Close()
 		inputStream = &buf
 	} else {
-		dockerfileURL = args["dockerfile"]
+		dockerfileURL = opts.Args["dockerfile"]
 		if dockerfileURL == "" {
 			return errors.New("Dockerfile is required")
 		}
@@ -62,8 +63,8 @@ func (b *dockerBuilder) buildPlatform(name string, args map[string]string, w io.
 			return errors.New("Dockerfile parameter must be a URL")
 		}
 	}
-	imageName := image.PlatformImageName(name)
-	client, err := getDockerClient()
+	imageName := image.PlatformImageName(opts.Name)
+	client, err := getPlatformBuildClient()
 	if err != nil {
 		return err
 	}
@@ -75,9 +76,10 @@ func (b *dockerBuilder) buildPlatform(name string, args map[string]string, w io.
 		RmTmpContainer:    true,
 		Remote:            dockerfileURL,
 		InputStream:       inputStream,
-		OutputStream:      &tsuruIo.DockerErrorCheckWriter{W: w},
+		OutputStream:      &tsuruIo.DockerErrorCheckWriter{W: opts.Output},
 		InactivityTimeout: net.StreamInactivityTimeout,
 		RawJSONStream:     true,
+		BuildArgs:         platformBuildArgs(opts),
 	}
 	err = client.BuildImage(buildOptions)
 	if err != nil {
@@ -93,12 +95,64 @@ func (b *dockerBuilder) buildPlatform(name string, args map[string]string, w io.
 	}
 	err = client.PushImage(pushOpts, dockercommon.RegistryAuthConfig())
 	if err != nil {
-		log.Errorf("[docker] Failed to push image %q (%s): %s", name, err, buf.String())
+		log.Errorf("[docker] Failed to push image %q (%s): %s", opts.Name, err, buf.String())
 		return err
 	}
 	return nil
 }
 
+// platformBuildArgs returns the build args sent to docker, including the
+// proxy variables when the platform is built behind a proxy.
+func platformBuildArgs(opts appTypes.PlatformOptions) []docker.BuildArg {
+	args := make(map[string]string, len(opts.BuildArgs))
+	for k, v := range opts.BuildArgs {
+		args[k] = v
+	}
+	if opts.Proxy != "" {
+		for _, k := range []string{"http_proxy", "https_proxy", "HTTP_PROXY", "HTTPS_PROXY"} {
+			if _, ok := args[k]; !ok {
+				args[k] = opts.Proxy
+			}
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buildArgs := make([]docker.BuildArg, len(keys))
+	for i, k := range keys {
+		buildArgs[i] = docker.BuildArg{Name: k, Value: args[k]}
+	}
+	return buildArgs
+}
+
+// getPlatformBuildClient returns the client used to build platforms, which
+// runs builds in the nodes designated as platform builders when the
+// provisioner supports it.
+func getPlatformBuildClient() (provision.BuilderDockerClient, error) {
+	provisioners, err := provision.Registry()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range provisioners {
+		if provisioner, ok := p.(provision.PlatformBuilderDockerClient); ok {
+			client, err := provisioner.GetPlatformBuildClient()
+			if err != nil {
+				log.Errorf("[docker] unable to get platform build client: %s", err)
+				continue
+			}
+			if client != nil {
+				return client, nil
+			}
+		}
+	}
+	return getDockerClient()
+}
+
 func getDockerClient() (provision.BuilderDockerClient, error) {
 	provisioners, err := provision.Registry()
 	if err != nil {
@@ -6,6 +6,7 @@ package docker
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -78,6 +79,39 @@ func (s *S) TestPlatformAddData(c *check.C) {
 	c.Assert(requests[1].URL.Path, check.Equals, "/images/localhost:3030/tsuru/test/push")
 }
 
+func (s *S) TestPlatformAddWithBuildArgsAndProxy(c *check.C) {
+	var requests []*http.Request
+	server, err := testing.NewServer("127.0.0.1:0", nil, func(r *http.Request) {
+		requests = append(requests, r)
+	})
+	c.Assert(err, check.IsNil)
+	defer server.Stop()
+	err = s.provisioner.AddNode(provision.AddNodeOptions{Address: server.URL()})
+	c.Assert(err, check.IsNil)
+	var b dockerBuilder
+	err = b.PlatformAdd(appTypes.PlatformOptions{
+		Name:      "test",
+		Args:      map[string]string{"dockerfile": "http://localhost/Dockerfile"},
+		BuildArgs: map[string]string{"JAVA_VERSION": "8", "http_proxy": "http://other:3128"},
+		Proxy:     "http://proxy:3128",
+		Output:    ioutil.Discard,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(len(requests) >= 2, check.Equals, true)
+	requests = requests[len(requests)-2:]
+	c.Assert(requests[0].URL.Path, check.Equals, "/build")
+	var buildArgs map[string]string
+	err = json.Unmarshal([]byte(requests[0].URL.Query().Get("buildargs")), &buildArgs)
+	c.Assert(err, check.IsNil)
+	c.Assert(buildArgs, check.DeepEquals, map[string]string{
+		"JAVA_VERSION": "8",
+		"http_proxy":   "http://other:3128",
+		"https_proxy":  "http://proxy:3128",
+		"HTTP_PROXY":   "http://proxy:3128",
+		"HTTPS_PROXY":  "http://proxy:3128",
+	})
+}
+
 func (s *S) TestPlatformAddWithoutArgs(c *check.C) {
 	b := dockerBuilder{}
 	err := b.PlatformAdd(appTypes.PlatformOptions{Name: "test"})
@@ -41,6 +41,9 @@ func (b *kubernetesBuilder) Build(prov provision.BuilderDeploy, app provision.Ap
 	if opts.ImageID != "" {
 		return imageBuild(client, app, opts.ImageID, evt)
 	}
+	if len(opts.Secrets) > 0 {
+		return "", builder.ErrBuildSecretsNotSupported
+	}
 	imageID, err := client.BuildPod(app, evt, opts.ArchiveFile, opts.Tag)
 	if err != nil {
 		return "", err
@@ -60,6 +60,27 @@ func (s *S) TestArchiveFileWithTag(c *check.C) {
 	c.Assert(imgID, check.Equals, s.team.Name+"/app-myapp:mytag")
 }
 
+func (s *S) TestArchiveFileWithBuildSecrets(c *check.C) {
+	a, _, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	evt, err := event.New(&event.Opts{
+		Target:  event.Target{Type: event.TargetTypeApp, Value: a.GetName()},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+	buf := strings.NewReader("my upload data")
+	bopts := builder.BuildOpts{
+		ArchiveFile: ioutil.NopCloser(buf),
+		ArchiveSize: int64(buf.Len()),
+		Secrets:     map[string]string{"NPM_TOKEN": "secret"},
+	}
+	imgID, err := s.b.Build(s.p, a, evt, &bopts)
+	c.Assert(err, check.Equals, builder.ErrBuildSecretsNotSupported)
+	c.Assert(imgID, check.Equals, "")
+}
+
 func (s *S) TestArchiveURL(c *check.C) {
 	a, _, rollback := s.mock.DefaultReactions(c)
 	defer rollback()
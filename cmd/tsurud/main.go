@@ -21,6 +21,7 @@ import (
 	_ "github.com/tsuru/tsuru/provision/swarm"
 	_ "github.com/tsuru/tsuru/repository/gandalf"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
+	_ "github.com/tsuru/tsuru/storage/redis"
 )
 
 const defaultConfigPath = "/etc/tsuru/tsuru.conf"
@@ -0,0 +1,182 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cron implements parsing and evaluation of standard five fields cron
+// expressions (minute, hour, day of month, month and day of week).
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 6},
+}
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxLookahead limits how far in the future Next looks for a match, impossible
+// expressions like "0 0 31 2 *" would loop forever otherwise.
+const maxLookahead = 5 * 366 * 24 * time.Hour
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expr    string
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// Parse parses a cron expression with five space separated fields or one of
+// the predefined macros (@hourly, @daily, @weekly, @monthly and @yearly).
+func Parse(expr string) (*Schedule, error) {
+	normalized := strings.TrimSpace(expr)
+	if macro, ok := macros[normalized]; ok {
+		normalized = macro
+	}
+	parts := strings.Fields(normalized)
+	if len(parts) != len(fields) {
+		return nil, errors.Errorf("invalid cron expression %q: expected %d fields, got %d", expr, len(fields), len(parts))
+	}
+	s := &Schedule{expr: expr}
+	targets := []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, part := range parts {
+		bits, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+		}
+		*targets[i] = bits
+	}
+	s.domStar = parts[2] == "*" || strings.HasPrefix(parts[2], "*/")
+	s.dowStar = parts[4] == "*" || strings.HasPrefix(parts[4], "*/")
+	return s, nil
+}
+
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		step := 1
+		if idx := strings.Index(item, "/"); idx != -1 {
+			var err error
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %s field: %q", f.name, item)
+			}
+			item = item[:idx]
+		}
+		start, end := f.min, f.max
+		if item != "*" {
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, errors.Errorf("invalid value in %s field: %q", f.name, item)
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, errors.Errorf("invalid value in %s field: %q", f.name, item)
+				}
+			} else if step > 1 {
+				end = f.max
+			}
+		}
+		if f.name == "day of week" && end == 7 {
+			// Both 0 and 7 mean sunday.
+			bits |= 1
+			if start == 7 {
+				continue
+			}
+			end = 6
+		}
+		if start < f.min || end > f.max || start > end {
+			return 0, errors.Errorf("value out of range in %s field: %q", f.name, item)
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first time after t matching the schedule. A zero time is
+// returned if no matching time exists.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxLookahead)
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Prev returns the last time before or equal to t matching the schedule,
+// looking back at most until since. A zero time is returned if there is no
+// match in the interval.
+func (s *Schedule) Prev(t, since time.Time) time.Time {
+	var last time.Time
+	for next := s.Next(since); !next.IsZero() && !next.After(t); next = s.Next(next) {
+		last = next
+	}
+	return last
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
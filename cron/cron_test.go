@@ -0,0 +1,84 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func mustParse(c *check.C, expr string) *Schedule {
+	s, err := Parse(expr)
+	c.Assert(err, check.IsNil)
+	return s
+}
+
+func (s *S) TestParseInvalid(c *check.C) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"a * * * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt)
+		c.Check(err, check.NotNil, check.Commentf("expr: %q", tt))
+	}
+}
+
+func (s *S) TestNext(c *check.C) {
+	base := time.Date(2018, 3, 14, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2018, 3, 14, 10, 31, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2018, 3, 15, 8, 0, 0, 0, time.UTC)},
+		{"0 22 * * *", time.Date(2018, 3, 14, 22, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2018, 3, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2018, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2018, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2018, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * 6", time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2018, 3, 14, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		next := mustParse(c, tt.expr).Next(base)
+		c.Check(next, check.DeepEquals, tt.expected, check.Commentf("expr: %q", tt.expr))
+	}
+}
+
+func (s *S) TestNextImpossible(c *check.C) {
+	next := mustParse(c, "0 0 31 2 *").Next(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(next.IsZero(), check.Equals, true)
+}
+
+func (s *S) TestPrev(c *check.C) {
+	sched := mustParse(c, "0 8,22 * * *")
+	now := time.Date(2018, 3, 14, 23, 0, 0, 0, time.UTC)
+	prev := sched.Prev(now, now.Add(-24*time.Hour))
+	c.Assert(prev, check.DeepEquals, time.Date(2018, 3, 14, 22, 0, 0, 0, time.UTC))
+	prev = sched.Prev(now, now.Add(-30*time.Minute))
+	c.Assert(prev.IsZero(), check.Equals, true)
+}
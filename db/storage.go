@@ -88,11 +88,70 @@ func LogConn() (*LogStorage, error) {
 	return &strg, err
 }
 
+var readPreferences = map[string]mgo.Mode{
+	"primary":             mgo.Primary,
+	"primary-preferred":   mgo.PrimaryPreferred,
+	"secondary":           mgo.Secondary,
+	"secondary-preferred": mgo.SecondaryPreferred,
+	"nearest":             mgo.Nearest,
+}
+
+// ReadPreference returns the read preference used by the given endpoint,
+// read from database:read-preference:<endpoint>, falling back to
+// database:read-preference:default. Reads go to the primary when neither is
+// set.
+func ReadPreference(endpoint string) (mgo.Mode, error) {
+	pref, _ := config.GetString("database:read-preference:" + endpoint)
+	if pref == "" {
+		pref, _ = config.GetString("database:read-preference:default")
+	}
+	if pref == "" {
+		return mgo.Primary, nil
+	}
+	mode, ok := readPreferences[pref]
+	if !ok {
+		return mgo.Primary, fmt.Errorf("invalid read preference %q for %s", pref, endpoint)
+	}
+	return mode, nil
+}
+
+// ReadConn returns a connection for the read-only queries of the given
+// endpoint, using its read preference. It must not be used for writes, as
+// they fail when the preference directs the session to secondaries.
+func ReadConn(endpoint string) (*Storage, error) {
+	mode, err := ReadPreference(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := Conn()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMode(mode)
+	return conn, nil
+}
+
+// ReadLogConn is like ReadConn, for the logs database.
+func ReadLogConn(endpoint string) (*LogStorage, error) {
+	mode, err := ReadPreference(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := LogConn()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMode(mode)
+	return conn, nil
+}
+
 // Apps returns the apps collection from MongoDB.
 func (s *Storage) Apps() *storage.Collection {
 	nameIndex := mgo.Index{Key: []string{"name"}, Unique: true}
+	textIndex := mgo.Index{Key: []string{"$text:name", "$text:description", "$text:tags"}}
 	c := s.Collection("apps")
 	c.EnsureIndex(nameIndex)
+	c.EnsureIndex(textIndex)
 	return c
 }
 
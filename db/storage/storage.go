@@ -44,6 +44,14 @@ func (c *Collection) Create(info *mgo.CollectionInfo) error {
 		return nil
 	}
 	createdMap[c.Name] = struct{}{}
+	// Like mgo does for indexes, collections are always created in the
+	// primary, even by sessions reading from secondaries.
+	if session := c.Database.Session; session.Mode() != mgo.Strong {
+		cloned := session.Clone()
+		defer cloned.Close()
+		cloned.SetMode(mgo.Strong, false)
+		return c.Collection.With(cloned).Create(info)
+	}
 	return c.Collection.Create(info)
 }
 
@@ -80,6 +88,12 @@ func (s *Storage) Close() {
 	s.session.Close()
 }
 
+// SetMode changes the consistency mode of the session in the storage. It
+// doesn't affect other storages opened to the same address.
+func (s *Storage) SetMode(mode mgo.Mode) {
+	s.session.SetMode(mode, true)
+}
+
 // Collection returns a collection by its name.
 //
 // If the collection does not exist, MongoDB will create it.
@@ -79,3 +79,27 @@ func (s *S) TestCollection(c *check.C) {
 	collection := storage.Collection("users")
 	c.Assert(collection.FullName, check.Equals, storage.dbname+".users")
 }
+
+func (s *S) TestSetMode(c *check.C) {
+	storage, err := Open("127.0.0.1:27017", "tsuru_storage_test")
+	c.Assert(err, check.IsNil)
+	defer storage.Close()
+	storage.SetMode(mgo.SecondaryPreferred)
+	c.Assert(storage.session.Mode(), check.Equals, mgo.SecondaryPreferred)
+	storage2, err := Open("127.0.0.1:27017", "tsuru_storage_test")
+	c.Assert(err, check.IsNil)
+	defer storage2.Close()
+	c.Assert(storage2.session.Mode(), check.Equals, mgo.Strong)
+}
+
+func (s *S) TestCollectionCreateWithReadMode(c *check.C) {
+	storage, err := Open("127.0.0.1:27017", "tsuru_storage_test")
+	c.Assert(err, check.IsNil)
+	defer storage.Close()
+	storage.SetMode(mgo.PrimaryPreferred)
+	coll := storage.Collection("capped_read")
+	defer coll.DropCollection()
+	err = coll.Create(&mgo.CollectionInfo{Capped: true, MaxBytes: 4096})
+	c.Assert(err, check.IsNil)
+	c.Assert(storage.session.Mode(), check.Equals, mgo.PrimaryPreferred)
+}
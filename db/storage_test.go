@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/globalsign/mgo"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/db/storage"
 	"gopkg.in/check.v1"
@@ -185,3 +186,47 @@ func (s *S) TestInstallHosts(c *check.C) {
 	hostsc := strg.Collection("install_hosts")
 	c.Assert(hosts, check.DeepEquals, hostsc)
 }
+
+func (s *S) TestReadPreference(c *check.C) {
+	defer config.Unset("database:read-preference")
+	mode, err := ReadPreference("events")
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, mgo.Primary)
+	config.Set("database:read-preference:default", "secondary-preferred")
+	mode, err = ReadPreference("events")
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, mgo.SecondaryPreferred)
+	config.Set("database:read-preference:events", "nearest")
+	mode, err = ReadPreference("events")
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, mgo.Nearest)
+	mode, err = ReadPreference("apps")
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, mgo.SecondaryPreferred)
+	config.Set("database:read-preference:apps", "secondary-only")
+	_, err = ReadPreference("apps")
+	c.Assert(err, check.ErrorMatches, `invalid read preference "secondary-only" for apps`)
+}
+
+func (s *S) TestReadConn(c *check.C) {
+	config.Set("database:read-preference:apps", "primary-preferred")
+	defer config.Unset("database:read-preference")
+	strg, err := ReadConn("apps")
+	c.Assert(err, check.IsNil)
+	defer strg.Close()
+	c.Assert(strg.Apps().Database.Session.Mode(), check.Equals, mgo.PrimaryPreferred)
+	c.Assert(strg.Apps().Find(nil).All(&[]struct{}{}), check.IsNil)
+	other, err := Conn()
+	c.Assert(err, check.IsNil)
+	defer other.Close()
+	c.Assert(other.Apps().Database.Session.Mode(), check.Equals, mgo.Strong)
+}
+
+func (s *S) TestReadLogConn(c *check.C) {
+	config.Set("database:read-preference:logs", "nearest")
+	defer config.Unset("database:read-preference")
+	strg, err := ReadLogConn("logs")
+	c.Assert(err, check.IsNil)
+	defer strg.Close()
+	c.Assert(strg.Logs("myapp").Database.Session.Mode(), check.Equals, mgo.Nearest)
+}
@@ -100,6 +100,9 @@ var (
 	TargetTypeEventBlock      = TargetType("event-block")
 	TargetTypeCluster         = TargetType("cluster")
 	TargetTypeVolume          = TargetType("volume")
+	TargetTypeRetryOperation  = TargetType("retry-operation")
+	TargetTypeJob             = TargetType("job")
+	TargetTypeEnvGroup        = TargetType("env-group")
 )
 
 const (
@@ -192,6 +195,7 @@ type eventData struct {
 	StartCustomData bson.Raw      `bson:",omitempty"`
 	EndCustomData   bson.Raw      `bson:",omitempty"`
 	OtherCustomData bson.Raw      `bson:",omitempty"`
+	ErrorContext    bson.Raw      `bson:",omitempty"`
 	Kind            Kind
 	Owner           Owner
 	LockUpdateTime  time.Time
@@ -203,6 +207,10 @@ type eventData struct {
 	Running         bool
 	Allowed         AllowedPermission
 	AllowedCancel   AllowedPermission
+
+	// CustomDataVersion is the version of the custom data schema
+	// registered for the event kind when the event was created.
+	CustomDataVersion int `bson:",omitempty"`
 }
 
 type cancelInfo struct {
@@ -247,6 +255,9 @@ func GetTargetType(t string) (TargetType, error) {
 type Owner struct {
 	Type ownerType
 	Name string
+	// Impersonator is the user acting as the owner when the event was
+	// created with an impersonation token.
+	Impersonator string `bson:",omitempty" json:",omitempty"`
 }
 
 type Kind struct {
@@ -255,6 +266,9 @@ type Kind struct {
 }
 
 func (o Owner) String() string {
+	if o.Impersonator != "" {
+		return fmt.Sprintf("%s %s (impersonated by %s)", o.Type, o.Name, o.Impersonator)
+	}
 	return fmt.Sprintf("%s %s", o.Type, o.Name)
 }
 
@@ -408,6 +422,7 @@ type Filter struct {
 	KindNames      []string `form:"-"`
 	OwnerType      ownerType
 	OwnerName      string
+	Impersonator   string
 	Since          time.Time
 	Until          time.Time
 	Running        *bool
@@ -420,6 +435,10 @@ type Filter struct {
 	Limit int
 	Skip  int
 	Sort  string
+
+	// UseReadPreference lists the events with the read preference of the
+	// events endpoint, which may return stale results.
+	UseReadPreference bool `form:"-"`
 }
 
 func (f *Filter) PruneUserValues() {
@@ -517,6 +536,9 @@ func (f *Filter) toQuery() (bson.M, error) {
 	if f.OwnerName != "" {
 		query["owner.name"] = f.OwnerName
 	}
+	if f.Impersonator != "" {
+		query["owner.impersonator"] = f.Impersonator
+	}
 	var timeParts []bson.M
 	if !f.Since.IsZero() {
 		timeParts = append(timeParts, bson.M{"starttime": bson.M{"$gte": f.Since}})
@@ -635,7 +657,12 @@ func List(filter *Filter) ([]Event, error) {
 			return nil, err
 		}
 	}
-	conn, err := db.Conn()
+	var conn *db.Storage
+	if filter != nil && filter.UseReadPreference {
+		conn, err = db.ReadConn("events")
+	} else {
+		conn, err = db.Conn()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -832,6 +859,7 @@ func newEvt(opts *Opts) (evt *Event, err error) {
 	} else {
 		o.Type = OwnerTypeUser
 		o.Name = opts.Owner.GetUserName()
+		o.Impersonator = auth.ImpersonatorOf(opts.Owner)
 	}
 	conn, err := db.Conn()
 	if err != nil {
@@ -847,6 +875,11 @@ func newEvt(opts *Opts) (evt *Event, err error) {
 	if err != nil {
 		return nil, err
 	}
+	schema, _ := targetCustomDataSchema(k.Name, opts.Target)
+	err = validateCustomData(k.Name, "start", schema.Start, opts.CustomData)
+	if err != nil {
+		return nil, err
+	}
 	now := time.Now().UTC()
 	raw, err := makeBSONRaw(opts.CustomData)
 	if err != nil {
@@ -874,6 +907,7 @@ func newEvt(opts *Opts) (evt *Event, err error) {
 		Allowed:         opts.Allowed,
 		AllowedCancel:   opts.AllowedCancel,
 	}}
+	evt.CustomDataVersion = schema.Version
 	evt.Init()
 	maxRetries := 1
 	for i := 0; i < maxRetries+1; i++ {
@@ -996,6 +1030,11 @@ func (e *Event) GetLogWriter() io.Writer {
 }
 
 func (e *Event) SetOtherCustomData(data interface{}) error {
+	schema, _ := targetCustomDataSchema(e.Kind.Name, e.Target)
+	err := validateCustomData(e.Kind.Name, "other", schema.Other, data)
+	if err != nil {
+		return err
+	}
 	conn, err := db.Conn()
 	if err != nil {
 		return err
@@ -1007,6 +1046,20 @@ func (e *Event) SetOtherCustomData(data interface{}) error {
 	})
 }
 
+// SetOtherCustomDataField sets a single field of the other custom data of
+// the event, keeping the fields already set.
+func (e *Event) SetOtherCustomDataField(name string, value interface{}) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	coll := conn.Events()
+	return coll.UpdateId(e.ID, bson.M{
+		"$set": bson.M{"othercustomdata." + name: value},
+	})
+}
+
 func (e *Event) Logf(format string, params ...interface{}) {
 	log.Debugf(fmt.Sprintf("%s(%s)[%s] %s", e.Target.Type, e.Target.Value, e.Kind, format), params...)
 	format += "\n"
@@ -1130,6 +1183,39 @@ func (e *Event) OtherData(value interface{}) error {
 	return e.OtherCustomData.Unmarshal(value)
 }
 
+// ErrorContextFunc returns information about a target to help handling
+// failures, it's stored in events finishing with an error.
+type ErrorContextFunc func(target Target) (interface{}, error)
+
+var errorContextFuncs = map[TargetType]ErrorContextFunc{}
+
+// RegisterErrorContext registers the function used to build the error
+// context of failed events with targets of the given type.
+func RegisterErrorContext(targetType TargetType, fn ErrorContextFunc) {
+	errorContextFuncs[targetType] = fn
+}
+
+func (e *Event) ErrorContextData(value interface{}) error {
+	if e.ErrorContext.Kind == 0 {
+		return nil
+	}
+	return e.ErrorContext.Unmarshal(value)
+}
+
+func (e *Event) fillErrorContext() {
+	fn := errorContextFuncs[e.Target.Type]
+	if fn == nil {
+		return
+	}
+	data, err := fn(e.Target)
+	if err == nil {
+		e.ErrorContext, err = makeBSONRaw(data)
+	}
+	if err != nil {
+		log.Errorf("[events] unable to get error context for %s: %s", e.Target, err)
+	}
+}
+
 func (e *Event) done(evtErr error, customData interface{}, abort bool) (err error) {
 	// Done will be usually called in a defer block ignoring errors. This is
 	// why we log error messages here.
@@ -1152,10 +1238,17 @@ func (e *Event) done(evtErr error, customData interface{}, abort bool) (err erro
 	}
 	if evtErr != nil {
 		e.Error = evtErr.Error()
+		e.fillErrorContext()
 	} else if e.CancelInfo.Canceled {
 		e.Error = "canceled by user request"
 	}
 	e.EndTime = time.Now().UTC()
+	// A mismatching end custom data must not leave the event running and
+	// its target locked, so it's only logged.
+	schema, _ := targetCustomDataSchema(e.Kind.Name, e.Target)
+	if validationErr := validateCustomData(e.Kind.Name, "end", schema.End, customData); validationErr != nil {
+		log.Errorf("[events] %s - %s", e.UniqueID.Hex(), validationErr)
+	}
 	e.EndCustomData, err = makeBSONRaw(customData)
 	if err != nil {
 		return err
@@ -1183,6 +1276,13 @@ func (e *Event) Init() {
 	}
 }
 
+// Expired reports whether the event is running but had no lock update for
+// longer than the lock expiration timeout, meaning its operation is dead and
+// the event will be finished by the event cleaner.
+func (e *Event) Expired() bool {
+	return e.Running && time.Now().UTC().After(e.LockUpdateTime.UTC().Add(lockExpireTimeout))
+}
+
 func checkIsExpired(coll *storage.Collection, id interface{}) bool {
 	var existingEvt Event
 	err := coll.FindId(id).One(&existingEvt.eventData)
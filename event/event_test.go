@@ -47,6 +47,7 @@ func setBaseConfig() {
 func (s *S) SetUpTest(c *check.C) {
 	setBaseConfig()
 	throttlingInfo = map[string]ThrottlingSpec{}
+	customDataSchemas = map[string]CustomDataSchema{}
 	conn, err := db.Conn()
 	c.Assert(err, check.IsNil)
 	defer conn.Close()
@@ -655,6 +656,49 @@ func (s *S) TestEventNewValidation(c *check.C) {
 	c.Assert(err, check.Equals, ErrNoOwner)
 }
 
+func (s *S) TestEventDoneErrorContext(c *check.C) {
+	RegisterErrorContext("mytarget", func(target Target) (interface{}, error) {
+		return map[string]string{"oncall": target.Value + "@example.com"}, nil
+	})
+	defer delete(errorContextFuncs, "mytarget")
+	evt, err := New(&Opts{
+		Target:  Target{Type: "mytarget", Value: "ops"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(errors.New("myerr"))
+	c.Assert(err, check.IsNil)
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	var data map[string]string
+	err = evts[0].ErrorContextData(&data)
+	c.Assert(err, check.IsNil)
+	c.Assert(data, check.DeepEquals, map[string]string{"oncall": "ops@example.com"})
+}
+
+func (s *S) TestEventDoneSuccessNoErrorContext(c *check.C) {
+	RegisterErrorContext("mytarget", func(target Target) (interface{}, error) {
+		return map[string]string{"oncall": "ops@example.com"}, nil
+	})
+	defer delete(errorContextFuncs, "mytarget")
+	evt, err := New(&Opts{
+		Target:  Target{Type: "mytarget", Value: "ops"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].ErrorContext.Kind, check.Equals, byte(0))
+}
+
 func (s *S) TestEventDoneLogError(c *check.C) {
 	logBuf := safe.NewBuffer(nil)
 	log.SetLogger(log.NewWriterLogger(logBuf, false))
@@ -1078,6 +1122,27 @@ func (s *S) TestEventOtherCustomData(c *check.C) {
 	c.Assert(data, check.DeepEquals, map[string]string{"z": "h"})
 }
 
+func (s *S) TestEventSetOtherCustomDataField(c *check.C) {
+	evt, err := New(&Opts{
+		Target:  Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.SetOtherCustomDataField("z", "h")
+	c.Assert(err, check.IsNil)
+	err = evt.SetOtherCustomDataField("w", "k")
+	c.Assert(err, check.IsNil)
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	var data map[string]string
+	err = evts[0].OtherData(&data)
+	c.Assert(err, check.IsNil)
+	c.Assert(data, check.DeepEquals, map[string]string{"z": "h", "w": "k"})
+}
+
 func (s *S) TestEventAsWriter(c *check.C) {
 	evt, err := New(&Opts{
 		Target:     Target{Type: "app", Value: "myapp"},
@@ -0,0 +1,228 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrSchemaNotFound = errors.New("event custom data schema not found")
+
+// CustomDataSchema describes the custom data stored in events of a kind.
+// Start, End and Other hold values of the Go types used as the respective
+// custom data, a nil value means the custom data is not validated. When
+// TargetType is set, only events with targets of that type are validated.
+type CustomDataSchema struct {
+	Kind       string
+	Version    int
+	TargetType TargetType
+	Start      interface{}
+	End        interface{}
+	Other      interface{}
+}
+
+// SchemaDescription is the description of a registered schema, with each
+// custom data described by its fields as they're stored in the event.
+type SchemaDescription struct {
+	Kind       string
+	Version    int
+	TargetType TargetType   `json:",omitempty"`
+	Start      *SchemaField `json:",omitempty"`
+	End        *SchemaField `json:",omitempty"`
+	Other      *SchemaField `json:",omitempty"`
+}
+
+// SchemaField describes a value in the custom data. Type is one of string,
+// int, uint, float, bool, time, binary, object, array, map or any. Objects
+// list their fields in Fields while arrays and maps describe their values in
+// Elem.
+type SchemaField struct {
+	Name   string `json:",omitempty"`
+	Type   string
+	Fields []SchemaField `json:",omitempty"`
+	Elem   *SchemaField  `json:",omitempty"`
+}
+
+var (
+	schemasMu         sync.RWMutex
+	customDataSchemas = map[string]CustomDataSchema{}
+)
+
+// RegisterCustomDataSchema registers the schema of the custom data of events
+// with the given kind, replacing any schema previously registered for it.
+// The version defaults to 1 and must be increased whenever the structure of
+// the custom data changes.
+func RegisterCustomDataSchema(schema CustomDataSchema) {
+	if schema.Version == 0 {
+		schema.Version = 1
+	}
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	customDataSchemas[schema.Kind] = schema
+}
+
+func getCustomDataSchema(kind string) (CustomDataSchema, bool) {
+	schemasMu.RLock()
+	defer schemasMu.RUnlock()
+	schema, ok := customDataSchemas[kind]
+	return schema, ok
+}
+
+// targetCustomDataSchema returns the schema used to validate the custom data
+// of events with the given kind and target.
+func targetCustomDataSchema(kind string, target Target) (CustomDataSchema, bool) {
+	schema, ok := getCustomDataSchema(kind)
+	if !ok || (schema.TargetType != "" && schema.TargetType != target.Type) {
+		return CustomDataSchema{}, false
+	}
+	return schema, true
+}
+
+// GetCustomDataSchema returns the description of the schema registered for
+// the given kind.
+func GetCustomDataSchema(kind string) (*SchemaDescription, error) {
+	schema, ok := getCustomDataSchema(kind)
+	if !ok {
+		return nil, ErrSchemaNotFound
+	}
+	return schema.describe(), nil
+}
+
+// CustomDataSchemas returns the description of all registered schemas,
+// sorted by kind.
+func CustomDataSchemas() []SchemaDescription {
+	schemasMu.RLock()
+	defer schemasMu.RUnlock()
+	result := make([]SchemaDescription, 0, len(customDataSchemas))
+	for _, schema := range customDataSchemas {
+		result = append(result, *schema.describe())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Kind < result[j].Kind
+	})
+	return result
+}
+
+func (s *CustomDataSchema) describe() *SchemaDescription {
+	return &SchemaDescription{
+		Kind:       s.Kind,
+		Version:    s.Version,
+		TargetType: s.TargetType,
+		Start:      describeValue(s.Start),
+		End:        describeValue(s.End),
+		Other:      describeValue(s.Other),
+	}
+}
+
+// validateCustomData checks whether the custom data matches the type
+// expected by the schema of the kind. Nil custom data is always valid.
+func validateCustomData(kind, part string, expected, data interface{}) error {
+	if expected == nil || data == nil {
+		return nil
+	}
+	expectedType := indirectType(reflect.TypeOf(expected))
+	dataType := indirectType(reflect.TypeOf(data))
+	if expectedType != dataType {
+		return ErrValidation(fmt.Sprintf("invalid %s custom data for event kind %q: expected %s, got %s", part, kind, expectedType, dataType))
+	}
+	return nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func describeValue(value interface{}) *SchemaField {
+	if value == nil {
+		return nil
+	}
+	field := describeType(reflect.TypeOf(value), map[reflect.Type]bool{})
+	return &field
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func describeType(t reflect.Type, visiting map[reflect.Type]bool) SchemaField {
+	t = indirectType(t)
+	switch t.Kind() {
+	case reflect.String:
+		return SchemaField{Type: "string"}
+	case reflect.Bool:
+		return SchemaField{Type: "bool"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return SchemaField{Type: "int"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SchemaField{Type: "uint"}
+	case reflect.Float32, reflect.Float64:
+		return SchemaField{Type: "float"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return SchemaField{Type: "binary"}
+		}
+		elem := describeType(t.Elem(), visiting)
+		return SchemaField{Type: "array", Elem: &elem}
+	case reflect.Map:
+		elem := describeType(t.Elem(), visiting)
+		return SchemaField{Type: "map", Elem: &elem}
+	case reflect.Struct:
+		if t == timeType {
+			return SchemaField{Type: "time"}
+		}
+		field := SchemaField{Type: "object"}
+		// Recursive types are described only up to their first
+		// repetition.
+		if visiting[t] {
+			return field
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		field.Fields = describeFields(t, visiting)
+		return field
+	}
+	return SchemaField{Type: "any"}
+}
+
+func describeFields(t reflect.Type, visiting map[reflect.Type]bool) []SchemaField {
+	var fields []SchemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		switch indirectType(f.Type).Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			continue
+		}
+		tag := f.Tag.Get("bson")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		if strings.Contains(opts, "inline") && indirectType(f.Type).Kind() == reflect.Struct {
+			fields = append(fields, describeFields(indirectType(f.Type), visiting)...)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		field := describeType(f.Type, visiting)
+		field.Name = name
+		fields = append(fields, field)
+	}
+	return fields
+}
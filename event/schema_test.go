@@ -0,0 +1,163 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/permission"
+	"gopkg.in/check.v1"
+)
+
+type schemaStartData struct {
+	Name     string
+	Count    int    `bson:"total"`
+	Internal string `bson:"-"`
+	Tags     []string
+	When     time.Time
+	Inner    *schemaInnerData
+	Labels   map[string]string
+	Extra    interface{}
+}
+
+type schemaInnerData struct {
+	Ok     bool
+	Parent *schemaInnerData
+}
+
+func (s *S) TestCustomDataSchemaDescription(c *check.C) {
+	RegisterCustomDataSchema(CustomDataSchema{
+		Kind:  "schema-test",
+		Start: &schemaStartData{},
+		End:   []string{},
+	})
+	schema, err := GetCustomDataSchema("schema-test")
+	c.Assert(err, check.IsNil)
+	c.Assert(schema, check.DeepEquals, &SchemaDescription{
+		Kind:    "schema-test",
+		Version: 1,
+		Start: &SchemaField{Type: "object", Fields: []SchemaField{
+			{Name: "name", Type: "string"},
+			{Name: "total", Type: "int"},
+			{Name: "tags", Type: "array", Elem: &SchemaField{Type: "string"}},
+			{Name: "when", Type: "time"},
+			{Name: "inner", Type: "object", Fields: []SchemaField{
+				{Name: "ok", Type: "bool"},
+				{Name: "parent", Type: "object"},
+			}},
+			{Name: "labels", Type: "map", Elem: &SchemaField{Type: "string"}},
+			{Name: "extra", Type: "any"},
+		}},
+		End: &SchemaField{Type: "array", Elem: &SchemaField{Type: "string"}},
+	})
+	_, err = GetCustomDataSchema("schema-unknown")
+	c.Assert(err, check.Equals, ErrSchemaNotFound)
+	var kinds []string
+	for _, schema := range CustomDataSchemas() {
+		kinds = append(kinds, schema.Kind)
+	}
+	c.Assert(kinds, check.DeepEquals, []string{"schema-test"})
+}
+
+func (s *S) TestNewValidatesCustomDataSchema(c *check.C) {
+	RegisterCustomDataSchema(CustomDataSchema{
+		Kind:    "schema-test",
+		Version: 2,
+		Start:   schemaStartData{},
+		End:     map[string]string{},
+		Other:   schemaInnerData{},
+	})
+	opts := &Opts{
+		Target:       Target{Type: "app", Value: "myapp"},
+		InternalKind: "schema-test",
+		CustomData:   map[string]string{"name": "x"},
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	}
+	_, err := NewInternal(opts)
+	c.Assert(err, check.ErrorMatches, `invalid start custom data for event kind "schema-test": expected event.schemaStartData, got map\[string\]string`)
+	opts.CustomData = &schemaStartData{Name: "x"}
+	evt, err := NewInternal(opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.CustomDataVersion, check.Equals, 2)
+	err = evt.SetOtherCustomData([]string{"a"})
+	c.Assert(err, check.FitsTypeOf, ErrValidation(""))
+	err = evt.SetOtherCustomData(schemaInnerData{Ok: true})
+	c.Assert(err, check.IsNil)
+	err = evt.DoneCustomData(nil, map[string]string{"a": "b"})
+	c.Assert(err, check.IsNil)
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].CustomDataVersion, check.Equals, 2)
+	var data schemaStartData
+	err = evts[0].StartData(&data)
+	c.Assert(err, check.IsNil)
+	c.Assert(data.Name, check.Equals, "x")
+}
+
+func (s *S) TestDoneWithInvalidEndCustomData(c *check.C) {
+	RegisterCustomDataSchema(CustomDataSchema{
+		Kind: "schema-test",
+		End:  map[string]string{},
+	})
+	opts := &Opts{
+		Target:       Target{Type: "app", Value: "myapp"},
+		InternalKind: "schema-test",
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	}
+	evt, err := NewInternal(opts)
+	c.Assert(err, check.IsNil)
+	err = evt.DoneCustomData(nil, []string{"a"})
+	c.Assert(err, check.IsNil)
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	var data []string
+	err = evts[0].EndData(&data)
+	c.Assert(err, check.IsNil)
+	c.Assert(data, check.DeepEquals, []string{"a"})
+	_, err = NewInternal(opts)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCustomDataSchemaTargetType(c *check.C) {
+	RegisterCustomDataSchema(CustomDataSchema{
+		Kind:       "schema-test",
+		TargetType: TargetTypeApp,
+		Start:      schemaStartData{},
+	})
+	_, err := NewInternal(&Opts{
+		Target:       Target{Type: TargetTypeApp, Value: "myapp"},
+		InternalKind: "schema-test",
+		CustomData:   []string{"a"},
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.FitsTypeOf, ErrValidation(""))
+	evt, err := NewInternal(&Opts{
+		Target:       Target{Type: TargetTypeGlobal},
+		InternalKind: "schema-test",
+		CustomData:   []string{"a"},
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.CustomDataVersion, check.Equals, 0)
+	schema, err := GetCustomDataSchema("schema-test")
+	c.Assert(err, check.IsNil)
+	c.Assert(schema.TargetType, check.Equals, TargetTypeApp)
+}
+
+func (s *S) TestNewWithoutCustomDataSchema(c *check.C) {
+	evt, err := NewInternal(&Opts{
+		Target:       Target{Type: "app", Value: "myapp"},
+		InternalKind: "schema-test",
+		CustomData:   []string{"a"},
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.CustomDataVersion, check.Equals, 0)
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,11 +25,13 @@ import (
 	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodeagent"
 	"github.com/tsuru/tsuru/scopedconfig"
 )
 
 const (
 	nodeHealerConfigCollection = "node-healer"
+	healingStepsInterval       = time.Hour
 )
 
 type NodeHealer struct {
@@ -38,6 +41,8 @@ type NodeHealer struct {
 	failuresBeforeHealing int
 	quit                  chan bool
 	started               time.Time
+	stepsMu               sync.Mutex
+	lastHealingSteps      map[string]time.Time
 }
 
 type nodeHealerArgs struct {
@@ -228,13 +233,14 @@ func (h *NodeHealer) tryHealingNode(node provision.Node, reason string, lastChec
 	}
 	var createdNode *provision.NodeSpec
 	var evtErr error
+	var recovered bool
 	defer func() {
 		if createdNode != nil {
 			evt.ExtraTargets = append(evt.ExtraTargets,
 				event.ExtraTarget{Target: event.Target{Type: event.TargetTypeNode, Value: createdNode.Address}})
 		}
 		var updateErr error
-		if evtErr == nil && createdNode == nil {
+		if evtErr == nil && createdNode == nil && !recovered {
 			updateErr = evt.Abort()
 		} else {
 			updateErr = evt.DoneCustomData(evtErr, createdNode)
@@ -259,11 +265,58 @@ func (h *NodeHealer) tryHealingNode(node provision.Node, reason string, lastChec
 	if !shouldHeal {
 		return nil
 	}
+	if h.runHealingSteps(node, evt) {
+		recovered = true
+		return nil
+	}
 	log.Errorf("initiating healing process for node %q due to: %s", node.Address(), reason)
 	createdNode, evtErr = h.healNode(node)
 	return evtErr
 }
 
+// runHealingSteps tries to recover the node running the healing steps
+// configured in the node agent, returning true if one of them succeeded and
+// the agent reports the node healthy afterwards. The
+// steps run at most once per node in healingStepsInterval, so nodes that keep
+// failing are eventually replaced.
+func (h *NodeHealer) runHealingSteps(node provision.Node, w io.Writer) bool {
+	steps := nodeagent.HealingSteps()
+	if len(steps) == 0 {
+		return false
+	}
+	client, err := nodeagent.NewClient(node.Address())
+	if err != nil {
+		return false
+	}
+	h.stepsMu.Lock()
+	if h.lastHealingSteps == nil {
+		h.lastHealingSteps = map[string]time.Time{}
+	}
+	lastRun := h.lastHealingSteps[node.Address()]
+	if time.Since(lastRun) < healingStepsInterval {
+		h.stepsMu.Unlock()
+		return false
+	}
+	h.lastHealingSteps[node.Address()] = time.Now()
+	h.stepsMu.Unlock()
+	for _, step := range steps {
+		fmt.Fprintf(w, "running healing step %q in node %q\n", step, node.Address())
+		err = client.RunHealingStep(w, step)
+		if err != nil {
+			fmt.Fprintf(w, "healing step %q failed: %s\n", step, err)
+			continue
+		}
+		err = client.Health()
+		if err != nil {
+			fmt.Fprintf(w, "node still unhealthy after healing step %q: %s\n", step, err)
+			continue
+		}
+		log.Debugf("healing step %q recovered node %q", step, node.Address())
+		return true
+	}
+	return false
+}
+
 func (h *NodeHealer) HandleError(node provision.NodeHealthChecker) time.Duration {
 	h.wg.Add(1)
 	defer h.wg.Done()
@@ -328,6 +381,26 @@ func (h *NodeHealer) GetNodeStatusData(node provision.Node) (NodeStatusData, err
 	return nodeStatus, nil
 }
 
+// ListNodeStatusData returns the status data of every node, indexed by node
+// address.
+func (h *NodeHealer) ListNodeStatusData() (map[string]NodeStatusData, error) {
+	coll, err := nodeDataCollection()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get node data collection")
+	}
+	defer coll.Close()
+	var statuses []NodeStatusData
+	err = coll.Find(nil).All(&statuses)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]NodeStatusData, len(statuses))
+	for _, st := range statuses {
+		result[st.Address] = st
+	}
+	return result, nil
+}
+
 func (h *NodeHealer) UpdateNodeData(node provision.Node, checks []provision.NodeCheckResult) error {
 	isSuccess := true
 	for _, c := range checks {
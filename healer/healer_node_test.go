@@ -7,8 +7,13 @@ package healer
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,6 +26,7 @@ import (
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodehook"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"gopkg.in/check.v1"
 )
@@ -95,6 +101,32 @@ func (s *S) TestHealerHealNode(c *check.C) {
 	c.Assert(n, check.Equals, 0)
 }
 
+func (s *S) TestHealerHealNodeCallsNodeHooks(c *check.C) {
+	var mu sync.Mutex
+	var events []string
+	hookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p nodehook.Payload
+		json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		events = append(events, p.Event+" "+p.Node.Address)
+		mu.Unlock()
+	}))
+	defer hookServer.Close()
+	healer, nodes, _ := s.createBasicTestHealer(c)
+	nodehook.Wait()
+	config.Set("node-hooks:webhooks", []string{hookServer.URL})
+	defer config.Unset("node-hooks")
+	_, err := healer.healNode(nodes[0])
+	c.Assert(err, check.IsNil)
+	nodehook.Wait()
+	sort.Strings(events)
+	c.Assert(events, check.DeepEquals, []string{
+		"add http://addr2:2",
+		"disable http://addr1:1",
+		"remove http://addr1:1",
+	})
+}
+
 func (s *S) TestHealerHealNodeSameAddr(c *check.C) {
 	healer, nodes, p := s.createBasicTestHealer(c)
 	s.iaasInst.Addr = "addr1"
@@ -490,6 +522,102 @@ func (s *S) TestHealerHandleError(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestHealerHandleErrorRecoveredByHealingSteps(c *check.C) {
+	var steps []string
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		steps = append(steps, r.URL.Path)
+		if r.URL.Path != "/healing/steps/restart-docker" && r.URL.Path != "/health" {
+			http.Error(w, "unable to recover", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("docker restarted\n"))
+	}))
+	defer agent.Close()
+	agentURL, err := url.Parse(agent.URL)
+	c.Assert(err, check.IsNil)
+	config.Set("node-agent:port", agentURL.Port())
+	config.Set("node-agent:scheme", "http")
+	config.Set("node-agent:healing-steps", []string{"check-disks", "restart-docker"})
+	defer config.Unset("node-agent")
+	factory, iaasInst := iaasTesting.NewHealerIaaSConstructorWithInst("127.0.0.1")
+	iaas.RegisterIaasProvider("my-healer-iaas", factory)
+	m, err := iaas.CreateMachineForIaaS("my-healer-iaas", map[string]string{})
+	c.Assert(err, check.IsNil)
+	iaasInst.Addr = "addr2"
+	config.Set("iaas:node-protocol", "http")
+	config.Set("iaas:node-port", 2)
+	defer config.Unset("iaas:node-protocol")
+	defer config.Unset("iaas:node-port")
+	p := provisiontest.ProvisionerInstance
+	err = p.AddNode(provision.AddNodeOptions{
+		Address:  "http://127.0.0.1:1",
+		Metadata: map[string]string{"iaas": "my-healer-iaas"},
+		IaaSID:   m.Id,
+		Pool:     "p1",
+	})
+	c.Assert(err, check.IsNil)
+	node, err := p.GetNode("http://127.0.0.1:1")
+	c.Assert(err, check.IsNil)
+	healer := newNodeHealer(nodeHealerArgs{
+		FailuresBeforeHealing: 1,
+		WaitTimeNewMachine:    time.Minute,
+	})
+	healer.Shutdown(context.Background())
+	healer.started = time.Now().Add(-3 * time.Second)
+	conf := healerConfig()
+	err = conf.SaveBase(NodeHealerConfig{Enabled: boolPtr(true), MaxUnresponsiveTime: intPtr(1)})
+	c.Assert(err, check.IsNil)
+	err = healer.UpdateNodeData(node, []provision.NodeCheckResult{})
+	c.Assert(err, check.IsNil)
+	time.Sleep(1200 * time.Millisecond)
+	node.(*provisiontest.FakeNode).SetHealth(2, true)
+	healer.HandleError(node.(provision.NodeHealthChecker))
+	c.Assert(steps, check.DeepEquals, []string{"/healing/steps/check-disks", "/healing/steps/restart-docker", "/health"})
+	nodes, err := p.ListNodes(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	c.Assert(nodes[0].Address(), check.Equals, "http://127.0.0.1:1")
+	c.Assert(eventtest.EventDesc{
+		Target:     event.Target{Type: "node", Value: "http://127.0.0.1:1"},
+		Kind:       "healer",
+		LogMatches: `(?s).*healing step "check-disks" failed.*docker restarted.*`,
+	}, eventtest.HasEvent)
+	healer.HandleError(node.(provision.NodeHealthChecker))
+	c.Assert(steps, check.HasLen, 3)
+	nodes, err = p.ListNodes(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	c.Assert(nodes[0].Address(), check.Equals, "http://addr2:2")
+}
+
+func (s *S) TestHealerHealingStepsRequireHealthyAgent(c *check.C) {
+	var paths []string
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/health" {
+			http.Error(w, "disk failure", http.StatusServiceUnavailable)
+		}
+	}))
+	defer agent.Close()
+	agentURL, err := url.Parse(agent.URL)
+	c.Assert(err, check.IsNil)
+	config.Set("node-agent:port", agentURL.Port())
+	config.Set("node-agent:scheme", "http")
+	config.Set("node-agent:healing-steps", []string{"restart-docker"})
+	defer config.Unset("node-agent")
+	p := provisiontest.ProvisionerInstance
+	err = p.AddNode(provision.AddNodeOptions{Address: "http://127.0.0.1:1", Pool: "p1"})
+	c.Assert(err, check.IsNil)
+	node, err := p.GetNode("http://127.0.0.1:1")
+	c.Assert(err, check.IsNil)
+	healer := newNodeHealer(nodeHealerArgs{WaitTimeNewMachine: time.Minute})
+	healer.Shutdown(context.Background())
+	var buf bytes.Buffer
+	c.Assert(healer.runHealingSteps(node, &buf), check.Equals, false)
+	c.Assert(paths, check.DeepEquals, []string{"/healing/steps/restart-docker", "/health"})
+	c.Assert(buf.String(), check.Matches, `(?s).*node still unhealthy after healing step "restart-docker": .*disk failure.*`)
+}
+
 func (s *S) TestHealerHandleErrorFailureEvent(c *check.C) {
 	factory, iaasInst := iaasTesting.NewHealerIaaSConstructorWithInst("addr1")
 	iaas.RegisterIaasProvider("my-healer-iaas", factory)
@@ -777,6 +905,31 @@ func (s *S) TestHealerGetNodeStatusData(c *check.C) {
 	})
 }
 
+func (s *S) TestHealerListNodeStatusData(c *check.C) {
+	p := provisiontest.ProvisionerInstance
+	err := p.AddNode(provision.AddNodeOptions{Address: "http://addr1:1"})
+	c.Assert(err, check.IsNil)
+	err = p.AddNode(provision.AddNodeOptions{Address: "http://addr2:1"})
+	c.Assert(err, check.IsNil)
+	node1, err := p.GetNode("http://addr1:1")
+	c.Assert(err, check.IsNil)
+	healer := newNodeHealer(nodeHealerArgs{})
+	healer.Shutdown(context.Background())
+	statuses, err := healer.ListNodeStatusData()
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 0)
+	checks := []provision.NodeCheckResult{{Name: "ok1", Successful: true}}
+	err = healer.UpdateNodeData(node1, checks)
+	c.Assert(err, check.IsNil)
+	statuses, err = healer.ListNodeStatusData()
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 1)
+	status := statuses["http://addr1:1"]
+	c.Assert(status.LastSuccess.IsZero(), check.Equals, false)
+	c.Assert(status.Checks, check.HasLen, 1)
+	c.Assert(status.Checks[0].Checks, check.DeepEquals, checks)
+}
+
 func (s *S) TestFindNodesForHealingNoNodes(c *check.C) {
 	p := provisiontest.ProvisionerInstance
 	nodeAddr := "http://addr1:1"
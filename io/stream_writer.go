@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sync"
 
 	"github.com/docker/docker/pkg/jsonmessage"
@@ -27,6 +28,15 @@ type syncWriter struct {
 	mu sync.Mutex
 }
 
+// NewSyncWriter returns a writer that serializes writes to w, allowing it to
+// be shared by multiple goroutines. Writes are discarded if w is nil.
+func NewSyncWriter(w io.Writer) io.Writer {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	return &syncWriter{w: w}
+}
+
 func (w *syncWriter) Write(b []byte) (int, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
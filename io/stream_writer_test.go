@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
 
 	"gopkg.in/check.v1"
 )
@@ -305,6 +307,24 @@ func (s *S) TestSyncWriterFD(c *check.C) {
 	c.Assert(fd, check.Equals, 0)
 }
 
+func (s *S) TestNewSyncWriter(c *check.C) {
+	var buf bytes.Buffer
+	w := NewSyncWriter(&buf)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Write([]byte("abc"))
+		}()
+	}
+	wg.Wait()
+	c.Assert(buf.String(), check.Equals, strings.Repeat("abc", 10))
+	n, err := NewSyncWriter(nil).Write([]byte("abc"))
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 3)
+}
+
 func (s *S) TestDockerErrorCheckWriter(c *check.C) {
 	tests := []struct {
 		msg []string
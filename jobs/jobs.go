@@ -0,0 +1,506 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jobs runs recurring platform tasks on cron schedules. Tasks are
+// registered by tsuru components and can be enabled, disabled, rescheduled
+// and triggered by administrators. When running multiple API instances only
+// the one holding the leader lease evaluates the schedules. Every run is
+// recorded as an event targeting the job, which also prevents concurrent
+// runs of the same job.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/cron"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const (
+	EventKindRun = "job-run"
+
+	defaultInterval    = 30 * time.Second
+	defaultLeaderLease = 2 * time.Minute
+	leaderID           = "leader"
+	// maxCatchUp limits how far in the past the scheduler looks for missed
+	// runs, e.g. when no API instance was running.
+	maxCatchUp = 24 * time.Hour
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+
+	instanceID = bson.NewObjectId().Hex()
+)
+
+// RunFunc runs a job. The context is canceled when the API is shutting down
+// and messages logged in the event are kept in the run history.
+type RunFunc func(ctx context.Context, evt *event.Event) error
+
+// Job is a recurring task. Schedule is the default cron expression used to
+// run it, which may be changed by administrators. Jobs registered as disabled
+// only run after being explicitly enabled.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Disabled    bool
+	Run         RunFunc
+}
+
+var registry = struct {
+	sync.RWMutex
+	m map[string]Job
+}{m: map[string]Job{}}
+
+// Register registers a job, replacing any job previously registered with
+// the same name.
+func Register(j Job) error {
+	if j.Name == "" {
+		return errors.New("job name is mandatory")
+	}
+	if j.Run == nil {
+		return errors.Errorf("job %q has no run function", j.Name)
+	}
+	_, err := cron.Parse(j.Schedule)
+	if err != nil {
+		return errors.Wrapf(err, "invalid schedule for job %q", j.Name)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.m[j.Name] = j
+	return nil
+}
+
+func getJob(name string) (Job, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	j, ok := registry.m[name]
+	return j, ok
+}
+
+func allJobs() []Job {
+	registry.RLock()
+	defer registry.RUnlock()
+	result := make([]Job, 0, len(registry.m))
+	for _, j := range registry.m {
+		result = append(result, j)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// state is the stored configuration and last run of a job.
+type state struct {
+	Name      string `bson:"_id"`
+	Disabled  *bool  `bson:",omitempty"`
+	Schedule  string `bson:",omitempty"`
+	LastRun   time.Time
+	LastError string
+	// Since is the starting point used to look for scheduled runs when the
+	// job has never run.
+	Since time.Time
+}
+
+// Info describes a registered job with its current configuration.
+type Info struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Schedule    string    `json:"schedule"`
+	Enabled     bool      `json:"enabled"`
+	LastRun     time.Time `json:"lastRun,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+	NextRun     time.Time `json:"nextRun,omitempty"`
+}
+
+// UpdateOptions holds the changes in the configuration of a job. Nil fields
+// are kept unchanged and an empty schedule restores the default one.
+type UpdateOptions struct {
+	Enabled  *bool
+	Schedule *string
+}
+
+func collection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("jobs"), nil
+}
+
+func leaderCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("jobs_leader"), nil
+}
+
+func durationConfig(key string, def time.Duration) time.Duration {
+	seconds, _ := config.GetInt(key)
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func loadState(coll *storage.Collection, name string) (*state, error) {
+	var st state
+	err := coll.FindId(name).One(&st)
+	if err == mgo.ErrNotFound {
+		now := time.Now().UTC()
+		st = state{Name: name, Since: now}
+		err = coll.Insert(st)
+		if mgo.IsDup(err) {
+			err = coll.FindId(name).One(&st)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (st *state) enabled(j Job) bool {
+	if st.Disabled != nil {
+		return !*st.Disabled
+	}
+	return !j.Disabled
+}
+
+func (st *state) schedule(j Job) string {
+	if st.Schedule != "" {
+		return st.Schedule
+	}
+	return j.Schedule
+}
+
+func (st *state) info(j Job) Info {
+	info := Info{
+		Name:        j.Name,
+		Description: j.Description,
+		Schedule:    st.schedule(j),
+		Enabled:     st.enabled(j),
+		LastRun:     st.LastRun,
+		LastError:   st.LastError,
+	}
+	if info.Enabled {
+		if sched, err := cron.Parse(info.Schedule); err == nil {
+			info.NextRun = sched.Next(time.Now().UTC())
+		}
+	}
+	return info
+}
+
+// List returns all registered jobs.
+func List() ([]Info, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var result []Info
+	for _, j := range allJobs() {
+		st, err := loadState(coll, j.Name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, st.info(j))
+	}
+	return result, nil
+}
+
+// Get returns the registered job with the given name.
+func Get(name string) (*Info, error) {
+	j, ok := getJob(name)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	st, err := loadState(coll, name)
+	if err != nil {
+		return nil, err
+	}
+	info := st.info(j)
+	return &info, nil
+}
+
+// Update changes the configuration of the job with the given name.
+func Update(name string, opts UpdateOptions) error {
+	if _, ok := getJob(name); !ok {
+		return ErrJobNotFound
+	}
+	set := bson.M{}
+	if opts.Enabled != nil {
+		set["disabled"] = !*opts.Enabled
+	}
+	if opts.Schedule != nil {
+		if *opts.Schedule != "" {
+			if _, err := cron.Parse(*opts.Schedule); err != nil {
+				return &tsuruErrors.ValidationError{Message: err.Error()}
+			}
+		}
+		set["schedule"] = *opts.Schedule
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = loadState(coll, name)
+	if err != nil {
+		return err
+	}
+	return coll.UpdateId(name, bson.M{"$set": set})
+}
+
+// Run runs the job with the given name in the event, which must target the
+// job, recording its start time and outcome as the last run.
+func Run(ctx context.Context, name string, evt *event.Event) error {
+	j, ok := getJob(name)
+	if !ok {
+		return ErrJobNotFound
+	}
+	start := time.Now().UTC()
+	runErr := run(ctx, j, evt)
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	var lastError string
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+	_, err = coll.UpsertId(name, bson.M{
+		"$set": bson.M{"lasterror": lastError},
+		"$max": bson.M{"lastrun": start},
+	})
+	if err != nil {
+		log.Errorf("[jobs] unable to store result of job %q: %v", name, err)
+	}
+	return runErr
+}
+
+func run(ctx context.Context, j Job, evt *event.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic running job: %v", r)
+		}
+	}()
+	return j.Run(ctx, evt)
+}
+
+// Target returns the event target of runs of the job with the given name.
+func Target(name string) event.Target {
+	return event.Target{Type: event.TargetTypeJob, Value: name}
+}
+
+// tryLead acquires or renews the leader lease, returning whether this
+// instance is the leader.
+func tryLead(now time.Time) (bool, error) {
+	coll, err := leaderCollection()
+	if err != nil {
+		return false, err
+	}
+	defer coll.Close()
+	_, err = coll.Find(bson.M{"_id": leaderID, "$or": []bson.M{
+		{"owner": instanceID},
+		{"expires": bson.M{"$lt": now}},
+	}}).Apply(mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"owner":   instanceID,
+			"expires": now.Add(durationConfig("jobs:leader-lease", defaultLeaderLease)),
+		}},
+		Upsert: true,
+	}, nil)
+	if mgo.IsDup(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func resign() error {
+	coll, err := leaderCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.Remove(bson.M{"_id": leaderID, "owner": instanceID})
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// claim marks the job as run at the given time, returning false when the
+// run was already claimed.
+func (st *state) claim(coll *storage.Collection, runAt time.Time) (bool, error) {
+	err := coll.Update(bson.M{"_id": st.Name, "lastrun": st.LastRun}, bson.M{"$set": bson.M{"lastrun": runAt}})
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// dueJobs claims and returns the enabled jobs whose schedules fired since
+// their last run.
+func dueJobs(now time.Time) ([]Job, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var due []Job
+	for _, j := range allJobs() {
+		st, err := loadState(coll, j.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !st.enabled(j) {
+			continue
+		}
+		sched, err := cron.Parse(st.schedule(j))
+		if err != nil {
+			log.Errorf("[jobs] ignoring job %q with invalid schedule: %v", j.Name, err)
+			continue
+		}
+		since := st.LastRun
+		if since.IsZero() {
+			since = st.Since
+		}
+		if limit := now.Add(-maxCatchUp); since.Before(limit) {
+			since = limit
+		}
+		runAt := sched.Prev(now, since)
+		if runAt.IsZero() {
+			continue
+		}
+		claimed, err := st.claim(coll, runAt)
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			due = append(due, j)
+		}
+	}
+	return due, nil
+}
+
+func runScheduled(ctx context.Context, j Job) {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       Target(j.Name),
+		InternalKind: EventKindRun,
+		Allowed:      event.Allowed(permission.PermJobReadEvents),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			log.Debugf("[jobs] job %q already running, skipping scheduled run", j.Name)
+			return
+		}
+		log.Errorf("[jobs] unable to create event for job %q: %v", j.Name, err)
+		return
+	}
+	err = Run(ctx, j.Name, evt)
+	if err != nil {
+		log.Errorf("[jobs] error running job %q: %v", j.Name, err)
+	}
+	evt.Done(err)
+}
+
+// Initialize starts running the registered jobs in background.
+func Initialize() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &scheduler{stopCh: make(chan struct{}), ctx: ctx, cancel: cancel}
+	go s.spin()
+	shutdown.Register(s)
+	return nil
+}
+
+type scheduler struct {
+	stopCh chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (s *scheduler) tick() error {
+	now := time.Now().UTC()
+	leader, err := tryLead(now)
+	if err != nil || !leader {
+		return err
+	}
+	due, err := dueJobs(now)
+	for _, j := range due {
+		s.wg.Add(1)
+		go func(j Job) {
+			defer s.wg.Done()
+			runScheduled(s.ctx, j)
+		}(j)
+	}
+	return err
+}
+
+func (s *scheduler) spin() {
+	for {
+		err := s.tick()
+		if err != nil {
+			log.Errorf("[jobs] error running scheduled jobs: %v", err)
+		}
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(durationConfig("jobs:interval", defaultInterval)):
+		}
+	}
+}
+
+func (s *scheduler) Shutdown(ctx context.Context) error {
+	s.stopCh <- struct{}{}
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return resign()
+}
+
+func (s *scheduler) String() string {
+	return "platform jobs scheduler"
+}
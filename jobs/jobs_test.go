@@ -0,0 +1,177 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+func registerCounter(c *check.C, name string, disabled bool) *int {
+	var runs int
+	err := Register(Job{
+		Name:     name,
+		Schedule: "* * * * *",
+		Disabled: disabled,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			runs++
+			evt.Logf("run %d", runs)
+			return nil
+		},
+	})
+	c.Assert(err, check.IsNil)
+	return &runs
+}
+
+func (s *S) TestRegisterInvalid(c *check.C) {
+	run := func(ctx context.Context, evt *event.Event) error { return nil }
+	err := Register(Job{Schedule: "@hourly", Run: run})
+	c.Assert(err, check.ErrorMatches, "job name is mandatory")
+	err = Register(Job{Name: "myjob", Schedule: "@hourly"})
+	c.Assert(err, check.ErrorMatches, `job "myjob" has no run function`)
+	err = Register(Job{Name: "myjob", Schedule: "* *", Run: run})
+	c.Assert(err, check.ErrorMatches, `invalid schedule for job "myjob": .*`)
+}
+
+func (s *S) TestListAndUpdate(c *check.C) {
+	registerCounter(c, "b-job", false)
+	registerCounter(c, "a-job", true)
+	lst, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(lst, check.HasLen, 2)
+	c.Assert(lst[0].Name, check.Equals, "a-job")
+	c.Assert(lst[0].Enabled, check.Equals, false)
+	c.Assert(lst[0].NextRun.IsZero(), check.Equals, true)
+	c.Assert(lst[1].Name, check.Equals, "b-job")
+	c.Assert(lst[1].Enabled, check.Equals, true)
+	c.Assert(lst[1].Schedule, check.Equals, "* * * * *")
+	c.Assert(lst[1].NextRun.IsZero(), check.Equals, false)
+	enabled := true
+	schedule := "@daily"
+	err = Update("a-job", UpdateOptions{Enabled: &enabled, Schedule: &schedule})
+	c.Assert(err, check.IsNil)
+	info, err := Get("a-job")
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Enabled, check.Equals, true)
+	c.Assert(info.Schedule, check.Equals, "@daily")
+	schedule = ""
+	err = Update("a-job", UpdateOptions{Schedule: &schedule})
+	c.Assert(err, check.IsNil)
+	info, err = Get("a-job")
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Enabled, check.Equals, true)
+	c.Assert(info.Schedule, check.Equals, "* * * * *")
+	schedule = "x"
+	err = Update("a-job", UpdateOptions{Schedule: &schedule})
+	c.Assert(err, check.ErrorMatches, `invalid cron expression "x".*`)
+	err = Update("unknown", UpdateOptions{Enabled: &enabled})
+	c.Assert(err, check.Equals, ErrJobNotFound)
+	_, err = Get("unknown")
+	c.Assert(err, check.Equals, ErrJobNotFound)
+}
+
+func (s *S) TestRun(c *check.C) {
+	err := Register(Job{
+		Name:     "myjob",
+		Schedule: "@hourly",
+		Run: func(ctx context.Context, evt *event.Event) error {
+			return errors.New("my error")
+		},
+	})
+	c.Assert(err, check.IsNil)
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       Target("myjob"),
+		InternalKind: EventKindRun,
+		Allowed:      event.Allowed(permission.PermJobReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = Run(context.Background(), "myjob", evt)
+	c.Assert(err, check.ErrorMatches, "my error")
+	info, err := Get("myjob")
+	c.Assert(err, check.IsNil)
+	c.Assert(info.LastError, check.Equals, "my error")
+	c.Assert(info.LastRun.IsZero(), check.Equals, false)
+	err = Run(context.Background(), "unknown", evt)
+	c.Assert(err, check.Equals, ErrJobNotFound)
+}
+
+func (s *S) TestRunRecoversPanic(c *check.C) {
+	err := Register(Job{
+		Name:     "myjob",
+		Schedule: "@hourly",
+		Run: func(ctx context.Context, evt *event.Event) error {
+			panic("boom")
+		},
+	})
+	c.Assert(err, check.IsNil)
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       Target("myjob"),
+		InternalKind: EventKindRun,
+		Allowed:      event.Allowed(permission.PermJobReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = Run(context.Background(), "myjob", evt)
+	c.Assert(err, check.ErrorMatches, "panic running job: boom")
+}
+
+func (s *S) TestTryLead(c *check.C) {
+	now := time.Now().UTC()
+	leader, err := tryLead(now)
+	c.Assert(err, check.IsNil)
+	c.Assert(leader, check.Equals, true)
+	leader, err = tryLead(now.Add(time.Second))
+	c.Assert(err, check.IsNil)
+	c.Assert(leader, check.Equals, true)
+	myID := instanceID
+	instanceID = "other-instance"
+	defer func() { instanceID = myID }()
+	leader, err = tryLead(now.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(leader, check.Equals, false)
+	leader, err = tryLead(now.Add(defaultLeaderLease + 2*time.Second))
+	c.Assert(err, check.IsNil)
+	c.Assert(leader, check.Equals, true)
+	instanceID = myID
+	leader, err = tryLead(now.Add(defaultLeaderLease + 3*time.Second))
+	c.Assert(err, check.IsNil)
+	c.Assert(leader, check.Equals, false)
+}
+
+func (s *S) TestSchedulerTick(c *check.C) {
+	enabledRuns := registerCounter(c, "enabled-job", false)
+	disabledRuns := registerCounter(c, "disabled-job", true)
+	coll, err := collection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	for _, name := range []string{"enabled-job", "disabled-job"} {
+		_, err = loadState(coll, name)
+		c.Assert(err, check.IsNil)
+		err = coll.UpdateId(name, map[string]interface{}{"$set": map[string]interface{}{"since": time.Now().UTC().Add(-2 * time.Minute)}})
+		c.Assert(err, check.IsNil)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sched := &scheduler{ctx: ctx, cancel: cancel}
+	err = sched.tick()
+	c.Assert(err, check.IsNil)
+	sched.wg.Wait()
+	c.Assert(*enabledRuns, check.Equals, 1)
+	c.Assert(*disabledRuns, check.Equals, 0)
+	err = sched.tick()
+	c.Assert(err, check.IsNil)
+	sched.wg.Wait()
+	c.Assert(*enabledRuns, check.Equals, 1)
+	c.Assert(eventtest.EventDesc{
+		Target:     Target("enabled-job"),
+		Kind:       EventKindRun,
+		LogMatches: "run 1",
+	}, eventtest.HasEvent)
+}
@@ -13,6 +13,7 @@ var (
 	PermAppAdmin                         = PermissionRegistry.get("app.admin")                           // [global app team pool]
 	PermAppAdminQuota                    = PermissionRegistry.get("app.admin.quota")                     // [global app team pool]
 	PermAppAdminRoutes                   = PermissionRegistry.get("app.admin.routes")                    // [global app team pool]
+	PermAppAdminShell                    = PermissionRegistry.get("app.admin.shell")                     // [global app team pool]
 	PermAppAdminUnlock                   = PermissionRegistry.get("app.admin.unlock")                    // [global app team pool]
 	PermAppBuild                         = PermissionRegistry.get("app.build")                           // [global app team pool]
 	PermAppCreate                        = PermissionRegistry.get("app.create")                          // [global team]
@@ -25,18 +26,28 @@ var (
 	PermAppDeployRollback                = PermissionRegistry.get("app.deploy.rollback")                 // [global app team pool]
 	PermAppDeployUpload                  = PermissionRegistry.get("app.deploy.upload")                   // [global app team pool]
 	PermAppRead                          = PermissionRegistry.get("app.read")                            // [global app team pool]
+	PermAppReadBuildSecret               = PermissionRegistry.get("app.read.build-secret")               // [global app team pool]
 	PermAppReadCertificate               = PermissionRegistry.get("app.read.certificate")                // [global app team pool]
 	PermAppReadDeploy                    = PermissionRegistry.get("app.read.deploy")                     // [global app team pool]
 	PermAppReadEnv                       = PermissionRegistry.get("app.read.env")                        // [global app team pool]
 	PermAppReadEvents                    = PermissionRegistry.get("app.read.events")                     // [global app team pool]
 	PermAppReadLog                       = PermissionRegistry.get("app.read.log")                        // [global app team pool]
 	PermAppReadMetric                    = PermissionRegistry.get("app.read.metric")                     // [global app team pool]
+	PermAppReadPipeline                  = PermissionRegistry.get("app.read.pipeline")                   // [global app team pool]
+	PermAppReadReviewApp                 = PermissionRegistry.get("app.read.review-app")                 // [global app team pool]
 	PermAppReadRouter                    = PermissionRegistry.get("app.read.router")                     // [global app team pool]
+	PermAppReadRouterPolicy              = PermissionRegistry.get("app.read.router-policy")              // [global app team pool]
+	PermAppReadTlsPolicy                 = PermissionRegistry.get("app.read.tls-policy")                 // [global app team pool]
+	PermAppReadUsage                     = PermissionRegistry.get("app.read.usage")                      // [global app team pool]
 	PermAppRun                           = PermissionRegistry.get("app.run")                             // [global app team pool]
 	PermAppRunShell                      = PermissionRegistry.get("app.run.shell")                       // [global app team pool]
 	PermAppUpdate                        = PermissionRegistry.get("app.update")                          // [global app team pool]
+	PermAppUpdateAccessLog               = PermissionRegistry.get("app.update.access-log")               // [global app team pool]
 	PermAppUpdateBind                    = PermissionRegistry.get("app.update.bind")                     // [global app team pool]
 	PermAppUpdateBindVolume              = PermissionRegistry.get("app.update.bind-volume")              // [global app team pool]
+	PermAppUpdateBuildSecret             = PermissionRegistry.get("app.update.build-secret")             // [global app team pool]
+	PermAppUpdateBuildSecretSet          = PermissionRegistry.get("app.update.build-secret.set")         // [global app team pool]
+	PermAppUpdateBuildSecretUnset        = PermissionRegistry.get("app.update.build-secret.unset")       // [global app team pool]
 	PermAppUpdateCertificate             = PermissionRegistry.get("app.update.certificate")              // [global app team pool]
 	PermAppUpdateCertificateSet          = PermissionRegistry.get("app.update.certificate.set")          // [global app team pool]
 	PermAppUpdateCertificateUnset        = PermissionRegistry.get("app.update.certificate.unset")        // [global app team pool]
@@ -45,35 +56,59 @@ var (
 	PermAppUpdateCnameRemove             = PermissionRegistry.get("app.update.cname.remove")             // [global app team pool]
 	PermAppUpdateDeploy                  = PermissionRegistry.get("app.update.deploy")                   // [global app team pool]
 	PermAppUpdateDeployRollback          = PermissionRegistry.get("app.update.deploy.rollback")          // [global app team pool]
+	PermAppUpdateDeployTimeouts          = PermissionRegistry.get("app.update.deploy.timeouts")          // [global app team pool]
+	PermAppUpdateDeployVerification      = PermissionRegistry.get("app.update.deploy.verification")      // [global app team pool]
 	PermAppUpdateDescription             = PermissionRegistry.get("app.update.description")              // [global app team pool]
 	PermAppUpdateEnv                     = PermissionRegistry.get("app.update.env")                      // [global app team pool]
+	PermAppUpdateEnvGroup                = PermissionRegistry.get("app.update.env-group")                // [global app team pool]
+	PermAppUpdateEnvGroupSubscribe       = PermissionRegistry.get("app.update.env-group.subscribe")      // [global app team pool]
+	PermAppUpdateEnvGroupUnsubscribe     = PermissionRegistry.get("app.update.env-group.unsubscribe")    // [global app team pool]
 	PermAppUpdateEnvSet                  = PermissionRegistry.get("app.update.env.set")                  // [global app team pool]
 	PermAppUpdateEnvUnset                = PermissionRegistry.get("app.update.env.unset")                // [global app team pool]
+	PermAppUpdateErrorPage               = PermissionRegistry.get("app.update.error-page")               // [global app team pool]
 	PermAppUpdateEvents                  = PermissionRegistry.get("app.update.events")                   // [global app team pool]
 	PermAppUpdateGrant                   = PermissionRegistry.get("app.update.grant")                    // [global app team pool]
 	PermAppUpdateImageReset              = PermissionRegistry.get("app.update.image-reset")              // [global app team pool]
 	PermAppUpdateLog                     = PermissionRegistry.get("app.update.log")                      // [global app team pool]
+	PermAppUpdateMirror                  = PermissionRegistry.get("app.update.mirror")                   // [global app team pool]
+	PermAppUpdateOwnership               = PermissionRegistry.get("app.update.ownership")                // [global app team pool]
+	PermAppUpdatePipeline                = PermissionRegistry.get("app.update.pipeline")                 // [global app team pool]
+	PermAppUpdatePipelineApprove         = PermissionRegistry.get("app.update.pipeline.approve")         // [global app team pool]
+	PermAppUpdatePipelineRun             = PermissionRegistry.get("app.update.pipeline.run")             // [global app team pool]
+	PermAppUpdatePipelineSet             = PermissionRegistry.get("app.update.pipeline.set")             // [global app team pool]
+	PermAppUpdatePipelineUnset           = PermissionRegistry.get("app.update.pipeline.unset")           // [global app team pool]
 	PermAppUpdatePlan                    = PermissionRegistry.get("app.update.plan")                     // [global app team pool]
 	PermAppUpdatePlatform                = PermissionRegistry.get("app.update.platform")                 // [global app team pool]
 	PermAppUpdatePool                    = PermissionRegistry.get("app.update.pool")                     // [global app team pool]
+	PermAppUpdateProtocol                = PermissionRegistry.get("app.update.protocol")                 // [global app team pool]
+	PermAppUpdateReconcile               = PermissionRegistry.get("app.update.reconcile")                // [global app team pool]
 	PermAppUpdateRestart                 = PermissionRegistry.get("app.update.restart")                  // [global app team pool]
+	PermAppUpdateReviewApp               = PermissionRegistry.get("app.update.review-app")               // [global app team pool]
+	PermAppUpdateReviewAppCreate         = PermissionRegistry.get("app.update.review-app.create")        // [global app team pool]
+	PermAppUpdateReviewAppRemove         = PermissionRegistry.get("app.update.review-app.remove")        // [global app team pool]
 	PermAppUpdateRevoke                  = PermissionRegistry.get("app.update.revoke")                   // [global app team pool]
 	PermAppUpdateRouter                  = PermissionRegistry.get("app.update.router")                   // [global app team pool]
+	PermAppUpdateRouterPolicy            = PermissionRegistry.get("app.update.router-policy")            // [global app team pool]
 	PermAppUpdateRouterAdd               = PermissionRegistry.get("app.update.router.add")               // [global app team pool]
 	PermAppUpdateRouterRemove            = PermissionRegistry.get("app.update.router.remove")            // [global app team pool]
 	PermAppUpdateRouterUpdate            = PermissionRegistry.get("app.update.router.update")            // [global app team pool]
 	PermAppUpdateSleep                   = PermissionRegistry.get("app.update.sleep")                    // [global app team pool]
 	PermAppUpdateStart                   = PermissionRegistry.get("app.update.start")                    // [global app team pool]
+	PermAppUpdateStickySession           = PermissionRegistry.get("app.update.sticky-session")           // [global app team pool]
 	PermAppUpdateStop                    = PermissionRegistry.get("app.update.stop")                     // [global app team pool]
 	PermAppUpdateSwap                    = PermissionRegistry.get("app.update.swap")                     // [global app team pool]
 	PermAppUpdateTags                    = PermissionRegistry.get("app.update.tags")                     // [global app team pool]
 	PermAppUpdateTeamowner               = PermissionRegistry.get("app.update.teamowner")                // [global app team pool]
+	PermAppUpdateTlsPolicy               = PermissionRegistry.get("app.update.tls-policy")               // [global app team pool]
 	PermAppUpdateUnbind                  = PermissionRegistry.get("app.update.unbind")                   // [global app team pool]
 	PermAppUpdateUnbindVolume            = PermissionRegistry.get("app.update.unbind-volume")            // [global app team pool]
 	PermAppUpdateUnit                    = PermissionRegistry.get("app.update.unit")                     // [global app team pool]
 	PermAppUpdateUnitAdd                 = PermissionRegistry.get("app.update.unit.add")                 // [global app team pool]
+	PermAppUpdateUnitAutoscale           = PermissionRegistry.get("app.update.unit.autoscale")           // [global app team pool]
+	PermAppUpdateUnitPlacement           = PermissionRegistry.get("app.update.unit.placement")           // [global app team pool]
 	PermAppUpdateUnitRegister            = PermissionRegistry.get("app.update.unit.register")            // [global app team pool]
 	PermAppUpdateUnitRemove              = PermissionRegistry.get("app.update.unit.remove")              // [global app team pool]
+	PermAppUpdateUnitSchedule            = PermissionRegistry.get("app.update.unit.schedule")            // [global app team pool]
 	PermAppUpdateUnitStatus              = PermissionRegistry.get("app.update.unit.status")              // [global app team pool]
 	PermCluster                          = PermissionRegistry.get("cluster")                             // [global]
 	PermClusterCreate                    = PermissionRegistry.get("cluster.create")                      // [global]
@@ -82,6 +117,15 @@ var (
 	PermClusterReadEvents                = PermissionRegistry.get("cluster.read.events")                 // [global]
 	PermClusterUpdate                    = PermissionRegistry.get("cluster.update")                      // [global]
 	PermDebug                            = PermissionRegistry.get("debug")                               // [global]
+	PermEnvGroup                         = PermissionRegistry.get("env-group")                           // [global team]
+	PermEnvGroupCreate                   = PermissionRegistry.get("env-group.create")                    // [global team]
+	PermEnvGroupDelete                   = PermissionRegistry.get("env-group.delete")                    // [global team]
+	PermEnvGroupRead                     = PermissionRegistry.get("env-group.read")                      // [global team]
+	PermEnvGroupReadEvents               = PermissionRegistry.get("env-group.read.events")               // [global team]
+	PermEnvGroupUpdate                   = PermissionRegistry.get("env-group.update")                    // [global team]
+	PermEnvGroupUpdateEnv                = PermissionRegistry.get("env-group.update.env")                // [global team]
+	PermEnvGroupUpdateEnvSet             = PermissionRegistry.get("env-group.update.env.set")            // [global team]
+	PermEnvGroupUpdateEnvUnset           = PermissionRegistry.get("env-group.update.env.unset")          // [global team]
 	PermEventBlock                       = PermissionRegistry.get("event-block")                         // [global]
 	PermEventBlockAdd                    = PermissionRegistry.get("event-block.add")                     // [global]
 	PermEventBlockRead                   = PermissionRegistry.get("event-block.read")                    // [global]
@@ -93,6 +137,11 @@ var (
 	PermHealingUpdate                    = PermissionRegistry.get("healing.update")                      // [global pool]
 	PermInstall                          = PermissionRegistry.get("install")                             // [global]
 	PermInstallManage                    = PermissionRegistry.get("install.manage")                      // [global]
+	PermJob                              = PermissionRegistry.get("job")                                 // [global]
+	PermJobRead                          = PermissionRegistry.get("job.read")                            // [global]
+	PermJobReadEvents                    = PermissionRegistry.get("job.read.events")                     // [global]
+	PermJobRun                           = PermissionRegistry.get("job.run")                             // [global]
+	PermJobUpdate                        = PermissionRegistry.get("job.update")                          // [global]
 	PermMachine                          = PermissionRegistry.get("machine")                             // [global iaas]
 	PermMachineDelete                    = PermissionRegistry.get("machine.delete")                      // [global iaas]
 	PermMachineRead                      = PermissionRegistry.get("machine.read")                        // [global iaas]
@@ -111,7 +160,9 @@ var (
 	PermNodeCreate                       = PermissionRegistry.get("node.create")                         // [global pool]
 	PermNodeDelete                       = PermissionRegistry.get("node.delete")                         // [global pool]
 	PermNodeRead                         = PermissionRegistry.get("node.read")                           // [global pool]
+	PermNodeReadAgent                    = PermissionRegistry.get("node.read.agent")                     // [global pool]
 	PermNodeUpdate                       = PermissionRegistry.get("node.update")                         // [global pool]
+	PermNodeUpdateAgent                  = PermissionRegistry.get("node.update.agent")                   // [global pool]
 	PermNodeUpdateMove                   = PermissionRegistry.get("node.update.move")                    // [global pool]
 	PermNodeUpdateMoveContainer          = PermissionRegistry.get("node.update.move.container")          // [global pool]
 	PermNodeUpdateMoveContainers         = PermissionRegistry.get("node.update.move.containers")         // [global pool]
@@ -120,8 +171,14 @@ var (
 	PermNodecontainerCreate              = PermissionRegistry.get("nodecontainer.create")                // [global pool]
 	PermNodecontainerDelete              = PermissionRegistry.get("nodecontainer.delete")                // [global pool]
 	PermNodecontainerRead                = PermissionRegistry.get("nodecontainer.read")                  // [global pool]
+	PermNodecontainerReadLogs            = PermissionRegistry.get("nodecontainer.read.logs")             // [global pool]
 	PermNodecontainerUpdate              = PermissionRegistry.get("nodecontainer.update")                // [global pool]
+	PermNodecontainerUpdateSecret        = PermissionRegistry.get("nodecontainer.update.secret")         // [global pool]
+	PermNodecontainerUpdateToken         = PermissionRegistry.get("nodecontainer.update.token")          // [global pool]
 	PermNodecontainerUpdateUpgrade       = PermissionRegistry.get("nodecontainer.update.upgrade")        // [global pool]
+	PermOrphan                           = PermissionRegistry.get("orphan")                              // [global]
+	PermOrphanDelete                     = PermissionRegistry.get("orphan.delete")                       // [global]
+	PermOrphanRead                       = PermissionRegistry.get("orphan.read")                         // [global]
 	PermPlan                             = PermissionRegistry.get("plan")                                // [global]
 	PermPlanCreate                       = PermissionRegistry.get("plan.create")                         // [global]
 	PermPlanDelete                       = PermissionRegistry.get("plan.delete")                         // [global]
@@ -137,15 +194,25 @@ var (
 	PermPoolCreate                       = PermissionRegistry.get("pool.create")                         // [global]
 	PermPoolDelete                       = PermissionRegistry.get("pool.delete")                         // [global pool]
 	PermPoolRead                         = PermissionRegistry.get("pool.read")                           // [global pool]
+	PermPoolReadCapacity                 = PermissionRegistry.get("pool.read.capacity")                  // [global pool]
 	PermPoolReadConstraints              = PermissionRegistry.get("pool.read.constraints")               // [global pool]
 	PermPoolReadEvents                   = PermissionRegistry.get("pool.read.events")                    // [global pool]
+	PermPoolReadScheduler                = PermissionRegistry.get("pool.read.scheduler")                 // [global pool]
 	PermPoolUpdate                       = PermissionRegistry.get("pool.update")                         // [global pool]
 	PermPoolUpdateConstraints            = PermissionRegistry.get("pool.update.constraints")             // [global pool]
 	PermPoolUpdateConstraintsSet         = PermissionRegistry.get("pool.update.constraints.set")         // [global pool]
+	PermPoolUpdateDns                    = PermissionRegistry.get("pool.update.dns")                     // [global pool]
+	PermPoolUpdateEnv                    = PermissionRegistry.get("pool.update.env")                     // [global pool]
 	PermPoolUpdateLogs                   = PermissionRegistry.get("pool.update.logs")                    // [global pool]
+	PermPoolUpdateScheduler              = PermissionRegistry.get("pool.update.scheduler")               // [global pool]
 	PermPoolUpdateTeam                   = PermissionRegistry.get("pool.update.team")                    // [global pool]
 	PermPoolUpdateTeamAdd                = PermissionRegistry.get("pool.update.team.add")                // [global pool]
 	PermPoolUpdateTeamRemove             = PermissionRegistry.get("pool.update.team.remove")             // [global pool]
+	PermPoolUpdateTlsPolicy              = PermissionRegistry.get("pool.update.tls-policy")              // [global pool]
+	PermRetryOperation                   = PermissionRegistry.get("retry-operation")                     // [global]
+	PermRetryOperationRead               = PermissionRegistry.get("retry-operation.read")                // [global]
+	PermRetryOperationUpdate             = PermissionRegistry.get("retry-operation.update")              // [global]
+	PermRetryOperationUpdateRequeue      = PermissionRegistry.get("retry-operation.update.requeue")      // [global]
 	PermRole                             = PermissionRegistry.get("role")                                // [global]
 	PermRoleCreate                       = PermissionRegistry.get("role.create")                         // [global]
 	PermRoleDefault                      = PermissionRegistry.get("role.default")                        // [global]
@@ -169,14 +236,17 @@ var (
 	PermServiceInstanceCreate            = PermissionRegistry.get("service-instance.create")             // [global team]
 	PermServiceInstanceDelete            = PermissionRegistry.get("service-instance.delete")             // [global service-instance team]
 	PermServiceInstanceRead              = PermissionRegistry.get("service-instance.read")               // [global service-instance team]
+	PermServiceInstanceReadClone         = PermissionRegistry.get("service-instance.read.clone")         // [global service-instance team]
 	PermServiceInstanceReadEvents        = PermissionRegistry.get("service-instance.read.events")        // [global service-instance team]
 	PermServiceInstanceReadStatus        = PermissionRegistry.get("service-instance.read.status")        // [global service-instance team]
 	PermServiceInstanceUpdate            = PermissionRegistry.get("service-instance.update")             // [global service-instance team]
 	PermServiceInstanceUpdateBind        = PermissionRegistry.get("service-instance.update.bind")        // [global service-instance team]
 	PermServiceInstanceUpdateDescription = PermissionRegistry.get("service-instance.update.description") // [global service-instance team]
 	PermServiceInstanceUpdateGrant       = PermissionRegistry.get("service-instance.update.grant")       // [global service-instance team]
+	PermServiceInstanceUpdateParameters  = PermissionRegistry.get("service-instance.update.parameters")  // [global service-instance team]
 	PermServiceInstanceUpdatePlan        = PermissionRegistry.get("service-instance.update.plan")        // [global service-instance team]
 	PermServiceInstanceUpdateProxy       = PermissionRegistry.get("service-instance.update.proxy")       // [global service-instance team]
+	PermServiceInstanceUpdateReconcile   = PermissionRegistry.get("service-instance.update.reconcile")   // [global service-instance team]
 	PermServiceInstanceUpdateRevoke      = PermissionRegistry.get("service-instance.update.revoke")      // [global service-instance team]
 	PermServiceInstanceUpdateTags        = PermissionRegistry.get("service-instance.update.tags")        // [global service-instance team]
 	PermServiceInstanceUpdateTeamowner   = PermissionRegistry.get("service-instance.update.teamowner")   // [global service-instance team]
@@ -187,6 +257,7 @@ var (
 	PermServiceReadDoc                   = PermissionRegistry.get("service.read.doc")                    // [global service team]
 	PermServiceReadEvents                = PermissionRegistry.get("service.read.events")                 // [global service team]
 	PermServiceReadPlans                 = PermissionRegistry.get("service.read.plans")                  // [global service team]
+	PermServiceReadSchema                = PermissionRegistry.get("service.read.schema")                 // [global service team]
 	PermServiceUpdate                    = PermissionRegistry.get("service.update")                      // [global service team]
 	PermServiceUpdateDoc                 = PermissionRegistry.get("service.update.doc")                  // [global service team]
 	PermServiceUpdateGrantAccess         = PermissionRegistry.get("service.update.grant-access")         // [global service team]
@@ -201,15 +272,19 @@ var (
 	PermUser                             = PermissionRegistry.get("user")                                // [global user]
 	PermUserCreate                       = PermissionRegistry.get("user.create")                         // [global]
 	PermUserDelete                       = PermissionRegistry.get("user.delete")                         // [global user]
+	PermUserImpersonate                  = PermissionRegistry.get("user.impersonate")                    // [global user]
+	PermUserProvision                    = PermissionRegistry.get("user.provision")                      // [global]
 	PermUserRead                         = PermissionRegistry.get("user.read")                           // [global user]
 	PermUserReadEvents                   = PermissionRegistry.get("user.read.events")                    // [global user]
 	PermUserUpdate                       = PermissionRegistry.get("user.update")                         // [global user]
 	PermUserUpdateKey                    = PermissionRegistry.get("user.update.key")                     // [global user]
 	PermUserUpdateKeyAdd                 = PermissionRegistry.get("user.update.key.add")                 // [global user]
 	PermUserUpdateKeyRemove              = PermissionRegistry.get("user.update.key.remove")              // [global user]
+	PermUserUpdateLockout                = PermissionRegistry.get("user.update.lockout")                 // [global user]
 	PermUserUpdatePassword               = PermissionRegistry.get("user.update.password")                // [global user]
 	PermUserUpdateQuota                  = PermissionRegistry.get("user.update.quota")                   // [global user]
 	PermUserUpdateReset                  = PermissionRegistry.get("user.update.reset")                   // [global user]
+	PermUserUpdateSessions               = PermissionRegistry.get("user.update.sessions")                // [global user]
 	PermUserUpdateToken                  = PermissionRegistry.get("user.update.token")                   // [global user]
 	PermVolume                           = PermissionRegistry.get("volume")                              // [global volume team pool]
 	PermVolumeCreate                     = PermissionRegistry.get("volume.create")                       // [global team pool]
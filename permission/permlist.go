@@ -12,6 +12,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.create", []contextType{CtxTeam},
 ).add(
 	"app.update.description",
+	"app.update.ownership",
 	"app.update.tags",
 	"app.update.log",
 	"app.update.pool",
@@ -19,9 +20,23 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.unit.remove",
 	"app.update.unit.register",
 	"app.update.unit.status",
+	"app.update.unit.schedule",
+	"app.update.unit.autoscale",
+	"app.update.unit.placement",
 	"app.update.env.set",
 	"app.update.env.unset",
+	"app.update.env-group.subscribe",
+	"app.update.env-group.unsubscribe",
+	"app.update.build-secret.set",
+	"app.update.build-secret.unset",
+	"app.update.pipeline.set",
+	"app.update.pipeline.unset",
+	"app.update.pipeline.run",
+	"app.update.pipeline.approve",
+	"app.update.review-app.create",
+	"app.update.review-app.remove",
 	"app.update.restart",
+	"app.update.reconcile",
 	"app.update.sleep",
 	"app.update.start",
 	"app.update.stop",
@@ -42,6 +57,15 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.certificate.set",
 	"app.update.certificate.unset",
 	"app.update.deploy.rollback",
+	"app.update.deploy.timeouts",
+	"app.update.deploy.verification",
+	"app.update.tls-policy",
+	"app.update.protocol",
+	"app.update.sticky-session",
+	"app.update.router-policy",
+	"app.update.mirror",
+	"app.update.access-log",
+	"app.update.error-page",
 	"app.update.router.add",
 	"app.update.router.update",
 	"app.update.router.remove",
@@ -56,25 +80,34 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.read.deploy",
 	"app.read.router",
 	"app.read.env",
+	"app.read.build-secret",
+	"app.read.pipeline",
+	"app.read.review-app",
 	"app.read.events",
 	"app.read.metric",
+	"app.read.usage",
 	"app.read.log",
 	"app.read.certificate",
+	"app.read.tls-policy",
+	"app.read.router-policy",
 	"app.delete",
 	"app.run",
 	"app.run.shell",
 	"app.admin.unlock",
 	"app.admin.routes",
 	"app.admin.quota",
+	"app.admin.shell",
 	"app.build",
 ).addWithCtx(
 	"node", []contextType{CtxPool},
 ).add(
 	"node.create",
 	"node.read",
+	"node.read.agent",
 	"node.update.move.container",
 	"node.update.move.containers",
 	"node.update.rebalance",
+	"node.update.agent",
 	"node.delete",
 ).addWithCtx(
 	"node.autoscale", []contextType{},
@@ -105,8 +138,11 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"user", []contextType{CtxUser},
 ).addWithCtx(
 	"user.create", []contextType{},
+).addWithCtx(
+	"user.provision", []contextType{},
 ).add(
 	"user.delete",
+	"user.impersonate",
 	"user.read.events",
 	"user.update.token",
 	"user.update.quota",
@@ -114,6 +150,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"user.update.reset",
 	"user.update.key.add",
 	"user.update.key.remove",
+	"user.update.sessions",
+	"user.update.lockout",
 ).addWithCtx(
 	"service", []contextType{CtxService, CtxTeam},
 ).addWithCtx(
@@ -121,6 +159,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 ).add(
 	"service.read.doc",
 	"service.read.plans",
+	"service.read.schema",
 	"service.read.events",
 	"service.update.proxy",
 	"service.update.revoke-access",
@@ -134,6 +173,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 ).add(
 	"service-instance.read.events",
 	"service-instance.read.status",
+	"service-instance.read.clone",
 	"service-instance.delete",
 	"service-instance.update.proxy",
 	"service-instance.update.bind",
@@ -144,6 +184,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"service-instance.update.tags",
 	"service-instance.update.teamowner",
 	"service-instance.update.plan",
+	"service-instance.update.parameters",
+	"service-instance.update.reconcile",
 ).add(
 	"role.create",
 	"role.delete",
@@ -176,7 +218,13 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"pool.update.team.remove",
 	"pool.update.constraints.set",
 	"pool.read.constraints",
+	"pool.read.capacity",
 	"pool.update.logs",
+	"pool.read.scheduler",
+	"pool.update.scheduler",
+	"pool.update.tls-policy",
+	"pool.update.env",
+	"pool.update.dns",
 	"pool.delete",
 ).add(
 	"debug",
@@ -193,8 +241,11 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 ).add(
 	"nodecontainer.create",
 	"nodecontainer.read",
+	"nodecontainer.read.logs",
 	"nodecontainer.update",
 	"nodecontainer.update.upgrade",
+	"nodecontainer.update.token",
+	"nodecontainer.update.secret",
 	"nodecontainer.delete",
 ).add(
 	"install.manage",
@@ -203,6 +254,17 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"event-block.read.events",
 	"event-block.add",
 	"event-block.remove",
+).add(
+	"retry-operation.read",
+	"retry-operation.update.requeue",
+).add(
+	"orphan.read",
+	"orphan.delete",
+).add(
+	"job.read",
+	"job.read.events",
+	"job.update",
+	"job.run",
 ).add(
 	"cluster.read.events",
 	"cluster.create",
@@ -217,4 +279,14 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"volume.update.bind",
 	"volume.update.unbind",
 	"volume.delete",
+).addWithCtx(
+	"env-group", []contextType{CtxTeam},
+).addWithCtx(
+	"env-group.create", []contextType{CtxTeam},
+).add(
+	"env-group.read",
+	"env-group.read.events",
+	"env-group.update.env.set",
+	"env-group.update.env.unset",
+	"env-group.delete",
 )
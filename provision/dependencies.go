@@ -0,0 +1,137 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provision
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrProcessDependencyCycle is returned when the dependencies between the
+// processes of an app form a cycle.
+type ErrProcessDependencyCycle struct {
+	Processes []string
+}
+
+func (e ErrProcessDependencyCycle) Error() string {
+	return "cycle in process dependencies: " + strings.Join(e.Processes, " -> ")
+}
+
+// ValidateDependencies checks that the dependencies only reference the given
+// processes and don't form a cycle.
+func (d TsuruYamlData) ValidateDependencies(processes []string) error {
+	known := make(map[string]bool, len(processes))
+	for _, p := range processes {
+		known[p] = true
+	}
+	for _, process := range sortedKeys(d.Dependencies) {
+		if !known[process] {
+			return errors.Errorf("dependencies declared for unknown process %q", process)
+		}
+		for _, dep := range d.Dependencies[process] {
+			if !known[dep] {
+				return errors.Errorf("process %q depends on unknown process %q", process, dep)
+			}
+		}
+	}
+	_, err := d.ProcessStartOrder(processes)
+	return err
+}
+
+// ProcessStartOrder groups the given processes in the order they must be
+// started, each group only depending on processes in previous groups.
+// Dependencies on processes not in the list are ignored, as they're not
+// being started. Processes in each group are sorted by name.
+func (d TsuruYamlData) ProcessStartOrder(processes []string) ([][]string, error) {
+	pending := make(map[string][]string, len(processes))
+	for _, p := range processes {
+		pending[p] = nil
+	}
+	for p := range pending {
+		for _, dep := range d.Dependencies[p] {
+			if _, ok := pending[dep]; ok && dep != p {
+				pending[p] = append(pending[p], dep)
+			} else if dep == p {
+				return nil, ErrProcessDependencyCycle{Processes: []string{p, p}}
+			}
+		}
+	}
+	var groups [][]string
+	started := make(map[string]bool, len(processes))
+	for len(pending) > 0 {
+		var group []string
+		for p, deps := range pending {
+			ready := true
+			for _, dep := range deps {
+				if !started[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				group = append(group, p)
+			}
+		}
+		if len(group) == 0 {
+			return nil, ErrProcessDependencyCycle{Processes: findCycle(pending)}
+		}
+		sort.Strings(group)
+		for _, p := range group {
+			started[p] = true
+			delete(pending, p)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// findCycle returns a cycle among the pending processes, which must have at
+// least one, starting and ending with the same process.
+func findCycle(pending map[string][]string) []string {
+	names := sortedKeys(pending)
+	visited := map[string]int{}
+	var path []string
+	var visit func(p string) []string
+	visit = func(p string) []string {
+		if idx, ok := visited[p]; ok {
+			if idx >= 0 {
+				return append(append([]string{}, path[idx:]...), p)
+			}
+			return nil
+		}
+		visited[p] = len(path)
+		path = append(path, p)
+		deps := append([]string{}, pending[p]...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, ok := pending[dep]; !ok {
+				continue
+			}
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		visited[p] = -1
+		return nil
+	}
+	for _, p := range names {
+		if cycle := visit(p); cycle != nil {
+			return cycle
+		}
+	}
+	return names
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
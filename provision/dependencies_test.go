@@ -0,0 +1,55 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provision
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestProcessStartOrder(c *check.C) {
+	data := TsuruYamlData{Dependencies: map[string][]string{
+		"web":    {"migrate"},
+		"worker": {"web", "migrate"},
+		"other":  {"missing"},
+	}}
+	groups, err := data.ProcessStartOrder([]string{"worker", "web", "migrate", "other", "cron"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.DeepEquals, [][]string{
+		{"cron", "migrate", "other"},
+		{"web"},
+		{"worker"},
+	})
+	groups, err = data.ProcessStartOrder([]string{"worker", "migrate"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.DeepEquals, [][]string{{"migrate"}, {"worker"}})
+	groups, err = TsuruYamlData{}.ProcessStartOrder([]string{"web", "worker"})
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.DeepEquals, [][]string{{"web", "worker"}})
+}
+
+func (s *S) TestProcessStartOrderCycle(c *check.C) {
+	data := TsuruYamlData{Dependencies: map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+		"d": {"a"},
+	}}
+	_, err := data.ProcessStartOrder([]string{"a", "b", "c", "d", "e"})
+	c.Assert(err, check.DeepEquals, ErrProcessDependencyCycle{Processes: []string{"a", "b", "c", "a"}})
+	c.Assert(err, check.ErrorMatches, "cycle in process dependencies: a -> b -> c -> a")
+	_, err = data.ProcessStartOrder([]string{"a", "b", "d"})
+	c.Assert(err, check.IsNil)
+	_, err = TsuruYamlData{Dependencies: map[string][]string{"web": {"web"}}}.ProcessStartOrder([]string{"web"})
+	c.Assert(err, check.ErrorMatches, "cycle in process dependencies: web -> web")
+}
+
+func (s *S) TestValidateDependencies(c *check.C) {
+	data := TsuruYamlData{Dependencies: map[string][]string{"web": {"migrate"}}}
+	c.Assert(data.ValidateDependencies([]string{"web", "migrate"}), check.IsNil)
+	c.Assert(data.ValidateDependencies([]string{"web"}), check.ErrorMatches, `process "web" depends on unknown process "migrate"`)
+	c.Assert(data.ValidateDependencies([]string{"migrate"}), check.ErrorMatches, `dependencies declared for unknown process "web"`)
+	data = TsuruYamlData{Dependencies: map[string][]string{"web": {"worker"}, "worker": {"web"}}}
+	c.Assert(data.ValidateDependencies([]string{"web", "worker"}), check.ErrorMatches, "cycle in process dependencies: web -> worker -> web")
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provision
+
+import (
+	"sync"
+
+	"github.com/tsuru/tsuru/event"
+)
+
+// DeployPhase identifies a step of a deploy that may have its duration
+// limited.
+type DeployPhase string
+
+const (
+	DeployPhaseBuild       = DeployPhase("build")
+	DeployPhaseImagePush   = DeployPhase("image-push")
+	DeployPhaseUnitStart   = DeployPhase("unit-start")
+	DeployPhaseHealthcheck = DeployPhase("healthcheck")
+)
+
+// DeployPhases lists all phases of a deploy, in the order they're executed.
+var DeployPhases = []DeployPhase{
+	DeployPhaseBuild,
+	DeployPhaseImagePush,
+	DeployPhaseUnitStart,
+	DeployPhaseHealthcheck,
+}
+
+// DeployPhaseFunc is called every time a new phase starts in a deploy.
+type DeployPhaseFunc func(phase DeployPhase)
+
+var phaseWatchers = struct {
+	sync.Mutex
+	m map[*event.Event]DeployPhaseFunc
+}{m: map[*event.Event]DeployPhaseFunc{}}
+
+// WatchDeployPhases registers fn to be called when provisioners and builders
+// start a new phase of the deploy identified by evt. The returned function
+// must be called to stop watching.
+func WatchDeployPhases(evt *event.Event, fn DeployPhaseFunc) func() {
+	phaseWatchers.Lock()
+	defer phaseWatchers.Unlock()
+	phaseWatchers.m[evt] = fn
+	return func() {
+		phaseWatchers.Lock()
+		defer phaseWatchers.Unlock()
+		delete(phaseWatchers.m, evt)
+	}
+}
+
+// StartDeployPhase notifies that a phase of the deploy identified by evt has
+// started. It's a no-op if no one is watching the deploy.
+func StartDeployPhase(evt *event.Event, phase DeployPhase) {
+	if evt == nil {
+		return
+	}
+	phaseWatchers.Lock()
+	fn := phaseWatchers.m[evt]
+	phaseWatchers.Unlock()
+	if fn != nil {
+		fn(phase)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package provision
+
+import (
+	"github.com/tsuru/tsuru/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestWatchDeployPhases(c *check.C) {
+	evt := &event.Event{}
+	otherEvt := &event.Event{}
+	var phases []DeployPhase
+	unwatch := WatchDeployPhases(evt, func(phase DeployPhase) {
+		phases = append(phases, phase)
+	})
+	StartDeployPhase(evt, DeployPhaseBuild)
+	StartDeployPhase(otherEvt, DeployPhaseImagePush)
+	StartDeployPhase(nil, DeployPhaseImagePush)
+	StartDeployPhase(evt, DeployPhaseUnitStart)
+	unwatch()
+	StartDeployPhase(evt, DeployPhaseHealthcheck)
+	c.Assert(phases, check.DeepEquals, []DeployPhase{DeployPhaseBuild, DeployPhaseUnitStart})
+}
@@ -12,16 +12,20 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fsouza/go-dockerclient"
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/action"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/container"
 	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/router"
 )
 
@@ -37,11 +41,15 @@ type runContainerActionsArgs struct {
 	provisioner      *dockerProvisioner
 	exposedPort      string
 	event            *event.Event
+	checkpoint       *unitCheckpoint
 }
 
 type containersToAdd struct {
 	Quantity int
 	Status   provision.Status
+	// Checkpoint, only set when moving a single unit, is used to restore
+	// the memory state of the unit being replaced.
+	Checkpoint *unitCheckpoint
 }
 
 type changeUnitsPipelineArgs struct {
@@ -281,6 +289,14 @@ var startContainer = action.Action{
 			return nil, err
 		}
 		c := ctx.Previous.(*container.Container)
+		if args.checkpoint != nil {
+			log.Debugf("restoring container %s from checkpoint %s", c.ID, args.checkpoint.Name)
+			err := args.provisioner.restoreContainer(c, args.checkpoint)
+			if err == nil {
+				return c, nil
+			}
+			log.Errorf("error restoring container %s, falling back to regular start: %s", c.ID, err)
+		}
 		log.Debugf("starting container %s", c.ID)
 		err := c.Start(&container.StartArgs{
 			Client:  args.provisioner.ClusterClient(),
@@ -311,6 +327,79 @@ var rollbackNotice = func(ctx action.FWContext, err error) {
 	}
 }
 
+const defaultImagePreSeedTimeout = 5 * time.Minute
+
+func imagePreSeedEnabled() bool {
+	enabled, _ := config.GetBool("docker:image-preseed:enabled")
+	return enabled
+}
+
+func imagePreSeedTimeout() time.Duration {
+	seconds, _ := config.GetInt("docker:image-preseed:timeout")
+	if seconds <= 0 {
+		return defaultImagePreSeedTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// preSeedImage pulls the image being deployed in the nodes expected to
+// receive the new units before any unit is replaced, so cold nodes don't slow
+// down the start of the new units. Only the layers missing in each node are
+// downloaded. Failures are only logged, as the image is pulled again when
+// units are created.
+var preSeedImage = action.Action{
+	Name: "pre-seed-image",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args := ctx.Params[0].(changeUnitsPipelineArgs)
+		if !imagePreSeedEnabled() {
+			return nil, nil
+		}
+		if err := checkCanceled(args.event); err != nil {
+			return nil, err
+		}
+		w := args.writer
+		if w == nil {
+			w = ioutil.Discard
+		}
+		a, err := app.GetByName(args.app.GetName())
+		if err != nil {
+			log.Errorf("[image pre-seed] unable to get app %q: %s", args.app.GetName(), err)
+			return nil, nil
+		}
+		nodes, err := args.provisioner.scheduler.planNodes(a, args.toAdd, args.toHost)
+		if err != nil {
+			log.Errorf("[image pre-seed] unable to plan nodes for app %q: %s", a.Name, err)
+			return nil, nil
+		}
+		if len(nodes) == 0 {
+			return nil, nil
+		}
+		fmt.Fprintf(w, "\n---- Pre-seeding image in %d %s ----\n", len(nodes), pluralize("node", len(nodes)))
+		repo, tag := image.SplitImageName(dockercommon.PullImageName(args.imageID, a.Pool))
+		pullOpts := docker.PullImageOptions{
+			Repository:        repo,
+			Tag:               tag,
+			InactivityTimeout: net.StreamInactivityTimeout,
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- args.provisioner.Cluster().PullImage(pullOpts, dockercommon.RegistryAuthConfig(), nodes...)
+		}()
+		select {
+		case err = <-done:
+		case <-time.After(imagePreSeedTimeout()):
+			err = errors.New("timeout pre-seeding image")
+		}
+		if err != nil {
+			log.Errorf("[image pre-seed] unable to pre-seed image %q for app %q: %s", args.imageID, a.Name, err)
+			fmt.Fprintf(w, " ---> Unable to pre-seed image, it will be pulled when units are started: %s\n", err)
+			return nil, nil
+		}
+		fmt.Fprintf(w, " ---> Pre-seeded image %s\n", args.imageID)
+		return nil, nil
+	},
+}
+
 var provisionAddUnitsToHost = action.Action{
 	Name: "provision-add-units-to-host",
 	Forward: func(ctx action.FWContext) (action.Result, error) {
@@ -354,6 +443,7 @@ var bindAndHealthcheck = action.Action{
 		if err := checkCanceled(args.event); err != nil {
 			return nil, err
 		}
+		provision.StartDeployPhase(args.event, provision.DeployPhaseHealthcheck)
 		webProcessName, err := image.GetImageWebProcessName(args.imageID)
 		if err != nil {
 			log.Errorf("[WARNING] cannot get the name of the web process: %s", err)
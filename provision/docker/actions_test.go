@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"bytes"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -719,6 +720,54 @@ func (s *S) TestProvisionAddUnitsToHostName(c *check.C) {
 	c.Assert(provisionAddUnitsToHost.Name, check.Equals, "provision-add-units-to-host")
 }
 
+func (s *S) TestPreSeedImageForward(c *check.C) {
+	config.Set("docker:image-preseed:enabled", true)
+	defer config.Unset("docker:image-preseed:enabled")
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	a := &app.App{Name: "myapp-2", Platform: "python", Pool: "test-default"}
+	err = s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	imageID, err := image.AppNewImageName(a.Name)
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	args := changeUnitsPipelineArgs{
+		app:         a,
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		imageID:     imageID,
+		provisioner: p,
+		writer:      &buf,
+	}
+	context := action.FWContext{Params: []interface{}{args}}
+	_, err = preSeedImage.Forward(context)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s).*Pre-seeding image in 1 node.*Pre-seeded image `+imageID+`.*`)
+	client, err := docker.NewClient(s.extraServer.URL())
+	c.Assert(err, check.IsNil)
+	_, err = client.InspectImage(imageID)
+	c.Assert(err, check.IsNil)
+	client, err = docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	_, err = client.InspectImage(imageID)
+	c.Assert(err, check.Equals, docker.ErrNoSuchImage)
+}
+
+func (s *S) TestPreSeedImageForwardDisabled(c *check.C) {
+	var buf bytes.Buffer
+	args := changeUnitsPipelineArgs{
+		app:         provisiontest.NewFakeApp("myapp-2", "python", 0),
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		imageID:     "tsuru/app-myapp-2:v1",
+		provisioner: s.p,
+		writer:      &buf,
+	}
+	context := action.FWContext{Params: []interface{}{args}}
+	_, err := preSeedImage.Forward(context)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "")
+}
+
 func (s *S) TestProvisionAddUnitsToHostForward(c *check.C) {
 	p, err := s.startMultipleServersCluster()
 	c.Assert(err, check.IsNil)
@@ -0,0 +1,142 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+)
+
+const checkpointTimeout = 2 * time.Minute
+
+var errCheckpointUnsupported = errors.New("docker daemon doesn't support checkpoints, experimental features must be enabled")
+
+// unitCheckpoint is a checkpoint of the memory state of a running unit,
+// created with CRIU and stored in a directory shared by every node, so the
+// unit can be restored in another node.
+type unitCheckpoint struct {
+	Name string
+	Dir  string
+}
+
+// liveMigrationDir returns the directory, shared by every node, where
+// checkpoints are stored. An empty value means live migration is disabled.
+func liveMigrationDir() string {
+	enabled, _ := config.GetBool("docker:live-migration:enabled")
+	if !enabled {
+		return ""
+	}
+	dir, _ := config.GetString("docker:live-migration:checkpoint-dir")
+	return dir
+}
+
+// checkpointContainer creates a checkpoint of a running container, leaving
+// it running so it keeps serving requests until its replacement is ready.
+func (p *dockerProvisioner) checkpointContainer(c container.Container, dir string) (*unitCheckpoint, error) {
+	if c.ExpectedStatus() != provision.StatusStarted {
+		return nil, errors.Errorf("unit %s is not running", c.ID)
+	}
+	client, err := p.nodeClient(c.HostAddr)
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Info()
+	if err != nil {
+		return nil, err
+	}
+	if !info.ExperimentalBuild {
+		return nil, errCheckpointUnsupported
+	}
+	checkpoint := &unitCheckpoint{
+		Name: fmt.Sprintf("move-%s-%d", c.ShortID(), time.Now().Unix()),
+		Dir:  dir,
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"CheckpointID":  checkpoint.Name,
+		"CheckpointDir": checkpoint.Dir,
+		"Exit":          false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = doDockerRequest(client, http.MethodPost, "/containers/"+c.ID+"/checkpoints", nil, body, http.StatusCreated)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to checkpoint unit %s", c.ID)
+	}
+	return checkpoint, nil
+}
+
+// restoreContainer starts a container from the given checkpoint, removing
+// the checkpoint once it's restored.
+func (p *dockerProvisioner) restoreContainer(c *container.Container, checkpoint *unitCheckpoint) error {
+	client, err := p.nodeClient(c.HostAddr)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("checkpoint", checkpoint.Name)
+	params.Set("checkpoint-dir", checkpoint.Dir)
+	done := p.ActionLimiter().Start(c.HostAddr)
+	err = doDockerRequest(client, http.MethodPost, "/containers/"+c.ID+"/start", params, nil, http.StatusNoContent)
+	done()
+	if err != nil {
+		return errors.Wrapf(err, "unable to restore unit %s from checkpoint %s", c.ID, checkpoint.Name)
+	}
+	// Removing the checkpoint is best effort, the unit is already running.
+	params = url.Values{}
+	params.Set("dir", checkpoint.Dir)
+	doDockerRequest(client, http.MethodDelete, "/containers/"+c.ID+"/checkpoints/"+checkpoint.Name, params, nil, http.StatusNoContent)
+	return c.SetStatus(p.ClusterClient(), provision.StatusStarting, false)
+}
+
+func (p *dockerProvisioner) nodeClient(host string) (*docker.Client, error) {
+	node, err := dockercommon.GetNodeByHost(p.Cluster(), host)
+	if err != nil {
+		return nil, err
+	}
+	return node.Client()
+}
+
+// doDockerRequest calls docker API endpoints not supported by the docker
+// client, like the experimental checkpoint ones.
+func doDockerRequest(client *docker.Client, method, path string, params url.Values, body []byte, expectedStatus int) error {
+	u := strings.TrimSuffix(client.Endpoint(), "/") + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), checkpointTimeout)
+	defer cancel()
+	rsp, err := client.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != expectedStatus {
+		var buf bytes.Buffer
+		buf.ReadFrom(rsp.Body)
+		return errors.Errorf("unexpected status code %d: %s", rsp.StatusCode, strings.TrimSpace(buf.String()))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCheckpointAndRestoreContainer(c *check.C) {
+	var requests []string
+	var checkpointBody map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.String())
+		switch {
+		case r.URL.Path == "/info":
+			json.NewEncoder(w).Encode(map[string]interface{}{"ExperimentalBuild": true})
+		case r.Method == http.MethodPost && r.URL.Path == "/containers/cont1/checkpoints":
+			data, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(data, &checkpointBody)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+	clusterInstance, err := cluster.New(nil, &cluster.MapStorage{}, "", cluster.Node{Address: srv.URL})
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	cont := container.Container{Container: types.Container{ID: "cont1", HostAddr: "127.0.0.1", Status: provision.StatusStarted.String()}}
+	checkpoint, err := s.p.checkpointContainer(cont, "/shared/checkpoints")
+	c.Assert(err, check.IsNil)
+	c.Assert(checkpoint.Dir, check.Equals, "/shared/checkpoints")
+	c.Assert(checkpoint.Name, check.Matches, "move-cont1-[0-9]+")
+	c.Assert(checkpointBody, check.DeepEquals, map[string]interface{}{
+		"CheckpointID":  checkpoint.Name,
+		"CheckpointDir": "/shared/checkpoints",
+		"Exit":          false,
+	})
+	newCont := container.Container{Container: types.Container{ID: "cont2", HostAddr: "127.0.0.1"}}
+	err = s.p.restoreContainer(&newCont, checkpoint)
+	c.Assert(err, check.IsNil)
+	c.Assert(newCont.Status, check.Equals, provision.StatusStarting.String())
+	c.Assert(requests, check.DeepEquals, []string{
+		"GET /info",
+		"POST /containers/cont1/checkpoints",
+		"POST /containers/cont2/start?checkpoint=" + checkpoint.Name + "&checkpoint-dir=%2Fshared%2Fcheckpoints",
+		"DELETE /containers/cont2/checkpoints/" + checkpoint.Name + "?dir=%2Fshared%2Fcheckpoints",
+	})
+}
+
+func (s *S) TestCheckpointContainerUnsupported(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ExperimentalBuild": false})
+	}))
+	defer srv.Close()
+	clusterInstance, err := cluster.New(nil, &cluster.MapStorage{}, "", cluster.Node{Address: srv.URL})
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	cont := container.Container{Container: types.Container{ID: "cont1", HostAddr: "127.0.0.1", Status: provision.StatusStarted.String()}}
+	_, err = s.p.checkpointContainer(cont, "/shared/checkpoints")
+	c.Assert(err, check.Equals, errCheckpointUnsupported)
+}
@@ -5,7 +5,11 @@
 package clusterclient
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"math/rand"
+	"net/http"
 	"time"
 
 	"github.com/fsouza/go-dockerclient"
@@ -27,14 +31,33 @@ type ClusterClient struct {
 	Collection    func() *storage.Collection
 	Limiter       provision.ActionLimiter
 	PossibleNodes []string
+	// BuildNodes are the nodes where images are built, any node of the
+	// cluster is used when empty. Storage is used to register images built
+	// in them.
+	BuildNodes []string
+	Storage    cluster.Storage
+	// BuildPool is the pool dedicated to build containers, if any.
+	// BuildLimiter limits the number of simultaneous builds in each node to
+	// MaxBuildsPerNode, builds waiting for a free slot for longer than
+	// BuildQueueTimeout fail.
+	BuildPool         string
+	BuildLimiter      provision.ActionLimiter
+	MaxBuildsPerNode  int
+	BuildQueueTimeout time.Duration
 }
 
 var (
-	_ provision.BuilderDockerClient  = &ClusterClient{}
-	_ provision.ExecDockerClient     = &ClusterClient{}
-	_ container.ContainerStateClient = &ClusterClient{}
+	_ provision.BuilderDockerClient   = &ClusterClient{}
+	_ provision.BuildSlotDockerClient = &ClusterClient{}
+	_ provision.ExecDockerClient      = &ClusterClient{}
+	_ container.ContainerStateClient  = &ClusterClient{}
+	_ container.NodeStartClient       = &ClusterClient{}
 )
 
+func (c *ClusterClient) StartContainerInNode(ctx context.Context, host, id string) error {
+	return dockercommon.StartContainerInNode(ctx, c.Cluster, host, id)
+}
+
 func (c *ClusterClient) SetTimeout(time.Duration) {
 	// noop, cluster already handles timeouts per operation correctly
 }
@@ -50,7 +73,15 @@ func (c *ClusterClient) PullAndCreateContainer(opts docker.CreateContainerOption
 		schedulerOpts := &container.SchedulerOpts{
 			FilterNodes: c.PossibleNodes,
 		}
+		parent := opts.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := dockercommon.OperationContext(parent, dockercommon.OperationCreate)
+		defer cancel()
+		opts.Context = ctx
 		hostAddr, cont, err = c.Cluster.CreateContainerSchedulerOpts(opts, schedulerOpts, net.StreamInactivityTimeout)
+		breakerDone(hostAddr, schedulerOpts, err)
 		hostAddr = net.URLToHost(hostAddr)
 		return cont, hostAddr, err
 	}
@@ -90,6 +121,7 @@ func (c *ClusterClient) PullAndCreateContainer(opts docker.CreateContainerOption
 		UpdateName:    true,
 		ActionLimiter: c.Limiter,
 		FilterNodes:   c.PossibleNodes,
+		BuildPool:     c.BuildPool,
 	}
 	var addr string
 	pullOpts := docker.PullImageOptions{
@@ -108,7 +140,19 @@ func (c *ClusterClient) PullAndCreateContainer(opts docker.CreateContainerOption
 			return nil, "", err
 		}
 		nodes = []string{node.Address}
+		err = dockercommon.NodeBreakerAllow(node.Address)
+		if err != nil {
+			return nil, "", err
+		}
 	}
+	// The create timeout includes the image pull made before creating the
+	// container, which is also bound to the pull timeout.
+	ctx, cancel := dockercommon.OperationContext(opts.Context, dockercommon.OperationCreate)
+	defer cancel()
+	opts.Context = ctx
+	pullCtx, pullCancel := dockercommon.OperationContext(ctx, dockercommon.OperationPull)
+	defer pullCancel()
+	pullOpts.Context = pullCtx
 	addr, cont, err = c.Cluster.CreateContainerPullOptsSchedulerOpts(
 		opts,
 		pullOpts,
@@ -119,6 +163,10 @@ func (c *ClusterClient) PullAndCreateContainer(opts docker.CreateContainerOption
 	if schedulerOpts.LimiterDone != nil {
 		schedulerOpts.LimiterDone()
 	}
+	if addr == "" && len(nodes) > 0 {
+		addr = nodes[0]
+	}
+	breakerDone(addr, schedulerOpts, err)
 	if err != nil {
 		return nil, "", err
 	}
@@ -135,6 +183,92 @@ func (c *ClusterClient) PullAndCreateContainer(opts docker.CreateContainerOption
 	return cont, hostAddr, nil
 }
 
+// breakerDone records the result of a container creation in the circuit
+// breaker of the node where it was last tried, which is only known by the
+// scheduler when the creation is aborted.
+func breakerDone(addr string, schedulerOpts *container.SchedulerOpts, err error) {
+	if addr == "" {
+		addr = schedulerOpts.Node
+	}
+	if addr != "" {
+		dockercommon.NodeBreakerDone(addr, err)
+	}
+}
+
+// AcquireBuildSlot reserves a build slot in the least busy of the possible
+// nodes of the client, waiting while the node is saturated. Further
+// containers created by the client are bound to the chosen node, so the whole
+// build runs in the reserved slot.
+func (c *ClusterClient) AcquireBuildSlot(w io.Writer) (func(), error) {
+	if c.BuildLimiter == nil || len(c.PossibleNodes) == 0 {
+		return func() {}, nil
+	}
+	node := c.PossibleNodes[0]
+	for _, n := range c.PossibleNodes[1:] {
+		if c.BuildLimiter.Len(buildSlotKey(n)) < c.BuildLimiter.Len(buildSlotKey(node)) {
+			node = n
+		}
+	}
+	key := buildSlotKey(node)
+	if c.MaxBuildsPerNode > 0 && c.BuildLimiter.Len(key) >= c.MaxBuildsPerNode && w != nil {
+		fmt.Fprintln(w, "---- All build nodes are busy, waiting for a free build slot ----")
+	}
+	acquired := make(chan func(), 1)
+	go func() {
+		acquired <- c.BuildLimiter.Start(key)
+	}()
+	var timeout <-chan time.Time
+	if c.BuildQueueTimeout > 0 {
+		timeout = time.After(c.BuildQueueTimeout)
+	}
+	select {
+	case release := <-acquired:
+		c.PossibleNodes = []string{node}
+		return release, nil
+	case <-timeout:
+		go func() {
+			release := <-acquired
+			release()
+		}()
+		return nil, errors.Errorf("timeout after %v waiting for a free build slot", c.BuildQueueTimeout)
+	}
+}
+
+// buildSlotKey is the limiter key of the build slots of a node, distinct
+// from the keys of the action limiter, which may share the same storage.
+func buildSlotKey(node string) string {
+	return "build-" + net.URLToHost(node)
+}
+
+// BuildImage builds the image in one of the build nodes of the client,
+// registering it in the cluster so it can be pushed and inspected later.
+func (c *ClusterClient) BuildImage(opts docker.BuildImageOptions) error {
+	if len(c.BuildNodes) == 0 || c.Storage == nil {
+		return c.Cluster.BuildImage(opts)
+	}
+	addr := c.BuildNodes[rand.Intn(len(c.BuildNodes))]
+	node, err := c.Cluster.GetNode(addr)
+	if err != nil {
+		return err
+	}
+	client, err := node.Client()
+	if err != nil {
+		return err
+	}
+	// Builds may take long, they are only bound by the inactivity timeout
+	// in the options.
+	client.HTTPClient = &http.Client{Transport: client.HTTPClient.Transport}
+	err = client.BuildImage(opts)
+	if err != nil {
+		return errors.Wrapf(err, "unable to build image in node %q", addr)
+	}
+	img, err := client.InspectImage(opts.Name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to inspect image in node %q", addr)
+	}
+	return c.Storage.StoreImage(opts.Name, img.ID, addr)
+}
+
 func (c *ClusterClient) RemoveContainer(opts docker.RemoveContainerOptions) error {
 	err := c.Cluster.RemoveContainer(opts)
 	if err != nil {
@@ -5,8 +5,10 @@
 package clusterclient
 
 import (
+	"bytes"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/fsouza/go-dockerclient"
 	dTesting "github.com/fsouza/go-dockerclient/testing"
@@ -321,3 +323,52 @@ func (s *S) TestSchedulerClientRemoveContainer(c *check.C) {
 	_, err = s.getContainer(cont.ID)
 	c.Assert(err, check.Equals, mgo.ErrNotFound)
 }
+
+func (s *S) TestAcquireBuildSlotWithoutLimiter(c *check.C) {
+	client := &ClusterClient{PossibleNodes: []string{"http://n1:2375", "http://n2:2375"}}
+	release, err := client.AcquireBuildSlot(nil)
+	c.Assert(err, check.IsNil)
+	release()
+	c.Assert(client.PossibleNodes, check.DeepEquals, []string{"http://n1:2375", "http://n2:2375"})
+}
+
+func (s *S) TestAcquireBuildSlotChoosesLeastBusyNode(c *check.C) {
+	limiter := &provision.LocalLimiter{}
+	limiter.Initialize(2)
+	done := limiter.Start(buildSlotKey("http://n1:2375"))
+	defer done()
+	client := &ClusterClient{
+		PossibleNodes:    []string{"http://n1:2375", "http://n2:2375"},
+		BuildLimiter:     limiter,
+		MaxBuildsPerNode: 2,
+	}
+	var buf bytes.Buffer
+	release, err := client.AcquireBuildSlot(&buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(client.PossibleNodes, check.DeepEquals, []string{"http://n2:2375"})
+	c.Assert(limiter.Len(buildSlotKey("http://n2:2375")), check.Equals, 1)
+	c.Assert(buf.String(), check.Equals, "")
+	release()
+	c.Assert(limiter.Len(buildSlotKey("http://n2:2375")), check.Equals, 0)
+}
+
+func (s *S) TestAcquireBuildSlotQueueTimeout(c *check.C) {
+	limiter := &provision.LocalLimiter{}
+	limiter.Initialize(1)
+	done := limiter.Start(buildSlotKey("http://n1:2375"))
+	client := &ClusterClient{
+		PossibleNodes:     []string{"http://n1:2375"},
+		BuildLimiter:      limiter,
+		MaxBuildsPerNode:  1,
+		BuildQueueTimeout: 100 * time.Millisecond,
+	}
+	var buf bytes.Buffer
+	_, err := client.AcquireBuildSlot(&buf)
+	c.Assert(err, check.ErrorMatches, "timeout after 100ms waiting for a free build slot")
+	c.Assert(buf.String(), check.Equals, "---- All build nodes are busy, waiting for a free build slot ----\n")
+	done()
+	for i := 0; i < 100 && limiter.Len(buildSlotKey("http://n1:2375")) != 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(limiter.Len(buildSlotKey("http://n1:2375")), check.Equals, 0)
+}
@@ -4,12 +4,9 @@
 
 package container
 
-import "github.com/tsuru/config"
+import "github.com/tsuru/tsuru/provision/nodecontainer"
 
 func BsSysLogPort() int {
-	bsPort, _ := config.GetInt("docker:bs:syslog-port")
-	if bsPort == 0 {
-		bsPort = 1514
-	}
-	return bsPort
+	syslog, _ := nodecontainer.BsSyslog()
+	return syslog.Port
 }
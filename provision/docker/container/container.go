@@ -24,6 +24,7 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/types"
 	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/provision/pool"
 )
 
 func init() {
@@ -37,6 +38,11 @@ type SchedulerOpts struct {
 	FilterNodes   []string
 	ActionLimiter provision.ActionLimiter
 	LimiterDone   func()
+	// BuildPool is the pool whose nodes are used for build containers
+	// instead of the nodes of the pool of the app.
+	BuildPool string
+	// Node is set by the scheduler to the address of the chosen node.
+	Node string
 }
 
 type SchedulerError struct {
@@ -73,6 +79,12 @@ type ContainerStateClient interface {
 	SetContainerState(*Container, ContainerState) error
 }
 
+// NodeStartClient is implemented by clients able to start containers in the
+// node with the given host, aborting the start when its timeout expires.
+type NodeStartClient interface {
+	StartContainerInNode(ctx context.Context, host, id string) error
+}
+
 const (
 	maxStartRetries = 4
 )
@@ -160,7 +172,7 @@ func (c *Container) Create(args *CreateArgs) error {
 		return err
 	}
 	conf := docker.Config{
-		Image:        args.ImageID,
+		Image:        dockercommon.PullImageName(args.ImageID, args.App.GetPool()),
 		Cmd:          args.Commands,
 		Entrypoint:   []string{},
 		ExposedPorts: exposedPorts,
@@ -483,8 +495,7 @@ func (c *Container) hostConfig(app provision.App, isDeploy bool) (*docker.HostCo
 		hostConfig.PortBindings = map[docker.Port][]docker.PortBinding{
 			docker.Port(c.ExposedPort): {{HostIP: "", HostPort: ""}},
 		}
-		pool := app.GetPool()
-		driver, opts, logErr := LogOpts(pool)
+		driver, opts, logErr := LogOpts(app.GetPool())
 		if logErr != nil {
 			return nil, logErr
 		}
@@ -497,8 +508,26 @@ func (c *Container) hostConfig(app provision.App, isDeploy bool) (*docker.HostCo
 		hostConfig.LogConfig = docker.LogConfig{
 			Type: dockercommon.JsonFileLogDriver,
 		}
+		buildMemory, _ := config.GetInt("docker:builder:memory")
+		if buildMemory > 0 {
+			hostConfig.Memory = int64(buildMemory)
+			hostConfig.MemorySwap = int64(buildMemory)
+		}
+		buildCPUShares, _ := config.GetInt("docker:builder:cpu-shares")
+		if buildCPUShares > 0 {
+			hostConfig.CPUShares = int64(buildCPUShares)
+		}
 	}
 
+	dns, err := pool.DNSConfigForPool(app.GetPool())
+	if err != nil {
+		return nil, err
+	}
+	if dns != nil {
+		hostConfig.DNS = dns.Nameservers
+		hostConfig.DNSSearch = dns.Searches
+		hostConfig.DNSOptions = dns.Options()
+	}
 	hostConfig.SecurityOpt, _ = config.GetList("docker:security-opts")
 	if sharedBasedir != "" && sharedMount != "" {
 		if sharedIsolation {
@@ -520,7 +549,12 @@ func (c *Container) hostConfig(app provision.App, isDeploy bool) (*docker.HostCo
 
 func (c *Container) Start(args *StartArgs) error {
 	done := args.Limiter.Start(c.HostAddr)
-	err := args.Client.StartContainer(c.ID, nil)
+	var err error
+	if startCli, ok := args.Client.(NodeStartClient); ok {
+		err = startCli.StartContainerInNode(context.Background(), c.HostAddr, c.ID)
+	} else {
+		err = args.Client.StartContainer(c.ID, nil)
+	}
 	done()
 	if err != nil {
 		return &StartError{Base: err}
@@ -559,7 +593,7 @@ func (c *Container) AsUnit(a provision.App) provision.Unit {
 	if cType == "" {
 		cType = a.GetPlatform()
 	}
-	return provision.Unit{
+	unit := provision.Unit{
 		ID:          c.ID,
 		Name:        c.Name,
 		AppName:     a.GetName(),
@@ -569,6 +603,10 @@ func (c *Container) AsUnit(a provision.App) provision.Unit {
 		ProcessName: c.ProcessName,
 		Address:     c.Address(),
 	}
+	if c.MongoID.Valid() {
+		unit.CreatedAt = c.MongoID.Time()
+	}
+	return unit
 }
 
 func (c *Container) ValidAddr() bool {
@@ -28,6 +28,7 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/types"
 	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"github.com/tsuru/tsuru/router/routertest"
 	"gopkg.in/check.v1"
@@ -331,6 +332,26 @@ func (s *S) TestContainerCreateForDeploy(c *check.C) {
 	c.Assert(dockerContainer.HostConfig.OomScoreAdj, check.Equals, 1000)
 }
 
+func (s *S) TestContainerHostConfigForDeployWithBuildLimits(c *check.C) {
+	config.Set("docker:builder:memory", 536870912)
+	defer config.Unset("docker:builder:memory")
+	config.Set("docker:builder:cpu-shares", 256)
+	defer config.Unset("docker:builder:cpu-shares")
+	app := provisiontest.NewFakeApp("app-name", "brainfuck", 1)
+	app.Memory = 15
+	app.CpuShare = 50
+	cont := Container{Container: types.Container{Name: "myName", AppName: app.GetName()}}
+	hostConfig, err := cont.hostConfig(app, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(hostConfig.Memory, check.Equals, int64(536870912))
+	c.Assert(hostConfig.MemorySwap, check.Equals, int64(536870912))
+	c.Assert(hostConfig.CPUShares, check.Equals, int64(256))
+	hostConfig, err = cont.hostConfig(app, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(hostConfig.Memory, check.Equals, int64(15))
+	c.Assert(hostConfig.CPUShares, check.Equals, int64(50))
+}
+
 func (s *S) TestContainerCreateDoesNotSetEnvs(c *check.C) {
 	s.server.CustomHandler("/images/.*/json", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := docker.Image{
@@ -1041,3 +1062,29 @@ func (s *S) TestRunPipelineWithRetryStartErrorWithSuccess(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(calls, check.Equals, 3)
 }
+
+func (s *S) TestContainerHostConfigPoolDNS(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "corp"})
+	c.Assert(err, check.IsNil)
+	ndots := 3
+	err = pool.SetDNSConfig("corp", &pool.DNSConfig{
+		Nameservers: []string{"10.0.0.10"},
+		Searches:    []string{"corp.example.com"},
+		Ndots:       &ndots,
+	})
+	c.Assert(err, check.IsNil)
+	app := provisiontest.NewFakeApp("app-name", "python", 1)
+	app.Pool = "corp"
+	cont := Container{Container: types.Container{Name: "myName", AppName: app.GetName(), ExposedPort: "8888/tcp"}}
+	hostConfig, err := cont.hostConfig(app, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(hostConfig.DNS, check.DeepEquals, []string{"10.0.0.10"})
+	c.Assert(hostConfig.DNSSearch, check.DeepEquals, []string{"corp.example.com"})
+	c.Assert(hostConfig.DNSOptions, check.DeepEquals, []string{"ndots:3"})
+	app.Pool = "other"
+	hostConfig, err = cont.hostConfig(app, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(hostConfig.DNS, check.IsNil)
+	c.Assert(hostConfig.DNSSearch, check.IsNil)
+	c.Assert(hostConfig.DNSOptions, check.IsNil)
+}
@@ -5,10 +5,9 @@
 package container
 
 import (
-	"strconv"
-
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
 	"github.com/tsuru/tsuru/scopedconfig"
 )
 
@@ -40,9 +39,11 @@ func LogOpts(pool string) (string, map[string]string, error) {
 		return "", nil, err
 	}
 	if entry.Driver == "" || entry.Driver == dockerLogBsDriver {
-		return "syslog", map[string]string{
-			"syslog-address": "udp://localhost:" + strconv.Itoa(BsSysLogPort()),
-		}, nil
+		syslog, err := nodecontainer.BsSyslog()
+		if err != nil {
+			return "", nil, err
+		}
+		return "syslog", syslog.LogOpts(), nil
 	}
 	return entry.Driver, entry.LogOpts, nil
 }
@@ -5,6 +5,7 @@
 package container
 
 import (
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/provision/docker/types"
 	"github.com/tsuru/tsuru/scopedconfig"
 	"gopkg.in/check.v1"
@@ -85,3 +86,18 @@ func (s *S) TestDockerLogUpdate(c *check.C) {
 	c.Assert(driver, check.Equals, "fluentd")
 	c.Assert(opts, check.DeepEquals, map[string]string{"tag": "x"})
 }
+
+func (s *S) TestLogOptsBSTLS(c *check.C) {
+	config.Set("docker:bs:syslog-protocol", "tcp+tls")
+	config.Set("docker:bs:syslog-tls:cert-file", "/etc/bs/cert.pem")
+	config.Set("docker:bs:syslog-tls:key-file", "/etc/bs/key.pem")
+	config.Set("docker:bs:syslog-tls:ca-file", "/etc/bs/ca.pem")
+	defer config.Unset("docker:bs")
+	driver, opts, err := LogOpts("p1")
+	c.Assert(err, check.IsNil)
+	c.Assert(driver, check.Equals, "syslog")
+	c.Assert(opts, check.DeepEquals, map[string]string{
+		"syslog-address":     "tcp+tls://localhost:1514",
+		"syslog-tls-ca-cert": "/etc/bs/ca.pem",
+	})
+}
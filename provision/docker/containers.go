@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -23,11 +24,14 @@ import (
 	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/retry"
 	"github.com/tsuru/tsuru/router/rebuild"
 )
 
 const (
-	lockWaitTimeout = 30 * time.Second
+	lockWaitTimeout   = 30 * time.Second
+	moveUnitRetryKind = "docker-move-unit"
 )
 
 type appLocker struct {
@@ -113,6 +117,7 @@ func (p *dockerProvisioner) runReplaceUnitsPipeline(w io.Writer, a provision.App
 		)
 	} else {
 		pipeline = action.NewPipeline(
+			&preSeedImage,
 			&provisionAddUnitsToHost,
 			&bindAndHealthcheck,
 			&addNewRoutes,
@@ -194,6 +199,14 @@ func (p *dockerProvisioner) MoveOneContainer(c container.Container, toHost strin
 		fmt.Fprintf(writer, "Moving unit %s for %q from %s%s...\n", c.ID, c.AppName, c.HostAddr, suffix)
 	}
 	toAdd := map[string]*containersToAdd{c.ProcessName: {Quantity: 1, Status: c.ExpectedStatus()}}
+	if dir := liveMigrationDir(); dir != "" && !p.isDryMode {
+		checkpoint, checkpointErr := p.checkpointContainer(c, dir)
+		if checkpointErr != nil {
+			fmt.Fprintf(writer, "Unable to checkpoint unit %s, falling back to stop/start: %s\n", c.ID, checkpointErr)
+		} else {
+			toAdd[c.ProcessName].Checkpoint = checkpoint
+		}
+	}
 	var pipeWriter io.Writer
 	evt, _ := writer.(*event.Event)
 	if evt != nil {
@@ -305,7 +318,47 @@ func (p *dockerProvisioner) rebalanceContainersByHost(address string, w io.Write
 	if err != nil {
 		return err
 	}
-	return p.moveContainerList(containers, "", w)
+	err = p.moveContainerList(containers, "", w)
+	if err != nil {
+		p.enqueueUnmovedContainers(address)
+	}
+	return err
+}
+
+// enqueueUnmovedContainers stores the units still in the given host to be
+// moved later by the retry mechanism.
+func (p *dockerProvisioner) enqueueUnmovedContainers(address string) {
+	containers, err := p.listContainersByHost(address)
+	if err != nil {
+		log.Errorf("unable to list units to retry moving from %s: %s", address, err)
+		return
+	}
+	for _, c := range containers {
+		_, err = retry.Enqueue(moveUnitRetryKind, map[string]string{
+			"container": c.ID,
+			"fromHost":  address,
+		})
+		if err != nil {
+			log.Errorf("unable to enqueue moving of unit %s: %s", c.ID, err)
+		}
+	}
+}
+
+// retryMoveUnit moves a unit that couldn't be moved from a node being
+// removed. Units already moved or removed are ignored.
+func (p *dockerProvisioner) retryMoveUnit(params map[string]string) error {
+	c, err := p.GetContainer(params["container"])
+	if err != nil {
+		if _, ok := err.(*provision.UnitNotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+	if c.HostAddr != params["fromHost"] {
+		return nil
+	}
+	_, err = p.moveContainer(c.ID, "", ioutil.Discard)
+	return err
 }
 
 func (p *dockerProvisioner) runCommandInContainer(image string, command string, app provision.App, stdout, stderr io.Writer) error {
@@ -363,7 +416,7 @@ func (p *dockerProvisioner) runCommandInContainer(image string, command string,
 	<-attachOptions.Success
 	close(attachOptions.Success)
 	done := p.ActionLimiter().Start(hostAddr)
-	err = cluster.StartContainer(cont.ID, nil)
+	err = dockercommon.StartContainerInNode(context.Background(), cluster, hostAddr, cont.ID)
 	done()
 	if err != nil {
 		return err
@@ -12,6 +12,7 @@ import (
 
 	dtesting "github.com/fsouza/go-dockerclient/testing"
 	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
 	"github.com/tsuru/docker-cluster/cluster"
 	"github.com/tsuru/tsuru/action"
 	"github.com/tsuru/tsuru/app"
@@ -23,6 +24,7 @@ import (
 	"github.com/tsuru/tsuru/provision/docker/types"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/retry"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/safe"
 	"gopkg.in/check.v1"
@@ -181,6 +183,45 @@ func (s *S) TestMoveContainer(c *check.C) {
 	c.Assert(serviceBodies[1], check.Matches, ".*unit-host=localhost")
 }
 
+func (s *S) TestMoveContainerLiveMigrationUnsupported(c *check.C) {
+	config.Set("docker:live-migration:enabled", true)
+	config.Set("docker:live-migration:checkpoint-dir", "/shared/checkpoints")
+	defer config.Unset("docker:live-migration")
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	err = newFakeImage(p, "tsuru/app-myapp", nil)
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(appInstance)
+	p.Provision(appInstance)
+	coll := p.Collection()
+	defer coll.Close()
+	defer coll.RemoveAll(bson.M{"appname": appInstance.GetName()})
+	imageID, err := image.AppCurrentImageName(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	addedConts, err := addContainersWithHost(&changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 1}},
+		app:         appInstance,
+		imageID:     imageID,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	appStruct := s.newAppFromFake(appInstance)
+	err = s.conn.Apps().Insert(appStruct)
+	c.Assert(err, check.IsNil)
+	buf := safe.NewBuffer(nil)
+	_, err = p.moveContainer(addedConts[0].ID, "127.0.0.1", buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s).*Unable to checkpoint unit .*, falling back to stop/start: `+errCheckpointUnsupported.Error()+".*")
+	containers, err := p.listContainersByHost("localhost")
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 0)
+	containers, err = p.listContainersByHost("127.0.0.1")
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 1)
+}
+
 func (s *S) TestMoveContainerStopped(c *check.C) {
 	p, err := s.startMultipleServersCluster()
 	c.Assert(err, check.IsNil)
@@ -424,6 +465,67 @@ func (s *S) TestRebalanceContainersByHost(c *check.C) {
 	c.Assert(c2, check.HasLen, 5)
 }
 
+func (s *S) TestRebalanceContainersByHostRetriesFailedMoves(c *check.C) {
+	otherServer, err := dtesting.NewServer("localhost:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer otherServer.Stop()
+	otherURL := strings.Replace(otherServer.URL(), "127.0.0.1", "localhost", 1)
+	p := &dockerProvisioner{}
+	err = p.Initialize()
+	c.Assert(err, check.IsNil)
+	p.storage = &cluster.MapStorage{}
+	p.scheduler = &segregatedScheduler{provisioner: p}
+	p.cluster, err = cluster.New(p.scheduler, p.storage, "",
+		cluster.Node{Address: s.server.URL(), Metadata: map[string]string{"pool": "pool1"}},
+		cluster.Node{Address: otherURL, Metadata: map[string]string{"pool": "pool1"}},
+	)
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = pool.AddTeamsToPool("pool1", []string{"team1"})
+	c.Assert(err, check.IsNil)
+	err = newFakeImage(p, "tsuru/app-myapp", nil)
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(appInstance)
+	p.Provision(appInstance)
+	imageID, err := image.AppCurrentImageName(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	_, err = addContainersWithHost(&changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		imageID:     imageID,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	err = p.Cluster().Unregister(otherURL)
+	c.Assert(err, check.IsNil)
+	buf := safe.NewBuffer(nil)
+	err = p.rebalanceContainersByHost("localhost", buf)
+	c.Assert(err, check.NotNil)
+	ops, err := retry.List(retry.StatusPending)
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 2)
+	for _, op := range ops {
+		c.Assert(op.Kind, check.Equals, moveUnitRetryKind)
+		c.Assert(op.Params["fromHost"], check.Equals, "localhost")
+	}
+	appStruct := s.newAppFromFake(appInstance)
+	appStruct.TeamOwner = "team1"
+	appStruct.Pool = "pool1"
+	err = s.conn.Apps().Insert(appStruct)
+	c.Assert(err, check.IsNil)
+	err = retry.RunPending()
+	c.Assert(err, check.IsNil)
+	ops, err = retry.List("")
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 0)
+	conts, err := p.listContainersByHost("127.0.0.1")
+	c.Assert(err, check.IsNil)
+	c.Assert(conts, check.HasLen, 2)
+}
+
 func (s *S) TestAppLocker(c *check.C) {
 	appName := "myapp"
 	appDB := &app.App{Name: appName}
@@ -83,7 +83,7 @@ func (p *dockerProvisioner) deployPipeline(app provision.App, imageID string, co
 	return deployImage, nil
 }
 
-func (p *dockerProvisioner) start(oldContainer *container.Container, app provision.App, imageID string, w io.Writer, exposedPort string, destinationHosts ...string) (*container.Container, error) {
+func (p *dockerProvisioner) start(oldContainer *container.Container, app provision.App, imageID string, w io.Writer, exposedPort string, checkpoint *unitCheckpoint, destinationHosts ...string) (*container.Container, error) {
 	commands, processName, err := dockercommon.LeanContainerCmds(oldContainer.ProcessName, imageID, app)
 	if err != nil {
 		return nil, err
@@ -117,6 +117,7 @@ func (p *dockerProvisioner) start(oldContainer *container.Container, app provisi
 		destinationHosts: destinationHosts,
 		provisioner:      p,
 		exposedPort:      exposedPort,
+		checkpoint:       checkpoint,
 	}
 	err = container.RunPipelineWithRetry(pipeline, args)
 	if err != nil {
@@ -140,18 +141,80 @@ func (p *dockerProvisioner) ClusterClient() provision.BuilderDockerClient {
 
 func (p *dockerProvisioner) GetClient(app provision.App) (provision.BuilderDockerClient, error) {
 	cli := &clusterclient.ClusterClient{
-		Cluster:    p.Cluster(),
-		Collection: p.Collection,
-		Limiter:    p.ActionLimiter(),
+		Cluster:           p.Cluster(),
+		Collection:        p.Collection,
+		Limiter:           p.ActionLimiter(),
+		BuildLimiter:      p.buildLimiter,
+		MaxBuildsPerNode:  maxBuildsPerNode(),
+		BuildQueueTimeout: buildQueueTimeout(),
 	}
 	if app != nil {
-		appNodes, err := p.Nodes(app)
+		var nodes []cluster.Node
+		var err error
+		if pool := buildPool(); pool != "" {
+			cli.BuildPool = pool
+			nodes, err = p.Cluster().NodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
+		} else {
+			nodes, err = p.Nodes(app)
+		}
 		if err != nil {
 			return nil, err
 		}
-		for _, n := range appNodes {
+		for _, n := range nodes {
 			cli.PossibleNodes = append(cli.PossibleNodes, n.Address)
 		}
 	}
 	return cli, nil
 }
+
+const defaultBuildQueueTimeout = 30 * time.Minute
+
+// buildPool returns the pool dedicated to builds, if any. Images built in
+// this pool only reach the nodes of the apps through the registry, so the
+// pool is ignored when no registry is configured.
+func buildPool() string {
+	pool, _ := config.GetString("docker:builder:pool")
+	if pool == "" {
+		return ""
+	}
+	if registry, _ := config.GetString("docker:registry"); registry == "" {
+		log.Errorf("[docker] ignoring build pool %q, docker:registry must be set to use a build pool", pool)
+		return ""
+	}
+	return pool
+}
+
+func maxBuildsPerNode() int {
+	limit, _ := config.GetInt("docker:builder:max-builds-per-node")
+	return limit
+}
+
+func buildQueueTimeout() time.Duration {
+	seconds, _ := config.GetInt("docker:builder:queue-timeout")
+	if seconds <= 0 {
+		return defaultBuildQueueTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// PlatformBuilderMetadataName is the metadata of the nodes designated for
+// building platform images, any node is used when no node has it set to
+// true.
+const PlatformBuilderMetadataName = "platform-builder"
+
+func (p *dockerProvisioner) GetPlatformBuildClient() (provision.BuilderDockerClient, error) {
+	cli := &clusterclient.ClusterClient{
+		Cluster:    p.Cluster(),
+		Collection: p.Collection,
+		Limiter:    p.ActionLimiter(),
+		Storage:    p.storage,
+	}
+	nodes, err := p.Cluster().NodesForMetadata(map[string]string{PlatformBuilderMetadataName: "true"})
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		cli.BuildNodes = append(cli.BuildNodes, n.Address)
+	}
+	return cli, nil
+}
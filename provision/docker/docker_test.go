@@ -12,6 +12,7 @@ import (
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/config"
 	"github.com/tsuru/docker-cluster/cluster"
 	"github.com/tsuru/tsuru/app"
@@ -40,6 +41,7 @@ type newContainerOpts struct {
 func (s *S) newContainer(opts *newContainerOpts, p *dockerProvisioner) (*container.Container, error) {
 	container := container.Container{
 		Container: types.Container{
+			MongoID:     bson.NewObjectId(),
 			ID:          "id",
 			IP:          "10.10.10.10",
 			HostPort:    "3333",
@@ -218,7 +220,7 @@ func (s *S) TestStart(c *check.C) {
 	imageID := image.GetBuildImage(app)
 	routertest.FakeRouter.AddBackend(app)
 	var buf bytes.Buffer
-	cont, err := s.p.start(&container.Container{Container: types.Container{ProcessName: "web"}}, app, imageID, &buf, "")
+	cont, err := s.p.start(&container.Container{Container: types.Container{ProcessName: "web"}}, app, imageID, &buf, "", nil)
 	c.Assert(err, check.IsNil)
 	c.Assert(cont.ID, check.Not(check.Equals), "")
 	cont2, err := s.p.GetContainer(cont.ID)
@@ -237,7 +239,7 @@ func (s *S) TestStartStoppedContainer(c *check.C) {
 	imageID := image.GetBuildImage(app)
 	routertest.FakeRouter.AddBackend(app)
 	var buf bytes.Buffer
-	cont, err = s.p.start(cont, app, imageID, &buf, "")
+	cont, err = s.p.start(cont, app, imageID, &buf, "", nil)
 	c.Assert(err, check.IsNil)
 	c.Assert(cont.ID, check.Not(check.Equals), "")
 	cont2, err := s.p.GetContainer(cont.ID)
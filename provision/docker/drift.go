@@ -0,0 +1,96 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+)
+
+// AppDrift compares the containers of the app stored by tsuru with the ones
+// in the docker nodes. Containers are expected to run the current image of
+// the app and to have the envs it would have if created now. Envs removed
+// from the app but still present in the containers are not detected, as
+// they can't be told apart from the envs defined by the image.
+func (p *dockerProvisioner) AppDrift(a provision.App) ([]provision.AppDrift, error) {
+	containers, err := p.listContainersByApp(a.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, nil
+	}
+	currentImage, err := image.AppCurrentImageName(a.GetName())
+	if err != nil {
+		return nil, err
+	}
+	var result []provision.AppDrift
+	for _, c := range containers {
+		drift := func(kind, expected, actual string) {
+			result = append(result, provision.AppDrift{
+				Unit:     c.ID,
+				Process:  c.ProcessName,
+				Kind:     kind,
+				Expected: expected,
+				Actual:   actual,
+			})
+		}
+		dockerContainer, err := p.Cluster().InspectContainer(c.ID)
+		if err != nil {
+			if _, ok := err.(*docker.NoSuchContainer); ok {
+				drift(provision.DriftMissing, "", "")
+				continue
+			}
+			return nil, err
+		}
+		status := provision.Status(c.Status)
+		expectRunning := status == provision.StatusStarted || status == provision.StatusStarting
+		if expectRunning && !dockerContainer.State.Running {
+			drift(provision.DriftStopped, c.Status, dockerContainer.State.StateString())
+		}
+		if c.Image != currentImage {
+			drift(provision.DriftImage, currentImage, c.Image)
+		} else if expected := dockercommon.PullImageName(currentImage, a.GetPool()); dockerContainer.Config.Image != expected {
+			drift(provision.DriftImage, expected, dockerContainer.Config.Image)
+		}
+		expectedEnvs := map[string]string{}
+		for _, env := range provision.EnvsForApp(a, c.ProcessName, false) {
+			expectedEnvs[env.Name] = env.Value
+		}
+		actualEnvs := map[string]string{}
+		for _, env := range dockerContainer.Config.Env {
+			parts := strings.SplitN(env, "=", 2)
+			if _, ok := expectedEnvs[parts[0]]; !ok || len(parts) != 2 {
+				continue
+			}
+			actualEnvs[parts[0]] = parts[1]
+		}
+		expectedHash, actualHash := envsHash(expectedEnvs), envsHash(actualEnvs)
+		if expectedHash != actualHash {
+			drift(provision.DriftEnv, expectedHash, actualHash)
+		}
+	}
+	return result, nil
+}
+
+func envsHash(envs map[string]string) string {
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s=%s\x00", name, envs[name])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
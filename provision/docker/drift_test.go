@@ -0,0 +1,58 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestAppDrift(c *check.C) {
+	a := &app.App{Name: "myapp", Pool: "pool1"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	cont, err := s.newContainer(&newContainerOpts{AppName: a.Name, Status: provision.StatusStarted.String()}, nil)
+	c.Assert(err, check.IsNil)
+	defer s.removeTestContainer(cont)
+	currentImage, err := image.AppCurrentImageName(a.Name)
+	c.Assert(err, check.IsNil)
+	drifts, err := s.p.AppDrift(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 3)
+	c.Assert(drifts[0], check.DeepEquals, provision.AppDrift{
+		Unit: cont.ID, Process: "web", Kind: provision.DriftStopped, Expected: "started", Actual: "created",
+	})
+	c.Assert(drifts[1], check.DeepEquals, provision.AppDrift{
+		Unit: cont.ID, Process: "web", Kind: provision.DriftImage, Expected: currentImage, Actual: "tsuru/python:latest",
+	})
+	c.Assert(drifts[2].Kind, check.Equals, provision.DriftEnv)
+	c.Assert(drifts[2].Expected, check.Not(check.Equals), drifts[2].Actual)
+}
+
+func (s *S) TestAppDriftMissingContainer(c *check.C) {
+	a := &app.App{Name: "myapp", Pool: "pool1"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	cont, err := s.newContainer(&newContainerOpts{AppName: a.Name}, nil)
+	c.Assert(err, check.IsNil)
+	defer s.removeTestContainer(cont)
+	err = s.p.Cluster().RemoveContainer(docker.RemoveContainerOptions{ID: cont.ID, Force: true})
+	c.Assert(err, check.IsNil)
+	drifts, err := s.p.AppDrift(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.DeepEquals, []provision.AppDrift{
+		{Unit: cont.ID, Process: "web", Kind: provision.DriftMissing},
+	})
+}
+
+func (s *S) TestAppDriftNoContainers(c *check.C) {
+	a := &app.App{Name: "myapp", Pool: "pool1"}
+	drifts, err := s.p.AppDrift(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 0)
+}
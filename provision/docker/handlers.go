@@ -41,6 +41,9 @@ func init() {
 	api.RegisterHandler("/docker/bs", "GET", api.AuthorizationRequiredHandler(bsConfigGetHandler))
 	api.RegisterHandler("/docker/logs", "GET", api.AuthorizationRequiredHandler(logsConfigGetHandler))
 	api.RegisterHandler("/docker/logs", "POST", api.AuthorizationRequiredHandler(logsConfigSetHandler))
+	api.RegisterHandler("/docker/scheduler", "GET", api.AuthorizationRequiredHandler(schedulerConfigGetHandler))
+	api.RegisterHandler("/docker/scheduler", "POST", api.AuthorizationRequiredHandler(schedulerConfigSetHandler))
+	api.RegisterHandler("/docker/scheduler/plan", "GET", api.AuthorizationRequiredHandler(schedulerPlanHandler))
 }
 
 // title: move container
@@ -290,6 +293,166 @@ func logsConfigSetHandler(w http.ResponseWriter, r *http.Request, t auth.Token)
 	return nil
 }
 
+// title: scheduler config
+// path: /docker/scheduler
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   401: Unauthorized
+func schedulerConfigGetHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	pools, err := permission.ListContextValues(t, permission.PermPoolReadScheduler, true)
+	if err != nil {
+		return err
+	}
+	configEntries, err := SchedulerLoadAll()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(pools) == 0 {
+		return json.NewEncoder(w).Encode(configEntries)
+	}
+	newMap := map[string]SchedulerConfig{}
+	for _, p := range pools {
+		if entry, ok := configEntries[p]; ok {
+			newMap[p] = entry
+		}
+	}
+	return json.NewEncoder(w).Encode(newMap)
+}
+
+// title: scheduler config set
+// path: /docker/scheduler
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+func schedulerConfigSetHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("unable to parse form values: %s", err),
+		}
+	}
+	pool := r.FormValue("pool")
+	conf := SchedulerConfig{Strategy: r.FormValue("strategy")}
+	conf.MemoryOvercommit, err = formFloat(r, "memoryOvercommit")
+	if err != nil {
+		return err
+	}
+	conf.CPUOvercommit, err = formFloat(r, "cpuOvercommit")
+	if err != nil {
+		return err
+	}
+	if conf == (SchedulerConfig{}) {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "strategy or overcommit factors are required"}
+	}
+	err = conf.validate()
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	var ctxs []permission.PermissionContext
+	if pool != "" {
+		ctxs = append(ctxs, permission.Context(permission.CtxPool, pool))
+	}
+	if !permission.Check(t, permission.PermPoolUpdateScheduler, ctxs...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:      event.Target{Type: event.TargetTypePool, Value: pool},
+		Kind:        permission.PermPoolUpdateScheduler,
+		Owner:       t,
+		CustomData:  event.FormToCustomData(r.Form),
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermPoolReadEvents, ctxs...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return conf.Save(pool)
+}
+
+func formFloat(r *http.Request, field string) (float64, error) {
+	raw := r.FormValue(field)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid %s: %s", field, raw)}
+	}
+	return value, nil
+}
+
+// SchedulerPlacement is the number of units a node would receive in a
+// scheduler dry-run.
+type SchedulerPlacement struct {
+	Node  string `json:"node"`
+	Units int    `json:"units"`
+}
+
+// title: scheduler dry-run
+// path: /docker/scheduler/plan
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func schedulerPlanHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	appName := r.URL.Query().Get("app")
+	if appName == "" {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "app is required"}
+	}
+	units := 1
+	if value := r.URL.Query().Get("units"); value != "" {
+		var err error
+		units, err = strconv.Atoi(value)
+		if err != nil || units <= 0 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "units must be a positive integer"}
+		}
+	}
+	strategy := r.URL.Query().Get("strategy")
+	if strategy != "" && !validStrategy(strategy) {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: ErrInvalidSchedulerStrategy.Error()}
+	}
+	a, err := app.GetByName(appName)
+	if err != nil {
+		if err == app.ErrAppNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	if !permission.Check(t, permission.PermPoolReadScheduler, permission.Context(permission.CtxPool, a.Pool)) {
+		return permission.ErrUnauthorized
+	}
+	toAdd := map[string]*containersToAdd{r.URL.Query().Get("process"): {Quantity: units}}
+	placement, err := mainDockerProvisioner.scheduler.simulatePlacement(a, toAdd, "", strategy)
+	if err != nil {
+		return err
+	}
+	if len(placement) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	result := make([]SchedulerPlacement, 0, len(placement))
+	for node, count := range placement {
+		result = append(result, SchedulerPlacement{Node: node, Units: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Node < result[j].Node
+	})
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
 func tryRestartAppsByFilter(filter *app.Filter, writer io.Writer) error {
 	apps, err := app.List(filter)
 	if err != nil {
@@ -384,3 +384,143 @@ func (s *HandlersSuite) TestDockerLogsInfoHandler(c *check.C) {
 		"p1": {DockerLogConfig: types.DockerLogConfig{Driver: "syslog", LogOpts: map[string]string{}}},
 	})
 }
+
+func (s *HandlersSuite) TestSchedulerConfigSetHandler(c *check.C) {
+	values := url.Values{"pool": []string{"pool1"}, "strategy": []string{"binpack"}}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/docker/scheduler", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	strategy, err := SchedulerStrategy("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.Equals, SchedulerStrategyBinpack)
+	strategy, err = SchedulerStrategy("pool2")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.Equals, SchedulerStrategySpread)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.scheduler",
+		StartCustomData: []map[string]interface{}{
+			{"name": "pool", "value": "pool1"},
+			{"name": "strategy", "value": "binpack"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *HandlersSuite) TestSchedulerConfigSetHandlerInvalidStrategy(c *check.C) {
+	values := url.Values{"pool": []string{"pool1"}, "strategy": []string{"random"}}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/docker/scheduler", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, ErrInvalidSchedulerStrategy.Error()+"\n")
+}
+
+func (s *HandlersSuite) TestSchedulerConfigSetHandlerOvercommit(c *check.C) {
+	conf := SchedulerConfig{Strategy: SchedulerStrategyBinpack}
+	err := conf.Save("pool1")
+	c.Assert(err, check.IsNil)
+	values := url.Values{"pool": []string{"pool1"}, "memoryOvercommit": []string{"1.5"}, "cpuOvercommit": []string{"2"}}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/docker/scheduler", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	conf, err = LoadSchedulerConfig("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(conf, check.DeepEquals, SchedulerConfig{Strategy: SchedulerStrategyBinpack, MemoryOvercommit: 1.5, CPUOvercommit: 2})
+}
+
+func (s *HandlersSuite) TestSchedulerConfigSetHandlerInvalidOvercommit(c *check.C) {
+	tests := []struct {
+		values  url.Values
+		message string
+	}{
+		{url.Values{"memoryOvercommit": []string{"abc"}}, "invalid memoryOvercommit: abc\n"},
+		{url.Values{"cpuOvercommit": []string{"0.5"}}, ErrInvalidOvercommit.Error() + "\n"},
+		{url.Values{"pool": []string{"pool1"}}, "strategy or overcommit factors are required\n"},
+	}
+	server := api.RunServer(true)
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/docker/scheduler", strings.NewReader(tt.values.Encode()))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		server.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+		c.Assert(recorder.Body.String(), check.Equals, tt.message)
+	}
+}
+
+func (s *HandlersSuite) TestSchedulerConfigGetHandler(c *check.C) {
+	conf := SchedulerConfig{Strategy: SchedulerStrategyBinpack}
+	err := conf.Save("p1")
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/docker/scheduler", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var result map[string]SchedulerConfig
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, map[string]SchedulerConfig{
+		"":   {},
+		"p1": {Strategy: SchedulerStrategyBinpack},
+	})
+}
+
+func (s *HandlersSuite) TestSchedulerPlanHandler(c *check.C) {
+	err := s.conn.Apps().Insert(&app.App{Name: "myapp", Pool: "test-default"})
+	c.Assert(err, check.IsNil)
+	server2, err := dtesting.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server2.Stop()
+	localURL := strings.Replace(server2.URL(), "127.0.0.1", "localhost", -1)
+	err = s.p.Cluster().Register(cluster.Node{Address: localURL, Metadata: map[string]string{"pool": "test-default"}})
+	c.Assert(err, check.IsNil)
+	doRequest := func(strategy string) []SchedulerPlacement {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", "/docker/scheduler/plan?app=myapp&process=web&units=4&strategy="+strategy, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		server := api.RunServer(true)
+		server.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusOK)
+		var result []SchedulerPlacement
+		err = json.Unmarshal(recorder.Body.Bytes(), &result)
+		c.Assert(err, check.IsNil)
+		return result
+	}
+	expected := []SchedulerPlacement{{Node: s.server.URL(), Units: 2}, {Node: localURL, Units: 2}}
+	c.Assert(doRequest(""), check.DeepEquals, expected)
+	result := doRequest("binpack")
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].Units, check.Equals, 4)
+}
+
+func (s *HandlersSuite) TestSchedulerPlanHandlerAppNotFound(c *check.C) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/docker/scheduler/plan?app=unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -23,6 +23,7 @@ import (
 type ContainerHealer struct {
 	provisioner         DockerProvisioner
 	maxUnresponsiveTime time.Duration
+	maxStartingTime     time.Duration
 	done                chan bool
 	locker              AppLocker
 }
@@ -30,6 +31,7 @@ type ContainerHealer struct {
 type ContainerHealerArgs struct {
 	Provisioner         DockerProvisioner
 	MaxUnresponsiveTime time.Duration
+	MaxStartingTime     time.Duration
 	Done                chan bool
 	Locker              AppLocker
 }
@@ -38,6 +40,7 @@ func NewContainerHealer(args ContainerHealerArgs) *ContainerHealer {
 	return &ContainerHealer{
 		provisioner:         args.Provisioner,
 		maxUnresponsiveTime: args.MaxUnresponsiveTime,
+		maxStartingTime:     args.MaxStartingTime,
 		done:                args.Done,
 		locker:              args.Locker,
 	}
@@ -137,7 +140,17 @@ func (h *ContainerHealer) healContainerIfNeeded(cont container.Container) error
 	if err != nil {
 		return errors.Wrap(err, "Error trying to insert container healing event, healing aborted")
 	}
+	before, err := a.UnitCounts()
+	if err != nil {
+		log.Errorf("Containers healing: unable to count units of app %q: %s", a.Name, err)
+	}
 	newCont, healErr := h.healContainer(cont)
+	if before != nil {
+		err = a.RecordUnitCountChanges(before, evt)
+		if err != nil {
+			log.Errorf("Containers healing: unable to record unit count changes of app %q: %s", a.Name, err)
+		}
+	}
 	if healErr != nil {
 		healErr = errors.Errorf("Error healing container %q: %s", cont.ID, healErr.Error())
 	}
@@ -152,14 +165,28 @@ func (h *ContainerHealer) healContainerIfNeeded(cont container.Container) error
 }
 
 func (h *ContainerHealer) runContainerHealerOnce() {
-	containers, err := listUnresponsiveContainers(h.provisioner, h.maxUnresponsiveTime)
-	if err != nil {
-		log.Errorf("Containers Healing: couldn't list unresponsive containers: %s", err)
+	if h.maxUnresponsiveTime > 0 {
+		containers, err := listUnresponsiveContainers(h.provisioner, h.maxUnresponsiveTime)
+		if err != nil {
+			log.Errorf("Containers Healing: couldn't list unresponsive containers: %s", err)
+		}
+		for _, cont := range containers {
+			err := h.healContainerIfNeeded(cont)
+			if err != nil {
+				log.Errorf("Containers Healing: couldn't heal container: %s", err)
+			}
+		}
 	}
-	for _, cont := range containers {
-		err := h.healContainerIfNeeded(cont)
+	if h.maxStartingTime > 0 {
+		containers, err := listStuckContainers(h.provisioner, h.maxStartingTime)
 		if err != nil {
-			log.Errorf("Containers Healing: couldn't heal container: %s", err)
+			log.Errorf("Containers Healing: couldn't list stuck containers: %s", err)
+		}
+		for _, cont := range containers {
+			err := h.healStuckContainerIfNeeded(cont)
+			if err != nil {
+				log.Errorf("Containers Healing: couldn't heal stuck container: %s", err)
+			}
 		}
 	}
 }
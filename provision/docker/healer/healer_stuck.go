@@ -0,0 +1,218 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+)
+
+// StuckUnitsOptOutTag is the app tag that disables the healing of units of
+// the app stuck in the created or starting states.
+const StuckUnitsOptOutTag = "no-stuck-unit-healing"
+
+const stuckEventsTimeout = 10 * time.Second
+
+var stuckStatuses = []string{
+	provision.StatusCreated.String(),
+	provision.StatusStarting.String(),
+}
+
+// StuckUnitDiagnostics holds the information collected from docker about a
+// unit stuck in the created or starting states, stored in the healing event.
+type StuckUnitDiagnostics struct {
+	Container    container.Container
+	StuckSince   time.Time
+	State        *docker.State      `bson:",omitempty"`
+	InspectError string             `bson:",omitempty"`
+	Events       []docker.APIEvents `bson:",omitempty"`
+	EventsError  string             `bson:",omitempty"`
+}
+
+func stuckSince(cont container.Container) time.Time {
+	if cont.LastStatusUpdate.IsZero() {
+		return cont.MongoID.Time()
+	}
+	return cont.LastStatusUpdate
+}
+
+func isStuck(cont container.Container, maxStartingTime time.Duration) bool {
+	for _, s := range stuckStatuses {
+		if cont.Status == s {
+			return stuckSince(cont).Before(time.Now().Add(-maxStartingTime))
+		}
+	}
+	return false
+}
+
+func hasOptedOut(a *app.App) bool {
+	for _, tag := range a.Tags {
+		if tag == StuckUnitsOptOutTag {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *ContainerHealer) collectDiagnostics(cont container.Container) StuckUnitDiagnostics {
+	since := stuckSince(cont)
+	diag := StuckUnitDiagnostics{Container: cont, StuckSince: since}
+	dockerCont, err := h.provisioner.Cluster().InspectContainer(cont.ID)
+	if err == nil {
+		diag.State = &dockerCont.State
+	} else {
+		diag.InspectError = err.Error()
+	}
+	diag.Events, err = h.containerEvents(cont, since.Add(-time.Minute))
+	if err != nil {
+		diag.EventsError = err.Error()
+	}
+	return diag
+}
+
+// containerEvents returns the docker events of the container emitted since
+// the given time.
+func (h *ContainerHealer) containerEvents(cont container.Container, since time.Time) ([]docker.APIEvents, error) {
+	node, err := dockercommon.GetNodeByHost(h.provisioner.Cluster(), cont.HostAddr)
+	if err != nil {
+		return nil, err
+	}
+	client, err := node.Client()
+	if err != nil {
+		return nil, err
+	}
+	filters, err := json.Marshal(map[string][]string{"container": {cont.ID}})
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("since", fmt.Sprint(since.Unix()))
+	params.Set("until", fmt.Sprint(time.Now().Unix()))
+	params.Set("filters", string(filters))
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(client.Endpoint(), "/")+"/events?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stuckEventsTimeout)
+	defer cancel()
+	rsp, err := client.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code listing events: %d", rsp.StatusCode)
+	}
+	var events []docker.APIEvents
+	decoder := json.NewDecoder(rsp.Body)
+	for {
+		var evt docker.APIEvents
+		err = decoder.Decode(&evt)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, evt)
+	}
+}
+
+func (h *ContainerHealer) healStuckContainerIfNeeded(cont container.Container) error {
+	a, err := app.GetByName(cont.AppName)
+	if err != nil {
+		return errors.Wrapf(err, "Stuck units healing: unable to heal %q couldn't get app %q", cont.ID, cont.AppName)
+	}
+	if hasOptedOut(a) {
+		return nil
+	}
+	locked := h.locker.Lock(cont.AppName)
+	if !locked {
+		return errors.Errorf("Stuck units healing: unable to heal %q couldn't lock app %s", cont.ID, cont.AppName)
+	}
+	defer h.locker.Unlock(cont.AppName)
+	// Sanity check, now we have a lock, let's find out if the container is
+	// still stuck
+	current, err := h.provisioner.GetContainer(cont.ID)
+	if err != nil {
+		if _, isNotFound := err.(*provision.UnitNotFoundError); isNotFound {
+			return nil
+		}
+		return errors.Wrapf(err, "Stuck units healing: unable to heal %q couldn't verify it still exists", cont.ID)
+	}
+	if !isStuck(*current, h.maxStartingTime) {
+		return nil
+	}
+	cont = *current
+	log.Errorf("Initiating healing process for container %q, stuck in %q since %s.", cont.ID, cont.Status, stuckSince(cont))
+	diag := h.collectDiagnostics(cont)
+	evt, err := event.NewInternal(&event.Opts{
+		Target: event.Target{Type: event.TargetTypeContainer, Value: cont.ID},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: event.TargetTypeApp, Value: cont.AppName}},
+		},
+		InternalKind: "healer",
+		CustomData:   diag,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permission.CtxTeam, a.Teams),
+			permission.Context(permission.CtxApp, a.Name),
+			permission.Context(permission.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error trying to insert stuck unit healing event, healing aborted")
+	}
+	newCont, healErr := h.healContainer(cont)
+	if healErr != nil {
+		healErr = errors.Errorf("Error healing stuck container %q: %s", cont.ID, healErr.Error())
+	}
+	if newCont.ID != "" {
+		evt.ExtraTargets = append(evt.ExtraTargets, event.ExtraTarget{Target: event.Target{Type: event.TargetTypeContainer, Value: newCont.ID}})
+	}
+	err = evt.DoneCustomData(healErr, newCont)
+	if err != nil {
+		log.Errorf("Error trying to update stuck unit healing event: %s", err)
+	}
+	return healErr
+}
+
+func listStuckContainers(p DockerProvisioner, maxStartingTime time.Duration) ([]container.Container, error) {
+	limit := time.Now().UTC().Add(-maxStartingTime)
+	conts, err := p.ListContainers(bson.M{
+		"id":      bson.M{"$ne": ""},
+		"appname": bson.M{"$ne": ""},
+		"status":  bson.M{"$in": stuckStatuses},
+		"$or": []bson.M{
+			{"laststatusupdate": bson.M{"$lt": limit}},
+			{"laststatusupdate": time.Time{}, "_id": bson.M{"$lt": bson.NewObjectIdWithTime(limit)}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var stuck []container.Container
+	for _, c := range conts {
+		if isStuck(c, maxStartingTime) {
+			stuck = append(stuck, c)
+		}
+	}
+	return stuck, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package healer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/dockertest"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"gopkg.in/check.v1"
+)
+
+func startStuckContainers(c *check.C, p *dockertest.FakeDockerProvisioner, appName string) []container.Container {
+	fakeApp := newFakeAppInDB(appName, "python", 0)
+	node1 := p.Servers()[0]
+	containers, err := p.StartContainers(dockertest.StartContainersArgs{
+		Endpoint:  node1.URL(),
+		App:       fakeApp,
+		Amount:    map[string]int{"web": 2},
+		Image:     "tsuru/python",
+		PullImage: true,
+	})
+	c.Assert(err, check.IsNil)
+	for i := range containers {
+		containers[i].Status = provision.StatusStarted.String()
+		containers[i].LastStatusUpdate = time.Now().UTC()
+	}
+	containers[1].Status = provision.StatusStarting.String()
+	containers[1].LastStatusUpdate = time.Now().UTC().Add(-5 * time.Minute)
+	p.SetContainers(containers[0].HostAddr, containers)
+	for _, server := range p.Servers() {
+		server.CustomHandler("/events", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(docker.APIEvents{Action: "start", Type: "container", Actor: docker.APIActor{ID: containers[1].ID}})
+		}))
+	}
+	return containers
+}
+
+func (s *S) TestHealStuckContainer(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	containers := startStuckContainers(c, p, "myapp")
+	stuckCont := containers[1]
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner:     p,
+		MaxStartingTime: time.Minute,
+		Locker:          dockertest.NewFakeLocker(),
+	})
+	err = healer.healStuckContainerIfNeeded(stuckCont)
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Movings(), check.DeepEquals, []dockertest.ContainerMoving{
+		{ContainerID: stuckCont.ID, HostFrom: stuckCont.HostAddr, HostTo: ""},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: "container", Value: stuckCont.ID},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: "app", Value: "myapp"}},
+			{Target: event.Target{Type: "container", Value: stuckCont.ID + "-recreated"}},
+		},
+		Kind: "healer",
+		StartCustomData: map[string]interface{}{
+			"container.id":     stuckCont.ID,
+			"container.status": provision.StatusStarting.String(),
+			"state.running":    true,
+			"events.0.action":  "start",
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestHealStuckContainerNotStuck(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	containers := startStuckContainers(c, p, "myapp")
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner:     p,
+		MaxStartingTime: 10 * time.Minute,
+		Locker:          dockertest.NewFakeLocker(),
+	})
+	err = healer.healStuckContainerIfNeeded(containers[1])
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Movings(), check.IsNil)
+	c.Assert(eventtest.EventDesc{IsEmpty: true}, eventtest.HasEvent)
+}
+
+func (s *S) TestHealStuckContainerAppOptedOut(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	containers := startStuckContainers(c, p, "myapp")
+	conn, err := db.Conn()
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": "myapp"}, bson.M{"$set": bson.M{"tags": []string{StuckUnitsOptOutTag}}})
+	c.Assert(err, check.IsNil)
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner:     p,
+		MaxStartingTime: time.Minute,
+		Locker:          dockertest.NewFakeLocker(),
+	})
+	err = healer.healStuckContainerIfNeeded(containers[1])
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Movings(), check.IsNil)
+	c.Assert(eventtest.EventDesc{IsEmpty: true}, eventtest.HasEvent)
+}
+
+func (s *S) TestListStuckContainers(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	coll := p.Collection()
+	defer coll.Close()
+	now := time.Now().UTC()
+	coll.Insert(
+		container.Container{Container: types.Container{ID: "c1", AppName: "app_time_test",
+			LastStatusUpdate: now.Add(-5 * time.Minute), Status: provision.StatusStarting.String()}},
+		container.Container{Container: types.Container{ID: "c2", AppName: "app_time_test",
+			LastStatusUpdate: now.Add(-5 * time.Minute), Status: provision.StatusCreated.String()}},
+		container.Container{Container: types.Container{ID: "c3", AppName: "app_time_test",
+			LastStatusUpdate: now, Status: provision.StatusStarting.String()}},
+		container.Container{Container: types.Container{ID: "c4", AppName: "app_time_test",
+			LastStatusUpdate: now.Add(-5 * time.Minute), Status: provision.StatusStarted.String()}},
+		container.Container{Container: types.Container{MongoID: bson.NewObjectIdWithTime(now.Add(-5 * time.Minute)),
+			ID: "c5", AppName: "app_time_test", Status: provision.StatusCreated.String()}},
+	)
+	defer coll.RemoveAll(bson.M{"appname": "app_time_test"})
+	result, err := listStuckContainers(p, 3*time.Minute)
+	c.Assert(err, check.IsNil)
+	var ids []string
+	for _, cont := range result {
+		ids = append(ids, cont.ID)
+	}
+	sort.Strings(ids)
+	c.Assert(ids, check.DeepEquals, []string{"c1", "c2", "c5"})
+}
+
+func (s *S) TestRunContainerHealerStuckContainers(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	containers := startStuckContainers(c, p, "myapp")
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner:     p,
+		MaxStartingTime: time.Minute,
+		Locker:          dockertest.NewFakeLocker(),
+	})
+	healer.runContainerHealerOnce()
+	c.Assert(p.Movings(), check.DeepEquals, []dockertest.ContainerMoving{
+		{ContainerID: containers[1].ID, HostFrom: containers[1].HostAddr, HostTo: ""},
+	})
+	queries := p.Queries()
+	c.Assert(queries, check.HasLen, 1)
+	c.Assert(queries[0]["status"], check.DeepEquals, bson.M{"$in": stuckStatuses})
+}
@@ -5,13 +5,21 @@
 package docker
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
 	"github.com/fsouza/go-dockerclient"
 	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/config"
 	"github.com/tsuru/docker-cluster/cluster"
 	"github.com/tsuru/docker-cluster/storage"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/dockercommon"
 )
 
@@ -73,3 +81,40 @@ func (p *dockerProvisioner) CleanImage(appName, imgName string) error {
 	}
 	return nil
 }
+
+func (p *dockerProvisioner) PrepullImage(pool, imgName string, w io.Writer) error {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	nodes, err := p.Cluster().NodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	addrs := make([]string, len(nodes))
+	for i, n := range nodes {
+		addrs[i] = n.Address
+	}
+	repo, tag := image.SplitImageName(dockercommon.PullImageName(imgName, pool))
+	pullOpts := docker.PullImageOptions{
+		Repository:        repo,
+		Tag:               tag,
+		InactivityTimeout: net.StreamInactivityTimeout,
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Cluster().PullImage(pullOpts, dockercommon.RegistryAuthConfig(), addrs...)
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(imagePreSeedTimeout()):
+		err = errors.New("timeout pre-pulling image")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, " ---> Pre-pulled image %s in %d %s\n", imgName, len(addrs), pluralize("node", len(addrs)))
+	return nil
+}
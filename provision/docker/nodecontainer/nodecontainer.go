@@ -6,9 +6,13 @@ package nodecontainer
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,15 +20,38 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app/image"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/fix"
 	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
 )
 
+const (
+	defaultPullMaxTries        = 3
+	defaultPullBackoff         = time.Second
+	defaultRecreateConcurrency = 10
+	maxPullBackoff             = 30 * time.Second
+	maxPullLineSize            = 4096
+
+	EventKindCreate   = "nodecontainer-create"
+	EventKindRecreate = "nodecontainer-recreate"
+)
+
+// EventData is the custom data of the events recording the creation of node
+// containers in a node.
+type EventData struct {
+	Name  string
+	Node  string
+	Pool  string
+	Image string
+}
+
 type DockerProvisioner interface {
 	GetName() string
 	Cluster() *cluster.Cluster
@@ -35,24 +62,68 @@ type DockerProvisioner interface {
 //
 // It assumes that the given writer is thread safe.
 func recreateContainers(p DockerProvisioner, w io.Writer, nodes ...cluster.Node) error {
-	return ensureContainersStarted(p, w, true, nil, nodes...)
+	return ensureContainersStarted(context.Background(), p, w, true, nil, nodes...)
 }
 
 func RecreateNamedContainers(p DockerProvisioner, w io.Writer, name string, pool string) error {
-	var nodes []cluster.Node
-	var err error
-	if pool == "" {
-		nodes, err = p.Cluster().UnfilteredNodes()
-	} else {
-		nodes, err = p.Cluster().UnfilteredNodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
-	}
+	nodes, err := poolNodes(p, pool)
 	if err != nil || len(nodes) == 0 {
 		return err
 	}
-	return ensureContainersStarted(p, w, true, []string{name}, nodes...)
+	return ensureContainersStarted(context.Background(), p, w, true, []string{name}, nodes...)
 }
 
-func ensureContainersStarted(p DockerProvisioner, w io.Writer, relaunch bool, names []string, nodes ...cluster.Node) error {
+// RecreateNamedContainersDry reports the nodes where RecreateNamedContainers
+// would relaunch the node container, along with the image to be pulled and
+// the env resolved for each node, without touching any container. The pinned
+// image is ignored, as upgrading a node container resets it.
+func RecreateNamedContainersDry(p DockerProvisioner, w io.Writer, name string, pool string) error {
+	nodes, err := poolNodes(p, pool)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		nodePool := node.Metadata[provision.PoolMetadataName]
+		containerConfig, err := nodecontainer.LoadNodeContainer(nodePool, name)
+		if err != nil {
+			return err
+		}
+		if !containerConfig.Valid() {
+			fmt.Fprintf(w, "node container %q is disabled in the node %s [%s]\n", name, node.Address, nodePool)
+			continue
+		}
+		// Only env names are shown, values may hold tokens and secrets.
+		envNames := []string{"DOCKER_ENDPOINT"}
+		for _, env := range containerConfig.EnvListForNode(node.Address) {
+			envNames = append(envNames, strings.SplitN(env, "=", 2)[0])
+		}
+		fmt.Fprintf(w, "would relaunch node container %q in the node %s [%s]\n", name, node.Address, nodePool)
+		fmt.Fprintf(w, "  image: %s\n", dockercommon.PullImageName(containerConfig.Config.Image, nodePool))
+		fmt.Fprintf(w, "  env: %s\n", strings.Join(envNames, " "))
+	}
+	return nil
+}
+
+// poolNodes returns the nodes in the pool, or every node if pool is empty,
+// including disabled nodes.
+func poolNodes(p DockerProvisioner, pool string) ([]cluster.Node, error) {
+	if pool == "" {
+		return p.Cluster().UnfilteredNodes()
+	}
+	return p.Cluster().UnfilteredNodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
+}
+
+// recreateConcurrency returns the max number of nodes where node containers
+// are created at the same time when not upgrading them in a rolling fashion.
+func recreateConcurrency() int {
+	concurrency, err := config.GetInt("docker:bs:recreate-concurrency")
+	if err != nil || concurrency <= 0 {
+		return defaultRecreateConcurrency
+	}
+	return concurrency
+}
+
+func ensureContainersStarted(ctx context.Context, p DockerProvisioner, w io.Writer, relaunch bool, names []string, nodes ...cluster.Node) error {
 	if w == nil {
 		w = ioutil.Discard
 	}
@@ -69,56 +140,86 @@ func ensureContainersStarted(p DockerProvisioner, w io.Writer, relaunch bool, na
 			return err
 		}
 	}
+	// With max-workers set, node containers are upgraded in a rolling
+	// fashion, reporting the progress after each node. The upgrade is
+	// aborted once max-failures nodes have failed. Otherwise, the number of
+	// nodes handled at the same time is still limited, so images are not
+	// pulled by every node at once.
 	workers, _ := config.GetInt("docker:nodecontainer:max-workers")
-	if workers == 0 {
+	rolling := workers > 0 && workers < len(nodes)
+	if !rolling {
 		workers = len(nodes)
+		if concurrency := recreateConcurrency(); workers > concurrency {
+			workers = concurrency
+		}
 	}
-	step := len(nodes) / workers
-	if len(nodes)%workers != 0 {
-		step++
-	}
-	errChan := make(chan error, len(nodes)*len(names))
+	maxFailures, _ := config.GetInt("docker:nodecontainer:max-failures")
 	log.Debugf("[node containers] recreating %d containers", len(nodes)*len(names))
-	recreateContainer := func(node *cluster.Node, confName string) {
+	recreateContainer := func(node *cluster.Node, confName string) error {
 		pool := node.Metadata[provision.PoolMetadataName]
 		containerConfig, confErr := nodecontainer.LoadNodeContainer(pool, confName)
 		if confErr != nil {
-			errChan <- confErr
-			return
+			return confErr
 		}
 		if !containerConfig.Valid() {
-			return
+			return nil
 		}
 		log.Debugf("[node containers] recreating container %q in %s [%s]", confName, node.Address, pool)
 		fmt.Fprintf(w, "relaunching node container %q in the node %s [%s]\n", confName, node.Address, pool)
-		confErr = create(containerConfig, node, pool, p, relaunch)
+		confErr = createWithEvent(ctx, containerConfig, node, pool, p, relaunch, w)
 		if confErr != nil {
 			confErr = errors.Wrapf(confErr, "[node containers] failed to create container in %s [%s]", node.Address, pool)
-			errChan <- log.WrapError(confErr)
+			return log.WrapError(confErr)
 		}
+		return nil
 	}
+	var (
+		mu          sync.Mutex
+		allErrors   []error
+		processed   int
+		failedNodes int
+	)
+	nodeCh := make(chan *cluster.Node)
 	wg := sync.WaitGroup{}
-	splitWork := func(start, end int) {
-		for i := start; i < end; i++ {
-			for j := range names {
-				recreateContainer(&nodes[i], names[j])
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range nodeCh {
+				var nodeErrors []error
+				for j := range names {
+					if confErr := recreateContainer(node, names[j]); confErr != nil {
+						nodeErrors = append(nodeErrors, confErr)
+					}
+				}
+				mu.Lock()
+				processed++
+				if len(nodeErrors) > 0 {
+					failedNodes++
+					allErrors = append(allErrors, nodeErrors...)
+				}
+				if rolling {
+					fmt.Fprintf(w, "node containers processed in %d of %d nodes, %d failed\n", processed, len(nodes), failedNodes)
+				}
+				mu.Unlock()
 			}
-		}
-		wg.Done()
+		}()
 	}
-	for i := 0; i < len(nodes); i += step {
-		end := i + step
-		if end > len(nodes) {
-			end = len(nodes)
+	skipped := 0
+	for i := range nodes {
+		mu.Lock()
+		aborted := maxFailures > 0 && failedNodes >= maxFailures
+		mu.Unlock()
+		if aborted {
+			skipped = len(nodes) - i
+			break
 		}
-		wg.Add(1)
-		go splitWork(i, end)
+		nodeCh <- &nodes[i]
 	}
+	close(nodeCh)
 	wg.Wait()
-	close(errChan)
-	var allErrors []error
-	for err = range errChan {
-		allErrors = append(allErrors, err)
+	if skipped > 0 {
+		allErrors = append(allErrors, errors.Errorf("[node containers] aborted after %d failed nodes, %d nodes skipped", failedNodes, skipped))
 	}
 	if len(allErrors) == 0 {
 		return nil
@@ -126,13 +227,12 @@ func ensureContainersStarted(p DockerProvisioner, w io.Writer, relaunch bool, na
 	return tsuruErrors.NewMultiError(allErrors...)
 }
 
-func pullImage(c *nodecontainer.NodeContainerConfig, client *docker.Client, p DockerProvisioner, pool string) (string, error) {
+func pullImage(ctx context.Context, c *nodecontainer.NodeContainerConfig, rt Runtime, pool string, w io.Writer) (string, error) {
 	image := c.Image()
-	output, err := pullWithRetry(client, p, image, 3)
+	digest, err := rt.PullImage(ctx, image, pool, w)
 	if err != nil {
 		return "", err
 	}
-	digest, _ := fix.GetImageDigest(output)
 	err = c.PinImageIfNeeded(image, digest, pool)
 	if err != nil {
 		return "", err
@@ -140,16 +240,51 @@ func pullImage(c *nodecontainer.NodeContainerConfig, client *docker.Client, p Do
 	return image, err
 }
 
-func create(c *nodecontainer.NodeContainerConfig, node *cluster.Node, poolName string, p DockerProvisioner, relaunch bool) error {
-	client, err := node.Client()
+// createWithEvent creates the node container in the node, recording the
+// outcome in an event targeting the node. The progress of the image pull is
+// written to w.
+func createWithEvent(ctx context.Context, c *nodecontainer.NodeContainerConfig, node *cluster.Node, poolName string, p DockerProvisioner, relaunch bool, w io.Writer) (err error) {
+	kind := EventKindCreate
+	if relaunch {
+		kind = EventKindRecreate
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target: event.Target{Type: event.TargetTypeNode, Value: node.Address},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: event.TargetTypeNodeContainer, Value: c.Name}},
+			{Target: event.Target{Type: event.TargetTypePool, Value: poolName}},
+		},
+		InternalKind: kind,
+		CustomData: EventData{
+			Name:  c.Name,
+			Node:  node.Address,
+			Pool:  poolName,
+			Image: c.Image(),
+		},
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermPoolReadEvents, permission.Context(permission.CtxPool, poolName)),
+	})
 	if err != nil {
 		return err
 	}
-	c.Config.Image, err = pullImage(c, client, p, poolName)
+	defer func() { evt.Done(err) }()
+	return create(ctx, c, node, poolName, p, relaunch, w)
+}
+
+func create(ctx context.Context, c *nodecontainer.NodeContainerConfig, node *cluster.Node, poolName string, p DockerProvisioner, relaunch bool, w io.Writer) error {
+	rt, err := RuntimeForNode(node)
 	if err != nil {
 		return err
 	}
-	c.Config.Env = append([]string{"DOCKER_ENDPOINT=" + node.Address}, c.Config.Env...)
+	c.Config.Image, err = pullImage(ctx, c, rt, poolName, w)
+	if err != nil {
+		return err
+	}
+	c.Config.Env, err = nodecontainer.ResolveEnvs(append([]string{"DOCKER_ENDPOINT=" + node.Address}, c.EnvListForNode(node.Address)...))
+	if err != nil {
+		return err
+	}
+	c.ApplySecurity()
 	c.Config.Labels = provision.NodeContainerLabels(provision.NodeContainerLabelsOpts{
 		Name:         c.Name,
 		CustomLabels: c.Config.Labels,
@@ -160,56 +295,178 @@ func create(c *nodecontainer.NodeContainerConfig, node *cluster.Node, poolName s
 		Name:       c.Name,
 		HostConfig: &c.HostConfig,
 		Config:     &c.Config,
+		Context:    ctx,
 	}
-	_, err = client.CreateContainer(opts)
+	err = rt.CreateContainer(opts)
 	if err != nil {
 		if err != docker.ErrContainerAlreadyExists {
 			return err
 		}
 		if relaunch {
 			multiErr := tsuruErrors.NewMultiError()
-			err = tryRemovingOld(client, opts.Name)
+			err = tryRemovingOld(rt, opts.Name)
 			if err != nil {
 				multiErr.Add(errors.Wrapf(err, "unable to remove old node-container"))
 			}
-			_, err = client.CreateContainer(opts)
+			err = rt.CreateContainer(opts)
 			if err != nil {
 				multiErr.Add(errors.Wrapf(err, "unable to create new node-container"))
 				return multiErr
 			}
 		}
 	}
-	err = client.StartContainer(c.Name, nil)
+	err = rt.StartContainer(ctx, c.Name)
 	if _, ok := err.(*docker.ContainerAlreadyRunning); !ok {
 		return err
 	}
 	return nil
 }
 
-func tryRemovingOld(client *docker.Client, id string) error {
-	err := client.StopContainer(id, 10)
+func tryRemovingOld(rt Runtime, id string) error {
+	err := rt.StopContainer(id, 10)
 	if err == nil {
-		err = client.RemoveContainer(docker.RemoveContainerOptions{ID: id})
+		err = rt.RemoveContainer(id, false)
 	}
 	for retries := 2; err != nil && retries > 0; retries-- {
 		time.Sleep(time.Second)
-		err = client.RemoveContainer(docker.RemoveContainerOptions{ID: id, Force: true})
+		err = rt.RemoveContainer(id, true)
 	}
 	return err
 }
 
-func pullWithRetry(client *docker.Client, p DockerProvisioner, image string, maxTries int) (string, error) {
-	var buf bytes.Buffer
+// pullWithRetry pulls the image, retrying with exponential backoff on
+// failures. When the image can't be pulled, the configured registry mirrors
+// are tried in order before giving up. Images pulled through a P2P endpoint or
+// a mirror are tagged with their original names, so node containers keep
+// referencing them. The progress of the pull is streamed to w and the digest
+// of the pulled image is returned.
+func pullWithRetry(ctx context.Context, client *docker.Client, imageName, pool string, w *pullWriter) (string, error) {
+	defer w.Flush()
+	conf := loadPullConfig()
+	sources := []string{dockercommon.PullImageName(imageName, pool)}
+	for _, mirror := range conf.mirrors {
+		sources = append(sources, dockercommon.MirrorImageName(imageName, mirror))
+	}
 	var err error
-	pullOpts := docker.PullImageOptions{Repository: image, OutputStream: &buf, InactivityTimeout: net.StreamInactivityTimeout}
+	for _, source := range sources {
+		err = pullSource(ctx, client, source, conf, w)
+		if err != nil {
+			log.Errorf("[node containers] unable to pull image %q: %s", source, err)
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			continue
+		}
+		if source != imageName {
+			repo, tag := image.SplitImageName(imageName)
+			err = client.TagImage(source, docker.TagImageOptions{Repo: repo, Tag: tag, Force: true, Context: ctx})
+			if err != nil {
+				return "", err
+			}
+		}
+		return w.digest, nil
+	}
+	return "", err
+}
+
+func pullSource(ctx context.Context, client *docker.Client, imageName string, conf pullConfig, w *pullWriter) error {
 	registryAuth := dockercommon.RegistryAuthConfig()
-	for ; maxTries > 0; maxTries-- {
+	backoff := conf.backoff
+	var err error
+	for try := 1; ; try++ {
+		pullOpts := docker.PullImageOptions{
+			Repository:        imageName,
+			OutputStream:      w,
+			InactivityTimeout: net.StreamInactivityTimeout,
+			Context:           ctx,
+		}
 		err = client.PullImage(pullOpts, registryAuth)
+		w.Flush()
 		if err == nil {
-			return buf.String(), nil
+			return nil
+		}
+		if try >= conf.maxTries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxPullBackoff {
+			backoff = maxPullBackoff
 		}
 	}
-	return "", err
+}
+
+// pullWriter streams the progress of image pulls to a writer line by line,
+// prefixed by the address of the node, so pulls running in several nodes at
+// once can be told apart. Only the current line and the digest of the image
+// are kept in memory, regardless of the size of the image.
+type pullWriter struct {
+	w      io.Writer
+	prefix string
+	line   []byte
+	digest string
+}
+
+func newPullWriter(w io.Writer, address string) *pullWriter {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	return &pullWriter{w: w, prefix: fmt.Sprintf("  [%s] ", address)}
+}
+
+// Write never fails, as the pull must go on even when the output can't be
+// written anymore, like when the client streaming it goes away.
+func (pw *pullWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			pw.line = append(pw.line, p...)
+			if len(pw.line) >= maxPullLineSize {
+				pw.Flush()
+			}
+			break
+		}
+		pw.line = append(pw.line, p[:i]...)
+		pw.Flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Flush writes the pending line, if any.
+func (pw *pullWriter) Flush() {
+	if len(pw.line) == 0 {
+		return
+	}
+	line := string(pw.line)
+	pw.line = pw.line[:0]
+	if digest, err := fix.GetImageDigest(line); err == nil {
+		pw.digest = digest
+	}
+	fmt.Fprintf(pw.w, "%s%s\n", pw.prefix, line)
+}
+
+type pullConfig struct {
+	maxTries int
+	backoff  time.Duration
+	mirrors  []string
+}
+
+func loadPullConfig() pullConfig {
+	conf := pullConfig{maxTries: defaultPullMaxTries, backoff: defaultPullBackoff}
+	if maxTries, err := config.GetInt("docker:nodecontainer:pull:max-tries"); err == nil && maxTries > 0 {
+		conf.maxTries = maxTries
+	}
+	if backoff, err := config.GetFloat("docker:nodecontainer:pull:backoff"); err == nil && backoff >= 0 {
+		conf.backoff = time.Duration(backoff * float64(time.Second))
+	}
+	conf.mirrors, _ = config.GetList("docker:nodecontainer:pull:mirrors")
+	return conf
 }
 
 func RemoveNamedContainers(p DockerProvisioner, w io.Writer, name string, pool string) error {
@@ -227,12 +484,12 @@ func RemoveNamedContainers(p DockerProvisioner, w io.Writer, name string, pool s
 	wg := sync.WaitGroup{}
 	removeContainer := func(node *cluster.Node) {
 		pool := node.Metadata[provision.PoolMetadataName]
-		client, err := node.Client()
+		rt, err := RuntimeForNode(node)
 		if err != nil {
 			errChan <- err
 			return
 		}
-		err = client.StopContainer(name, 10)
+		err = rt.StopContainer(name, 10)
 		if err != nil {
 			if _, ok := err.(*docker.NoSuchContainer); ok {
 				log.Debugf("[node containers] no such container %q in %s [%s]", name, node.Address, pool)
@@ -247,7 +504,7 @@ func RemoveNamedContainers(p DockerProvisioner, w io.Writer, name string, pool s
 		}
 		log.Debugf("[node containers] removing container %q in %s [%s]", name, node.Address, pool)
 		fmt.Fprintf(w, "removing node container %q in the node %s [%s]\n", name, node.Address, pool)
-		err = client.RemoveContainer(docker.RemoveContainerOptions{ID: name, Force: true})
+		err = rt.RemoveContainer(name, true)
 		if err != nil {
 			err = errors.Wrapf(err, "[node containers] failed to remove container in %s [%s]", node.Address, pool)
 			errChan <- err
@@ -272,12 +529,198 @@ func RemoveNamedContainers(p DockerProvisioner, w io.Writer, name string, pool s
 	return tsuruErrors.NewMultiError(allErrors...)
 }
 
+// Logs writes the logs of the node container with the given name running in
+// the node with the given address.
+func Logs(p DockerProvisioner, address string, name string, opts provision.NodeContainerLogsOptions) error {
+	node, err := p.Cluster().GetNode(address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rt, err := RuntimeForNode(&node)
+	if err != nil {
+		return err
+	}
+	tail := "all"
+	if opts.Lines > 0 {
+		tail = strconv.Itoa(opts.Lines)
+	}
+	err = rt.Logs(docker.LogsOptions{
+		Container:    name,
+		OutputStream: opts.Writer,
+		ErrorStream:  opts.Writer,
+		Stdout:       true,
+		Stderr:       true,
+		Follow:       opts.Follow,
+		Tail:         tail,
+	})
+	if dockerErr, ok := err.(*docker.Error); ok && dockerErr.Status == http.StatusNotFound {
+		return nodecontainer.ErrNodeContainerNotFound
+	}
+	return err
+}
+
+// Status returns the state of the node container with the given name in
+// each node of the pool, or in every node if pool is empty, comparing it
+// with the config stored for the pool of the node. Nodes that can't be
+// reached have the failure reported in the Error field of their status.
+func Status(p DockerProvisioner, name string, pool string) ([]provision.NodeContainerStatus, error) {
+	_, err := nodecontainer.LoadNodeContainer(pool, name)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := poolNodes(p, pool)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]provision.NodeContainerStatus, len(nodes))
+	wg := sync.WaitGroup{}
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result[i] = nodeStatus(&nodes[i], name)
+		}(i)
+	}
+	wg.Wait()
+	return result, nil
+}
+
+func nodeStatus(node *cluster.Node, name string) provision.NodeContainerStatus {
+	pool := node.Metadata[provision.PoolMetadataName]
+	status := provision.NodeContainerStatus{Node: node.Address, Pool: pool}
+	conf, err := nodecontainer.LoadNodeContainer(pool, name)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	rt, err := RuntimeForNode(node)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	cont, err := rt.InspectContainer(name)
+	if err != nil {
+		if _, ok := err.(*docker.NoSuchContainer); !ok {
+			status.Error = err.Error()
+		}
+		return status
+	}
+	status.Exists = true
+	status.State = cont.State.StateString()
+	if cont.State.Running {
+		startedAt := cont.State.StartedAt
+		status.StartedAt = &startedAt
+		status.Uptime = time.Since(cont.State.StartedAt).Round(time.Second).String()
+	}
+	status.Image = cont.Config.Image
+	if img, imgErr := rt.InspectImage(cont.Image); imgErr == nil && len(img.RepoDigests) > 0 {
+		parts := strings.SplitN(img.RepoDigests[0], "@", 2)
+		status.ImageDigest = parts[len(parts)-1]
+	}
+	conf.ApplySecurity()
+	status.Mismatches = configMismatches(conf, rt, node.Address, cont, status.ImageDigest)
+	status.ConfigMatches = len(status.Mismatches) == 0
+	return status
+}
+
+// configMismatches lists the items of the container config that differ from
+// the ones create would use for the given node container config.
+func configMismatches(conf *nodecontainer.NodeContainerConfig, rt Runtime, address string, cont *docker.Container, imageDigest string) []string {
+	var mismatches []string
+	expectedImage := rt.ImageName(conf.Image())
+	imageMatches := cont.Config.Image == expectedImage
+	if !imageMatches && conf.PinnedImage != "" && imageDigest != "" {
+		// Containers created before their image was pinned run the
+		// unpinned name of the same image.
+		imageMatches = cont.Config.Image+"@"+imageDigest == rt.ImageName(conf.PinnedImage)
+	}
+	if !imageMatches {
+		mismatches = append(mismatches, "image")
+	}
+	actualEnvs := make(map[string]struct{}, len(cont.Config.Env))
+	actualEnvNames := make(map[string]struct{}, len(cont.Config.Env))
+	for _, env := range cont.Config.Env {
+		actualEnvs[env] = struct{}{}
+		actualEnvNames[strings.SplitN(env, "=", 2)[0]] = struct{}{}
+	}
+	expectedEnvs := append([]string{"DOCKER_ENDPOINT=" + address}, conf.EnvListForNode(address)...)
+	for _, env := range expectedEnvs {
+		name := strings.SplitN(env, "=", 2)[0]
+		if strings.HasPrefix(strings.TrimPrefix(env, name+"="), nodecontainer.SecretRefPrefix) {
+			// Containers run the resolved secret values, when the secret
+			// can't be resolved only the env name is compared.
+			resolved, err := nodecontainer.ResolveEnvs([]string{env})
+			if err != nil {
+				if _, ok := actualEnvNames[name]; !ok {
+					mismatches = append(mismatches, "env "+name)
+				}
+				continue
+			}
+			env = resolved[0]
+		}
+		if _, ok := actualEnvs[env]; !ok {
+			mismatches = append(mismatches, "env "+name)
+		}
+	}
+	if cont.HostConfig == nil {
+		return append(mismatches, "hostConfig")
+	}
+	if cont.HostConfig.Privileged != conf.HostConfig.Privileged {
+		mismatches = append(mismatches, "privileged")
+	}
+	if cont.HostConfig.NetworkMode != conf.HostConfig.NetworkMode && conf.HostConfig.NetworkMode != "" {
+		mismatches = append(mismatches, "networkMode")
+	}
+	if cont.HostConfig.RestartPolicy.Name != conf.HostConfig.RestartPolicy.Name {
+		mismatches = append(mismatches, "restartPolicy")
+	}
+	if !sameStrings(cont.HostConfig.Binds, conf.HostConfig.Binds) {
+		mismatches = append(mismatches, "binds")
+	}
+	if !sameStrings(cont.HostConfig.ExtraHosts, conf.HostConfig.ExtraHosts) {
+		mismatches = append(mismatches, "extraHosts")
+	}
+	if !sameStrings(cont.HostConfig.DNS, conf.HostConfig.DNS) {
+		mismatches = append(mismatches, "dns")
+	}
+	if !sameStrings(cont.HostConfig.DNSSearch, conf.HostConfig.DNSSearch) {
+		mismatches = append(mismatches, "dnsSearch")
+	}
+	if !sameStrings(cont.HostConfig.CapAdd, conf.HostConfig.CapAdd) {
+		mismatches = append(mismatches, "capAdd")
+	}
+	if !sameStrings(cont.HostConfig.CapDrop, conf.HostConfig.CapDrop) {
+		mismatches = append(mismatches, "capDrop")
+	}
+	if !sameStrings(cont.HostConfig.SecurityOpt, conf.HostConfig.SecurityOpt) {
+		mismatches = append(mismatches, "securityOpt")
+	}
+	return mismatches
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[string]int, len(a))
+	for _, v := range a {
+		count[v]++
+	}
+	for _, v := range b {
+		count[v]--
+		if count[v] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
 type ClusterHook struct {
 	Provisioner DockerProvisioner
 }
 
 func (h *ClusterHook) RunClusterHook(evt cluster.HookEvent, node *cluster.Node) error {
-	err := ensureContainersStarted(h.Provisioner, nil, false, nil, *node)
+	err := ensureContainersStarted(context.Background(), h.Provisioner, nil, false, nil, *node)
 	if err != nil {
 		return errors.Wrap(err, "unable to start node containers")
 	}
@@ -6,6 +6,7 @@ package nodecontainer
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -13,12 +14,16 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/fsouza/go-dockerclient/testing"
 	"github.com/tsuru/config"
 	"github.com/tsuru/docker-cluster/cluster"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/dockertest"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
@@ -80,6 +85,55 @@ func (s *S) TestRecreateNamedContainers(c *check.C) {
 	c.Assert(paths2, check.DeepEquals, expectedPaths)
 }
 
+func (s *S) TestRecreateNamedContainersDry(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	nodes, err := p.Cluster().Nodes()
+	c.Assert(err, check.IsNil)
+	for i, n := range nodes {
+		n.Metadata["pool"] = fmt.Sprintf("p-%d", i)
+		_, err = p.Cluster().UpdateNode(n)
+		c.Assert(err, check.IsNil)
+	}
+	err = nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:        "c1",
+		PinnedImage: "img1@sha256:abcef384829283eff",
+		Config:      docker.Config{Image: "img1", Env: []string{"A=1"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.AddNewContainer("p-1", &nodecontainer.NodeContainerConfig{
+		Name:     "c1",
+		Config:   docker.Config{Image: "img2"},
+		NodeEnvs: []nodecontainer.NodeEnv{{Address: nodes[1].Address, Env: []string{"A=2"}}},
+	})
+	c.Assert(err, check.IsNil)
+	var paths []string
+	for _, server := range p.Servers() {
+		server := server
+		server.CustomHandler("/.*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.Method+" "+r.URL.Path)
+			server.DefaultHandler().ServeHTTP(w, r)
+		}))
+	}
+	buf := safe.NewBuffer(nil)
+	err = RecreateNamedContainersDry(p, buf, "c1", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(paths, check.IsNil)
+	c.Assert(buf.String(), check.Equals, fmt.Sprintf(`would relaunch node container "c1" in the node %[1]s [p-0]
+  image: img1
+  env: DOCKER_ENDPOINT A
+would relaunch node container "c1" in the node %[2]s [p-1]
+  image: img2
+  env: DOCKER_ENDPOINT A
+`, nodes[0].Address, nodes[1].Address))
+	buf.Reset()
+	err = RecreateNamedContainersDry(p, buf, "c1", "p-1")
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `would relaunch node container "c1" in the node .* \[p-1\]\n(?s).*`)
+	c.Assert(strings.Count(buf.String(), "would relaunch"), check.Equals, 1)
+}
+
 func (s *S) TestEnsureContainersStarted(c *check.C) {
 	c1 := nodecontainer.NodeContainerConfig{
 		Name: "bs",
@@ -128,7 +182,7 @@ func (s *S) TestEnsureContainersStarted(c *check.C) {
 	}))
 	defer p.Destroy()
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	parts := strings.Split(buf.String(), "\n")
 	c.Assert(parts, check.HasLen, 5)
@@ -192,6 +246,267 @@ func (s *S) TestEnsureContainersStarted(c *check.C) {
 	c.Assert(containers, check.HasLen, 2)
 }
 
+func (s *S) TestEnsureContainersStartedBindsAndMounts(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "bsimg"},
+		HostConfig: docker.HostConfig{
+			Binds: []string{"/proc:/prochost:ro", "/var/log:/var/log:ro"},
+			Mounts: []docker.HostMount{
+				{Source: "/run/systemd/journal", Target: "/run/systemd/journal", Type: "bind", ReadOnly: true},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	var createBodies []string
+	var mut sync.Mutex
+	server := p.Servers()[0]
+	server.CustomHandler("/containers/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+		data, _ := ioutil.ReadAll(r.Body)
+		createBodies = append(createBodies, string(data))
+		r.Body = ioutil.NopCloser(bytes.NewBuffer(data))
+		server.DefaultHandler().ServeHTTP(w, r)
+	}))
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, false, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(createBodies, check.HasLen, 1)
+	var result struct {
+		HostConfig docker.HostConfig
+	}
+	err = json.Unmarshal([]byte(createBodies[0]), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.HostConfig.Binds, check.DeepEquals, []string{"/proc:/prochost:ro", "/var/log:/var/log:ro"})
+	c.Assert(result.HostConfig.Mounts, check.DeepEquals, []docker.HostMount{
+		{Source: "/run/systemd/journal", Target: "/run/systemd/journal", Type: "bind", ReadOnly: true},
+	})
+}
+
+func (s *S) TestEnsureContainersStartedSecurityProfile(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:       nodecontainer.BsDefaultName,
+		Config:     docker.Config{Image: "bsimg"},
+		HostConfig: docker.HostConfig{Privileged: true, NetworkMode: "host"},
+	})
+	c.Assert(err, check.IsNil)
+	privileged := false
+	err = nodecontainer.AddNewContainer("p1", &nodecontainer.NodeContainerConfig{
+		Name: nodecontainer.BsDefaultName,
+		Security: &nodecontainer.SecurityProfile{
+			Privileged:      &privileged,
+			CapAdd:          []string{"SYS_PTRACE"},
+			CapDrop:         []string{"ALL"},
+			AppArmorProfile: "bs-profile",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	nodes, err := p.Cluster().UnfilteredNodes()
+	c.Assert(err, check.IsNil)
+	var createBodies []string
+	var mut sync.Mutex
+	server := p.Servers()[0]
+	server.CustomHandler("/containers/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+		data, _ := ioutil.ReadAll(r.Body)
+		createBodies = append(createBodies, string(data))
+		r.Body = ioutil.NopCloser(bytes.NewBuffer(data))
+		server.DefaultHandler().ServeHTTP(w, r)
+	}))
+	for i := range nodes {
+		nodes[i].Metadata = map[string]string{"pool": "p1"}
+	}
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, false, nil, nodes...)
+	c.Assert(err, check.IsNil)
+	c.Assert(createBodies, check.HasLen, 1)
+	var result struct {
+		HostConfig docker.HostConfig
+	}
+	err = json.Unmarshal([]byte(createBodies[0]), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.HostConfig.Privileged, check.Equals, false)
+	c.Assert(result.HostConfig.NetworkMode, check.Equals, "host")
+	c.Assert(result.HostConfig.CapAdd, check.DeepEquals, []string{"SYS_PTRACE"})
+	c.Assert(result.HostConfig.CapDrop, check.DeepEquals, []string{"ALL"})
+	c.Assert(result.HostConfig.SecurityOpt, check.DeepEquals, []string{"apparmor=bs-profile"})
+}
+
+func (s *S) TestEnsureContainersStartedResolvesSecrets(c *check.C) {
+	err := nodecontainer.SetSecret("bs-token", "abc123")
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "bsimg", Env: []string{"A=1", "TOKEN=secret://tsuru/bs-token"}},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.IsNil)
+	client, err := docker.NewClient(p.Servers()[0].URL())
+	c.Assert(err, check.IsNil)
+	cont, err := client.InspectContainer(nodecontainer.BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(cont.Config.Env, check.DeepEquals, []string{"DOCKER_ENDPOINT=" + p.Servers()[0].URL(), "A=1", "TOKEN=abc123"})
+	conf, err := nodecontainer.LoadNodeContainer("", nodecontainer.BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(conf.Config.Env, check.DeepEquals, []string{"A=1", "TOKEN=secret://tsuru/bs-token"})
+}
+
+func (s *S) TestEnsureContainersStartedSecretNotFound(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "bsimg", Env: []string{"TOKEN=secret://tsuru/missing"}},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.ErrorMatches, `(?s).*unable to resolve env "TOKEN": node container secret not found.*`)
+}
+
+func (s *S) TestEnsureContainersStartedLogConfig(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "bsimg"},
+		HostConfig: docker.HostConfig{
+			LogConfig: docker.LogConfig{
+				Type:   "fluentd",
+				Config: map[string]string{"fluentd-address": "localhost:24224", "tag": "bs"},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, false, nil)
+	c.Assert(err, check.IsNil)
+	client, err := docker.NewClient(p.Servers()[0].URL())
+	c.Assert(err, check.IsNil)
+	container, err := client.InspectContainer(nodecontainer.BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(container.HostConfig.LogConfig, check.DeepEquals, docker.LogConfig{
+		Type:   "fluentd",
+		Config: map[string]string{"fluentd-address": "localhost:24224", "tag": "bs"},
+	})
+}
+
+func (s *S) TestEnsureContainersStartedEvents(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "bsimg"},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	nodes, err := p.Cluster().Nodes()
+	c.Assert(err, check.IsNil)
+	for i, n := range nodes {
+		n.Metadata["pool"] = fmt.Sprintf("p-%d", i)
+		_, err = p.Cluster().UpdateNode(n)
+		c.Assert(err, check.IsNil)
+	}
+	p.Servers()[1].PrepareFailure("create-failure", "/containers/create")
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, false, nil)
+	c.Assert(err, check.NotNil)
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil, nodes[0])
+	c.Assert(err, check.IsNil)
+	for _, kind := range []string{EventKindCreate, EventKindRecreate} {
+		c.Assert(eventtest.EventDesc{
+			Target: event.Target{Type: event.TargetTypeNode, Value: nodes[0].Address},
+			ExtraTargets: []event.ExtraTarget{
+				{Target: event.Target{Type: event.TargetTypeNodeContainer, Value: nodecontainer.BsDefaultName}},
+				{Target: event.Target{Type: event.TargetTypePool, Value: "p-0"}},
+			},
+			Kind: kind,
+			StartCustomData: map[string]interface{}{
+				"name":  nodecontainer.BsDefaultName,
+				"node":  nodes[0].Address,
+				"pool":  "p-0",
+				"image": "bsimg",
+			},
+		}, eventtest.HasEvent)
+	}
+	c.Assert(eventtest.EventDesc{
+		Target:       event.Target{Type: event.TargetTypeNode, Value: nodes[1].Address},
+		Kind:         EventKindCreate,
+		ErrorMatches: `.*create-failure.*`,
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestEnsureContainersStartedCustomLabels(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name: nodecontainer.BsDefaultName,
+		Config: docker.Config{
+			Image: "bsimg",
+			Labels: map[string]string{
+				"team":                "infra",
+				"cost-center":         "1234",
+				"node-container-name": "other",
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, false, nil)
+	c.Assert(err, check.IsNil)
+	client, err := docker.NewClient(p.Servers()[0].URL())
+	c.Assert(err, check.IsNil)
+	container, err := client.InspectContainer(nodecontainer.BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(container.Config.Labels, check.DeepEquals, map[string]string{
+		"team":                "infra",
+		"cost-center":         "1234",
+		"is-tsuru":            "true",
+		"is-node-container":   "true",
+		"provisioner":         "fake",
+		"node-container-name": nodecontainer.BsDefaultName,
+		"node-container-pool": "",
+	})
+}
+
+func (s *S) TestEnsureContainersStartedNodeEnvs(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	nodes, err := p.Cluster().Nodes()
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "bsimg", Env: []string{"A=1", "B=2"}},
+		NodeEnvs: []nodecontainer.NodeEnv{
+			{Address: nodes[0].Address, Env: []string{"B=3", "C=4"}},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = ensureContainersStarted(context.Background(), p, nil, true, nil)
+	c.Assert(err, check.IsNil)
+	expectedEnvs := [][]string{
+		{"DOCKER_ENDPOINT=" + nodes[0].Address, "A=1", "B=3", "C=4"},
+		{"DOCKER_ENDPOINT=" + nodes[1].Address, "A=1", "B=2"},
+	}
+	for i, node := range nodes {
+		client, err := node.Client()
+		c.Assert(err, check.IsNil)
+		container, err := client.InspectContainer("bs")
+		c.Assert(err, check.IsNil)
+		c.Assert(container.Config.Env, check.DeepEquals, expectedEnvs[i])
+	}
+}
+
 func (s *S) TestEnsureContainersStartedMaxWorkers(c *check.C) {
 	config.Set("docker:nodecontainer:max-workers", 1)
 	defer config.Unset("docker:nodecontainer:max-workers")
@@ -217,7 +532,7 @@ func (s *S) TestEnsureContainersStartedMaxWorkers(c *check.C) {
 		server.DefaultHandler().ServeHTTP(w, r)
 	}))
 	defer p.Destroy()
-	go ensureContainersStarted(p, nil, true, nil)
+	go ensureContainersStarted(context.Background(), p, nil, true, nil)
 	<-begin
 	select {
 	case <-begin:
@@ -234,6 +549,129 @@ func (s *S) TestEnsureContainersStartedMaxWorkers(c *check.C) {
 	c.Assert(calls, check.Equals, 2)
 }
 
+func (s *S) TestEnsureContainersStartedRecreateConcurrency(c *check.C) {
+	config.Set("docker:bs:recreate-concurrency", 1)
+	defer config.Unset("docker:bs:recreate-concurrency")
+	c1 := nodecontainer.NodeContainerConfig{Name: "bs", Config: docker.Config{Image: "bsimg"}}
+	err := nodecontainer.AddNewContainer("", &c1)
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	done := make(chan struct{})
+	begin := make(chan struct{}, 2)
+	var calls int32
+	for _, server := range p.Servers() {
+		server := server
+		server.CustomHandler("/containers/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			begin <- struct{}{}
+			server.DefaultHandler().ServeHTTP(w, r)
+			<-done
+		}))
+	}
+	buf := safe.NewBuffer(nil)
+	errCh := make(chan error)
+	go func() {
+		errCh <- ensureContainersStarted(context.Background(), p, buf, true, nil)
+	}()
+	<-begin
+	select {
+	case <-begin:
+		c.Fatal("second call should only happen after first finishes")
+	case <-time.After(200 * time.Millisecond):
+	}
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(1))
+	done <- struct{}{}
+	select {
+	case <-time.After(5 * time.Second):
+		c.Fatal("second call should been triggered")
+	case <-begin:
+	}
+	done <- struct{}{}
+	c.Assert(<-errCh, check.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(2))
+	c.Assert(buf.String(), check.Not(check.Matches), `(?s).*node containers processed.*`)
+}
+
+func (s *S) TestRecreateConcurrency(c *check.C) {
+	c.Assert(recreateConcurrency(), check.Equals, defaultRecreateConcurrency)
+	config.Set("docker:bs:recreate-concurrency", 50)
+	defer config.Unset("docker:bs:recreate-concurrency")
+	c.Assert(recreateConcurrency(), check.Equals, 50)
+	config.Set("docker:bs:recreate-concurrency", -1)
+	c.Assert(recreateConcurrency(), check.Equals, defaultRecreateConcurrency)
+}
+
+func (s *S) TestEnsureContainersStartedRollingProgress(c *check.C) {
+	config.Set("docker:nodecontainer:max-workers", 1)
+	defer config.Unset("docker:nodecontainer:max-workers")
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{Name: "bs", Config: docker.Config{Image: "bsimg"}})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	buf := safe.NewBuffer(nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
+	c.Assert(err, check.IsNil)
+	parts := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(parts, check.HasLen, 4)
+	c.Assert(parts[0], check.Matches, `relaunching node container "bs" in the node .*`)
+	c.Assert(parts[1], check.Equals, "node containers processed in 1 of 2 nodes, 0 failed")
+	c.Assert(parts[2], check.Matches, `relaunching node container "bs" in the node .*`)
+	c.Assert(parts[3], check.Equals, "node containers processed in 2 of 2 nodes, 0 failed")
+}
+
+func (s *S) TestEnsureContainersStartedMaxFailures(c *check.C) {
+	config.Set("docker:nodecontainer:max-workers", 1)
+	config.Set("docker:nodecontainer:max-failures", 1)
+	defer config.Unset("docker:nodecontainer")
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{Name: "bs", Config: docker.Config{Image: "bsimg"}})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	var calls int32
+	for _, server := range p.Servers() {
+		server.CustomHandler("/containers/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			http.Error(w, "create failed", http.StatusInternalServerError)
+		}))
+	}
+	buf := safe.NewBuffer(nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
+	c.Assert(err, check.NotNil)
+	multiErr, ok := err.(*tsuruErrors.MultiError)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(multiErr.Len(), check.Equals, 2)
+	c.Assert(multiErr.Error(), check.Matches, `(?s).*failed to create container in .*aborted after 1 failed nodes, 1 nodes skipped.*`)
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(1))
+	c.Assert(buf.String(), check.Matches, `(?s).*node containers processed in 1 of 2 nodes, 1 failed\n`)
+}
+
+func (s *S) TestEnsureContainersStartedReportsAllNodeErrors(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{Name: "bs", Config: docker.Config{Image: "bsimg"}})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	for _, server := range p.Servers() {
+		server.CustomHandler("/containers/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "create failed", http.StatusInternalServerError)
+		}))
+	}
+	err = ensureContainersStarted(context.Background(), p, nil, true, nil)
+	c.Assert(err, check.NotNil)
+	multiErr, ok := err.(*tsuruErrors.MultiError)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(multiErr.Len(), check.Equals, 2)
+	nodes, err := p.Cluster().Nodes()
+	c.Assert(err, check.IsNil)
+	for _, n := range nodes {
+		c.Assert(strings.Contains(multiErr.Error(), "failed to create container in "+n.Address), check.Equals, true)
+	}
+}
+
 func (s *S) TestEnsureContainersStartedPinImg(c *check.C) {
 	config.Set("docker:bs:image", "myregistry/tsuru/bs")
 	_, err := nodecontainer.InitializeBS(s.authScheme, "tsr")
@@ -263,7 +701,7 @@ func (s *S) TestEnsureContainersStartedPinImg(c *check.C) {
 	})
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
 	c.Assert(err, check.IsNil)
@@ -308,7 +746,7 @@ func (s *S) TestEnsureContainersStartedNoDigestNoPin(c *check.C) {
 	})
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
 	c.Assert(err, check.IsNil)
@@ -354,7 +792,7 @@ func (s *S) TestEnsureContainersStartedPinImgInParent(c *check.C) {
 	_, err = p.Cluster().UpdateNode(node)
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	all, err := nodecontainer.LoadNodeContainersForPoolsMerge("c1", false)
 	c.Assert(err, check.IsNil)
@@ -397,7 +835,7 @@ func (s *S) TestEnsureContainersStartedPinImgInChild(c *check.C) {
 	_, err = p.Cluster().UpdateNode(node)
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	all, err := nodecontainer.LoadNodeContainersForPoolsMerge("c1", false)
 	c.Assert(err, check.IsNil)
@@ -441,7 +879,7 @@ func (s *S) TestEnsureContainersStartedAlreadyPinned(c *check.C) {
 	})
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
 	c.Assert(err, check.IsNil)
@@ -479,7 +917,7 @@ func (s *S) TestEnsureContainersStartedOnlyChild(c *check.C) {
 	_, err = p.Cluster().UpdateNode(nodes[1])
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	client, err := docker.NewClient(nodes[0].Address)
 	c.Assert(err, check.IsNil)
@@ -636,7 +1074,7 @@ func (s *S) TestEnsureContainersStartedWithoutRelaunch(c *check.C) {
 	client, err := docker.NewClient(server.URL())
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, false, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, false, nil)
 	c.Assert(err, check.IsNil)
 	var paths []string
 	for _, r := range reqs {
@@ -649,7 +1087,7 @@ func (s *S) TestEnsureContainersStartedWithoutRelaunch(c *check.C) {
 		"POST /containers/big-sibling/start",
 	})
 	reqs = nil
-	err = ensureContainersStarted(p, buf, false, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, false, nil)
 	c.Assert(err, check.IsNil)
 	paths = nil
 	for _, r := range reqs {
@@ -685,7 +1123,7 @@ func (s *S) TestEnsureContainersStartedGracefullyStop(c *check.C) {
 	client, err := docker.NewClient(server.URL())
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	var paths []string
 	for _, r := range reqs {
@@ -698,7 +1136,7 @@ func (s *S) TestEnsureContainersStartedGracefullyStop(c *check.C) {
 		"POST /containers/big-sibling/start",
 	})
 	reqs = nil
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	paths = nil
 	for _, r := range reqs {
@@ -752,10 +1190,10 @@ func (s *S) TestEnsureContainersStartedForceStopOnlyOnFailure(c *check.C) {
 	client, err := docker.NewClient(server.URL())
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	reqs = nil
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
 	var paths []string
 	for _, r := range reqs {
@@ -802,9 +1240,9 @@ func (s *S) TestEnsureContainersStartedTryCreatingAfterRmFailure(c *check.C) {
 	c.Assert(err, check.IsNil)
 	defer p.Destroy()
 	buf := safe.NewBuffer(nil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.IsNil)
-	err = ensureContainersStarted(p, buf, true, nil)
+	err = ensureContainersStarted(context.Background(), p, buf, true, nil)
 	c.Assert(err, check.ErrorMatches, `(?s).*API error \(500\): my error.*unable to remove old node-container.*container already exists.*unable to create new node-container.*`)
 }
 
@@ -905,7 +1343,7 @@ func (s *S) TestRemoveNamedContainers(c *check.C) {
 		paths2 = append(paths2, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
 		server2.DefaultHandler().ServeHTTP(w, r)
 	}))
-	err = ensureContainersStarted(p, ioutil.Discard, true, nil)
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
 	c.Assert(err, check.IsNil)
 	paths = nil
 	paths2 = nil
@@ -914,7 +1352,7 @@ func (s *S) TestRemoveNamedContainers(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(paths, check.DeepEquals, expectedPaths)
 	c.Assert(paths2, check.DeepEquals, expectedPaths)
-	err = ensureContainersStarted(p, ioutil.Discard, true, nil)
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
 	c.Assert(err, check.IsNil)
 	paths = nil
 	paths2 = nil
@@ -923,3 +1361,275 @@ func (s *S) TestRemoveNamedContainers(c *check.C) {
 	c.Assert(paths, check.DeepEquals, []string(nil))
 	c.Assert(paths2, check.DeepEquals, expectedPaths)
 }
+
+func (s *S) TestLogs(c *check.C) {
+	config.Set("docker:bs:image", "myregistry/tsuru/bs")
+	_, err := nodecontainer.InitializeBS(s.authScheme, "tsr")
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.IsNil)
+	server := p.Servers()[0]
+	var paths []string
+	var tail string
+	server.CustomHandler("/containers/.*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		tail = r.URL.Query().Get("tail")
+		server.DefaultHandler().ServeHTTP(w, r)
+	}))
+	var buf bytes.Buffer
+	err = Logs(p, server.URL(), "big-sibling", provision.NodeContainerLogsOptions{Writer: &buf, Lines: 10})
+	c.Assert(err, check.IsNil)
+	c.Assert(paths, check.DeepEquals, []string{"GET /containers/big-sibling/logs"})
+	c.Assert(tail, check.Equals, "10")
+	c.Assert(buf.String(), check.Equals, "Container is running\nWhat happened?\nSomething happened\n")
+}
+
+func (s *S) TestLogsContainerNotFound(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = Logs(p, p.Servers()[0].URL(), "big-sibling", provision.NodeContainerLogsOptions{Writer: ioutil.Discard})
+	c.Assert(err, check.Equals, nodecontainer.ErrNodeContainerNotFound)
+}
+
+func (s *S) TestPullWithRetryBackoff(c *check.C) {
+	config.Set("docker:nodecontainer:pull:backoff", 0)
+	defer config.Unset("docker:nodecontainer:pull")
+	server, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server.Stop()
+	var calls int32
+	server.CustomHandler("/images/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		server.DefaultHandler().ServeHTTP(w, r)
+	}))
+	client, err := docker.NewClient(server.URL())
+	c.Assert(err, check.IsNil)
+	_, err = pullWithRetry(context.Background(), client, "tsuru/bs", "", newPullWriter(nil, server.URL()))
+	c.Assert(err, check.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(3))
+	atomic.StoreInt32(&calls, -10)
+	config.Set("docker:nodecontainer:pull:max-tries", 2)
+	_, err = pullWithRetry(context.Background(), client, "tsuru/bs", "", newPullWriter(nil, server.URL()))
+	c.Assert(err, check.NotNil)
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(-8))
+}
+
+func (s *S) TestPullWithRetryMirrorFallback(c *check.C) {
+	config.Set("docker:nodecontainer:pull:backoff", 0)
+	config.Set("docker:nodecontainer:pull:max-tries", 2)
+	config.Set("docker:nodecontainer:pull:mirrors", []interface{}{"mirror1.local", "mirror2.local:5000"})
+	defer config.Unset("docker:nodecontainer:pull")
+	server, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server.Stop()
+	var mut sync.Mutex
+	var pulled []string
+	server.CustomHandler("/images/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+		from := r.URL.Query().Get("fromImage")
+		pulled = append(pulled, from)
+		if !strings.HasPrefix(from, "mirror2.local:5000/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		server.DefaultHandler().ServeHTTP(w, r)
+	}))
+	client, err := docker.NewClient(server.URL())
+	c.Assert(err, check.IsNil)
+	_, err = pullWithRetry(context.Background(), client, "myregistry.com/tsuru/bs:v1", "", newPullWriter(nil, server.URL()))
+	c.Assert(err, check.IsNil)
+	c.Assert(pulled, check.DeepEquals, []string{
+		"myregistry.com/tsuru/bs:v1",
+		"myregistry.com/tsuru/bs:v1",
+		"mirror1.local/tsuru/bs:v1",
+		"mirror1.local/tsuru/bs:v1",
+		"mirror2.local:5000/tsuru/bs:v1",
+	})
+	_, err = client.InspectImage("myregistry.com/tsuru/bs:v1")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestPullWithRetryStreamsProgress(c *check.C) {
+	config.Set("docker:nodecontainer:pull:backoff", 0)
+	defer config.Unset("docker:nodecontainer:pull")
+	server, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server.Stop()
+	server.CustomHandler("/images/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"status":"Pulling from tsuru/bs","id":"v1"}`)
+		fmt.Fprintln(w, `{"status":"Downloading","progressDetail":{"current":10,"total":100},"progress":"[=>    ]","id":"abc"}`)
+		fmt.Fprintln(w, `{"status":"Pull complete","id":"abc"}`)
+		fmt.Fprintln(w, `{"status":"Digest: sha256:8a1e"}`)
+		fmt.Fprintln(w, `{"status":"Status: Downloaded newer image for tsuru/bs:v1"}`)
+	}))
+	client, err := docker.NewClient(server.URL())
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	digest, err := pullWithRetry(context.Background(), client, "tsuru/bs:v1", "", newPullWriter(&buf, "http://node1:2375"))
+	c.Assert(err, check.IsNil)
+	c.Assert(digest, check.Equals, "sha256:8a1e")
+	c.Assert(buf.String(), check.Equals, `  [http://node1:2375] v1: Pulling from tsuru/bs
+  [http://node1:2375] abc: Pull complete
+  [http://node1:2375] Digest: sha256:8a1e
+  [http://node1:2375] Status: Downloaded newer image for tsuru/bs:v1
+`)
+}
+
+func (s *S) TestPullWriter(c *check.C) {
+	var buf bytes.Buffer
+	w := newPullWriter(&buf, "n1")
+	n, err := w.Write([]byte("line 1\nline"))
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 11)
+	c.Assert(buf.String(), check.Equals, "  [n1] line 1\n")
+	w.Write([]byte(" 2\nDigest: sha256:abc\n\npartial"))
+	c.Assert(buf.String(), check.Equals, "  [n1] line 1\n  [n1] line 2\n  [n1] Digest: sha256:abc\n")
+	c.Assert(w.digest, check.Equals, "sha256:abc")
+	w.Flush()
+	c.Assert(buf.String(), check.Equals, "  [n1] line 1\n  [n1] line 2\n  [n1] Digest: sha256:abc\n  [n1] partial\n")
+	w.Write(bytes.Repeat([]byte("a"), maxPullLineSize+10))
+	c.Assert(len(w.line), check.Equals, 0)
+}
+
+func (s *S) TestStatus(c *check.C) {
+	config.Set("docker:bs:image", "myregistry/tsuru/bs")
+	_, err := nodecontainer.InitializeBS(s.authScheme, "tsr")
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.IsNil)
+	statuses, err := Status(p, nodecontainer.BsDefaultName, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 2)
+	for _, st := range statuses {
+		c.Assert(st.Error, check.Equals, "")
+		c.Assert(st.Exists, check.Equals, true)
+		c.Assert(st.State, check.Equals, "running")
+		c.Assert(st.Image, check.Equals, "myregistry/tsuru/bs")
+		c.Assert(st.Mismatches, check.IsNil)
+		c.Assert(st.ConfigMatches, check.Equals, true)
+	}
+	err = nodecontainer.UpdateContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Env: []string{"A=1"}},
+	})
+	c.Assert(err, check.IsNil)
+	statuses, err = Status(p, nodecontainer.BsDefaultName, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 2)
+	for _, st := range statuses {
+		c.Assert(st.Exists, check.Equals, true)
+		c.Assert(st.ConfigMatches, check.Equals, false)
+		c.Assert(st.Mismatches, check.DeepEquals, []string{"env A"})
+	}
+}
+
+func (s *S) TestStatusNetworkMismatches(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   nodecontainer.BsDefaultName,
+		Config: docker.Config{Image: "bsimg"},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.UpdateContainer("", &nodecontainer.NodeContainerConfig{
+		Name: nodecontainer.BsDefaultName,
+		HostConfig: docker.HostConfig{
+			ExtraHosts: []string{"tsuru.local:10.0.0.1"},
+			DNS:        []string{"8.8.8.8"},
+			DNSSearch:  []string{"tsuru.local"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	statuses, err := Status(p, nodecontainer.BsDefaultName, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 2)
+	for _, st := range statuses {
+		c.Assert(st.ConfigMatches, check.Equals, false)
+		c.Assert(st.Mismatches, check.DeepEquals, []string{"extraHosts", "dns", "dnsSearch"})
+	}
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.IsNil)
+	statuses, err = Status(p, nodecontainer.BsDefaultName, "")
+	c.Assert(err, check.IsNil)
+	for _, st := range statuses {
+		c.Assert(st.ConfigMatches, check.Equals, true)
+	}
+}
+
+func (s *S) TestStatusSecretEnvs(c *check.C) {
+	err := nodecontainer.SetSecret("token", "s3cr3t")
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1", Env: []string{"TOKEN=secret://tsuru/token"}},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.IsNil)
+	statuses, err := Status(p, "c1", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 2)
+	for _, st := range statuses {
+		c.Assert(st.Mismatches, check.IsNil)
+		c.Assert(st.ConfigMatches, check.Equals, true)
+		c.Assert(st.StartedAt, check.NotNil)
+	}
+	err = nodecontainer.SetSecret("token", "n3w")
+	c.Assert(err, check.IsNil)
+	statuses, err = Status(p, "c1", "")
+	c.Assert(err, check.IsNil)
+	for _, st := range statuses {
+		c.Assert(st.Mismatches, check.DeepEquals, []string{"env TOKEN"})
+	}
+	err = nodecontainer.RemoveSecret("token")
+	c.Assert(err, check.IsNil)
+	statuses, err = Status(p, "c1", "")
+	c.Assert(err, check.IsNil)
+	for _, st := range statuses {
+		c.Assert(st.Mismatches, check.IsNil)
+	}
+}
+
+func (s *S) TestStatusContainerNotFound(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1"},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	statuses, err := Status(p, "c1", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 2)
+	for _, st := range statuses {
+		c.Assert(st.Error, check.Equals, "")
+		c.Assert(st.Exists, check.Equals, false)
+	}
+}
+
+func (s *S) TestStatusNodeContainerNotFound(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	_, err = Status(p, "c1", "")
+	c.Assert(err, check.Equals, nodecontainer.ErrNodeContainerNotFound)
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+)
+
+// OrphanContainer is a node container running in a node of the cluster
+// without a config for the pool of the node, either because the config was
+// removed or because the node was moved to another pool.
+type OrphanContainer struct {
+	ID   string
+	Name string
+	Node string
+	Pool string
+}
+
+// FindOrphanContainers returns the node containers created by the
+// provisioner in the nodes of the cluster which no longer have a valid config
+// for the pool of the node. Nodes that can't be reached are reported in the
+// returned error, along with the containers found in the other ones.
+func FindOrphanContainers(p DockerProvisioner) ([]OrphanContainer, error) {
+	nodes, err := p.Cluster().UnfilteredNodes()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	selector := provision.NodeContainerLabels(provision.NodeContainerLabelsOpts{
+		Provisioner: p.GetName(),
+	}).ToIsNodeContainerSelector()
+	var filters []string
+	for k, v := range selector {
+		filters = append(filters, k+"="+v)
+	}
+	valid := map[[2]string]bool{}
+	multi := tsuruErrors.NewMultiError()
+	var result []OrphanContainer
+	for i := range nodes {
+		node := &nodes[i]
+		pool := node.Metadata[provision.PoolMetadataName]
+		rt, err := RuntimeForNode(node)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		containers, err := rt.ListContainers(docker.ListContainersOptions{
+			All:     true,
+			Filters: map[string][]string{"label": filters},
+		})
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to list containers in node %s", node.Address))
+			continue
+		}
+		for _, c := range containers {
+			cont, err := rt.InspectContainer(c.ID)
+			if err != nil {
+				multi.Add(errors.Wrapf(err, "unable to inspect container %s in node %s", c.ID, node.Address))
+				continue
+			}
+			if cont.Config == nil {
+				continue
+			}
+			labels := provision.LabelSet{Labels: cont.Config.Labels}
+			if !labels.IsNodeContainer() || labels.Provisioner() != p.GetName() {
+				continue
+			}
+			name := labels.NodeContainerName()
+			key := [2]string{pool, name}
+			isValid, ok := valid[key]
+			if !ok {
+				conf, err := nodecontainer.LoadNodeContainer(pool, name)
+				if err != nil {
+					multi.Add(err)
+					continue
+				}
+				isValid = conf.Valid()
+				valid[key] = isValid
+			}
+			if !isValid {
+				result = append(result, OrphanContainer{ID: c.ID, Name: name, Node: node.Address, Pool: pool})
+			}
+		}
+	}
+	return result, multi.ToError()
+}
+
+// RemoveOrphanContainer removes the container with the given id from the
+// node of the cluster with the given address.
+func RemoveOrphanContainer(p DockerProvisioner, address, id string) error {
+	node, err := p.Cluster().GetNode(address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rt, err := RuntimeForNode(&node)
+	if err != nil {
+		return err
+	}
+	return rt.RemoveContainer(id, true)
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"context"
+	"io/ioutil"
+	"sort"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/provision/docker/dockertest"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestFindOrphanContainers(c *check.C) {
+	for _, name := range []string{"c1", "c2"} {
+		err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+			Name:   name,
+			Config: docker.Config{Image: "img-" + name},
+		})
+		c.Assert(err, check.IsNil)
+	}
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, false, nil)
+	c.Assert(err, check.IsNil)
+	orphans, err := FindOrphanContainers(p)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 0)
+	err = nodecontainer.RemoveContainer("", "c2")
+	c.Assert(err, check.IsNil)
+	orphans, err = FindOrphanContainers(p)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 2)
+	var nodes []string
+	for _, o := range orphans {
+		c.Assert(o.Name, check.Equals, "c2")
+		c.Assert(o.Pool, check.Equals, "")
+		nodes = append(nodes, o.Node)
+	}
+	sort.Strings(nodes)
+	clusterNodes, err := p.Cluster().UnfilteredNodes()
+	c.Assert(err, check.IsNil)
+	var expected []string
+	for _, n := range clusterNodes {
+		expected = append(expected, n.Address)
+	}
+	sort.Strings(expected)
+	c.Assert(nodes, check.DeepEquals, expected)
+}
+
+func (s *S) TestRemoveOrphanContainer(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img-c1"},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, false, nil)
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.RemoveContainer("", "c1")
+	c.Assert(err, check.IsNil)
+	orphans, err := FindOrphanContainers(p)
+	c.Assert(err, check.IsNil)
+	c.Assert(orphans, check.HasLen, 2)
+	err = RemoveOrphanContainer(p, orphans[0].Node, orphans[0].ID)
+	c.Assert(err, check.IsNil)
+	remaining, err := FindOrphanContainers(p)
+	c.Assert(err, check.IsNil)
+	c.Assert(remaining, check.DeepEquals, orphans[1:])
+}
@@ -0,0 +1,29 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/jobs"
+)
+
+// ReconcileJobName is the name of the job registered by RegisterReconcileJob.
+const ReconcileJobName = "node-containers-reconcile"
+
+// RegisterReconcileJob registers the job that creates and starts the node
+// containers missing in the nodes of the cluster. It's disabled by default.
+func RegisterReconcileJob(p DockerProvisioner) error {
+	return jobs.Register(jobs.Job{
+		Name:        ReconcileJobName,
+		Description: "creates and starts missing node containers in docker nodes",
+		Schedule:    "@hourly",
+		Disabled:    true,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			return ensureContainersStarted(ctx, p, evt, false, nil)
+		},
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"context"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/docker/dockertest"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestReconcileJob(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "bsimg"},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = RegisterReconcileJob(p)
+	c.Assert(err, check.IsNil)
+	info, err := jobs.Get(ReconcileJobName)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Enabled, check.Equals, false)
+	c.Assert(info.Schedule, check.Equals, "@hourly")
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       jobs.Target(ReconcileJobName),
+		InternalKind: jobs.EventKindRun,
+		Allowed:      event.Allowed(permission.PermJobReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = jobs.Run(context.Background(), ReconcileJobName, evt)
+	c.Assert(err, check.IsNil)
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+	for _, server := range p.Servers() {
+		client, err := docker.NewClient(server.URL())
+		c.Assert(err, check.IsNil)
+		containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+		c.Assert(err, check.IsNil)
+		c.Assert(containers, check.HasLen, 1)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+)
+
+// RestartJobName is the name of the job registered by RegisterRestartJob.
+const RestartJobName = "node-containers-restart"
+
+// RegisterRestartJob registers the job that restarts the big-sibling
+// containers following the restart schedule in its config. It's disabled by
+// default.
+func RegisterRestartJob(p DockerProvisioner) error {
+	return jobs.Register(jobs.Job{
+		Name:        RestartJobName,
+		Description: "restarts big-sibling containers following their restart schedule",
+		Schedule:    "*/5 * * * *",
+		Disabled:    true,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			return restartScheduled(p, evt, time.Now().UTC())
+		},
+	})
+}
+
+// restartScheduled restarts the big-sibling containers whose restart time,
+// in the latest occurrence of their restart schedule, has come and which
+// haven't been started since then. Containers are restarted, not recreated,
+// keeping their config.
+func restartScheduled(p DockerProvisioner, w io.Writer, now time.Time) error {
+	nodes, err := p.Cluster().UnfilteredNodes()
+	if err != nil {
+		return err
+	}
+	multiErr := tsuruErrors.NewMultiError()
+	for i := range nodes {
+		node := &nodes[i]
+		pool := node.Metadata[provision.PoolMetadataName]
+		conf, err := nodecontainer.LoadNodeContainer(pool, nodecontainer.BsDefaultName)
+		if err != nil {
+			multiErr.Add(err)
+			continue
+		}
+		if !conf.Valid() {
+			continue
+		}
+		restartAt, due := conf.RestartTime(node.Address, now)
+		if !due {
+			continue
+		}
+		rt, err := RuntimeForNode(node)
+		if err != nil {
+			multiErr.Add(err)
+			continue
+		}
+		cont, err := rt.InspectContainer(conf.Name)
+		if err != nil {
+			if _, ok := err.(*docker.NoSuchContainer); !ok {
+				multiErr.Add(err)
+			}
+			continue
+		}
+		if !cont.State.StartedAt.Before(restartAt) {
+			continue
+		}
+		log.Debugf("[node containers] restarting container %q in %s [%s]", conf.Name, node.Address, pool)
+		fmt.Fprintf(w, "restarting node container %q in the node %s [%s]\n", conf.Name, node.Address, pool)
+		err = rt.StopContainer(conf.Name, 10)
+		if err != nil {
+			if _, ok := err.(*docker.ContainerNotRunning); !ok {
+				multiErr.Add(errors.Wrapf(err, "unable to stop node container %q in %s", conf.Name, node.Address))
+				continue
+			}
+		}
+		err = rt.StartContainer(context.Background(), conf.Name)
+		if err != nil {
+			multiErr.Add(errors.Wrapf(err, "unable to start node container %q in %s", conf.Name, node.Address))
+		}
+	}
+	return multiErr.ToError()
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/cron"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/provision/docker/dockertest"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestRegisterRestartJob(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = RegisterRestartJob(p)
+	c.Assert(err, check.IsNil)
+	info, err := jobs.Get(RestartJobName)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Enabled, check.Equals, false)
+	c.Assert(info.Schedule, check.Equals, "*/5 * * * *")
+}
+
+func (s *S) TestRestartScheduled(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:            nodecontainer.BsDefaultName,
+		Config:          docker.Config{Image: "bsimg"},
+		RestartSchedule: "0 4 * * *",
+	})
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	err = ensureContainersStarted(context.Background(), p, nil, false, nil)
+	c.Assert(err, check.IsNil)
+	startedAt := map[string]time.Time{}
+	for _, server := range p.Servers() {
+		client, err := docker.NewClient(server.URL())
+		c.Assert(err, check.IsNil)
+		cont, err := client.InspectContainer(nodecontainer.BsDefaultName)
+		c.Assert(err, check.IsNil)
+		startedAt[server.URL()] = cont.State.StartedAt
+	}
+	sched, err := cron.Parse("0 4 * * *")
+	c.Assert(err, check.IsNil)
+	occurrence := sched.Next(time.Now().UTC())
+	buf := bytes.Buffer{}
+	err = restartScheduled(p, &buf, occurrence.Add(-time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "")
+	err = restartScheduled(p, &buf, occurrence.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s)restarting node container "big-sibling" in the node .*restarting node container "big-sibling" in the node .*`)
+	for _, server := range p.Servers() {
+		client, err := docker.NewClient(server.URL())
+		c.Assert(err, check.IsNil)
+		cont, err := client.InspectContainer(nodecontainer.BsDefaultName)
+		c.Assert(err, check.IsNil)
+		c.Assert(cont.State.Running, check.Equals, true)
+		c.Assert(cont.State.StartedAt.After(startedAt[server.URL()]), check.Equals, true)
+	}
+}
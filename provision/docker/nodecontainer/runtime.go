@@ -0,0 +1,243 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+)
+
+const (
+	// RuntimeMetadataName is the node metadata selecting the runtime used
+	// to launch node containers in the node. Nodes without it use the
+	// Docker Engine.
+	RuntimeMetadataName = "container-runtime"
+	// RuntimeEndpointMetadataName is the node metadata with the address
+	// of the runtime API, when it differs from the node address.
+	RuntimeEndpointMetadataName = "container-runtime-endpoint"
+
+	RuntimeDocker = "docker"
+	RuntimePodman = "podman"
+	// RuntimeContainerd is recognized but not built in, containerd has no
+	// Docker compatible API. Nodes running containerd may use podman, or a
+	// runtime registered with RegisterRuntime under this name.
+	RuntimeContainerd = "containerd"
+)
+
+var ErrContainerdRuntimeUnavailable = errors.New("containerd runtime is not built in, register one with RegisterRuntime or use the podman runtime")
+
+// Runtime launches node containers in a node. Container configs use the
+// Docker Engine API types, and runtimes must report missing containers and
+// images with the errors returned by the docker client, like
+// docker.NoSuchContainer and docker.ErrContainerAlreadyExists.
+type Runtime interface {
+	// PullImage pulls the image, streaming its progress to w, and returns
+	// the digest of the pulled image, if reported by the runtime. The pull
+	// is aborted when ctx is done.
+	PullImage(ctx context.Context, image, pool string, w io.Writer) (string, error)
+	// ImageName returns the name given by the runtime to the image.
+	ImageName(image string) string
+	InspectImage(image string) (*docker.Image, error)
+	CreateContainer(opts docker.CreateContainerOptions) error
+	StartContainer(ctx context.Context, name string) error
+	StopContainer(name string, timeout uint) error
+	// RemoveContainer removes the container, killing it first if it's
+	// running and force is true.
+	RemoveContainer(name string, force bool) error
+	InspectContainer(name string) (*docker.Container, error)
+	ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error)
+	Logs(opts docker.LogsOptions) error
+}
+
+// RuntimeFactory returns the runtime for the given node, connecting to the
+// given endpoint.
+type RuntimeFactory func(node *cluster.Node, endpoint string) (Runtime, error)
+
+var (
+	runtimesMu sync.RWMutex
+	runtimes   = map[string]RuntimeFactory{
+		RuntimeDocker: newDockerRuntime,
+		RuntimePodman: newPodmanRuntime,
+	}
+)
+
+// RegisterRuntime makes a runtime available to be selected in node metadata.
+func RegisterRuntime(name string, factory RuntimeFactory) {
+	runtimesMu.Lock()
+	defer runtimesMu.Unlock()
+	runtimes[name] = factory
+}
+
+// RuntimeForNode returns the runtime selected in the node metadata.
+func RuntimeForNode(node *cluster.Node) (Runtime, error) {
+	name := node.Metadata[RuntimeMetadataName]
+	if name == "" {
+		name = RuntimeDocker
+	}
+	runtimesMu.RLock()
+	factory, ok := runtimes[name]
+	runtimesMu.RUnlock()
+	if !ok && name == RuntimeContainerd {
+		return nil, errors.Wrapf(ErrContainerdRuntimeUnavailable, "unable to use container runtime in node %s", node.Address)
+	}
+	if !ok {
+		return nil, errors.Errorf("unknown container runtime %q in node %s", name, node.Address)
+	}
+	endpoint := node.Metadata[RuntimeEndpointMetadataName]
+	if endpoint == "" {
+		endpoint = node.Address
+	}
+	return factory(node, endpoint)
+}
+
+// dockerRuntime launches node containers through the Docker API. Pulls,
+// creates and starts are bound to their operation timeouts and guarded by
+// the circuit breaker of the node.
+type dockerRuntime struct {
+	client  *docker.Client
+	address string
+}
+
+func newDockerRuntime(node *cluster.Node, endpoint string) (Runtime, error) {
+	client, err := endpointClient(node, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerRuntime{client: client, address: node.Address}, nil
+}
+
+// endpointClient returns a client connected to the endpoint using the TLS
+// certificates of the node.
+func endpointClient(node *cluster.Node, endpoint string) (*docker.Client, error) {
+	if endpoint == node.Address {
+		return node.Client()
+	}
+	endpointNode := *node
+	endpointNode.Address = endpoint
+	return endpointNode.Client()
+}
+
+// PullImage pulls the image in the node, aborting the pull, and the writes of
+// its progress to w, when ctx is done or the pull timeout expires.
+func (r *dockerRuntime) PullImage(ctx context.Context, image, pool string, w io.Writer) (string, error) {
+	err := dockercommon.NodeBreakerAllow(r.address)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := dockercommon.OperationContext(ctx, dockercommon.OperationPull)
+	defer cancel()
+	digest, err := pullWithRetry(ctx, r.client, image, pool, newPullWriter(w, r.address))
+	dockercommon.NodeBreakerDone(r.address, err)
+	return digest, err
+}
+
+func (r *dockerRuntime) ImageName(image string) string {
+	return image
+}
+
+func (r *dockerRuntime) InspectImage(image string) (*docker.Image, error) {
+	return r.client.InspectImage(image)
+}
+
+func (r *dockerRuntime) CreateContainer(opts docker.CreateContainerOptions) error {
+	err := dockercommon.NodeBreakerAllow(r.address)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := dockercommon.OperationContext(opts.Context, dockercommon.OperationCreate)
+	defer cancel()
+	opts.Context = ctx
+	_, err = r.client.CreateContainer(opts)
+	dockercommon.NodeBreakerDone(r.address, err)
+	return err
+}
+
+func (r *dockerRuntime) StartContainer(ctx context.Context, name string) error {
+	err := dockercommon.NodeBreakerAllow(r.address)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := dockercommon.OperationContext(ctx, dockercommon.OperationStart)
+	defer cancel()
+	err = r.client.StartContainerWithContext(name, nil, ctx)
+	dockercommon.NodeBreakerDone(r.address, err)
+	return err
+}
+
+func (r *dockerRuntime) StopContainer(name string, timeout uint) error {
+	return r.client.StopContainer(name, timeout)
+}
+
+func (r *dockerRuntime) RemoveContainer(name string, force bool) error {
+	return r.client.RemoveContainer(docker.RemoveContainerOptions{ID: name, Force: force})
+}
+
+func (r *dockerRuntime) InspectContainer(name string) (*docker.Container, error) {
+	return r.client.InspectContainer(name)
+}
+
+func (r *dockerRuntime) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
+	return r.client.ListContainers(opts)
+}
+
+func (r *dockerRuntime) Logs(opts docker.LogsOptions) error {
+	return r.client.Logs(opts)
+}
+
+// podmanRuntime launches node containers through the Docker compatible API
+// of podman. Podman doesn't resolve short image names without a registry
+// search list, so images are always referenced by their fully qualified
+// names.
+type podmanRuntime struct {
+	dockerRuntime
+}
+
+func newPodmanRuntime(node *cluster.Node, endpoint string) (Runtime, error) {
+	client, err := endpointClient(node, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &podmanRuntime{dockerRuntime{client: client, address: node.Address}}, nil
+}
+
+func (r *podmanRuntime) PullImage(ctx context.Context, image, pool string, w io.Writer) (string, error) {
+	return r.dockerRuntime.PullImage(ctx, r.ImageName(image), pool, w)
+}
+
+func (r *podmanRuntime) ImageName(image string) string {
+	return qualifiedImageName(image)
+}
+
+func (r *podmanRuntime) CreateContainer(opts docker.CreateContainerOptions) error {
+	if opts.Config != nil {
+		conf := *opts.Config
+		conf.Image = r.ImageName(conf.Image)
+		opts.Config = &conf
+	}
+	return r.dockerRuntime.CreateContainer(opts)
+}
+
+// qualifiedImageName returns the image name including the registry, using
+// the Docker Hub for images without one.
+func qualifiedImageName(image string) string {
+	if image == "" {
+		return image
+	}
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return image
+	}
+	if len(parts) == 1 {
+		return "docker.io/library/" + image
+	}
+	return "docker.io/" + image
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/fsouza/go-dockerclient/testing"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/provision/docker/dockertest"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestRuntimeForNode(c *check.C) {
+	rt, err := RuntimeForNode(&cluster.Node{Address: "http://n1:2375"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rt, check.FitsTypeOf, &dockerRuntime{})
+	rt, err = RuntimeForNode(&cluster.Node{Address: "http://n1:2375", Metadata: map[string]string{
+		RuntimeMetadataName:         RuntimePodman,
+		RuntimeEndpointMetadataName: "http://n1:8888",
+	}})
+	c.Assert(err, check.IsNil)
+	c.Assert(rt, check.FitsTypeOf, &podmanRuntime{})
+	c.Assert(rt.(*podmanRuntime).client.Endpoint(), check.Equals, "http://n1:8888")
+}
+
+func (s *S) TestDockerRuntimePullImageTimeout(c *check.C) {
+	config.Set("docker:operation-timeout:pull", 1)
+	config.Set("docker:nodecontainer:pull:max-tries", 1)
+	defer config.Unset("docker:operation-timeout:pull")
+	defer config.Unset("docker:nodecontainer:pull")
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"Pulling from tsuru/bs","id":"latest"}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+	client, err := docker.NewClient(server.URL)
+	c.Assert(err, check.IsNil)
+	rt := &dockerRuntime{client: client, address: server.URL}
+	var buf bytes.Buffer
+	_, err = rt.PullImage(context.Background(), "tsuru/bs", "", &buf)
+	c.Assert(errors.Cause(err), check.Equals, context.DeadlineExceeded)
+	c.Assert(buf.String(), check.Matches, `(?s).*Pulling from tsuru/bs.*`)
+}
+
+func (s *S) TestRuntimeForNodeUnknown(c *check.C) {
+	_, err := RuntimeForNode(&cluster.Node{Address: "http://n1:2375", Metadata: map[string]string{
+		RuntimeMetadataName: "other",
+	}})
+	c.Assert(err, check.ErrorMatches, `unknown container runtime "other" in node http://n1:2375`)
+}
+
+func (s *S) TestRuntimeForNodeContainerd(c *check.C) {
+	_, err := RuntimeForNode(&cluster.Node{Address: "http://n1:2375", Metadata: map[string]string{
+		RuntimeMetadataName: RuntimeContainerd,
+	}})
+	c.Assert(errors.Cause(err), check.Equals, ErrContainerdRuntimeUnavailable)
+}
+
+func (s *S) TestRegisterRuntime(c *check.C) {
+	var endpoint string
+	RegisterRuntime("fake-runtime", func(node *cluster.Node, e string) (Runtime, error) {
+		endpoint = e
+		return &dockerRuntime{}, nil
+	})
+	defer func() {
+		runtimesMu.Lock()
+		delete(runtimes, "fake-runtime")
+		runtimesMu.Unlock()
+	}()
+	_, err := RuntimeForNode(&cluster.Node{Address: "http://n1:2375", Metadata: map[string]string{
+		RuntimeMetadataName: "fake-runtime",
+	}})
+	c.Assert(err, check.IsNil)
+	c.Assert(endpoint, check.Equals, "http://n1:2375")
+}
+
+func (s *S) TestQualifiedImageName(c *check.C) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"bs", "docker.io/library/bs"},
+		{"tsuru/bs:v1", "docker.io/tsuru/bs:v1"},
+		{"tsuru/bs@" + digest, "docker.io/tsuru/bs@" + digest},
+		{"myregistry.com/tsuru/bs", "myregistry.com/tsuru/bs"},
+		{"myregistry:5000/tsuru/bs", "myregistry:5000/tsuru/bs"},
+		{"localhost/bs", "localhost/bs"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		c.Check(qualifiedImageName(tt.image), check.Equals, tt.expected)
+	}
+}
+
+func (s *S) TestEnsureContainersStartedPodmanRuntime(c *check.C) {
+	config.Set("docker:bs:image", "myregistry/tsuru/bs")
+	_, err := nodecontainer.InitializeBS(s.authScheme, "tsr")
+	c.Assert(err, check.IsNil)
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	podmanServer, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer podmanServer.Stop()
+	server := p.Servers()[0]
+	node, err := p.Cluster().GetNode(server.URL())
+	c.Assert(err, check.IsNil)
+	node.Metadata[RuntimeMetadataName] = RuntimePodman
+	node.Metadata[RuntimeEndpointMetadataName] = podmanServer.URL()
+	_, err = p.Cluster().UpdateNode(node)
+	c.Assert(err, check.IsNil)
+	err = ensureContainersStarted(context.Background(), p, ioutil.Discard, true, nil)
+	c.Assert(err, check.IsNil)
+	client, err := docker.NewClient(server.URL())
+	c.Assert(err, check.IsNil)
+	containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 0)
+	podmanClient, err := docker.NewClient(podmanServer.URL())
+	c.Assert(err, check.IsNil)
+	cont, err := podmanClient.InspectContainer(nodecontainer.BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(cont.Config.Image, check.Equals, "docker.io/myregistry/tsuru/bs")
+	c.Assert(cont.State.Running, check.Equals, true)
+	statuses, err := Status(p, nodecontainer.BsDefaultName, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 2)
+	for _, st := range statuses {
+		c.Assert(st.Exists, check.Equals, true)
+		c.Assert(st.ConfigMatches, check.Equals, true)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/iaas"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	internalNodeContainer "github.com/tsuru/tsuru/provision/docker/nodecontainer"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+)
+
+const (
+	orphanKindUnit          = "unit"
+	orphanKindNodeContainer = "nodecontainer"
+	orphanKindNodeImage     = "nodeimage"
+
+	nodeContainersGCJobName = "node-containers-gc"
+)
+
+// OrphanResources returns the containers and the node images of apps that
+// no longer exist and the node containers still running in IaaS machines
+// that are no longer registered as nodes or running in nodes without a
+// config for their pool. Nodes and machines that can't be reached are
+// reported in the returned error, along with the resources found in the
+// other ones.
+func (p *dockerProvisioner) OrphanResources() ([]provision.OrphanResource, error) {
+	units, err := p.orphanUnits()
+	if err != nil {
+		return nil, err
+	}
+	multi := tsuruErrors.NewMultiError()
+	nodeContainers, err := p.orphanNodeContainers()
+	if err != nil {
+		multi.Add(err)
+	}
+	nodeImages, err := p.orphanNodeImages()
+	if err != nil {
+		multi.Add(err)
+	}
+	result := append(units, nodeContainers...)
+	return append(result, nodeImages...), multi.ToError()
+}
+
+func (p *dockerProvisioner) RemoveOrphanResource(r provision.OrphanResource) error {
+	switch r.Kind {
+	case orphanKindUnit:
+		cont, err := p.GetContainer(r.Name)
+		if err != nil {
+			return err
+		}
+		return cont.Remove(p.ClusterClient(), p.ActionLimiter())
+	case orphanKindNodeContainer:
+		if _, err := p.Cluster().GetNode(r.Node); err == nil {
+			return internalNodeContainer.RemoveOrphanContainer(p, r.Node, r.Name)
+		}
+		client, err := machineClient(r.Node)
+		if err != nil {
+			return err
+		}
+		return client.RemoveContainer(docker.RemoveContainerOptions{ID: r.Name, Force: true})
+	case orphanKindNodeImage:
+		node, err := p.Cluster().GetNode(r.Node)
+		if err != nil {
+			return err
+		}
+		client, err := node.Client()
+		if err != nil {
+			return err
+		}
+		return client.RemoveImage(r.Name)
+	}
+	return errors.Errorf("unknown orphan resource kind %q", r.Kind)
+}
+
+func (p *dockerProvisioner) orphanUnits() ([]provision.OrphanResource, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	appNames := []string{}
+	err = conn.Apps().Find(nil).Distinct("name", &appNames)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := p.ListContainers(bson.M{"appname": bson.M{"$nin": appNames}})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]provision.OrphanResource, len(containers))
+	for i, c := range containers {
+		result[i] = provision.OrphanResource{
+			Kind: orphanKindUnit,
+			Name: c.ID,
+			App:  c.AppName,
+			Node: c.HostAddr,
+		}
+	}
+	return result, nil
+}
+
+// orphanNodeImages returns the app images in the nodes whose app no longer
+// exists.
+func (p *dockerProvisioner) orphanNodeImages() ([]provision.OrphanResource, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	appNames := []string{}
+	err = conn.Apps().Find(nil).Distinct("name", &appNames)
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+	apps := make(map[string]struct{}, len(appNames))
+	for _, name := range appNames {
+		apps[name] = struct{}{}
+	}
+	nodes, err := p.Cluster().UnfilteredNodes()
+	if err != nil {
+		return nil, err
+	}
+	multi := tsuruErrors.NewMultiError()
+	var result []provision.OrphanResource
+	for _, n := range nodes {
+		client, err := n.Client()
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		images, err := client.ListImages(docker.ListImagesOptions{})
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to list images in node %s", n.Address))
+			continue
+		}
+		for _, img := range images {
+			for _, tag := range img.RepoTags {
+				appName, ok := image.AppNameFromImage(tag)
+				if !ok {
+					continue
+				}
+				if _, ok := apps[appName]; ok {
+					continue
+				}
+				result = append(result, provision.OrphanResource{
+					Kind: orphanKindNodeImage,
+					Name: tag,
+					App:  appName,
+					Node: n.Address,
+				})
+			}
+		}
+	}
+	return result, multi.ToError()
+}
+
+func (p *dockerProvisioner) orphanNodeContainers() ([]provision.OrphanResource, error) {
+	multi := tsuruErrors.NewMultiError()
+	orphans, err := internalNodeContainer.FindOrphanContainers(p)
+	if err != nil {
+		multi.Add(err)
+	}
+	result := make([]provision.OrphanResource, len(orphans))
+	for i, o := range orphans {
+		result[i] = provision.OrphanResource{
+			Kind: orphanKindNodeContainer,
+			Name: o.ID,
+			Node: o.Node,
+		}
+	}
+	machineResult, err := p.orphanMachineNodeContainers()
+	if err != nil {
+		multi.Add(err)
+	}
+	return append(result, machineResult...), multi.ToError()
+}
+
+// orphanMachineNodeContainers returns the node containers running in IaaS
+// machines that are no longer registered as nodes.
+func (p *dockerProvisioner) orphanMachineNodeContainers() ([]provision.OrphanResource, error) {
+	machines, err := iaas.ListMachines()
+	if err != nil {
+		return nil, err
+	}
+	if len(machines) == 0 {
+		return nil, nil
+	}
+	names, err := nodecontainer.AllNodeContainersNames()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	nodes, err := p.Cluster().UnfilteredNodes()
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]struct{}, len(nodes))
+	for _, n := range nodes {
+		registered[net.URLToHost(n.Address)] = struct{}{}
+	}
+	multi := tsuruErrors.NewMultiError()
+	var result []provision.OrphanResource
+	for _, m := range machines {
+		if _, ok := registered[m.Address]; ok {
+			continue
+		}
+		client, err := machineClient(m.Address)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to list containers in machine %s", m.Address))
+			continue
+		}
+		for _, c := range containers {
+			for _, contName := range c.Names {
+				contName = strings.TrimPrefix(contName, "/")
+				if !containsString(names, contName) {
+					continue
+				}
+				result = append(result, provision.OrphanResource{
+					Kind: orphanKindNodeContainer,
+					Name: c.ID,
+					Node: m.Address,
+				})
+			}
+		}
+	}
+	return result, multi.ToError()
+}
+
+// registerNodeContainersGCJob registers the job that removes the orphan node
+// containers, keeping the nodes and removed machines clean after node
+// containers are removed or nodes are moved between pools. It's disabled by
+// default.
+func registerNodeContainersGCJob(p *dockerProvisioner) error {
+	return jobs.Register(jobs.Job{
+		Name:        nodeContainersGCJobName,
+		Description: "removes node containers without config for the pool of their nodes or left in removed machines",
+		Schedule:    "@hourly",
+		Disabled:    true,
+		Run: func(ctx context.Context, evt *event.Event) error {
+			return p.removeOrphanNodeContainers(evt)
+		},
+	})
+}
+
+func (p *dockerProvisioner) removeOrphanNodeContainers(w io.Writer) error {
+	multi := tsuruErrors.NewMultiError()
+	orphans, err := p.orphanNodeContainers()
+	if err != nil {
+		multi.Add(err)
+	}
+	for _, o := range orphans {
+		fmt.Fprintf(w, "removing orphan node container %s in %s\n", o.Name, o.Node)
+		err = p.RemoveOrphanResource(o)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to remove node container %s in %s", o.Name, o.Node))
+		}
+	}
+	return multi.ToError()
+}
+
+func machineClient(address string) (*docker.Client, error) {
+	m, err := iaas.FindMachineByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	node := cluster.Node{
+		Address:    m.FormatNodeAddress(),
+		CaCert:     m.CaCert,
+		ClientCert: m.ClientCert,
+		ClientKey:  m.ClientKey,
+	}
+	return node.Client()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/fsouza/go-dockerclient"
+	dtesting "github.com/fsouza/go-dockerclient/testing"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/iaas"
+	"github.com/tsuru/tsuru/jobs"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestOrphanResourcesUnits(c *check.C) {
+	err := s.conn.Apps().Insert(app.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	cont1, err := s.newContainer(&newContainerOpts{AppName: "myapp"}, nil)
+	c.Assert(err, check.IsNil)
+	defer s.removeTestContainer(cont1)
+	cont2, err := s.newContainer(&newContainerOpts{AppName: "removedapp"}, nil)
+	c.Assert(err, check.IsNil)
+	resources, err := s.p.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.DeepEquals, []provision.OrphanResource{
+		{Kind: "unit", Name: cont2.ID, App: "removedapp", Node: cont2.HostAddr},
+	})
+	err = s.p.RemoveOrphanResource(resources[0])
+	c.Assert(err, check.IsNil)
+	_, err = s.p.GetContainer(cont2.ID)
+	c.Assert(err, check.NotNil)
+	_, err = s.p.GetContainer(cont1.ID)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestOrphanResourcesNodeImages(c *check.C) {
+	err := s.conn.Apps().Insert(app.App{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	err = newFakeImage(s.p, s.repoNamespace+"/app-myapp:v1", nil)
+	c.Assert(err, check.IsNil)
+	err = newFakeImage(s.p, s.repoNamespace+"/app-removedapp:v1", nil)
+	c.Assert(err, check.IsNil)
+	resources, err := s.p.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.DeepEquals, []provision.OrphanResource{
+		{Kind: "nodeimage", Name: s.repoNamespace + "/app-removedapp:v1", App: "removedapp", Node: s.server.URL()},
+	})
+	err = s.p.RemoveOrphanResource(resources[0])
+	c.Assert(err, check.IsNil)
+	resources, err = s.p.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.HasLen, 0)
+}
+
+func (s *S) TestOrphanResourcesNodeContainers(c *check.C) {
+	otherServer, err := dtesting.NewServer("localhost:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer otherServer.Stop()
+	u, err := url.Parse(otherServer.URL())
+	c.Assert(err, check.IsNil)
+	port, err := strconv.Atoi(u.Port())
+	c.Assert(err, check.IsNil)
+	err = s.conn.Collection("iaas_machines").Insert(iaas.Machine{
+		Id:       "m1",
+		Address:  "localhost",
+		Port:     port,
+		Protocol: "http",
+	})
+	c.Assert(err, check.IsNil)
+	err = nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "bsimg"},
+	})
+	c.Assert(err, check.IsNil)
+	client, err := docker.NewClient(otherServer.URL())
+	c.Assert(err, check.IsNil)
+	err = client.PullImage(docker.PullImageOptions{Repository: "bsimg"}, docker.AuthConfiguration{})
+	c.Assert(err, check.IsNil)
+	bs, err := client.CreateContainer(docker.CreateContainerOptions{Name: "bs", Config: &docker.Config{Image: "bsimg"}})
+	c.Assert(err, check.IsNil)
+	_, err = client.CreateContainer(docker.CreateContainerOptions{Name: "other", Config: &docker.Config{Image: "bsimg"}})
+	c.Assert(err, check.IsNil)
+	resources, err := s.p.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.DeepEquals, []provision.OrphanResource{
+		{Kind: "nodecontainer", Name: bs.ID, Node: "localhost"},
+	})
+	err = s.p.RemoveOrphanResource(resources[0])
+	c.Assert(err, check.IsNil)
+	containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 1)
+	c.Assert(containers[0].Names, check.DeepEquals, []string{"/other"})
+}
+
+func (s *S) TestOrphanResourcesNodeContainersWithoutPoolConfig(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "bsimg"},
+	})
+	c.Assert(err, check.IsNil)
+	disabled := true
+	err = nodecontainer.AddNewContainer("test-default", &nodecontainer.NodeContainerConfig{
+		Name:     "bs",
+		Disabled: &disabled,
+	})
+	c.Assert(err, check.IsNil)
+	client, err := docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	err = client.PullImage(docker.PullImageOptions{Repository: "bsimg"}, docker.AuthConfiguration{})
+	c.Assert(err, check.IsNil)
+	labels := provision.NodeContainerLabels(provision.NodeContainerLabelsOpts{
+		Name:        "bs",
+		Pool:        "test-default",
+		Provisioner: s.p.GetName(),
+	}).ToLabels()
+	bs, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name:   "bs",
+		Config: &docker.Config{Image: "bsimg", Labels: labels},
+	})
+	c.Assert(err, check.IsNil)
+	resources, err := s.p.OrphanResources()
+	c.Assert(err, check.IsNil)
+	c.Assert(resources, check.DeepEquals, []provision.OrphanResource{
+		{Kind: "nodecontainer", Name: bs.ID, Node: s.server.URL()},
+	})
+	err = s.p.RemoveOrphanResource(resources[0])
+	c.Assert(err, check.IsNil)
+	_, err = client.InspectContainer(bs.ID)
+	c.Assert(err, check.FitsTypeOf, &docker.NoSuchContainer{})
+}
+
+func (s *S) TestNodeContainersGCJob(c *check.C) {
+	err := nodecontainer.AddNewContainer("", &nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "bsimg"},
+	})
+	c.Assert(err, check.IsNil)
+	client, err := docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	err = client.PullImage(docker.PullImageOptions{Repository: "bsimg"}, docker.AuthConfiguration{})
+	c.Assert(err, check.IsNil)
+	labels := provision.NodeContainerLabels(provision.NodeContainerLabelsOpts{
+		Name:        "removed",
+		Pool:        "test-default",
+		Provisioner: s.p.GetName(),
+	}).ToLabels()
+	removed, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name:   "removed",
+		Config: &docker.Config{Image: "bsimg", Labels: labels},
+	})
+	c.Assert(err, check.IsNil)
+	err = registerNodeContainersGCJob(s.p)
+	c.Assert(err, check.IsNil)
+	info, err := jobs.Get(nodeContainersGCJobName)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Enabled, check.Equals, false)
+	c.Assert(info.Schedule, check.Equals, "@hourly")
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       jobs.Target(nodeContainersGCJobName),
+		InternalKind: jobs.EventKindRun,
+		Allowed:      event.Allowed(permission.PermJobReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	evt.SetLogWriter(&buf)
+	err = jobs.Run(context.Background(), nodeContainersGCJobName, evt)
+	c.Assert(err, check.IsNil)
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, "(?s).*removing orphan node container "+removed.ID+" in "+s.server.URL()+".*")
+	_, err = client.InspectContainer(removed.ID)
+	c.Assert(err, check.FitsTypeOf, &docker.NoSuchContainer{})
+}
@@ -0,0 +1,308 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const (
+	nodeUsageCollection       = "docker_node_usage"
+	defaultOvercommitMaxUsage = 0.9
+	overcommitEventKind       = "overcommit-rebalance"
+	containerStatsTimeout     = 10 * time.Second
+)
+
+// nodeUsage is the real memory usage of a node in a pool with memory
+// overcommit, as last measured by the overcommit guard.
+type nodeUsage struct {
+	Host      string `bson:"_id"`
+	Pool      string
+	Memory    int64
+	Ratio     float64
+	Saturated bool
+	UpdatedAt time.Time
+}
+
+func nodeUsageColl() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection(nodeUsageCollection), nil
+}
+
+func overcommitCheckInterval() time.Duration {
+	seconds, _ := config.GetInt("docker:scheduler:overcommit:check-interval")
+	return time.Duration(seconds) * time.Second
+}
+
+// filterSaturatedNodes removes the nodes whose real memory usage, as last
+// measured by the overcommit guard, is close to their physical limit. An
+// error is returned when every node is saturated, refusing the scheduling.
+func filterSaturatedNodes(nodes []cluster.Node) ([]cluster.Node, error) {
+	interval := overcommitCheckInterval()
+	if interval <= 0 || len(nodes) == 0 {
+		return nodes, nil
+	}
+	hosts := make([]string, len(nodes))
+	for i := range nodes {
+		hosts[i] = net.URLToHost(nodes[i].Address)
+	}
+	coll, err := nodeUsageColl()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var saturated []nodeUsage
+	err = coll.Find(bson.M{
+		"_id":       bson.M{"$in": hosts},
+		"saturated": true,
+		"updatedat": bson.M{"$gt": time.Now().UTC().Add(-3 * interval)},
+	}).All(&saturated)
+	if err != nil {
+		return nil, err
+	}
+	if len(saturated) == 0 {
+		return nodes, nil
+	}
+	saturatedMap := make(map[string]struct{}, len(saturated))
+	for _, u := range saturated {
+		saturatedMap[u.Host] = struct{}{}
+	}
+	nodeList := make([]cluster.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if _, ok := saturatedMap[net.URLToHost(node.Address)]; ok {
+			log.Errorf("Node %q is close to its physical memory limit, ignoring it.", node.Address)
+			continue
+		}
+		nodeList = append(nodeList, node)
+	}
+	if len(nodeList) == 0 {
+		return nil, errors.New("all nodes are close to their physical memory limit")
+	}
+	return nodeList, nil
+}
+
+// NodesMemoryUsage returns the real memory usage of nodes as last measured by
+// the overcommit guard. Only nodes in pools with memory overcommit are
+// measured.
+func (p *dockerProvisioner) NodesMemoryUsage(addresses []string) (map[string]int64, error) {
+	interval := overcommitCheckInterval()
+	if interval <= 0 || len(addresses) == 0 {
+		return nil, nil
+	}
+	hostToAddr := make(map[string]string, len(addresses))
+	hosts := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		host := net.URLToHost(addr)
+		hostToAddr[host] = addr
+		hosts = append(hosts, host)
+	}
+	coll, err := nodeUsageColl()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var usages []nodeUsage
+	err = coll.Find(bson.M{
+		"_id":       bson.M{"$in": hosts},
+		"updatedat": bson.M{"$gt": time.Now().UTC().Add(-3 * interval)},
+	}).All(&usages)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int64, len(usages))
+	for _, u := range usages {
+		result[hostToAddr[u.Host]] = u.Memory
+	}
+	return result, nil
+}
+
+// overcommitGuard periodically measures the real memory usage of nodes in
+// pools with memory overcommit, marking the nodes close to their physical
+// limit as saturated and optionally rebalancing their pools.
+type overcommitGuard struct {
+	p         *dockerProvisioner
+	interval  time.Duration
+	maxUsage  float64
+	rebalance bool
+	done      chan bool
+}
+
+func newOvercommitGuard(p *dockerProvisioner) *overcommitGuard {
+	maxUsage, err := config.GetFloat("docker:scheduler:overcommit:max-usage")
+	if err != nil || maxUsage <= 0 {
+		maxUsage = defaultOvercommitMaxUsage
+	}
+	rebalance, _ := config.GetBool("docker:scheduler:overcommit:rebalance")
+	return &overcommitGuard{
+		p:         p,
+		interval:  overcommitCheckInterval(),
+		maxUsage:  maxUsage,
+		rebalance: rebalance,
+		done:      make(chan bool),
+	}
+}
+
+func (g *overcommitGuard) run() {
+	for {
+		err := g.runOnce()
+		if err != nil {
+			log.Errorf("[overcommit guard] %s", err)
+		}
+		select {
+		case <-g.done:
+			return
+		case <-time.After(g.interval):
+		}
+	}
+}
+
+func (g *overcommitGuard) Shutdown(ctx context.Context) error {
+	g.done <- true
+	return nil
+}
+
+func (g *overcommitGuard) String() string {
+	return "overcommit guard"
+}
+
+func (g *overcommitGuard) runOnce() error {
+	nodes, err := g.p.Cluster().UnfilteredNodes()
+	if err != nil {
+		return err
+	}
+	coll, err := nodeUsageColl()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	poolOvercommit := map[string]float64{}
+	saturatedPools := map[string]struct{}{}
+	for _, node := range nodes {
+		pool := node.Metadata[provision.PoolMetadataName]
+		overcommit, ok := poolOvercommit[pool]
+		if !ok {
+			conf, err := LoadSchedulerConfig(pool)
+			if err != nil {
+				return err
+			}
+			overcommit = conf.MemoryOvercommit
+			poolOvercommit[pool] = overcommit
+		}
+		if overcommit <= 1 {
+			continue
+		}
+		totalMemory, _ := strconv.ParseFloat(node.Metadata[g.p.scheduler.TotalMemoryMetadata], 64)
+		if totalMemory == 0 {
+			continue
+		}
+		used, err := nodeMemoryUsage(node)
+		if err != nil {
+			log.Errorf("[overcommit guard] unable to get memory usage of node %q: %s", node.Address, err)
+			continue
+		}
+		usage := nodeUsage{
+			Host:      net.URLToHost(node.Address),
+			Pool:      pool,
+			Memory:    used,
+			Ratio:     float64(used) / totalMemory,
+			UpdatedAt: time.Now().UTC(),
+		}
+		usage.Saturated = usage.Ratio >= g.maxUsage
+		if usage.Saturated {
+			log.Errorf("[overcommit guard] node %q is using %0.2f%% of its memory", node.Address, usage.Ratio*100)
+			saturatedPools[pool] = struct{}{}
+		}
+		_, err = coll.UpsertId(usage.Host, usage)
+		if err != nil {
+			return err
+		}
+	}
+	if !g.rebalance {
+		return nil
+	}
+	for pool := range saturatedPools {
+		err = g.rebalancePool(pool)
+		if err != nil {
+			log.Errorf("[overcommit guard] unable to rebalance pool %q: %s", pool, err)
+		}
+	}
+	return nil
+}
+
+func (g *overcommitGuard) rebalancePool(pool string) (err error) {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypePool, Value: pool},
+		InternalKind: overcommitEventKind,
+		Allowed:      event.Allowed(permission.PermPoolReadEvents, permission.Context(permission.CtxPool, pool)),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.Logf("rebalancing pool %q, some of its nodes are close to their physical memory limit", pool)
+	_, err = g.p.RebalanceNodes(provision.RebalanceNodesOptions{
+		Event: evt,
+		Pool:  pool,
+		Force: true,
+	})
+	return err
+}
+
+// nodeMemoryUsage returns the memory used by the running containers in the
+// node, not counting the page cache.
+func nodeMemoryUsage(node cluster.Node) (int64, error) {
+	client, err := node.Client()
+	if err != nil {
+		return 0, err
+	}
+	containers, err := client.ListContainers(docker.ListContainersOptions{})
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, c := range containers {
+		statsCh := make(chan *docker.Stats, 1)
+		errCh := make(chan error, 1)
+		go func(id string) {
+			errCh <- client.Stats(docker.StatsOptions{
+				ID:      id,
+				Stats:   statsCh,
+				Timeout: containerStatsTimeout,
+			})
+		}(c.ID)
+		for stats := range statsCh {
+			total += int64(stats.MemoryStats.Usage) - int64(stats.MemoryStats.Stats.Cache)
+		}
+		err = <-errCh
+		if _, ok := err.(*docker.NoSuchContainer); ok {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/fsouza/go-dockerclient/testing"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestOvercommitGuardRunOnce(c *check.C) {
+	server1, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server1.Stop()
+	server2, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server2.Stop()
+	scheduler := segregatedScheduler{TotalMemoryMetadata: "totalMemory", provisioner: s.p}
+	clusterInstance, err := cluster.New(&scheduler, &cluster.MapStorage{}, "",
+		cluster.Node{Address: server1.URL(), Metadata: map[string]string{"pool": "pool1", "totalMemory": "1000"}},
+		cluster.Node{Address: server2.URL(), Metadata: map[string]string{"pool": "pool2", "totalMemory": "1000"}},
+	)
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	s.p.scheduler = &scheduler
+	client, err := docker.NewClient(server1.URL())
+	c.Assert(err, check.IsNil)
+	err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{})
+	c.Assert(err, check.IsNil)
+	cont, err := client.CreateContainer(docker.CreateContainerOptions{Config: &docker.Config{Image: "tsuru/python"}})
+	c.Assert(err, check.IsNil)
+	err = client.StartContainer(cont.ID, nil)
+	c.Assert(err, check.IsNil)
+	server1.PrepareStats(cont.ID, func(string) docker.Stats {
+		var stats docker.Stats
+		stats.MemoryStats.Usage = 1000
+		stats.MemoryStats.Stats.Cache = 50
+		return stats
+	})
+	conf := SchedulerConfig{MemoryOvercommit: 2}
+	err = conf.Save("pool1")
+	c.Assert(err, check.IsNil)
+	guard := &overcommitGuard{p: s.p, maxUsage: 0.9}
+	err = guard.runOnce()
+	c.Assert(err, check.IsNil)
+	coll, err := nodeUsageColl()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	var usages []nodeUsage
+	err = coll.Find(nil).All(&usages)
+	c.Assert(err, check.IsNil)
+	c.Assert(usages, check.HasLen, 1)
+	c.Assert(usages[0].Host, check.Equals, "127.0.0.1")
+	c.Assert(usages[0].Pool, check.Equals, "pool1")
+	c.Assert(usages[0].Memory, check.Equals, int64(950))
+	c.Assert(usages[0].Saturated, check.Equals, true)
+}
+
+func (s *S) TestNodesMemoryUsage(c *check.C) {
+	config.Set("docker:scheduler:overcommit:check-interval", 60)
+	defer config.Unset("docker:scheduler:overcommit:check-interval")
+	coll, err := nodeUsageColl()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	now := time.Now().UTC()
+	err = coll.Insert(
+		nodeUsage{Host: "10.0.0.1", Pool: "pool1", Memory: 500, UpdatedAt: now},
+		nodeUsage{Host: "10.0.0.2", Pool: "pool1", Memory: 700, UpdatedAt: now.Add(-time.Hour)},
+		nodeUsage{Host: "10.0.0.3", Pool: "pool2", Memory: 900, UpdatedAt: now},
+	)
+	c.Assert(err, check.IsNil)
+	usage, err := s.p.NodesMemoryUsage([]string{"http://10.0.0.1:2375", "http://10.0.0.2:2375"})
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, map[string]int64{"http://10.0.0.1:2375": 500})
+	config.Unset("docker:scheduler:overcommit:check-interval")
+	usage, err = s.p.NodesMemoryUsage([]string{"http://10.0.0.1:2375"})
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.IsNil)
+}
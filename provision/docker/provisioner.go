@@ -39,7 +39,9 @@ import (
 	"github.com/tsuru/tsuru/provision/docker/types"
 	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"github.com/tsuru/tsuru/provision/nodehook"
 	"github.com/tsuru/tsuru/queue"
+	"github.com/tsuru/tsuru/retry"
 	_ "github.com/tsuru/tsuru/router/api"
 	_ "github.com/tsuru/tsuru/router/galeb"
 	_ "github.com/tsuru/tsuru/router/hipache"
@@ -73,25 +75,30 @@ type dockerProvisioner struct {
 	scheduler      *segregatedScheduler
 	isDryMode      bool
 	actionLimiter  provision.ActionLimiter
+	buildLimiter   provision.ActionLimiter
 }
 
 var (
-	_ provision.Provisioner               = &dockerProvisioner{}
-	_ provision.RollbackableDeployer      = &dockerProvisioner{}
-	_ provision.ShellProvisioner          = &dockerProvisioner{}
-	_ provision.ExecutableProvisioner     = &dockerProvisioner{}
-	_ provision.SleepableProvisioner      = &dockerProvisioner{}
-	_ provision.MessageProvisioner        = &dockerProvisioner{}
-	_ provision.InitializableProvisioner  = &dockerProvisioner{}
-	_ provision.OptionalLogsProvisioner   = &dockerProvisioner{}
-	_ provision.UnitStatusProvisioner     = &dockerProvisioner{}
-	_ provision.NodeProvisioner           = &dockerProvisioner{}
-	_ provision.NodeRebalanceProvisioner  = &dockerProvisioner{}
-	_ provision.NodeContainerProvisioner  = &dockerProvisioner{}
-	_ provision.UnitFinderProvisioner     = &dockerProvisioner{}
-	_ provision.AppFilterProvisioner      = &dockerProvisioner{}
-	_ provision.BuilderDeploy             = &dockerProvisioner{}
-	_ provision.BuilderDeployDockerClient = &dockerProvisioner{}
+	_ provision.Provisioner                  = &dockerProvisioner{}
+	_ provision.RollbackableDeployer         = &dockerProvisioner{}
+	_ provision.ShellProvisioner             = &dockerProvisioner{}
+	_ provision.ExecutableProvisioner        = &dockerProvisioner{}
+	_ provision.SleepableProvisioner         = &dockerProvisioner{}
+	_ provision.MessageProvisioner           = &dockerProvisioner{}
+	_ provision.InitializableProvisioner     = &dockerProvisioner{}
+	_ provision.OptionalLogsProvisioner      = &dockerProvisioner{}
+	_ provision.UnitStatusProvisioner        = &dockerProvisioner{}
+	_ provision.NodeProvisioner              = &dockerProvisioner{}
+	_ provision.NodeRebalanceProvisioner     = &dockerProvisioner{}
+	_ provision.NodeContainerProvisioner     = &dockerProvisioner{}
+	_ provision.NodeContainerLogsProvisioner = &dockerProvisioner{}
+	_ provision.NodeUsageProvisioner         = &dockerProvisioner{}
+	_ provision.UnitFinderProvisioner        = &dockerProvisioner{}
+	_ provision.AppFilterProvisioner         = &dockerProvisioner{}
+	_ provision.BuilderDeploy                = &dockerProvisioner{}
+	_ provision.BuilderDeployDockerClient    = &dockerProvisioner{}
+	_ provision.PlatformBuilderDockerClient  = &dockerProvisioner{}
+	_ provision.ImagePrepullProvisioner      = &dockerProvisioner{}
 )
 
 type hookHealer struct {
@@ -130,10 +137,12 @@ func (p *dockerProvisioner) initDockerCluster() error {
 	}
 	var nodes []cluster.Node
 	TotalMemoryMetadata, _ := config.GetString("docker:scheduler:total-memory-metadata")
+	TotalCPUMetadata, _ := config.GetString("docker:scheduler:total-cpu-metadata")
 	maxUsedMemory, _ := config.GetFloat("docker:scheduler:max-used-memory")
 	p.scheduler = &segregatedScheduler{
 		maxMemoryRatio:      float32(maxUsedMemory),
 		TotalMemoryMetadata: TotalMemoryMetadata,
+		TotalCPUMetadata:    TotalCPUMetadata,
 		provisioner:         p,
 	}
 	caPath, _ := config.GetString("docker:tls:root-path")
@@ -148,16 +157,23 @@ func (p *dockerProvisioner) initDockerCluster() error {
 		p.cluster.AddHook(cluster.HookEventBeforeNodeUnregister, healer)
 	}
 	healContainersSeconds, _ := config.GetInt("docker:healing:heal-containers-timeout")
-	if healContainersSeconds > 0 {
+	healStuckSeconds, _ := config.GetInt("docker:healing:heal-stuck-units-timeout")
+	if healContainersSeconds > 0 || healStuckSeconds > 0 {
 		contHealerInst := healer.NewContainerHealer(healer.ContainerHealerArgs{
 			Provisioner:         p,
 			MaxUnresponsiveTime: time.Duration(healContainersSeconds) * time.Second,
+			MaxStartingTime:     time.Duration(healStuckSeconds) * time.Second,
 			Done:                make(chan bool),
 			Locker:              &appLocker{},
 		})
 		shutdown.Register(contHealerInst)
 		go contHealerInst.RunContainerHealer()
 	}
+	if TotalMemoryMetadata != "" && overcommitCheckInterval() > 0 {
+		guard := newOvercommitGuard(p)
+		shutdown.Register(guard)
+		go guard.run()
+	}
 	activeMonitoring, _ := config.GetInt("docker:healing:active-monitoring-interval")
 	if activeMonitoring > 0 {
 		p.cluster.StartActiveMonitoring(time.Duration(activeMonitoring) * time.Second)
@@ -165,13 +181,19 @@ func (p *dockerProvisioner) initDockerCluster() error {
 	limitMode, _ := config.GetString("docker:limit:mode")
 	if limitMode == "global" {
 		p.actionLimiter = &provision.MongodbLimiter{}
+		p.buildLimiter = &provision.MongodbLimiter{}
 	} else {
 		p.actionLimiter = &provision.LocalLimiter{}
+		p.buildLimiter = &provision.LocalLimiter{}
 	}
 	actionLimit, _ := config.GetUint("docker:limit:actions-per-host")
 	if actionLimit > 0 {
 		p.actionLimiter.Initialize(actionLimit)
 	}
+	buildLimit, _ := config.GetUint("docker:builder:max-builds-per-node")
+	if buildLimit > 0 {
+		p.buildLimiter.Initialize(buildLimit)
+	}
 	return nil
 }
 
@@ -189,6 +211,7 @@ func (p *dockerProvisioner) cloneProvisioner(ignoredContainers []container.Conta
 	overridenProvisioner.scheduler = &segregatedScheduler{
 		maxMemoryRatio:      p.scheduler.maxMemoryRatio,
 		TotalMemoryMetadata: p.scheduler.TotalMemoryMetadata,
+		TotalCPUMetadata:    p.scheduler.TotalCPUMetadata,
 		provisioner:         &overridenProvisioner,
 		ignoredContainers:   containerIds,
 	}
@@ -224,6 +247,7 @@ func (p *dockerProvisioner) dryMode(ignoredContainers []container.Container) (*d
 	overridenProvisioner.scheduler = &segregatedScheduler{
 		maxMemoryRatio:      p.scheduler.maxMemoryRatio,
 		TotalMemoryMetadata: p.scheduler.TotalMemoryMetadata,
+		TotalCPUMetadata:    p.scheduler.TotalCPUMetadata,
 		provisioner:         overridenProvisioner,
 		ignoredContainers:   containerIds,
 	}
@@ -276,6 +300,19 @@ func (p *dockerProvisioner) Initialize() error {
 	if err != nil {
 		return err
 	}
+	err = internalNodeContainer.RegisterReconcileJob(p)
+	if err != nil {
+		return err
+	}
+	err = internalNodeContainer.RegisterRestartJob(p)
+	if err != nil {
+		return err
+	}
+	err = registerNodeContainersGCJob(p)
+	if err != nil {
+		return err
+	}
+	retry.RegisterHandler(moveUnitRetryKind, p.retryMoveUnit)
 	return p.initDockerCluster()
 }
 
@@ -407,6 +444,7 @@ func (p *dockerProvisioner) deploy(a provision.App, imageID string, evt *event.E
 	if err := checkCanceled(evt); err != nil {
 		return err
 	}
+	provision.StartDeployPhase(evt, provision.DeployPhaseUnitStart)
 	containers, err := p.listContainersByApp(a.GetName())
 	if err != nil {
 		return err
@@ -536,16 +574,21 @@ func addContainersWithHost(args *changeUnitsPipelineArgs) ([]container.Container
 		w = ioutil.Discard
 	}
 	fmt.Fprintf(w, "\n---- Starting %d new %s %s ----\n", units, pluralize("unit", units), strings.Join(processMsg, " "))
-	oldContainers := make([]container.Container, 0, units)
-	for processName, cont := range args.toAdd {
-		for i := 0; i < cont.Quantity; i++ {
-			oldContainers = append(oldContainers, container.Container{
-				Container: types.Container{
-					ProcessName: processName,
-					Status:      cont.Status.String(),
-				},
-			})
-		}
+	yamlData, err := image.GetImageTsuruYamlData(imageID)
+	if err != nil {
+		return nil, err
+	}
+	processNames := make([]string, 0, len(args.toAdd))
+	for processName := range args.toAdd {
+		processNames = append(processNames, processName)
+	}
+	groups, err := yamlData.ProcessStartOrder(processNames)
+	if err != nil {
+		return nil, err
+	}
+	webProcessName, err := image.GetImageWebProcessName(imageID)
+	if err != nil {
+		log.Errorf("[WARNING] cannot get the name of the web process: %s", err)
 	}
 	rollbackCallback := func(c *container.Container) {
 		log.Errorf("Removing container %q due failed add units.", c.ID)
@@ -558,30 +601,73 @@ func addContainersWithHost(args *changeUnitsPipelineArgs) ([]container.Container
 		createdContainers []*container.Container
 		m                 sync.Mutex
 	)
-	err := runInContainers(oldContainers, func(c *container.Container, toRollback chan *container.Container) error {
-		c, startErr := args.provisioner.start(c, a, imageID, w, args.exposedPort, destinationHost...)
-		if startErr != nil {
-			return startErr
+	// Processes are started in groups, following the dependencies declared
+	// in tsuru.yaml. Each group waits for the web process of the previous
+	// ones to pass the healthcheck, other processes are considered healthy
+	// once started.
+	for i, group := range groups {
+		if len(groups) > 1 {
+			fmt.Fprintf(w, " ---> Starting processes: %s\n", strings.Join(group, ", "))
+		}
+		var groupContainers []container.Container
+		for _, processName := range group {
+			cont := args.toAdd[processName]
+			for j := 0; j < cont.Quantity; j++ {
+				groupContainers = append(groupContainers, container.Container{
+					Container: types.Container{
+						ProcessName: processName,
+						Status:      cont.Status.String(),
+					},
+				})
+			}
+		}
+		previousContainers := createdContainers
+		err = runInContainers(groupContainers, func(c *container.Container, toRollback chan *container.Container) error {
+			c, startErr := args.provisioner.start(c, a, imageID, w, args.exposedPort, args.toAdd[c.ProcessName].Checkpoint, destinationHost...)
+			if startErr != nil {
+				return startErr
+			}
+			toRollback <- c
+			m.Lock()
+			createdContainers = append(createdContainers, c)
+			m.Unlock()
+			fmt.Fprintf(w, " ---> Started unit %s [%s]\n", c.ShortID(), c.ProcessName)
+			return nil
+		}, rollbackCallback, true)
+		if err == nil && i < len(groups)-1 {
+			err = waitProcessesHealthy(createdContainers[len(previousContainers):], webProcessName, w)
+			if err != nil {
+				previousContainers = createdContainers
+			}
+		}
+		if err != nil {
+			for _, c := range previousContainers {
+				rollbackCallback(c)
+			}
+			return nil, err
 		}
-		toRollback <- c
-		m.Lock()
-		createdContainers = append(createdContainers, c)
-		m.Unlock()
-		fmt.Fprintf(w, " ---> Started unit %s [%s]\n", c.ShortID(), c.ProcessName)
-		return nil
-	}, rollbackCallback, true)
-	if err != nil {
-		return nil, err
 	}
 	result := make([]container.Container, len(createdContainers))
-	i := 0
-	for _, c := range createdContainers {
+	for i, c := range createdContainers {
 		result[i] = *c
-		i++
 	}
 	return result, nil
 }
 
+// waitProcessesHealthy runs the healthcheck in the containers of the web
+// process, so processes depending on it are only started once it's healthy.
+func waitProcessesHealthy(containers []*container.Container, webProcessName string, w io.Writer) error {
+	var toCheck []container.Container
+	for _, c := range containers {
+		if c.ProcessName == webProcessName {
+			toCheck = append(toCheck, *c)
+		}
+	}
+	return runInContainers(toCheck, func(c *container.Container, _ chan *container.Container) error {
+		return runHealthcheck(c, w)
+	}, nil, true)
+}
+
 func (p *dockerProvisioner) AddUnits(a provision.App, units uint, process string, w io.Writer) error {
 	if a.GetDeploys() == 0 {
 		return errors.New("New units can only be added after the first deployment")
@@ -611,29 +697,72 @@ func (p *dockerProvisioner) RemoveUnits(a provision.App, units uint, processName
 	if units == 0 {
 		return errors.New("cannot remove zero units")
 	}
-	var err error
 	if w == nil {
 		w = ioutil.Discard
 	}
-	imgID, err := image.AppCurrentImageName(a.GetName())
+	p, toRemove, err := p.removableContainers(a, units, processName)
 	if err != nil {
 		return err
 	}
+	fmt.Fprintf(w, "\n---- Removing %d %s ----\n", units, pluralize("unit", int(units)))
+	args := changeUnitsPipelineArgs{
+		app:         a,
+		toRemove:    toRemove,
+		writer:      w,
+		provisioner: p,
+	}
+	pipeline := action.NewPipeline(
+		&removeOldRoutes,
+		&provisionRemoveOldUnits,
+		&provisionUnbindOldUnits,
+	)
+	err = pipeline.Execute(args)
+	if err != nil {
+		return errors.Wrap(err, "error removing routes, units weren't removed")
+	}
+	return nil
+}
+
+func (p *dockerProvisioner) RemoveUnitsDry(a provision.App, units uint, processName string) ([]provision.Unit, error) {
+	if a == nil {
+		return nil, errors.New("remove units: app should not be nil")
+	}
+	if units == 0 {
+		return nil, errors.New("cannot remove zero units")
+	}
+	_, toRemove, err := p.removableContainers(a, units, processName)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]provision.Unit, len(toRemove))
+	for i, c := range toRemove {
+		result[i] = c.AsUnit(a)
+	}
+	return result, nil
+}
+
+// removableContainers chooses the containers of the process removed by
+// RemoveUnits. It returns the cloned provisioner whose scheduler ignores the
+// chosen containers.
+func (p *dockerProvisioner) removableContainers(a provision.App, units uint, processName string) (*dockerProvisioner, []container.Container, error) {
+	imgID, err := image.AppCurrentImageName(a.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
 	_, processName, err = dockercommon.ProcessCmdForImage(processName, imgID)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	containers, err := p.listContainersByProcess(a.GetName(), processName)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if len(containers) < int(units) {
-		return errors.Errorf("cannot remove %d units from process %q, only %d available", units, processName, len(containers))
+		return nil, nil, errors.Errorf("cannot remove %d units from process %q, only %d available", units, processName, len(containers))
 	}
-	fmt.Fprintf(w, "\n---- Removing %d %s ----\n", units, pluralize("unit", int(units)))
 	p, err = p.cloneProvisioner(nil)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	toRemove := make([]container.Container, 0, units)
 	for i := 0; i < int(units); i++ {
@@ -643,31 +772,16 @@ func (p *dockerProvisioner) RemoveUnits(a provision.App, units uint, processName
 		)
 		containerID, err = p.scheduler.GetRemovableContainer(a.GetName(), processName)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		cont, err = p.GetContainer(containerID)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		p.scheduler.ignoredContainers = append(p.scheduler.ignoredContainers, cont.ID)
 		toRemove = append(toRemove, *cont)
 	}
-	args := changeUnitsPipelineArgs{
-		app:         a,
-		toRemove:    toRemove,
-		writer:      w,
-		provisioner: p,
-	}
-	pipeline := action.NewPipeline(
-		&removeOldRoutes,
-		&provisionRemoveOldUnits,
-		&provisionUnbindOldUnits,
-	)
-	err = pipeline.Execute(args)
-	if err != nil {
-		return errors.Wrap(err, "error removing routes, units weren't removed")
-	}
-	return nil
+	return p, toRemove, nil
 }
 
 func (p *dockerProvisioner) SetUnitStatus(unit provision.Unit, status provision.Status) error {
@@ -929,8 +1043,9 @@ func (p *dockerProvisioner) FilterAppsByUnitStatus(apps []provision.App, status
 }
 
 var (
-	_ provision.Node              = &clusterNodeWrapper{}
-	_ provision.NodeHealthChecker = &clusterNodeWrapper{}
+	_ provision.Node                      = &clusterNodeWrapper{}
+	_ provision.NodeHealthChecker         = &clusterNodeWrapper{}
+	_ provision.NodeCircuitBreakerChecker = &clusterNodeWrapper{}
 )
 
 type clusterNodeWrapper struct {
@@ -962,6 +1077,10 @@ func (n *clusterNodeWrapper) ExtraData() map[string]string {
 	return n.Node.ExtraMetadata()
 }
 
+func (n *clusterNodeWrapper) CircuitBreaker() provision.NodeCircuitBreaker {
+	return dockercommon.NodeBreakerState(n.Node.Address)
+}
+
 func (n *clusterNodeWrapper) Units() ([]provision.Unit, error) {
 	if n.prov == nil {
 		return nil, errors.New("no provisioner instance in node wrapper")
@@ -981,6 +1100,62 @@ func (n *clusterNodeWrapper) Units() ([]provision.Unit, error) {
 	return units, nil
 }
 
+// UnitsByNode returns the units in every node of the cluster, grouped by node
+// address. Containers are loaded with a single aggregation instead of one
+// query per node.
+func (p *dockerProvisioner) UnitsByNode() (map[string][]provision.Unit, error) {
+	nodes, err := p.Cluster().UnfilteredNodes()
+	if err != nil {
+		return nil, err
+	}
+	addrMap := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		addrMap[net.URLToHost(n.Address)] = n.Address
+	}
+	groups, err := p.listContainersGroupedByHost()
+	if err != nil {
+		return nil, err
+	}
+	appNames := map[string]struct{}{}
+	filter := &app.Filter{}
+	for _, g := range groups {
+		for _, c := range g.Containers {
+			if _, ok := appNames[c.AppName]; !ok {
+				appNames[c.AppName] = struct{}{}
+				filter.ExtraIn("name", c.AppName)
+			}
+		}
+	}
+	result := map[string][]provision.Unit{}
+	if len(appNames) == 0 {
+		return result, nil
+	}
+	apps, err := app.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	appMap := make(map[string]provision.App, len(apps))
+	for i := range apps {
+		appMap[apps[i].Name] = &apps[i]
+	}
+	for _, g := range groups {
+		addr, ok := addrMap[g.Host]
+		if !ok {
+			continue
+		}
+		units := make([]provision.Unit, 0, len(g.Containers))
+		for _, c := range g.Containers {
+			a, ok := appMap[c.AppName]
+			if !ok {
+				continue
+			}
+			units = append(units, c.AsUnit(a))
+		}
+		result[addr] = units
+	}
+	return result, nil
+}
+
 func (n *clusterNodeWrapper) Provisioner() provision.NodeProvisioner {
 	return n.prov
 }
@@ -1090,7 +1265,11 @@ func (p *dockerProvisioner) AddNode(opts provision.AddNodeOptions) error {
 	if err == nil && job != nil {
 		_, err = job.Result()
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	nodehook.NotifyNodeAddress(nodehook.EventAdd, p, opts.Address)
+	return nil
 }
 
 func (p *dockerProvisioner) UpdateNode(opts provision.UpdateNodeOptions) error {
@@ -1111,7 +1290,11 @@ func (p *dockerProvisioner) UpdateNode(opts provision.UpdateNodeOptions) error {
 	if err == clusterStorage.ErrNoSuchNode {
 		return provision.ErrNodeNotFound
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	nodehook.NotifyNodeAddress(nodehook.UpdateEvent(opts), p, opts.Address)
+	return nil
 }
 
 func (p *dockerProvisioner) GetNode(address string) (provision.Node, error) {
@@ -1144,17 +1327,34 @@ func (p *dockerProvisioner) RemoveNode(opts provision.RemoveNodeOptions) error {
 			return err
 		}
 	}
-	return p.Cluster().Unregister(opts.Address)
+	err = p.Cluster().Unregister(opts.Address)
+	if err != nil {
+		return err
+	}
+	nodehook.NotifyNode(nodehook.EventRemove, &clusterNodeWrapper{Node: &node, prov: p})
+	return nil
 }
 
 func (p *dockerProvisioner) UpgradeNodeContainer(name string, pool string, writer io.Writer) error {
 	return internalNodeContainer.RecreateNamedContainers(p, writer, name, pool)
 }
 
+func (p *dockerProvisioner) UpgradeNodeContainerDry(name string, pool string, writer io.Writer) error {
+	return internalNodeContainer.RecreateNamedContainersDry(p, writer, name, pool)
+}
+
 func (p *dockerProvisioner) RemoveNodeContainer(name string, pool string, writer io.Writer) error {
 	return internalNodeContainer.RemoveNamedContainers(p, writer, name, pool)
 }
 
+func (p *dockerProvisioner) NodeContainerLogs(node provision.Node, name string, opts provision.NodeContainerLogsOptions) error {
+	return internalNodeContainer.Logs(p, node.Address(), name, opts)
+}
+
+func (p *dockerProvisioner) NodeContainerStatus(name string, pool string) ([]provision.NodeContainerStatus, error) {
+	return internalNodeContainer.Status(p, name, pool)
+}
+
 func (p *dockerProvisioner) RebalanceNodes(opts provision.RebalanceNodesOptions) (bool, error) {
 	if opts.MetadataFilter == nil {
 		opts.MetadataFilter = map[string]string{}
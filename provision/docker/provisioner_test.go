@@ -979,6 +979,53 @@ func (s *S) TestProvisionerRemoveUnits(c *check.C) {
 	c.Assert(papp.HasBind(&units[2]), check.Equals, false)
 }
 
+func (s *S) TestProvisionerRemoveUnitsDry(c *check.C) {
+	a1 := app.App{Name: "impius", Teams: []string{"tsuruteam", "nodockerforme"}, Pool: "pool1"}
+	cont1 := container.Container{Container: types.Container{ID: "1", Name: "impius1", AppName: a1.Name, ProcessName: "web", HostAddr: "url0", HostPort: "1"}}
+	cont2 := container.Container{Container: types.Container{ID: "2", Name: "mirror1", AppName: a1.Name, ProcessName: "worker", HostAddr: "url0", HostPort: "2"}}
+	cont3 := container.Container{Container: types.Container{ID: "3", Name: "dedication1", AppName: a1.Name, ProcessName: "web", HostAddr: "url0", HostPort: "3"}}
+	err := s.conn.Apps().Insert(a1)
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	err = contColl.Insert(cont1, cont2, cont3)
+	c.Assert(err, check.IsNil)
+	scheduler := segregatedScheduler{provisioner: s.p}
+	s.p.storage = &cluster.MapStorage{}
+	clusterInstance, err := cluster.New(&scheduler, s.p.storage, "")
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	s.p.scheduler = &scheduler
+	err = clusterInstance.Register(cluster.Node{
+		Address:  "http://url0:1234",
+		Metadata: map[string]string{"pool": "pool1"},
+	})
+	c.Assert(err, check.IsNil)
+	customData := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	}
+	err = image.SaveImageCustomData("tsuru/app-"+a1.Name, customData)
+	c.Assert(err, check.IsNil)
+	papp := provisiontest.NewFakeApp(a1.Name, "python", 0)
+	units, err := s.p.RemoveUnitsDry(papp, 2, "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+	ids := []string{units[0].ID, units[1].ID}
+	sort.Strings(ids)
+	c.Assert(ids, check.DeepEquals, []string{"1", "3"})
+	for _, cont := range []container.Container{cont1, cont2, cont3} {
+		_, err = s.p.GetContainer(cont.ID)
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(s.p.scheduler.ignoredContainers, check.IsNil)
+	_, err = s.p.RemoveUnitsDry(papp, 3, "web")
+	c.Assert(err, check.ErrorMatches, `cannot remove 3 units from process "web", only 2 available`)
+}
+
 func (s *S) TestProvisionerRemoveUnitsFailRemoveOldRoute(c *check.C) {
 	a1 := app.App{Name: "impius", Teams: []string{"tsuruteam", "nodockerforme"}, Pool: "pool1"}
 	cont1 := container.Container{Container: types.Container{ID: "1", Name: "impius1", AppName: a1.Name, ProcessName: "web", HostAddr: "url0", HostPort: "1"}}
@@ -1880,9 +1927,11 @@ func (s *S) TestProvisionerUnits(c *check.C) {
 	app := app.App{Name: "myapplication"}
 	coll := s.p.Collection()
 	defer coll.Close()
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Second)
 	err := coll.Insert(
 		container.Container{
 			Container: types.Container{
+				MongoID:  bson.NewObjectIdWithTime(createdAt),
 				ID:       "9930c24f1c4f",
 				AppName:  app.Name,
 				Type:     "python",
@@ -1907,6 +1956,7 @@ func (s *S) TestProvisionerUnits(c *check.C) {
 				Scheme: "http",
 				Host:   "192.168.123.9:9025",
 			},
+			CreatedAt: createdAt,
 		},
 	}
 	c.Assert(units, check.DeepEquals, expected)
@@ -1976,9 +2026,11 @@ func (s *S) TestProvisionerUnitsStatus(c *check.C) {
 	app := app.App{Name: "myapplication"}
 	coll := s.p.Collection()
 	defer coll.Close()
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Second)
 	err := coll.Insert(
 		container.Container{
 			Container: types.Container{
+				MongoID:  bson.NewObjectIdWithTime(createdAt),
 				ID:       "9930c24f1c4f",
 				AppName:  app.Name,
 				Type:     "python",
@@ -1990,6 +2042,7 @@ func (s *S) TestProvisionerUnitsStatus(c *check.C) {
 		},
 		container.Container{
 			Container: types.Container{
+				MongoID:  bson.NewObjectIdWithTime(createdAt.Add(time.Minute)),
 				ID:       "9930c24f1c4j",
 				AppName:  app.Name,
 				Type:     "python",
@@ -2015,6 +2068,7 @@ func (s *S) TestProvisionerUnitsStatus(c *check.C) {
 				Scheme: "http",
 				Host:   "10.0.0.7:9025",
 			},
+			CreatedAt: createdAt,
 		},
 		{
 			ID:      "9930c24f1c4j",
@@ -2026,6 +2080,7 @@ func (s *S) TestProvisionerUnitsStatus(c *check.C) {
 				Scheme: "http",
 				Host:   "10.0.0.7:9025",
 			},
+			CreatedAt: createdAt.Add(time.Minute),
 		},
 	}
 	c.Assert(units, check.DeepEquals, expected)
@@ -2035,9 +2090,11 @@ func (s *S) TestProvisionerUnitsIp(c *check.C) {
 	app := app.App{Name: "myapplication"}
 	coll := s.p.Collection()
 	defer coll.Close()
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Second)
 	err := coll.Insert(
 		container.Container{
 			Container: types.Container{
+				MongoID:  bson.NewObjectIdWithTime(createdAt),
 				ID:       "9930c24f1c4f",
 				AppName:  app.Name,
 				Type:     "python",
@@ -2062,6 +2119,7 @@ func (s *S) TestProvisionerUnitsIp(c *check.C) {
 				Scheme: "http",
 				Host:   "127.0.0.1:9025",
 			},
+			CreatedAt: createdAt,
 		},
 	}
 	c.Assert(units, check.DeepEquals, expected)
@@ -2508,6 +2566,21 @@ func (s *S) TestListNodes(c *check.C) {
 	c.Assert(listedNodes, check.DeepEquals, []provision.Node{})
 }
 
+func (s *S) TestNodeCircuitBreaker(c *check.C) {
+	config.Set("docker:circuit-breaker:failures", 1)
+	defer config.Unset("docker:circuit-breaker:failures")
+	defer dockercommon.ResetNodeBreakers()
+	node, err := s.p.GetNode(s.server.URL())
+	c.Assert(err, check.IsNil)
+	breakerNode, ok := node.(provision.NodeCircuitBreakerChecker)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(breakerNode.CircuitBreaker(), check.DeepEquals, provision.NodeCircuitBreaker{State: "closed"})
+	dockercommon.NodeBreakerDone(s.server.URL(), docker.ErrConnectionRefused)
+	breaker := breakerNode.CircuitBreaker()
+	c.Assert(breaker.State, check.Equals, "open")
+	c.Assert(breaker.Failures, check.Equals, 1)
+}
+
 func (s *S) TestAddNode(c *check.C) {
 	server, waitQueue := startFakeDockerNode(c)
 	defer server.Stop()
@@ -2763,6 +2836,44 @@ func (s *S) TestNodeUnits(c *check.C) {
 	c.Assert(units, check.DeepEquals, expected)
 }
 
+func (s *S) TestUnitsByNode(c *check.C) {
+	unitsByNode, err := s.p.UnitsByNode()
+	c.Assert(err, check.IsNil)
+	c.Assert(unitsByNode, check.DeepEquals, map[string][]provision.Unit{})
+	err = newFakeImage(s.p, "tsuru/app-myapp", nil)
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	err = s.p.Provision(appInstance)
+	c.Assert(err, check.IsNil)
+	imageID, err := image.AppCurrentImageName(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	appStruct := s.newAppFromFake(appInstance)
+	err = s.conn.Apps().Insert(appStruct)
+	c.Assert(err, check.IsNil)
+	containers, err := addContainersWithHost(&changeUnitsPipelineArgs{
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 3}},
+		app:         appInstance,
+		imageID:     imageID,
+		provisioner: s.p,
+	})
+	c.Assert(err, check.IsNil)
+	nodes, err := s.p.ListNodes(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	unitsByNode, err = s.p.UnitsByNode()
+	c.Assert(err, check.IsNil)
+	c.Assert(unitsByNode, check.HasLen, 1)
+	units := unitsByNode[nodes[0].Address()]
+	expected := []provision.Unit{
+		containers[0].AsUnit(appInstance),
+		containers[1].AsUnit(appInstance),
+		containers[2].AsUnit(appInstance),
+	}
+	sortUnits(units)
+	sortUnits(expected)
+	c.Assert(units, check.DeepEquals, expected)
+}
+
 func (s *S) TestUpdateNode(c *check.C) {
 	nodes, err := s.p.Cluster().Nodes()
 	c.Assert(err, check.IsNil)
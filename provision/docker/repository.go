@@ -142,6 +142,21 @@ func (p *dockerProvisioner) listAppsForNodes(nodes []*cluster.Node) ([]string, e
 	return appNames, err
 }
 
+type hostContainers struct {
+	Host       string                `bson:"_id"`
+	Containers []container.Container `bson:"containers"`
+}
+
+func (p *dockerProvisioner) listContainersGroupedByHost() ([]hostContainers, error) {
+	coll := p.Collection()
+	defer coll.Close()
+	var result []hostContainers
+	err := coll.Pipe([]bson.M{
+		{"$group": bson.M{"_id": "$hostaddr", "containers": bson.M{"$push": "$$ROOT"}}},
+	}).All(&result)
+	return result, err
+}
+
 func (p *dockerProvisioner) ListContainers(query bson.M) ([]container.Container, error) {
 	var list []container.Container
 	coll := p.Collection()
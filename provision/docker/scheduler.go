@@ -7,6 +7,7 @@ package docker
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -21,12 +22,15 @@ import (
 	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/provision/pool"
 )
 
 type segregatedScheduler struct {
 	hostMutex           sync.Mutex
 	maxMemoryRatio      float32
 	TotalMemoryMetadata string
+	TotalCPUMetadata    string
 	provisioner         *dockerProvisioner
 	// ignored containers is only set in provisioner returned by
 	// cloneProvisioner which will set this field to exclude some container
@@ -50,22 +54,32 @@ func (s *segregatedScheduler) Schedule(c *cluster.Cluster, opts *docker.CreateCo
 		}
 	}
 	if appName == "" {
-		return s.scheduleAnyNode(c, filterNodesMap)
+		node, err := s.scheduleAnyNode(c, filterNodesMap)
+		if err == nil && schedOpts != nil {
+			schedOpts.Node = node.Address
+		}
+		return node, err
 	}
 	a, _ := app.GetByName(schedOpts.AppName)
-	nodes, err := s.provisioner.Nodes(a)
-	if err != nil {
-		return cluster.Node{}, &container.SchedulerError{Base: err}
+	var nodes []cluster.Node
+	var minZones int
+	var err error
+	if schedOpts.BuildPool != "" {
+		nodes, err = s.buildPoolNodes(c, schedOpts.BuildPool, filterNodesMap)
+	} else {
+		nodes, err = s.appNodes(a, filterNodesMap)
+		if a != nil {
+			minZones = a.MinZones
+		}
 	}
-	nodes = filterNodes(nodes, filterNodesMap)
-	nodes, err = s.filterByMemoryUsage(a, nodes, s.maxMemoryRatio, s.TotalMemoryMetadata)
 	if err != nil {
 		return cluster.Node{}, &container.SchedulerError{Base: err}
 	}
-	node, err := s.chooseNodeToAdd(nodes, opts.Name, schedOpts.AppName, schedOpts.ProcessName)
+	node, err := s.chooseNodeToAdd(nodes, opts.Name, schedOpts.AppName, schedOpts.ProcessName, minZones)
 	if err != nil {
 		return cluster.Node{}, &container.SchedulerError{Base: err}
 	}
+	schedOpts.Node = node
 	if schedOpts.ActionLimiter != nil {
 		schedOpts.LimiterDone = schedOpts.ActionLimiter.Start(net.URLToHost(node))
 	}
@@ -78,6 +92,69 @@ func (s *segregatedScheduler) Schedule(c *cluster.Cluster, opts *docker.CreateCo
 	return cluster.Node{Address: node}, nil
 }
 
+func (s *segregatedScheduler) appNodes(a *app.App, filter map[string]struct{}) ([]cluster.Node, error) {
+	err := checkDedicatedPool(a)
+	if err != nil {
+		return nil, err
+	}
+	err = checkBuildPool(a)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.provisioner.Nodes(a)
+	if err != nil {
+		return nil, err
+	}
+	nodes = filterNodes(nodes, filter)
+	nodes, err = filterOpenBreakers(nodes)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterByResourceUsage(a, nodes)
+}
+
+// buildPoolNodes returns the nodes of the pool dedicated to builds, where
+// build containers are scheduled regardless of the pool of the app.
+func (s *segregatedScheduler) buildPoolNodes(c *cluster.Cluster, pool string, filter map[string]struct{}) ([]cluster.Node, error) {
+	nodes, err := c.NodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
+	if err != nil {
+		return nil, err
+	}
+	nodes = filterNodes(nodes, filter)
+	if len(nodes) == 0 {
+		return nil, errors.Errorf("no nodes found in build pool %q", pool)
+	}
+	return filterOpenBreakers(nodes)
+}
+
+// checkBuildPool refuses scheduling units of an app in the pool dedicated
+// to builds.
+func checkBuildPool(a *app.App) error {
+	if a == nil {
+		return nil
+	}
+	if pool := buildPool(); pool != "" && a.Pool == pool {
+		return errors.Errorf("pool %q is reserved for builds", pool)
+	}
+	return nil
+}
+
+// checkDedicatedPool refuses scheduling units of an app in a pool dedicated
+// to another team.
+func checkDedicatedPool(a *app.App) error {
+	if a == nil {
+		return nil
+	}
+	p, err := pool.GetPoolByName(a.Pool)
+	if err == pool.ErrPoolNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return p.CheckDedicated(a.TeamOwner)
+}
+
 func (s *segregatedScheduler) scheduleAnyNode(c *cluster.Cluster, filter map[string]struct{}) (cluster.Node, error) {
 	nodes, err := c.Nodes()
 	if err != nil {
@@ -87,6 +164,10 @@ func (s *segregatedScheduler) scheduleAnyNode(c *cluster.Cluster, filter map[str
 	if len(nodes) < 1 {
 		return cluster.Node{}, errors.New("There is no Docker node. Add one with `tsuru node-add`")
 	}
+	nodes, err = filterOpenBreakers(nodes)
+	if err != nil {
+		return cluster.Node{}, err
+	}
 	log.Debugf("[scheduler] Schedule any node with filter %#v possible nodes: %#v", filter, nodes)
 	nodeAddr, _, err := s.minMaxNodes(nodes, "", "")
 	if err != nil {
@@ -96,6 +177,24 @@ func (s *segregatedScheduler) scheduleAnyNode(c *cluster.Cluster, filter map[str
 	return cluster.Node{Address: nodeAddr}, nil
 }
 
+// filterOpenBreakers removes the nodes whose circuit breaker is open, so
+// units aren't scheduled in nodes with an unresponsive docker daemon.
+func filterOpenBreakers(nodes []cluster.Node) ([]cluster.Node, error) {
+	if len(nodes) == 0 {
+		return nodes, nil
+	}
+	var result []cluster.Node
+	for _, n := range nodes {
+		if dockercommon.NodeBreakerAvailable(n.Address) {
+			result = append(result, n)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.Wrap(dockercommon.ErrNodeCircuitOpen, "no nodes available")
+	}
+	return result, nil
+}
+
 func (s *segregatedScheduler) updateContainerName(opts *docker.CreateContainerOptions, appName string) error {
 	if opts.Name == "" {
 		return nil
@@ -111,39 +210,50 @@ func (s *segregatedScheduler) updateContainerName(opts *docker.CreateContainerOp
 	return nil
 }
 
+// filterByResourceUsage removes the nodes close to their physical memory
+// limit and the nodes without enough memory or CPU left for a new container
+// of the app.
+func (s *segregatedScheduler) filterByResourceUsage(a *app.App, nodes []cluster.Node) ([]cluster.Node, error) {
+	nodes, err := filterSaturatedNodes(nodes)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err = s.filterByMemoryUsage(a, nodes, s.maxMemoryRatio, s.TotalMemoryMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterByCPUUsage(a, nodes)
+}
+
 func (s *segregatedScheduler) filterByMemoryUsage(a *app.App, nodes []cluster.Node, maxMemoryRatio float32, TotalMemoryMetadata string) ([]cluster.Node, error) {
 	if maxMemoryRatio == 0 || TotalMemoryMetadata == "" {
 		return nodes, nil
 	}
+	conf, err := LoadSchedulerConfig(a.Pool)
+	if err != nil {
+		return nil, err
+	}
 	hosts := make([]string, len(nodes))
 	for i := range nodes {
 		hosts[i] = net.URLToHost(nodes[i].Address)
 	}
-	containers, err := s.provisioner.ListContainers(bson.M{"hostaddr": bson.M{"$in": hosts}, "id": bson.M{"$nin": s.ignoredContainers}})
+	reserved, err := s.hostReserved(hosts)
 	if err != nil {
 		return nil, err
 	}
-	hostReserved := make(map[string]int64)
-	for _, cont := range containers {
-		contApp, err := app.GetByName(cont.AppName)
-		if err != nil {
-			return nil, err
-		}
-		hostReserved[cont.HostAddr] += contApp.Plan.Memory
-	}
 	megabyte := float64(1024 * 1024)
 	nodeList := make([]cluster.Node, 0, len(nodes))
 	for _, node := range nodes {
 		totalMemory, _ := strconv.ParseFloat(node.Metadata[TotalMemoryMetadata], 64)
 		shouldAdd := true
 		if totalMemory != 0 {
-			maxMemory := totalMemory * float64(maxMemoryRatio)
+			maxMemory := totalMemory * float64(maxMemoryRatio) * conf.MemoryOvercommit
 			host := net.URLToHost(node.Address)
-			nodeReserved := hostReserved[host] + a.Plan.Memory
+			nodeReserved := reserved.memory[host] + a.Plan.Memory
 			if nodeReserved > int64(maxMemory) {
 				shouldAdd = false
 				tryingToReserveMB := float64(a.Plan.Memory) / megabyte
-				reservedMB := float64(hostReserved[host]) / megabyte
+				reservedMB := float64(reserved.memory[host]) / megabyte
 				limitMB := maxMemory / megabyte
 				log.Errorf("Node %q has reached its memory limit. "+
 					"Limit %0.4fMB. Reserved: %0.4fMB. Needed additional %0.4fMB",
@@ -155,24 +265,107 @@ func (s *segregatedScheduler) filterByMemoryUsage(a *app.App, nodes []cluster.No
 		}
 	}
 	if len(nodeList) == 0 {
-		var autoScaleEnabled bool
-		rule, _ := autoscale.AutoScaleRuleForMetadata(a.Pool)
-		if rule != nil {
-			autoScaleEnabled = rule.Enabled
-		}
 		errMsg := fmt.Sprintf("no nodes found with enough memory for container of %q: %0.4fMB",
 			a.Name, float64(a.Plan.Memory)/megabyte)
-		if autoScaleEnabled {
-			// Allow going over quota temporarily because auto-scale will be
-			// able to detect this and automatically add a new nodes.
-			log.Errorf("WARNING: %s. Will ignore memory restrictions.", errMsg)
-			return nodes, nil
+		return allowOverQuota(a, nodes, errMsg)
+	}
+	return nodeList, nil
+}
+
+// cpuSharesPerCPU is the number of cpu shares equivalent to one CPU of a
+// node, as used by docker.
+const cpuSharesPerCPU = 1024
+
+// filterByCPUUsage removes the nodes where the cpu shares reserved by the
+// plans of the apps would go over the number of CPUs of the node, multiplied
+// by the CPU overcommit factor of the pool.
+func (s *segregatedScheduler) filterByCPUUsage(a *app.App, nodes []cluster.Node) ([]cluster.Node, error) {
+	if s.TotalCPUMetadata == "" || a.Plan.CpuShare == 0 {
+		return nodes, nil
+	}
+	conf, err := LoadSchedulerConfig(a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(nodes))
+	for i := range nodes {
+		hosts[i] = net.URLToHost(nodes[i].Address)
+	}
+	reserved, err := s.hostReserved(hosts)
+	if err != nil {
+		return nil, err
+	}
+	nodeList := make([]cluster.Node, 0, len(nodes))
+	for _, node := range nodes {
+		totalCPU, _ := strconv.ParseFloat(node.Metadata[s.TotalCPUMetadata], 64)
+		if totalCPU != 0 {
+			maxShares := totalCPU * cpuSharesPerCPU * conf.CPUOvercommit
+			host := net.URLToHost(node.Address)
+			if reserved.cpuShares[host]+int64(a.Plan.CpuShare) > int64(maxShares) {
+				log.Errorf("Node %q has reached its cpu limit. Limit %0.0f shares. Reserved: %d shares. Needed additional %d shares",
+					host, maxShares, reserved.cpuShares[host], a.Plan.CpuShare)
+				continue
+			}
 		}
-		return nil, errors.New(errMsg)
+		nodeList = append(nodeList, node)
+	}
+	if len(nodeList) == 0 {
+		errMsg := fmt.Sprintf("no nodes found with enough cpu for container of %q: %d cpu shares",
+			a.Name, a.Plan.CpuShare)
+		return allowOverQuota(a, nodes, errMsg)
 	}
 	return nodeList, nil
 }
 
+// allowOverQuota returns all the given nodes if auto scale is enabled in the
+// pool of the app, or an error with errMsg otherwise.
+func allowOverQuota(a *app.App, nodes []cluster.Node, errMsg string) ([]cluster.Node, error) {
+	var autoScaleEnabled bool
+	rule, _ := autoscale.AutoScaleRuleForMetadata(a.Pool)
+	if rule != nil {
+		autoScaleEnabled = rule.Enabled
+	}
+	if autoScaleEnabled {
+		// Allow going over quota temporarily because auto-scale will be
+		// able to detect this and automatically add a new nodes.
+		log.Errorf("WARNING: %s. Will ignore resource restrictions.", errMsg)
+		return nodes, nil
+	}
+	return nil, errors.New(errMsg)
+}
+
+// hostReservation holds the memory and cpu shares reserved by the plans of
+// the apps with containers in each host.
+type hostReservation struct {
+	memory    map[string]int64
+	cpuShares map[string]int64
+}
+
+func (s *segregatedScheduler) hostReserved(hosts []string) (*hostReservation, error) {
+	containers, err := s.provisioner.ListContainers(bson.M{"hostaddr": bson.M{"$in": hosts}, "id": bson.M{"$nin": s.ignoredContainers}})
+	if err != nil {
+		return nil, err
+	}
+	apps := make(map[string]*app.App)
+	reserved := &hostReservation{
+		memory:    make(map[string]int64),
+		cpuShares: make(map[string]int64),
+	}
+	for _, cont := range containers {
+		contApp, ok := apps[cont.AppName]
+		if !ok {
+			contApp, err = app.GetByName(cont.AppName)
+			if err != nil {
+				return nil, err
+			}
+			apps[cont.AppName] = contApp
+		}
+		reserved.memory[cont.HostAddr] += contApp.Plan.Memory
+		reserved.cpuShares[cont.HostAddr] += int64(contApp.Plan.CpuShare)
+	}
+	return reserved, nil
+}
+
 type nodeAggregate struct {
 	HostAddr string `bson:"_id"`
 	Count    int
@@ -279,11 +472,17 @@ func (s *segregatedScheduler) nodesToHosts(nodes []cluster.Node) ([]string, map[
 }
 
 // chooseNodeToAdd finds which is the node with the minimum number of containers
-// and returns it
-func (s *segregatedScheduler) chooseNodeToAdd(nodes []cluster.Node, contName string, appName, process string) (string, error) {
+// and returns it. When minZones is greater than one, only nodes in zones
+// without containers of the app process are considered until they're spread
+// across minZones zones.
+func (s *segregatedScheduler) chooseNodeToAdd(nodes []cluster.Node, contName string, appName, process string, minZones int) (string, error) {
 	log.Debugf("[scheduler] Possible nodes for container %s: %#v", contName, nodes)
 	s.hostMutex.Lock()
 	defer s.hostMutex.Unlock()
+	nodes, err := s.filterByZoneSpread(nodes, appName, process, minZones)
+	if err != nil {
+		return "", err
+	}
 	chosenNode, _, err := s.minMaxNodes(nodes, appName, process)
 	if err != nil {
 		return "", err
@@ -297,6 +496,56 @@ func (s *segregatedScheduler) chooseNodeToAdd(nodes []cluster.Node, contName str
 	return chosenNode, err
 }
 
+// filterByZoneSpread keeps only the nodes in availability zones without
+// containers of the app process while these containers are spread across less
+// than minZones zones. An error is returned when the nodes are in less than
+// minZones zones.
+func (s *segregatedScheduler) filterByZoneSpread(nodes []cluster.Node, appName, process string, minZones int) ([]cluster.Node, error) {
+	if minZones <= 1 {
+		return nodes, nil
+	}
+	zoneMetadata := provision.ZoneMetadataName()
+	zones := map[string]struct{}{}
+	for _, n := range nodes {
+		if zone := n.Metadata[zoneMetadata]; zone != "" {
+			zones[zone] = struct{}{}
+		}
+	}
+	if len(zones) < minZones {
+		return nil, errors.Errorf("app %q requires units spread across %d zones, but only %d zones are available", appName, minZones, len(zones))
+	}
+	allNodes, err := s.provisioner.Cluster().UnfilteredNodes()
+	if err != nil {
+		return nil, err
+	}
+	hosts, _ := s.nodesToHosts(allNodes)
+	hostZones := make(map[string]string, len(allNodes))
+	for i, n := range allNodes {
+		hostZones[hosts[i]] = n.Metadata[zoneMetadata]
+	}
+	countMap, err := s.aggregateContainersByHostAppProcess(hosts, appName, process)
+	if err != nil {
+		return nil, err
+	}
+	usedZones := map[string]struct{}{}
+	for host, count := range countMap {
+		if zone := hostZones[host]; count > 0 && zone != "" {
+			usedZones[zone] = struct{}{}
+		}
+	}
+	if len(usedZones) >= minZones {
+		return nodes, nil
+	}
+	result := make([]cluster.Node, 0, len(nodes))
+	for _, n := range nodes {
+		zone := n.Metadata[zoneMetadata]
+		if _, used := usedZones[zone]; zone != "" && !used {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
 // chooseContainerToRemove finds a container from the the node with maximum
 // number of containers and returns it
 func (s *segregatedScheduler) chooseContainerToRemove(nodes []cluster.Node, appName, process string) (string, error) {
@@ -324,10 +573,46 @@ func appGroupCount(hostGroups map[string]int, appCountHost map[string]int) map[s
 	return result
 }
 
-// Find the host with the minimum (good to add a new container) and maximum
-// (good to remove a container) value for the pair [(number of containers for
-// app-process), (number of containers in host)]
-func (s *segregatedScheduler) minMaxNodes(nodes []cluster.Node, appName, process string) (string, string, error) {
+// hostScores holds the data used to score hosts when choosing where to add
+// or remove containers of an app process.
+type hostScores struct {
+	strategy       string
+	hosts          []string
+	hostsMap       map[string]string
+	hostGroupMap   map[string]int
+	hostCountMap   map[string]int
+	appCountMap    map[string]int
+	memoryTotal    map[string]float64
+	memoryReserved map[string]int64
+	// maxMemoryRatio and unitMemory are only set when simulating the
+	// placement of several containers, so binpack stops choosing a host
+	// once it would go over the memory limit. maxMemoryRatio already
+	// accounts for the memory overcommit of the pool.
+	maxMemoryRatio float64
+	unitMemory     int64
+}
+
+// nodesPool returns the pool of the given nodes, they are expected to belong
+// to the same pool when scheduling containers of an app.
+func nodesPool(nodes []cluster.Node) string {
+	for _, n := range nodes {
+		if pool := n.Metadata[provision.PoolMetadataName]; pool != "" {
+			return pool
+		}
+	}
+	return ""
+}
+
+// hostScores loads the data used to score the given nodes. An empty strategy
+// means the strategy configured for the pool of the nodes.
+func (s *segregatedScheduler) hostScores(nodes []cluster.Node, appName, process, strategy string) (*hostScores, error) {
+	if strategy == "" {
+		var err error
+		strategy, err = SchedulerStrategy(nodesPool(nodes))
+		if err != nil {
+			return nil, err
+		}
+	}
 	nodesList := make(provision.NodeList, len(nodes))
 	for i := range nodes {
 		nodesList[i] = &clusterNodeWrapper{Node: &nodes[i], prov: s.provisioner}
@@ -345,17 +630,101 @@ func (s *segregatedScheduler) minMaxNodes(nodes []cluster.Node, appName, process
 	hosts, hostsMap := s.nodesToHosts(nodes)
 	hostCountMap, err := s.aggregateContainersByHost(hosts)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	appCountMap, err := s.aggregateContainersByHostAppProcess(hosts, appName, process)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	priorityEntries := []map[string]int{appGroupCount(hostGroupMap, appCountMap), appCountMap, hostCountMap}
+	scores := &hostScores{
+		strategy:     strategy,
+		hosts:        hosts,
+		hostsMap:     hostsMap,
+		hostGroupMap: hostGroupMap,
+		hostCountMap: hostCountMap,
+		appCountMap:  appCountMap,
+	}
+	if strategy == SchedulerStrategyBinpack && s.TotalMemoryMetadata != "" {
+		scores.memoryTotal = make(map[string]float64)
+		for _, node := range nodes {
+			totalMemory, _ := strconv.ParseFloat(node.Metadata[s.TotalMemoryMetadata], 64)
+			scores.memoryTotal[net.URLToHost(node.Address)] = totalMemory
+		}
+		reserved, err := s.hostReserved(hosts)
+		if err != nil {
+			return nil, err
+		}
+		scores.memoryReserved = reserved.memory
+	}
+	return scores, nil
+}
+
+// add accounts for a new container with the given memory in host.
+func (h *hostScores) add(host string, memory int64) {
+	h.hostCountMap[host]++
+	h.appCountMap[host]++
+	if h.memoryReserved != nil {
+		h.memoryReserved[host] += memory
+	}
+}
+
+// load returns how used a host is: the ratio of reserved memory when the
+// total memory of the node is known, or its number of containers otherwise.
+func (h *hostScores) load(host string) float64 {
+	if total := h.memoryTotal[host]; total > 0 {
+		return float64(h.memoryReserved[host]) / total
+	}
+	return float64(h.hostCountMap[host])
+}
+
+func (h *hostScores) fits(host string) bool {
+	total := h.memoryTotal[host]
+	if h.maxMemoryRatio == 0 || total == 0 {
+		return true
+	}
+	return float64(h.memoryReserved[host]+h.unitMemory) <= total*h.maxMemoryRatio
+}
+
+// binpackMinMax returns the most used host with room for a new container,
+// which should receive new containers, and the least used host running
+// containers of the app process, which should have containers removed first.
+func (h *hostScores) binpackMinMax() (string, string) {
+	var addHost, fallbackHost, removeHost string
+	addLoad, fallbackLoad, removeLoad := -1.0, -1.0, math.MaxFloat64
+	for _, host := range h.hosts {
+		load := h.load(host)
+		if load > fallbackLoad {
+			fallbackLoad = load
+			fallbackHost = host
+		}
+		if h.fits(host) && (load > addLoad || (load == addLoad && h.appCountMap[host] < h.appCountMap[addHost])) {
+			addLoad = load
+			addHost = host
+		}
+		if h.appCountMap[host] > 0 && load < removeLoad {
+			removeLoad = load
+			removeHost = host
+		}
+	}
+	if addHost == "" {
+		addHost = fallbackHost
+	}
+	if removeHost == "" {
+		removeHost = addHost
+	}
+	return h.hostsMap[addHost], h.hostsMap[removeHost]
+}
+
+// minMax returns the nodes with the minimum and maximum scores.
+func (h *hostScores) minMax() (string, string) {
+	if h.strategy == SchedulerStrategyBinpack {
+		return h.binpackMinMax()
+	}
+	priorityEntries := []map[string]int{appGroupCount(h.hostGroupMap, h.appCountMap), h.appCountMap, h.hostCountMap}
 	var minHost, maxHost string
 	var minScore uint64 = math.MaxUint64
 	var maxScore uint64 = 0
-	for _, host := range hosts {
+	for _, host := range h.hosts {
 		var score uint64
 		for i, e := range priorityEntries {
 			score += uint64(e[host]) << uint((len(priorityEntries)-i-1)*(64/len(priorityEntries)))
@@ -369,7 +738,93 @@ func (s *segregatedScheduler) minMaxNodes(nodes []cluster.Node, appName, process
 			maxHost = host
 		}
 	}
-	return hostsMap[minHost], hostsMap[maxHost], nil
+	return h.hostsMap[minHost], h.hostsMap[maxHost]
+}
+
+// Find the host with the minimum (good to add a new container) and maximum
+// (good to remove a container) value for the pair [(number of containers for
+// app-process), (number of containers in host)]
+func (s *segregatedScheduler) minMaxNodes(nodes []cluster.Node, appName, process string) (string, string, error) {
+	scores, err := s.hostScores(nodes, appName, process, "")
+	if err != nil {
+		return "", "", err
+	}
+	minHost, maxHost := scores.minMax()
+	return minHost, maxHost, nil
+}
+
+// planNodes returns the nodes expected to receive new containers of an app,
+// simulating the choices made by Schedule for each container to be added,
+// without reserving any node. If toHost is not empty only nodes in this host
+// are considered.
+func (s *segregatedScheduler) planNodes(a *app.App, toAdd map[string]*containersToAdd, toHost string) ([]string, error) {
+	placement, err := s.simulatePlacement(a, toAdd, toHost, "")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(placement))
+	for node := range placement {
+		result = append(result, node)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// simulatePlacement returns how many of the containers to be added would be
+// placed in each node using the given strategy, an empty strategy means the
+// strategy configured for the pool of the app.
+func (s *segregatedScheduler) simulatePlacement(a *app.App, toAdd map[string]*containersToAdd, toHost, strategy string) (map[string]int, error) {
+	nodes, err := s.provisioner.Nodes(a)
+	if err != nil {
+		return nil, err
+	}
+	if toHost != "" {
+		filterNodesMap := map[string]struct{}{}
+		for _, n := range nodes {
+			if net.URLToHost(n.Address) == toHost {
+				filterNodesMap[n.Address] = struct{}{}
+			}
+		}
+		if len(filterNodesMap) == 0 {
+			return nil, nil
+		}
+		nodes = filterNodes(nodes, filterNodesMap)
+	}
+	nodes, err = s.filterByResourceUsage(a, nodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	conf, err := LoadSchedulerConfig(a.Pool)
+	if err != nil {
+		return nil, err
+	}
+	placement := map[string]int{}
+	addedToHost := map[string]int{}
+	for process, ct := range toAdd {
+		scores, err := s.hostScores(nodes, a.Name, process, strategy)
+		if err != nil {
+			return nil, err
+		}
+		scores.maxMemoryRatio = float64(s.maxMemoryRatio) * conf.MemoryOvercommit
+		scores.unitMemory = a.Plan.Memory
+		for host, count := range addedToHost {
+			scores.hostCountMap[host] += count
+			if scores.memoryReserved != nil {
+				scores.memoryReserved[host] += int64(count) * a.Plan.Memory
+			}
+		}
+		for i := 0; i < ct.Quantity; i++ {
+			node, _ := scores.minMax()
+			host := net.URLToHost(node)
+			scores.add(host, a.Plan.Memory)
+			addedToHost[host]++
+			placement[node]++
+		}
+	}
+	return placement, nil
 }
 
 func filterNodes(nodes []cluster.Node, filter map[string]struct{}) []cluster.Node {
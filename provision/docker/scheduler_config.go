@@ -0,0 +1,107 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/scopedconfig"
+)
+
+const (
+	// SchedulerStrategySpread distributes the units of each app among as
+	// many nodes as possible, favoring availability.
+	SchedulerStrategySpread = "spread"
+	// SchedulerStrategyBinpack places units in the most used nodes that
+	// still have room for them, favoring cost by keeping nodes idle.
+	SchedulerStrategyBinpack = "binpack"
+
+	schedulerConfigCollection = "scheduler"
+)
+
+var (
+	ErrInvalidSchedulerStrategy = errors.Errorf("invalid scheduler strategy, possible values are %q and %q", SchedulerStrategySpread, SchedulerStrategyBinpack)
+	ErrInvalidOvercommit        = errors.New("overcommit factors must be greater than or equal to 1")
+)
+
+// SchedulerConfig holds the scheduler settings of a pool. The overcommit
+// factors multiply the memory and CPU capacity of the nodes in the pool when
+// the scheduler checks whether a new unit fits in a node.
+type SchedulerConfig struct {
+	Strategy         string  `json:"strategy"`
+	MemoryOvercommit float64 `json:"memoryOvercommit"`
+	CPUOvercommit    float64 `json:"cpuOvercommit"`
+}
+
+func loadSchedulerConfig() *scopedconfig.ScopedConfig {
+	conf := scopedconfig.FindScopedConfig(schedulerConfigCollection)
+	conf.ShallowMerge = true
+	return conf
+}
+
+func validStrategy(strategy string) bool {
+	return strategy == SchedulerStrategySpread || strategy == SchedulerStrategyBinpack
+}
+
+// LoadSchedulerConfig returns the scheduler config of the given pool, merged
+// with the default config. Unset values are filled with the spread strategy
+// and no overcommit.
+func LoadSchedulerConfig(pool string) (SchedulerConfig, error) {
+	var conf SchedulerConfig
+	err := loadSchedulerConfig().Load(pool, &conf)
+	if err != nil {
+		return conf, err
+	}
+	if conf.Strategy == "" {
+		conf.Strategy = SchedulerStrategySpread
+	}
+	if conf.MemoryOvercommit == 0 {
+		conf.MemoryOvercommit = 1
+	}
+	if conf.CPUOvercommit == 0 {
+		conf.CPUOvercommit = 1
+	}
+	return conf, nil
+}
+
+// SchedulerStrategy returns the scheduler strategy used in the given pool,
+// falling back to the default config and then to spread.
+func SchedulerStrategy(pool string) (string, error) {
+	conf, err := LoadSchedulerConfig(pool)
+	if err != nil {
+		return "", err
+	}
+	return conf.Strategy, nil
+}
+
+// SchedulerLoadAll returns the scheduler config of every pool, the default
+// config is indexed by the empty string.
+func SchedulerLoadAll() (map[string]SchedulerConfig, error) {
+	var all map[string]SchedulerConfig
+	err := loadSchedulerConfig().LoadAll(&all)
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (c *SchedulerConfig) validate() error {
+	if c.Strategy != "" && !validStrategy(c.Strategy) {
+		return ErrInvalidSchedulerStrategy
+	}
+	if (c.MemoryOvercommit != 0 && c.MemoryOvercommit < 1) || (c.CPUOvercommit != 0 && c.CPUOvercommit < 1) {
+		return ErrInvalidOvercommit
+	}
+	return nil
+}
+
+// Save stores the config of the given pool, unset values keep their current
+// value.
+func (c *SchedulerConfig) Save(pool string) error {
+	err := c.validate()
+	if err != nil {
+		return err
+	}
+	return loadSchedulerConfig().SaveMerge(pool, *c)
+}
@@ -9,12 +9,15 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/fsouza/go-dockerclient/testing"
 	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/docker-cluster/cluster"
 	"github.com/tsuru/tsuru/app"
@@ -22,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision/docker/container"
 	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/provision/pool"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	"gopkg.in/check.v1"
@@ -82,6 +86,165 @@ func (s *S) TestSchedulerSchedule(c *check.C) {
 	c.Check(node.Address, check.Equals, localURL)
 }
 
+func (s *S) TestSchedulerPlanNodes(c *check.C) {
+	a1 := app.App{Name: "impius", Teams: []string{"tsuruteam"}, Pool: "pool1"}
+	a2 := app.App{Name: "mirror", Teams: []string{"tsuruteam"}, Pool: "pool1"}
+	err := s.conn.Apps().Insert(a1, a2)
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = pool.AddTeamsToPool("pool1", []string{"tsuruteam"})
+	c.Assert(err, check.IsNil)
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	err = contColl.Insert(
+		container.Container{Container: types.Container{ID: "1", Name: "mirror1", AppName: a2.Name, HostAddr: "127.0.0.1"}},
+	)
+	c.Assert(err, check.IsNil)
+	scheduler := segregatedScheduler{provisioner: s.p}
+	clusterInstance, err := cluster.New(&scheduler, &cluster.MapStorage{}, "")
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	server1, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server1.Stop()
+	server2, err := testing.NewServer("localhost:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server2.Stop()
+	localURL := strings.Replace(server2.URL(), "127.0.0.1", "localhost", -1)
+	err = clusterInstance.Register(cluster.Node{Address: server1.URL(), Metadata: map[string]string{"pool": "pool1"}})
+	c.Assert(err, check.IsNil)
+	err = clusterInstance.Register(cluster.Node{Address: localURL, Metadata: map[string]string{"pool": "pool1"}})
+	c.Assert(err, check.IsNil)
+	nodes, err := scheduler.planNodes(&a1, map[string]*containersToAdd{"web": {Quantity: 1}}, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.DeepEquals, []string{localURL})
+	nodes, err = scheduler.planNodes(&a1, map[string]*containersToAdd{"web": {Quantity: 2}, "worker": {Quantity: 1}}, "")
+	c.Assert(err, check.IsNil)
+	expected := []string{server1.URL(), localURL}
+	sort.Strings(expected)
+	c.Assert(nodes, check.DeepEquals, expected)
+	nodes, err = scheduler.planNodes(&a1, map[string]*containersToAdd{"web": {Quantity: 2}}, "127.0.0.1")
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.DeepEquals, []string{server1.URL()})
+}
+
+func (s *S) TestSchedulerStrategy(c *check.C) {
+	strategy, err := SchedulerStrategy("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.Equals, SchedulerStrategySpread)
+	conf := SchedulerConfig{Strategy: "random"}
+	err = conf.Save("pool1")
+	c.Assert(err, check.Equals, ErrInvalidSchedulerStrategy)
+	conf = SchedulerConfig{Strategy: SchedulerStrategyBinpack}
+	err = conf.Save("")
+	c.Assert(err, check.IsNil)
+	conf = SchedulerConfig{Strategy: SchedulerStrategySpread}
+	err = conf.Save("pool2")
+	c.Assert(err, check.IsNil)
+	strategy, err = SchedulerStrategy("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.Equals, SchedulerStrategyBinpack)
+	strategy, err = SchedulerStrategy("pool2")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.Equals, SchedulerStrategySpread)
+}
+
+func (s *S) TestSchedulerConfigOvercommit(c *check.C) {
+	conf, err := LoadSchedulerConfig("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(conf, check.DeepEquals, SchedulerConfig{Strategy: SchedulerStrategySpread, MemoryOvercommit: 1, CPUOvercommit: 1})
+	conf = SchedulerConfig{MemoryOvercommit: 0.5}
+	err = conf.Save("pool1")
+	c.Assert(err, check.Equals, ErrInvalidOvercommit)
+	conf = SchedulerConfig{Strategy: SchedulerStrategyBinpack}
+	err = conf.Save("pool1")
+	c.Assert(err, check.IsNil)
+	conf = SchedulerConfig{MemoryOvercommit: 1.5, CPUOvercommit: 2}
+	err = conf.Save("pool1")
+	c.Assert(err, check.IsNil)
+	conf, err = LoadSchedulerConfig("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(conf, check.DeepEquals, SchedulerConfig{Strategy: SchedulerStrategyBinpack, MemoryOvercommit: 1.5, CPUOvercommit: 2})
+}
+
+func (s *S) TestSchedulerSimulatePlacementBinpackWithMemory(c *check.C) {
+	a1 := app.App{Name: "skyrim", Plan: appTypes.Plan{Memory: 40000}, Pool: "pool1"}
+	a2 := app.App{Name: "oblivion", Plan: appTypes.Plan{Memory: 30000}, Pool: "pool1"}
+	err := s.conn.Apps().Insert(a1, a2)
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	conf := SchedulerConfig{Strategy: SchedulerStrategyBinpack}
+	err = conf.Save("pool1")
+	c.Assert(err, check.IsNil)
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	err = contColl.Insert(
+		container.Container{Container: types.Container{ID: "1", Name: "skyrim1", AppName: a1.Name, HostAddr: "127.0.0.1"}},
+	)
+	c.Assert(err, check.IsNil)
+	scheduler := segregatedScheduler{
+		maxMemoryRatio:      0.8,
+		TotalMemoryMetadata: "totalMemory",
+		provisioner:         s.p,
+	}
+	clusterInstance, err := cluster.New(&scheduler, &cluster.MapStorage{}, "")
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	server1, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server1.Stop()
+	server2, err := testing.NewServer("localhost:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer server2.Stop()
+	localURL := strings.Replace(server2.URL(), "127.0.0.1", "localhost", -1)
+	err = clusterInstance.Register(cluster.Node{Address: server1.URL(), Metadata: map[string]string{"pool": "pool1", "totalMemory": "100000"}})
+	c.Assert(err, check.IsNil)
+	err = clusterInstance.Register(cluster.Node{Address: localURL, Metadata: map[string]string{"pool": "pool1", "totalMemory": "100000"}})
+	c.Assert(err, check.IsNil)
+	placement, err := scheduler.simulatePlacement(&a2, map[string]*containersToAdd{"web": {Quantity: 3}}, "", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(placement, check.DeepEquals, map[string]int{server1.URL(): 1, localURL: 2})
+	placement, err = scheduler.simulatePlacement(&a2, map[string]*containersToAdd{"web": {Quantity: 2}}, "", SchedulerStrategySpread)
+	c.Assert(err, check.IsNil)
+	c.Assert(placement, check.DeepEquals, map[string]int{server1.URL(): 1, localURL: 1})
+}
+
+func (s *S) TestChooseNodeBinpack(c *check.C) {
+	conf := SchedulerConfig{Strategy: SchedulerStrategyBinpack}
+	err := conf.Save("pool1")
+	c.Assert(err, check.IsNil)
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"pool": "pool1"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"pool": "pool1"}},
+	}
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	err = contColl.Insert(
+		container.Container{Container: types.Container{ID: "pre1", Name: "existing1", AppName: "other", HostAddr: "server1"}},
+		container.Container{Container: types.Container{ID: "pre2", Name: "existing2", AppName: "coolapp9", ProcessName: "web", HostAddr: "server2"}},
+		container.Container{Container: types.Container{ID: "pre3", Name: "existing3", AppName: "other", HostAddr: "server2"}},
+	)
+	c.Assert(err, check.IsNil)
+	sched := segregatedScheduler{provisioner: s.p}
+	for i := 0; i < 3; i++ {
+		cont := container.Container{Container: types.Container{ID: fmt.Sprintf("new%d", i), Name: fmt.Sprintf("unit%d", i), AppName: "coolapp9", ProcessName: "web"}}
+		err = contColl.Insert(cont)
+		c.Assert(err, check.IsNil)
+		node, err := sched.chooseNodeToAdd(nodes, cont.Name, "coolapp9", "web", 0)
+		c.Assert(err, check.IsNil)
+		c.Assert(node, check.Equals, "http://server2:1234")
+	}
+	err = contColl.Insert(
+		container.Container{Container: types.Container{ID: "pre4", Name: "existing4", AppName: "coolapp9", ProcessName: "web", HostAddr: "server1"}},
+	)
+	c.Assert(err, check.IsNil)
+	id, err := sched.chooseContainerToRemove(nodes, "coolapp9", "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(id, check.Equals, "pre4")
+}
+
 func (s *S) TestSchedulerScheduleFilteringNodes(c *check.C) {
 	a1 := app.App{Name: "impius", Teams: []string{"tsuruteam", "nodockerforme"}, Pool: "pool1"}
 	a2 := app.App{Name: "mirror", Teams: []string{"tsuruteam"}, Pool: "pool1"}
@@ -379,6 +542,157 @@ func (s *S) TestSchedulerScheduleWithMemoryAwareness(c *check.C) {
 	c.Assert(node, check.DeepEquals, cluster.Node{})
 }
 
+func (s *S) TestSchedulerScheduleDedicatedPool(c *check.C) {
+	err := pool.AddPool(pool.AddPoolOptions{Name: "mypool", Dedicated: true})
+	c.Assert(err, check.IsNil)
+	err = pool.AddTeamsToPool("mypool", []string{s.team.Name})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "skyrim", Pool: "mypool", TeamOwner: "otherteam"}
+	err = s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	segSched := segregatedScheduler{provisioner: s.p}
+	opts := docker.CreateContainerOptions{Name: "unit1"}
+	_, err = segSched.Schedule(s.p.Cluster(), &opts, &container.SchedulerOpts{AppName: a.Name, ProcessName: "web"})
+	c.Assert(err, check.ErrorMatches, `.*Pool "mypool" is dedicated to another team, apps from team "otherteam" can't run on it.*`)
+}
+
+func (s *S) TestSchedulerScheduleBuildPool(c *check.C) {
+	a := app.App{Name: "skyrim", Pool: "mypool"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	segSched := segregatedScheduler{provisioner: s.p}
+	clusterInstance, err := cluster.New(&segSched, &cluster.MapStorage{}, "",
+		cluster.Node{Address: "http://server1:1234", Metadata: map[string]string{"pool": "mypool"}},
+		cluster.Node{Address: "http://server2:1234", Metadata: map[string]string{"pool": "builders"}},
+	)
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	opts := docker.CreateContainerOptions{Name: "build1"}
+	node, err := segSched.Schedule(clusterInstance, &opts, &container.SchedulerOpts{AppName: a.Name, BuildPool: "builders"})
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Address, check.Equals, "http://server2:1234")
+	_, err = segSched.Schedule(clusterInstance, &opts, &container.SchedulerOpts{AppName: a.Name, BuildPool: "otherbuilders"})
+	c.Assert(err, check.ErrorMatches, `.*no nodes found in build pool "otherbuilders".*`)
+}
+
+func (s *S) TestSchedulerScheduleAppInBuildPool(c *check.C) {
+	config.Set("docker:builder:pool", "builders")
+	defer config.Unset("docker:builder:pool")
+	config.Set("docker:registry", "localhost:3030")
+	defer config.Unset("docker:registry")
+	a := app.App{Name: "skyrim", Pool: "builders"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	segSched := segregatedScheduler{provisioner: s.p}
+	opts := docker.CreateContainerOptions{Name: "unit1"}
+	_, err = segSched.Schedule(s.p.Cluster(), &opts, &container.SchedulerOpts{AppName: a.Name, ProcessName: "web"})
+	c.Assert(err, check.ErrorMatches, `.*pool "builders" is reserved for builds.*`)
+}
+
+func (s *S) TestSchedulerFilterByMemoryUsageOvercommit(c *check.C) {
+	a1 := app.App{Name: "skyrim", Plan: appTypes.Plan{Memory: 60000}, Pool: "mypool"}
+	a2 := app.App{Name: "oblivion", Plan: appTypes.Plan{Memory: 30000}, Pool: "mypool"}
+	err := s.conn.Apps().Insert(a1, a2)
+	c.Assert(err, check.IsNil)
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	err = contColl.Insert(
+		container.Container{Container: types.Container{ID: "pre1", Name: "existing1", AppName: a1.Name, HostAddr: "server1"}},
+	)
+	c.Assert(err, check.IsNil)
+	sched := segregatedScheduler{
+		maxMemoryRatio:      0.8,
+		TotalMemoryMetadata: "totalMemory",
+		provisioner:         s.p,
+	}
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"pool": "mypool", "totalMemory": "100000"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"pool": "mypool", "totalMemory": "100000"}},
+	}
+	filtered, err := sched.filterByMemoryUsage(&a2, nodes, sched.maxMemoryRatio, sched.TotalMemoryMetadata)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[1:])
+	conf := SchedulerConfig{MemoryOvercommit: 1.5}
+	err = conf.Save("mypool")
+	c.Assert(err, check.IsNil)
+	filtered, err = sched.filterByMemoryUsage(&a2, nodes, sched.maxMemoryRatio, sched.TotalMemoryMetadata)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+}
+
+func (s *S) TestSchedulerFilterByCPUUsage(c *check.C) {
+	a1 := app.App{Name: "skyrim", Plan: appTypes.Plan{CpuShare: 1024}, Pool: "mypool"}
+	a2 := app.App{Name: "oblivion", Plan: appTypes.Plan{CpuShare: 512}, Pool: "mypool"}
+	err := s.conn.Apps().Insert(a1, a2)
+	c.Assert(err, check.IsNil)
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	err = contColl.Insert(
+		container.Container{Container: types.Container{ID: "pre1", Name: "existing1", AppName: a1.Name, HostAddr: "server1"}},
+		container.Container{Container: types.Container{ID: "pre2", Name: "existing2", AppName: a1.Name, HostAddr: "server2"}},
+	)
+	c.Assert(err, check.IsNil)
+	sched := segregatedScheduler{
+		TotalCPUMetadata: "cpus",
+		provisioner:      s.p,
+	}
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"pool": "mypool", "cpus": "1"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"pool": "mypool", "cpus": "2"}},
+	}
+	filtered, err := sched.filterByCPUUsage(&a2, nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[1:])
+	_, err = sched.filterByCPUUsage(&a2, nodes[:1])
+	c.Assert(err, check.ErrorMatches, `no nodes found with enough cpu for container of "oblivion": 512 cpu shares`)
+	conf := SchedulerConfig{CPUOvercommit: 2}
+	err = conf.Save("mypool")
+	c.Assert(err, check.IsNil)
+	filtered, err = sched.filterByCPUUsage(&a2, nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+}
+
+func (s *S) TestFilterSaturatedNodes(c *check.C) {
+	config.Set("docker:scheduler:overcommit:check-interval", 60)
+	defer config.Unset("docker:scheduler:overcommit:check-interval")
+	coll, err := nodeUsageColl()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	err = coll.Insert(
+		nodeUsage{Host: "server1", Saturated: true, UpdatedAt: time.Now().UTC()},
+		nodeUsage{Host: "server2", Saturated: true, UpdatedAt: time.Now().UTC().Add(-time.Hour)},
+		nodeUsage{Host: "server3", Saturated: false, UpdatedAt: time.Now().UTC()},
+	)
+	c.Assert(err, check.IsNil)
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234"},
+		{Address: "http://server2:1234"},
+		{Address: "http://server3:1234"},
+	}
+	filtered, err := filterSaturatedNodes(nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[1:])
+	_, err = filterSaturatedNodes(nodes[:1])
+	c.Assert(err, check.ErrorMatches, "all nodes are close to their physical memory limit")
+}
+
+func (s *S) TestFilterOpenBreakers(c *check.C) {
+	config.Set("docker:circuit-breaker:failures", 1)
+	defer config.Unset("docker:circuit-breaker:failures")
+	defer dockercommon.ResetNodeBreakers()
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234"},
+		{Address: "http://server2:1234"},
+	}
+	dockercommon.NodeBreakerDone("server1", docker.ErrConnectionRefused)
+	filtered, err := filterOpenBreakers(nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[1:])
+	_, err = filterOpenBreakers(nodes[:1])
+	c.Assert(errors.Cause(err), check.Equals, dockercommon.ErrNodeCircuitOpen)
+}
+
 func (s *S) TestSchedulerScheduleWithMemoryAwarenessWithAutoScale(c *check.C) {
 	config.Set("docker:scheduler:total-memory-metadata", "memory")
 	defer config.Unset("docker:scheduler:total-memory-metadata")
@@ -587,7 +901,7 @@ func (s *S) TestChooseNodeDistributesNodesEqually(c *check.C) {
 			cont := container.Container{Container: types.Container{ID: string(i), Name: fmt.Sprintf("unit%d", i), AppName: "coolapp9"}}
 			insertErr := contColl.Insert(cont)
 			c.Assert(insertErr, check.IsNil)
-			node, insertErr := sched.chooseNodeToAdd(nodes, cont.Name, "coolapp9", "web")
+			node, insertErr := sched.chooseNodeToAdd(nodes, cont.Name, "coolapp9", "web", 0)
 			c.Assert(insertErr, check.IsNil)
 			c.Assert(node, check.NotNil)
 		}(i)
@@ -633,7 +947,7 @@ func (s *S) TestChooseNodeDistributesNodesEquallyDifferentApps(c *check.C) {
 			cont := container.Container{Container: types.Container{ID: string(i), Name: fmt.Sprintf("unit%d", i), AppName: "oblivion", ProcessName: "web"}}
 			insertErr := contColl.Insert(cont)
 			c.Assert(insertErr, check.IsNil)
-			node, insertErr := sched.chooseNodeToAdd(nodes, cont.Name, "oblivion", "web")
+			node, insertErr := sched.chooseNodeToAdd(nodes, cont.Name, "oblivion", "web", 0)
 			c.Assert(insertErr, check.IsNil)
 			c.Assert(node, check.NotNil)
 		}(i)
@@ -676,7 +990,7 @@ func (s *S) TestChooseNodeDistributesNodesEquallyDifferentProcesses(c *check.C)
 			cont := container.Container{Container: types.Container{ID: string(i), Name: fmt.Sprintf("unit%d", i), AppName: "skyrim", ProcessName: "worker"}}
 			insertErr := contColl.Insert(cont)
 			c.Assert(insertErr, check.IsNil)
-			node, insertErr := sched.chooseNodeToAdd(nodes, cont.Name, "skyrim", "worker")
+			node, insertErr := sched.chooseNodeToAdd(nodes, cont.Name, "skyrim", "worker", 0)
 			c.Assert(insertErr, check.IsNil)
 			c.Assert(node, check.NotNil)
 		}(i)
@@ -696,6 +1010,42 @@ func (s *S) TestChooseNodeDistributesNodesEquallyDifferentProcesses(c *check.C)
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *S) TestChooseNodeToAddSpreadsAcrossZones(c *check.C) {
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"zone": "a"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"zone": "a"}},
+		{Address: "http://server3:1234", Metadata: map[string]string{"zone": "b"}},
+	}
+	sched := segregatedScheduler{provisioner: s.p}
+	clusterInstance, err := cluster.New(&sched, &cluster.MapStorage{}, "", nodes...)
+	c.Assert(err, check.IsNil)
+	s.p.cluster = clusterInstance
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	err = contColl.Insert(
+		container.Container{Container: types.Container{ID: "pre1", Name: "existing1", AppName: "myapp", ProcessName: "web", HostAddr: "server1"}},
+		container.Container{Container: types.Container{ID: "pre2", Name: "existing2", AppName: "myapp", ProcessName: "worker", HostAddr: "server3"}},
+	)
+	c.Assert(err, check.IsNil)
+	filtered, err := sched.filterByZoneSpread(nodes, "myapp", "web", 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, []cluster.Node{nodes[2]})
+	filtered, err = sched.filterByZoneSpread(nodes, "myapp", "worker", 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[:2])
+	_, err = sched.filterByZoneSpread(nodes, "myapp", "web", 3)
+	c.Assert(err, check.ErrorMatches, `app "myapp" requires units spread across 3 zones, but only 2 zones are available`)
+	cont := container.Container{Container: types.Container{ID: "new1", Name: "unit1", AppName: "myapp", ProcessName: "web"}}
+	err = contColl.Insert(cont)
+	c.Assert(err, check.IsNil)
+	node, err := sched.chooseNodeToAdd(nodes, cont.Name, "myapp", "web", 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(node, check.Equals, "http://server3:1234")
+	filtered, err = sched.filterByZoneSpread(nodes, "myapp", "web", 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+}
+
 func (s *S) TestChooseNodeDistributesNodesConsideringMetadata(c *check.C) {
 	nodes := []cluster.Node{
 		{Address: "http://server1:1234", Metadata: map[string]string{
@@ -717,7 +1067,7 @@ func (s *S) TestChooseNodeDistributesNodesConsideringMetadata(c *check.C) {
 		cont := container.Container{Container: types.Container{Name: fmt.Sprintf("unit%d", i), AppName: app, ProcessName: process}}
 		err := contColl.Insert(cont)
 		c.Assert(err, check.IsNil)
-		node, err := sched.chooseNodeToAdd(nodes, cont.Name, app, process)
+		node, err := sched.chooseNodeToAdd(nodes, cont.Name, app, process, 0)
 		c.Assert(err, check.IsNil)
 		c.Assert(node, check.Not(check.Equals), "")
 	}
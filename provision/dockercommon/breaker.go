@@ -0,0 +1,220 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockercommon
+
+import (
+	"context"
+	stdNet "net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const (
+	OperationCreate = "create"
+	OperationStart  = "start"
+	OperationPull   = "pull"
+
+	BreakerStateClosed   = "closed"
+	BreakerStateOpen     = "open"
+	BreakerStateHalfOpen = "half-open"
+
+	defaultBreakerOpenTimeout = 30 * time.Second
+)
+
+var (
+	ErrNodeCircuitOpen = errors.New("circuit breaker open for node, too many consecutive failures")
+
+	// Breakers are kept in memory, so each tsuru API process tracks the
+	// failures of the nodes it talks to on its own.
+	breakersMu sync.Mutex
+	breakers   = map[string]*nodeBreaker{}
+)
+
+type nodeBreaker struct {
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// breakerThreshold returns the number of consecutive failures opening the
+// circuit breaker of a node, set in docker:circuit-breaker:failures. Breakers
+// are disabled when it's zero.
+func breakerThreshold() int {
+	failures, _ := config.GetInt("docker:circuit-breaker:failures")
+	return failures
+}
+
+func breakerOpenTimeout() time.Duration {
+	seconds, _ := config.GetInt("docker:circuit-breaker:open-timeout")
+	if seconds <= 0 {
+		return defaultBreakerOpenTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// OperationTimeout returns the maximum duration of the given operation in a
+// docker node, set in docker:operation-timeout:<operation>. Zero means no
+// timeout.
+func OperationTimeout(operation string) time.Duration {
+	seconds, _ := config.GetInt("docker:operation-timeout:" + operation)
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// OperationContext returns a context derived from parent bound to the
+// timeout of the operation.
+func OperationContext(parent context.Context, operation string) (context.Context, context.CancelFunc) {
+	timeout := OperationTimeout(operation)
+	if timeout == 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// NodeBreakerAllow returns ErrNodeCircuitOpen when operations in the node
+// with the given address must fail fast. Once the breaker open timeout
+// expires, a single trial operation is allowed, closing the breaker again
+// when it succeeds.
+func NodeBreakerAllow(address string) error {
+	if breakerThreshold() <= 0 {
+		return nil
+	}
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b := breakers[net.URLToHost(address)]
+	if b == nil || b.openUntil.IsZero() {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return errors.Wrapf(ErrNodeCircuitOpen, "node %s", address)
+	}
+	b.trial = true
+	return nil
+}
+
+// NodeBreakerAvailable returns whether operations in the node may run,
+// without reserving the trial operation of half-open breakers.
+func NodeBreakerAvailable(address string) bool {
+	if breakerThreshold() <= 0 {
+		return true
+	}
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b := breakers[net.URLToHost(address)]
+	return b == nil || b.openUntil.IsZero() || (!b.trial && !time.Now().Before(b.openUntil))
+}
+
+// NodeBreakerDone records the result of an operation in the node. Only
+// errors showing the node is unreachable or unresponsive count as failures.
+func NodeBreakerDone(address string, err error) {
+	threshold := breakerThreshold()
+	if threshold <= 0 {
+		return
+	}
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	host := net.URLToHost(address)
+	b := breakers[host]
+	if !isNodeFailure(err) {
+		delete(breakers, host)
+		return
+	}
+	if b == nil {
+		b = &nodeBreaker{}
+		breakers[host] = b
+	}
+	b.failures++
+	b.trial = false
+	if b.failures >= threshold {
+		b.openUntil = time.Now().Add(breakerOpenTimeout())
+	}
+}
+
+// NodeBreakerState returns the state of the circuit breaker of the node.
+func NodeBreakerState(address string) provision.NodeCircuitBreaker {
+	state := provision.NodeCircuitBreaker{State: BreakerStateClosed}
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b := breakers[net.URLToHost(address)]
+	if b == nil {
+		return state
+	}
+	state.Failures = b.failures
+	if !b.openUntil.IsZero() {
+		state.OpenUntil = b.openUntil
+		state.State = BreakerStateOpen
+		if !time.Now().Before(b.openUntil) {
+			state.State = BreakerStateHalfOpen
+		}
+	}
+	return state
+}
+
+// ResetNodeBreakers closes all circuit breakers.
+func ResetNodeBreakers() {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakers = map[string]*nodeBreaker{}
+}
+
+// StartContainerInNode starts the container in the node with the given host,
+// guarded by the circuit breaker of the node and aborted when the start
+// timeout expires.
+func StartContainerInNode(ctx context.Context, c *cluster.Cluster, host, id string) error {
+	node, err := GetNodeByHost(c, host)
+	if err != nil {
+		return err
+	}
+	client, err := node.Client()
+	if err != nil {
+		return err
+	}
+	return StartContainerWithClient(ctx, client, node.Address, id)
+}
+
+// StartContainerWithClient starts the container using the client of the node
+// with the given address, guarded by the circuit breaker of the node and
+// aborted when ctx is done or the start timeout expires.
+func StartContainerWithClient(ctx context.Context, client *docker.Client, address, id string) error {
+	err := NodeBreakerAllow(address)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := OperationContext(ctx, OperationStart)
+	defer cancel()
+	err = client.StartContainerWithContext(id, nil, ctx)
+	NodeBreakerDone(address, err)
+	return err
+}
+
+func isNodeFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	err = errors.Cause(err)
+	if nodeErr, ok := err.(cluster.DockerNodeError); ok {
+		err = errors.Cause(nodeErr.BaseError())
+	}
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	switch e := err.(type) {
+	case *docker.Error:
+		return e.Status >= 500
+	case stdNet.Error:
+		return true
+	}
+	return err == context.DeadlineExceeded || err == docker.ErrConnectionRefused
+}
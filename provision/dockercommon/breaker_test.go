@@ -0,0 +1,133 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockercommon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestNodeBreakerDisabled(c *check.C) {
+	defer ResetNodeBreakers()
+	for i := 0; i < 10; i++ {
+		NodeBreakerDone("http://10.0.0.1:2375", docker.ErrConnectionRefused)
+	}
+	c.Assert(NodeBreakerAllow("http://10.0.0.1:2375"), check.IsNil)
+	c.Assert(NodeBreakerState("10.0.0.1"), check.DeepEquals, provision.NodeCircuitBreaker{State: BreakerStateClosed})
+}
+
+func (s *S) TestNodeBreakerOpensAfterFailures(c *check.C) {
+	config.Set("docker:circuit-breaker:failures", 2)
+	defer config.Unset("docker:circuit-breaker:failures")
+	defer ResetNodeBreakers()
+	NodeBreakerDone("http://10.0.0.1:2375", docker.ErrConnectionRefused)
+	c.Assert(NodeBreakerAllow("http://10.0.0.1:2375"), check.IsNil)
+	c.Assert(NodeBreakerState("http://10.0.0.1:2375").State, check.Equals, BreakerStateClosed)
+	c.Assert(NodeBreakerState("http://10.0.0.1:2375").Failures, check.Equals, 1)
+	NodeBreakerDone("http://10.0.0.1:2375", &docker.Error{Status: 500})
+	err := NodeBreakerAllow("10.0.0.1")
+	c.Assert(errors.Cause(err), check.Equals, ErrNodeCircuitOpen)
+	c.Assert(NodeBreakerAvailable("http://10.0.0.1:2375"), check.Equals, false)
+	state := NodeBreakerState("http://10.0.0.1:2375")
+	c.Assert(state.State, check.Equals, BreakerStateOpen)
+	c.Assert(state.Failures, check.Equals, 2)
+	c.Assert(state.OpenUntil.After(time.Now()), check.Equals, true)
+	c.Assert(NodeBreakerAllow("http://10.0.0.2:2375"), check.IsNil)
+}
+
+func (s *S) TestNodeBreakerIgnoresNonNodeErrors(c *check.C) {
+	config.Set("docker:circuit-breaker:failures", 1)
+	defer config.Unset("docker:circuit-breaker:failures")
+	defer ResetNodeBreakers()
+	NodeBreakerDone("http://10.0.0.1:2375", &docker.NoSuchContainer{ID: "x"})
+	NodeBreakerDone("http://10.0.0.1:2375", &docker.Error{Status: 404})
+	c.Assert(NodeBreakerAllow("http://10.0.0.1:2375"), check.IsNil)
+	NodeBreakerDone("http://10.0.0.1:2375", context.DeadlineExceeded)
+	c.Assert(NodeBreakerAllow("http://10.0.0.1:2375"), check.NotNil)
+}
+
+func (s *S) TestNodeBreakerHalfOpen(c *check.C) {
+	config.Set("docker:circuit-breaker:failures", 1)
+	defer config.Unset("docker:circuit-breaker:failures")
+	defer ResetNodeBreakers()
+	NodeBreakerDone("http://10.0.0.1:2375", docker.ErrConnectionRefused)
+	breakersMu.Lock()
+	breakers["10.0.0.1"].openUntil = time.Now().Add(-time.Second)
+	breakersMu.Unlock()
+	c.Assert(NodeBreakerState("http://10.0.0.1:2375").State, check.Equals, BreakerStateHalfOpen)
+	c.Assert(NodeBreakerAvailable("http://10.0.0.1:2375"), check.Equals, true)
+	c.Assert(NodeBreakerAllow("http://10.0.0.1:2375"), check.IsNil)
+	c.Assert(NodeBreakerAllow("http://10.0.0.1:2375"), check.NotNil)
+	c.Assert(NodeBreakerAvailable("http://10.0.0.1:2375"), check.Equals, false)
+	NodeBreakerDone("http://10.0.0.1:2375", nil)
+	c.Assert(NodeBreakerAllow("http://10.0.0.1:2375"), check.IsNil)
+	c.Assert(NodeBreakerState("http://10.0.0.1:2375"), check.DeepEquals, provision.NodeCircuitBreaker{State: BreakerStateClosed})
+}
+
+func (s *S) TestStartContainerWithClientTimeout(c *check.C) {
+	config.Set("docker:circuit-breaker:failures", 1)
+	config.Set("docker:operation-timeout:start", 1)
+	defer config.Unset("docker:circuit-breaker:failures")
+	defer config.Unset("docker:operation-timeout:start")
+	defer ResetNodeBreakers()
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+	client, err := docker.NewClient(server.URL)
+	c.Assert(err, check.IsNil)
+	start := time.Now()
+	err = StartContainerWithClient(context.Background(), client, server.URL, "c1")
+	c.Assert(err, check.Equals, context.DeadlineExceeded)
+	c.Assert(time.Since(start) < 5*time.Second, check.Equals, true)
+	err = StartContainerWithClient(context.Background(), client, server.URL, "c1")
+	c.Assert(errors.Cause(err), check.Equals, ErrNodeCircuitOpen)
+}
+
+func (s *S) TestStartContainerWithClientCanceled(c *check.C) {
+	config.Set("docker:circuit-breaker:failures", 1)
+	defer config.Unset("docker:circuit-breaker:failures")
+	defer ResetNodeBreakers()
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+	client, err := docker.NewClient(server.URL)
+	c.Assert(err, check.IsNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	err = StartContainerWithClient(ctx, client, server.URL, "c1")
+	c.Assert(err, check.Equals, context.Canceled)
+	c.Assert(NodeBreakerAllow(server.URL), check.IsNil)
+}
+
+func (s *S) TestOperationContext(c *check.C) {
+	ctx, cancel := OperationContext(context.Background(), OperationCreate)
+	defer cancel()
+	_, hasDeadline := ctx.Deadline()
+	c.Assert(hasDeadline, check.Equals, false)
+	config.Set("docker:operation-timeout:create", 30)
+	defer config.Unset("docker:operation-timeout:create")
+	ctx, cancel = OperationContext(context.Background(), OperationCreate)
+	defer cancel()
+	deadline, hasDeadline := ctx.Deadline()
+	c.Assert(hasDeadline, check.Equals, true)
+	c.Assert(time.Until(deadline) <= 30*time.Second, check.Equals, true)
+}
@@ -37,6 +37,10 @@ func (c *PullAndCreateClient) SetTimeout(timeout time.Duration) {
 	c.Client.HTTPClient.Timeout = timeout
 }
 
+func (c *PullAndCreateClient) StartContainerInNode(ctx context.Context, host, id string) error {
+	return StartContainerWithClient(ctx, c.Client, c.Client.Endpoint(), id)
+}
+
 func (c *PullAndCreateClient) PullAndCreateContainer(opts docker.CreateContainerOptions, w io.Writer) (*docker.Container, string, error) {
 	if w != nil {
 		w = &tsuruIo.DockerErrorCheckWriter{W: w}
@@ -0,0 +1,80 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockercommon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tsuru/config"
+)
+
+const dockerHubRegistry = "docker.io"
+
+// P2PEndpoint returns the address of the P2P image distribution layer (e.g.
+// a Dragonfly dfdaemon or a Spegel mirror) used by the nodes of the given
+// pool to pull images. The endpoint configured for the pool takes precedence
+// over the global one. An empty string means images are pulled directly from
+// their registries.
+func P2PEndpoint(pool string) string {
+	if pool != "" {
+		endpoint, err := config.GetString(fmt.Sprintf("docker:p2p:pools:%s:endpoint", pool))
+		if err == nil {
+			return strings.TrimSuffix(endpoint, "/")
+		}
+	}
+	endpoint, _ := config.GetString("docker:p2p:endpoint")
+	return strings.TrimSuffix(endpoint, "/")
+}
+
+func p2pRegistries() []string {
+	registries, _ := config.GetList("docker:p2p:registries")
+	if len(registries) == 0 {
+		if registry, _ := config.GetString("docker:registry"); registry != "" {
+			registries = []string{registry}
+		}
+	}
+	return registries
+}
+
+// splitRegistry splits the registry from the rest of the image name, using
+// the same rules as the docker daemon: the first component of the name is
+// a registry only when it looks like a hostname.
+func splitRegistry(imageName string) (string, string) {
+	parts := strings.SplitN(imageName, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0], parts[1]
+	}
+	if len(parts) == 1 {
+		return dockerHubRegistry, "library/" + imageName
+	}
+	return dockerHubRegistry, imageName
+}
+
+// PullImageName returns the name that must be used to pull imageName in the
+// nodes of the given pool. When a P2P endpoint is configured for the pool and
+// the image comes from one of the registries served by it, the registry in
+// the image name is replaced by the endpoint, otherwise imageName is returned
+// unchanged.
+func PullImageName(imageName, pool string) string {
+	endpoint := P2PEndpoint(pool)
+	if endpoint == "" || imageName == "" {
+		return imageName
+	}
+	registry, name := splitRegistry(imageName)
+	for _, r := range p2pRegistries() {
+		if r == registry {
+			return endpoint + "/" + name
+		}
+	}
+	return imageName
+}
+
+// MirrorImageName returns the name of imageName in the given registry
+// mirror, replacing the registry in the image name by the mirror.
+func MirrorImageName(imageName, mirror string) string {
+	_, name := splitRegistry(imageName)
+	return strings.TrimSuffix(mirror, "/") + "/" + name
+}
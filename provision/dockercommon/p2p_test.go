@@ -0,0 +1,71 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockercommon
+
+import (
+	"github.com/tsuru/config"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestP2PEndpoint(c *check.C) {
+	c.Assert(P2PEndpoint("pool1"), check.Equals, "")
+	config.Set("docker:p2p:endpoint", "localhost:65001/")
+	defer config.Unset("docker:p2p")
+	c.Assert(P2PEndpoint("pool1"), check.Equals, "localhost:65001")
+	c.Assert(P2PEndpoint(""), check.Equals, "localhost:65001")
+	config.Set("docker:p2p:pools:pool1:endpoint", "127.0.0.1:5000")
+	c.Assert(P2PEndpoint("pool1"), check.Equals, "127.0.0.1:5000")
+	c.Assert(P2PEndpoint("pool2"), check.Equals, "localhost:65001")
+}
+
+func (s *S) TestPullImageName(c *check.C) {
+	c.Assert(PullImageName("my.registry/tsuru/app-myapp:v1", "pool1"), check.Equals, "my.registry/tsuru/app-myapp:v1")
+	config.Set("docker:p2p:endpoint", "localhost:65001")
+	defer config.Unset("docker:p2p")
+	config.Set("docker:p2p:pools:pool2:endpoint", "127.0.0.1:5000")
+	tests := []struct {
+		image    string
+		pool     string
+		expected string
+	}{
+		{"my.registry/tsuru/app-myapp:v1", "pool1", "localhost:65001/tsuru/app-myapp:v1"},
+		{"my.registry/tsuru/app-myapp:v1", "pool2", "127.0.0.1:5000/tsuru/app-myapp:v1"},
+		{"other.registry/tsuru/app-myapp:v1", "pool1", "other.registry/tsuru/app-myapp:v1"},
+		{"tsuru/bs:v1", "pool1", "tsuru/bs:v1"},
+		{"", "pool1", ""},
+	}
+	for _, tt := range tests {
+		c.Check(PullImageName(tt.image, tt.pool), check.Equals, tt.expected)
+	}
+	config.Set("docker:p2p:registries", []interface{}{"docker.io", "other.registry"})
+	tests = []struct {
+		image    string
+		pool     string
+		expected string
+	}{
+		{"my.registry/tsuru/app-myapp:v1", "pool1", "my.registry/tsuru/app-myapp:v1"},
+		{"other.registry/tsuru/app-myapp:v1", "pool1", "localhost:65001/tsuru/app-myapp:v1"},
+		{"tsuru/bs:v1", "pool1", "localhost:65001/tsuru/bs:v1"},
+		{"busybox", "pool2", "127.0.0.1:5000/library/busybox"},
+	}
+	for _, tt := range tests {
+		c.Check(PullImageName(tt.image, tt.pool), check.Equals, tt.expected)
+	}
+}
+
+func (s *S) TestMirrorImageName(c *check.C) {
+	tests := []struct {
+		image    string
+		mirror   string
+		expected string
+	}{
+		{"my.registry/tsuru/bs:v1", "mirror.local:5000", "mirror.local:5000/tsuru/bs:v1"},
+		{"tsuru/bs:v1", "mirror.local/", "mirror.local/tsuru/bs:v1"},
+		{"busybox", "mirror.local", "mirror.local/library/busybox"},
+	}
+	for _, tt := range tests {
+		c.Check(MirrorImageName(tt.image, tt.mirror), check.Equals, tt.expected)
+	}
+}
@@ -185,6 +185,10 @@ func createPod(params createPodParams) error {
 		Pool:   params.app.GetPool(),
 		Prefix: tsuruLabelPrefix,
 	}).ToNodeByPoolSelector()
+	dnsConfig, err := dnsConfigForApp(params.app)
+	if err != nil {
+		return err
+	}
 	commitContainer := "committer-cont"
 	_, uid := dockercommon.UserForContainer()
 	kubeConf := getKubeConfig()
@@ -198,6 +202,7 @@ func createPod(params createPodParams) error {
 		Spec: apiv1.PodSpec{
 			ServiceAccountName: serviceAccountNameForApp(params.app),
 			NodeSelector:       nodeSelector,
+			DNSConfig:          dnsConfig,
 			Volumes: append([]apiv1.Volume{
 				{
 					Name: "dockersock",
@@ -397,6 +402,10 @@ func createAppDeployment(client *ClusterClient, oldDeployment *v1beta2.Deploymen
 	if err != nil {
 		return nil, nil, err
 	}
+	dnsConfig, err := dnsConfigForApp(a)
+	if err != nil {
+		return nil, nil, err
+	}
 	deployment := v1beta2.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      depName,
@@ -429,6 +438,7 @@ func createAppDeployment(client *ClusterClient, oldDeployment *v1beta2.Deploymen
 					NodeSelector:  nodeSelector,
 					Volumes:       volumes,
 					Subdomain:     headlessServiceNameForApp(a, process),
+					DNSConfig:     dnsConfig,
 					Containers: []apiv1.Container{
 						{
 							Name:           depName,
@@ -618,17 +628,18 @@ func (m *serviceManager) DeployService(a provision.App, process string, labels *
 	if err != nil {
 		return err
 	}
-	if m.writer == nil {
-		m.writer = ioutil.Discard
+	w := m.writer
+	if w == nil {
+		w = ioutil.Discard
 	}
-	err = monitorDeployment(m.client, dep, a, process, m.writer)
+	err = monitorDeployment(m.client, dep, a, process, w)
 	if err != nil {
-		fmt.Fprintf(m.writer, "\n**** ROLLING BACK AFTER FAILURE ****\n ---> %s <---\n", err)
+		fmt.Fprintf(w, "\n**** ROLLING BACK AFTER FAILURE ****\n ---> %s <---\n", err)
 		rollbackErr := m.client.ExtensionsV1beta1().Deployments(m.client.Namespace()).Rollback(&extensions.DeploymentRollback{
 			Name: depName,
 		})
 		if rollbackErr != nil {
-			fmt.Fprintf(m.writer, "\n**** ERROR DURING ROLLBACK ****\n ---> %s <---\n", rollbackErr)
+			fmt.Fprintf(w, "\n**** ERROR DURING ROLLBACK ****\n ---> %s <---\n", rollbackErr)
 		}
 		return err
 	}
@@ -19,6 +19,7 @@ import (
 	tsuruNet "github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"github.com/tsuru/tsuru/provision/pool"
 	"k8s.io/api/apps/v1beta2"
 	apiv1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -379,6 +380,10 @@ func cleanupDaemonSet(client *ClusterClient, name, pool string) error {
 	if err != nil && !k8sErrors.IsNotFound(err) {
 		return errors.WithStack(err)
 	}
+	err = client.CoreV1().Secrets(client.Namespace()).Delete(dsName, &metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.WithStack(err)
+	}
 	ls := provision.NodeContainerLabels(provision.NodeContainerLabelsOpts{
 		Name:        name,
 		Pool:        pool,
@@ -560,6 +565,10 @@ func runPod(args runSinglePodArgs) error {
 		Pool:   args.app.GetPool(),
 		Prefix: tsuruLabelPrefix,
 	}).ToNodeByPoolSelector()
+	dnsConfig, err := dnsConfigForApp(args.app)
+	if err != nil {
+		return err
+	}
 	pod := &apiv1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      args.name,
@@ -569,6 +578,7 @@ func runPod(args runSinglePodArgs) error {
 		Spec: apiv1.PodSpec{
 			ServiceAccountName: serviceAccountNameForApp(args.app),
 			NodeSelector:       nodeSelector,
+			DNSConfig:          dnsConfig,
 			RestartPolicy:      apiv1.RestartPolicyNever,
 			Containers: []apiv1.Container{
 				{
@@ -646,3 +656,21 @@ nodesloop:
 	}
 	return nil, provision.ErrNodeNotFound
 }
+
+// dnsConfigForApp returns the DNS config of the pool of the app, which is
+// merged by kubernetes with the config from the DNS policy of the pod.
+func dnsConfigForApp(a provision.App) (*apiv1.PodDNSConfig, error) {
+	dns, err := pool.DNSConfigForPool(a.GetPool())
+	if err != nil || dns == nil {
+		return nil, err
+	}
+	conf := &apiv1.PodDNSConfig{
+		Nameservers: dns.Nameservers,
+		Searches:    dns.Searches,
+	}
+	if dns.Ndots != nil {
+		ndots := strconv.Itoa(*dns.Ndots)
+		conf.Options = []apiv1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}}
+	}
+	return conf, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"gopkg.in/check.v1"
 	"k8s.io/api/apps/v1beta2"
@@ -510,3 +511,25 @@ func (s *S) TestGetServicePort(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(port, check.Equals, int32(123))
 }
+
+func (s *S) TestDNSConfigForApp(c *check.C) {
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	dnsConfig, err := dnsConfigForApp(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(dnsConfig, check.IsNil)
+	ndots := 2
+	err = pool.SetDNSConfig(a.GetPool(), &pool.DNSConfig{
+		Nameservers: []string{"10.0.0.10"},
+		Searches:    []string{"corp.example.com"},
+		Ndots:       &ndots,
+	})
+	c.Assert(err, check.IsNil)
+	dnsConfig, err = dnsConfigForApp(a)
+	c.Assert(err, check.IsNil)
+	ndotsValue := "2"
+	c.Assert(dnsConfig, check.DeepEquals, &apiv1.PodDNSConfig{
+		Nameservers: []string{"10.0.0.10"},
+		Searches:    []string{"corp.example.com"},
+		Options:     []apiv1.PodDNSConfigOption{{Name: "ndots", Value: &ndotsValue}},
+	})
+}
@@ -24,7 +24,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-const alphaAffinityAnnotation = "scheduler.alpha.kubernetes.io/affinity"
+const (
+	alphaAffinityAnnotation  = "scheduler.alpha.kubernetes.io/affinity"
+	appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+	seccompAnnotationPrefix  = "container.seccomp.security.alpha.kubernetes.io/"
+)
 
 type nodeContainerManager struct{}
 
@@ -80,6 +84,15 @@ func (m *nodeContainerManager) deployNodeContainerForCluster(client *ClusterClie
 		return errors.WithStack(err)
 	}
 	affinityAnnotation[alphaAffinityAnnotation] = string(affinityData)
+	config.ApplySecurity()
+	if config.Security != nil {
+		if config.Security.AppArmorProfile != "" {
+			affinityAnnotation[appArmorAnnotationPrefix+config.Name] = "localhost/" + config.Security.AppArmorProfile
+		}
+		if config.Security.SeccompProfile == nodecontainer.SeccompUnconfined {
+			affinityAnnotation[seccompAnnotationPrefix+config.Name] = nodecontainer.SeccompUnconfined
+		}
+	}
 	if oldDs != nil && placementOnly {
 		if reflect.DeepEqual(oldDs.Spec.Template.ObjectMeta.Annotations, affinityAnnotation) &&
 			reflect.DeepEqual(oldDs.Spec.Template.Spec.Affinity, affinity) {
@@ -97,13 +110,13 @@ func (m *nodeContainerManager) deployNodeContainerForCluster(client *ClusterClie
 		Provisioner:  provisionerName,
 		Prefix:       tsuruLabelPrefix,
 	})
-	envVars := make([]apiv1.EnvVar, len(config.Config.Env))
-	for i, v := range config.Config.Env {
-		parts := strings.SplitN(v, "=", 2)
-		envVars[i].Name = parts[0]
-		if len(parts) > 1 {
-			envVars[i].Value = parts[1]
-		}
+	envVars, secretData, err := nodeContainerEnvVars(config.Config.Env, dsName)
+	if err != nil {
+		return err
+	}
+	err = ensureNodeContainerSecret(client, dsName, ls, secretData)
+	if err != nil {
+		return err
 	}
 	var volumes []apiv1.Volume
 	var volumeMounts []apiv1.VolumeMount
@@ -143,6 +156,18 @@ func (m *nodeContainerManager) deployNodeContainerForCluster(client *ClusterClie
 			Privileged: &trueVar,
 		}
 	}
+	if len(config.HostConfig.CapAdd) > 0 || len(config.HostConfig.CapDrop) > 0 {
+		if secCtx == nil {
+			secCtx = &apiv1.SecurityContext{}
+		}
+		secCtx.Capabilities = &apiv1.Capabilities{}
+		for _, c := range config.HostConfig.CapAdd {
+			secCtx.Capabilities.Add = append(secCtx.Capabilities.Add, apiv1.Capability(c))
+		}
+		for _, c := range config.HostConfig.CapDrop {
+			secCtx.Capabilities.Drop = append(secCtx.Capabilities.Drop, apiv1.Capability(c))
+		}
+	}
 	restartPolicy := apiv1.RestartPolicyAlways
 	switch config.HostConfig.RestartPolicy.Name {
 	case docker.RestartOnFailure(0).Name:
@@ -219,6 +244,67 @@ func (m *nodeContainerManager) deployNodeContainerForCluster(client *ClusterClie
 	return errors.WithStack(err)
 }
 
+// nodeContainerEnvVars converts the node container envs to env vars, the
+// ones referencing secrets are resolved into the returned data and read from
+// the secret with the given name, so their values never show up in the
+// DaemonSet spec.
+func nodeContainerEnvVars(envs []string, secretName string) ([]apiv1.EnvVar, map[string][]byte, error) {
+	envVars := make([]apiv1.EnvVar, len(envs))
+	secretData := map[string][]byte{}
+	for i, v := range envs {
+		parts := strings.SplitN(v, "=", 2)
+		envVars[i].Name = parts[0]
+		if len(parts) < 2 {
+			continue
+		}
+		if !nodecontainer.IsSecretRef(parts[1]) {
+			envVars[i].Value = parts[1]
+			continue
+		}
+		value, err := nodecontainer.ResolveSecret(parts[1])
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to resolve env %q", parts[0])
+		}
+		secretData[parts[0]] = []byte(value)
+		envVars[i].ValueFrom = &apiv1.EnvVarSource{
+			SecretKeyRef: &apiv1.SecretKeySelector{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: secretName},
+				Key:                  parts[0],
+			},
+		}
+	}
+	return envVars, secretData, nil
+}
+
+// ensureNodeContainerSecret creates or updates the secret holding the
+// resolved secret envs of a node container, removing it when there are none.
+func ensureNodeContainerSecret(client *ClusterClient, name string, ls *provision.LabelSet, data map[string][]byte) error {
+	secrets := client.CoreV1().Secrets(client.Namespace())
+	if len(data) == 0 {
+		err := secrets.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+		return nil
+	}
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: client.Namespace(),
+			Labels:    ls.ToLabels(),
+		},
+		Type: apiv1.SecretTypeOpaque,
+		Data: data,
+	}
+	_, err := secrets.Get(name, metav1.GetOptions{})
+	if err == nil {
+		_, err = secrets.Update(secret)
+	} else if k8sErrors.IsNotFound(err) {
+		_, err = secrets.Create(secret)
+	}
+	return errors.WithStack(err)
+}
+
 func ensureNodeContainers() error {
 	m := nodeContainerManager{}
 	buf := &bytes.Buffer{}
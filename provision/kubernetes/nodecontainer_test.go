@@ -10,12 +10,14 @@ import (
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/kr/pretty"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/provision/cluster"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
 	"github.com/tsuru/tsuru/provision/servicecommon"
 	"gopkg.in/check.v1"
 	"k8s.io/api/apps/v1beta2"
 	apiv1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -291,6 +293,75 @@ func (s *S) TestManagerDeployNodeContainerBSSpecialMount(c *check.C) {
 	})
 }
 
+func (s *S) TestManagerDeployNodeContainerSecurityProfile(c *check.C) {
+	s.mock.MockfakeNodes(c)
+	privileged := false
+	c1 := nodecontainer.NodeContainerConfig{
+		Name:       nodecontainer.BsDefaultName,
+		Config:     docker.Config{Image: "img1"},
+		HostConfig: docker.HostConfig{Privileged: true},
+		Security: &nodecontainer.SecurityProfile{
+			Privileged:      &privileged,
+			CapAdd:          []string{"SYS_PTRACE"},
+			CapDrop:         []string{"ALL"},
+			SeccompProfile:  nodecontainer.SeccompUnconfined,
+			AppArmorProfile: "bs-profile",
+		},
+	}
+	err := nodecontainer.AddNewContainer("", &c1)
+	c.Assert(err, check.IsNil)
+	m := nodeContainerManager{}
+	err = m.DeployNodeContainer(&c1, "", servicecommon.PoolFilter{}, false)
+	c.Assert(err, check.IsNil)
+	daemon, err := s.client.AppsV1beta2().DaemonSets(s.client.Namespace()).Get("node-container-big-sibling-all", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(daemon.Spec.Template.Spec.Containers[0].SecurityContext, check.DeepEquals, &apiv1.SecurityContext{
+		Capabilities: &apiv1.Capabilities{
+			Add:  []apiv1.Capability{"SYS_PTRACE"},
+			Drop: []apiv1.Capability{"ALL"},
+		},
+	})
+	annotations := daemon.Spec.Template.ObjectMeta.Annotations
+	c.Assert(annotations["container.apparmor.security.beta.kubernetes.io/big-sibling"], check.Equals, "localhost/bs-profile")
+	c.Assert(annotations["container.seccomp.security.alpha.kubernetes.io/big-sibling"], check.Equals, "unconfined")
+}
+
+func (s *S) TestManagerDeployNodeContainerSecretEnvs(c *check.C) {
+	config.Set("docker:nodecontainer:secrets:key", "secret-store-key")
+	defer config.Unset("docker:nodecontainer:secrets:key")
+	s.mock.MockfakeNodes(c)
+	err := nodecontainer.SetSecret("bs-token", "abc123")
+	c.Assert(err, check.IsNil)
+	c1 := nodecontainer.NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Image: "img1", Env: []string{"A=1", "TOKEN=secret://tsuru/bs-token"}},
+	}
+	err = nodecontainer.AddNewContainer("", &c1)
+	c.Assert(err, check.IsNil)
+	m := nodeContainerManager{}
+	err = m.DeployNodeContainer(&c1, "", servicecommon.PoolFilter{}, false)
+	c.Assert(err, check.IsNil)
+	daemon, err := s.client.AppsV1beta2().DaemonSets(s.client.Namespace()).Get("node-container-bs-all", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(daemon.Spec.Template.Spec.Containers[0].Env, check.DeepEquals, []apiv1.EnvVar{
+		{Name: "A", Value: "1"},
+		{Name: "TOKEN", ValueFrom: &apiv1.EnvVarSource{
+			SecretKeyRef: &apiv1.SecretKeySelector{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: "node-container-bs-all"},
+				Key:                  "TOKEN",
+			},
+		}},
+	})
+	secret, err := s.client.CoreV1().Secrets(s.client.Namespace()).Get("node-container-bs-all", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(secret.Data, check.DeepEquals, map[string][]byte{"TOKEN": []byte("abc123")})
+	c1.Config.Env = []string{"A=1"}
+	err = m.DeployNodeContainer(&c1, "", servicecommon.PoolFilter{}, false)
+	c.Assert(err, check.IsNil)
+	_, err = s.client.CoreV1().Secrets(s.client.Namespace()).Get("node-container-bs-all", metav1.GetOptions{})
+	c.Assert(k8sErrors.IsNotFound(err), check.Equals, true)
+}
+
 func (s *S) TestManagerDeployNodeContainerBSMultiCluster(c *check.C) {
 	s.mock.MockfakeNodes(c)
 	cluster2 := &cluster.Cluster{
@@ -17,9 +17,11 @@ import (
 	"github.com/tsuru/tsuru/app/image"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
 	tsuruNet "github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/cluster"
+	"github.com/tsuru/tsuru/provision/nodehook"
 	"github.com/tsuru/tsuru/provision/servicecommon"
 	"github.com/tsuru/tsuru/set"
 	apiv1 "k8s.io/api/core/v1"
@@ -175,7 +177,7 @@ func changeState(a provision.App, process string, state servicecommon.ProcessSta
 	}
 	return servicecommon.ChangeAppState(&serviceManager{
 		client: client,
-		writer: w,
+		writer: tsuruIo.NewSyncWriter(w),
 	}, a, process, state)
 }
 
@@ -186,7 +188,7 @@ func changeUnits(a provision.App, units int, processName string, w io.Writer) er
 	}
 	return servicecommon.ChangeUnits(&serviceManager{
 		client: client,
-		writer: w,
+		writer: tsuruIo.NewSyncWriter(w),
 	}, a, units, processName)
 }
 
@@ -292,6 +294,7 @@ func (p *kubernetesProvisioner) podsToUnits(client *ClusterClient, pods []apiv1.
 			IP:          wrapper.ip(),
 			Status:      stateMap[pod.Status.Phase],
 			Address:     url,
+			CreatedAt:   pod.CreationTimestamp.Time,
 		}
 	}
 	return units, nil
@@ -514,16 +517,19 @@ func (p *kubernetesProvisioner) AddNode(opts provision.AddNodeOptions) error {
 	setNodeMetadata(node, opts.Pool, opts.IaaSID, opts.Metadata)
 	_, err = client.CoreV1().Nodes().Create(node)
 	if k8sErrors.IsAlreadyExists(err) {
-		return p.internalNodeUpdate(provision.UpdateNodeOptions{
+		err = p.internalNodeUpdate(provision.UpdateNodeOptions{
 			Address:  hostAddr,
 			Metadata: opts.Metadata,
 			Pool:     opts.Pool,
 		}, opts.IaaSID)
-	}
-	if err == nil {
+	} else if err == nil {
 		servicecommon.RebuildRoutesPoolApps(opts.Pool)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	nodehook.NotifyNodeAddress(nodehook.EventAdd, p, hostAddr)
+	return nil
 }
 
 func (p *kubernetesProvisioner) RemoveNode(opts provision.RemoveNodeOptions) error {
@@ -560,6 +566,7 @@ func (p *kubernetesProvisioner) RemoveNode(opts provision.RemoveNodeOptions) err
 		return errors.WithStack(err)
 	}
 	servicecommon.RebuildRoutesPoolApps(nodeWrapper.Pool())
+	nodehook.NotifyNode(nodehook.EventRemove, nodeWrapper)
 	return nil
 }
 
@@ -604,7 +611,12 @@ func (p *kubernetesProvisioner) findNodeByAddress(address string) (*ClusterClien
 }
 
 func (p *kubernetesProvisioner) UpdateNode(opts provision.UpdateNodeOptions) error {
-	return p.internalNodeUpdate(opts, "")
+	err := p.internalNodeUpdate(opts, "")
+	if err != nil {
+		return err
+	}
+	nodehook.NotifyNodeAddress(nodehook.UpdateEvent(opts), p, opts.Address)
+	return nil
 }
 
 func (p *kubernetesProvisioner) internalNodeUpdate(opts provision.UpdateNodeOptions, iaasID string) error {
@@ -678,8 +690,9 @@ func (p *kubernetesProvisioner) Deploy(a provision.App, buildImageID string, evt
 	}
 	manager := &serviceManager{
 		client: client,
-		writer: evt,
+		writer: tsuruIo.NewSyncWriter(evt),
 	}
+	provision.StartDeployPhase(evt, provision.DeployPhaseUnitStart)
 	err = servicecommon.RunServicePipeline(manager, a, newImage, nil)
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -84,6 +84,10 @@ func (s *LabelSet) ToNodeByPoolSelector() map[string]string {
 	return withPrefix(subMap(s.Labels, LabelNodePool), s.Prefix)
 }
 
+func (s *LabelSet) ToIsNodeContainerSelector() map[string]string {
+	return withPrefix(subMap(s.Labels, labelIsNodeContainer, labelProvisioner), s.Prefix)
+}
+
 func (s *LabelSet) ToIsServiceSelector() map[string]string {
 	return withPrefix(subMap(s.Labels, labelIsService), s.Prefix)
 }
@@ -196,6 +200,18 @@ func (s *LabelSet) IsIsolatedRun() bool {
 	return s.getBoolLabel(labelIsIsolatedRun)
 }
 
+func (s *LabelSet) IsNodeContainer() bool {
+	return s.getBoolLabel(labelIsNodeContainer)
+}
+
+func (s *LabelSet) NodeContainerName() string {
+	return s.getLabel(labelNodeContainerName)
+}
+
+func (s *LabelSet) Provisioner() string {
+	return s.getLabel(labelProvisioner)
+}
+
 func (s *LabelSet) SetRestarts(count int) {
 	s.addLabel(labelRestarts, strconv.Itoa(count))
 }
@@ -355,16 +371,16 @@ type NodeContainerLabelsOpts struct {
 }
 
 func NodeContainerLabels(opts NodeContainerLabelsOpts) *LabelSet {
-	labels := map[string]string{
-		labelIsTsuru:           strconv.FormatBool(true),
-		labelIsNodeContainer:   strconv.FormatBool(true),
-		labelProvisioner:       opts.Provisioner,
-		labelNodeContainerName: opts.Name,
-		labelNodeContainerPool: opts.Pool,
-	}
+	labels := map[string]string{}
 	for k, v := range opts.CustomLabels {
 		labels[k] = v
 	}
+	// Custom labels must not override the ones used to find node containers.
+	labels[labelIsTsuru] = strconv.FormatBool(true)
+	labels[labelIsNodeContainer] = strconv.FormatBool(true)
+	labels[labelProvisioner] = opts.Provisioner
+	labels[labelNodeContainerName] = opts.Name
+	labels[labelNodeContainerPool] = opts.Pool
 	return &LabelSet{Labels: labels, Prefix: opts.Prefix}
 }
 
@@ -132,6 +132,17 @@ func (s *S) TestNodeContainerLabels(c *check.C) {
 			"a": "1",
 		},
 	})
+	opts.CustomLabels = map[string]string{"a": "1", "node-container-name": "other", "is-tsuru": "false"}
+	c.Assert(provision.NodeContainerLabels(opts), check.DeepEquals, &provision.LabelSet{
+		Labels: map[string]string{
+			"is-tsuru":            "true",
+			"is-node-container":   "true",
+			"provisioner":         "provisioner",
+			"node-container-name": "name",
+			"node-container-pool": "pool",
+			"a":                   "1",
+		},
+	})
 }
 
 func (s *S) TestNodeLabels(c *check.C) {
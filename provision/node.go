@@ -8,6 +8,7 @@ import (
 	"sort"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/net"
 )
@@ -16,8 +17,20 @@ const (
 	PoolMetadataName   = "pool"
 	IaaSIDMetadataName = "iaas-id"
 	IaaSMetadataName   = "iaas"
+
+	defaultZoneMetadataName = "zone"
 )
 
+// ZoneMetadataName returns the name of the node metadata holding the
+// availability zone of the node.
+func ZoneMetadataName() string {
+	name, _ := config.GetString("zone-metadata")
+	if name == "" {
+		return defaultZoneMetadataName
+	}
+	return name
+}
+
 type MetaWithFrequency struct {
 	Metadata map[string]string
 	Nodes    []Node
@@ -90,6 +103,21 @@ func FindNode(address string) (Provisioner, Node, error) {
 	return FindNodeSkipProvisioner(address, "")
 }
 
+// RemoveNodeDry reports what removing the node would do. Its units are only
+// affected when the removal rebalances them to other nodes.
+func RemoveNodeDry(node Node, rebalance bool) (*DryRunReport, error) {
+	report := DryRunReport{Nodes: []string{node.Address()}}
+	if !rebalance {
+		return &report, nil
+	}
+	units, err := node.Units()
+	if err != nil {
+		return nil, err
+	}
+	report.Moved = units
+	return &report, nil
+}
+
 func metadataNoIaasID(n Node) map[string]string {
 	// iaas-id is ignored because it wasn't created in previous tsuru versions
 	// and having nodes with and without it would cause unbalanced metadata
@@ -6,6 +6,7 @@ package provision_test
 
 import (
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"gopkg.in/check.v1"
@@ -132,6 +133,13 @@ func (s *S) TestFindNodeIgnoreErrorIfFound(c *check.C) {
 	c.Assert(err, check.ErrorMatches, `(?s)get node error.*`)
 }
 
+func (s *S) TestZoneMetadataName(c *check.C) {
+	c.Assert(provision.ZoneMetadataName(), check.Equals, "zone")
+	config.Set("zone-metadata", "availability-zone")
+	defer config.Unset("zone-metadata")
+	c.Assert(provision.ZoneMetadataName(), check.Equals, "availability-zone")
+}
+
 func (s *S) TestSplitMetadata(c *check.C) {
 	var err error
 	makeNode := func(addr string, metadata map[string]string) provision.Node {
@@ -0,0 +1,173 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package nodeagent provides a client to the optional agent running in the
+// nodes, used for operations that can't be done through the docker API, like
+// reading kernel logs or restarting the docker daemon.
+package nodeagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const defaultScheme = "https"
+
+var ErrAgentDisabled = errors.New("node agent is not enabled")
+
+// AgentError is returned when the agent responds to a request with an error
+// status.
+type AgentError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *AgentError) Error() string {
+	return fmt.Sprintf("node agent error (%d): %s", e.StatusCode, e.Message)
+}
+
+// DiskHealth is the SMART status of a disk in the node.
+type DiskHealth struct {
+	Device  string `json:"device"`
+	Healthy bool   `json:"healthy"`
+	Status  string `json:"status"`
+}
+
+// Client talks to the agent running in a node.
+type Client struct {
+	endpoint string
+	token    string
+}
+
+// Enabled returns whether the node agent is configured.
+func Enabled() bool {
+	port, _ := config.GetInt("node-agent:port")
+	return port > 0
+}
+
+// HealingSteps returns the names of the steps run by the agent when a node
+// is about to be healed, in the order they should be attempted.
+func HealingSteps() []string {
+	steps, _ := config.GetList("node-agent:healing-steps")
+	return steps
+}
+
+// NewClient returns a client to the agent in the node with the given
+// address, or ErrAgentDisabled if the agent isn't configured.
+func NewClient(address string) (*Client, error) {
+	port, _ := config.GetInt("node-agent:port")
+	if port <= 0 {
+		return nil, ErrAgentDisabled
+	}
+	scheme, _ := config.GetString("node-agent:scheme")
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	token, _ := config.GetString("node-agent:token")
+	host := tsuruNet.URLToHost(address)
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return &Client{
+		endpoint: fmt.Sprintf("%s://%s:%d", scheme, host, port),
+		token:    token,
+	}, nil
+}
+
+func (c *Client) do(method, path string, params url.Values) (*http.Response, error) {
+	u := c.endpoint + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "bearer "+c.token)
+	}
+	rsp, err := tsuruNet.Dial5Full300Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to reach node agent at %s", c.endpoint)
+	}
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		defer rsp.Body.Close()
+		data, _ := ioutil.ReadAll(rsp.Body)
+		return nil, &AgentError{StatusCode: rsp.StatusCode, Message: strings.TrimSpace(string(data))}
+	}
+	return rsp, nil
+}
+
+// KernelLogs writes the last lines of the kernel log of the node to w. All
+// available lines are returned when lines is zero.
+func (c *Client) KernelLogs(w io.Writer, lines int) error {
+	params := url.Values{}
+	if lines > 0 {
+		params.Set("lines", strconv.Itoa(lines))
+	}
+	rsp, err := c.do(http.MethodGet, "/kernel-logs", params)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	_, err = io.Copy(w, rsp.Body)
+	return err
+}
+
+// Health checks whether the agent is up and considers the node healthy.
+func (c *Client) Health() error {
+	rsp, err := c.do(http.MethodGet, "/health", nil)
+	if err != nil {
+		return err
+	}
+	rsp.Body.Close()
+	return nil
+}
+
+// RestartDocker restarts the docker daemon in the node.
+func (c *Client) RestartDocker() error {
+	rsp, err := c.do(http.MethodPost, "/docker/restart", nil)
+	if err != nil {
+		return err
+	}
+	rsp.Body.Close()
+	return nil
+}
+
+// DiskHealth returns the SMART status of the disks in the node.
+func (c *Client) DiskHealth() ([]DiskHealth, error) {
+	rsp, err := c.do(http.MethodGet, "/disks", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	var disks []DiskHealth
+	err = json.NewDecoder(rsp.Body).Decode(&disks)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse node agent response")
+	}
+	return disks, nil
+}
+
+// RunHealingStep runs a healing step in the node, writing its output to w.
+// A nil error means the step fixed the node.
+func (c *Client) RunHealingStep(w io.Writer, step string) error {
+	rsp, err := c.do(http.MethodPost, "/healing/steps/"+url.PathEscape(step), nil)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	_, err = io.Copy(w, rsp.Body)
+	return err
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodeagent
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/tsuru/config"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	server   *httptest.Server
+	requests []*http.Request
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpTest(c *check.C) {
+	s.requests = nil
+	mux := http.NewServeMux()
+	mux.HandleFunc("/kernel-logs", func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+		fmt.Fprintf(w, "kernel: line %s\n", r.URL.Query().Get("lines"))
+	})
+	mux.HandleFunc("/docker/restart", func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+	})
+	mux.HandleFunc("/disks", func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+		w.Write([]byte(`[{"device":"/dev/sda","healthy":true,"status":"PASSED"}]`))
+	})
+	mux.HandleFunc("/healing/steps/", func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+		http.Error(w, "step failed", http.StatusInternalServerError)
+	})
+	s.server = httptest.NewServer(mux)
+	u, err := url.Parse(s.server.URL)
+	c.Assert(err, check.IsNil)
+	config.Set("node-agent:port", u.Port())
+	config.Set("node-agent:scheme", "http")
+	config.Set("node-agent:token", "secret")
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.server.Close()
+	config.Unset("node-agent")
+}
+
+func (s *S) TestNewClientDisabled(c *check.C) {
+	config.Unset("node-agent")
+	c.Assert(Enabled(), check.Equals, false)
+	_, err := NewClient("http://127.0.0.1:2375")
+	c.Assert(err, check.Equals, ErrAgentDisabled)
+}
+
+func (s *S) TestNewClientDefaultScheme(c *check.C) {
+	config.Unset("node-agent:scheme")
+	client, err := NewClient("http://127.0.0.1:2375")
+	c.Assert(err, check.IsNil)
+	c.Assert(client.endpoint, check.Matches, `https://127\.0\.0\.1:\d+`)
+}
+
+func (s *S) TestKernelLogs(c *check.C) {
+	c.Assert(Enabled(), check.Equals, true)
+	client, err := NewClient("http://127.0.0.1:2375")
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = client.KernelLogs(&buf, 10)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "kernel: line 10\n")
+	c.Assert(s.requests, check.HasLen, 1)
+	c.Assert(s.requests[0].Method, check.Equals, http.MethodGet)
+	c.Assert(s.requests[0].Header.Get("Authorization"), check.Equals, "bearer secret")
+}
+
+func (s *S) TestRestartDocker(c *check.C) {
+	client, err := NewClient("http://127.0.0.1:2375")
+	c.Assert(err, check.IsNil)
+	err = client.RestartDocker()
+	c.Assert(err, check.IsNil)
+	c.Assert(s.requests, check.HasLen, 1)
+	c.Assert(s.requests[0].Method, check.Equals, http.MethodPost)
+}
+
+func (s *S) TestHealth(c *check.C) {
+	client, err := NewClient("http://127.0.0.1:2375")
+	c.Assert(err, check.IsNil)
+	err = client.Health()
+	c.Assert(err, check.IsNil)
+	c.Assert(s.requests, check.HasLen, 1)
+	c.Assert(s.requests[0].URL.Path, check.Equals, "/health")
+}
+
+func (s *S) TestDiskHealth(c *check.C) {
+	client, err := NewClient("http://127.0.0.1:2375")
+	c.Assert(err, check.IsNil)
+	disks, err := client.DiskHealth()
+	c.Assert(err, check.IsNil)
+	c.Assert(disks, check.DeepEquals, []DiskHealth{
+		{Device: "/dev/sda", Healthy: true, Status: "PASSED"},
+	})
+}
+
+func (s *S) TestRunHealingStepError(c *check.C) {
+	client, err := NewClient("http://127.0.0.1:2375")
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = client.RunHealingStep(&buf, "restart-docker")
+	c.Assert(err, check.DeepEquals, &AgentError{StatusCode: http.StatusInternalServerError, Message: "step failed"})
+	c.Assert(s.requests, check.HasLen, 1)
+	c.Assert(s.requests[0].URL.Path, check.Equals, "/healing/steps/restart-docker")
+}
@@ -5,20 +5,99 @@
 package nodecontainer
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"strings"
+	"time"
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/cron"
 )
 
 const (
 	BsDefaultName      = "big-sibling"
 	bsDefaultImageName = "tsuru/bs:v1"
 	bsHostProc         = "/prochost"
+	bsTokenEnvPrefix   = "TSURU_TOKEN="
+
+	bsDefaultSyslogPort = 1514
+
+	// bsRestartGrace is how long after the restart window containers that
+	// missed their restart time are still restarted.
+	bsRestartGrace = 10 * time.Minute
+
+	BsSyslogProtocolUDP = "udp"
+	BsSyslogProtocolTCP = "tcp"
+	BsSyslogProtocolTLS = "tcp+tls"
 )
 
+var (
+	ErrBSTokenNotFound = errors.New("big-sibling token not found")
+	ErrBSTokenChanged  = errors.New("big-sibling token changed while rotating it")
+)
+
+// BsSyslogConfig describes the syslog listener of the big-sibling node
+// container, which receives the logs of the app containers.
+type BsSyslogConfig struct {
+	Protocol string
+	Port     int
+	// CertFile and KeyFile are the paths, in the nodes, of the certificate
+	// and key used by the listener when Protocol is BsSyslogProtocolTLS.
+	CertFile string
+	KeyFile  string
+	// CAFile is the path, in the nodes, of the CA certificate used by the
+	// docker daemon to verify the listener certificate. The system CAs are
+	// used when it's empty.
+	CAFile string
+}
+
+// BsSyslog returns the syslog listener config of big-sibling, from the
+// docker:bs:syslog-* settings.
+func BsSyslog() (BsSyslogConfig, error) {
+	conf := BsSyslogConfig{Protocol: BsSyslogProtocolUDP, Port: bsDefaultSyslogPort}
+	if port, _ := config.GetInt("docker:bs:syslog-port"); port != 0 {
+		conf.Port = port
+	}
+	if protocol, _ := config.GetString("docker:bs:syslog-protocol"); protocol != "" {
+		conf.Protocol = protocol
+	}
+	switch conf.Protocol {
+	case BsSyslogProtocolUDP, BsSyslogProtocolTCP:
+	case BsSyslogProtocolTLS:
+		conf.CertFile, _ = config.GetString("docker:bs:syslog-tls:cert-file")
+		conf.KeyFile, _ = config.GetString("docker:bs:syslog-tls:key-file")
+		conf.CAFile, _ = config.GetString("docker:bs:syslog-tls:ca-file")
+		if conf.CertFile == "" || conf.KeyFile == "" {
+			return conf, errors.New("docker:bs:syslog-tls:cert-file and docker:bs:syslog-tls:key-file must be set when docker:bs:syslog-protocol is tcp+tls")
+		}
+	default:
+		return conf, fmt.Errorf("invalid docker:bs:syslog-protocol %q, valid values are: %s, %s, %s",
+			conf.Protocol, BsSyslogProtocolUDP, BsSyslogProtocolTCP, BsSyslogProtocolTLS)
+	}
+	return conf, nil
+}
+
+// ListenAddress returns the address big-sibling listens on, set in its
+// SYSLOG_LISTEN_ADDRESS env.
+func (c BsSyslogConfig) ListenAddress() string {
+	return fmt.Sprintf("%s://0.0.0.0:%d", c.Protocol, c.Port)
+}
+
+// LogOpts returns the options of the docker syslog log driver sending the
+// logs of app containers to big-sibling.
+func (c BsSyslogConfig) LogOpts() map[string]string {
+	opts := map[string]string{
+		"syslog-address": fmt.Sprintf("%s://localhost:%d", c.Protocol, c.Port),
+	}
+	if c.Protocol == BsSyslogProtocolTLS && c.CAFile != "" {
+		opts["syslog-tls-ca-cert"] = c.CAFile
+	}
+	return opts
+}
+
 func InitializeBS(authScheme auth.Scheme, appUser string) (bool, error) {
 	bsNodeContainer, err := LoadNodeContainer("", BsDefaultName)
 	if err != nil {
@@ -34,7 +113,7 @@ func InitializeBS(authScheme auth.Scheme, appUser string) (bool, error) {
 	token := tokenData.GetValue()
 	conf := configFor(BsDefaultName)
 	isSet, _ := conf.SetFieldAtomic("", "Config.Env", []string{
-		"TSURU_TOKEN=" + token,
+		bsTokenEnvPrefix + token,
 	})
 	if !isSet {
 		// Already set by someone else, just bail out.
@@ -55,24 +134,120 @@ func InitializeBS(authScheme auth.Scheme, appUser string) (bool, error) {
 	if image == "" {
 		image = bsDefaultImageName
 	}
-	bsPort, _ := config.GetInt("docker:bs:syslog-port")
-	if bsPort == 0 {
-		bsPort = 1514
+	syslog, err := BsSyslog()
+	if err != nil {
+		return true, err
 	}
 	bsNodeContainer.Name = BsDefaultName
 	bsNodeContainer.Config.Env = append(bsNodeContainer.Config.Env, []string{
 		"TSURU_ENDPOINT=" + tsuruEndpoint,
 		"HOST_PROC=" + bsHostProc,
-		"SYSLOG_LISTEN_ADDRESS=" + fmt.Sprintf("udp://0.0.0.0:%d", bsPort),
+		"SYSLOG_LISTEN_ADDRESS=" + syslog.ListenAddress(),
 	}...)
 	bsNodeContainer.Config.Image = image
 	bsNodeContainer.HostConfig.RestartPolicy = docker.AlwaysRestart()
 	bsNodeContainer.HostConfig.Privileged = true
 	bsNodeContainer.HostConfig.NetworkMode = "host"
 	bsNodeContainer.HostConfig.Binds = []string{fmt.Sprintf("/proc:%s:ro", bsHostProc)}
+	if syslog.Protocol == BsSyslogProtocolTLS {
+		bsNodeContainer.Config.Env = append(bsNodeContainer.Config.Env,
+			"SYSLOG_TLS_CERT_FILE="+syslog.CertFile,
+			"SYSLOG_TLS_KEY_FILE="+syslog.KeyFile,
+		)
+		bsNodeContainer.HostConfig.Binds = append(bsNodeContainer.HostConfig.Binds,
+			fmt.Sprintf("%s:%s:ro", syslog.CertFile, syslog.CertFile),
+			fmt.Sprintf("%s:%s:ro", syslog.KeyFile, syslog.KeyFile),
+		)
+	}
 	if socket != "" {
 		bsNodeContainer.Config.Env = append(bsNodeContainer.Config.Env, "DOCKER_ENDPOINT=unix:///var/run/docker.sock")
 		bsNodeContainer.HostConfig.Binds = append(bsNodeContainer.HostConfig.Binds, fmt.Sprintf("%s:/var/run/docker.sock:rw", socket))
 	}
+	// Nodes with broken resolvers may still reach the tsuru API and the log
+	// and metrics backends through static hosts or other nameservers.
+	bsNodeContainer.HostConfig.ExtraHosts, _ = config.GetList("docker:bs:extra-hosts")
+	bsNodeContainer.HostConfig.DNS, _ = config.GetList("docker:bs:dns")
+	bsNodeContainer.HostConfig.DNSSearch, _ = config.GetList("docker:bs:dns-search")
+	err = validateNetwork(bsNodeContainer.HostConfig)
+	if err != nil {
+		return true, err
+	}
 	return true, conf.Save("", bsNodeContainer)
 }
+
+// AutoUpgradeEnabled returns whether the containers of the node container
+// with the given name must be recreated as soon as its config is changed,
+// which is only supported for big-sibling, through docker:bs:auto-upgrade.
+func AutoUpgradeEnabled(name string) bool {
+	if name != BsDefaultName {
+		return false
+	}
+	enabled, _ := config.GetBool("docker:bs:auto-upgrade")
+	return enabled
+}
+
+// RestartTime returns the time when the container in the node with the
+// given address is due to be restarted, in the latest occurrence of the
+// restart schedule up to now. Each node gets a fixed offset in the restart
+// window, so nodes aren't restarted at once. The returned bool is false when
+// there's no restart due, either because the schedule isn't set, the node
+// time hasn't come yet or the window of the occurrence is already over.
+func (c *NodeContainerConfig) RestartTime(address string, now time.Time) (time.Time, bool) {
+	if c.RestartSchedule == "" {
+		return time.Time{}, false
+	}
+	sched, err := cron.Parse(c.RestartSchedule)
+	if err != nil {
+		return time.Time{}, false
+	}
+	window := time.Duration(c.RestartWindow) * time.Second
+	occurrence := sched.Prev(now, now.Add(-window-bsRestartGrace))
+	if occurrence.IsZero() {
+		return time.Time{}, false
+	}
+	restartAt := occurrence
+	if window > 0 {
+		h := fnv.New32a()
+		h.Write([]byte(address))
+		restartAt = restartAt.Add(time.Duration(h.Sum32()%uint32(c.RestartWindow)) * time.Second)
+	}
+	if now.Before(restartAt) {
+		return time.Time{}, false
+	}
+	return restartAt, true
+}
+
+// RotateBSToken mints a new app token for the big-sibling node container and
+// swaps it with the current one in its base config. It returns the previous
+// token, which must only be revoked once the containers using it are
+// recreated.
+func RotateBSToken(authScheme auth.Scheme, appUser string) (string, error) {
+	bsNodeContainer, err := LoadNodeContainer("", BsDefaultName)
+	if err != nil {
+		return "", err
+	}
+	var oldEnv string
+	for _, env := range bsNodeContainer.Config.Env {
+		if strings.HasPrefix(env, bsTokenEnvPrefix) {
+			oldEnv = env
+			break
+		}
+	}
+	if oldEnv == "" {
+		return "", ErrBSTokenNotFound
+	}
+	tokenData, err := authScheme.AppLogin(appUser)
+	if err != nil {
+		return "", err
+	}
+	token := tokenData.GetValue()
+	replaced, err := configFor(BsDefaultName).ReplaceFieldItem("", "Config.Env", oldEnv, bsTokenEnvPrefix+token)
+	if err == nil && !replaced {
+		err = ErrBSTokenChanged
+	}
+	if err != nil {
+		authScheme.AppLogout(token)
+		return "", err
+	}
+	return strings.TrimPrefix(oldEnv, bsTokenEnvPrefix), nil
+}
@@ -6,7 +6,9 @@ package nodecontainer
 
 import (
 	"runtime"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/tsuru/config"
@@ -50,6 +52,32 @@ func (s *S) TestInitializeBS(c *check.C) {
 	c.Assert(initialized, check.Equals, false)
 }
 
+func (s *S) TestInitializeBSNetworkConfig(c *check.C) {
+	config.Set("docker:bs:extra-hosts", []interface{}{"tsuru.local:10.0.0.1", "logs.local:10.0.0.2"})
+	config.Set("docker:bs:dns", []interface{}{"8.8.8.8"})
+	config.Set("docker:bs:dns-search", []interface{}{"tsuru.local"})
+	defer config.Unset("docker:bs:extra-hosts")
+	defer config.Unset("docker:bs:dns")
+	defer config.Unset("docker:bs:dns-search")
+	nativeScheme := auth.ManagedScheme(native.NativeScheme{})
+	initialized, err := InitializeBS(nativeScheme, "tsr")
+	c.Assert(err, check.IsNil)
+	c.Assert(initialized, check.Equals, true)
+	nodeContainer, err := LoadNodeContainer("", BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodeContainer.HostConfig.ExtraHosts, check.DeepEquals, []string{"tsuru.local:10.0.0.1", "logs.local:10.0.0.2"})
+	c.Assert(nodeContainer.HostConfig.DNS, check.DeepEquals, []string{"8.8.8.8"})
+	c.Assert(nodeContainer.HostConfig.DNSSearch, check.DeepEquals, []string{"tsuru.local"})
+}
+
+func (s *S) TestInitializeBSInvalidDNS(c *check.C) {
+	config.Set("docker:bs:dns", []interface{}{"ns.tsuru.local"})
+	defer config.Unset("docker:bs:dns")
+	nativeScheme := auth.ManagedScheme(native.NativeScheme{})
+	_, err := InitializeBS(nativeScheme, "tsr")
+	c.Assert(err, check.ErrorMatches, `invalid node container dns "ns.tsuru.local", must be an IP address`)
+}
+
 func (s *S) TestInitializeBSStress(c *check.C) {
 	originalMaxProcs := runtime.GOMAXPROCS(10)
 	defer runtime.GOMAXPROCS(originalMaxProcs)
@@ -77,3 +105,123 @@ func (s *S) TestInitializeBSStress(c *check.C) {
 	}
 	c.Assert(initOk, check.Equals, true)
 }
+
+func (s *S) TestRotateBSToken(c *check.C) {
+	nativeScheme := auth.ManagedScheme(native.NativeScheme{})
+	_, err := InitializeBS(nativeScheme, "tsr")
+	c.Assert(err, check.IsNil)
+	nodeContainer, err := LoadNodeContainer("", BsDefaultName)
+	c.Assert(err, check.IsNil)
+	initialEnv := nodeContainer.Config.Env
+	oldToken, err := RotateBSToken(nativeScheme, "tsr")
+	c.Assert(err, check.IsNil)
+	c.Assert("TSURU_TOKEN="+oldToken, check.Equals, initialEnv[0])
+	nodeContainer, err = LoadNodeContainer("", BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodeContainer.Config.Env, check.HasLen, len(initialEnv))
+	c.Assert(nodeContainer.Config.Env[0], check.Matches, `^TSURU_TOKEN=.{40}$`)
+	c.Assert(nodeContainer.Config.Env[0], check.Not(check.Equals), initialEnv[0])
+	c.Assert(nodeContainer.Config.Env[1:], check.DeepEquals, initialEnv[1:])
+	_, err = nativeScheme.Auth(oldToken)
+	c.Assert(err, check.IsNil)
+	_, err = nativeScheme.Auth(strings.TrimPrefix(nodeContainer.Config.Env[0], "TSURU_TOKEN="))
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestRotateBSTokenNotInitialized(c *check.C) {
+	nativeScheme := auth.ManagedScheme(native.NativeScheme{})
+	_, err := RotateBSToken(nativeScheme, "tsr")
+	c.Assert(err, check.Equals, ErrNodeContainerNotFound)
+}
+
+func (s *S) TestBsSyslog(c *check.C) {
+	defer config.Unset("docker:bs")
+	syslog, err := BsSyslog()
+	c.Assert(err, check.IsNil)
+	c.Assert(syslog, check.DeepEquals, BsSyslogConfig{Protocol: "udp", Port: 1514})
+	c.Assert(syslog.ListenAddress(), check.Equals, "udp://0.0.0.0:1514")
+	c.Assert(syslog.LogOpts(), check.DeepEquals, map[string]string{"syslog-address": "udp://localhost:1514"})
+	config.Set("docker:bs:syslog-protocol", "tcp")
+	config.Set("docker:bs:syslog-port", 1515)
+	syslog, err = BsSyslog()
+	c.Assert(err, check.IsNil)
+	c.Assert(syslog.ListenAddress(), check.Equals, "tcp://0.0.0.0:1515")
+	c.Assert(syslog.LogOpts(), check.DeepEquals, map[string]string{"syslog-address": "tcp://localhost:1515"})
+	config.Set("docker:bs:syslog-protocol", "tcp+tls")
+	_, err = BsSyslog()
+	c.Assert(err, check.ErrorMatches, `docker:bs:syslog-tls:cert-file and docker:bs:syslog-tls:key-file must be set .*`)
+	config.Set("docker:bs:syslog-tls:cert-file", "/etc/bs/cert.pem")
+	config.Set("docker:bs:syslog-tls:key-file", "/etc/bs/key.pem")
+	syslog, err = BsSyslog()
+	c.Assert(err, check.IsNil)
+	c.Assert(syslog.ListenAddress(), check.Equals, "tcp+tls://0.0.0.0:1515")
+	c.Assert(syslog.LogOpts(), check.DeepEquals, map[string]string{"syslog-address": "tcp+tls://localhost:1515"})
+	config.Set("docker:bs:syslog-protocol", "http")
+	_, err = BsSyslog()
+	c.Assert(err, check.ErrorMatches, `invalid docker:bs:syslog-protocol "http", valid values are: udp, tcp, tcp\+tls`)
+}
+
+func (s *S) TestInitializeBSSyslogTLS(c *check.C) {
+	config.Set("host", "127.0.0.1:8080")
+	config.Set("docker:bs:syslog-protocol", "tcp+tls")
+	config.Set("docker:bs:syslog-tls:cert-file", "/etc/bs/cert.pem")
+	config.Set("docker:bs:syslog-tls:key-file", "/etc/bs/key.pem")
+	defer config.Unset("host")
+	defer config.Unset("docker:bs")
+	nativeScheme := auth.ManagedScheme(native.NativeScheme{})
+	initialized, err := InitializeBS(nativeScheme, "tsr")
+	c.Assert(err, check.IsNil)
+	c.Assert(initialized, check.Equals, true)
+	nodeContainer, err := LoadNodeContainer("", BsDefaultName)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodeContainer.Config.Env[1:], check.DeepEquals, []string{
+		"TSURU_ENDPOINT=http://127.0.0.1:8080/",
+		"HOST_PROC=/prochost",
+		"SYSLOG_LISTEN_ADDRESS=tcp+tls://0.0.0.0:1514",
+		"SYSLOG_TLS_CERT_FILE=/etc/bs/cert.pem",
+		"SYSLOG_TLS_KEY_FILE=/etc/bs/key.pem",
+	})
+	c.Assert(nodeContainer.HostConfig.Binds, check.DeepEquals, []string{
+		"/proc:/prochost:ro",
+		"/etc/bs/cert.pem:/etc/bs/cert.pem:ro",
+		"/etc/bs/key.pem:/etc/bs/key.pem:ro",
+	})
+}
+
+func (s *S) TestNodeContainerRestartTime(c *check.C) {
+	conf := NodeContainerConfig{Name: BsDefaultName, RestartSchedule: "0 4 * * *", RestartWindow: 3600}
+	occurrence := time.Date(2018, 5, 10, 4, 0, 0, 0, time.UTC)
+	restartAt, due := conf.RestartTime("http://n1:2375", occurrence.Add(time.Hour))
+	c.Assert(due, check.Equals, true)
+	c.Assert(restartAt.Before(occurrence), check.Equals, false)
+	c.Assert(restartAt.Before(occurrence.Add(time.Hour)), check.Equals, true)
+	again, due := conf.RestartTime("http://n1:2375", restartAt)
+	c.Assert(due, check.Equals, true)
+	c.Assert(again, check.DeepEquals, restartAt)
+	_, due = conf.RestartTime("http://n1:2375", restartAt.Add(-time.Second))
+	c.Assert(due, check.Equals, false)
+	_, due = conf.RestartTime("http://n1:2375", occurrence.Add(time.Hour+9*time.Minute))
+	c.Assert(due, check.Equals, true)
+	_, due = conf.RestartTime("http://n1:2375", occurrence.Add(time.Hour+11*time.Minute))
+	c.Assert(due, check.Equals, false)
+	offsets := map[time.Time]struct{}{}
+	for _, addr := range []string{"http://n1:2375", "http://n2:2375", "http://n3:2375", "http://n4:2375"} {
+		t, due := conf.RestartTime(addr, occurrence.Add(time.Hour))
+		c.Assert(due, check.Equals, true)
+		offsets[t] = struct{}{}
+	}
+	c.Assert(len(offsets) > 1, check.Equals, true)
+}
+
+func (s *S) TestNodeContainerRestartTimeNoWindow(c *check.C) {
+	conf := NodeContainerConfig{Name: BsDefaultName, RestartSchedule: "0 4 * * *"}
+	occurrence := time.Date(2018, 5, 10, 4, 0, 0, 0, time.UTC)
+	restartAt, due := conf.RestartTime("http://n1:2375", occurrence.Add(time.Minute))
+	c.Assert(due, check.Equals, true)
+	c.Assert(restartAt, check.DeepEquals, occurrence)
+	_, due = conf.RestartTime("http://n1:2375", occurrence.Add(-time.Minute))
+	c.Assert(due, check.Equals, false)
+	conf.RestartSchedule = ""
+	_, due = conf.RestartTime("http://n1:2375", occurrence.Add(time.Minute))
+	c.Assert(due, check.Equals, false)
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const (
+	// InternalServicePrefix prefixes the names of the node containers
+	// running services used by tsuru itself.
+	InternalServicePrefix = "tsuru-internal-"
+
+	internalServicesDataDir = "/var/lib/tsuru/internal"
+	internalServicesUser    = "tsuru"
+)
+
+// InternalService describes a dependency of tsuru that may be hosted by tsuru
+// itself, as a node container in a designated pool. Services only listen in
+// the loopback interface of the node, unless a password is set for them.
+type InternalService struct {
+	Name         string
+	DefaultImage string
+	Port         int
+	DataPath     string
+	cmd          func(password string) []string
+	authEnvs     func(password string) []string
+}
+
+// ContainerName returns the name of the node container running the service.
+func (s InternalService) ContainerName() string {
+	return InternalServicePrefix + s.Name
+}
+
+var internalServices = map[string]InternalService{
+	"redis": {
+		Name:         "redis",
+		DefaultImage: "redis:3.2-alpine",
+		Port:         6379,
+		DataPath:     "/data",
+		cmd: func(password string) []string {
+			if password == "" {
+				return []string{"redis-server", "--appendonly", "yes", "--bind", "127.0.0.1"}
+			}
+			return []string{"redis-server", "--appendonly", "yes", "--requirepass", password}
+		},
+	},
+	"queue": {
+		Name:         "queue",
+		DefaultImage: "mongo:3.4",
+		Port:         27017,
+		DataPath:     "/data/db",
+		cmd: func(password string) []string {
+			if password == "" {
+				return []string{"mongod", "--bind_ip", "127.0.0.1"}
+			}
+			return []string{"mongod", "--auth", "--bind_ip", "0.0.0.0"}
+		},
+		authEnvs: func(password string) []string {
+			return []string{
+				"MONGO_INITDB_ROOT_USERNAME=" + internalServicesUser,
+				"MONGO_INITDB_ROOT_PASSWORD=" + password,
+			}
+		},
+	},
+}
+
+// EnabledInternalServices returns the internal services set in the
+// internal-services config, sorted by name.
+func EnabledInternalServices() []InternalService {
+	var result []InternalService
+	for name, svc := range internalServices {
+		if _, err := config.Get("internal-services:" + name); err == nil {
+			result = append(result, svc)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// InitializeInternalServices creates the node containers for the enabled
+// internal services, enabled only in the pool set in internal-services:pool.
+// Services already initialized are kept untouched, so they're managed like
+// any other node container afterwards. It returns the names of the node
+// containers created.
+func InitializeInternalServices() ([]string, error) {
+	services := EnabledInternalServices()
+	if len(services) == 0 {
+		return nil, nil
+	}
+	pool, _ := config.GetString("internal-services:pool")
+	if pool == "" {
+		return nil, errors.New("internal-services:pool must be set to host internal services")
+	}
+	var created []string
+	for _, svc := range services {
+		isSet, err := initializeInternalService(pool, svc)
+		if err != nil {
+			return created, errors.Wrapf(err, "unable to initialize internal service %q", svc.Name)
+		}
+		if isSet {
+			created = append(created, svc.ContainerName())
+		}
+	}
+	return created, nil
+}
+
+func initializeInternalService(pool string, svc InternalService) (bool, error) {
+	name := svc.ContainerName()
+	existing, err := LoadNodeContainer("", name)
+	if err != nil {
+		return false, err
+	}
+	if existing.Config.Image != "" {
+		return false, nil
+	}
+	conf := configFor(name)
+	image, _ := config.GetString(fmt.Sprintf("internal-services:%s:image", svc.Name))
+	if image == "" {
+		image = svc.DefaultImage
+	}
+	envs, _ := config.GetList(fmt.Sprintf("internal-services:%s:env", svc.Name))
+	password, _ := config.GetString(fmt.Sprintf("internal-services:%s:password", svc.Name))
+	if password != "" && svc.authEnvs != nil {
+		envs = append(envs, svc.authEnvs(password)...)
+	}
+	disabled, enabled := true, false
+	base := NodeContainerConfig{
+		Name:     name,
+		Disabled: &disabled,
+		Config: docker.Config{
+			Image: image,
+			Env:   envs,
+			Cmd:   svc.cmd(password),
+		},
+		HostConfig: docker.HostConfig{
+			RestartPolicy: docker.AlwaysRestart(),
+			NetworkMode:   "host",
+			Binds:         []string{fmt.Sprintf("%s/%s:%s:rw", internalServicesDataDir, svc.Name, svc.DataPath)},
+		},
+	}
+	// The base config is only saved, already disabled, if no one else set
+	// the image in the meantime, so the service never runs outside the pool.
+	isSet, err := conf.SaveAtomic("", "Config.Image", base)
+	if err != nil || !isSet {
+		return false, err
+	}
+	return true, conf.Save(pool, NodeContainerConfig{Name: name, Disabled: &enabled})
+}
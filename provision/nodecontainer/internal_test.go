@@ -0,0 +1,90 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestInitializeInternalServices(c *check.C) {
+	config.Set("internal-services:pool", "infra")
+	config.Set("internal-services:redis:image", "redis:4")
+	config.Set("internal-services:redis:env", []interface{}{"A=1"})
+	config.Set("internal-services:queue", map[interface{}]interface{}{})
+	defer config.Unset("internal-services")
+	created, err := InitializeInternalServices()
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.DeepEquals, []string{"tsuru-internal-queue", "tsuru-internal-redis"})
+	base, err := LoadNodeContainer("", "tsuru-internal-redis")
+	c.Assert(err, check.IsNil)
+	c.Assert(base.Valid(), check.Equals, false)
+	c.Assert(base.Config, check.DeepEquals, docker.Config{
+		Image: "redis:4",
+		Env:   []string{"A=1"},
+		Cmd:   []string{"redis-server", "--appendonly", "yes", "--bind", "127.0.0.1"},
+	})
+	c.Assert(base.HostConfig, check.DeepEquals, docker.HostConfig{
+		RestartPolicy: docker.AlwaysRestart(),
+		NetworkMode:   "host",
+		Binds:         []string{"/var/lib/tsuru/internal/redis:/data:rw"},
+	})
+	infra, err := LoadNodeContainer("infra", "tsuru-internal-queue")
+	c.Assert(err, check.IsNil)
+	c.Assert(infra.Valid(), check.Equals, true)
+	c.Assert(infra.Config.Image, check.Equals, "mongo:3.4")
+	c.Assert(infra.Config.Cmd, check.DeepEquals, []string{"mongod", "--bind_ip", "127.0.0.1"})
+	other, err := LoadNodeContainer("other", "tsuru-internal-queue")
+	c.Assert(err, check.IsNil)
+	c.Assert(other.Valid(), check.Equals, false)
+	created, err = InitializeInternalServices()
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.IsNil)
+}
+
+func (s *S) TestInitializeInternalServicesPassword(c *check.C) {
+	config.Set("internal-services:pool", "infra")
+	config.Set("internal-services:redis:password", "redispw")
+	config.Set("internal-services:queue:password", "mongopw")
+	defer config.Unset("internal-services")
+	_, err := InitializeInternalServices()
+	c.Assert(err, check.IsNil)
+	redis, err := LoadNodeContainer("", "tsuru-internal-redis")
+	c.Assert(err, check.IsNil)
+	c.Assert(redis.Config.Cmd, check.DeepEquals, []string{"redis-server", "--appendonly", "yes", "--requirepass", "redispw"})
+	c.Assert(redis.Config.Env, check.IsNil)
+	queue, err := LoadNodeContainer("", "tsuru-internal-queue")
+	c.Assert(err, check.IsNil)
+	c.Assert(queue.Config.Cmd, check.DeepEquals, []string{"mongod", "--auth", "--bind_ip", "0.0.0.0"})
+	c.Assert(queue.Config.Env, check.DeepEquals, []string{"MONGO_INITDB_ROOT_USERNAME=tsuru", "MONGO_INITDB_ROOT_PASSWORD=mongopw"})
+}
+
+func (s *S) TestInitializeInternalServiceAlreadySet(c *check.C) {
+	err := configFor("tsuru-internal-redis").SetField("", "Config.Image", "other")
+	c.Assert(err, check.IsNil)
+	isSet, err := initializeInternalService("infra", internalServices["redis"])
+	c.Assert(err, check.IsNil)
+	c.Assert(isSet, check.Equals, false)
+	base, err := LoadNodeContainer("", "tsuru-internal-redis")
+	c.Assert(err, check.IsNil)
+	c.Assert(base.Config.Image, check.Equals, "other")
+	hasEntry, err := configFor("tsuru-internal-redis").HasEntry("infra")
+	c.Assert(err, check.IsNil)
+	c.Assert(hasEntry, check.Equals, false)
+}
+
+func (s *S) TestInitializeInternalServicesNoPool(c *check.C) {
+	config.Set("internal-services:redis:image", "redis:4")
+	defer config.Unset("internal-services")
+	_, err := InitializeInternalServices()
+	c.Assert(err, check.ErrorMatches, `internal-services:pool must be set to host internal services`)
+}
+
+func (s *S) TestInitializeInternalServicesNoneEnabled(c *check.C) {
+	created, err := InitializeInternalServices()
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.IsNil)
+}
@@ -6,16 +6,23 @@ package nodecontainer
 
 import (
 	"fmt"
+	"net"
+	"sort"
 	"strings"
 
 	"github.com/fsouza/go-dockerclient"
 	"github.com/globalsign/mgo"
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/scopedconfig"
 )
 
 const (
 	nodeContainerCollection = "nodeContainer"
+
+	PinImageAlways       = "always"
+	PinImageNever        = "never"
+	PinImageUntaggedOnly = "untagged-only"
 )
 
 var (
@@ -29,6 +36,21 @@ type NodeContainerConfig struct {
 	Disabled    *bool
 	Config      docker.Config
 	HostConfig  docker.HostConfig
+	NodeEnvs    []NodeEnv
+	// RestartSchedule is a cron expression with the times when the
+	// containers are restarted, only supported for big-sibling. Restarts
+	// are spread across the nodes during RestartWindow seconds after each
+	// scheduled time.
+	RestartSchedule string
+	RestartWindow   int
+	Security        *SecurityProfile
+}
+
+// NodeEnv holds env vars that override the container env in the node with
+// the given address.
+type NodeEnv struct {
+	Address string
+	Env     []string
 }
 
 type NodeContainerConfigGroup struct {
@@ -61,6 +83,103 @@ func (c *NodeContainerConfig) validate(pool string) error {
 	if c.Config.Image == "" && (pool == "" || base.Config.Image == "") {
 		return ValidationErr{message: "node container config image cannot be empty"}
 	}
+	if errs := validateFields(c); len(errs) > 0 {
+		return ValidationErr{message: errs[0].Message}
+	}
+	if err := validateResources(c.HostConfig); err != nil {
+		return err
+	}
+	return validateNetwork(c.HostConfig)
+}
+
+func validateResources(hostConfig docker.HostConfig) error {
+	if hostConfig.Memory < 0 {
+		return ValidationErr{message: "node container memory limit cannot be negative"}
+	}
+	if hostConfig.MemorySwap < -1 {
+		return ValidationErr{message: "node container memory swap limit must be -1 or positive"}
+	}
+	if hostConfig.MemorySwap > 0 && hostConfig.MemorySwap < hostConfig.Memory {
+		return ValidationErr{message: "node container memory swap limit cannot be lower than the memory limit"}
+	}
+	if hostConfig.CPUShares < 0 || hostConfig.CPUQuota < 0 || hostConfig.CPUPeriod < 0 {
+		return ValidationErr{message: "node container cpu limits cannot be negative"}
+	}
+	return nil
+}
+
+// mergeResources returns the memory and cpu limits of the pool host config
+// merged over the base one, the same way their configs are merged.
+func mergeResources(base, pool docker.HostConfig) docker.HostConfig {
+	merged := docker.HostConfig{
+		Memory:     base.Memory,
+		MemorySwap: base.MemorySwap,
+		CPUShares:  base.CPUShares,
+		CPUQuota:   base.CPUQuota,
+		CPUPeriod:  base.CPUPeriod,
+	}
+	if pool.Memory != 0 {
+		merged.Memory = pool.Memory
+	}
+	if pool.MemorySwap != 0 {
+		merged.MemorySwap = pool.MemorySwap
+	}
+	if pool.CPUShares != 0 {
+		merged.CPUShares = pool.CPUShares
+	}
+	if pool.CPUQuota != 0 {
+		merged.CPUQuota = pool.CPUQuota
+	}
+	if pool.CPUPeriod != 0 {
+		merged.CPUPeriod = pool.CPUPeriod
+	}
+	return merged
+}
+
+// validateMergedResources validates the limits of the configs used by the
+// containers once c is saved in the given pool, as each pool config is
+// merged over the base one. With merge set, c is merged over the config
+// already stored in the pool.
+func validateMergedResources(pool string, c *NodeContainerConfig, merge bool) error {
+	entries, err := LoadNodeContainersForPoolsMerge(c.Name, false)
+	if err != nil {
+		return err
+	}
+	hostConfig := c.HostConfig
+	if merge {
+		hostConfig = mergeResources(entries[pool].HostConfig, c.HostConfig)
+	}
+	if pool != "" {
+		return validateResources(mergeResources(entries[""].HostConfig, hostConfig))
+	}
+	pools := make([]string, 0, len(entries))
+	for p := range entries {
+		if p != "" {
+			pools = append(pools, p)
+		}
+	}
+	sort.Strings(pools)
+	for _, p := range pools {
+		err = validateResources(mergeResources(hostConfig, entries[p].HostConfig))
+		if err != nil {
+			return ValidationErr{message: fmt.Sprintf("%v in pool %q", err, p)}
+		}
+	}
+	return nil
+}
+
+func validateNetwork(hostConfig docker.HostConfig) error {
+	for _, host := range hostConfig.ExtraHosts {
+		parts := strings.SplitN(host, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || net.ParseIP(parts[1]) == nil {
+			return ValidationErr{message: fmt.Sprintf("invalid node container extra host %q, must be in the host:ip format", host)}
+		}
+	}
+	for _, dns := range hostConfig.DNS {
+		if net.ParseIP(dns) == nil {
+			return ValidationErr{message: fmt.Sprintf("invalid node container dns %q, must be an IP address", dns)}
+		}
+	}
 	return nil
 }
 
@@ -68,6 +187,9 @@ func AddNewContainer(pool string, c *NodeContainerConfig) error {
 	if err := c.validate(pool); err != nil {
 		return err
 	}
+	if err := validateMergedResources(pool, c, false); err != nil {
+		return err
+	}
 	conf := configFor(c.Name)
 	return conf.Save(pool, c)
 }
@@ -76,6 +198,15 @@ func UpdateContainer(pool string, c *NodeContainerConfig) error {
 	if c.Name == "" {
 		return ErrNodeContainerNoName
 	}
+	if errs := validateFields(c); len(errs) > 0 {
+		return ValidationErr{message: errs[0].Message}
+	}
+	if err := validateResources(c.HostConfig); err != nil {
+		return err
+	}
+	if err := validateNetwork(c.HostConfig); err != nil {
+		return err
+	}
 	conf := configFor(c.Name)
 	conf.SliceAdd = false
 	conf.PtrNilIsEmpty = false
@@ -86,6 +217,10 @@ func UpdateContainer(pool string, c *NodeContainerConfig) error {
 	if !hasEntry {
 		return ErrNodeContainerNotFound
 	}
+	err = validateMergedResources(pool, c, true)
+	if err != nil {
+		return err
+	}
 	err = removeDuplicateEnvs(c, conf, pool)
 	if err != nil {
 		return err
@@ -243,6 +378,31 @@ func (c *NodeContainerConfig) EnvMap() map[string]string {
 	return envMap
 }
 
+// EnvListForNode returns the container env merged with the env vars set for
+// the node with the given address, which take precedence.
+func (c *NodeContainerConfig) EnvListForNode(address string) []string {
+	envs := append([]string{}, c.Config.Env...)
+	indexes := map[string]int{}
+	for i, e := range envs {
+		indexes[strings.SplitN(e, "=", 2)[0]] = i
+	}
+	for _, nodeEnv := range c.NodeEnvs {
+		if nodeEnv.Address != address {
+			continue
+		}
+		for _, e := range nodeEnv.Env {
+			name := strings.SplitN(e, "=", 2)[0]
+			if i, ok := indexes[name]; ok {
+				envs[i] = e
+				continue
+			}
+			indexes[name] = len(envs)
+			envs = append(envs, e)
+		}
+	}
+	return envs
+}
+
 func (c *NodeContainerConfig) Valid() bool {
 	if c.Disabled != nil && *c.Disabled {
 		return false
@@ -266,15 +426,45 @@ func configFor(name string) *scopedconfig.ScopedConfig {
 	return conf
 }
 
-func shouldPinImage(image string) bool {
+// pinImagePolicy returns the policy used to decide whether node container
+// images are pinned to their digests after being pulled.
+func pinImagePolicy() (string, error) {
+	policy, _ := config.GetString("docker:nodecontainer:pin-image")
+	switch policy {
+	case "":
+		return PinImageUntaggedOnly, nil
+	case PinImageAlways, PinImageNever, PinImageUntaggedOnly:
+		return policy, nil
+	}
+	return "", errors.Errorf("invalid docker:nodecontainer:pin-image policy %q, valid values are: %s, %s, %s",
+		policy, PinImageAlways, PinImageNever, PinImageUntaggedOnly)
+}
+
+func shouldPinImage(image, policy string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	switch policy {
+	case PinImageAlways:
+		return true
+	case PinImageNever:
+		return false
+	}
 	parts := strings.SplitN(image, "/", 3)
 	lastPart := parts[len(parts)-1]
 	versionParts := strings.SplitN(lastPart, ":", 2)
 	return len(versionParts) < 2 || versionParts[1] == "latest"
 }
 
+// PinImageIfNeeded pins the node container to the digest of the pulled
+// image, according to the docker:nodecontainer:pin-image policy. The pinned
+// image keeps the tag, if any, along with the digest.
 func (c *NodeContainerConfig) PinImageIfNeeded(image, digest, pool string) error {
-	if !shouldPinImage(image) {
+	policy, err := pinImagePolicy()
+	if err != nil {
+		return err
+	}
+	if !shouldPinImage(image, policy) {
 		return nil
 	}
 	base, err := LoadNodeContainer("", c.Name)
@@ -8,6 +8,7 @@ import (
 	"sort"
 
 	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
 	"gopkg.in/check.v1"
 )
 
@@ -127,6 +128,109 @@ func (s *S) TestAddNewContainerInvalid(c *check.C) {
 	c.Assert(err, check.IsNil)
 	err = AddNewContainer("", &NodeContainerConfig{Name: "x", Config: docker.Config{Image: ""}})
 	c.Assert(err, check.ErrorMatches, "node container config image cannot be empty")
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", NodeEnvs: []NodeEnv{{Env: []string{"A=1"}}}})
+	c.Assert(err, check.ErrorMatches, "node container node env address cannot be empty")
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{Memory: -1}})
+	c.Assert(err, check.ErrorMatches, "node container memory limit cannot be negative")
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{MemorySwap: -2}})
+	c.Assert(err, check.ErrorMatches, "node container memory swap limit must be -1 or positive")
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{Memory: 200, MemorySwap: 100}})
+	c.Assert(err, check.ErrorMatches, "node container memory swap limit cannot be lower than the memory limit")
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{CPUQuota: -1}})
+	c.Assert(err, check.ErrorMatches, "node container cpu limits cannot be negative")
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{ExtraHosts: []string{"tsuru.local"}}})
+	c.Assert(err, check.ErrorMatches, `invalid node container extra host "tsuru.local", must be in the host:ip format`)
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{ExtraHosts: []string{"tsuru.local:tsuru.com"}}})
+	c.Assert(err, check.ErrorMatches, `invalid node container extra host "tsuru.local:tsuru.com", must be in the host:ip format`)
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{DNS: []string{"ns.tsuru.local"}}})
+	c.Assert(err, check.ErrorMatches, `invalid node container dns "ns.tsuru.local", must be an IP address`)
+	err = UpdateContainer("p1", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{DNS: []string{"ns.tsuru.local"}}})
+	c.Assert(err, check.ErrorMatches, `invalid node container dns "ns.tsuru.local", must be an IP address`)
+}
+
+func (s *S) TestAddNewContainerResourceLimits(c *check.C) {
+	err := AddNewContainer("", &NodeContainerConfig{
+		Name:       "bs",
+		Config:     docker.Config{Image: "myimg"},
+		HostConfig: docker.HostConfig{Memory: 256, MemorySwap: -1, CPUShares: 100},
+	})
+	c.Assert(err, check.IsNil)
+	err = AddNewContainer("p1", &NodeContainerConfig{
+		Name:       "bs",
+		HostConfig: docker.HostConfig{Memory: 512, MemorySwap: 1024, CPUQuota: 50000, CPUPeriod: 100000},
+	})
+	c.Assert(err, check.IsNil)
+	base, err := LoadNodeContainer("", "bs")
+	c.Assert(err, check.IsNil)
+	c.Assert(base.HostConfig.Memory, check.Equals, int64(256))
+	c.Assert(base.HostConfig.MemorySwap, check.Equals, int64(-1))
+	c.Assert(base.HostConfig.CPUShares, check.Equals, int64(100))
+	c.Assert(base.HostConfig.CPUQuota, check.Equals, int64(0))
+	pool, err := LoadNodeContainer("p1", "bs")
+	c.Assert(err, check.IsNil)
+	c.Assert(pool.HostConfig.Memory, check.Equals, int64(512))
+	c.Assert(pool.HostConfig.MemorySwap, check.Equals, int64(1024))
+	c.Assert(pool.HostConfig.CPUShares, check.Equals, int64(100))
+	c.Assert(pool.HostConfig.CPUQuota, check.Equals, int64(50000))
+	c.Assert(pool.HostConfig.CPUPeriod, check.Equals, int64(100000))
+}
+
+func (s *S) TestAddNewContainerInvalidMergedResources(c *check.C) {
+	err := AddNewContainer("", &NodeContainerConfig{
+		Name:       "bs",
+		Config:     docker.Config{Image: "myimg"},
+		HostConfig: docker.HostConfig{Memory: 512},
+	})
+	c.Assert(err, check.IsNil)
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "bs", HostConfig: docker.HostConfig{MemorySwap: 256}})
+	c.Assert(err, check.ErrorMatches, "node container memory swap limit cannot be lower than the memory limit")
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "bs", HostConfig: docker.HostConfig{MemorySwap: 1024}})
+	c.Assert(err, check.IsNil)
+	err = UpdateContainer("", &NodeContainerConfig{Name: "bs", HostConfig: docker.HostConfig{Memory: 2048}})
+	c.Assert(err, check.ErrorMatches, `node container memory swap limit cannot be lower than the memory limit in pool "p1"`)
+	err = AddNewContainer("", &NodeContainerConfig{
+		Name:       "bs",
+		Config:     docker.Config{Image: "myimg"},
+		HostConfig: docker.HostConfig{Memory: 2048},
+	})
+	c.Assert(err, check.ErrorMatches, `node container memory swap limit cannot be lower than the memory limit in pool "p1"`)
+	err = UpdateContainer("p1", &NodeContainerConfig{Name: "bs", HostConfig: docker.HostConfig{Memory: 2048}})
+	c.Assert(err, check.ErrorMatches, "node container memory swap limit cannot be lower than the memory limit")
+	base, err := LoadNodeContainer("", "bs")
+	c.Assert(err, check.IsNil)
+	c.Assert(base.HostConfig.Memory, check.Equals, int64(512))
+}
+
+func (s *S) TestAddNewContainerNodeEnvs(c *check.C) {
+	err := AddNewContainer("", &NodeContainerConfig{Name: "bs", Config: docker.Config{Image: "img1", Env: []string{"A=1"}}})
+	c.Assert(err, check.IsNil)
+	err = AddNewContainer("p1", &NodeContainerConfig{Name: "bs", NodeEnvs: []NodeEnv{
+		{Address: "http://n1:2375", Env: []string{"A=2", "B=1"}},
+	}})
+	c.Assert(err, check.IsNil)
+	result, err := LoadNodeContainer("p1", "bs")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.NodeEnvs, check.DeepEquals, []NodeEnv{
+		{Address: "http://n1:2375", Env: []string{"A=2", "B=1"}},
+	})
+	c.Assert(result.EnvListForNode("http://n1:2375"), check.DeepEquals, []string{"A=2", "B=1"})
+	c.Assert(result.EnvListForNode("http://n2:2375"), check.DeepEquals, []string{"A=1"})
+}
+
+func (s *S) TestEnvListForNode(c *check.C) {
+	config := NodeContainerConfig{
+		Name:   "bs",
+		Config: docker.Config{Env: []string{"A=1", "B=2", "C=3"}},
+		NodeEnvs: []NodeEnv{
+			{Address: "http://n1:2375", Env: []string{"B=x", "D=4"}},
+			{Address: "http://n2:2375", Env: []string{"A=y"}},
+			{Address: "http://n1:2375", Env: []string{"D=z"}},
+		},
+	}
+	c.Assert(config.EnvListForNode("http://n1:2375"), check.DeepEquals, []string{"A=1", "B=x", "C=3", "D=z"})
+	c.Assert(config.EnvListForNode("http://n2:2375"), check.DeepEquals, []string{"A=y", "B=2", "C=3"})
+	c.Assert(config.EnvListForNode("http://n3:2375"), check.DeepEquals, []string{"A=1", "B=2", "C=3"})
+	c.Assert(config.Config.Env, check.DeepEquals, []string{"A=1", "B=2", "C=3"})
 }
 
 func (s *S) TestUpdateContainer(c *check.C) {
@@ -166,6 +270,8 @@ func (s *S) TestUpdateContainerInvalid(c *check.C) {
 	c.Assert(err, check.Equals, ErrNodeContainerNotFound)
 	err = UpdateContainer("", &NodeContainerConfig{Name: "x"})
 	c.Assert(err, check.Equals, ErrNodeContainerNotFound)
+	err = UpdateContainer("", &NodeContainerConfig{Name: "x", HostConfig: docker.HostConfig{CPUShares: -10}})
+	c.Assert(err, check.ErrorMatches, "node container cpu limits cannot be negative")
 	err = AddNewContainer("", &NodeContainerConfig{Name: "x", Config: docker.Config{Image: "img1"}})
 	c.Assert(err, check.IsNil)
 	err = UpdateContainer("p1", &NodeContainerConfig{Name: "x"})
@@ -298,3 +404,59 @@ func (s *S) TestResetImage(c *check.C) {
 	})
 
 }
+
+func (s *S) TestShouldPinImage(c *check.C) {
+	tests := []struct {
+		image    string
+		policy   string
+		expected bool
+	}{
+		{"tsuru/bs", PinImageUntaggedOnly, true},
+		{"tsuru/bs:latest", PinImageUntaggedOnly, true},
+		{"tsuru/bs:v1", PinImageUntaggedOnly, false},
+		{"myregistry:5000/tsuru/bs", PinImageUntaggedOnly, true},
+		{"tsuru/bs:v1", PinImageAlways, true},
+		{"tsuru/bs", PinImageNever, false},
+		{"tsuru/bs:v1@sha256:abc", PinImageAlways, false},
+		{"tsuru/bs@sha256:abc", PinImageUntaggedOnly, false},
+	}
+	for _, tt := range tests {
+		c.Check(shouldPinImage(tt.image, tt.policy), check.Equals, tt.expected, check.Commentf("image %q, policy %q", tt.image, tt.policy))
+	}
+}
+
+func (s *S) TestPinImageIfNeededPolicyAlways(c *check.C) {
+	config.Set("docker:nodecontainer:pin-image", PinImageAlways)
+	defer config.Unset("docker:nodecontainer:pin-image")
+	cont := NodeContainerConfig{Name: "c1", Config: docker.Config{Image: "img1:v1"}}
+	err := AddNewContainer("", &cont)
+	c.Assert(err, check.IsNil)
+	err = cont.PinImageIfNeeded("img1:v1", "sha256:abc", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(cont.PinnedImage, check.Equals, "img1:v1@sha256:abc")
+	result, err := LoadNodeContainer("", "c1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.PinnedImage, check.Equals, "img1:v1@sha256:abc")
+	c.Assert(result.Image(), check.Equals, "img1:v1@sha256:abc")
+}
+
+func (s *S) TestPinImageIfNeededPolicyNever(c *check.C) {
+	config.Set("docker:nodecontainer:pin-image", PinImageNever)
+	defer config.Unset("docker:nodecontainer:pin-image")
+	cont := NodeContainerConfig{Name: "c1", Config: docker.Config{Image: "img1"}}
+	err := AddNewContainer("", &cont)
+	c.Assert(err, check.IsNil)
+	err = cont.PinImageIfNeeded("img1", "sha256:abc", "")
+	c.Assert(err, check.IsNil)
+	result, err := LoadNodeContainer("", "c1")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.PinnedImage, check.Equals, "")
+}
+
+func (s *S) TestPinImageIfNeededInvalidPolicy(c *check.C) {
+	config.Set("docker:nodecontainer:pin-image", "sometimes")
+	defer config.Unset("docker:nodecontainer:pin-image")
+	cont := NodeContainerConfig{Name: "c1", Config: docker.Config{Image: "img1"}}
+	err := cont.PinImageIfNeeded("img1", "sha256:abc", "")
+	c.Assert(err, check.ErrorMatches, `invalid docker:nodecontainer:pin-image policy "sometimes".*`)
+}
@@ -0,0 +1,305 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const (
+	// SecretRefPrefix starts env values referencing a secret, in the
+	// secret://<backend>/<path> format. They're resolved when the node
+	// container is created, so the secret is never stored in its config.
+	SecretRefPrefix = "secret://"
+
+	SecretBackendTsuru = "tsuru"
+	SecretBackendVault = "vault"
+
+	secretsCollection = "nodecontainer_secrets"
+)
+
+var (
+	ErrSecretNotFound   = errors.New("node container secret not found")
+	ErrSecretKeyMissing = errors.New("secret store key not set in docker:nodecontainer:secrets:key")
+)
+
+// SecretBackend returns the values of secrets referenced in node container
+// envs.
+type SecretBackend interface {
+	Secret(path string) (string, error)
+}
+
+var (
+	secretBackendsMu sync.RWMutex
+	secretBackends   = map[string]SecretBackend{
+		SecretBackendTsuru: &storeSecretBackend{},
+		SecretBackendVault: &vaultSecretBackend{},
+	}
+)
+
+// RegisterSecretBackend makes a secret backend available to be referenced
+// in node container envs.
+func RegisterSecretBackend(name string, backend SecretBackend) {
+	secretBackendsMu.Lock()
+	defer secretBackendsMu.Unlock()
+	secretBackends[name] = backend
+}
+
+// IsSecretRef returns whether the env value references a secret.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefPrefix)
+}
+
+// ResolveEnvs returns the envs replacing the values referencing secrets with
+// the secret values.
+func ResolveEnvs(envs []string) ([]string, error) {
+	if len(envs) == 0 {
+		return envs, nil
+	}
+	result := make([]string, len(envs))
+	for i, env := range envs {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || !IsSecretRef(parts[1]) {
+			result[i] = env
+			continue
+		}
+		value, err := ResolveSecret(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve env %q", parts[0])
+		}
+		result[i] = parts[0] + "=" + value
+	}
+	return result, nil
+}
+
+// ResolveSecret returns the value of the secret referenced in the
+// secret://<backend>/<path> format.
+func ResolveSecret(ref string) (string, error) {
+	backend, path, err := parseSecretRef(ref)
+	if err != nil {
+		return "", err
+	}
+	return backend.Secret(path)
+}
+
+func parseSecretRef(ref string) (SecretBackend, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(ref, SecretRefPrefix), "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, "", errors.Errorf("invalid secret reference %q, expected %s<backend>/<path>", ref, SecretRefPrefix)
+	}
+	secretBackendsMu.RLock()
+	backend, ok := secretBackends[parts[0]]
+	secretBackendsMu.RUnlock()
+	if !ok {
+		return nil, "", errors.Errorf("unknown secret backend %q", parts[0])
+	}
+	return backend, parts[1], nil
+}
+
+// secret holds a value of the tsuru secret store, encrypted with AES-GCM
+// using the key in docker:nodecontainer:secrets:key.
+type secret struct {
+	Name      string `bson:"_id"`
+	Value     string
+	Encrypted bool
+}
+
+func secretCipher() (cipher.AEAD, error) {
+	key, _ := config.GetString("docker:nodecontainer:secrets:key")
+	if key == "" {
+		return nil, ErrSecretKeyMissing
+	}
+	hash := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(hash[:])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptSecret(name, value string) (string, error) {
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	data := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decryptSecret(name, value string) (string, error) {
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errors.Errorf("invalid encrypted value for secret %q", name)
+	}
+	nonceSize := gcm.NonceSize()
+	plain, err := gcm.Open(nil, data[:nonceSize], data[nonceSize:], []byte(name))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to decrypt secret %q", name)
+	}
+	return string(plain), nil
+}
+
+func secretsColl() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection(secretsCollection), nil
+}
+
+// SetSecret stores the secret in the tsuru secret store, available to node
+// containers as secret://tsuru/<name>. The value is encrypted before being
+// stored.
+func SetSecret(name, value string) error {
+	if name == "" {
+		return ValidationErr{message: "node container secret name cannot be empty"}
+	}
+	encrypted, err := encryptSecret(name, value)
+	if err != nil {
+		return err
+	}
+	coll, err := secretsColl()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.UpsertId(name, secret{Name: name, Value: encrypted, Encrypted: true})
+	return err
+}
+
+// RemoveSecret removes the secret from the tsuru secret store.
+func RemoveSecret(name string) error {
+	coll, err := secretsColl()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return ErrSecretNotFound
+	}
+	return err
+}
+
+// SecretNames returns the sorted names of the secrets in the tsuru secret
+// store. Values are never exposed.
+func SecretNames() ([]string, error) {
+	coll, err := secretsColl()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var secrets []secret
+	err = coll.Find(nil).Select(map[string]int{"_id": 1}).All(&secrets)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(secrets))
+	for i, s := range secrets {
+		names[i] = s.Name
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// storeSecretBackend reads secrets from the tsuru secret store.
+type storeSecretBackend struct{}
+
+func (b *storeSecretBackend) Secret(name string) (string, error) {
+	coll, err := secretsColl()
+	if err != nil {
+		return "", err
+	}
+	defer coll.Close()
+	var s secret
+	err = coll.FindId(name).One(&s)
+	if err == mgo.ErrNotFound {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if !s.Encrypted {
+		return s.Value, nil
+	}
+	return decryptSecret(s.Name, s.Value)
+}
+
+// vaultSecretBackend reads secrets from the Vault KV secrets engine. Paths
+// are in the <secret path>#<key> format, e.g. secret/data/bs#token, the key
+// defaulting to "value". Both versions of the KV engine are supported.
+type vaultSecretBackend struct{}
+
+func (b *vaultSecretBackend) Secret(path string) (string, error) {
+	address, _ := config.GetString("docker:nodecontainer:secrets:vault:address")
+	if address == "" {
+		return "", errors.New("vault address not set in docker:nodecontainer:secrets:vault:address")
+	}
+	token, _ := config.GetString("docker:nodecontainer:secrets:vault:token")
+	key := "value"
+	if idx := strings.LastIndex(path, "#"); idx >= 0 {
+		path, key = path[:idx], path[idx+1:]
+	}
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimRight(address, "/"), strings.TrimLeft(path, "/"))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	rsp, err := tsuruNet.Dial5Full60ClientNoKeepAlive.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read secret from vault")
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNotFound {
+		return "", ErrSecretNotFound
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unable to read secret from vault, invalid status code %d", rsp.StatusCode)
+	}
+	var result struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err = json.NewDecoder(rsp.Body).Decode(&result)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to parse vault response")
+	}
+	data := result.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return fmt.Sprintf("%v", value), nil
+}
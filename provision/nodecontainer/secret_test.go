@@ -0,0 +1,170 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	"gopkg.in/check.v1"
+)
+
+type fakeSecretBackend map[string]string
+
+func (b fakeSecretBackend) Secret(path string) (string, error) {
+	value, ok := b[path]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *S) TestResolveEnvs(c *check.C) {
+	err := SetSecret("bs-token", "abc123")
+	c.Assert(err, check.IsNil)
+	envs, err := ResolveEnvs([]string{"A=1", "TOKEN=secret://tsuru/bs-token", "B", "C=secret:/other"})
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []string{"A=1", "TOKEN=abc123", "B", "C=secret:/other"})
+	envs, err = ResolveEnvs(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.IsNil)
+}
+
+func (s *S) TestResolveEnvsErrors(c *check.C) {
+	_, err := ResolveEnvs([]string{"TOKEN=secret://tsuru/missing"})
+	c.Assert(err, check.ErrorMatches, `unable to resolve env "TOKEN": node container secret not found`)
+	_, err = ResolveEnvs([]string{"TOKEN=secret://tsuru"})
+	c.Assert(err, check.ErrorMatches, `unable to resolve env "TOKEN": invalid secret reference "secret://tsuru", expected secret://<backend>/<path>`)
+	_, err = ResolveEnvs([]string{"TOKEN=secret://unknown/x"})
+	c.Assert(err, check.ErrorMatches, `unable to resolve env "TOKEN": unknown secret backend "unknown"`)
+}
+
+func (s *S) TestRegisterSecretBackend(c *check.C) {
+	RegisterSecretBackend("fake", fakeSecretBackend{"a/b": "value"})
+	defer func() {
+		secretBackendsMu.Lock()
+		delete(secretBackends, "fake")
+		secretBackendsMu.Unlock()
+	}()
+	envs, err := ResolveEnvs([]string{"X=secret://fake/a/b"})
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []string{"X=value"})
+}
+
+func (s *S) TestResolveEnvsVault(c *check.C) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/bs":
+			fmt.Fprint(w, `{"data": {"data": {"token": "kv2-token"}, "metadata": {"version": 1}}}`)
+		case "/v1/kv/bs":
+			fmt.Fprint(w, `{"data": {"value": "kv1-token"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	config.Set("docker:nodecontainer:secrets:vault:address", server.URL+"/")
+	config.Set("docker:nodecontainer:secrets:vault:token", "vault-token")
+	defer config.Unset("docker:nodecontainer:secrets")
+	envs, err := ResolveEnvs([]string{"A=secret://vault/secret/data/bs#token", "B=secret://vault/kv/bs"})
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []string{"A=kv2-token", "B=kv1-token"})
+	_, err = ResolveEnvs([]string{"A=secret://vault/secret/data/other"})
+	c.Assert(err, check.ErrorMatches, `.*node container secret not found`)
+	_, err = ResolveEnvs([]string{"A=secret://vault/secret/data/bs#missing"})
+	c.Assert(err, check.ErrorMatches, `.*node container secret not found`)
+	config.Set("docker:nodecontainer:secrets:vault:token", "invalid")
+	_, err = ResolveEnvs([]string{"A=secret://vault/kv/bs"})
+	c.Assert(err, check.ErrorMatches, `.*invalid status code 403`)
+	c.Assert(paths, check.DeepEquals, []string{"/v1/secret/data/bs", "/v1/kv/bs", "/v1/secret/data/other", "/v1/secret/data/bs", "/v1/kv/bs"})
+}
+
+func (s *S) TestSecretNamesAndRemoveSecret(c *check.C) {
+	err := SetSecret("b", "1")
+	c.Assert(err, check.IsNil)
+	err = SetSecret("a", "2")
+	c.Assert(err, check.IsNil)
+	err = SetSecret("a", "3")
+	c.Assert(err, check.IsNil)
+	names, err := SecretNames()
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"a", "b"})
+	envs, err := ResolveEnvs([]string{"A=secret://tsuru/a"})
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, []string{"A=3"})
+	err = RemoveSecret("a")
+	c.Assert(err, check.IsNil)
+	err = RemoveSecret("a")
+	c.Assert(err, check.Equals, ErrSecretNotFound)
+	names, err = SecretNames()
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"b"})
+	err = SetSecret("", "x")
+	c.Assert(err, check.FitsTypeOf, ValidationErr{})
+}
+
+func (s *S) TestSetSecretEncryptsValue(c *check.C) {
+	err := SetSecret("bs-token", "abc123")
+	c.Assert(err, check.IsNil)
+	coll, err := secretsColl()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	var stored secret
+	err = coll.FindId("bs-token").One(&stored)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Encrypted, check.Equals, true)
+	c.Assert(stored.Value, check.Not(check.Equals), "")
+	c.Assert(strings.Contains(stored.Value, "abc123"), check.Equals, false)
+	value, err := ResolveSecret("secret://tsuru/bs-token")
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "abc123")
+	config.Set("docker:nodecontainer:secrets:key", "other-key")
+	defer config.Set("docker:nodecontainer:secrets:key", "secret-store-key")
+	_, err = ResolveSecret("secret://tsuru/bs-token")
+	c.Assert(err, check.ErrorMatches, `unable to decrypt secret "bs-token": .*`)
+}
+
+func (s *S) TestSetSecretNoKey(c *check.C) {
+	config.Unset("docker:nodecontainer:secrets:key")
+	defer config.Set("docker:nodecontainer:secrets:key", "secret-store-key")
+	err := SetSecret("bs-token", "abc123")
+	c.Assert(err, check.Equals, ErrSecretKeyMissing)
+	names, err := SecretNames()
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.HasLen, 0)
+}
+
+func (s *S) TestResolveSecretNotEncrypted(c *check.C) {
+	coll, err := secretsColl()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	err = coll.Insert(secret{Name: "old", Value: "plain"})
+	c.Assert(err, check.IsNil)
+	value, err := ResolveSecret("secret://tsuru/old")
+	c.Assert(err, check.IsNil)
+	c.Assert(value, check.Equals, "plain")
+}
+
+func (s *S) TestValidateSecretReferences(c *check.C) {
+	errs, err := Validate("", &NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1", Env: []string{"A=secret://tsuru/x", "B=secret://nope/x", "C=secret://tsuru/"}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.DeepEquals, ValidationErrors{
+		{Field: "Config.Env[1]", Message: `unknown secret backend "nope"`},
+		{Field: "Config.Env[2]", Message: `invalid secret reference "secret://tsuru/", expected secret://<backend>/<path>`},
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// SeccompUnconfined disables seccomp filtering in the containers.
+const SeccompUnconfined = "unconfined"
+
+var (
+	capabilityRegexp      = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	appArmorProfileRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_./-]*$`)
+)
+
+// SecurityProfile holds the security settings of the containers, applied
+// over their HostConfig. The profile set for a pool replaces the one in the
+// base config as a whole.
+type SecurityProfile struct {
+	// Privileged overrides HostConfig.Privileged when set, allowing
+	// containers to run unprivileged even when the base config, like the
+	// one of big-sibling, is privileged.
+	Privileged *bool
+	CapAdd     []string
+	CapDrop    []string
+	// SeccompProfile is either SeccompUnconfined or a seccomp profile in
+	// JSON. Containers use the default profile of the runtime when it's
+	// empty.
+	SeccompProfile string
+	// AppArmorProfile is the name of an AppArmor profile loaded in the
+	// nodes.
+	AppArmorProfile string
+}
+
+// ApplySecurity sets the security profile of the config in its HostConfig.
+func (c *NodeContainerConfig) ApplySecurity() {
+	s := c.Security
+	if s == nil {
+		return
+	}
+	if s.Privileged != nil {
+		c.HostConfig.Privileged = *s.Privileged
+	}
+	if len(s.CapAdd) > 0 {
+		c.HostConfig.CapAdd = append(append([]string{}, c.HostConfig.CapAdd...), s.CapAdd...)
+	}
+	if len(s.CapDrop) > 0 {
+		c.HostConfig.CapDrop = append(append([]string{}, c.HostConfig.CapDrop...), s.CapDrop...)
+	}
+	var opts []string
+	if s.SeccompProfile != "" {
+		opts = append(opts, "seccomp="+s.SeccompProfile)
+	}
+	if s.AppArmorProfile != "" {
+		opts = append(opts, "apparmor="+s.AppArmorProfile)
+	}
+	if len(opts) > 0 {
+		c.HostConfig.SecurityOpt = append(append([]string{}, c.HostConfig.SecurityOpt...), opts...)
+	}
+}
+
+func validateSecurity(s *SecurityProfile) ValidationErrors {
+	if s == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	errs = append(errs, validateCapabilities("Security.CapAdd", s.CapAdd)...)
+	errs = append(errs, validateCapabilities("Security.CapDrop", s.CapDrop)...)
+	if s.SeccompProfile != "" && s.SeccompProfile != SeccompUnconfined {
+		var profile map[string]interface{}
+		if err := json.Unmarshal([]byte(s.SeccompProfile), &profile); err != nil {
+			errs = append(errs, FieldError{Field: "Security.SeccompProfile", Message: fmt.Sprintf("seccomp profile must be %q or a JSON profile: %s", SeccompUnconfined, err)})
+		}
+	}
+	if s.AppArmorProfile != "" && !appArmorProfileRegexp.MatchString(s.AppArmorProfile) {
+		errs = append(errs, FieldError{Field: "Security.AppArmorProfile", Message: fmt.Sprintf("invalid apparmor profile %q", s.AppArmorProfile)})
+	}
+	return errs
+}
+
+func validateCapabilities(field string, caps []string) ValidationErrors {
+	var errs ValidationErrors
+	for i, capability := range caps {
+		if !capabilityRegexp.MatchString(capability) {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("%s[%d]", field, i), Message: fmt.Sprintf("invalid capability %q", capability)})
+		}
+	}
+	return errs
+}
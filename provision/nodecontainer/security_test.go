@@ -0,0 +1,75 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"github.com/fsouza/go-dockerclient"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestApplySecurity(c *check.C) {
+	privileged := false
+	conf := NodeContainerConfig{
+		Name: BsDefaultName,
+		HostConfig: docker.HostConfig{
+			Privileged:  true,
+			CapAdd:      []string{"NET_ADMIN"},
+			SecurityOpt: []string{"no-new-privileges"},
+		},
+		Security: &SecurityProfile{
+			Privileged:      &privileged,
+			CapAdd:          []string{"SYS_PTRACE"},
+			CapDrop:         []string{"ALL"},
+			SeccompProfile:  SeccompUnconfined,
+			AppArmorProfile: "bs-profile",
+		},
+	}
+	conf.ApplySecurity()
+	c.Assert(conf.HostConfig.Privileged, check.Equals, false)
+	c.Assert(conf.HostConfig.CapAdd, check.DeepEquals, []string{"NET_ADMIN", "SYS_PTRACE"})
+	c.Assert(conf.HostConfig.CapDrop, check.DeepEquals, []string{"ALL"})
+	c.Assert(conf.HostConfig.SecurityOpt, check.DeepEquals, []string{"no-new-privileges", "seccomp=unconfined", "apparmor=bs-profile"})
+}
+
+func (s *S) TestApplySecurityKeepsPrivilegedWhenNotSet(c *check.C) {
+	conf := NodeContainerConfig{
+		Name:       BsDefaultName,
+		HostConfig: docker.HostConfig{Privileged: true},
+		Security:   &SecurityProfile{CapDrop: []string{"NET_RAW"}},
+	}
+	conf.ApplySecurity()
+	c.Assert(conf.HostConfig.Privileged, check.Equals, true)
+	c.Assert(conf.HostConfig.CapDrop, check.DeepEquals, []string{"NET_RAW"})
+	c.Assert(conf.HostConfig.SecurityOpt, check.IsNil)
+}
+
+func (s *S) TestValidateSecurity(c *check.C) {
+	errs, err := Validate("", &NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "c1"},
+		Security: &SecurityProfile{
+			CapAdd:          []string{"SYS_PTRACE", "sys-admin"},
+			CapDrop:         []string{""},
+			SeccompProfile:  "{invalid",
+			AppArmorProfile: "bad profile",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.HasLen, 4)
+	c.Assert(errs[0], check.DeepEquals, FieldError{Field: "Security.CapAdd[1]", Message: `invalid capability "sys-admin"`})
+	c.Assert(errs[1], check.DeepEquals, FieldError{Field: "Security.CapDrop[0]", Message: `invalid capability ""`})
+	c.Assert(errs[2].Field, check.Equals, "Security.SeccompProfile")
+	c.Assert(errs[3], check.DeepEquals, FieldError{Field: "Security.AppArmorProfile", Message: `invalid apparmor profile "bad profile"`})
+	errs, err = Validate("", &NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "c1"},
+		Security: &SecurityProfile{
+			CapDrop:        []string{"ALL"},
+			SeccompProfile: `{"defaultAction": "SCMP_ACT_ERRNO"}`,
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.HasLen, 0)
+}
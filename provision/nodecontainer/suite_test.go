@@ -24,6 +24,7 @@ type S struct{}
 
 func (s *S) SetUpSuite(c *check.C) {
 	config.Set("log:disable-syslog", true)
+	config.Set("docker:nodecontainer:secrets:key", "secret-store-key")
 	config.Set("database:driver", "mongodb")
 	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
 	config.Set("database:name", "docker_provision_nodecontainer_tests")
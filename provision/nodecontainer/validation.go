@@ -0,0 +1,198 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/cron"
+)
+
+var (
+	envNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// imageRegexp follows the docker image reference grammar: an optional
+	// registry, lowercase path components, an optional tag and an optional
+	// digest.
+	imageRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,})?$`)
+	volumeNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	bindModes        = map[string]bool{
+		"ro": true, "rw": true, "z": true, "Z": true,
+		"shared": true, "rshared": true, "slave": true, "rslave": true, "private": true, "rprivate": true,
+		"nocopy": true, "consistent": true, "cached": true, "delegated": true,
+	}
+	defaultForbiddenEnvs = []string{"TSURU_TOKEN"}
+)
+
+// FieldError describes an invalid field in a node container config.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors holds all the invalid fields found in a node container
+// config.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fieldErr := range e {
+		msgs[i] = fieldErr.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the config proposed for the node container in the given
+// pool without persisting it, returning all the invalid fields found. The
+// image is only required when neither the proposed config nor the existing
+// one define it.
+func Validate(pool string, c *NodeContainerConfig) (ValidationErrors, error) {
+	var errs ValidationErrors
+	if c.Name == "" {
+		errs = append(errs, FieldError{Field: "Name", Message: ErrNodeContainerNoName.Error()})
+	} else if c.Config.Image == "" {
+		existing, err := LoadNodeContainer(pool, c.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing.Config.Image == "" {
+			errs = append(errs, FieldError{Field: "Config.Image", Message: "node container config image cannot be empty"})
+		}
+	}
+	errs = append(errs, validateFields(c)...)
+	errs = append(errs, forbiddenEnvErrors(c)...)
+	if err := validateResources(c.HostConfig); err != nil {
+		errs = append(errs, FieldError{Field: "HostConfig", Message: err.Error()})
+	}
+	return errs, nil
+}
+
+// validateFields checks the fields of the config whose values may be
+// checked without the config stored for the node container.
+func validateFields(c *NodeContainerConfig) ValidationErrors {
+	var errs ValidationErrors
+	if c.Config.Image != "" && !imageRegexp.MatchString(c.Config.Image) {
+		errs = append(errs, FieldError{Field: "Config.Image", Message: fmt.Sprintf("invalid image reference %q", c.Config.Image)})
+	}
+	errs = append(errs, validateEnvs("Config.Env", c.Config.Env)...)
+	for i, nodeEnv := range c.NodeEnvs {
+		field := fmt.Sprintf("NodeEnvs[%d]", i)
+		if nodeEnv.Address == "" {
+			errs = append(errs, FieldError{Field: field + ".Address", Message: "node container node env address cannot be empty"})
+		}
+		errs = append(errs, validateEnvs(field+".Env", nodeEnv.Env)...)
+	}
+	for i, bind := range c.HostConfig.Binds {
+		if msg := validateBind(bind); msg != "" {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("HostConfig.Binds[%d]", i), Message: msg})
+		}
+	}
+	if c.RestartSchedule != "" {
+		if c.Name != BsDefaultName {
+			errs = append(errs, FieldError{Field: "RestartSchedule", Message: fmt.Sprintf("restart schedule is only supported for %s", BsDefaultName)})
+		} else if _, err := cron.Parse(c.RestartSchedule); err != nil {
+			errs = append(errs, FieldError{Field: "RestartSchedule", Message: fmt.Sprintf("invalid restart schedule %q: %s", c.RestartSchedule, err)})
+		}
+	}
+	if c.RestartWindow < 0 {
+		errs = append(errs, FieldError{Field: "RestartWindow", Message: "node container restart window cannot be negative"})
+	}
+	errs = append(errs, validateSecurity(c.Security)...)
+	return errs
+}
+
+// CheckForbiddenEnvs returns a ValidationErr when the config sets any env
+// var managed by tsuru. It must only be applied to configs sent by users,
+// tsuru itself stores some of these envs, e.g. the TSURU_TOKEN of big-sibling.
+func CheckForbiddenEnvs(c *NodeContainerConfig) error {
+	if errs := forbiddenEnvErrors(c); len(errs) > 0 {
+		return ValidationErr{message: errs[0].Message}
+	}
+	return nil
+}
+
+func forbiddenEnvErrors(c *NodeContainerConfig) ValidationErrors {
+	forbidden := forbiddenEnvs()
+	errs := checkForbidden("Config.Env", c.Config.Env, forbidden)
+	for i, nodeEnv := range c.NodeEnvs {
+		errs = append(errs, checkForbidden(fmt.Sprintf("NodeEnvs[%d].Env", i), nodeEnv.Env, forbidden)...)
+	}
+	return errs
+}
+
+func checkForbidden(field string, envs []string, forbidden map[string]bool) ValidationErrors {
+	var errs ValidationErrors
+	for i, env := range envs {
+		name := strings.SplitN(env, "=", 2)[0]
+		if forbidden[name] {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("%s[%d]", field, i), Message: fmt.Sprintf("env var %q is managed by tsuru and cannot be set", name)})
+		}
+	}
+	return errs
+}
+
+// forbiddenEnvs returns the env vars managed by tsuru, which can't be set in
+// node container configs.
+func forbiddenEnvs() map[string]bool {
+	names, err := config.GetList("docker:nodecontainer:forbidden-envs")
+	if err != nil {
+		names = defaultForbiddenEnvs
+	}
+	result := make(map[string]bool, len(names))
+	for _, name := range names {
+		result[name] = true
+	}
+	return result
+}
+
+func validateEnvs(field string, envs []string) ValidationErrors {
+	var errs ValidationErrors
+	for i, env := range envs {
+		parts := strings.SplitN(env, "=", 2)
+		name := parts[0]
+		envField := fmt.Sprintf("%s[%d]", field, i)
+		if !envNameRegexp.MatchString(name) {
+			errs = append(errs, FieldError{Field: envField, Message: fmt.Sprintf("invalid env var name %q", name)})
+		} else if len(parts) == 2 && strings.HasPrefix(parts[1], SecretRefPrefix) {
+			if _, _, err := parseSecretRef(parts[1]); err != nil {
+				errs = append(errs, FieldError{Field: envField, Message: err.Error()})
+			}
+		}
+	}
+	return errs
+}
+
+// validateBind checks a bind in the source:destination[:mode] format,
+// returning the reason why it's invalid or an empty string. The source may
+// be a host path or a volume name.
+func validateBind(bind string) string {
+	parts := strings.Split(bind, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Sprintf("invalid bind %q, expected source:destination[:mode]", bind)
+	}
+	source, dest := parts[0], parts[1]
+	if !path.IsAbs(source) && !volumeNameRegexp.MatchString(source) {
+		return fmt.Sprintf("invalid bind source %q, must be an absolute path or a volume name", source)
+	}
+	if !path.IsAbs(dest) {
+		return fmt.Sprintf("invalid bind destination %q, must be an absolute path", dest)
+	}
+	if path.Clean(dest) == "/" {
+		return fmt.Sprintf("invalid bind destination %q, cannot mount over the container root", dest)
+	}
+	if len(parts) == 3 {
+		for _, mode := range strings.Split(parts[2], ",") {
+			if !bindModes[mode] {
+				return fmt.Sprintf("invalid bind mode %q", mode)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodecontainer
+
+import (
+	"github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestValidate(c *check.C) {
+	errs, err := Validate("", &NodeContainerConfig{
+		Name: "c1",
+		Config: docker.Config{
+			Image: "Invalid Image",
+			Env:   []string{"A=1", "1B=2", "TSURU_TOKEN=abc"},
+		},
+		HostConfig: docker.HostConfig{
+			Binds:  []string{"/proc:/prochost:ro", "relative/path:/data", "/data:data", "/x:/y:bogus"},
+			Memory: -1,
+		},
+		NodeEnvs: []NodeEnv{{Env: []string{"X-Y=1"}}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.DeepEquals, ValidationErrors{
+		{Field: "Config.Image", Message: `invalid image reference "Invalid Image"`},
+		{Field: "Config.Env[1]", Message: `invalid env var name "1B"`},
+		{Field: "NodeEnvs[0].Address", Message: "node container node env address cannot be empty"},
+		{Field: "NodeEnvs[0].Env[0]", Message: `invalid env var name "X-Y"`},
+		{Field: "HostConfig.Binds[1]", Message: `invalid bind source "relative/path", must be an absolute path or a volume name`},
+		{Field: "HostConfig.Binds[2]", Message: `invalid bind destination "data", must be an absolute path`},
+		{Field: "HostConfig.Binds[3]", Message: `invalid bind mode "bogus"`},
+		{Field: "Config.Env[2]", Message: `env var "TSURU_TOKEN" is managed by tsuru and cannot be set`},
+		{Field: "HostConfig", Message: "node container memory limit cannot be negative"},
+	})
+}
+
+func (s *S) TestValidateValid(c *check.C) {
+	errs, err := Validate("p1", &NodeContainerConfig{
+		Name: "c1",
+		Config: docker.Config{
+			Image: "myregistry.com:5000/tsuru/bs:v1",
+			Env:   []string{"A=1", "B"},
+		},
+		HostConfig: docker.HostConfig{
+			Binds: []string{"/proc:/prochost:ro", "myvolume:/data:rw,z"},
+		},
+		NodeEnvs: []NodeEnv{{Address: "http://n1:2375", Env: []string{"C=3"}}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.HasLen, 0)
+}
+
+func (s *S) TestValidateImageFromExistingConfig(c *check.C) {
+	errs, err := Validate("p1", &NodeContainerConfig{Name: "c1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.DeepEquals, ValidationErrors{
+		{Field: "Config.Image", Message: "node container config image cannot be empty"},
+	})
+	err = AddNewContainer("", &NodeContainerConfig{Name: "c1", Config: docker.Config{Image: "img1"}})
+	c.Assert(err, check.IsNil)
+	errs, err = Validate("p1", &NodeContainerConfig{Name: "c1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.HasLen, 0)
+	errs, err = Validate("", &NodeContainerConfig{})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.DeepEquals, ValidationErrors{
+		{Field: "Name", Message: "node container config name cannot be empty"},
+	})
+}
+
+func (s *S) TestValidateForbiddenEnvsConfig(c *check.C) {
+	config.Set("docker:nodecontainer:forbidden-envs", []interface{}{"SECRET"})
+	defer config.Unset("docker:nodecontainer:forbidden-envs")
+	errs, err := Validate("", &NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1", Env: []string{"TSURU_TOKEN=abc", "SECRET=1"}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.DeepEquals, ValidationErrors{
+		{Field: "Config.Env[1]", Message: `env var "SECRET" is managed by tsuru and cannot be set`},
+	})
+}
+
+func (s *S) TestAddNewContainerAllowsManagedEnvs(c *check.C) {
+	err := AddNewContainer("", &NodeContainerConfig{
+		Name:   BsDefaultName,
+		Config: docker.Config{Image: "img1", Env: []string{"TSURU_TOKEN=abc"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = CheckForbiddenEnvs(&NodeContainerConfig{
+		Name:   BsDefaultName,
+		Config: docker.Config{Env: []string{"TSURU_TOKEN=abc"}},
+	})
+	c.Assert(err, check.FitsTypeOf, ValidationErr{})
+	c.Assert(err, check.ErrorMatches, `env var "TSURU_TOKEN" is managed by tsuru and cannot be set`)
+}
+
+func (s *S) TestAddNewContainerInvalidFields(c *check.C) {
+	err := AddNewContainer("", &NodeContainerConfig{
+		Name:   "c1",
+		Config: docker.Config{Image: "img1", Env: []string{"A B=1"}},
+	})
+	c.Assert(err, check.FitsTypeOf, ValidationErr{})
+	c.Assert(err, check.ErrorMatches, `invalid env var name "A B"`)
+}
+
+func (s *S) TestUpdateContainerInvalidFields(c *check.C) {
+	err := AddNewContainer("", &NodeContainerConfig{Name: "c1", Config: docker.Config{Image: "img1"}})
+	c.Assert(err, check.IsNil)
+	err = UpdateContainer("", &NodeContainerConfig{
+		Name:       "c1",
+		HostConfig: docker.HostConfig{Binds: []string{"/x:/"}},
+	})
+	c.Assert(err, check.FitsTypeOf, ValidationErr{})
+	c.Assert(err, check.ErrorMatches, `invalid bind destination "/", cannot mount over the container root`)
+}
+
+func (s *S) TestValidateRestartSchedule(c *check.C) {
+	errs, err := Validate("", &NodeContainerConfig{
+		Name:            BsDefaultName,
+		Config:          docker.Config{Image: "bs"},
+		RestartSchedule: "0 25 * * *",
+		RestartWindow:   -1,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.HasLen, 2)
+	c.Assert(errs[0].Field, check.Equals, "RestartSchedule")
+	c.Assert(errs[0].Message, check.Matches, `invalid restart schedule "0 25 \* \* \*": .*`)
+	c.Assert(errs[1], check.DeepEquals, FieldError{Field: "RestartWindow", Message: "node container restart window cannot be negative"})
+	errs, err = Validate("", &NodeContainerConfig{
+		Name:            "c1",
+		Config:          docker.Config{Image: "c1"},
+		RestartSchedule: "@daily",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.DeepEquals, ValidationErrors{
+		{Field: "RestartSchedule", Message: "restart schedule is only supported for big-sibling"},
+	})
+	errs, err = Validate("", &NodeContainerConfig{
+		Name:            BsDefaultName,
+		Config:          docker.Config{Image: "bs"},
+		RestartSchedule: "0 4 * * 0",
+		RestartWindow:   3600,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(errs, check.HasLen, 0)
+}
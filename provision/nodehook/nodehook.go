@@ -0,0 +1,223 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package nodehook notifies external systems, like inventories and monitoring
+// tools, about nodes being added, updated and removed, through webhooks and
+// scripts configured in node-hooks. Hooks are fired by the provisioners when
+// nodes are added, updated and removed, whether by the API, the node healer
+// or the autoscaler.
+package nodehook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const (
+	EventAdd     = "add"
+	EventUpdate  = "update"
+	EventDisable = "disable"
+	EventEnable  = "enable"
+	EventRemove  = "remove"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Payload is the JSON document sent to webhooks, in the request body, and to
+// scripts, in their standard input.
+type Payload struct {
+	Event string             `json:"event"`
+	Time  time.Time          `json:"time"`
+	Node  provision.NodeSpec `json:"node"`
+}
+
+var (
+	pending      sync.WaitGroup
+	registerOnce sync.Once
+)
+
+type shutdownWaiter struct{}
+
+func (shutdownWaiter) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func hookTimeout() time.Duration {
+	seconds, _ := config.GetInt("node-hooks:timeout")
+	if seconds <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Enabled returns whether any webhook or script is configured.
+func Enabled() bool {
+	webhooks, _ := config.GetList("node-hooks:webhooks")
+	scripts, _ := config.GetList("node-hooks:scripts")
+	return len(webhooks)+len(scripts) > 0
+}
+
+// Notify sends the payload to every configured webhook and script. Each hook
+// is bounded by node-hooks:timeout and all of them are called even when some
+// fail, the returned error describes every failure.
+func Notify(payload Payload) error {
+	if payload.Time.IsZero() {
+		payload.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	webhooks, _ := config.GetList("node-hooks:webhooks")
+	for _, url := range webhooks {
+		err = callWebhook(url, data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("webhook %s: %s", url, err))
+		}
+	}
+	scripts, _ := config.GetList("node-hooks:scripts")
+	for _, script := range scripts {
+		err = runScript(script, payload, data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("script %s: %s", script, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("node hooks failed for %s of node %s: %v", payload.Event, payload.Node.Address, errs)
+	}
+	return nil
+}
+
+// NotifyNode notifies the hooks about the event in the node in background.
+// Failures are only logged, as hooks must not fail nor delay the operation in
+// the node.
+func NotifyNode(event string, node provision.Node) {
+	if !Enabled() {
+		return
+	}
+	dispatch(event, provision.NodeToSpec(node))
+}
+
+// NodeGetter returns nodes by their address, like node provisioners.
+type NodeGetter interface {
+	GetNode(address string) (provision.Node, error)
+}
+
+// NotifyNodeAddress notifies the hooks about the event in the node with the
+// given address in background, reading the node from the provisioner to send
+// its current state.
+func NotifyNodeAddress(event string, prov NodeGetter, address string) {
+	if !Enabled() {
+		return
+	}
+	node, err := prov.GetNode(address)
+	if err != nil {
+		log.Errorf("[node hooks] unable to find node %q: %s", address, err)
+		return
+	}
+	dispatch(event, provision.NodeToSpec(node))
+}
+
+// UpdateEvent returns the event of a node update with the given options.
+func UpdateEvent(opts provision.UpdateNodeOptions) string {
+	if opts.Disable {
+		return EventDisable
+	}
+	if opts.Enable {
+		return EventEnable
+	}
+	return EventUpdate
+}
+
+// Wait blocks until the notifications running in background are done.
+func Wait() {
+	pending.Wait()
+}
+
+func dispatch(event string, node provision.NodeSpec) {
+	registerOnce.Do(func() {
+		shutdown.Register(shutdownWaiter{})
+	})
+	payload := Payload{Event: event, Time: time.Now().UTC(), Node: node}
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		err := Notify(payload)
+		if err != nil {
+			log.Errorf("[node hooks] %s", err)
+		}
+	}()
+}
+
+func callWebhook(url string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout())
+	defer cancel()
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := tsuruNet.Dial5Full60ClientNoKeepAlive.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return errors.Errorf("invalid status code %d", rsp.StatusCode)
+	}
+	return nil
+}
+
+func runScript(script string, payload Payload, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout())
+	defer cancel()
+	var output bytes.Buffer
+	parts := strings.Fields(script)
+	if len(parts) == 0 {
+		return errors.New("empty command")
+	}
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = append(os.Environ(),
+		"TSURU_NODE_EVENT="+payload.Event,
+		"TSURU_NODE_ADDRESS="+payload.Node.Address,
+		"TSURU_NODE_POOL="+payload.Node.Pool,
+	)
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return errors.Errorf("%s, output: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
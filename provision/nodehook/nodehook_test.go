@@ -0,0 +1,175 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nodehook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	server   *httptest.Server
+	payloads []Payload
+	status   int
+	dir      string
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpTest(c *check.C) {
+	s.payloads = nil
+	s.status = http.StatusOK
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		json.NewDecoder(r.Body).Decode(&p)
+		s.payloads = append(s.payloads, p)
+		w.WriteHeader(s.status)
+	}))
+	var err error
+	s.dir, err = ioutil.TempDir("", "nodehook")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.server.Close()
+	os.RemoveAll(s.dir)
+	config.Unset("node-hooks")
+}
+
+func (s *S) writeScript(c *check.C, content string) string {
+	path := filepath.Join(s.dir, "hook.sh")
+	err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+content+"\n"), 0755)
+	c.Assert(err, check.IsNil)
+	return path
+}
+
+func (s *S) TestEnabled(c *check.C) {
+	c.Assert(Enabled(), check.Equals, false)
+	config.Set("node-hooks:scripts", []string{"true"})
+	c.Assert(Enabled(), check.Equals, true)
+}
+
+func (s *S) TestNotifyWebhook(c *check.C) {
+	config.Set("node-hooks:webhooks", []string{s.server.URL})
+	node := provision.NodeSpec{Address: "http://n1:2375", Pool: "p1", Metadata: map[string]string{"zone": "z1"}}
+	err := Notify(Payload{Event: EventAdd, Node: node})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.payloads, check.HasLen, 1)
+	c.Assert(s.payloads[0].Event, check.Equals, EventAdd)
+	c.Assert(s.payloads[0].Node, check.DeepEquals, node)
+	c.Assert(s.payloads[0].Time.IsZero(), check.Equals, false)
+}
+
+type fakeNode struct {
+	address string
+	pool    string
+}
+
+func (n *fakeNode) Pool() string                           { return n.pool }
+func (n *fakeNode) IaaSID() string                         { return "" }
+func (n *fakeNode) Address() string                        { return n.address }
+func (n *fakeNode) Status() string                         { return "ready" }
+func (n *fakeNode) Metadata() map[string]string            { return map[string]string{"pool": n.pool} }
+func (n *fakeNode) MetadataNoPrefix() map[string]string    { return n.Metadata() }
+func (n *fakeNode) Units() ([]provision.Unit, error)       { return nil, nil }
+func (n *fakeNode) Provisioner() provision.NodeProvisioner { return nil }
+
+type fakeNodeGetter map[string]provision.Node
+
+func (g fakeNodeGetter) GetNode(address string) (provision.Node, error) {
+	node, ok := g[address]
+	if !ok {
+		return nil, provision.ErrNodeNotFound
+	}
+	return node, nil
+}
+
+func (s *S) TestNotifyNodeInBackground(c *check.C) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		var p Payload
+		json.NewDecoder(r.Body).Decode(&p)
+		s.payloads = append(s.payloads, p)
+	}))
+	defer server.Close()
+	config.Set("node-hooks:webhooks", []string{server.URL})
+	NotifyNode(EventRemove, &fakeNode{address: "http://n1:2375", pool: "p1"})
+	c.Assert(s.payloads, check.HasLen, 0)
+	close(block)
+	Wait()
+	c.Assert(s.payloads, check.HasLen, 1)
+	c.Assert(s.payloads[0].Event, check.Equals, EventRemove)
+	c.Assert(s.payloads[0].Node.Address, check.Equals, "http://n1:2375")
+	c.Assert(s.payloads[0].Node.Pool, check.Equals, "p1")
+	c.Assert(s.payloads[0].Time.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestNotifyNodeAddress(c *check.C) {
+	config.Set("node-hooks:webhooks", []string{s.server.URL})
+	getter := fakeNodeGetter{"http://n1:2375": &fakeNode{address: "http://n1:2375", pool: "p1"}}
+	NotifyNodeAddress(EventAdd, getter, "http://n1:2375")
+	NotifyNodeAddress(EventAdd, getter, "http://n2:2375")
+	Wait()
+	c.Assert(s.payloads, check.HasLen, 1)
+	c.Assert(s.payloads[0].Event, check.Equals, EventAdd)
+	c.Assert(s.payloads[0].Node.Metadata, check.DeepEquals, map[string]string{"pool": "p1"})
+}
+
+func (s *S) TestUpdateEvent(c *check.C) {
+	c.Assert(UpdateEvent(provision.UpdateNodeOptions{}), check.Equals, EventUpdate)
+	c.Assert(UpdateEvent(provision.UpdateNodeOptions{Disable: true}), check.Equals, EventDisable)
+	c.Assert(UpdateEvent(provision.UpdateNodeOptions{Enable: true}), check.Equals, EventEnable)
+}
+
+func (s *S) TestNotifyWebhookInvalidStatus(c *check.C) {
+	s.status = http.StatusInternalServerError
+	config.Set("node-hooks:webhooks", []string{s.server.URL})
+	err := Notify(Payload{Event: EventRemove, Node: provision.NodeSpec{Address: "http://n1:2375"}})
+	c.Assert(err, check.ErrorMatches, `(?s).*webhook .*: invalid status code 500.*`)
+	c.Assert(s.payloads, check.HasLen, 1)
+}
+
+func (s *S) TestNotifyScript(c *check.C) {
+	out := filepath.Join(s.dir, "out")
+	script := s.writeScript(c, "cat > $1; echo $TSURU_NODE_EVENT $TSURU_NODE_ADDRESS $TSURU_NODE_POOL >> $1")
+	config.Set("node-hooks:scripts", []string{script + " " + out})
+	node := provision.NodeSpec{Address: "http://n1:2375", Pool: "p1"}
+	err := Notify(Payload{Event: EventDisable, Node: node, Time: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)})
+	c.Assert(err, check.IsNil)
+	data, err := ioutil.ReadFile(out)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, `{"event":"disable","time":"2018-01-02T03:04:05Z","node":{"Address":"http://n1:2375","IaaSID":"","Metadata":null,"Status":"","Pool":"p1","Provisioner":""}}`+"disable http://n1:2375 p1\n")
+}
+
+func (s *S) TestNotifyScriptFailureCallsOtherHooks(c *check.C) {
+	config.Set("node-hooks:webhooks", []string{s.server.URL})
+	script := s.writeScript(c, "echo failed; exit 1")
+	config.Set("node-hooks:scripts", []string{script})
+	err := Notify(Payload{Event: EventAdd, Node: provision.NodeSpec{Address: "http://n1:2375"}})
+	c.Assert(err, check.ErrorMatches, `.*script .*: exit status 1, output: failed.*`)
+	c.Assert(s.payloads, check.HasLen, 1)
+}
+
+func (s *S) TestNotifyScriptTimeout(c *check.C) {
+	config.Set("node-hooks:timeout", 1)
+	config.Set("node-hooks:scripts", []string{"sleep 5"})
+	t0 := time.Now()
+	err := Notify(Payload{Event: EventAdd, Node: provision.NodeSpec{Address: "http://n1:2375"}})
+	c.Assert(err, check.ErrorMatches, `.*context deadline exceeded.*`)
+	c.Assert(time.Since(t0) < 5*time.Second, check.Equals, true)
+}
@@ -89,6 +89,10 @@ func SetPoolConstraint(c *PoolConstraint) error {
 	if !isValid {
 		return ErrInvalidConstraintType
 	}
+	err = validateDedicatedPools(c)
+	if err != nil {
+		return err
+	}
 	if len(c.Values) == 0 || (len(c.Values) == 1 && c.Values[0] == "") {
 		errRem := conn.PoolsConstraints().Remove(bson.M{"poolexpr": c.PoolExpr, "field": c.Field})
 		if errRem != mgo.ErrNotFound {
@@ -123,6 +127,20 @@ func appendPoolConstraint(poolExpr string, field poolConstraintType, values ...s
 		return err
 	}
 	defer conn.Close()
+	var current PoolConstraint
+	err = conn.PoolsConstraints().Find(bson.M{"poolexpr": poolExpr, "field": field}).One(&current)
+	if err != nil && err != mgo.ErrNotFound {
+		return err
+	}
+	err = validateDedicatedPools(&PoolConstraint{
+		PoolExpr:  poolExpr,
+		Field:     field,
+		Values:    append(current.Values, values...),
+		Blacklist: current.Blacklist,
+	})
+	if err != nil {
+		return err
+	}
 	_, err = conn.PoolsConstraints().Upsert(
 		bson.M{"poolexpr": poolExpr, "field": field},
 		bson.M{"$addToSet": bson.M{"values": bson.M{"$each": values}}},
@@ -177,6 +195,12 @@ func getConstraintsForPool(pool string, fields ...poolConstraintType) (map[poolC
 	if err != nil {
 		return nil, err
 	}
+	return mergeConstraintsForPool(pool, constraints)
+}
+
+// mergeConstraintsForPool returns, for each field, the most specific
+// constraint among the given ones matching the pool.
+func mergeConstraintsForPool(pool string, constraints []*PoolConstraint) (map[poolConstraintType]*PoolConstraint, error) {
 	var matches []*PoolConstraint
 	for _, c := range constraints {
 		pattern := exprAsGlobPattern(c.PoolExpr)
@@ -0,0 +1,118 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+var (
+	ErrDedicatedPoolPublic     = &tsuruErrors.ValidationError{Message: "Dedicated pool can't be public or default."}
+	ErrDedicatedPoolSingleTeam = &tsuruErrors.ValidationError{Message: "Dedicated pool must be restricted to a single team, without wildcards or blacklists."}
+)
+
+// validateDedicated checks that a dedicated pool is restricted to at most one
+// team, given by the team constraint, and that every app in the pool belongs
+// to this team, so apps from different teams never share its nodes.
+func (p *Pool) validateDedicated(teamConstraint *PoolConstraint) error {
+	if !p.Dedicated {
+		return nil
+	}
+	if p.Default {
+		return ErrDedicatedPoolPublic
+	}
+	var teams []string
+	if teamConstraint != nil {
+		if teamConstraint.AllowsAll() {
+			return ErrDedicatedPoolPublic
+		}
+		if teamConstraint.Blacklist || len(teamConstraint.Values) > 1 {
+			return ErrDedicatedPoolSingleTeam
+		}
+		for _, v := range teamConstraint.Values {
+			if strings.Contains(v, "*") {
+				return ErrDedicatedPoolSingleTeam
+			}
+		}
+		teams = teamConstraint.Values
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var otherTeams []string
+	err = conn.Apps().Find(bson.M{"pool": p.Name, "teamowner": bson.M{"$nin": teams}}).Distinct("teamowner", &otherTeams)
+	if err != nil {
+		return err
+	}
+	if len(otherTeams) > 0 {
+		msg := fmt.Sprintf("Dedicated pool %q has apps owned by other teams: %s.", p.Name, strings.Join(otherTeams, ", "))
+		return &tsuruErrors.ValidationError{Message: msg}
+	}
+	return nil
+}
+
+// validateDedicatedPools checks whether every dedicated pool remains valid
+// after setting the given constraint.
+func validateDedicatedPools(c *PoolConstraint) error {
+	if c.Field != ConstraintTypeTeam {
+		return nil
+	}
+	pools, err := listPools(bson.M{"dedicated": true})
+	if err != nil || len(pools) == 0 {
+		return err
+	}
+	constraints, err := ListPoolsConstraints(bson.M{"field": ConstraintTypeTeam})
+	if err != nil {
+		return err
+	}
+	candidates := make([]*PoolConstraint, 0, len(constraints)+1)
+	for _, current := range constraints {
+		if current.PoolExpr != c.PoolExpr {
+			candidates = append(candidates, current)
+		}
+	}
+	if len(c.Values) > 0 && !(len(c.Values) == 1 && c.Values[0] == "") {
+		candidates = append(candidates, c)
+	}
+	for _, p := range pools {
+		if match, _ := regexp.MatchString(exprAsGlobPattern(c.PoolExpr), p.Name); !match {
+			continue
+		}
+		merged, err := mergeConstraintsForPool(p.Name, candidates)
+		if err != nil {
+			return err
+		}
+		err = p.validateDedicated(merged[ConstraintTypeTeam])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckDedicated returns an error if the pool is dedicated to a team other
+// than the given one.
+func (p *Pool) CheckDedicated(team string) error {
+	if !p.Dedicated {
+		return nil
+	}
+	constraints, err := getConstraintsForPool(p.Name, ConstraintTypeTeam)
+	if err != nil {
+		return err
+	}
+	if !constraints[ConstraintTypeTeam].checkExact(team) {
+		msg := fmt.Sprintf("Pool %q is dedicated to another team, apps from team %q can't run on it.", p.Name, team)
+		return &tsuruErrors.ValidationError{Message: msg}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"github.com/globalsign/mgo/bson"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestAddPoolDedicatedPublic(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1", Dedicated: true, Public: true})
+	c.Assert(err, check.Equals, ErrDedicatedPoolPublic)
+	err = AddPool(AddPoolOptions{Name: "pool1", Dedicated: true})
+	c.Assert(err, check.IsNil)
+	p, err := GetPoolByName("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Dedicated, check.Equals, true)
+}
+
+func (s *S) TestAddTeamsToDedicatedPool(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1", Dedicated: true})
+	c.Assert(err, check.IsNil)
+	err = AddTeamsToPool("pool1", []string{"ateam"})
+	c.Assert(err, check.IsNil)
+	err = AddTeamsToPool("pool1", []string{"test"})
+	c.Assert(err, check.Equals, ErrDedicatedPoolSingleTeam)
+	teams, err := getExactConstraintForPool("pool1", ConstraintTypeTeam)
+	c.Assert(err, check.IsNil)
+	c.Assert(teams.Values, check.DeepEquals, []string{"ateam"})
+}
+
+func (s *S) TestSetPoolConstraintDedicatedPool(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1", Dedicated: true})
+	c.Assert(err, check.IsNil)
+	err = SetPoolConstraint(&PoolConstraint{PoolExpr: "pool*", Field: ConstraintTypeTeam, Values: []string{"*"}})
+	c.Assert(err, check.Equals, ErrDedicatedPoolPublic)
+	err = SetPoolConstraint(&PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeTeam, Values: []string{"ateam"}})
+	c.Assert(err, check.IsNil)
+	err = SetPoolConstraint(&PoolConstraint{PoolExpr: "pool*", Field: ConstraintTypeTeam, Values: []string{"*"}})
+	c.Assert(err, check.IsNil)
+	err = SetPoolConstraint(&PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeTeam, Values: []string{"ateam"}, Blacklist: true})
+	c.Assert(err, check.Equals, ErrDedicatedPoolSingleTeam)
+	err = SetPoolConstraint(&PoolConstraint{PoolExpr: "pool1", Field: ConstraintTypeRouter, Values: []string{"*"}})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestPoolUpdateDedicated(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = AddTeamsToPool("pool1", []string{"ateam", "test"})
+	c.Assert(err, check.IsNil)
+	err = s.storage.Apps().Insert(
+		bson.M{"name": "app1", "pool": "pool1", "teamowner": "ateam"},
+		bson.M{"name": "app2", "pool": "pool1", "teamowner": "test"},
+	)
+	c.Assert(err, check.IsNil)
+	dedicated := true
+	err = PoolUpdate("pool1", UpdatePoolOptions{Dedicated: &dedicated})
+	c.Assert(err, check.Equals, ErrDedicatedPoolSingleTeam)
+	err = RemoveTeamsFromPool("pool1", []string{"test"})
+	c.Assert(err, check.IsNil)
+	err = PoolUpdate("pool1", UpdatePoolOptions{Dedicated: &dedicated})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `Dedicated pool "pool1" has apps owned by other teams: test.`)
+	err = s.storage.Apps().Remove(bson.M{"name": "app2"})
+	c.Assert(err, check.IsNil)
+	err = PoolUpdate("pool1", UpdatePoolOptions{Dedicated: &dedicated})
+	c.Assert(err, check.IsNil)
+	p, err := GetPoolByName("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Dedicated, check.Equals, true)
+	public := true
+	err = PoolUpdate("pool1", UpdatePoolOptions{Public: &public})
+	c.Assert(err, check.Equals, ErrDedicatedPoolPublic)
+}
+
+func (s *S) TestCheckDedicated(c *check.C) {
+	p := Pool{Name: "pool1"}
+	c.Assert(p.CheckDedicated("test"), check.IsNil)
+	err := AddPool(AddPoolOptions{Name: "pool1", Dedicated: true})
+	c.Assert(err, check.IsNil)
+	err = AddTeamsToPool("pool1", []string{"ateam"})
+	c.Assert(err, check.IsNil)
+	p.Dedicated = true
+	c.Assert(p.CheckDedicated("ateam"), check.IsNil)
+	err = p.CheckDedicated("test")
+	c.Assert(err, check.ErrorMatches, `Pool "pool1" is dedicated to another team, apps from team "test" can't run on it.`)
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const (
+	maxDNSNameservers = 3
+	maxDNSSearches    = 6
+	maxDNSNdots       = 15
+)
+
+var dnsSearchRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.?$`)
+
+// DNSConfig holds the resolver settings of the app units in a pool,
+// replacing the ones inherited from the nodes.
+type DNSConfig struct {
+	Nameservers []string `json:"nameservers,omitempty" bson:",omitempty"`
+	Searches    []string `json:"searches,omitempty" bson:",omitempty"`
+	Ndots       *int     `json:"ndots,omitempty" bson:",omitempty"`
+}
+
+// Options returns the resolver options of the config, in the resolv.conf
+// format.
+func (c *DNSConfig) Options() []string {
+	if c == nil || c.Ndots == nil {
+		return nil
+	}
+	return []string{"ndots:" + strconv.Itoa(*c.Ndots)}
+}
+
+func (c *DNSConfig) Validate() error {
+	if len(c.Nameservers) == 0 && len(c.Searches) == 0 && c.Ndots == nil {
+		return &tsuruErrors.ValidationError{Message: "dns config must have at least one nameserver, search domain or ndots"}
+	}
+	if len(c.Nameservers) > maxDNSNameservers {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("dns config must have at most %d nameservers", maxDNSNameservers)}
+	}
+	for _, ns := range c.Nameservers {
+		if net.ParseIP(ns) == nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid dns nameserver %q, must be an IP address", ns)}
+		}
+	}
+	if len(c.Searches) > maxDNSSearches {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("dns config must have at most %d search domains", maxDNSSearches)}
+	}
+	for _, search := range c.Searches {
+		if !dnsSearchRegexp.MatchString(search) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid dns search domain %q", search)}
+		}
+	}
+	if c.Ndots != nil && (*c.Ndots < 0 || *c.Ndots > maxDNSNdots) {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid dns ndots %d, must be between 0 and %d", *c.Ndots, maxDNSNdots)}
+	}
+	return nil
+}
+
+// SetDNSConfig sets the DNS config of the pool, a nil config removes it.
+// Running units only use the new config after being restarted.
+func SetDNSConfig(name string, c *DNSConfig) error {
+	var update bson.M
+	if c == nil {
+		update = bson.M{"$unset": bson.M{"dns": ""}}
+	} else {
+		err := c.Validate()
+		if err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"dns": c}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Pools().UpdateId(name, update)
+	if err == mgo.ErrNotFound {
+		return ErrPoolNotFound
+	}
+	return err
+}
+
+// DNSConfigForPool returns the DNS config of the pool, or nil when the pool
+// has none or doesn't exist.
+func DNSConfigForPool(name string) (*DNSConfig, error) {
+	if name == "" {
+		return nil, nil
+	}
+	p, err := GetPoolByName(name)
+	if err == ErrPoolNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.DNS, nil
+}
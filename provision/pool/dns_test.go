@@ -0,0 +1,80 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDNSConfigValidate(c *check.C) {
+	one, tooMany := 1, 16
+	tests := []struct {
+		conf DNSConfig
+		err  string
+	}{
+		{DNSConfig{Nameservers: []string{"10.0.0.10", "fd00::10"}}, ""},
+		{DNSConfig{Searches: []string{"corp.example.com", "svc.local."}}, ""},
+		{DNSConfig{Ndots: &one}, ""},
+		{DNSConfig{}, "dns config must have at least one nameserver, search domain or ndots"},
+		{DNSConfig{Nameservers: []string{"dns.corp"}}, `invalid dns nameserver "dns.corp", must be an IP address`},
+		{DNSConfig{Nameservers: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}}, "dns config must have at most 3 nameservers"},
+		{DNSConfig{Searches: []string{"corp example"}}, `invalid dns search domain "corp example"`},
+		{DNSConfig{Searches: []string{"a", "b", "c", "d", "e", "f", "g"}}, "dns config must have at most 6 search domains"},
+		{DNSConfig{Ndots: &tooMany}, "invalid dns ndots 16, must be between 0 and 15"},
+	}
+	for _, tt := range tests {
+		err := tt.conf.Validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil)
+			continue
+		}
+		c.Check(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Check(err, check.ErrorMatches, tt.err)
+	}
+}
+
+func (s *S) TestDNSConfigOptions(c *check.C) {
+	var nilConf *DNSConfig
+	c.Assert(nilConf.Options(), check.IsNil)
+	c.Assert((&DNSConfig{}).Options(), check.IsNil)
+	ndots := 2
+	c.Assert((&DNSConfig{Ndots: &ndots}).Options(), check.DeepEquals, []string{"ndots:2"})
+}
+
+func (s *S) TestSetDNSConfig(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	ndots := 2
+	conf := &DNSConfig{Nameservers: []string{"10.0.0.10"}, Searches: []string{"corp.example.com"}, Ndots: &ndots}
+	err = SetDNSConfig("pool1", conf)
+	c.Assert(err, check.IsNil)
+	dns, err := DNSConfigForPool("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dns, check.DeepEquals, conf)
+	err = SetDNSConfig("pool1", nil)
+	c.Assert(err, check.IsNil)
+	dns, err = DNSConfigForPool("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dns, check.IsNil)
+}
+
+func (s *S) TestSetDNSConfigInvalid(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetDNSConfig("pool1", &DNSConfig{Nameservers: []string{"x"}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = SetDNSConfig("notfound", &DNSConfig{Nameservers: []string{"10.0.0.10"}})
+	c.Assert(err, check.Equals, ErrPoolNotFound)
+}
+
+func (s *S) TestDNSConfigForPoolNotFound(c *check.C) {
+	dns, err := DNSConfigForPool("notfound")
+	c.Assert(err, check.IsNil)
+	c.Assert(dns, check.IsNil)
+	dns, err = DNSConfigForPool("")
+	c.Assert(err, check.IsNil)
+	c.Assert(dns, check.IsNil)
+}
@@ -8,11 +8,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/db"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/provision"
@@ -23,6 +25,10 @@ import (
 	"github.com/tsuru/tsuru/validation"
 )
 
+// privateEnvValue replaces the values of private env vars when pools are
+// listed.
+const privateEnvValue = "*** (private variable)"
+
 var (
 	ErrPublicDefaultPoolCantHaveTeams = errors.New("Public/Default pool can't have teams.")
 	ErrDefaultPoolAlreadyExists       = errors.New("Default pool already exists.")
@@ -38,6 +44,18 @@ type Pool struct {
 	Name        string `bson:"_id"`
 	Default     bool
 	Provisioner string
+	// Dedicated pools are restricted to a single team, only apps from this
+	// team may run on their nodes.
+	Dedicated bool
+	// TLSPolicy is applied by routers to apps in the pool without a policy
+	// of their own.
+	TLSPolicy *router.TLSPolicy `bson:",omitempty"`
+	// Envs are injected in every unit of the apps in the pool, env vars set
+	// in the app take precedence over them.
+	Envs []bind.EnvVar `bson:",omitempty"`
+	// DNS overrides the resolver settings of the units of the apps in the
+	// pool.
+	DNS *DNSConfig `bson:",omitempty"`
 }
 
 type AddPoolOptions struct {
@@ -46,12 +64,14 @@ type AddPoolOptions struct {
 	Default     bool
 	Force       bool
 	Provisioner string
+	Dedicated   bool
 }
 
 type UpdatePoolOptions struct {
-	Default *bool
-	Public  *bool
-	Force   bool
+	Default   *bool
+	Public    *bool
+	Dedicated *bool
+	Force     bool
 }
 
 func (p *Pool) GetProvisioner() (provision.Provisioner, error) {
@@ -238,6 +258,23 @@ func (p *Pool) MarshalJSON() ([]byte, error) {
 	result["public"] = teams.AllowsAll()
 	result["default"] = p.Default
 	result["provisioner"] = p.Provisioner
+	result["dedicated"] = p.Dedicated
+	if p.TLSPolicy != nil {
+		result["tlsPolicy"] = p.TLSPolicy
+	}
+	if len(p.Envs) > 0 {
+		envs := make([]bind.EnvVar, len(p.Envs))
+		for i, e := range p.Envs {
+			if !e.Public {
+				e.Value = privateEnvValue
+			}
+			envs[i] = e
+		}
+		result["envs"] = envs
+	}
+	if p.DNS != nil {
+		result["dns"] = p.DNS
+	}
 	result["teams"] = resolvedConstraints[ConstraintTypeTeam]
 	result["allowed"] = resolvedConstraints
 	return json.Marshal(&result)
@@ -257,10 +294,13 @@ func (p *Pool) validate() error {
 }
 
 func AddPool(opts AddPoolOptions) error {
-	pool := Pool{Name: opts.Name, Default: opts.Default, Provisioner: opts.Provisioner}
+	pool := Pool{Name: opts.Name, Default: opts.Default, Provisioner: opts.Provisioner, Dedicated: opts.Dedicated}
 	if err := pool.validate(); err != nil {
 		return err
 	}
+	if opts.Dedicated && (opts.Public || opts.Default) {
+		return ErrDedicatedPoolPublic
+	}
 	conn, err := db.Conn()
 	if err != nil {
 		return err
@@ -482,7 +522,11 @@ func PoolUpdate(name string, opts UpdatePoolOptions) error {
 		return err
 	}
 	defer conn.Close()
-	_, err = GetPoolByName(name)
+	p, err := GetPoolByName(name)
+	if err != nil {
+		return err
+	}
+	err = p.validateUpdate(opts)
 	if err != nil {
 		return err
 	}
@@ -496,6 +540,9 @@ func PoolUpdate(name string, opts UpdatePoolOptions) error {
 	if opts.Default != nil {
 		query["default"] = *opts.Default
 	}
+	if opts.Dedicated != nil {
+		query["dedicated"] = *opts.Dedicated
+	}
 	if (opts.Public != nil && *opts.Public) || (opts.Default != nil && *opts.Default) {
 		errConstraint := SetPoolConstraint(&PoolConstraint{PoolExpr: name, Field: ConstraintTypeTeam, Values: []string{"*"}})
 		if errConstraint != nil {
@@ -518,6 +565,135 @@ func PoolUpdate(name string, opts UpdatePoolOptions) error {
 	return err
 }
 
+// SetTLSPolicy sets the TLS policy of the pool, a nil policy removes it.
+func SetTLSPolicy(name string, policy *router.TLSPolicy) error {
+	var update bson.M
+	if policy == nil {
+		update = bson.M{"$unset": bson.M{"tlspolicy": ""}}
+	} else {
+		err := policy.Validate()
+		if err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"tlspolicy": policy}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Pools().UpdateId(name, update)
+	if err == mgo.ErrNotFound {
+		return ErrPoolNotFound
+	}
+	return err
+}
+
+// SetEnvs adds the given env vars to the pool, replacing the values of env
+// vars already set.
+func SetEnvs(name string, envs []bind.EnvVar) error {
+	if len(envs) == 0 {
+		return nil
+	}
+	p, err := GetPoolByName(name)
+	if err != nil {
+		return err
+	}
+	merged := make(map[string]bind.EnvVar, len(p.Envs)+len(envs))
+	for _, e := range p.Envs {
+		merged[e.Name] = e
+	}
+	for _, e := range envs {
+		if e.Name == "" {
+			return &tsuruErrors.ValidationError{Message: "env var name is required"}
+		}
+		if !validation.ValidateEnvName(e.Name) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid env var name %q", e.Name)}
+		}
+		merged[e.Name] = e
+	}
+	return updateEnvs(name, merged)
+}
+
+// UnsetEnvs removes the env vars with the given names from the pool.
+func UnsetEnvs(name string, names []string) error {
+	p, err := GetPoolByName(name)
+	if err != nil {
+		return err
+	}
+	merged := make(map[string]bind.EnvVar, len(p.Envs))
+	for _, e := range p.Envs {
+		merged[e.Name] = e
+	}
+	for _, n := range names {
+		delete(merged, n)
+	}
+	return updateEnvs(name, merged)
+}
+
+func updateEnvs(name string, envs map[string]bind.EnvVar) error {
+	var update bson.M
+	if len(envs) == 0 {
+		update = bson.M{"$unset": bson.M{"envs": ""}}
+	} else {
+		list := make([]bind.EnvVar, 0, len(envs))
+		for _, e := range envs {
+			list = append(list, e)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+		update = bson.M{"$set": bson.M{"envs": list}}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Pools().UpdateId(name, update)
+	if err == mgo.ErrNotFound {
+		return ErrPoolNotFound
+	}
+	return err
+}
+
+// validateUpdate checks whether the pool remains valid if dedicated after
+// applying the given update.
+func (p *Pool) validateUpdate(opts UpdatePoolOptions) error {
+	updated := *p
+	if opts.Dedicated != nil {
+		updated.Dedicated = *opts.Dedicated
+	}
+	if opts.Default != nil {
+		updated.Default = *opts.Default
+	}
+	if !updated.Dedicated {
+		return nil
+	}
+	if opts.Public != nil && *opts.Public {
+		return ErrDedicatedPoolPublic
+	}
+	constraints, err := getConstraintsForPool(p.Name, ConstraintTypeTeam)
+	if err != nil {
+		return err
+	}
+	teamConstraint := constraints[ConstraintTypeTeam]
+	if teamConstraint != nil && teamConstraint.PoolExpr == p.Name && opts.Public != nil && !*opts.Public {
+		teamConstraint = &PoolConstraint{PoolExpr: p.Name, Field: ConstraintTypeTeam, Values: withoutValue(teamConstraint.Values, "*")}
+	}
+	return updated.validateDedicated(teamConstraint)
+}
+
+func withoutValue(values []string, value string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
 func exprAsGlobPattern(expr string) string {
 	parts := strings.Split(expr, "*")
 	for i := range parts {
@@ -5,11 +5,13 @@
 package pool
 
 import (
+	"encoding/json"
 	"sort"
 	"testing"
 
 	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/db/dbtest"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
@@ -371,6 +373,76 @@ func (s *S) TestPoolUpdate(c *check.C) {
 	c.Assert(constraint.AllowsAll(), check.Equals, true)
 }
 
+func (s *S) TestSetEnvs(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs("pool1", []bind.EnvVar{
+		{Name: "REGION", Value: "us-east", Public: true},
+		{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+	})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs("pool1", []bind.EnvVar{{Name: "REGION", Value: "us-west", Public: true}})
+	c.Assert(err, check.IsNil)
+	p, err := GetPoolByName("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Envs, check.DeepEquals, []bind.EnvVar{
+		{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+		{Name: "REGION", Value: "us-west", Public: true},
+	})
+}
+
+func (s *S) TestSetEnvsInvalid(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs("pool1", []bind.EnvVar{{Value: "x"}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = SetEnvs("pool1", []bind.EnvVar{{Name: "MY-VAR", Value: "x"}})
+	c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: `invalid env var name "MY-VAR"`})
+	err = SetEnvs("notfound", []bind.EnvVar{{Name: "A", Value: "x"}})
+	c.Assert(err, check.Equals, ErrPoolNotFound)
+}
+
+func (s *S) TestPoolMarshalJSONMasksPrivateEnvs(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs("pool1", []bind.EnvVar{
+		{Name: "REGION", Value: "us-east", Public: true},
+		{Name: "TOKEN", Value: "secret"},
+	})
+	c.Assert(err, check.IsNil)
+	p, err := GetPoolByName("pool1")
+	c.Assert(err, check.IsNil)
+	data, err := json.Marshal(p)
+	c.Assert(err, check.IsNil)
+	var result struct {
+		Envs []bind.EnvVar
+	}
+	err = json.Unmarshal(data, &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Envs, check.DeepEquals, []bind.EnvVar{
+		{Name: "REGION", Value: "us-east", Public: true},
+		{Name: "TOKEN", Value: "*** (private variable)"},
+	})
+	c.Assert(p.Envs[1].Value, check.Equals, "secret")
+}
+
+func (s *S) TestUnsetEnvs(c *check.C) {
+	err := AddPool(AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetEnvs("pool1", []bind.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}})
+	c.Assert(err, check.IsNil)
+	err = UnsetEnvs("pool1", []string{"A", "C"})
+	c.Assert(err, check.IsNil)
+	p, err := GetPoolByName("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Envs, check.DeepEquals, []bind.EnvVar{{Name: "B", Value: "2"}})
+	err = UnsetEnvs("pool1", []string{"B"})
+	c.Assert(err, check.IsNil)
+	p, err = GetPoolByName("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Envs, check.IsNil)
+}
+
 func (s *S) TestPoolUpdateToDefault(c *check.C) {
 	opts := AddPoolOptions{
 		Name:    "pool1",
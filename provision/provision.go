@@ -135,7 +135,7 @@ const (
 )
 
 // Unit represents a provision unit. Can be a machine, container or anything
-// IP-addressable.
+// IP-addressable. Node and Zone describe where the unit is placed, when known.
 type Unit struct {
 	ID          string
 	Name        string
@@ -145,6 +145,9 @@ type Unit struct {
 	IP          string
 	Status      Status
 	Address     *url.URL
+	Node        string    `json:",omitempty"`
+	Zone        string    `json:",omitempty"`
+	CreatedAt   time.Time `json:"-"`
 }
 
 // GetName returns the name of the unit.
@@ -160,17 +163,27 @@ func (u *Unit) GetIp() string {
 func (u *Unit) MarshalJSON() ([]byte, error) {
 	type UnitForMarshal Unit
 	host, port, _ := net.SplitHostPort(u.Address.Host)
+	var createdAt *time.Time
+	var age int64
+	if !u.CreatedAt.IsZero() {
+		createdAt = &u.CreatedAt
+		age = int64(time.Since(u.CreatedAt) / time.Second)
+	}
 	// New fields added for compatibility with old routes returning containers.
 	return json.Marshal(&struct {
 		*UnitForMarshal
-		HostAddr string
-		HostPort string
-		IP       string
+		HostAddr  string
+		HostPort  string
+		IP        string
+		CreatedAt *time.Time `json:",omitempty"`
+		Age       int64      `json:",omitempty"`
 	}{
 		UnitForMarshal: (*UnitForMarshal)(u),
 		HostAddr:       host,
 		HostPort:       port,
 		IP:             u.IP,
+		CreatedAt:      createdAt,
+		Age:            age,
 	})
 }
 
@@ -279,6 +292,13 @@ type BuilderDockerClient interface {
 	SetTimeout(timeout time.Duration)
 }
 
+// BuildSlotDockerClient is a docker client able to reserve a slot for a
+// build in the nodes used for builds, queueing the build while all of them
+// are busy. The returned function releases the slot.
+type BuildSlotDockerClient interface {
+	AcquireBuildSlot(w io.Writer) (func(), error)
+}
+
 type ExecDockerClient interface {
 	CreateExec(opts docker.CreateExecOptions) (*docker.Exec, error)
 	StartExec(execId string, opts docker.StartExecOptions) error
@@ -306,6 +326,12 @@ type BuilderDeployKubeClient interface {
 	GetClient(App) (BuilderKubeClient, error)
 }
 
+// PlatformBuilderDockerClient is a provisioner able to return a client bound
+// to the nodes designated for building platform images.
+type PlatformBuilderDockerClient interface {
+	GetPlatformBuildClient() (BuilderDockerClient, error)
+}
+
 // Provisioner is the basic interface of this package.
 //
 // Any tsuru provisioner must implement this interface in order to provision
@@ -355,6 +381,64 @@ type Provisioner interface {
 	RegisterUnit(App, string, map[string]interface{}) error
 }
 
+// UnitsRemovalDryRunProvisioner is a provisioner able to tell which units
+// would be removed by RemoveUnits, without removing them.
+type UnitsRemovalDryRunProvisioner interface {
+	RemoveUnitsDry(App, uint, string) ([]Unit, error)
+}
+
+// OrphanResource is a resource managed by a provisioner that is no longer
+// tracked by tsuru, like a unit whose app was removed.
+type OrphanResource struct {
+	Kind string
+	Name string
+	App  string
+	Node string
+}
+
+// OrphanProvisioner is a provisioner able to find the resources it manages
+// that are no longer tracked by tsuru, and to remove them.
+type OrphanProvisioner interface {
+	OrphanResources() ([]OrphanResource, error)
+	RemoveOrphanResource(OrphanResource) error
+}
+
+const (
+	DriftMissing = "missing"
+	DriftStopped = "stopped"
+	DriftImage   = "image"
+	DriftEnv     = "env"
+)
+
+// AppDrift is a mismatch between the state tsuru expects for a unit and the
+// state it actually has in the provisioner. For env drifts, Expected and
+// Actual hold hashes of the envs instead of their values.
+type AppDrift struct {
+	Unit     string `json:"unit"`
+	Process  string `json:"process"`
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// DriftProvisioner is a provisioner able to compare the units tsuru
+// expects for an app with the ones actually running.
+type DriftProvisioner interface {
+	AppDrift(App) ([]AppDrift, error)
+}
+
+// DryRunReport describes what a destructive operation would remove or move
+// if it was not a dry run.
+type DryRunReport struct {
+	Units   []Unit   `json:"units,omitempty"`
+	Moved   []Unit   `json:"moved,omitempty"`
+	Volumes []string `json:"volumes,omitempty"`
+	Routers []string `json:"routers,omitempty"`
+	CNames  []string `json:"cnames,omitempty"`
+	Nodes   []string `json:"nodes,omitempty"`
+	Pools   []string `json:"pools,omitempty"`
+}
+
 // ShellProvisioner is a provisioner that allows opening a shell to existing
 // units.
 type ShellProvisioner interface {
@@ -458,6 +542,22 @@ type NodeProvisioner interface {
 	NodeForNodeData(NodeStatusData) (Node, error)
 }
 
+// NodeUnitsProvisioner is a provisioner able to list the units of all its
+// nodes at once, instead of listing them node by node.
+type NodeUnitsProvisioner interface {
+	// UnitsByNode returns the units in the provisioner grouped by the address
+	// of the node running them.
+	UnitsByNode() (map[string][]Unit, error)
+}
+
+// NodeUsageProvisioner is a provisioner able to report the real memory usage
+// of its nodes.
+type NodeUsageProvisioner interface {
+	// NodesMemoryUsage returns the memory in use, in bytes, by the nodes with
+	// the given addresses. Nodes without a recent measure are not included.
+	NodesMemoryUsage(addresses []string) (map[string]int64, error)
+}
+
 type RebalanceNodesOptions struct {
 	Event          *event.Event
 	Pool           string
@@ -476,6 +576,47 @@ type NodeContainerProvisioner interface {
 	RemoveNodeContainer(name string, pool string, writer io.Writer) error
 }
 
+// NodeContainerDryRunProvisioner is a provisioner able to report, without
+// touching any node, the changes made by upgrading a node container.
+type NodeContainerDryRunProvisioner interface {
+	UpgradeNodeContainerDry(name string, pool string, writer io.Writer) error
+}
+
+type NodeContainerLogsOptions struct {
+	Writer io.Writer
+	Lines  int
+	Follow bool
+}
+
+// NodeContainerLogsProvisioner is a provisioner able to fetch the logs of
+// node containers running in its nodes.
+type NodeContainerLogsProvisioner interface {
+	NodeContainerLogs(node Node, name string, opts NodeContainerLogsOptions) error
+}
+
+// NodeContainerStatus is the state of a node container in a node. When the
+// container doesn't match the stored config, the differing items are listed
+// in Mismatches. Envs are identified by name only.
+type NodeContainerStatus struct {
+	Node          string    `json:"node"`
+	Pool          string    `json:"pool"`
+	Exists        bool      `json:"exists"`
+	State         string    `json:"state,omitempty"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	Uptime        string    `json:"uptime,omitempty"`
+	Image         string    `json:"image,omitempty"`
+	ImageDigest   string    `json:"imageDigest,omitempty"`
+	ConfigMatches bool      `json:"configMatches"`
+	Mismatches    []string  `json:"mismatches,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// NodeContainerStatusProvisioner is a provisioner able to report the state
+// of node containers in its nodes.
+type NodeContainerStatusProvisioner interface {
+	NodeContainerStatus(name string, pool string) ([]NodeContainerStatus, error)
+}
+
 // UnitFinderProvisioner is a provisioner that allows finding a specific unit
 // by its id. New provisioners should not implement this interface, this was
 // only used during events format migration and is exclusive to docker
@@ -499,6 +640,12 @@ type CleanImageProvisioner interface {
 	CleanImage(appName string, image string) error
 }
 
+// ImagePrepullProvisioner is a provisioner able to pull an image in the nodes
+// of a pool ahead of its use by units.
+type ImagePrepullProvisioner interface {
+	PrepullImage(pool, image string, w io.Writer) error
+}
+
 type Node interface {
 	Pool() string
 	IaaSID() string
@@ -521,6 +668,20 @@ type NodeExtraData interface {
 	ExtraData() map[string]string
 }
 
+// NodeCircuitBreaker is the state of the circuit breaker guarding the
+// operations in a node, which fail fast while it's open.
+type NodeCircuitBreaker struct {
+	State     string    `json:"state"`
+	Failures  int       `json:"failures"`
+	OpenUntil time.Time `json:"openUntil,omitempty"`
+}
+
+// NodeCircuitBreakerChecker is a node whose operations are guarded by a
+// circuit breaker.
+type NodeCircuitBreakerChecker interface {
+	CircuitBreaker() NodeCircuitBreaker
+}
+
 type NodeHealthChecker interface {
 	Node
 	FailureCount() int
@@ -671,6 +832,9 @@ func (e *Error) Error() string {
 type TsuruYamlData struct {
 	Hooks       TsuruYamlHooks       `bson:",omitempty"`
 	Healthcheck TsuruYamlHealthcheck `bson:",omitempty"`
+	// Dependencies maps each process to the processes that must be healthy
+	// before it starts.
+	Dependencies map[string][]string `bson:",omitempty"`
 }
 
 type TsuruYamlHooks struct {
@@ -5,9 +5,12 @@
 package provision
 
 import (
+	"encoding/json"
 	"errors"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"gopkg.in/check.v1"
 )
@@ -124,6 +127,36 @@ func (ProvisionSuite) TestUnitGetIp(c *check.C) {
 	c.Assert(u.IP, check.Equals, u.GetIp())
 }
 
+func (ProvisionSuite) TestUnitMarshalJSONPlacement(c *check.C) {
+	u := Unit{
+		ID:        "u1",
+		IP:        "10.3.3.1",
+		Address:   &url.URL{Host: "10.3.3.1:8080"},
+		Node:      "http://10.3.3.1:2375",
+		Zone:      "zone-a",
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+	data, err := json.Marshal(&u)
+	c.Assert(err, check.IsNil)
+	var result map[string]interface{}
+	err = json.Unmarshal(data, &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result["Node"], check.Equals, "http://10.3.3.1:2375")
+	c.Assert(result["Zone"], check.Equals, "zone-a")
+	c.Assert(result["CreatedAt"], check.NotNil)
+	c.Assert(result["Age"].(float64) >= 3600, check.Equals, true)
+	u = Unit{ID: "u2", Address: &url.URL{Host: "10.3.3.2:8080"}}
+	data, err = json.Marshal(&u)
+	c.Assert(err, check.IsNil)
+	result = nil
+	err = json.Unmarshal(data, &result)
+	c.Assert(err, check.IsNil)
+	for _, k := range []string{"Node", "Zone", "CreatedAt", "Age"} {
+		_, ok := result[k]
+		c.Check(ok, check.Equals, false, check.Commentf("key %q", k))
+	}
+}
+
 func (ProvisionSuite) TestUnitNotFoundError(c *check.C) {
 	var err error = &UnitNotFoundError{ID: "some unit"}
 	c.Assert(err.Error(), check.Equals, `unit "some unit" not found`)
@@ -22,6 +22,7 @@ import (
 	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/provision/nodehook"
 	"github.com/tsuru/tsuru/quota"
 	"github.com/tsuru/tsuru/router/routertest"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -352,6 +353,8 @@ type FakeProvisioner struct {
 	shellMut       sync.Mutex
 	nodes          map[string]FakeNode
 	nodeContainers map[string]int
+	orphans        []provision.OrphanResource
+	drifts         map[string][]provision.AppDrift
 }
 
 func NewFakeProvisioner() *FakeProvisioner {
@@ -475,6 +478,8 @@ func (p *FakeProvisioner) AddNode(opts provision.AddNodeOptions) error {
 		p:        p,
 		status:   "enabled",
 	}
+	node := p.nodes[opts.Address]
+	nodehook.NotifyNode(nodehook.EventAdd, &node)
 	return nil
 }
 
@@ -496,11 +501,12 @@ func (p *FakeProvisioner) RemoveNode(opts provision.RemoveNodeOptions) error {
 	if err := p.getError("RemoveNode"); err != nil {
 		return err
 	}
-	_, ok := p.nodes[opts.Address]
+	node, ok := p.nodes[opts.Address]
 	if !ok {
 		return provision.ErrNodeNotFound
 	}
 	delete(p.nodes, opts.Address)
+	nodehook.NotifyNode(nodehook.EventRemove, &node)
 	if opts.Writer != nil {
 		if opts.Rebalance {
 			opts.Writer.Write([]byte("rebalancing..."))
@@ -536,6 +542,7 @@ func (p *FakeProvisioner) UpdateNode(opts provision.UpdateNodeOptions) error {
 		n.status = "disabled"
 	}
 	p.nodes[opts.Address] = n
+	nodehook.NotifyNode(nodehook.UpdateEvent(opts), &n)
 	return nil
 }
 
@@ -780,6 +787,11 @@ func (p *FakeProvisioner) Reset() {
 
 	p.nodeContainers = make(map[string]int)
 
+	p.mut.Lock()
+	p.orphans = nil
+	p.drifts = nil
+	p.mut.Unlock()
+
 	for {
 		select {
 		case <-p.outputs:
@@ -1080,6 +1092,81 @@ func (p *FakeProvisioner) RemoveUnits(app provision.App, n uint, process string,
 	return nil
 }
 
+func (p *FakeProvisioner) RemoveUnitsDry(app provision.App, n uint, process string) ([]provision.Unit, error) {
+	if err := p.getError("RemoveUnitsDry"); err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errors.New("cannot remove 0 units")
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	pApp, ok := p.apps[app.GetName()]
+	if !ok {
+		return nil, errNotProvisioned
+	}
+	var removed []provision.Unit
+	for _, u := range pApp.units {
+		if uint(len(removed)) < n && u.ProcessName == process {
+			removed = append(removed, u)
+		}
+	}
+	if uint(len(removed)) < n {
+		return nil, errors.New("too many units to remove")
+	}
+	return removed, nil
+}
+
+// AddOrphanResource adds a resource to the list returned by OrphanResources.
+func (p *FakeProvisioner) AddOrphanResource(r provision.OrphanResource) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.orphans = append(p.orphans, r)
+}
+
+func (p *FakeProvisioner) OrphanResources() ([]provision.OrphanResource, error) {
+	if err := p.getError("OrphanResources"); err != nil {
+		return nil, err
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return append([]provision.OrphanResource(nil), p.orphans...), nil
+}
+
+func (p *FakeProvisioner) RemoveOrphanResource(r provision.OrphanResource) error {
+	if err := p.getError("RemoveOrphanResource"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	for i := range p.orphans {
+		if p.orphans[i] == r {
+			p.orphans = append(p.orphans[:i], p.orphans[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("orphan resource not found")
+}
+
+// AddAppDrift adds a drift to the list returned by AppDrift for the app.
+func (p *FakeProvisioner) AddAppDrift(appName string, d provision.AppDrift) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.drifts == nil {
+		p.drifts = make(map[string][]provision.AppDrift)
+	}
+	p.drifts[appName] = append(p.drifts[appName], d)
+}
+
+func (p *FakeProvisioner) AppDrift(app provision.App) ([]provision.AppDrift, error) {
+	if err := p.getError("AppDrift"); err != nil {
+		return nil, err
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return append([]provision.AppDrift(nil), p.drifts[app.GetName()]...), nil
+}
+
 // ExecuteCommand will pretend to execute the given command, recording data
 // about it.
 //
@@ -1419,11 +1506,50 @@ func (p *FakeProvisioner) UpgradeNodeContainer(name string, pool string, writer
 	return nil
 }
 
+func (p *FakeProvisioner) UpgradeNodeContainerDry(name string, pool string, writer io.Writer) error {
+	fmt.Fprintf(writer, "would upgrade node container %q in pool %q\n", name, pool)
+	return nil
+}
+
 func (p *FakeProvisioner) RemoveNodeContainer(name string, pool string, writer io.Writer) error {
 	p.nodeContainers[name+"-"+pool] = 0
 	return nil
 }
 
+func (p *FakeProvisioner) NodeContainerLogs(node provision.Node, name string, opts provision.NodeContainerLogsOptions) error {
+	if err := p.getError("NodeContainerLogs"); err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Writer, "logs for %s in %s, lines: %d, follow: %v\n", name, node.Address(), opts.Lines, opts.Follow)
+	return nil
+}
+
+// NodeContainerStatus reports the node container as existing and matching
+// its config in the nodes of the pool if it was upgraded in the pool.
+func (p *FakeProvisioner) NodeContainerStatus(name string, pool string) ([]provision.NodeContainerStatus, error) {
+	if err := p.getError("NodeContainerStatus"); err != nil {
+		return nil, err
+	}
+	nodes, err := p.ListNodes(nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []provision.NodeContainerStatus
+	for _, n := range nodes {
+		if pool != "" && n.Pool() != pool {
+			continue
+		}
+		exists := p.nodeContainers[name+"-"+pool] > 0
+		result = append(result, provision.NodeContainerStatus{
+			Node:          n.Address(),
+			Pool:          n.Pool(),
+			Exists:        exists,
+			ConfigMatches: exists,
+		})
+	}
+	return result, nil
+}
+
 func (p *FakeProvisioner) HasNodeContainer(name string, pool string) bool {
 	return p.nodeContainers[name+"-"+pool] > 0
 }
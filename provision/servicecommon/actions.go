@@ -6,10 +6,13 @@ package servicecommon
 
 import (
 	"sort"
+	"sync"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/action"
 	"github.com/tsuru/tsuru/app/image"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/set"
@@ -87,6 +90,53 @@ func RunServicePipeline(manager ServiceManager, a provision.App, newImg string,
 	})
 }
 
+// processConcurrency returns how many processes are deployed at the same
+// time, 0 means all processes of the app are deployed at once.
+func processConcurrency() int {
+	concurrency, _ := config.GetInt("deploy:process-concurrency")
+	if concurrency < 0 {
+		return 0
+	}
+	return concurrency
+}
+
+// deployProcesses deploys each process concurrently. Each call to
+// DeployService only waits for the units of its own process, so a slow
+// process doesn't hold the others. It returns the processes successfully
+// deployed, in the same order as the given processes.
+func deployProcesses(args *pipelineArgs, processes []string, labelsMap map[string]*labelReplicas) ([]string, error) {
+	concurrency := processConcurrency()
+	if concurrency == 0 || concurrency > len(processes) {
+		concurrency = len(processes)
+	}
+	errs := make([]error, len(processes))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, processName := range processes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, processName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			labels := labelsMap[processName]
+			errs[i] = args.manager.DeployService(args.app, processName, labels.labels, labels.realReplicas, args.newImage)
+		}(i, processName)
+	}
+	wg.Wait()
+	var deployedProcesses []string
+	multiErr := tsuruErrors.NewMultiError()
+	for i, processName := range processes {
+		if errs[i] != nil {
+			multiErr.Add(errs[i])
+			continue
+		}
+		deployedProcesses = append(deployedProcesses, processName)
+	}
+	return deployedProcesses, multiErr.ToError()
+}
+
 func rollbackAddedProcesses(args *pipelineArgs, processes []string) {
 	for _, processName := range processes {
 		var err error
@@ -184,14 +234,7 @@ var updateServices = &action.Action{
 		if err != nil {
 			return nil, err
 		}
-		for _, processName := range toDeployProcesses {
-			labels := labelsMap[processName]
-			err = args.manager.DeployService(args.app, processName, labels.labels, labels.realReplicas, args.newImage)
-			if err != nil {
-				break
-			}
-			deployedProcesses = append(deployedProcesses, processName)
-		}
+		deployedProcesses, err = deployProcesses(args, toDeployProcesses, labelsMap)
 		if err != nil {
 			rollbackAddedProcesses(args, deployedProcesses)
 			return nil, err
@@ -5,7 +5,10 @@
 package servicecommon
 
 import (
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
@@ -43,6 +46,7 @@ func (s *S) SetUpSuite(c *check.C) {
 	config.Set("queue:mongo-url", "127.0.0.1:27017?maxPoolSize=100")
 	config.Set("queue:mongo-database", "queue_servicecommon_tests_s")
 	config.Set("queue:mongo-polling-interval", 0.01)
+	config.Set("deploy:process-concurrency", 1)
 }
 
 func (s *S) SetUpTest(c *check.C) {
@@ -79,6 +83,7 @@ type managerCall struct {
 }
 
 type recordManager struct {
+	mu           sync.Mutex
 	deployErrMap map[string]error
 	removeErrMap map[string]error
 	lastLabels   map[string]*provision.LabelSet
@@ -99,6 +104,8 @@ func (m *recordManager) CurrentLabels(a provision.App, processName string) (*pro
 }
 
 func (m *recordManager) DeployService(a provision.App, processName string, labels *provision.LabelSet, replicas int, image string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	call := managerCall{
 		action:      "deploy",
 		processName: processName,
@@ -115,6 +122,8 @@ func (m *recordManager) DeployService(a provision.App, processName string, label
 }
 
 func (m *recordManager) RemoveService(a provision.App, processName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	call := managerCall{
 		action:      "remove",
 		processName: processName,
@@ -388,6 +397,62 @@ func (s *S) TestActionUpdateServicesForwardFailureInMiddleNewProc(c *check.C) {
 	})
 }
 
+type blockingManager struct {
+	recordManager
+	started chan string
+	release chan struct{}
+}
+
+func (m *blockingManager) DeployService(a provision.App, processName string, labels *provision.LabelSet, replicas int, image string) error {
+	m.started <- processName
+	<-m.release
+	return m.recordManager.DeployService(a, processName, labels, replicas, image)
+}
+
+func (s *S) TestActionUpdateServicesForwardConcurrent(c *check.C) {
+	config.Set("deploy:process-concurrency", 0)
+	defer config.Set("deploy:process-concurrency", 1)
+	m := &blockingManager{
+		started: make(chan string, 3),
+		release: make(chan struct{}),
+	}
+	m.deployErrMap = map[string]error{"worker2": errors.New("my deploy error")}
+	fakeApp := provisiontest.NewFakeApp("myapp", "whitespace", 1)
+	args := &pipelineArgs{
+		manager:          m,
+		app:              fakeApp,
+		newImage:         "image",
+		newImageSpec:     ProcessSpec{"web": ProcessState{Start: true}, "worker1": ProcessState{Start: true}, "worker2": ProcessState{Start: true}},
+		currentImage:     "oldImage",
+		currentImageSpec: ProcessSpec{},
+	}
+	done := make(chan error)
+	go func() {
+		_, err := updateServices.Forward(action.FWContext{Params: []interface{}{args}})
+		done <- err
+	}()
+	var started []string
+	for i := 0; i < 3; i++ {
+		select {
+		case p := <-m.started:
+			started = append(started, p)
+		case <-time.After(5 * time.Second):
+			c.Fatalf("timeout waiting for processes to start concurrently, started: %v", started)
+		}
+	}
+	sort.Strings(started)
+	c.Assert(started, check.DeepEquals, []string{"web", "worker1", "worker2"})
+	close(m.release)
+	err := <-done
+	c.Assert(err, check.ErrorMatches, "my deploy error")
+	var removed []string
+	for _, call := range m.calls[3:] {
+		c.Assert(call.action, check.Equals, "remove")
+		removed = append(removed, call.processName)
+	}
+	c.Assert(removed, check.DeepEquals, []string{"web", "worker1"})
+}
+
 func (s *S) TestActionUpdateServicesBackward(c *check.C) {
 	m := &recordManager{}
 	fakeApp := provisiontest.NewFakeApp("myapp", "whitespace", 1)
@@ -27,6 +27,7 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/servicecommon"
 )
 
@@ -242,6 +243,18 @@ func serviceSpecForApp(opts tsuruServiceOpts) (*swarm.ServiceSpec, error) {
 	if err != nil {
 		return nil, err
 	}
+	var dnsConfig *swarm.DNSConfig
+	poolDNS, err := pool.DNSConfigForPool(opts.app.GetPool())
+	if err != nil {
+		return nil, err
+	}
+	if poolDNS != nil {
+		dnsConfig = &swarm.DNSConfig{
+			Nameservers: poolDNS.Nameservers,
+			Search:      poolDNS.Searches,
+			Options:     poolDNS.Options(),
+		}
+	}
 	if !opts.isDeploy && !opts.isIsolatedRun {
 		endpointSpec = &swarm.EndpointSpec{
 			Mode: swarm.ResolutionModeVIP,
@@ -303,6 +316,7 @@ func serviceSpecForApp(opts tsuruServiceOpts) (*swarm.ServiceSpec, error) {
 				Command:     cmds,
 				Healthcheck: healthConfig,
 				Mounts:      mounts,
+				DNSConfig:   dnsConfig,
 			},
 			Networks: networks,
 			RestartPolicy: &swarm.RestartPolicy{
@@ -441,6 +455,10 @@ func serviceSpecForNodeContainer(config *nodecontainer.NodeContainerConfig, pool
 			constraints = append(constraints, toNodePoolConstraint(v, true))
 		}
 	}
+	envs, err := nodecontainer.ResolveEnvs(config.Config.Env)
+	if err != nil {
+		return nil, err
+	}
 	var mounts []mount.Mount
 	for _, b := range config.HostConfig.Binds {
 		parts := strings.SplitN(b, ":", 3)
@@ -481,7 +499,7 @@ func serviceSpecForNodeContainer(config *nodecontainer.NodeContainerConfig, pool
 				Labels:      labels,
 				Command:     config.Config.Entrypoint,
 				Args:        config.Config.Cmd,
-				Env:         config.Config.Env,
+				Env:         envs,
 				Dir:         config.Config.WorkingDir,
 				User:        config.Config.User,
 				TTY:         config.Config.Tty,
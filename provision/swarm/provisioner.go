@@ -25,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/provision/cluster"
 	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
+	"github.com/tsuru/tsuru/provision/nodehook"
 	"github.com/tsuru/tsuru/provision/servicecommon"
 )
 
@@ -210,6 +211,7 @@ func taskToUnit(task *swarm.Task, service *swarm.Service, node *swarm.Node, a pr
 		IP:          host,
 		Status:      stateMap[task.Status.State],
 		Address:     &url.URL{},
+		CreatedAt:   task.CreatedAt,
 	}
 }
 
@@ -548,10 +550,12 @@ func (p *swarmProvisioner) AddNode(opts provision.AddNodeOptions) error {
 		Version:  nodeData.Version.Index,
 		NodeSpec: nodeData.Spec,
 	})
-	if err == nil {
-		servicecommon.RebuildRoutesPoolApps(opts.Pool)
+	if err != nil {
+		return errors.WithStack(err)
 	}
-	return errors.WithStack(err)
+	servicecommon.RebuildRoutesPoolApps(opts.Pool)
+	nodehook.NotifyNodeAddress(nodehook.EventAdd, p, opts.Address)
+	return nil
 }
 
 func (p *swarmProvisioner) RemoveNode(opts provision.RemoveNodeOptions) error {
@@ -585,10 +589,12 @@ func (p *swarmProvisioner) RemoveNode(opts provision.RemoveNodeOptions) error {
 		ID:    swarmNode.ID,
 		Force: true,
 	})
-	if err == nil {
-		servicecommon.RebuildRoutesPoolApps(node.Pool())
+	if err != nil {
+		return errors.WithStack(err)
 	}
-	return errors.WithStack(err)
+	servicecommon.RebuildRoutesPoolApps(node.Pool())
+	nodehook.NotifyNode(nodehook.EventRemove, node)
+	return nil
 }
 
 func (p *swarmProvisioner) UpdateNode(opts provision.UpdateNodeOptions) error {
@@ -633,6 +639,7 @@ func (p *swarmProvisioner) UpdateNode(opts provision.UpdateNodeOptions) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	nodehook.NotifyNodeAddress(nodehook.UpdateEvent(opts), p, opts.Address)
 	return nil
 }
 
@@ -660,6 +667,7 @@ func (p *swarmProvisioner) CleanImage(appName, imgName string) error {
 
 func (p *swarmProvisioner) Deploy(a provision.App, buildImageID string, evt *event.Event) (string, error) {
 	if !strings.HasSuffix(buildImageID, "-builder") {
+		provision.StartDeployPhase(evt, provision.DeployPhaseUnitStart)
 		err := deployProcesses(a, buildImageID, nil)
 		if err != nil {
 			return "", err
@@ -686,10 +694,12 @@ func (p *swarmProvisioner) Deploy(a provision.App, buildImageID string, evt *eve
 	if err != nil {
 		return "", err
 	}
+	provision.StartDeployPhase(evt, provision.DeployPhaseImagePush)
 	_, err = commitPushBuildImage(nodeClient, deployImage, task.Status.ContainerStatus.ContainerID, a)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
+	provision.StartDeployPhase(evt, provision.DeployPhaseUnitStart)
 	err = deployProcesses(a, deployImage, nil)
 	if err != nil {
 		return "", errors.WithStack(err)
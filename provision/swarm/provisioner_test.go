@@ -387,7 +387,7 @@ func (s *S) TestUnits(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(units, check.HasLen, 1)
 	expected := []provision.Unit{
-		{ID: units[0].ID, Name: "", AppName: "myapp", ProcessName: "web", Type: "", IP: "127.0.0.1", Status: "starting", Address: &url.URL{}},
+		{ID: units[0].ID, Name: "", AppName: "myapp", ProcessName: "web", Type: "", IP: "127.0.0.1", Status: "starting", Address: &url.URL{}, CreatedAt: units[0].CreatedAt},
 	}
 	c.Assert(units, check.DeepEquals, expected)
 }
@@ -1122,7 +1122,7 @@ func (s *S) TestDeploy(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(units, check.HasLen, 1)
 	c.Assert(units, check.DeepEquals, []provision.Unit{
-		{ID: units[0].ID, AppName: a.Name, Type: "whitespace", ProcessName: "web", IP: "127.0.0.1", Status: "starting", Address: &url.URL{}},
+		{ID: units[0].ID, AppName: a.Name, Type: "whitespace", ProcessName: "web", IP: "127.0.0.1", Status: "starting", Address: &url.URL{}, CreatedAt: units[0].CreatedAt},
 	})
 	task, err := cli.InspectTask(units[0].ID)
 	c.Assert(err, check.IsNil)
@@ -1173,7 +1173,7 @@ func (s *S) TestDeployImageID(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(units, check.HasLen, 1)
 	c.Assert(units, check.DeepEquals, []provision.Unit{
-		{ID: units[0].ID, AppName: a.Name, ProcessName: "web", IP: "127.0.0.1", Status: "starting", Address: &url.URL{}},
+		{ID: units[0].ID, AppName: a.Name, ProcessName: "web", IP: "127.0.0.1", Status: "starting", Address: &url.URL{}, CreatedAt: units[0].CreatedAt},
 	})
 	dbImg, err := image.AppCurrentImageName(a.GetName())
 	c.Assert(err, check.IsNil)
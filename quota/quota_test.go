@@ -7,6 +7,7 @@ package quota
 import (
 	"testing"
 
+	"github.com/tsuru/config"
 	"gopkg.in/check.v1"
 )
 
@@ -30,3 +31,28 @@ func (Suite) TestQuotaUnlimited(c *check.C) {
 	q.Limit = 4
 	c.Assert(q.Unlimited(), check.Equals, false)
 }
+
+func (Suite) TestThresholds(c *check.C) {
+	c.Assert(Thresholds(), check.DeepEquals, []int{80, 95})
+	config.Set("quota:warning-thresholds", []interface{}{90, "x", 50, 150})
+	defer config.Unset("quota:warning-thresholds")
+	c.Assert(Thresholds(), check.DeepEquals, []int{50, 90})
+}
+
+func (Suite) TestCrossedThreshold(c *check.C) {
+	tests := []struct {
+		limit, before, after int
+		expected             int
+	}{
+		{10, 7, 8, 80},
+		{10, 8, 9, 0},
+		{10, 7, 10, 95},
+		{20, 18, 19, 95},
+		{10, 9, 8, 0},
+		{-1, 7, 100, 0},
+		{0, 0, 1, 0},
+	}
+	for _, tt := range tests {
+		c.Check(CrossedThreshold(tt.limit, tt.before, tt.after), check.Equals, tt.expected, check.Commentf("%#v", tt))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/tsuru/config"
+)
+
+// EventKindThreshold is the kind of the internal events created when the
+// usage of a quota crosses one of the warning thresholds.
+const EventKindThreshold = "quota-threshold"
+
+var defaultThresholds = []int{80, 95}
+
+// Thresholds returns the warning thresholds, in percent of the quota limit,
+// defined in the quota:warning-thresholds setting. Invalid values are
+// ignored.
+func Thresholds() []int {
+	values, err := config.GetList("quota:warning-thresholds")
+	if err != nil {
+		return defaultThresholds
+	}
+	var thresholds []int
+	for _, v := range values {
+		t, err := strconv.Atoi(v)
+		if err != nil || t <= 0 || t > 100 {
+			continue
+		}
+		thresholds = append(thresholds, t)
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// CrossedThreshold returns the highest warning threshold crossed when the
+// usage of a quota with the given limit goes from before to after. It
+// returns 0 when no threshold is crossed, which is always the case for
+// unlimited quotas and when the usage decreases.
+func CrossedThreshold(limit, before, after int) int {
+	if limit <= 0 || after <= before {
+		return 0
+	}
+	var crossed int
+	for _, t := range Thresholds() {
+		if before*100 < t*limit && after*100 >= t*limit {
+			crossed = t
+		}
+	}
+	return crossed
+}
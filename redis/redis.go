@@ -23,8 +23,10 @@ type baseClient interface {
 	Exists(key string) *redis.BoolCmd
 	RPush(key string, values ...string) *redis.IntCmd
 	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	SetXX(key string, value interface{}, expiration time.Duration) *redis.BoolCmd
 	Get(key string) *redis.StringCmd
 	Del(keys ...string) *redis.IntCmd
+	Expire(key string, expiration time.Duration) *redis.BoolCmd
 	Ping() *redis.StatusCmd
 	LRange(key string, start, stop int64) *redis.StringSliceCmd
 	LRem(key string, count int64, value interface{}) *redis.IntCmd
@@ -35,6 +37,7 @@ type baseClient interface {
 	HMGet(key string, fields ...string) *redis.SliceCmd
 	HMSetMap(key string, fields map[string]string) *redis.StatusCmd
 	HLen(key string) *redis.IntCmd
+	Eval(script string, keys []string, args []string) *redis.Cmd
 	Close() error
 }
 
@@ -101,6 +101,82 @@ func RemoveAppImages(appName string) error {
 	return multi.ToError()
 }
 
+// ListAppImages returns the names of all app images in a remote registry v2
+// server, one for each tag. Apps without images in the registry have none.
+func ListAppImages(appName string) ([]string, error) {
+	registry, _ := config.GetString("docker:registry")
+	if registry == "" {
+		return nil, nil
+	}
+	r := &dockerRegistry{server: registry}
+	image := fmt.Sprintf("tsuru/app-%s", appName)
+	resp, err := r.doRequest("GET", fmt.Sprintf("/v2/%s/tags/list", image), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("invalid status code listing image tags (%d)", resp.StatusCode)
+	}
+	var it imageTags
+	if err = json.NewDecoder(resp.Body).Decode(&it); err != nil {
+		return nil, err
+	}
+	tags := it.Tags
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = fmt.Sprintf("%s/%s:%s", registry, image, tag)
+	}
+	return result, nil
+}
+
+// ListAppNames returns the names of the apps with an images repository in a
+// remote registry v2 server, including apps no longer registered in tsuru.
+func ListAppNames() ([]string, error) {
+	registry, _ := config.GetString("docker:registry")
+	if registry == "" {
+		return nil, nil
+	}
+	r := &dockerRegistry{server: registry}
+	const pageSize = 1000
+	var (
+		names []string
+		last  string
+	)
+	for {
+		path := fmt.Sprintf("/v2/_catalog?n=%d", pageSize)
+		if last != "" {
+			path += "&last=" + url.QueryEscape(last)
+		}
+		resp, err := r.doRequest("GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var cat catalog
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&cat)
+		} else {
+			err = errors.Errorf("invalid status code listing repositories (%d)", resp.StatusCode)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range cat.Repositories {
+			if strings.HasPrefix(repo, "tsuru/app-") {
+				names = append(names, strings.TrimPrefix(repo, "tsuru/app-"))
+			}
+		}
+		if len(cat.Repositories) < pageSize {
+			return names, nil
+		}
+		last = cat.Repositories[len(cat.Repositories)-1]
+	}
+}
+
 func (r dockerRegistry) getDigest(image, tag string) (string, error) {
 	path := fmt.Sprintf("/v2/%s/manifests/%s", image, tag)
 	resp, err := r.doRequest("HEAD", path, map[string]string{"Accept": "application/vnd.docker.distribution.manifest.v2+json"})
@@ -123,6 +199,10 @@ type imageTags struct {
 	Tags []string
 }
 
+type catalog struct {
+	Repositories []string
+}
+
 func (r dockerRegistry) getImageTags(image string) ([]string, error) {
 	path := fmt.Sprintf("/v2/%s/tags/list", image)
 	resp, err := r.doRequest("GET", path, nil)
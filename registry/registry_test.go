@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -71,6 +72,47 @@ func (s *S) TestRegistryRemoveAppImages(c *check.C) {
 	c.Assert(s.server.Repos[0].Tags, check.HasLen, 0)
 }
 
+func (s *S) TestRegistryListAppImages(c *check.C) {
+	s.server.AddRepo(registrytest.Repository{Name: "tsuru/app-teste", Tags: map[string]string{"v1": "abcdefg", "v2": "hijklmn"}})
+	images, err := ListAppImages("teste")
+	c.Assert(err, check.IsNil)
+	sort.Strings(images)
+	c.Assert(images, check.DeepEquals, []string{
+		s.server.Addr() + "/tsuru/app-teste:v1",
+		s.server.Addr() + "/tsuru/app-teste:v2",
+	})
+}
+
+func (s *S) TestRegistryListAppImagesNotFound(c *check.C) {
+	images, err := ListAppImages("teste")
+	c.Assert(err, check.IsNil)
+	c.Assert(images, check.IsNil)
+}
+
+func (s *S) TestRegistryListAppImagesNoRegistry(c *check.C) {
+	config.Unset("docker:registry")
+	images, err := ListAppImages("teste")
+	c.Assert(err, check.IsNil)
+	c.Assert(images, check.IsNil)
+}
+
+func (s *S) TestRegistryListAppNames(c *check.C) {
+	s.server.AddRepo(registrytest.Repository{Name: "tsuru/app-teste", Tags: map[string]string{"v1": "abcdefg"}})
+	s.server.AddRepo(registrytest.Repository{Name: "tsuru/python", Tags: map[string]string{"latest": "hijklmn"}})
+	s.server.AddRepo(registrytest.Repository{Name: "tsuru/app-removed", Tags: map[string]string{"v3": "opqrstu"}})
+	names, err := ListAppNames()
+	c.Assert(err, check.IsNil)
+	sort.Strings(names)
+	c.Assert(names, check.DeepEquals, []string{"removed", "teste"})
+}
+
+func (s *S) TestRegistryListAppNamesNoRegistry(c *check.C) {
+	config.Unset("docker:registry")
+	names, err := ListAppNames()
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.IsNil)
+}
+
 func (s *S) TestRegistryRemoveImage(c *check.C) {
 	s.server.AddRepo(registrytest.Repository{Name: "tsuru/app-teste", Tags: map[string]string{"v1": "abcdefg", "v2": "hijklmn"}})
 	c.Assert(s.server.Repos, check.HasLen, 1)
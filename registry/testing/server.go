@@ -26,6 +26,10 @@ type tagListResponse struct {
 	Tags []string `json:"tags"`
 }
 
+type catalogResponse struct {
+	Repositories []string `json:"repositories"`
+}
+
 type RegistryServer struct {
 	listener      net.Listener
 	muxer         *mux.Router
@@ -92,6 +96,20 @@ func (s *RegistryServer) buildMuxer() {
 	s.muxer.Path("/v2/{name:.*}/manifests/{tag:.*}").Methods("HEAD").HandlerFunc(s.getDigest)
 	s.muxer.Path("/v2/{name:.*}/manifests/{digest:.*}").Methods("DELETE").HandlerFunc(s.removeTag)
 	s.muxer.Path("/v2/{name:.*}/tags/list").Methods("GET").HandlerFunc(s.listTags)
+	s.muxer.Path("/v2/_catalog").Methods("GET").HandlerFunc(s.listRepositories)
+}
+
+func (s *RegistryServer) listRepositories(w http.ResponseWriter, r *http.Request) {
+	s.reposLock.RLock()
+	defer s.reposLock.RUnlock()
+	names := make([]string, len(s.Repos))
+	for i, repo := range s.Repos {
+		names[i] = repo.Name
+	}
+	err := json.NewEncoder(w).Encode(catalogResponse{Repositories: names})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
 }
 
 func (s *RegistryServer) removeTag(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,382 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package retry stores asynchronous operations that failed and retries them
+// with exponential backoff until they succeed or reach the maximum number of
+// attempts. Operations that exhaust their attempts are kept as stuck, so they
+// can be inspected and requeued by an administrator.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusStuck   = "stuck"
+
+	defaultMaxAttempts = 10
+	defaultBackoff     = 10 * time.Second
+	defaultMaxBackoff  = time.Hour
+	defaultLease       = 10 * time.Minute
+	defaultInterval    = 10 * time.Second
+)
+
+var (
+	ErrOperationNotFound = errors.New("operation not found")
+	ErrNotStuck          = errors.New("only stuck operations can be requeued")
+	ErrAlreadyQueued     = errors.New("the same operation is already waiting to be retried")
+)
+
+// Handler runs an operation with the given parameters. A nil error means
+// the operation succeeded and won't be retried.
+type Handler func(params map[string]string) error
+
+var handlers = struct {
+	sync.RWMutex
+	m map[string]Handler
+}{m: map[string]Handler{}}
+
+// RegisterHandler registers the handler responsible for running operations
+// of the given kind.
+func RegisterHandler(kind string, h Handler) {
+	handlers.Lock()
+	defer handlers.Unlock()
+	handlers.m[kind] = h
+}
+
+func getHandler(kind string) Handler {
+	handlers.RLock()
+	defer handlers.RUnlock()
+	return handlers.m[kind]
+}
+
+// Operation is a failed asynchronous operation waiting to be retried.
+type Operation struct {
+	ID          bson.ObjectId     `bson:"_id" json:"id"`
+	Kind        string            `json:"kind"`
+	Key         string            `json:"-"`
+	Params      map[string]string `json:"params"`
+	Status      string            `json:"status"`
+	Attempts    int               `json:"attempts"`
+	MaxAttempts int               `json:"maxAttempts"`
+	LastError   string            `json:"lastError,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	LastAttempt time.Time         `json:"lastAttempt,omitempty"`
+	NextAttempt time.Time         `json:"nextAttempt"`
+	LeaseExpiry time.Time         `json:"-"`
+	// Active is set while the operation is pending or running, a unique
+	// index ensures a single active operation for each key.
+	Active bool `json:"-"`
+}
+
+func collection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("retry_operations")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"status", "nextattempt"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{
+		Key:           []string{"key", "active"},
+		Unique:        true,
+		PartialFilter: bson.M{"active": true},
+	})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func durationConfig(key string, def time.Duration) time.Duration {
+	seconds, _ := config.GetInt(key)
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func maxAttempts() int {
+	attempts, _ := config.GetInt("retry:max-attempts")
+	if attempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return attempts
+}
+
+// backoff returns how long to wait before the next attempt of an operation
+// that has already been attempted the given number of times.
+func backoff(attempts int) time.Duration {
+	base := durationConfig("retry:backoff", defaultBackoff)
+	limit := durationConfig("retry:max-backoff", defaultMaxBackoff)
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+func operationKey(kind string, params map[string]string) string {
+	parts := make([]string, 0, len(params))
+	for k, v := range params {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return kind + "?" + strings.Join(parts, "&")
+}
+
+// Enqueue stores an operation to be retried as soon as possible. If the same
+// operation is already waiting to be retried, the existing one is returned.
+func Enqueue(kind string, params map[string]string) (*Operation, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	key := operationKey(kind, params)
+	for {
+		now := time.Now().UTC()
+		op := Operation{
+			ID:          bson.NewObjectId(),
+			Kind:        kind,
+			Key:         key,
+			Params:      params,
+			Status:      StatusPending,
+			MaxAttempts: maxAttempts(),
+			CreatedAt:   now,
+			NextAttempt: now,
+			Active:      true,
+		}
+		err = coll.Insert(op)
+		if err == nil {
+			return &op, nil
+		}
+		if !mgo.IsDup(err) {
+			return nil, err
+		}
+		err = coll.Find(bson.M{"key": key, "active": true}).One(&op)
+		if err == nil {
+			return &op, nil
+		}
+		if err != mgo.ErrNotFound {
+			return nil, err
+		}
+		// The existing operation finished in the meantime, try again.
+	}
+}
+
+// List returns the stored operations, optionally filtered by status.
+func List(status string) ([]Operation, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	query := bson.M{}
+	if status != "" {
+		query["status"] = status
+	}
+	var ops []Operation
+	err = coll.Find(query).Sort("_id").All(&ops)
+	if err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Get returns an operation by its id.
+func Get(id string) (*Operation, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, ErrOperationNotFound
+	}
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var op Operation
+	err = coll.FindId(bson.ObjectIdHex(id)).One(&op)
+	if err == mgo.ErrNotFound {
+		return nil, ErrOperationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// Requeue resets the attempts of a stuck operation, so it's retried again as
+// soon as possible.
+func (op *Operation) Requeue() error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	update := bson.M{
+		"status":      StatusPending,
+		"attempts":    0,
+		"maxattempts": maxAttempts(),
+		"nextattempt": now,
+		"active":      true,
+	}
+	err = coll.Update(bson.M{"_id": op.ID, "status": StatusStuck}, bson.M{"$set": update})
+	if err == mgo.ErrNotFound {
+		return ErrNotStuck
+	}
+	if mgo.IsDup(err) {
+		return ErrAlreadyQueued
+	}
+	if err != nil {
+		return err
+	}
+	op.Status = StatusPending
+	op.Attempts = 0
+	op.MaxAttempts = update["maxattempts"].(int)
+	op.NextAttempt = now
+	op.Active = true
+	return nil
+}
+
+func claimNext(coll *storage.Collection, now time.Time) (*Operation, error) {
+	var op Operation
+	_, err := coll.Find(bson.M{"$or": []bson.M{
+		{"status": StatusPending, "nextattempt": bson.M{"$lte": now}},
+		{"status": StatusRunning, "leaseexpiry": bson.M{"$lt": now}},
+	}}).Sort("nextattempt").Apply(mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"status":      StatusRunning,
+			"leaseexpiry": now.Add(durationConfig("retry:lease", defaultLease)),
+		}},
+		ReturnNew: true,
+	}, &op)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+func run(op *Operation) (err error) {
+	h := getHandler(op.Kind)
+	if h == nil {
+		return errors.Errorf("no handler registered for operations of kind %q", op.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic running operation: %v", r)
+		}
+	}()
+	return h(op.Params)
+}
+
+func finish(coll *storage.Collection, op *Operation, opErr error) error {
+	if opErr == nil {
+		return coll.RemoveId(op.ID)
+	}
+	now := time.Now().UTC()
+	attempts := op.Attempts + 1
+	update := bson.M{
+		"attempts":    attempts,
+		"lasterror":   opErr.Error(),
+		"lastattempt": now,
+	}
+	if attempts >= op.MaxAttempts {
+		update["status"] = StatusStuck
+		update["active"] = false
+		log.Errorf("[retry] operation %s (%s) is stuck after %d attempts: %v", op.ID.Hex(), op.Key, attempts, opErr)
+	} else {
+		update["status"] = StatusPending
+		update["nextattempt"] = now.Add(backoff(attempts))
+	}
+	return coll.UpdateId(op.ID, bson.M{"$set": update})
+}
+
+// RunPending retries every operation whose next attempt is due.
+func RunPending() error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	for {
+		op, err := claimNext(coll, now)
+		if err != nil {
+			return err
+		}
+		if op == nil {
+			return nil
+		}
+		opErr := run(op)
+		if opErr != nil {
+			log.Errorf("[retry] error running operation %s (%s), attempt %d: %v", op.ID.Hex(), op.Key, op.Attempts+1, opErr)
+		}
+		err = finish(coll, op, opErr)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Initialize starts retrying the stored operations in background.
+func Initialize() error {
+	r := &retrier{stopCh: make(chan struct{})}
+	go r.spin()
+	shutdown.Register(r)
+	return nil
+}
+
+type retrier struct {
+	stopCh chan struct{}
+}
+
+func (r *retrier) spin() {
+	for {
+		err := RunPending()
+		if err != nil {
+			log.Errorf("[retry] error retrying operations: %v", err)
+		}
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(durationConfig("retry:interval", defaultInterval)):
+		}
+	}
+}
+
+func (r *retrier) Shutdown(ctx context.Context) error {
+	r.stopCh <- struct{}{}
+	return nil
+}
+
+func (r *retrier) String() string {
+	return "failed operations retrier"
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retry
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestBackoff(c *check.C) {
+	c.Assert(backoff(1), check.Equals, 10*time.Second)
+	c.Assert(backoff(2), check.Equals, 20*time.Second)
+	c.Assert(backoff(4), check.Equals, 80*time.Second)
+	c.Assert(backoff(20), check.Equals, time.Hour)
+	config.Set("retry:backoff", 1)
+	config.Set("retry:max-backoff", 5)
+	c.Assert(backoff(1), check.Equals, time.Second)
+	c.Assert(backoff(3), check.Equals, 4*time.Second)
+	c.Assert(backoff(4), check.Equals, 5*time.Second)
+}
+
+func (s *S) TestEnqueue(c *check.C) {
+	op, err := Enqueue("kind1", map[string]string{"a": "1", "b": "2"})
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, StatusPending)
+	c.Assert(op.MaxAttempts, check.Equals, 10)
+	c.Assert(op.Key, check.Equals, "kind1?a=1&b=2")
+	dup, err := Enqueue("kind1", map[string]string{"b": "2", "a": "1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(dup.ID, check.Equals, op.ID)
+	other, err := Enqueue("kind1", map[string]string{"a": "2"})
+	c.Assert(err, check.IsNil)
+	c.Assert(other.ID, check.Not(check.Equals), op.ID)
+	ops, err := List(StatusPending)
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 2)
+	ops, err = List(StatusStuck)
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 0)
+}
+
+func (s *S) TestRunPendingSuccess(c *check.C) {
+	var calls []map[string]string
+	RegisterHandler("kind1", func(params map[string]string) error {
+		calls = append(calls, params)
+		return nil
+	})
+	_, err := Enqueue("kind1", map[string]string{"a": "1"})
+	c.Assert(err, check.IsNil)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.DeepEquals, []map[string]string{{"a": "1"}})
+	ops, err := List("")
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 0)
+}
+
+func (s *S) TestRunPendingFailureBacksOff(c *check.C) {
+	calls := 0
+	RegisterHandler("kind1", func(params map[string]string) error {
+		calls++
+		return errors.New("my error")
+	})
+	op, err := Enqueue("kind1", nil)
+	c.Assert(err, check.IsNil)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.Equals, 1)
+	op, err = Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, StatusPending)
+	c.Assert(op.Attempts, check.Equals, 1)
+	c.Assert(op.LastError, check.Equals, "my error")
+	c.Assert(op.NextAttempt.Sub(op.LastAttempt), check.Equals, 10*time.Second)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.Equals, 1)
+}
+
+func (s *S) TestRunPendingStuckAfterMaxAttempts(c *check.C) {
+	config.Set("retry:max-attempts", 2)
+	config.Set("retry:backoff", 1)
+	calls := 0
+	RegisterHandler("kind1", func(params map[string]string) error {
+		calls++
+		return errors.New("my error")
+	})
+	op, err := Enqueue("kind1", nil)
+	c.Assert(err, check.IsNil)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	time.Sleep(1100 * time.Millisecond)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.Equals, 2)
+	op, err = Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, StatusStuck)
+	c.Assert(op.Attempts, check.Equals, 2)
+	ops, err := List(StatusStuck)
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 1)
+	newOp, err := Enqueue("kind1", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(newOp.ID, check.Not(check.Equals), op.ID)
+}
+
+func (s *S) TestRunPendingNoHandler(c *check.C) {
+	op, err := Enqueue("unknown", nil)
+	c.Assert(err, check.IsNil)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	op, err = Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Attempts, check.Equals, 1)
+	c.Assert(op.LastError, check.Equals, `no handler registered for operations of kind "unknown"`)
+}
+
+func (s *S) TestRequeue(c *check.C) {
+	config.Set("retry:max-attempts", 1)
+	fail := true
+	RegisterHandler("kind1", func(params map[string]string) error {
+		if fail {
+			return errors.New("my error")
+		}
+		return nil
+	})
+	op, err := Enqueue("kind1", nil)
+	c.Assert(err, check.IsNil)
+	err = op.Requeue()
+	c.Assert(err, check.Equals, ErrNotStuck)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	op, err = Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, StatusStuck)
+	err = op.Requeue()
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, StatusPending)
+	fail = false
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	_, err = Get(op.ID.Hex())
+	c.Assert(err, check.Equals, ErrOperationNotFound)
+}
+
+func (s *S) TestRequeueAlreadyQueued(c *check.C) {
+	config.Set("retry:max-attempts", 1)
+	RegisterHandler("kind1", func(params map[string]string) error {
+		return errors.New("my error")
+	})
+	op, err := Enqueue("kind1", nil)
+	c.Assert(err, check.IsNil)
+	err = RunPending()
+	c.Assert(err, check.IsNil)
+	op, err = Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, StatusStuck)
+	newOp, err := Enqueue("kind1", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(newOp.ID, check.Not(check.Equals), op.ID)
+	err = op.Requeue()
+	c.Assert(err, check.Equals, ErrAlreadyQueued)
+	op, err = Get(op.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Status, check.Equals, StatusStuck)
+}
+
+func (s *S) TestGetInvalidID(c *check.C) {
+	_, err := Get("invalid")
+	c.Assert(err, check.Equals, ErrOperationNotFound)
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// CapabilityAccessLog is the capability of routers implementing
+// AccessLogRouter.
+const CapabilityAccessLog = "access-log"
+
+// AccessLogFields are the request fields routers may include in access log
+// entries, in the order they are logged.
+var AccessLogFields = []string{"client", "method", "host", "path", "status", "size", "duration", "referer", "user-agent"}
+
+// AccessLogConfig holds the settings of the access logs collected by routers
+// for the backend of an app. SampleRate is the fraction of the requests
+// logged and an empty Fields logs every field in AccessLogFields.
+type AccessLogConfig struct {
+	Enabled    bool     `json:"enabled"`
+	SampleRate float64  `json:"sampleRate"`
+	Fields     []string `json:"fields,omitempty"`
+}
+
+// AccessLogEntry is a request logged by a router, holding the configured
+// fields.
+type AccessLogEntry struct {
+	Time   time.Time         `json:"time"`
+	Fields map[string]string `json:"fields"`
+}
+
+// AccessLogRouter is a router able to log the requests sent to backends,
+// the logs are periodically collected by tsuru into the app logs. A nil
+// config stops the logging.
+type AccessLogRouter interface {
+	SetAccessLog(app App, config *AccessLogConfig) error
+	// AccessLogs returns the entries logged for the backend after the
+	// given time, oldest first.
+	AccessLogs(name string, since time.Time) ([]AccessLogEntry, error)
+}
+
+// Validate checks whether the sample rate is in the (0, 1] range and every
+// field is known.
+func (c *AccessLogConfig) Validate() error {
+	if c.SampleRate <= 0 || c.SampleRate > 1 {
+		return &tsuruErrors.ValidationError{Message: "Invalid sample rate, it must be greater than 0 and at most 1"}
+	}
+	for _, field := range c.Fields {
+		if !isAccessLogField(field) {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("Invalid access log field %q, possible values are: %s", field, strings.Join(AccessLogFields, ", ")),
+			}
+		}
+	}
+	return nil
+}
+
+// LoggedFields returns the fields logged with the config, in the order they
+// are formatted.
+func (c *AccessLogConfig) LoggedFields() []string {
+	if len(c.Fields) == 0 {
+		return AccessLogFields
+	}
+	var fields []string
+	for _, field := range AccessLogFields {
+		for _, f := range c.Fields {
+			if f == field {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// Format returns the entry as a log message with the given fields, in the
+// key=value format. Missing fields are logged as "-".
+func (e *AccessLogEntry) Format(fields []string) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		value := e.Fields[field]
+		if value == "" {
+			value = "-"
+		} else if strings.ContainsAny(value, " \"") {
+			value = fmt.Sprintf("%q", value)
+		}
+		parts[i] = field + "=" + value
+	}
+	return strings.Join(parts, " ")
+}
+
+func isAccessLogField(field string) bool {
+	for _, f := range AccessLogFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
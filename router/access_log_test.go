@@ -0,0 +1,48 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestAccessLogConfigValidate(c *check.C) {
+	tests := []struct {
+		config AccessLogConfig
+		err    string
+	}{
+		{config: AccessLogConfig{Enabled: true, SampleRate: 1}},
+		{config: AccessLogConfig{Enabled: true, SampleRate: 0.01, Fields: []string{"method", "path", "status"}}},
+		{config: AccessLogConfig{Enabled: true}, err: "Invalid sample rate, .*"},
+		{config: AccessLogConfig{SampleRate: 1.5}, err: "Invalid sample rate, .*"},
+		{config: AccessLogConfig{SampleRate: 1, Fields: []string{"cookie"}}, err: `Invalid access log field "cookie", possible values are: client, .*`},
+	}
+	for i, tt := range tests {
+		err := tt.config.Validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("case %d", i))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("case %d", i))
+		}
+	}
+}
+
+func (s *S) TestAccessLogConfigLoggedFields(c *check.C) {
+	config := AccessLogConfig{SampleRate: 1}
+	c.Assert(config.LoggedFields(), check.DeepEquals, AccessLogFields)
+	config.Fields = []string{"status", "method", "path"}
+	c.Assert(config.LoggedFields(), check.DeepEquals, []string{"method", "path", "status"})
+}
+
+func (s *S) TestAccessLogEntryFormat(c *check.C) {
+	entry := AccessLogEntry{Fields: map[string]string{
+		"method":     "GET",
+		"path":       "/index.html",
+		"status":     "200",
+		"user-agent": "curl/7.58.0 (x86_64)",
+	}}
+	msg := entry.Format([]string{"method", "path", "status", "duration", "user-agent"})
+	c.Assert(msg, check.Equals, `method=GET path=/index.html status=200 duration=- user-agent="curl/7.58.0 (x86_64)"`)
+}
@@ -16,11 +16,15 @@ import (
 )
 
 var capMap = map[string][]string{
-	"cname":       {"router.CNameRouter", "apiRouterWithCnameSupport"},
-	"tls":         {"router.TLSRouter", "apiRouterWithTLSSupport"},
-	"healthcheck": {"router.CustomHealthcheckRouter", "apiRouterWithHealthcheckSupport"},
-	"info":        {"router.InfoRouter", "apiRouterWithInfo"},
-	"status":      {"router.StatusRouter", "apiRouterWithStatus"},
+	"cname":          {"router.CNameRouter", "apiRouterWithCnameSupport"},
+	"tls":            {"router.TLSRouter", "apiRouterWithTLSSupport"},
+	"healthcheck":    {"router.CustomHealthcheckRouter", "apiRouterWithHealthcheckSupport"},
+	"info":           {"router.InfoRouter", "apiRouterWithInfo"},
+	"status":         {"router.StatusRouter", "apiRouterWithStatus"},
+	"tls-policy":     {"router.TLSPolicyRouter", "apiRouterWithTLSPolicySupport"},
+	"protocol":       {"router.ProtocolRouter", "apiRouterWithProtocolSupport"},
+	"sticky-session": {"router.StickySessionRouter", "apiRouterWithStickySessionSupport"},
+	"header-policy":  {"router.HeaderPolicyRouter", "apiRouterWithHeaderPolicySupport"},
 }
 
 var fileTpl = `// AUTOMATICALLY GENERATED FILE - DO NOT EDIT!
@@ -52,7 +56,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		supports["{{ $capv }}"]
 	{{- end -}} {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 		{{ range $element -}}
 			{{ index (index $capMap (index $caps .)) 0 }}
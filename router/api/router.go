@@ -14,6 +14,7 @@ import (
 	"net/url"
 
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
@@ -36,20 +37,47 @@ var (
 	_ router.CustomHealthcheckRouter = &apiRouterWithHealthcheckSupport{}
 	_ router.InfoRouter              = &apiRouterWithInfo{}
 	_ router.StatusRouter            = &apiRouterWithStatus{}
+	_ router.TLSPolicyRouter         = &apiRouterWithTLSPolicySupport{}
+	_ router.ProtocolRouter          = &apiRouterWithProtocolSupport{}
+	_ router.StickySessionRouter     = &apiRouterWithStickySessionSupport{}
+	_ router.HeaderPolicyRouter      = &apiRouterWithHeaderPolicySupport{}
+	_ baseRouter                     = &apiRouter{}
 )
 
+// baseRouter holds the interfaces implemented by every API router. Support
+// for the optional ones in it is checked at runtime with router.Supports,
+// instead of generating a type for each combination of capabilities.
+type baseRouter interface {
+	router.Router
+	router.CapabilityRouter
+	router.MirrorRouter
+	router.AccessLogRouter
+	router.ErrorPageRouter
+	router.StatusReporter
+	router.TrafficRouter
+}
+
 type apiRouter struct {
 	routerName string
 	endpoint   string
 	headers    map[string]string
 	client     *http.Client
 	debug      bool
+	supports   map[capability]bool
 }
 
 type apiRouterWithCnameSupport struct{ *apiRouter }
 
 type apiRouterWithTLSSupport struct{ *apiRouter }
 
+type apiRouterWithTLSPolicySupport struct{ *apiRouter }
+
+type apiRouterWithProtocolSupport struct{ *apiRouter }
+
+type apiRouterWithStickySessionSupport struct{ *apiRouter }
+
+type apiRouterWithHeaderPolicySupport struct{ *apiRouter }
+
 type apiRouterWithHealthcheckSupport struct{ *apiRouter }
 
 type apiRouterWithInfo struct{ *apiRouter }
@@ -78,6 +106,18 @@ type backendResp struct {
 	Address string `json:"address"`
 }
 
+type protocolsResp struct {
+	Protocols []string `json:"protocols"`
+}
+
+type backendProtocolsReq struct {
+	Protocols map[string]string `json:"protocols"`
+}
+
+type lastRequestResp struct {
+	LastRequest time.Time `json:"lastRequest"`
+}
+
 type statusResp struct {
 	Status router.BackendStatus `json:"status"`
 	Detail string               `json:"detail"`
@@ -91,8 +131,17 @@ var (
 	capHealthcheck = capability("healthcheck")
 	capInfo        = capability("info")
 	capStatus      = capability("status")
-
-	allCaps = []capability{capCName, capTLS, capHealthcheck, capInfo, capStatus}
+	capTLSPolicy   = capability("tls-policy")
+	capProtocol    = capability("protocol")
+	capSticky      = capability("sticky-session")
+	capHeaders     = capability("header-policy")
+	capMirror      = capability(router.CapabilityMirror)
+	capAccessLog   = capability(router.CapabilityAccessLog)
+	capErrorPage   = capability(router.CapabilityErrorPage)
+	capUnitStatus  = capability(router.CapabilityStatusReporter)
+	capTraffic     = capability(router.CapabilityTraffic)
+
+	allCaps = []capability{capCName, capTLS, capHealthcheck, capInfo, capStatus, capTLSPolicy, capProtocol, capSticky, capHeaders, capMirror, capAccessLog, capErrorPage, capUnitStatus, capTraffic}
 )
 
 func init() {
@@ -136,6 +185,7 @@ func createRouter(routerName, configPrefix string) (router.Router, error) {
 			log.Errorf("failed to fetch %q support from router %q: %s", cap, routerName, err)
 		}
 	}
+	baseRouter.supports = supports
 	return toSupportedInterface(baseRouter, supports), nil
 }
 
@@ -300,6 +350,131 @@ func (r *apiRouter) HealthCheck() error {
 	return nil
 }
 
+func (r *apiRouter) SupportsCapability(cap string) bool {
+	return r.supports[capability(cap)]
+}
+
+func (r *apiRouter) SetMirror(app router.App, mirror *router.Mirror) error {
+	path := fmt.Sprintf("backend/%s/mirror", app.GetName())
+	if mirror == nil {
+		_, code, err := r.do(http.MethodDelete, path, nil)
+		if code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	b, err := json.Marshal(mirror)
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(http.MethodPut, path, bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouter) SetAccessLog(app router.App, config *router.AccessLogConfig) error {
+	path := fmt.Sprintf("backend/%s/access-log", app.GetName())
+	if config == nil {
+		_, code, err := r.do(http.MethodDelete, path, nil)
+		if code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(http.MethodPut, path, bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouter) AccessLogs(name string, since time.Time) ([]router.AccessLogEntry, error) {
+	backendName, err := router.Retrieve(name)
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("backend/%s/access-log?since=%s", backendName, url.QueryEscape(since.UTC().Format(time.RFC3339Nano)))
+	data, code, err := r.do(http.MethodGet, path, nil)
+	if code == http.StatusNotFound {
+		return nil, router.ErrBackendNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []router.AccessLogEntry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *apiRouter) SetErrorPages(app router.App, pages []router.ErrorPage) error {
+	path := fmt.Sprintf("backend/%s/error-pages", app.GetName())
+	if len(pages) == 0 {
+		_, code, err := r.do(http.MethodDelete, path, nil)
+		if code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	b, err := json.Marshal(pages)
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(http.MethodPut, path, bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouter) RoutesStatus(name string) ([]router.RouteStatus, error) {
+	backendName, err := router.Retrieve(name)
+	if err != nil {
+		return nil, err
+	}
+	data, code, err := r.do(http.MethodGet, fmt.Sprintf("backend/%s/routes/status", backendName), nil)
+	if code == http.StatusNotFound {
+		return nil, router.ErrBackendNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var routes []router.RouteStatus
+	err = json.Unmarshal(data, &routes)
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (r *apiRouter) LastRequest(name string) (time.Time, error) {
+	backendName, err := router.Retrieve(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	data, code, err := r.do(http.MethodGet, fmt.Sprintf("backend/%s/last-request", backendName), nil)
+	if code == http.StatusNotFound {
+		return time.Time{}, router.ErrBackendNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	var resp lastRequestResp
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return resp.LastRequest, nil
+}
+
 func (r *apiRouter) checkSupports(feature string) (bool, error) {
 	path := fmt.Sprintf("support/%s", feature)
 	data, statusCode, err := r.do(http.MethodGet, path, nil)
@@ -441,6 +616,88 @@ func (r *apiRouterWithTLSSupport) GetCertificate(app router.App, cname string) (
 	return "", err
 }
 
+func (r *apiRouterWithTLSPolicySupport) SetTLSPolicy(app router.App, policy *router.TLSPolicy) error {
+	path := fmt.Sprintf("backend/%s/tls-policy", app.GetName())
+	if policy == nil {
+		_, code, err := r.do(http.MethodDelete, path, nil)
+		if code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	_, _, err = r.do(http.MethodPut, path, bytes.NewReader(b))
+	return err
+}
+
+func (r *apiRouterWithProtocolSupport) SupportedProtocols() ([]string, error) {
+	data, _, err := r.do(http.MethodGet, "protocols", nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp protocolsResp
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Protocols, nil
+}
+
+func (r *apiRouterWithProtocolSupport) SetBackendProtocols(app router.App, protocols map[string]string) error {
+	b, err := json.Marshal(backendProtocolsReq{Protocols: protocols})
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(http.MethodPut, fmt.Sprintf("backend/%s/protocols", app.GetName()), bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouterWithStickySessionSupport) SetStickySession(app router.App, session *router.StickySession) error {
+	path := fmt.Sprintf("backend/%s/sticky-session", app.GetName())
+	if session == nil {
+		_, code, err := r.do(http.MethodDelete, path, nil)
+		if code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	b, err := json.Marshal(session.WithDefaults())
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(http.MethodPut, path, bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouterWithHeaderPolicySupport) SetHeaderPolicy(app router.App, policy *router.HeaderPolicy) error {
+	path := fmt.Sprintf("backend/%s/header-policy", app.GetName())
+	if policy == nil {
+		_, code, err := r.do(http.MethodDelete, path, nil)
+		if code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(http.MethodPut, path, bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
 func (r *apiRouterWithHealthcheckSupport) SetHealthcheck(name string, data router.HealthcheckData) error {
 	backendName, err := router.Retrieve(name)
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"net/url"
 
@@ -65,6 +66,13 @@ func init() {
 func (s *S) SetUpTest(c *check.C) {
 	s.apiRouter = newFakeRouter(c)
 	s.apiRouter.certificates = make(map[string]certData)
+	s.apiRouter.tlsPolicies = make(map[string]router.TLSPolicy)
+	s.apiRouter.protocols = make(map[string]map[string]string)
+	s.apiRouter.stickySessions = make(map[string]router.StickySession)
+	s.apiRouter.headerPolicies = make(map[string]router.HeaderPolicy)
+	s.apiRouter.mirrors = make(map[string]router.Mirror)
+	s.apiRouter.accessLogs = make(map[string]router.AccessLogConfig)
+	s.apiRouter.errorPages = make(map[string][]router.ErrorPage)
 	s.testRouter = &apiRouter{
 		endpoint:   s.apiRouter.endpoint,
 		client:     tsuruNet.Dial5Full60ClientNoKeepAlive,
@@ -319,6 +327,172 @@ func (s *S) TestRemoveCertificateNotFound(c *check.C) {
 	c.Assert(err, check.DeepEquals, router.ErrCertificateNotFound)
 }
 
+func (s *S) TestSetTLSPolicy(c *check.C) {
+	policyRouter := &apiRouterWithTLSPolicySupport{s.testRouter}
+	policy := router.TLSPolicy{
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		HSTS:         &router.HSTSPolicy{MaxAge: 31536000, IncludeSubdomains: true},
+	}
+	err := policyRouter.SetTLSPolicy(routertest.FakeApp{Name: "myapp"}, &policy)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.tlsPolicies["myapp"], check.DeepEquals, policy)
+	err = policyRouter.SetTLSPolicy(routertest.FakeApp{Name: "myapp"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.tlsPolicies, check.HasLen, 0)
+	err = policyRouter.SetTLSPolicy(routertest.FakeApp{Name: "myapp"}, nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSupportedProtocols(c *check.C) {
+	protoRouter := &apiRouterWithProtocolSupport{s.testRouter}
+	protocols, err := protoRouter.SupportedProtocols()
+	c.Assert(err, check.IsNil)
+	c.Assert(protocols, check.DeepEquals, []string{"http", "h2c", "websocket"})
+}
+
+func (s *S) TestSetBackendProtocols(c *check.C) {
+	protoRouter := &apiRouterWithProtocolSupport{s.testRouter}
+	protocols := map[string]string{"web": "h2c", "ws": "websocket"}
+	err := protoRouter.SetBackendProtocols(routertest.FakeApp{Name: "mybackend"}, protocols)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.protocols["mybackend"], check.DeepEquals, protocols)
+}
+
+func (s *S) TestSetBackendProtocolsBackendNotFound(c *check.C) {
+	protoRouter := &apiRouterWithProtocolSupport{s.testRouter}
+	err := protoRouter.SetBackendProtocols(routertest.FakeApp{Name: "invalid"}, map[string]string{"web": "h2c"})
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestSetStickySession(c *check.C) {
+	stickyRouter := &apiRouterWithStickySessionSupport{s.testRouter}
+	err := stickyRouter.SetStickySession(routertest.FakeApp{Name: "mybackend"}, &router.StickySession{TTL: 3600})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.stickySessions["mybackend"], check.DeepEquals, router.StickySession{CookieName: router.DefaultStickyCookieName, TTL: 3600})
+	err = stickyRouter.SetStickySession(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.stickySessions, check.HasLen, 0)
+	err = stickyRouter.SetStickySession(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSetStickySessionBackendNotFound(c *check.C) {
+	stickyRouter := &apiRouterWithStickySessionSupport{s.testRouter}
+	err := stickyRouter.SetStickySession(routertest.FakeApp{Name: "invalid"}, &router.StickySession{CookieName: "c"})
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestSetHeaderPolicy(c *check.C) {
+	headerRouter := &apiRouterWithHeaderPolicySupport{s.testRouter}
+	policy := router.HeaderPolicy{
+		CORS:    &router.CORSPolicy{AllowedOrigins: []string{"https://example.com"}, AllowedMethods: []string{"GET"}},
+		Headers: map[string]string{"X-Frame-Options": "DENY"},
+	}
+	err := headerRouter.SetHeaderPolicy(routertest.FakeApp{Name: "mybackend"}, &policy)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.headerPolicies["mybackend"], check.DeepEquals, policy)
+	err = headerRouter.SetHeaderPolicy(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.headerPolicies, check.HasLen, 0)
+	err = headerRouter.SetHeaderPolicy(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	err = headerRouter.SetHeaderPolicy(routertest.FakeApp{Name: "invalid"}, &policy)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestSetMirror(c *check.C) {
+	mirror := router.Mirror{Target: "myapp-next", Percentage: 10}
+	err := s.testRouter.SetMirror(routertest.FakeApp{Name: "mybackend"}, &mirror)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.mirrors["mybackend"], check.DeepEquals, mirror)
+	err = s.testRouter.SetMirror(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.mirrors, check.HasLen, 0)
+	err = s.testRouter.SetMirror(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	err = s.testRouter.SetMirror(routertest.FakeApp{Name: "invalid"}, &mirror)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestSetAccessLog(c *check.C) {
+	config := router.AccessLogConfig{Enabled: true, SampleRate: 0.5, Fields: []string{"path", "status"}}
+	err := s.testRouter.SetAccessLog(routertest.FakeApp{Name: "mybackend"}, &config)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.accessLogs["mybackend"], check.DeepEquals, config)
+	err = s.testRouter.SetAccessLog(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.accessLogs, check.HasLen, 0)
+	err = s.testRouter.SetAccessLog(routertest.FakeApp{Name: "invalid"}, &config)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestAccessLogs(c *check.C) {
+	since := time.Date(2018, 5, 10, 12, 0, 0, 0, time.UTC)
+	var gotSince string
+	s.apiRouter.router.HandleFunc("/backend/{name}/access-log", func(w http.ResponseWriter, r *http.Request) {
+		gotSince = r.URL.Query().Get("since")
+		w.Write([]byte(`[{"time": "2018-05-10T12:00:01Z", "fields": {"path": "/", "status": "200"}}]`))
+	}).Methods(http.MethodGet)
+	entries, err := s.testRouter.AccessLogs("mybackend", since)
+	c.Assert(err, check.IsNil)
+	c.Assert(gotSince, check.Equals, "2018-05-10T12:00:00Z")
+	c.Assert(entries, check.DeepEquals, []router.AccessLogEntry{
+		{Time: since.Add(time.Second), Fields: map[string]string{"path": "/", "status": "200"}},
+	})
+}
+
+func (s *S) TestSetErrorPages(c *check.C) {
+	pages := []router.ErrorPage{{StatusCode: 503, ContentType: "text/html", Body: "<h1>maintenance</h1>"}}
+	err := s.testRouter.SetErrorPages(routertest.FakeApp{Name: "mybackend"}, pages)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.errorPages["mybackend"], check.DeepEquals, pages)
+	err = s.testRouter.SetErrorPages(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.errorPages, check.HasLen, 0)
+	err = s.testRouter.SetErrorPages(routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	err = s.testRouter.SetErrorPages(routertest.FakeApp{Name: "invalid"}, pages)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestRoutesStatus(c *check.C) {
+	s.apiRouter.router.HandleFunc("/backend/{name}/routes/status", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["name"] != "mybackend" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[{"address": "http://10.0.0.1:8080", "status": "up", "responseTime": 2000000}, {"address": "http://10.0.0.2:8080", "status": "down", "lastFailure": "connection refused"}]`))
+	}).Methods(http.MethodGet)
+	routes, err := s.testRouter.RoutesStatus("mybackend")
+	c.Assert(err, check.IsNil)
+	c.Assert(routes, check.DeepEquals, []router.RouteStatus{
+		{Address: "http://10.0.0.1:8080", Status: router.RouteHealthUp, ResponseTime: 2 * time.Millisecond},
+		{Address: "http://10.0.0.2:8080", Status: router.RouteHealthDown, LastFailure: "connection refused"},
+	})
+	err = router.Store("otherbackend", "otherbackend", "api")
+	c.Assert(err, check.IsNil)
+	_, err = s.testRouter.RoutesStatus("otherbackend")
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestLastRequest(c *check.C) {
+	s.apiRouter.router.HandleFunc("/backend/{name}/last-request", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["name"] != "mybackend" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"lastRequest": "2018-03-04T05:06:07Z"}`))
+	}).Methods(http.MethodGet)
+	last, err := s.testRouter.LastRequest("mybackend")
+	c.Assert(err, check.IsNil)
+	c.Assert(last.Equal(time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC)), check.Equals, true)
+	err = router.Store("otherbackend", "otherbackend", "api")
+	c.Assert(err, check.IsNil)
+	_, err = s.testRouter.LastRequest("otherbackend")
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
 func (s *S) TestGetCertificate(c *check.C) {
 	tlsRouter := &apiRouterWithTLSSupport{s.testRouter}
 	err := tlsRouter.AddCertificate(routertest.FakeApp{Name: "myapp"}, "cname.com", "cert", "key")
@@ -356,8 +530,17 @@ func (s *S) TestCreateRouterSupport(c *check.C) {
 		expectCname bool
 		expectTLS   bool
 		expectHC    bool
+		expectTLSP  bool
+		expectProto bool
+		expectStick bool
+		expectHdrs  bool
+		expectMirr  bool
+		expectALog  bool
+		expectErrPg bool
+		expectUStat bool
+		expectTraff bool
 	}{
-		{nil, false, false, false},
+		{nil, false, false, false, false, false, false, false, false, false, false, false, false},
 		{features: map[string]bool{"cname": true}, expectCname: true},
 		{features: map[string]bool{"tls": true}, expectTLS: true},
 		{features: map[string]bool{"healthcheck": true}, expectHC: true},
@@ -365,6 +548,15 @@ func (s *S) TestCreateRouterSupport(c *check.C) {
 		{features: map[string]bool{"cname": true, "tls": true, "healthcheck": true}, expectCname: true, expectTLS: true, expectHC: true},
 		{features: map[string]bool{"cname": true, "healthcheck": true}, expectCname: true, expectHC: true},
 		{features: map[string]bool{"tls": true, "healthcheck": true}, expectTLS: true, expectHC: true},
+		{features: map[string]bool{"tls": true, "tls-policy": true}, expectTLS: true, expectTLSP: true},
+		{features: map[string]bool{"protocol": true}, expectProto: true},
+		{features: map[string]bool{"sticky-session": true, "cname": true}, expectStick: true, expectCname: true},
+		{features: map[string]bool{"header-policy": true, "tls": true}, expectHdrs: true, expectTLS: true},
+		{features: map[string]bool{"mirror": true}, expectMirr: true},
+		{features: map[string]bool{"access-log": true, "mirror": true}, expectALog: true, expectMirr: true},
+		{features: map[string]bool{"error-page": true}, expectErrPg: true},
+		{features: map[string]bool{"unit-status": true, "status": true}, expectUStat: true},
+		{features: map[string]bool{"traffic": true}, expectTraff: true},
 	}
 	var i int
 	s.apiRouter.router.HandleFunc("/support/{name}", func(w http.ResponseWriter, r *http.Request) {
@@ -390,6 +582,21 @@ func (s *S) TestCreateRouterSupport(c *check.C) {
 		c.Assert(ok, check.Equals, tt[i].expectTLS, comment)
 		_, ok = r.(router.CustomHealthcheckRouter)
 		c.Assert(ok, check.Equals, tt[i].expectHC, comment)
+		_, ok = r.(router.TLSPolicyRouter)
+		c.Assert(ok, check.Equals, tt[i].expectTLSP, comment)
+		_, ok = r.(router.ProtocolRouter)
+		c.Assert(ok, check.Equals, tt[i].expectProto, comment)
+		_, ok = r.(router.StickySessionRouter)
+		c.Assert(ok, check.Equals, tt[i].expectStick, comment)
+		_, ok = r.(router.HeaderPolicyRouter)
+		c.Assert(ok, check.Equals, tt[i].expectHdrs, comment)
+		_, ok = r.(router.MirrorRouter)
+		c.Assert(ok, check.Equals, true, comment)
+		c.Assert(router.Supports(r, router.CapabilityMirror), check.Equals, tt[i].expectMirr, comment)
+		c.Assert(router.Supports(r, router.CapabilityAccessLog), check.Equals, tt[i].expectALog, comment)
+		c.Assert(router.Supports(r, router.CapabilityErrorPage), check.Equals, tt[i].expectErrPg, comment)
+		c.Assert(router.Supports(r, router.CapabilityStatusReporter), check.Equals, tt[i].expectUStat, comment)
+		c.Assert(router.Supports(r, router.CapabilityTraffic), check.Equals, tt[i].expectTraff, comment)
 	}
 }
 
@@ -431,7 +638,21 @@ func newFakeRouter(c *check.C) *fakeRouterAPI {
 	r.HandleFunc("/backend/{name}/certificate/{cname}", api.addCertificate).Methods(http.MethodPut)
 	r.HandleFunc("/backend/{name}/certificate/{cname}", api.removeCertificate).Methods(http.MethodDelete)
 	r.HandleFunc("/backend/{name}/status", api.getStatusBackend).Methods(http.MethodGet)
+	r.HandleFunc("/backend/{name}/tls-policy", api.setTLSPolicy).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/tls-policy", api.removeTLSPolicy).Methods(http.MethodDelete)
+	r.HandleFunc("/backend/{name}/protocols", api.setProtocols).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/sticky-session", api.setStickySession).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/sticky-session", api.removeStickySession).Methods(http.MethodDelete)
+	r.HandleFunc("/backend/{name}/header-policy", api.setHeaderPolicy).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/header-policy", api.removeHeaderPolicy).Methods(http.MethodDelete)
+	r.HandleFunc("/backend/{name}/mirror", api.setMirror).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/mirror", api.removeMirror).Methods(http.MethodDelete)
+	r.HandleFunc("/backend/{name}/access-log", api.setAccessLog).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/access-log", api.removeAccessLog).Methods(http.MethodDelete)
+	r.HandleFunc("/backend/{name}/error-pages", api.setErrorPages).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/error-pages", api.removeErrorPages).Methods(http.MethodDelete)
 	r.HandleFunc("/info", api.getInfo).Methods(http.MethodGet)
+	r.HandleFunc("/protocols", api.getProtocols).Methods(http.MethodGet)
 
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	c.Assert(err, check.IsNil)
@@ -453,11 +674,18 @@ type backend struct {
 }
 
 type fakeRouterAPI struct {
-	listener     net.Listener
-	backends     map[string]*backend
-	certificates map[string]certData
-	endpoint     string
-	router       *mux.Router
+	listener       net.Listener
+	backends       map[string]*backend
+	certificates   map[string]certData
+	tlsPolicies    map[string]router.TLSPolicy
+	protocols      map[string]map[string]string
+	stickySessions map[string]router.StickySession
+	headerPolicies map[string]router.HeaderPolicy
+	mirrors        map[string]router.Mirror
+	accessLogs     map[string]router.AccessLogConfig
+	errorPages     map[string][]router.ErrorPage
+	endpoint       string
+	router         *mux.Router
 }
 
 func (f *fakeRouterAPI) getInfo(w http.ResponseWriter, r *http.Request) {
@@ -718,6 +946,137 @@ func (f *fakeRouterAPI) addCertificate(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (f *fakeRouterAPI) setTLSPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy router.TLSPolicy
+	json.NewDecoder(r.Body).Decode(&policy)
+	f.tlsPolicies[mux.Vars(r)["name"]] = policy
+}
+
+func (f *fakeRouterAPI) removeTLSPolicy(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.tlsPolicies[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(f.tlsPolicies, name)
+}
+
+func (f *fakeRouterAPI) setStickySession(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.backends[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var session router.StickySession
+	json.NewDecoder(r.Body).Decode(&session)
+	f.stickySessions[name] = session
+}
+
+func (f *fakeRouterAPI) removeStickySession(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.stickySessions[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(f.stickySessions, name)
+}
+
+func (f *fakeRouterAPI) setHeaderPolicy(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.backends[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var policy router.HeaderPolicy
+	json.NewDecoder(r.Body).Decode(&policy)
+	f.headerPolicies[name] = policy
+}
+
+func (f *fakeRouterAPI) removeHeaderPolicy(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.headerPolicies[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(f.headerPolicies, name)
+}
+
+func (f *fakeRouterAPI) setMirror(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.backends[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var mirror router.Mirror
+	json.NewDecoder(r.Body).Decode(&mirror)
+	f.mirrors[name] = mirror
+}
+
+func (f *fakeRouterAPI) removeMirror(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.mirrors[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(f.mirrors, name)
+}
+
+func (f *fakeRouterAPI) setAccessLog(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.backends[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var config router.AccessLogConfig
+	json.NewDecoder(r.Body).Decode(&config)
+	f.accessLogs[name] = config
+}
+
+func (f *fakeRouterAPI) removeAccessLog(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.accessLogs[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(f.accessLogs, name)
+}
+
+func (f *fakeRouterAPI) setErrorPages(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.backends[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var pages []router.ErrorPage
+	json.NewDecoder(r.Body).Decode(&pages)
+	f.errorPages[name] = pages
+}
+
+func (f *fakeRouterAPI) removeErrorPages(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.errorPages[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(f.errorPages, name)
+}
+
+func (f *fakeRouterAPI) getProtocols(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"protocols": ["http", "h2c", "websocket"]}`))
+}
+
+func (f *fakeRouterAPI) setProtocols(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if _, ok := f.backends[name]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req backendProtocolsReq
+	json.NewDecoder(r.Body).Decode(&req)
+	f.protocols[name] = req.Protocols
+}
+
 func (f *fakeRouterAPI) removeCertificate(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	cname := vars["cname"]
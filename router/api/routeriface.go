@@ -13,23 +13,27 @@ import (
 
 func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.Router {
 	apiRouterWithCnameSupportInst := &apiRouterWithCnameSupport{base}
+	apiRouterWithHeaderPolicySupportInst := &apiRouterWithHeaderPolicySupport{base}
 	apiRouterWithHealthcheckSupportInst := &apiRouterWithHealthcheckSupport{base}
 	apiRouterWithInfoInst := &apiRouterWithInfo{base}
+	apiRouterWithProtocolSupportInst := &apiRouterWithProtocolSupport{base}
 	apiRouterWithStatusInst := &apiRouterWithStatus{base}
+	apiRouterWithStickySessionSupportInst := &apiRouterWithStickySessionSupport{base}
 	apiRouterWithTLSSupportInst := &apiRouterWithTLSSupport{base}
+	apiRouterWithTLSPolicySupportInst := &apiRouterWithTLSPolicySupport{base}
 
-	if !supports["cname"] && !supports["healthcheck"] && !supports["info"] && !supports["status"] && !supports["tls"] {
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 		}{
 			base,
 			base,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && !supports["info"] && !supports["status"] && !supports["tls"] {
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
 		}{
@@ -38,432 +42,9200 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			apiRouterWithCnameSupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && !supports["info"] && !supports["status"] && !supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.HeaderPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && !supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && !supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && !supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && !supports["info"] && !supports["status"] && !supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && !supports["healthcheck"] && supports["info"] && !supports["status"] && !supports["tls"] {
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && supports["info"] && !supports["status"] && !supports["tls"] {
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && supports["info"] && !supports["status"] && !supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && supports["info"] && !supports["status"] && !supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && !supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.ProtocolRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && !supports["healthcheck"] && !supports["info"] && supports["status"] && !supports["tls"] {
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && !supports["info"] && supports["status"] && !supports["tls"] {
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && !supports["info"] && supports["status"] && !supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
-			router.CustomHealthcheckRouter
+			router.HeaderPolicyRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
-			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && !supports["info"] && supports["status"] && !supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
-			router.CustomHealthcheckRouter
+			router.HeaderPolicyRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
-			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && !supports["healthcheck"] && supports["info"] && supports["status"] && !supports["tls"] {
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
-			router.InfoRouter
+			router.CustomHealthcheckRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
-			apiRouterWithInfoInst,
+			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && supports["info"] && supports["status"] && !supports["tls"] {
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
-			router.InfoRouter
+			router.CustomHealthcheckRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
-			apiRouterWithInfoInst,
+			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && supports["info"] && supports["status"] && !supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
-			router.InfoRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
-			apiRouterWithInfoInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && supports["info"] && supports["status"] && !supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
-			router.InfoRouter
 			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
-			apiRouterWithInfoInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && !supports["healthcheck"] && !supports["info"] && !supports["status"] && supports["tls"] {
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && !supports["info"] && !supports["status"] && supports["tls"] {
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && !supports["info"] && !supports["status"] && supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
-			router.CustomHealthcheckRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
-			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && !supports["info"] && !supports["status"] && supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
-			router.CustomHealthcheckRouter
+			router.HeaderPolicyRouter
+			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
-			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && !supports["healthcheck"] && supports["info"] && !supports["status"] && supports["tls"] {
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && supports["info"] && !supports["status"] && supports["tls"] {
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && supports["info"] && !supports["status"] && supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && supports["info"] && !supports["status"] && supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && !supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.HeaderPolicyRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && !supports["healthcheck"] && !supports["info"] && supports["status"] && supports["tls"] {
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && !supports["info"] && supports["status"] && supports["tls"] {
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && !supports["info"] && supports["status"] && supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && !supports["info"] && supports["status"] && supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && !supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && !supports["healthcheck"] && supports["info"] && supports["status"] && supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.HeaderPolicyRouter
 			router.InfoRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && !supports["healthcheck"] && supports["info"] && supports["status"] && supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && !supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.HeaderPolicyRouter
 			router.InfoRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if !supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithHealthcheckSupportInst,
+			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
+			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
+			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
+		}
+	}
+	if supports["cname"] && !supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
+		return &struct {
+			baseRouter
+			router.OptsRouter
+			router.CNameRouter
+			router.CustomHealthcheckRouter
+			router.InfoRouter
+			router.ProtocolRouter
+			router.StatusRouter
+			router.StickySessionRouter
+			router.TLSRouter
+			router.TLSPolicyRouter
+		}{
+			base,
+			base,
+			apiRouterWithCnameSupportInst,
+			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if !supports["cname"] && supports["healthcheck"] && supports["info"] && supports["status"] && supports["tls"] {
+	if !supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
-	if supports["cname"] && supports["healthcheck"] && supports["info"] && supports["status"] && supports["tls"] {
+	if supports["cname"] && supports["header-policy"] && supports["healthcheck"] && supports["info"] && supports["protocol"] && supports["status"] && supports["sticky-session"] && supports["tls"] && supports["tls-policy"] {
 		return &struct {
-			router.Router
+			baseRouter
 			router.OptsRouter
 			router.CNameRouter
+			router.HeaderPolicyRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
+			router.ProtocolRouter
 			router.StatusRouter
+			router.StickySessionRouter
 			router.TLSRouter
+			router.TLSPolicyRouter
 		}{
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
+			apiRouterWithHeaderPolicySupportInst,
 			apiRouterWithHealthcheckSupportInst,
 			apiRouterWithInfoInst,
+			apiRouterWithProtocolSupportInst,
 			apiRouterWithStatusInst,
+			apiRouterWithStickySessionSupportInst,
 			apiRouterWithTLSSupportInst,
+			apiRouterWithTLSPolicySupportInst,
 		}
 	}
 	return nil
@@ -0,0 +1,73 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// CapabilityErrorPage is the capability of routers implementing
+// ErrorPageRouter.
+const CapabilityErrorPage = "error-page"
+
+// DefaultErrorPageContentType is the content type of error pages not
+// defining one.
+const DefaultErrorPageContentType = "text/html; charset=utf-8"
+
+// MaxErrorPageSize is the maximum size, in bytes, of the body of an error
+// page.
+const MaxErrorPageSize = 256 * 1024
+
+// ErrorPageStatusCodes are the status codes of the responses routers send
+// when no healthy backend unit is available, which apps may customize.
+var ErrorPageStatusCodes = []int{http.StatusBadGateway, http.StatusServiceUnavailable}
+
+// ErrorPage is a static response served by routers instead of their default
+// one when no healthy unit of the backend is able to handle a request.
+type ErrorPage struct {
+	StatusCode  int    `json:"statusCode"`
+	ContentType string `json:"contentType"`
+	Body        string `json:"body"`
+}
+
+// ErrorPageRouter is a router able to serve custom error pages for backends.
+// Pages not in the given list go back to the router default, an empty list
+// removes every custom page.
+type ErrorPageRouter interface {
+	SetErrorPages(app App, pages []ErrorPage) error
+}
+
+// Validate checks whether the status code of the page can be customized and
+// its content type and size are valid.
+func (p *ErrorPage) Validate() error {
+	if !isErrorPageStatusCode(p.StatusCode) {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid status code %d, error pages are only available for %v", p.StatusCode, ErrorPageStatusCodes)}
+	}
+	if p.Body == "" {
+		return &tsuruErrors.ValidationError{Message: "Error page content must not be empty"}
+	}
+	if len(p.Body) > MaxErrorPageSize {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Error page content must have at most %d bytes", MaxErrorPageSize)}
+	}
+	if p.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(p.ContentType); err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid content type %q", p.ContentType)}
+		}
+	}
+	return nil
+}
+
+func isErrorPageStatusCode(code int) bool {
+	for _, c := range ErrorPageStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestErrorPageValidate(c *check.C) {
+	tests := []struct {
+		page ErrorPage
+		err  string
+	}{
+		{page: ErrorPage{StatusCode: 502, Body: "<h1>down</h1>"}},
+		{page: ErrorPage{StatusCode: 503, ContentType: "application/json", Body: `{"error": "unavailable"}`}},
+		{page: ErrorPage{StatusCode: 500, Body: "oops"}, err: `Invalid status code 500, error pages are only available for \[502 503\]`},
+		{page: ErrorPage{StatusCode: 503}, err: "Error page content must not be empty"},
+		{page: ErrorPage{StatusCode: 503, Body: strings.Repeat("a", MaxErrorPageSize+1)}, err: "Error page content must have at most .* bytes"},
+		{page: ErrorPage{StatusCode: 502, ContentType: "text/", Body: "x"}, err: `Invalid content type "text/"`},
+	}
+	for i, tt := range tests {
+		err := tt.page.Validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("case %d", i))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("case %d", i))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// reservedHeaders are the response headers managed by routers themselves,
+// which apps are not allowed to inject.
+var reservedHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Keep-Alive":        true,
+	"Set-Cookie":        true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+// HeaderPolicy holds the response header rules routers should apply to the
+// responses of the backend of an app.
+type HeaderPolicy struct {
+	CORS    *CORSPolicy       `json:"cors,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// CORSPolicy holds the rules used by routers to answer cross-origin
+// requests.
+type CORSPolicy struct {
+	AllowedOrigins   []string `json:"allowedOrigins"`
+	AllowedMethods   []string `json:"allowedMethods,omitempty"`
+	AllowedHeaders   []string `json:"allowedHeaders,omitempty"`
+	ExposedHeaders   []string `json:"exposedHeaders,omitempty"`
+	AllowCredentials bool     `json:"allowCredentials"`
+	MaxAge           int      `json:"maxAge"`
+}
+
+// HeaderPolicyRouter is a router able to apply header policies to the
+// responses of backends. A nil policy removes the rules.
+type HeaderPolicyRouter interface {
+	SetHeaderPolicy(app App, policy *HeaderPolicy) error
+}
+
+// Validate checks whether the policy only contains valid header names and
+// CORS rules.
+func (p *HeaderPolicy) Validate() error {
+	for name, value := range p.Headers {
+		err := validateHeaderName(name)
+		if err != nil {
+			return err
+		}
+		if reservedHeaders[http.CanonicalHeaderKey(name)] {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Header %q is managed by the router and cannot be set", name)}
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid value for header %q, it must not contain line breaks", name)}
+		}
+	}
+	if p.CORS != nil {
+		return p.CORS.Validate()
+	}
+	return nil
+}
+
+// Validate checks whether the origins, methods and headers of the policy are
+// well formed.
+func (p *CORSPolicy) Validate() error {
+	if len(p.AllowedOrigins) == 0 {
+		return &tsuruErrors.ValidationError{Message: "CORS policy requires at least one allowed origin"}
+	}
+	for _, origin := range p.AllowedOrigins {
+		if origin == "*" {
+			if p.AllowCredentials {
+				return &tsuruErrors.ValidationError{Message: "CORS policy cannot allow credentials for any origin"}
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.TrimSuffix(u.Path, "/") != "" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid CORS origin %q, it must be * or a scheme and host, like https://example.com", origin)}
+		}
+	}
+	for _, method := range p.AllowedMethods {
+		if !tokenRegexp.MatchString(method) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid CORS method %q", method)}
+		}
+	}
+	for _, names := range [][]string{p.AllowedHeaders, p.ExposedHeaders} {
+		for _, name := range names {
+			err := validateHeaderName(name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if p.MaxAge < 0 {
+		return &tsuruErrors.ValidationError{Message: "Invalid CORS max-age, it must not be negative"}
+	}
+	return nil
+}
+
+func validateHeaderName(name string) error {
+	if !tokenRegexp.MatchString(name) {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid header name %q", name)}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestHeaderPolicyValidate(c *check.C) {
+	tests := []struct {
+		policy HeaderPolicy
+		err    string
+	}{
+		{policy: HeaderPolicy{}},
+		{policy: HeaderPolicy{Headers: map[string]string{"X-Frame-Options": "DENY", "Strict-Transport-Security": "max-age=31536000"}}},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "POST"}, AllowedHeaders: []string{"Authorization"}, MaxAge: 600}}},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"https://example.com", "http://localhost:8080/"}, AllowCredentials: true}}},
+		{policy: HeaderPolicy{Headers: map[string]string{"X Frame": "DENY"}}, err: `Invalid header name "X Frame"`},
+		{policy: HeaderPolicy{Headers: map[string]string{"set-cookie": "a=b"}}, err: `Header "set-cookie" is managed by the router and cannot be set`},
+		{policy: HeaderPolicy{Headers: map[string]string{"X-Test": "a\r\nX-Other: b"}}, err: `Invalid value for header "X-Test", it must not contain line breaks`},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{}}, err: "CORS policy requires at least one allowed origin"},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}}, err: "CORS policy cannot allow credentials for any origin"},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"example.com"}}}, err: `Invalid CORS origin "example.com", .*`},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"https://example.com/path"}}}, err: `Invalid CORS origin "https://example.com/path", .*`},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET POST"}}}, err: `Invalid CORS method "GET POST"`},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"*"}, ExposedHeaders: []string{"X:Y"}}}, err: `Invalid header name "X:Y"`},
+		{policy: HeaderPolicy{CORS: &CORSPolicy{AllowedOrigins: []string{"*"}, MaxAge: -1}}, err: "Invalid CORS max-age, it must not be negative"},
+	}
+	for i, tt := range tests {
+		err := tt.policy.Validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("case %d", i))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("case %d", i))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// CapabilityMirror is the capability of routers implementing MirrorRouter.
+const CapabilityMirror = "mirror"
+
+// Mirror holds the settings used by routers to copy requests sent to the
+// backend of an app to the backend of another app. Responses from the target
+// are discarded.
+type Mirror struct {
+	Target     string `json:"target"`
+	Percentage int    `json:"percentage"`
+}
+
+// MirrorRouter is a router able to mirror traffic between backends. A nil
+// mirror stops the mirroring.
+type MirrorRouter interface {
+	SetMirror(app App, mirror *Mirror) error
+}
+
+// Validate checks whether the mirror has a target and a percentage between
+// 1 and 100.
+func (m *Mirror) Validate() error {
+	if m.Target == "" {
+		return &tsuruErrors.ValidationError{Message: "Mirror target is required"}
+	}
+	if m.Percentage < 1 || m.Percentage > 100 {
+		return &tsuruErrors.ValidationError{Message: "Invalid mirror percentage, it must be between 1 and 100"}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestMirrorValidate(c *check.C) {
+	tests := []struct {
+		mirror Mirror
+		err    string
+	}{
+		{mirror: Mirror{Target: "myapp-next", Percentage: 1}},
+		{mirror: Mirror{Target: "myapp-next", Percentage: 100}},
+		{mirror: Mirror{Percentage: 10}, err: "Mirror target is required"},
+		{mirror: Mirror{Target: "myapp-next"}, err: "Invalid mirror percentage, it must be between 1 and 100"},
+		{mirror: Mirror{Target: "myapp-next", Percentage: 101}, err: "Invalid mirror percentage, it must be between 1 and 100"},
+	}
+	for i, tt := range tests {
+		err := tt.mirror.Validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("case %d", i))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("case %d", i))
+		}
+	}
+}
+
+type testCapabilityRouter struct {
+	testNamedRouter
+	capabilities map[string]bool
+}
+
+func (r *testCapabilityRouter) SupportsCapability(capability string) bool {
+	return r.capabilities[capability]
+}
+
+func (s *S) TestSupports(c *check.C) {
+	c.Assert(Supports(&testNamedRouter{name: "plain"}, CapabilityMirror), check.Equals, true)
+	r := &testCapabilityRouter{capabilities: map[string]bool{CapabilityMirror: true}}
+	c.Assert(Supports(r, CapabilityMirror), check.Equals, true)
+	c.Assert(Supports(r, "other"), check.Equals, false)
+}
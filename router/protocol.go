@@ -0,0 +1,87 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const (
+	ProtocolHTTP      = "http"
+	ProtocolH2C       = "h2c"
+	ProtocolGRPC      = "grpc"
+	ProtocolWebsocket = "websocket"
+)
+
+// BackendProtocols are the protocols processes may declare for their
+// backends. HTTP is the default and supported by every router.
+var BackendProtocols = []string{ProtocolHTTP, ProtocolH2C, ProtocolGRPC, ProtocolWebsocket}
+
+// ProtocolRouter is a router able to forward requests to backends using
+// protocols other than HTTP/1.1.
+type ProtocolRouter interface {
+	SupportedProtocols() ([]string, error)
+	// SetBackendProtocols configures the protocol of each process of the
+	// app, processes not in the map use HTTP.
+	SetBackendProtocols(app App, protocols map[string]string) error
+}
+
+type ErrProtocolNotSupported struct {
+	Router   string
+	Protocol string
+}
+
+func (e *ErrProtocolNotSupported) Error() string {
+	return fmt.Sprintf("router %q does not support the %q protocol", e.Router, e.Protocol)
+}
+
+// ValidateProtocol checks whether the protocol is a known backend protocol.
+func ValidateProtocol(protocol string) error {
+	for _, p := range BackendProtocols {
+		if p == protocol {
+			return nil
+		}
+	}
+	return &tsuruErrors.ValidationError{
+		Message: fmt.Sprintf("Invalid protocol %q, possible values are: %s", protocol, strings.Join(BackendProtocols, ", ")),
+	}
+}
+
+// CheckProtocolsSupport returns an error if the router is unable to handle
+// any of the given protocols, keyed by process name.
+func CheckProtocolsSupport(r Router, protocols map[string]string) error {
+	var required []string
+	for _, protocol := range protocols {
+		if protocol != ProtocolHTTP {
+			required = append(required, protocol)
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+	sort.Strings(required)
+	protoRouter, ok := r.(ProtocolRouter)
+	if !ok {
+		return &ErrProtocolNotSupported{Router: r.GetName(), Protocol: required[0]}
+	}
+	supported, err := protoRouter.SupportedProtocols()
+	if err != nil {
+		return err
+	}
+	supportedSet := make(map[string]bool, len(supported))
+	for _, p := range supported {
+		supportedSet[p] = true
+	}
+	for _, p := range required {
+		if !supportedSet[p] {
+			return &ErrProtocolNotSupported{Router: r.GetName(), Protocol: p}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"gopkg.in/check.v1"
+)
+
+type testNamedRouter struct {
+	Router
+	name string
+}
+
+func (r *testNamedRouter) GetName() string {
+	return r.name
+}
+
+type testProtocolRouter struct {
+	testNamedRouter
+	supported []string
+}
+
+func (r *testProtocolRouter) SupportedProtocols() ([]string, error) {
+	return r.supported, nil
+}
+
+func (r *testProtocolRouter) SetBackendProtocols(app App, protocols map[string]string) error {
+	return nil
+}
+
+func (s *S) TestValidateProtocol(c *check.C) {
+	for _, p := range BackendProtocols {
+		c.Assert(ValidateProtocol(p), check.IsNil)
+	}
+	err := ValidateProtocol("spdy")
+	c.Assert(err, check.ErrorMatches, `Invalid protocol "spdy", possible values are: http, h2c, grpc, websocket`)
+}
+
+func (s *S) TestCheckProtocolsSupport(c *check.C) {
+	plain := &testNamedRouter{name: "plain"}
+	c.Assert(CheckProtocolsSupport(plain, nil), check.IsNil)
+	c.Assert(CheckProtocolsSupport(plain, map[string]string{"web": ProtocolHTTP}), check.IsNil)
+	err := CheckProtocolsSupport(plain, map[string]string{"web": ProtocolGRPC})
+	c.Assert(err, check.DeepEquals, &ErrProtocolNotSupported{Router: "plain", Protocol: ProtocolGRPC})
+	c.Assert(err, check.ErrorMatches, `router "plain" does not support the "grpc" protocol`)
+	proto := &testProtocolRouter{
+		testNamedRouter: testNamedRouter{name: "proto"},
+		supported:       []string{ProtocolH2C, ProtocolWebsocket},
+	}
+	err = CheckProtocolsSupport(proto, map[string]string{"web": ProtocolH2C, "ws": ProtocolWebsocket, "worker": ProtocolHTTP})
+	c.Assert(err, check.IsNil)
+	err = CheckProtocolsSupport(proto, map[string]string{"web": ProtocolH2C, "api": ProtocolGRPC})
+	c.Assert(err, check.DeepEquals, &ErrProtocolNotSupported{Router: "proto", Protocol: ProtocolGRPC})
+}
@@ -11,9 +11,13 @@ import (
 	"github.com/tsuru/monsterqueue"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/queue"
+	"github.com/tsuru/tsuru/retry"
 )
 
-const routesRebuildTaskName = "rebuildRoutesTask"
+const (
+	routesRebuildTaskName  = "rebuildRoutesTask"
+	routesRebuildRetryKind = "rebuild-routes"
+)
 
 var routesRebuildRetryTime = 10 * time.Second
 
@@ -31,14 +35,20 @@ func (t *routesRebuildTask) Run(job monsterqueue.Job) {
 	if !ok {
 		job.Error(errors.New("invalid parameters, expected appName"))
 	}
-	for !runRoutesRebuildOnce(appName, true) {
+	for runRoutesRebuildOnce(appName, true) != nil {
 		time.Sleep(routesRebuildRetryTime)
 	}
 	job.Success(nil)
 }
 
+// RegisterTask registers the routes rebuild handler in the retry mechanism.
+// The queue task is still registered so jobs enqueued by previous versions
+// are processed.
 func RegisterTask(finder func(string) (RebuildApp, error)) error {
 	appFinder = finder
+	retry.RegisterHandler(routesRebuildRetryKind, func(params map[string]string) error {
+		return runRoutesRebuildOnce(params["appName"], true)
+	})
 	q, err := queue.Queue()
 	if err != nil {
 		return err
@@ -46,33 +56,36 @@ func RegisterTask(finder func(string) (RebuildApp, error)) error {
 	return q.RegisterTask(&routesRebuildTask{})
 }
 
-func runRoutesRebuildOnce(appName string, lock bool) bool {
+func runRoutesRebuildOnce(appName string, lock bool) error {
 	if appFinder == nil {
-		return false
+		return errors.New("routes rebuild task not registered")
 	}
 	a, err := appFinder(appName)
 	if err != nil {
 		log.Errorf("[routes-rebuild-task] error getting app %q: %s", appName, err)
-		return false
+		return err
 	}
 	if a == nil {
 		log.Errorf("[routes-rebuild-task] app %q not found, aborting", appName)
-		return true
+		return nil
 	}
 	if lock {
 		var locked bool
 		locked, err = a.InternalLock("rebuild-routes-task")
-		if err != nil || !locked {
-			return false
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return errors.Errorf("unable to lock app %q", appName)
 		}
 		defer a.Unlock()
 	}
 	_, err = RebuildRoutes(a, false)
 	if err != nil {
 		log.Errorf("[routes-rebuild-task] error rebuilding app %q: %s", appName, err)
-		return false
+		return err
 	}
-	return true
+	return nil
 }
 
 func RoutesRebuildOrEnqueue(appName string) {
@@ -84,19 +97,13 @@ func LockedRoutesRebuildOrEnqueue(appName string) {
 }
 
 func routesRebuildOrEnqueueOptionalLock(appName string, lock bool) {
-	if runRoutesRebuildOnce(appName, lock) {
+	if runRoutesRebuildOnce(appName, lock) == nil {
 		return
 	}
-	q, err := queue.Queue()
-	if err != nil {
-		log.Errorf("unable to enqueue rebuild routes task: %s", err)
-		return
-	}
-	_, err = q.Enqueue(routesRebuildTaskName, monsterqueue.JobParams{
+	_, err := retry.Enqueue(routesRebuildRetryKind, map[string]string{
 		"appName": appName,
 	})
 	if err != nil {
 		log.Errorf("unable to enqueue rebuild routes task: %s", err)
-		return
 	}
 }
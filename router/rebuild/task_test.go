@@ -6,10 +6,9 @@ package rebuild_test
 
 import (
 	"net/url"
-	"time"
 
 	"github.com/tsuru/tsuru/app"
-	"github.com/tsuru/tsuru/queue"
+	"github.com/tsuru/tsuru/retry"
 	"github.com/tsuru/tsuru/router/rebuild"
 	"github.com/tsuru/tsuru/router/routertest"
 	"gopkg.in/check.v1"
@@ -46,8 +45,12 @@ func (s *S) TestRoutesRebuildOrEnqueueForceEnqueue(c *check.C) {
 	routertest.FakeRouter.FailForIp(invalidAddr.String())
 	rebuild.RoutesRebuildOrEnqueue(a.GetName())
 	c.Assert(routertest.FakeRouter.HasRoute(a.GetName(), invalidAddr.String()), check.Equals, true)
+	ops, err := retry.List(retry.StatusPending)
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 1)
+	c.Assert(ops[0].Params, check.DeepEquals, map[string]string{"appName": a.GetName()})
 	routertest.FakeRouter.RemoveFailForIp(invalidAddr.String())
-	err = queue.TestingWaitQueueTasks(1, 10*time.Second)
+	err = retry.RunPending()
 	c.Assert(err, check.IsNil)
 	c.Assert(routertest.FakeRouter.HasRoute(a.GetName(), invalidAddr.String()), check.Equals, false)
 }
@@ -70,7 +73,7 @@ func (s *S) TestRoutesRebuildOrEnqueueLocked(c *check.C) {
 	rebuild.LockedRoutesRebuildOrEnqueue(a.GetName())
 	c.Assert(routertest.FakeRouter.HasRoute(a.GetName(), invalidAddr.String()), check.Equals, true)
 	app.ReleaseApplicationLock(a.Name)
-	err = queue.TestingWaitQueueTasks(1, 10*time.Second)
+	err = retry.RunPending()
 	c.Assert(err, check.IsNil)
 	c.Assert(routertest.FakeRouter.HasRoute(a.GetName(), invalidAddr.String()), check.Equals, false)
 }
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
@@ -179,6 +180,45 @@ type StatusRouter interface {
 	GetBackendStatus(name string) (status BackendStatus, detail string, err error)
 }
 
+// CapabilityTraffic is the capability of routers implementing TrafficRouter.
+const CapabilityTraffic = "traffic"
+
+// TrafficRouter is a router able to tell when a backend last received a
+// request. A zero time means the backend never received requests.
+type TrafficRouter interface {
+	LastRequest(name string) (time.Time, error)
+}
+
+// BackendMetrics holds cumulative request counters for a backend since the
+// router started tracking it.
+type BackendMetrics struct {
+	Requests     uint64
+	Errors       uint64
+	TotalLatency time.Duration
+}
+
+// MetricsRouter is a router able to report request metrics for its backends,
+// they are periodically collected by tsuru and used by the app autoscaler.
+type MetricsRouter interface {
+	BackendMetrics(name string) (BackendMetrics, error)
+}
+
+// CapabilityRouter is implemented by routers whose support for some of the
+// optional interfaces they implement is only known at runtime, like routers
+// backed by a remote API.
+type CapabilityRouter interface {
+	SupportsCapability(capability string) bool
+}
+
+// Supports reports whether a router implementing the optional interface of
+// the given capability is able to handle it.
+func Supports(r Router, capability string) bool {
+	if capRouter, ok := r.(CapabilityRouter); ok {
+		return capRouter.SupportsCapability(capability)
+	}
+	return true
+}
+
 type HealthcheckData struct {
 	Path   string
 	Status int
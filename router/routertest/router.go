@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/router"
@@ -29,14 +30,54 @@ var InfoRouter = infoRouter{
 }
 
 var StatusRouter = statusRouter{
-	fakeRouter: newFakeRouter(),
-	Status:     router.BackendStatusReady,
+	fakeRouter:  newFakeRouter(),
+	Status:      router.BackendStatusReady,
+	RouteStatus: make(map[string]router.RouteStatus),
 }
 
 var TLSRouter = tlsRouter{
 	fakeRouter: newFakeRouter(),
 	Certs:      make(map[string]string),
 	Keys:       make(map[string]string),
+	Policies:   make(map[string]*router.TLSPolicy),
+	Headers:    make(map[string]*router.HeaderPolicy),
+}
+
+var TrafficRouter = trafficRouter{
+	fakeRouter:   newFakeRouter(),
+	LastRequests: make(map[string]time.Time),
+}
+
+var MetricsRouter = metricsRouter{
+	fakeRouter: newFakeRouter(),
+	Metrics:    make(map[string]router.BackendMetrics),
+}
+
+var ProtocolRouter = protocolRouter{
+	fakeRouter: newFakeRouter(),
+	Supported:  []string{router.ProtocolHTTP, router.ProtocolH2C, router.ProtocolGRPC, router.ProtocolWebsocket},
+	Protocols:  make(map[string]map[string]string),
+}
+
+var StickyRouter = stickyRouter{
+	fakeRouter: newFakeRouter(),
+	Sessions:   make(map[string]router.StickySession),
+}
+
+var MirrorRouter = mirrorRouter{
+	fakeRouter: newFakeRouter(),
+	Mirrors:    make(map[string]router.Mirror),
+}
+
+var AccessLogRouter = accessLogRouter{
+	fakeRouter: newFakeRouter(),
+	Configs:    make(map[string]router.AccessLogConfig),
+	Entries:    make(map[string][]router.AccessLogEntry),
+}
+
+var ErrorPageRouter = errorPageRouter{
+	fakeRouter: newFakeRouter(),
+	Pages:      make(map[string][]router.ErrorPage),
 }
 
 var ErrForcedFailure = errors.New("Forced failure")
@@ -48,6 +89,13 @@ func init() {
 	router.Register("fake-opts", createOptsRouter)
 	router.Register("fake-info", createInfoRouter)
 	router.Register("fake-status", createStatusRouter)
+	router.Register("fake-traffic", createTrafficRouter)
+	router.Register("fake-metrics", createMetricsRouter)
+	router.Register("fake-protocol", createProtocolRouter)
+	router.Register("fake-sticky", createStickyRouter)
+	router.Register("fake-mirror", createMirrorRouter)
+	router.Register("fake-accesslog", createAccessLogRouter)
+	router.Register("fake-errorpage", createErrorPageRouter)
 }
 
 func createRouter(name, prefix string) (router.Router, error) {
@@ -74,6 +122,34 @@ func createStatusRouter(name, prefix string) (router.Router, error) {
 	return &StatusRouter, nil
 }
 
+func createTrafficRouter(name, prefix string) (router.Router, error) {
+	return &TrafficRouter, nil
+}
+
+func createMetricsRouter(name, prefix string) (router.Router, error) {
+	return &MetricsRouter, nil
+}
+
+func createProtocolRouter(name, prefix string) (router.Router, error) {
+	return &ProtocolRouter, nil
+}
+
+func createStickyRouter(name, prefix string) (router.Router, error) {
+	return &StickyRouter, nil
+}
+
+func createMirrorRouter(name, prefix string) (router.Router, error) {
+	return &MirrorRouter, nil
+}
+
+func createAccessLogRouter(name, prefix string) (router.Router, error) {
+	return &AccessLogRouter, nil
+}
+
+func createErrorPageRouter(name, prefix string) (router.Router, error) {
+	return &ErrorPageRouter, nil
+}
+
 func newFakeRouter() fakeRouter {
 	return fakeRouter{cnames: make(map[string]string), backends: make(map[string][]string), failuresByIp: make(map[string]bool), healthcheck: make(map[string]router.HealthcheckData), mutex: &sync.Mutex{}}
 }
@@ -423,11 +499,17 @@ func (r *fakeRouter) RemoveHealthcheck(name string) error {
 
 type tlsRouter struct {
 	fakeRouter
-	Certs map[string]string
-	Keys  map[string]string
+	Certs    map[string]string
+	Keys     map[string]string
+	Policies map[string]*router.TLSPolicy
+	Headers  map[string]*router.HeaderPolicy
 }
 
-var _ router.TLSRouter = &tlsRouter{}
+var (
+	_ router.TLSRouter          = &tlsRouter{}
+	_ router.TLSPolicyRouter    = &tlsRouter{}
+	_ router.HeaderPolicyRouter = &tlsRouter{}
+)
 
 func (r *tlsRouter) AddCertificate(app router.App, cname, certificate, key string) error {
 	r.Certs[cname] = certificate
@@ -449,6 +531,36 @@ func (r *tlsRouter) GetCertificate(app router.App, cname string) (string, error)
 	return data, nil
 }
 
+func (r *tlsRouter) SetTLSPolicy(app router.App, policy *router.TLSPolicy) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if policy == nil {
+		delete(r.Policies, app.GetName())
+		return nil
+	}
+	r.Policies[app.GetName()] = policy
+	return nil
+}
+
+func (r *tlsRouter) SetHeaderPolicy(app router.App, policy *router.HeaderPolicy) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if policy == nil {
+		delete(r.Headers, app.GetName())
+		return nil
+	}
+	r.Headers[app.GetName()] = policy
+	return nil
+}
+
+func (r *tlsRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Policies = make(map[string]*router.TLSPolicy)
+	r.Headers = make(map[string]*router.HeaderPolicy)
+}
+
 func (r *tlsRouter) Addr(name string) (string, error) {
 	addr, err := r.fakeRouter.Addr(name)
 	if err != nil {
@@ -494,16 +606,242 @@ type statusRouter struct {
 	fakeRouter
 	Status       router.BackendStatus
 	StatusDetail string
+	// RouteStatus holds the status reported for routes, by address. Routes
+	// not in it are reported as up.
+	RouteStatus map[string]router.RouteStatus
 }
 
-var _ router.StatusRouter = &statusRouter{}
+var (
+	_ router.StatusRouter   = &statusRouter{}
+	_ router.StatusReporter = &statusRouter{}
+)
 
 func (r *statusRouter) GetBackendStatus(name string) (router.BackendStatus, string, error) {
 	return r.Status, r.StatusDetail, nil
 }
 
+func (r *statusRouter) RoutesStatus(name string) ([]router.RouteStatus, error) {
+	backendName, err := router.Retrieve(name)
+	if err != nil {
+		return nil, err
+	}
+	if !r.HasBackend(backendName) {
+		return nil, router.ErrBackendNotFound
+	}
+	routes, err := r.Routes(name)
+	if err != nil {
+		return nil, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	result := make([]router.RouteStatus, len(routes))
+	for i, route := range routes {
+		status, ok := r.RouteStatus[route.String()]
+		if !ok {
+			status = router.RouteStatus{Status: router.RouteHealthUp}
+		}
+		status.Address = route.String()
+		result[i] = status
+	}
+	return result, nil
+}
+
 func (r *statusRouter) Reset() {
 	r.fakeRouter.Reset()
 	r.Status = router.BackendStatusReady
 	r.StatusDetail = ""
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.RouteStatus = make(map[string]router.RouteStatus)
+}
+
+type trafficRouter struct {
+	fakeRouter
+	LastRequests map[string]time.Time
+}
+
+var _ router.TrafficRouter = &trafficRouter{}
+
+func (r *trafficRouter) LastRequest(name string) (time.Time, error) {
+	backendName, err := router.Retrieve(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.LastRequests[backendName], nil
+}
+
+func (r *trafficRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.LastRequests = make(map[string]time.Time)
+}
+
+type metricsRouter struct {
+	fakeRouter
+	Metrics map[string]router.BackendMetrics
+}
+
+var _ router.MetricsRouter = &metricsRouter{}
+
+func (r *metricsRouter) BackendMetrics(name string) (router.BackendMetrics, error) {
+	backendName, err := router.Retrieve(name)
+	if err != nil {
+		return router.BackendMetrics{}, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.Metrics[backendName], nil
+}
+
+func (r *metricsRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Metrics = make(map[string]router.BackendMetrics)
+}
+
+type protocolRouter struct {
+	fakeRouter
+	Supported []string
+	Protocols map[string]map[string]string
+}
+
+var _ router.ProtocolRouter = &protocolRouter{}
+
+func (r *protocolRouter) SupportedProtocols() ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.Supported, nil
+}
+
+func (r *protocolRouter) SetBackendProtocols(app router.App, protocols map[string]string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Protocols[app.GetName()] = protocols
+	return nil
+}
+
+func (r *protocolRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Supported = []string{router.ProtocolHTTP, router.ProtocolH2C, router.ProtocolGRPC, router.ProtocolWebsocket}
+	r.Protocols = make(map[string]map[string]string)
+}
+
+type stickyRouter struct {
+	fakeRouter
+	Sessions map[string]router.StickySession
+}
+
+var _ router.StickySessionRouter = &stickyRouter{}
+
+func (r *stickyRouter) SetStickySession(app router.App, session *router.StickySession) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if session == nil {
+		delete(r.Sessions, app.GetName())
+		return nil
+	}
+	r.Sessions[app.GetName()] = session.WithDefaults()
+	return nil
+}
+
+func (r *stickyRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Sessions = make(map[string]router.StickySession)
+}
+
+type mirrorRouter struct {
+	fakeRouter
+	Mirrors map[string]router.Mirror
+}
+
+var _ router.MirrorRouter = &mirrorRouter{}
+
+func (r *mirrorRouter) SetMirror(app router.App, mirror *router.Mirror) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if mirror == nil {
+		delete(r.Mirrors, app.GetName())
+		return nil
+	}
+	r.Mirrors[app.GetName()] = *mirror
+	return nil
+}
+
+func (r *mirrorRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Mirrors = make(map[string]router.Mirror)
+}
+
+type accessLogRouter struct {
+	fakeRouter
+	Configs map[string]router.AccessLogConfig
+	Entries map[string][]router.AccessLogEntry
+}
+
+var _ router.AccessLogRouter = &accessLogRouter{}
+
+func (r *accessLogRouter) SetAccessLog(app router.App, config *router.AccessLogConfig) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if config == nil {
+		delete(r.Configs, app.GetName())
+		return nil
+	}
+	r.Configs[app.GetName()] = *config
+	return nil
+}
+
+func (r *accessLogRouter) AccessLogs(name string, since time.Time) ([]router.AccessLogEntry, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var entries []router.AccessLogEntry
+	for _, e := range r.Entries[name] {
+		if e.Time.After(since) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (r *accessLogRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Configs = make(map[string]router.AccessLogConfig)
+	r.Entries = make(map[string][]router.AccessLogEntry)
+}
+
+type errorPageRouter struct {
+	fakeRouter
+	Pages map[string][]router.ErrorPage
+}
+
+var _ router.ErrorPageRouter = &errorPageRouter{}
+
+func (r *errorPageRouter) SetErrorPages(app router.App, pages []router.ErrorPage) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(pages) == 0 {
+		delete(r.Pages, app.GetName())
+		return nil
+	}
+	r.Pages[app.GetName()] = pages
+	return nil
+}
+
+func (r *errorPageRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Pages = make(map[string][]router.ErrorPage)
 }
@@ -0,0 +1,35 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import "time"
+
+// CapabilityStatusReporter is the capability of routers implementing
+// StatusReporter.
+const CapabilityStatusReporter = "unit-status"
+
+type RouteHealth string
+
+var (
+	RouteHealthUp   = RouteHealth("up")
+	RouteHealthDown = RouteHealth("down")
+)
+
+// RouteStatus is the health of a route of a backend as seen by the router.
+// ResponseTime is the average response time of the route, zero when the
+// router does not measure it.
+type RouteStatus struct {
+	Address       string        `json:"address"`
+	Status        RouteHealth   `json:"status"`
+	LastFailure   string        `json:"lastFailure,omitempty"`
+	LastFailureAt *time.Time    `json:"lastFailureAt,omitempty"`
+	ResponseTime  time.Duration `json:"responseTime,omitempty"`
+}
+
+// StatusReporter is a router able to report the health of each route of its
+// backends, as checked by the router itself.
+type StatusReporter interface {
+	RoutesStatus(name string) ([]RouteStatus, error)
+}
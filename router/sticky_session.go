@@ -0,0 +1,51 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"regexp"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// DefaultStickyCookieName is the name of the affinity cookie used when the
+// sticky session settings do not define one.
+const DefaultStickyCookieName = "tsuru_sticky"
+
+var tokenRegexp = regexp.MustCompile(`^[A-Za-z0-9!#$%&'*+\-.^_|~]+$`)
+
+// StickySession holds the session affinity settings of the backend of an
+// app. A TTL of zero makes the affinity cookie last for the browser session.
+type StickySession struct {
+	CookieName string `json:"cookieName"`
+	TTL        int    `json:"ttl"`
+}
+
+// StickySessionRouter is a router able to keep clients bound to the same
+// unit through an affinity cookie. A nil session disables the affinity.
+type StickySessionRouter interface {
+	SetStickySession(app App, session *StickySession) error
+}
+
+// Validate checks whether the cookie name is a valid token and the TTL is
+// not negative.
+func (s *StickySession) Validate() error {
+	if s.CookieName != "" && !tokenRegexp.MatchString(s.CookieName) {
+		return &tsuruErrors.ValidationError{Message: "Invalid cookie name, it must only contain letters, numbers and the characters !#$%&'*+-.^_|~"}
+	}
+	if s.TTL < 0 {
+		return &tsuruErrors.ValidationError{Message: "Invalid TTL, it must not be negative"}
+	}
+	return nil
+}
+
+// WithDefaults returns a copy of the settings filling the cookie name with
+// the default one when empty.
+func (s StickySession) WithDefaults() StickySession {
+	if s.CookieName == "" {
+		s.CookieName = DefaultStickyCookieName
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestStickySessionValidate(c *check.C) {
+	tests := []struct {
+		session StickySession
+		err     string
+	}{
+		{session: StickySession{}},
+		{session: StickySession{CookieName: "my_app-session", TTL: 3600}},
+		{session: StickySession{CookieName: "my cookie"}, err: "Invalid cookie name, .*"},
+		{session: StickySession{CookieName: "a;b"}, err: "Invalid cookie name, .*"},
+		{session: StickySession{TTL: -1}, err: "Invalid TTL, it must not be negative"},
+	}
+	for i, tt := range tests {
+		err := tt.session.Validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("case %d", i))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("case %d", i))
+		}
+	}
+}
+
+func (s *S) TestStickySessionWithDefaults(c *check.C) {
+	session := StickySession{TTL: 60}
+	c.Assert(session.WithDefaults(), check.DeepEquals, StickySession{CookieName: DefaultStickyCookieName, TTL: 60})
+	session = StickySession{CookieName: "mycookie"}
+	c.Assert(session.WithDefaults(), check.DeepEquals, session)
+}
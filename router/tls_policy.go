@@ -0,0 +1,115 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// RecommendedHSTSMaxAge is the minimum HSTS max-age, in seconds, not
+// reported as weak.
+const RecommendedHSTSMaxAge = 365 * 24 * 60 * 60
+
+// TLSVersions are the accepted values for the minimum TLS version of a
+// policy, from the oldest to the newest.
+var TLSVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+const recommendedTLSVersion = "1.2"
+
+// TLSPolicy holds the TLS settings routers should apply to the backend of an
+// app.
+type TLSPolicy struct {
+	MinVersion   string      `json:"minVersion,omitempty"`
+	CipherSuites []string    `json:"cipherSuites,omitempty"`
+	HSTS         *HSTSPolicy `json:"hsts,omitempty"`
+}
+
+// HSTSPolicy holds the settings of the Strict-Transport-Security header.
+type HSTSPolicy struct {
+	MaxAge            int  `json:"maxAge"`
+	IncludeSubdomains bool `json:"includeSubdomains"`
+	Preload           bool `json:"preload"`
+}
+
+// TLSPolicyRouter is a router able to enforce TLS policies on backends. A
+// nil policy restores the default settings of the router.
+type TLSPolicyRouter interface {
+	SetTLSPolicy(app App, policy *TLSPolicy) error
+}
+
+func tlsVersionIndex(version string) int {
+	for i, v := range TLSVersions {
+		if v == version {
+			return i
+		}
+	}
+	return -1
+}
+
+func insecureCipherSuites() map[string]bool {
+	names := make(map[string]bool)
+	for _, c := range tls.InsecureCipherSuites() {
+		names[c.Name] = true
+	}
+	return names
+}
+
+// Validate checks whether the policy only uses known TLS versions and cipher
+// suites.
+func (p *TLSPolicy) Validate() error {
+	if p.MinVersion != "" && tlsVersionIndex(p.MinVersion) == -1 {
+		return &tsuruErrors.ValidationError{
+			Message: fmt.Sprintf("Invalid minimum TLS version %q, possible values are: %s", p.MinVersion, strings.Join(TLSVersions, ", ")),
+		}
+	}
+	known := insecureCipherSuites()
+	for _, c := range tls.CipherSuites() {
+		known[c.Name] = true
+	}
+	for _, name := range p.CipherSuites {
+		if !known[name] {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Unknown cipher suite %q", name)}
+		}
+	}
+	if p.HSTS != nil && p.HSTS.MaxAge < 0 {
+		return &tsuruErrors.ValidationError{Message: "Invalid HSTS max-age, it must not be negative"}
+	}
+	return nil
+}
+
+// Weaknesses returns descriptions of the settings in the policy considered
+// weak. A nil policy leaves the router defaults, which are reported as weak
+// as nothing is enforced.
+func (p *TLSPolicy) Weaknesses() []string {
+	if p == nil {
+		return []string{"no TLS policy"}
+	}
+	var weaknesses []string
+	if tlsVersionIndex(p.MinVersion) < tlsVersionIndex(recommendedTLSVersion) {
+		weaknesses = append(weaknesses, fmt.Sprintf("minimum TLS version below %s", recommendedTLSVersion))
+	}
+	insecure := insecureCipherSuites()
+	var weakCiphers []string
+	for _, name := range p.CipherSuites {
+		if insecure[name] {
+			weakCiphers = append(weakCiphers, name)
+		}
+	}
+	if len(weakCiphers) > 0 {
+		sort.Strings(weakCiphers)
+		weaknesses = append(weaknesses, "weak cipher suites: "+strings.Join(weakCiphers, ", "))
+	}
+	if p.HSTS == nil || p.HSTS.MaxAge == 0 {
+		weaknesses = append(weaknesses, "HSTS disabled")
+	} else if p.HSTS.MaxAge < RecommendedHSTSMaxAge {
+		weaknesses = append(weaknesses, "HSTS max-age below one year")
+	}
+	return weaknesses
+}
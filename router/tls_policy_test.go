@@ -0,0 +1,52 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestTLSPolicyValidate(c *check.C) {
+	tests := []struct {
+		policy TLSPolicy
+		err    string
+	}{
+		{policy: TLSPolicy{}},
+		{policy: TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_RC4_128_SHA"}}},
+		{policy: TLSPolicy{MinVersion: "1.4"}, err: `Invalid minimum TLS version "1.4", possible values are: 1.0, 1.1, 1.2, 1.3`},
+		{policy: TLSPolicy{CipherSuites: []string{"NOT_A_CIPHER"}}, err: `Unknown cipher suite "NOT_A_CIPHER"`},
+		{policy: TLSPolicy{HSTS: &HSTSPolicy{MaxAge: -1}}, err: "Invalid HSTS max-age, it must not be negative"},
+	}
+	for i, tt := range tests {
+		err := tt.policy.Validate()
+		if tt.err == "" {
+			c.Check(err, check.IsNil, check.Commentf("case %d", i))
+		} else {
+			c.Check(err, check.ErrorMatches, tt.err, check.Commentf("case %d", i))
+		}
+	}
+}
+
+func (s *S) TestTLSPolicyWeaknesses(c *check.C) {
+	var nilPolicy *TLSPolicy
+	c.Assert(nilPolicy.Weaknesses(), check.DeepEquals, []string{"no TLS policy"})
+	strong := &TLSPolicy{
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		HSTS:         &HSTSPolicy{MaxAge: RecommendedHSTSMaxAge},
+	}
+	c.Assert(strong.Weaknesses(), check.IsNil)
+	weak := &TLSPolicy{
+		MinVersion:   "1.0",
+		CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		HSTS:         &HSTSPolicy{MaxAge: 3600},
+	}
+	c.Assert(weak.Weaknesses(), check.DeepEquals, []string{
+		"minimum TLS version below 1.2",
+		"weak cipher suites: TLS_RSA_WITH_RC4_128_SHA",
+		"HSTS max-age below one year",
+	})
+	c.Assert((&TLSPolicy{MinVersion: "1.3"}).Weaknesses(), check.DeepEquals, []string{"HSTS disabled"})
+}
@@ -84,6 +84,29 @@ func (n *ScopedConfig) SetFieldAtomic(pool, name string, value interface{}) (boo
 	return false, err
 }
 
+// ReplaceFieldItem atomically replaces the item oldValue with newValue in
+// the slice field with the given name. It returns false when oldValue is no
+// longer in the field.
+func (n *ScopedConfig) ReplaceFieldItem(pool, name string, oldValue, newValue interface{}) (bool, error) {
+	coll, err := n.collection()
+	if err != nil {
+		return false, err
+	}
+	defer coll.Close()
+	err = coll.Update(bson.M{
+		"name":        n.name,
+		"pool":        pool,
+		"val." + name: oldValue,
+	}, bson.M{"$set": bson.M{"val." + name + ".$": newValue}})
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (n *ScopedConfig) SetField(pool, name string, value interface{}) error {
 	coll, err := n.collection()
 	if err != nil {
@@ -99,34 +122,68 @@ func (n *ScopedConfig) SaveBase(val interface{}) error {
 }
 
 func (n *ScopedConfig) Save(pool string, val interface{}) error {
-	if reflect.TypeOf(val).Kind() == reflect.Ptr {
-		val = reflect.ValueOf(val).Elem().Interface()
-	}
-	if reflect.TypeOf(val).Kind() != reflect.Struct {
-		return errors.New("a struct type or pointer to a struct is required as value")
+	val, err := n.entryVal(val)
+	if err != nil {
+		return err
 	}
 	coll, err := n.collection()
 	if err != nil {
 		return err
 	}
 	defer coll.Close()
-	if n.Jsonfy {
-		var result map[string]interface{}
-		var data []byte
-		data, err = json.Marshal(val)
-		if err != nil {
-			return err
-		}
-		err = json.Unmarshal(data, &result)
-		if err != nil {
-			return err
-		}
-		val = interface{}(result)
-	}
 	_, err = coll.Upsert(bson.M{"name": n.name, "pool": pool}, bson.M{"name": n.name, "pool": pool, "val": val})
 	return err
 }
 
+// SaveAtomic saves the value only if the field with the given name is empty
+// in the stored value, in a single write. It returns false when the field is
+// already set.
+func (n *ScopedConfig) SaveAtomic(pool, name string, val interface{}) (bool, error) {
+	val, err := n.entryVal(val)
+	if err != nil {
+		return false, err
+	}
+	coll, err := n.collection()
+	if err != nil {
+		return false, err
+	}
+	defer coll.Close()
+	_, err = coll.Upsert(bson.M{
+		"name": n.name,
+		"pool": pool,
+		"$or":  []bson.M{{"val." + name: ""}, {"val." + name: bson.M{"$exists": false}}},
+	}, bson.M{"name": n.name, "pool": pool, "val": val})
+	if err == nil {
+		return true, nil
+	}
+	if mgo.IsDup(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (n *ScopedConfig) entryVal(val interface{}) (interface{}, error) {
+	if reflect.TypeOf(val).Kind() == reflect.Ptr {
+		val = reflect.ValueOf(val).Elem().Interface()
+	}
+	if reflect.TypeOf(val).Kind() != reflect.Struct {
+		return nil, errors.New("a struct type or pointer to a struct is required as value")
+	}
+	if !n.Jsonfy {
+		return val, nil
+	}
+	var result map[string]interface{}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (n *ScopedConfig) HasEntry(pool string) (bool, error) {
 	coll, err := n.collection()
 	if err != nil {
@@ -540,6 +540,44 @@ func (s *S) TestScopedConfigSetFieldAtomic(c *check.C) {
 	c.Assert(val.Myvalue, check.Equals, fmt.Sprintf("val-%d", *valueSet))
 }
 
+func (s *S) TestScopedConfigSaveAtomic(c *check.C) {
+	conf := FindScopedConfig("x")
+	isSet, err := conf.SaveAtomic("", "a", TestStdAux{A: "x", B: "1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(isSet, check.Equals, true)
+	isSet, err = conf.SaveAtomic("", "a", TestStdAux{A: "y", B: "2"})
+	c.Assert(err, check.IsNil)
+	c.Assert(isSet, check.Equals, false)
+	var val TestStdAux
+	err = conf.LoadBase(&val)
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, TestStdAux{A: "x", B: "1"})
+	err = conf.Save("", TestStdAux{B: "3"})
+	c.Assert(err, check.IsNil)
+	isSet, err = conf.SaveAtomic("", "a", TestStdAux{A: "z", B: "4"})
+	c.Assert(err, check.IsNil)
+	c.Assert(isSet, check.Equals, true)
+	err = conf.LoadBase(&val)
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, TestStdAux{A: "z", B: "4"})
+}
+
+func (s *S) TestScopedConfigReplaceFieldItem(c *check.C) {
+	conf := FindScopedConfig("x")
+	err := conf.SetField("", "myvalues", []string{"a", "b", "c"})
+	c.Assert(err, check.IsNil)
+	replaced, err := conf.ReplaceFieldItem("", "myvalues", "b", "x")
+	c.Assert(err, check.IsNil)
+	c.Assert(replaced, check.Equals, true)
+	replaced, err = conf.ReplaceFieldItem("", "myvalues", "b", "y")
+	c.Assert(err, check.IsNil)
+	c.Assert(replaced, check.Equals, false)
+	var val struct{ Myvalues []string }
+	err = conf.LoadBase(&val)
+	c.Assert(err, check.IsNil)
+	c.Assert(val.Myvalues, check.DeepEquals, []string{"a", "x", "c"})
+}
+
 func (s *S) TestScopedConfigSetField(c *check.C) {
 	conf := FindScopedConfig("x")
 	var val1, val2 struct{ Myvalue string }
@@ -5,6 +5,7 @@
 package service
 
 import (
+	"fmt"
 	"io"
 	"sort"
 	"sync"
@@ -80,6 +81,51 @@ var notifyCreateServiceInstance = action.Action{
 	MinParams: 3,
 }
 
+// notifyCloneServiceInstance is an action that calls the service endpoint
+// to create a service instance from a snapshot of another one.
+//
+// The first argument in the context must be a Service.
+// The second argument in the context must be a ServiceInstance.
+// The third argument in the context must be an event.
+// The fourth argument in the context must be a request ID.
+// The fifth argument in the context must be the source ServiceInstance.
+var notifyCloneServiceInstance = action.Action{
+	Name: "notify-clone-service-instance",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		service, ok := ctx.Params[0].(Service)
+		if !ok {
+			return nil, errors.New("First parameter must be a Service.")
+		}
+		endpoint, err := service.getClient("production")
+		if err != nil {
+			return nil, err
+		}
+		instance, ok := ctx.Params[1].(ServiceInstance)
+		if !ok {
+			return nil, errors.New("Second parameter must be a ServiceInstance.")
+		}
+		evt, ok := ctx.Params[2].(*event.Event)
+		if !ok {
+			return nil, errors.New("Third parameter must be an event.")
+		}
+		requestID, ok := ctx.Params[3].(string)
+		if !ok {
+			return nil, errors.New("RequestID should be a string.")
+		}
+		source, ok := ctx.Params[4].(ServiceInstance)
+		if !ok {
+			return nil, errors.New("Fifth parameter must be a ServiceInstance.")
+		}
+		err = endpoint.Clone(&source, &instance, evt, requestID)
+		if err != nil {
+			return nil, err
+		}
+		return instance, nil
+	},
+	Backward:  notifyCreateServiceInstance.Backward,
+	MinParams: 5,
+}
+
 // createServiceInstance is an action that inserts an instance in the database.
 //
 // The second argument in the context must be a Service Instance.
@@ -132,14 +178,18 @@ var updateServiceInstance = action.Action{
 			return nil, err
 		}
 		defer conn.Close()
+		set := bson.M{
+			"description": updateData.Description,
+			"tags":        updateData.Tags,
+			"teamowner":   updateData.TeamOwner,
+		}
+		if updateData.Parameters != nil {
+			set["parameters"] = updateData.Parameters
+		}
 		return nil, conn.ServiceInstances().Update(
 			bson.M{"name": instance.Name, "service_name": instance.ServiceName},
 			bson.M{
-				"$set": bson.M{
-					"description": updateData.Description,
-					"tags":        updateData.Tags,
-					"teamowner":   updateData.TeamOwner,
-				},
+				"$set": set,
 				"$addToSet": bson.M{
 					"teams": updateData.TeamOwner,
 				},
@@ -164,6 +214,7 @@ var updateServiceInstance = action.Action{
 					"tags":        instance.Tags,
 					"teamowner":   instance.TeamOwner,
 					"teams":       instance.Teams,
+					"parameters":  instance.Parameters,
 				},
 			},
 		)
@@ -192,6 +243,9 @@ var notifyUpdateServiceInstance = action.Action{
 		if !ok {
 			return nil, errors.New("Second parameter must be a ServiceInstance.")
 		}
+		if updateData, ok := ctx.Params[2].(ServiceInstance); ok && updateData.Parameters != nil {
+			instance.Parameters = updateData.Parameters
+		}
 		evt, ok := ctx.Params[3].(*event.Event)
 		if !ok {
 			return nil, errors.New("Third parameter must be an event.")
@@ -251,6 +305,25 @@ var bindAppDBAction = &action.Action{
 	MinParams: 1,
 }
 
+var allowNetworkAccessAction = &action.Action{
+	Name: "allow-network-access",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args, _ := ctx.Params[0].(*bindPipelineArgs)
+		if args == nil {
+			return nil, errors.New("invalid arguments for pipeline, expected *bindPipelineArgs.")
+		}
+		return nil, args.serviceInstance.AllowNetworkAccess(args.app, args.requestID)
+	},
+	Backward: func(ctx action.BWContext) {
+		args, _ := ctx.Params[0].(*bindPipelineArgs)
+		err := args.serviceInstance.RevokeNetworkAccess(args.app, args.requestID)
+		if err != nil {
+			log.Errorf("[allow-network-access backward] failed to revoke network access: %s", err)
+		}
+	},
+	MinParams: 1,
+}
+
 var bindAppEndpointAction = &action.Action{
 	Name: "bind-app-endpoint",
 	Forward: func(ctx action.FWContext) (action.Result, error) {
@@ -492,3 +565,27 @@ var removeBoundEnvs = action.Action{
 	},
 	MinParams: 1,
 }
+
+// revokeNetworkAccess is the last action in the unbind pipeline, failures
+// are reported to the user instead of rolling back the whole unbind, as the
+// app is no longer using the instance anyway.
+var revokeNetworkAccess = action.Action{
+	Name: "revoke-network-access",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args, _ := ctx.Params[0].(*bindPipelineArgs)
+		if args == nil {
+			return nil, errors.New("invalid arguments for pipeline, expected *bindPipelineArgs.")
+		}
+		err := args.serviceInstance.RevokeNetworkAccess(args.app, args.requestID)
+		if err != nil {
+			log.Errorf("[revoke-network-access forward] failed to revoke network access: %s", err)
+			if args.writer != nil {
+				fmt.Fprintf(args.writer, "WARNING: unable to revoke network access, it must be removed manually: %s\n", err)
+			}
+		}
+		return nil, nil
+	},
+	Backward: func(ctx action.BWContext) {
+	},
+	MinParams: 1,
+}
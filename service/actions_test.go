@@ -6,6 +6,7 @@ package service
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"sort"
@@ -786,3 +787,96 @@ func (s *S) TestRemoveBoundEnvsForward(c *check.C) {
 	envs := a.GetServiceEnvs()
 	c.Assert(envs, check.DeepEquals, []bind.ServiceEnvVar{})
 }
+
+func (s *S) TestAllowNetworkAccessActionName(c *check.C) {
+	c.Assert(allowNetworkAccessAction.Name, check.Equals, "allow-network-access")
+}
+
+func (s *S) TestAllowNetworkAccessActionForward(c *check.C) {
+	var reqs []*http.Request
+	var payload NetworkAccessRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqs = append(reqs, r)
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mysql", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "s3cr3t", NetworkAccessHook: ts.URL + "/access"}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	si := ServiceInstance{Name: "my-mysql", ServiceName: "mysql", Teams: []string{s.team.Name}}
+	a := provisiontest.NewFakeApp("myapp", "static", 2)
+	ctx := action.FWContext{Params: []interface{}{&bindPipelineArgs{app: a, serviceInstance: &si}}}
+	_, err = allowNetworkAccessAction.Forward(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].Method, check.Equals, "POST")
+	c.Assert(reqs[0].URL.Path, check.Equals, "/access")
+	user, password, _ := reqs[0].BasicAuth()
+	c.Assert(user, check.Equals, "mysql")
+	c.Assert(password, check.Equals, "s3cr3t")
+	c.Assert(payload.Service, check.Equals, "mysql")
+	c.Assert(payload.Instance, check.Equals, "my-mysql")
+	c.Assert(payload.App, check.Equals, "myapp")
+	c.Assert(payload.UnitIPs, check.HasLen, 2)
+}
+
+func (s *S) TestAllowNetworkAccessActionForwardWithoutHook(c *check.C) {
+	srv := Service{Name: "mysql", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "s3cr3t"}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	si := ServiceInstance{Name: "my-mysql", ServiceName: "mysql", Teams: []string{s.team.Name}}
+	a := provisiontest.NewFakeApp("myapp", "static", 1)
+	ctx := action.FWContext{Params: []interface{}{&bindPipelineArgs{app: a, serviceInstance: &si}}}
+	_, err = allowNetworkAccessAction.Forward(ctx)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestAllowNetworkAccessActionForwardFailure(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("rule quota exceeded"))
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mysql", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "s3cr3t", NetworkAccessHook: ts.URL}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	si := ServiceInstance{Name: "my-mysql", ServiceName: "mysql", Teams: []string{s.team.Name}}
+	a := provisiontest.NewFakeApp("myapp", "static", 1)
+	ctx := action.FWContext{Params: []interface{}{&bindPipelineArgs{app: a, serviceInstance: &si}}}
+	_, err = allowNetworkAccessAction.Forward(ctx)
+	c.Assert(err, check.ErrorMatches, `network access hook of service "mysql" failed with status 500: rule quota exceeded`)
+}
+
+func (s *S) TestAllowNetworkAccessActionBackward(c *check.C) {
+	var reqs []*http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqs = append(reqs, r)
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mysql", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "s3cr3t", NetworkAccessHook: ts.URL}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	si := ServiceInstance{Name: "my-mysql", ServiceName: "mysql", Teams: []string{s.team.Name}}
+	a := provisiontest.NewFakeApp("myapp", "static", 1)
+	allowNetworkAccessAction.Backward(action.BWContext{Params: []interface{}{&bindPipelineArgs{app: a, serviceInstance: &si}}})
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].Method, check.Equals, "DELETE")
+}
+
+func (s *S) TestRevokeNetworkAccessForwardFailureDoesNotFail(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mysql", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "s3cr3t", NetworkAccessHook: ts.URL}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	si := ServiceInstance{Name: "my-mysql", ServiceName: "mysql", Teams: []string{s.team.Name}}
+	a := provisiontest.NewFakeApp("myapp", "static", 1)
+	buf := bytes.NewBuffer(nil)
+	ctx := action.FWContext{Params: []interface{}{&bindPipelineArgs{app: a, serviceInstance: &si, writer: buf}}}
+	_, err = revokeNetworkAccess.Forward(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s)WARNING: unable to revoke network access.*status 500.*`)
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"github.com/tsuru/tsuru/action"
+	"github.com/tsuru/tsuru/event"
+)
+
+// CloneServiceInstance creates the instance from a snapshot of the source
+// instance, delegating the copy of the data to the service API. The plan,
+// description, tags and parameters not set in the instance are inherited from
+// the source.
+func CloneServiceInstance(source ServiceInstance, instance ServiceInstance, evt *event.Event, requestID string) error {
+	service := &Service{Name: source.ServiceName}
+	err := service.Get()
+	if err != nil {
+		return err
+	}
+	err = validateServiceInstance(instance, service)
+	if err != nil {
+		return err
+	}
+	instance.ServiceName = service.Name
+	instance.Teams = []string{instance.TeamOwner}
+	if instance.PlanName == "" {
+		instance.PlanName = source.PlanName
+	}
+	if instance.Description == "" {
+		instance.Description = source.Description
+	}
+	if len(instance.Tags) == 0 {
+		instance.Tags = source.Tags
+	}
+	instance.Tags = processTags(instance.Tags)
+	params := make(map[string]interface{}, len(source.Parameters)+len(instance.Parameters))
+	for k, v := range source.Parameters {
+		params[k] = v
+	}
+	for k, v := range instance.Parameters {
+		params[k] = v
+	}
+	instance.Parameters, err = service.validateParameters(params, requestID)
+	if err != nil {
+		return err
+	}
+	actions := []*action.Action{&notifyCloneServiceInstance, &createServiceInstance}
+	pipeline := action.NewPipeline(actions...)
+	return pipeline.Execute(*service, instance, evt, requestID, source)
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/globalsign/mgo/bson"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCloneServiceInstance(c *check.C) {
+	var reqs []*http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/resources/schema" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		r.ParseForm()
+		reqs = append(reqs, r)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mysql", Endpoint: map[string]string{"production": ts.URL}, Password: "s3cr3t", OwnerTeams: []string{s.team.Name}}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	source := ServiceInstance{
+		Name:        "prod-db",
+		ServiceName: "mysql",
+		PlanName:    "large",
+		Description: "production database",
+		Tags:        []string{"prod"},
+		Parameters:  map[string]interface{}{"engine": "innodb", "replicas": "3"},
+		TeamOwner:   s.team.Name,
+		Teams:       []string{s.team.Name},
+	}
+	err = s.conn.ServiceInstances().Insert(&source)
+	c.Assert(err, check.IsNil)
+	instance := ServiceInstance{
+		Name:       "staging-db",
+		TeamOwner:  s.team.Name,
+		Tags:       []string{"staging"},
+		Parameters: map[string]interface{}{"replicas": "1"},
+	}
+	evt := createEvt(c)
+	err = CloneServiceInstance(source, instance, evt, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].Method, check.Equals, "POST")
+	c.Assert(reqs[0].URL.Path, check.Equals, "/resources/prod-db/clone")
+	c.Assert(reqs[0].Form.Get("name"), check.Equals, "staging-db")
+	c.Assert(reqs[0].Form.Get("plan"), check.Equals, "large")
+	c.Assert(reqs[0].Form.Get("parameters.engine"), check.Equals, "innodb")
+	c.Assert(reqs[0].Form.Get("parameters.replicas"), check.Equals, "1")
+	var clone ServiceInstance
+	err = s.conn.ServiceInstances().Find(bson.M{"name": "staging-db", "service_name": "mysql"}).One(&clone)
+	c.Assert(err, check.IsNil)
+	c.Assert(clone.PlanName, check.Equals, "large")
+	c.Assert(clone.Description, check.Equals, "production database")
+	c.Assert(clone.Tags, check.DeepEquals, []string{"staging"})
+	c.Assert(clone.Teams, check.DeepEquals, []string{s.team.Name})
+}
+
+func (s *S) TestCloneServiceInstanceNotSupported(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mysql", Endpoint: map[string]string{"production": ts.URL}, Password: "s3cr3t", OwnerTeams: []string{s.team.Name}}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	source := ServiceInstance{Name: "prod-db", ServiceName: "mysql", TeamOwner: s.team.Name, Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(&source)
+	c.Assert(err, check.IsNil)
+	evt := createEvt(c)
+	err = CloneServiceInstance(source, ServiceInstance{Name: "staging-db", TeamOwner: s.team.Name}, evt, "")
+	c.Assert(err, check.Equals, ErrCloneNotSupported)
+	n, err := s.conn.ServiceInstances().Find(bson.M{"name": "staging-db"}).Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const (
+	InstanceStatusUp      = "up"
+	InstanceStatusPending = "pending"
+	InstanceStatusDown    = "down"
+	InstanceStatusGone    = "gone"
+
+	instanceStatusNotImplemented = "not implemented for this service"
+
+	ReconcileRecreate = "recreate"
+	ReconcileBroken   = "broken"
+
+	DriftEventKind = "service-instance-drift"
+)
+
+var ErrInstanceNotGone = &tsuruErrors.ValidationError{Message: "Only instances gone from the service API can be recreated."}
+
+// InstanceHealth is the state of a service instance in the service API, as
+// last seen by the drift detector.
+type InstanceHealth struct {
+	Status    string
+	Broken    bool
+	CheckedAt time.Time
+	ChangedAt time.Time
+}
+
+// Drifted returns whether the service API disagrees with tsuru about the
+// instance, either because it's gone or down.
+func (h *InstanceHealth) Drifted() bool {
+	return h != nil && (h.Status == InstanceStatusGone || h.Status == InstanceStatusDown)
+}
+
+// InitializeDriftDetector starts periodically checking the status of every
+// service instance if an interval is configured.
+func InitializeDriftDetector() error {
+	interval, _ := config.GetDuration("service:drift:interval")
+	if interval <= 0 {
+		return nil
+	}
+	d := &driftDetector{once: &sync.Once{}, interval: interval}
+	d.start()
+	shutdown.Register(d)
+	return nil
+}
+
+type driftDetector struct {
+	once     *sync.Once
+	stopCh   chan struct{}
+	interval time.Duration
+}
+
+func (d *driftDetector) start() {
+	d.once.Do(func() {
+		d.stopCh = make(chan struct{})
+		go d.spin()
+	})
+}
+
+func (d *driftDetector) Shutdown(ctx context.Context) error {
+	if d.stopCh == nil {
+		return nil
+	}
+	d.stopCh <- struct{}{}
+	d.stopCh = nil
+	d.once = &sync.Once{}
+	return nil
+}
+
+func (d *driftDetector) spin() {
+	for {
+		err := checkInstancesDrift(time.Now().UTC())
+		if err != nil {
+			log.Errorf("[drift-detector] errors checking service instances: %v", err)
+		}
+		select {
+		case <-d.stopCh:
+			return
+		case <-time.After(d.interval):
+		}
+	}
+}
+
+func checkInstancesDrift(now time.Time) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	var instances []ServiceInstance
+	err = conn.ServiceInstances().Find(nil).All(&instances)
+	conn.Close()
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range instances {
+		err = instances[i].checkDrift(now)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to check service instance %s/%s", instances[i].ServiceName, instances[i].Name))
+		}
+	}
+	if multi.Len() == 0 {
+		return nil
+	}
+	return multi
+}
+
+// checkDrift queries the service API for the status of the instance, storing
+// it and emitting an event whenever the instance drifts or recovers.
+func (si *ServiceInstance) checkDrift(now time.Time) error {
+	status, err := si.Status("")
+	if err != nil {
+		return err
+	}
+	if status == instanceStatusNotImplemented {
+		return nil
+	}
+	if status != InstanceStatusPending && status != InstanceStatusDown && status != InstanceStatusGone {
+		// Services may describe healthy instances with custom messages.
+		status = InstanceStatusUp
+	}
+	health := InstanceHealth{Status: status, CheckedAt: now, ChangedAt: now}
+	wasDrifted := si.Health.Drifted()
+	var previous string
+	if si.Health != nil {
+		previous = si.Health.Status
+		health.Broken = si.Health.Broken
+		if previous == status {
+			health.ChangedAt = si.Health.ChangedAt
+		}
+	}
+	err = si.updateData(bson.M{"$set": bson.M{"health": health}})
+	if err != nil {
+		return err
+	}
+	si.Health = &health
+	if previous == status || !(wasDrifted || health.Drifted()) {
+		return nil
+	}
+	return si.driftEvent(previous, &health)
+}
+
+func (si *ServiceInstance) driftEvent(previous string, health *InstanceHealth) error {
+	target := fmt.Sprintf("%s/%s", si.ServiceName, si.Name)
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeServiceInstance, Value: target},
+		InternalKind: DriftEventKind,
+		CustomData:   map[string]string{"previous": previous, "status": health.Status},
+		Allowed: event.Allowed(permission.PermServiceInstanceReadEvents, append(permission.Contexts(permission.CtxTeam, si.Teams),
+			permission.Context(permission.CtxServiceInstance, target),
+		)...),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	var evtErr error
+	if health.Drifted() {
+		evtErr = errors.Errorf("service instance %s is %s in the service API", target, health.Status)
+	} else {
+		evt.Logf("service instance %s is %s again in the service API", target, health.Status)
+	}
+	return evt.Done(evtErr)
+}
+
+// Reconcile fixes a service instance drifted from the service API, either
+// recreating it in the service API or marking it as broken.
+func (si *ServiceInstance) Reconcile(action string, evt *event.Event, requestID string) error {
+	switch action {
+	case ReconcileRecreate:
+		if si.Health == nil || si.Health.Status != InstanceStatusGone {
+			return ErrInstanceNotGone
+		}
+		endpoint, err := si.Service().getClient("production")
+		if err != nil {
+			return err
+		}
+		err = endpoint.Create(si, evt, requestID)
+		if err != nil && err != ErrInstanceAlreadyExistsInAPI {
+			return err
+		}
+		si.Health = nil
+		return si.updateData(bson.M{"$unset": bson.M{"health": ""}})
+	case ReconcileBroken:
+		if si.Health == nil {
+			si.Health = &InstanceHealth{}
+		}
+		si.Health.Broken = true
+		return si.updateData(bson.M{"$set": bson.M{"health.broken": true}})
+	}
+	return &tsuruErrors.ValidationError{
+		Message: fmt.Sprintf("Invalid reconcile action %q, possible values are %q and %q.", action, ReconcileRecreate, ReconcileBroken),
+	}
+}
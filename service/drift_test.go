@@ -0,0 +1,98 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *InstanceSuite) TestCheckInstancesDrift(c *check.C) {
+	status := http.StatusNoContent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mongodb", Endpoint: map[string]string{"production": ts.URL}}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	si := ServiceInstance{Name: "instance", ServiceName: srv.Name, Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(&si)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC().Truncate(time.Second)
+	err = checkInstancesDrift(now)
+	c.Assert(err, check.IsNil)
+	instance, err := GetServiceInstance(srv.Name, si.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(instance.Health.Status, check.Equals, InstanceStatusUp)
+	c.Assert(instance.Health.CheckedAt.Equal(now), check.Equals, true)
+	evts, err := event.List(&event.Filter{KindNames: []string{DriftEventKind}})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 0)
+	status = http.StatusGone
+	err = checkInstancesDrift(now.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	instance, err = GetServiceInstance(srv.Name, si.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(instance.Health.Status, check.Equals, InstanceStatusGone)
+	c.Assert(instance.Health.Drifted(), check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target:       event.Target{Type: event.TargetTypeServiceInstance, Value: "mongodb/instance"},
+		Kind:         DriftEventKind,
+		ErrorMatches: `service instance mongodb/instance is gone in the service API`,
+	}, eventtest.HasEvent)
+}
+
+func (s *InstanceSuite) TestReconcileRecreate(c *check.C) {
+	var created bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/resources" {
+			created = true
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mongodb", Endpoint: map[string]string{"production": ts.URL}}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	si := ServiceInstance{Name: "instance", ServiceName: srv.Name, TeamOwner: s.team.Name, Health: &InstanceHealth{Status: InstanceStatusGone}}
+	err = s.conn.ServiceInstances().Insert(&si)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeServiceInstance, Value: "mongodb/instance"},
+		Kind:     permission.PermServiceInstanceUpdateReconcile,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermServiceInstanceReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = si.Reconcile(ReconcileRecreate, evt, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.Equals, true)
+	instance, err := GetServiceInstance(srv.Name, si.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(instance.Health, check.IsNil)
+}
+
+func (s *InstanceSuite) TestReconcile(c *check.C) {
+	si := ServiceInstance{Name: "instance", ServiceName: "mongodb", Health: &InstanceHealth{Status: InstanceStatusDown}}
+	err := s.conn.ServiceInstances().Insert(&si)
+	c.Assert(err, check.IsNil)
+	err = si.Reconcile(ReconcileRecreate, nil, "")
+	c.Assert(err, check.Equals, ErrInstanceNotGone)
+	err = si.Reconcile("destroy", nil, "")
+	c.Assert(err, check.ErrorMatches, `Invalid reconcile action "destroy".*`)
+	err = si.Reconcile(ReconcileBroken, nil, "")
+	c.Assert(err, check.IsNil)
+	instance, err := GetServiceInstance(si.ServiceName, si.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(instance.Health.Broken, check.Equals, true)
+	c.Assert(instance.Health.Status, check.Equals, InstanceStatusDown)
+}
@@ -6,6 +6,7 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -27,6 +28,7 @@ var (
 	ErrInstanceAlreadyExistsInAPI = errors.New("instance already exists in the service API")
 	ErrInstanceNotFoundInAPI      = errors.New("instance does not exist in the service API")
 	ErrInstanceNotReady           = errors.New("instance is not ready yet")
+	ErrCloneNotSupported          = errors.New("the service API does not support cloning instances")
 
 	requestLatencies = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name: "tsuru_service_request_duration_seconds",
@@ -125,6 +127,7 @@ func (c *Client) Create(instance *ServiceInstance, evt *event.Event, requestID s
 	if instance.Description != "" {
 		params["description"] = []string{instance.Description}
 	}
+	addParameters(params, instance.Parameters)
 	log.Debugf("Attempting to call creation of service instance for %q, params: %#v", instance.ServiceName, params)
 	resp, err = c.issueRequest("/resources", "POST", params, requestID)
 	if err == nil {
@@ -140,6 +143,42 @@ func (c *Client) Create(instance *ServiceInstance, evt *event.Event, requestID s
 	return log.WrapError(err)
 }
 
+// Clone asks the service API to create the instance from a snapshot of the
+// source instance.
+func (c *Client) Clone(source, instance *ServiceInstance, evt *event.Event, requestID string) error {
+	params := map[string][]string{
+		"name":    {instance.Name},
+		"team":    {instance.TeamOwner},
+		"user":    {evt.Owner.Name},
+		"eventid": {evt.UniqueID.Hex()},
+	}
+	if instance.PlanName != "" {
+		params["plan"] = []string{instance.PlanName}
+	}
+	if instance.Description != "" {
+		params["description"] = []string{instance.Description}
+	}
+	addParameters(params, instance.Parameters)
+	log.Debugf("Attempting to call clone of service instance %q for %q, params: %#v", source.Name, instance.ServiceName, params)
+	resp, err := c.issueRequest("/resources/"+source.GetIdentifier()+"/clone", "POST", params, requestID)
+	if err == nil {
+		if resp.StatusCode < 300 {
+			resp.Body.Close()
+			return nil
+		}
+		switch resp.StatusCode {
+		case http.StatusConflict:
+			resp.Body.Close()
+			return ErrInstanceAlreadyExistsInAPI
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			resp.Body.Close()
+			return ErrCloneNotSupported
+		}
+	}
+	err = errors.Wrapf(c.buildErrorMessage(err, resp), "Failed to clone the instance %s", source.Name)
+	return log.WrapError(err)
+}
+
 func (c *Client) Update(instance *ServiceInstance, evt *event.Event, requestID string) error {
 	log.Debugf("Attempting to call update of service instance %q at %q api", instance.Name, instance.ServiceName)
 	params := map[string][]string{
@@ -150,6 +189,7 @@ func (c *Client) Update(instance *ServiceInstance, evt *event.Event, requestID s
 		"user":        {evt.Owner.Name},
 		"eventid":     {evt.UniqueID.Hex()},
 	}
+	addParameters(params, instance.Parameters)
 	resp, err := c.issueRequest("/resources/"+instance.GetIdentifier(), "PUT", params, requestID)
 	if err == nil {
 		defer resp.Body.Close()
@@ -320,6 +360,8 @@ func (c *Client) UnbindUnit(instance *ServiceInstance, app bind.App, unit bind.U
 	return err
 }
 
+// Status returns the status of the service instance. Services should reply
+// with 410 Gone for instances they don't know about anymore.
 func (c *Client) Status(instance *ServiceInstance, requestID string) (string, error) {
 	log.Debugf("Attempting to call status of service instance %q at %q api", instance.Name, instance.ServiceName)
 	var (
@@ -335,13 +377,15 @@ func (c *Client) Status(instance *ServiceInstance, requestID string) (string, er
 			data, err = ioutil.ReadAll(resp.Body)
 			return string(data), err
 		case http.StatusAccepted:
-			return "pending", nil
+			return InstanceStatusPending, nil
 		case http.StatusNoContent:
-			return "up", nil
+			return InstanceStatusUp, nil
 		case http.StatusNotFound:
-			return "not implemented for this service", nil
+			return instanceStatusNotImplemented, nil
+		case http.StatusGone:
+			return InstanceStatusGone, nil
 		case http.StatusInternalServerError:
-			return "down", nil
+			return InstanceStatusDown, nil
 		}
 	}
 	err = errors.Wrapf(c.buildErrorMessage(err, resp), "Failed to get status of instance %s", instance.Name)
@@ -393,6 +437,40 @@ func (c *Client) Plans(requestID string) ([]Plan, error) {
 	return result, nil
 }
 
+// ParametersSchema returns the JSON schema of the instance parameters
+// accepted by the service, or nil if the service doesn't publish one.
+// The api should be prepared to receive the request,
+// like below:
+// GET /resources/schema
+func (c *Client) ParametersSchema(requestID string) (*ParametersSchema, error) {
+	resp, err := c.issueRequest("/resources/schema", "GET", nil, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusNoContent:
+		return nil, nil
+	default:
+		return nil, errors.Wrap(c.buildErrorMessage(nil, resp), "Failed to get parameters schema")
+	}
+	var schema ParametersSchema
+	err = c.jsonFromResponse(resp, &schema)
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// addParameters adds the instance parameters to the request params, each
+// one prefixed by "parameters.".
+func addParameters(params map[string][]string, parameters map[string]interface{}) {
+	for k, v := range parameters {
+		params["parameters."+k] = []string{fmt.Sprint(v)}
+	}
+}
+
 // Proxy is a proxy between tsuru and the service.
 // This method allow customized service methods.
 func (c *Client) Proxy(path string, evt *event.Event, requestID string, w http.ResponseWriter, r *http.Request) error {
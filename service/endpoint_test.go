@@ -630,6 +630,7 @@ func (s *S) TestStatus(c *check.C) {
 		{http.StatusNoContent, "up"},
 		{http.StatusAccepted, "pending"},
 		{http.StatusNotFound, "not implemented for this service"},
+		{http.StatusGone, "gone"},
 		{http.StatusInternalServerError, "down"},
 	}
 	var request int
@@ -736,3 +737,53 @@ func (s *S) TestProxyWithBodyAndHeaders(c *check.C) {
 	c.Assert(proxiedRequest.Host, check.Equals, tsURL.Host)
 	c.Assert(string(readBodyStr), check.Equals, `{"bla": "bla"}`)
 }
+
+func (s *S) TestEndpointCreateWithParameters(c *check.C) {
+	h := TestHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	instance := ServiceInstance{Name: "my-redis", ServiceName: "redis", TeamOwner: "theteam", Parameters: map[string]interface{}{
+		"version": "4.0",
+		"size":    int64(3),
+	}}
+	client := &Client{endpoint: ts.URL, username: "user", password: "abcde"}
+	evt := createEvt(c)
+	err := client.Create(&instance, evt, "")
+	c.Assert(err, check.IsNil)
+	h.Lock()
+	defer h.Unlock()
+	v, err := url.ParseQuery(string(h.body))
+	c.Assert(err, check.IsNil)
+	c.Assert(v.Get("parameters.version"), check.Equals, "4.0")
+	c.Assert(v.Get("parameters.size"), check.Equals, "3")
+}
+
+func (s *S) TestParametersSchema(c *check.C) {
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"type": "object", "properties": {"size": {"type": "integer", "minimum": 1}}, "required": ["size"]}`))
+	}))
+	defer ts.Close()
+	client := &Client{endpoint: ts.URL, username: "user", password: "abcde"}
+	schema, err := client.ParametersSchema("")
+	c.Assert(err, check.IsNil)
+	c.Assert(path, check.Equals, "/resources/schema")
+	min := float64(1)
+	c.Assert(schema, check.DeepEquals, &ParametersSchema{
+		Type:       "object",
+		Properties: map[string]*PropertySchema{"size": {Type: SchemaTypeInteger, Minimum: &min}},
+		Required:   []string{"size"},
+	})
+}
+
+func (s *S) TestParametersSchemaNotFound(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	client := &Client{endpoint: ts.URL, username: "user", password: "abcde"}
+	schema, err := client.ParametersSchema("")
+	c.Assert(err, check.IsNil)
+	c.Assert(schema, check.IsNil)
+}
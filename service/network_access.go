@@ -0,0 +1,118 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
+)
+
+// NetworkAccessRequest is the payload sent to the network access hook of a
+// service, describing the app that must be allowed to, or no longer be
+// allowed to, reach the service instance.
+type NetworkAccessRequest struct {
+	Service   string   `json:"service"`
+	Instance  string   `json:"instance"`
+	App       string   `json:"app"`
+	Addresses []string `json:"addresses"`
+	UnitIPs   []string `json:"unitIPs"`
+}
+
+func validateNetworkAccessHook(hook string) error {
+	if hook == "" {
+		return nil
+	}
+	u, err := url.Parse(hook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid network access hook, it must be an http or https URL")
+	}
+	return nil
+}
+
+func newNetworkAccessRequest(si *ServiceInstance, app bind.App) (*NetworkAccessRequest, error) {
+	addresses, err := app.GetAddresses()
+	if err != nil {
+		return nil, err
+	}
+	units, err := app.GetUnits()
+	if err != nil {
+		return nil, err
+	}
+	unitIPs := make([]string, 0, len(units))
+	for _, u := range units {
+		if ip := u.GetIp(); ip != "" {
+			unitIPs = append(unitIPs, ip)
+		}
+	}
+	return &NetworkAccessRequest{
+		Service:   si.ServiceName,
+		Instance:  si.Name,
+		App:       app.GetName(),
+		Addresses: addresses,
+		UnitIPs:   unitIPs,
+	}, nil
+}
+
+// AllowNetworkAccess calls the network access hook of the service, if any,
+// asking it to allow the app to reach the service instance.
+func (si *ServiceInstance) AllowNetworkAccess(app bind.App, requestID string) error {
+	return si.callNetworkAccessHook(http.MethodPost, app, requestID)
+}
+
+// RevokeNetworkAccess calls the network access hook of the service, if any,
+// asking it to stop allowing the app to reach the service instance.
+func (si *ServiceInstance) RevokeNetworkAccess(app bind.App, requestID string) error {
+	return si.callNetworkAccessHook(http.MethodDelete, app, requestID)
+}
+
+func (si *ServiceInstance) callNetworkAccessHook(method string, app bind.App, requestID string) error {
+	s := si.Service()
+	if s == nil || s.NetworkAccessHook == "" {
+		return nil
+	}
+	payload, err := newNetworkAccessRequest(si, app)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, s.NetworkAccessHook, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	requestIDHeader, err := config.GetString("request-id-header")
+	if err == nil && requestID != "" && requestIDHeader != "" {
+		req.Header.Set(requestIDHeader, requestID)
+	}
+	req.SetBasicAuth(s.GetUsername(), s.Password)
+	req.Close = true
+	resp, err := net.Dial5Full300ClientNoKeepAlive.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "unable to call network access hook of service %q", s.Name)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	log.Errorf("network access hook of service %q returned %d: %s", s.Name, resp.StatusCode, body)
+	return errors.Errorf("network access hook of service %q failed with status %d: %s", s.Name, resp.StatusCode, body)
+}
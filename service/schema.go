@@ -0,0 +1,234 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	SchemaTypeString  = "string"
+	SchemaTypeInteger = "integer"
+	SchemaTypeNumber  = "number"
+	SchemaTypeBoolean = "boolean"
+)
+
+// ParametersSchema is the JSON schema published by a service describing the
+// parameters accepted on instance creation and update. Only flat objects with
+// scalar properties are supported.
+type ParametersSchema struct {
+	Type                 string                     `json:"type,omitempty"`
+	Properties           map[string]*PropertySchema `json:"properties,omitempty"`
+	Required             []string                   `json:"required,omitempty"`
+	AdditionalProperties *bool                      `json:"additionalProperties,omitempty"`
+}
+
+// PropertySchema is the JSON schema of a single instance parameter.
+type PropertySchema struct {
+	Type        string        `json:"type,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	MinLength   *int          `json:"minLength,omitempty"`
+	MaxLength   *int          `json:"maxLength,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"`
+}
+
+// GetParametersSchema returns the parameters schema published by the service,
+// or nil if the service doesn't publish one.
+func GetParametersSchema(serviceName, requestID string) (*ParametersSchema, error) {
+	s := Service{Name: serviceName}
+	err := s.Get()
+	if err != nil {
+		return nil, err
+	}
+	return s.parametersSchema(requestID)
+}
+
+func (s *Service) parametersSchema(requestID string) (*ParametersSchema, error) {
+	endpoint, err := s.getClient("production")
+	if err != nil {
+		return nil, nil
+	}
+	return endpoint.ParametersSchema(requestID)
+}
+
+// validateParameters validates the instance parameters against the schema
+// published by the service, converting them to the types in the schema.
+// Parameters are accepted as they are when the service has no schema. Errors
+// getting the schema are ignored when there are no parameters, as services
+// not aware of schemas may fail to answer the request.
+func (s *Service) validateParameters(params map[string]interface{}, requestID string) (map[string]interface{}, error) {
+	if len(params) == 0 {
+		params = nil
+	}
+	schema, err := s.parametersSchema(requestID)
+	if err != nil {
+		if params == nil {
+			log.Errorf("unable to get parameters schema of service %q: %s", s.Name, err)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if schema == nil {
+		return params, nil
+	}
+	return schema.Validate(params)
+}
+
+// Validate checks the parameters against the schema, returning them converted
+// to the types in the schema. Parameters given as strings, like the ones sent
+// in forms, are parsed.
+func (schema *ParametersSchema) Validate(params map[string]interface{}) (map[string]interface{}, error) {
+	var msgs []string
+	result := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		prop, ok := schema.Properties[name]
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				msgs = append(msgs, fmt.Sprintf("%s: unknown parameter", name))
+				continue
+			}
+			result[name] = value
+			continue
+		}
+		converted, err := prop.validate(value)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		result[name] = converted
+	}
+	for _, name := range schema.Required {
+		if _, ok := params[name]; !ok {
+			msgs = append(msgs, fmt.Sprintf("%s: required parameter", name))
+		}
+	}
+	if len(msgs) > 0 {
+		sort.Strings(msgs)
+		return nil, &tsuruErrors.ValidationError{Message: "Invalid parameters: " + strings.Join(msgs, "; ")}
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func (prop *PropertySchema) validate(value interface{}) (interface{}, error) {
+	converted, err := prop.convert(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(prop.Enum) > 0 {
+		var found bool
+		for _, v := range prop.Enum {
+			if fmt.Sprint(v) == fmt.Sprint(converted) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("must be one of %v", prop.Enum)
+		}
+	}
+	switch v := converted.(type) {
+	case string:
+		if prop.MinLength != nil && len(v) < *prop.MinLength {
+			return nil, fmt.Errorf("must have at least %d characters", *prop.MinLength)
+		}
+		if prop.MaxLength != nil && len(v) > *prop.MaxLength {
+			return nil, fmt.Errorf("must have at most %d characters", *prop.MaxLength)
+		}
+		if prop.Pattern != "" {
+			match, err := regexp.MatchString(prop.Pattern, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern in schema: %s", err)
+			}
+			if !match {
+				return nil, fmt.Errorf("must match %q", prop.Pattern)
+			}
+		}
+	case int64:
+		err = prop.validateRange(float64(v))
+	case float64:
+		err = prop.validateRange(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+func (prop *PropertySchema) validateRange(v float64) error {
+	if prop.Minimum != nil && v < *prop.Minimum {
+		return fmt.Errorf("must be greater than or equal to %v", *prop.Minimum)
+	}
+	if prop.Maximum != nil && v > *prop.Maximum {
+		return fmt.Errorf("must be less than or equal to %v", *prop.Maximum)
+	}
+	return nil
+}
+
+func (prop *PropertySchema) convert(value interface{}) (interface{}, error) {
+	str, isString := value.(string)
+	switch prop.Type {
+	case "", SchemaTypeString:
+		if isString {
+			return str, nil
+		}
+		if prop.Type == "" {
+			return value, nil
+		}
+	case SchemaTypeInteger:
+		switch v := value.(type) {
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i, nil
+			}
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case float64:
+			if v == float64(int64(v)) {
+				return int64(v), nil
+			}
+		}
+	case SchemaTypeNumber:
+		switch v := value.(type) {
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case SchemaTypeBoolean:
+		switch v := value.(type) {
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		case bool:
+			return v, nil
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("must be of type %s", prop.Type)
+}
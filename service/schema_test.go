@@ -0,0 +1,65 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"encoding/json"
+
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestParametersSchemaValidate(c *check.C) {
+	var schema ParametersSchema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"size": {"type": "integer", "minimum": 1, "maximum": 10},
+			"ratio": {"type": "number"},
+			"replicated": {"type": "boolean"},
+			"engine": {"type": "string", "enum": ["innodb", "myisam"]},
+			"name": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 5}
+		},
+		"required": ["size"],
+		"additionalProperties": false
+	}`), &schema)
+	c.Assert(err, check.IsNil)
+	params, err := schema.Validate(map[string]interface{}{
+		"size":       "3",
+		"ratio":      "0.5",
+		"replicated": "true",
+		"engine":     "innodb",
+		"name":       "abc",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(params, check.DeepEquals, map[string]interface{}{
+		"size":       int64(3),
+		"ratio":      0.5,
+		"replicated": true,
+		"engine":     "innodb",
+		"name":       "abc",
+	})
+	_, err = schema.Validate(map[string]interface{}{
+		"size":   "11",
+		"engine": "memory",
+		"name":   "abcdef",
+		"other":  "x",
+	})
+	c.Assert(err, check.ErrorMatches, `Invalid parameters: engine: must be one of \[innodb myisam\]; `+
+		`name: must have at most 5 characters; other: unknown parameter; size: must be less than or equal to 10`)
+	_, err = schema.Validate(map[string]interface{}{"replicated": "maybe"})
+	c.Assert(err, check.ErrorMatches, `Invalid parameters: replicated: must be of type boolean; size: required parameter`)
+}
+
+func (s *S) TestParametersSchemaValidateAdditionalProperties(c *check.C) {
+	schema := ParametersSchema{
+		Properties: map[string]*PropertySchema{"size": {Type: SchemaTypeInteger}},
+	}
+	params, err := schema.Validate(map[string]interface{}{"size": float64(2), "other": "x"})
+	c.Assert(err, check.IsNil)
+	c.Assert(params, check.DeepEquals, map[string]interface{}{"size": int64(2), "other": "x"})
+	params, err = schema.Validate(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(params, check.IsNil)
+}
@@ -28,6 +28,10 @@ type Service struct {
 	Teams        []string
 	Doc          string
 	IsRestricted bool `bson:"is_restricted"`
+	// NetworkAccessHook is an optional URL called when apps are bound to and
+	// unbound from instances of the service, so network access between them
+	// can be granted and revoked.
+	NetworkAccessHook string `bson:"network_access_hook,omitempty"`
 }
 
 var (
@@ -155,6 +159,9 @@ func (s *Service) validate(skipName bool) (err error) {
 	if endpoint, ok := s.Endpoint["production"]; !ok || endpoint == "" {
 		return fmt.Errorf("Service production endpoint is required")
 	}
+	if err = validateNetworkAccessHook(s.NetworkAccessHook); err != nil {
+		return err
+	}
 	return s.validateOwnerTeams()
 }
 
@@ -50,6 +50,8 @@ type ServiceInstance struct {
 	TeamOwner   string
 	Description string
 	Tags        []string
+	Health      *InstanceHealth        `bson:",omitempty"`
+	Parameters  map[string]interface{} `bson:",omitempty"`
 }
 
 type Unit struct {
@@ -163,6 +165,12 @@ func (si *ServiceInstance) Update(service Service, updateData ServiceInstance, e
 	} else {
 		updateData.Tags = tags
 	}
+	if updateData.Parameters != nil {
+		updateData.Parameters, err = service.validateParameters(updateData.Parameters, requestID)
+		if err != nil {
+			return err
+		}
+	}
 	actions := []*action.Action{&updateServiceInstance, &notifyUpdateServiceInstance}
 	pipeline := action.NewPipeline(actions...)
 	return pipeline.Execute(service, *si, updateData, evt, requestID)
@@ -189,6 +197,7 @@ func (si *ServiceInstance) BindApp(app bind.App, shouldRestart bool, writer io.W
 	}
 	actions := []*action.Action{
 		bindAppDBAction,
+		allowNetworkAccessAction,
 		bindAppEndpointAction,
 		setBoundEnvsAction,
 		bindUnitsAction,
@@ -262,6 +271,7 @@ func (si *ServiceInstance) UnbindApp(app bind.App, shouldRestart bool, writer io
 		&unbindAppDB,
 		&unbindAppEndpoint,
 		&removeBoundEnvs,
+		&revokeNetworkAccess,
 	}
 	pipeline := action.NewPipeline(actions...)
 	return pipeline.Execute(&args)
@@ -401,6 +411,10 @@ func CreateServiceInstance(instance ServiceInstance, service *Service, evt *even
 	instance.ServiceName = service.Name
 	instance.Teams = []string{instance.TeamOwner}
 	instance.Tags = processTags(instance.Tags)
+	instance.Parameters, err = service.validateParameters(instance.Parameters, requestID)
+	if err != nil {
+		return err
+	}
 	actions := []*action.Action{&notifyCreateServiceInstance, &createServiceInstance}
 	pipeline := action.NewPipeline(actions...)
 	return pipeline.Execute(*service, instance, evt, requestID)
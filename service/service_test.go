@@ -90,6 +90,10 @@ func (s *S) TestCreateServiceMissingFields(c *check.C) {
 	service.OwnerTeams = []string{s.team.Name, ""}
 	err = service.Create()
 	c.Assert(err, check.ErrorMatches, "Team owner doesn't exist")
+	service.OwnerTeams = []string{s.team.Name}
+	service.NetworkAccessHook = "firewall.example.com/rules"
+	err = service.Create()
+	c.Assert(err, check.ErrorMatches, "Invalid network access hook, it must be an http or https URL")
 }
 
 func (s *S) TestDeleteService(c *check.C) {
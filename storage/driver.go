@@ -19,6 +19,7 @@ type DbDriver struct {
 	PlatformStorage app.PlatformStorage
 	PlanStorage     app.PlanStorage
 	CacheService    cache.CacheService
+	TokenStorage    auth.TokenStorage
 }
 
 var (
@@ -26,6 +27,7 @@ var (
 	dbDrivers           = make(map[string]DbDriver)
 	driverLock          sync.RWMutex
 	currentDbDriver     *DbDriver
+	tokenStorages       = make(map[string]auth.TokenStorage)
 )
 
 // RegisterDbDriver registers a new DB driver
@@ -71,3 +73,28 @@ func GetCurrentDbDriver() (*DbDriver, error) {
 func GetDefaultDbDriver() (*DbDriver, error) {
 	return GetDbDriver(DefaultDbDriverName)
 }
+
+// RegisterTokenStorage registers a token storage that may be used in place of
+// the one of the DB driver.
+func RegisterTokenStorage(name string, storage auth.TokenStorage) {
+	tokenStorages[name] = storage
+}
+
+// GetTokenStorage returns the token storage specified in the configuration
+// file. If this configuration was omitted, it returns the token storage of the
+// current DB driver
+func GetTokenStorage() (auth.TokenStorage, error) {
+	name, _ := config.GetString("auth:token-storage")
+	if name == "" {
+		driver, err := GetCurrentDbDriver()
+		if err != nil {
+			return nil, err
+		}
+		return driver.TokenStorage, nil
+	}
+	storage, ok := tokenStorages[name]
+	if !ok {
+		return nil, errors.Errorf("Unknown token storage: %q.", name)
+	}
+	return storage, nil
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/types/auth"
 
 	check "gopkg.in/check.v1"
 )
@@ -23,6 +24,7 @@ func Test(t *testing.T) {
 
 func (s *S) TearDownTest(c *check.C) {
 	dbDrivers = make(map[string]DbDriver)
+	tokenStorages = make(map[string]auth.TokenStorage)
 }
 
 func (s *S) TestRegisterDbDriver(c *check.C) {
@@ -67,3 +69,33 @@ func (s *S) TestGetDefaultDbDriver(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(driver, check.NotNil)
 }
+
+func (s *S) TestGetTokenStorage(c *check.C) {
+	storage := &auth.MockTokenStorage{}
+	RegisterTokenStorage("redis", storage)
+	config.Set("auth:token-storage", "redis")
+	defer config.Unset("auth:token-storage")
+	tokenStorage, err := GetTokenStorage()
+	c.Assert(err, check.IsNil)
+	c.Assert(tokenStorage, check.Equals, storage)
+}
+
+func (s *S) TestGetTokenStorageUnknown(c *check.C) {
+	config.Set("auth:token-storage", "memcached")
+	defer config.Unset("auth:token-storage")
+	tokenStorage, err := GetTokenStorage()
+	c.Assert(err, check.ErrorMatches, `Unknown token storage: "memcached".`)
+	c.Assert(tokenStorage, check.IsNil)
+}
+
+func (s *S) TestGetTokenStorageFromDbDriver(c *check.C) {
+	storage := &auth.MockTokenStorage{}
+	RegisterDbDriver("mysql", DbDriver{TokenStorage: storage})
+	config.Set("database:driver", "mysql")
+	defer config.Unset("database:driver")
+	currentDbDriver = nil
+	defer func() { currentDbDriver = nil }()
+	tokenStorage, err := GetTokenStorage()
+	c.Assert(err, check.IsNil)
+	c.Assert(tokenStorage, check.Equals, storage)
+}
@@ -12,6 +12,7 @@ func init() {
 		PlatformStorage: &PlatformStorage{},
 		PlanStorage:     &PlanStorage{},
 		CacheService:    &cacheService{},
+		TokenStorage:    &TokenStorage{},
 	}
 	storage.RegisterDbDriver("mongodb", mongodbDriver)
 }
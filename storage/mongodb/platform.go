@@ -17,8 +17,9 @@ var _ app.PlatformStorage = &PlatformStorage{}
 type PlatformStorage struct{}
 
 type platform struct {
-	Name     string `bson:"_id"`
-	Disabled bool   `bson:",omitempty"`
+	Name       string `bson:"_id"`
+	Disabled   bool   `bson:",omitempty"`
+	Deprecated bool   `bson:",omitempty"`
 }
 
 func platformsCollection(conn *db.Storage) *dbStorage.Collection {
@@ -89,7 +90,7 @@ func (s *PlatformStorage) Update(p app.Platform) error {
 		return err
 	}
 	defer conn.Close()
-	return platformsCollection(conn).Update(bson.M{"_id": p.Name}, bson.M{"$set": bson.M{"disabled": p.Disabled}})
+	return platformsCollection(conn).Update(bson.M{"_id": p.Name}, bson.M{"$set": bson.M{"disabled": p.Disabled, "deprecated": p.Deprecated}})
 }
 
 func (s *PlatformStorage) Delete(p app.Platform) error {
@@ -0,0 +1,110 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/types/auth"
+)
+
+type TokenStorage struct{}
+
+var _ auth.TokenStorage = &TokenStorage{}
+
+type token struct {
+	Token     string
+	Creation  time.Time
+	Expires   time.Duration
+	UserEmail string
+	AppName   string
+	Client    string
+	IP        string
+	LastUse   time.Time
+}
+
+func (s *TokenStorage) Insert(t auth.Token) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Tokens().Insert(token(t))
+}
+
+func (s *TokenStorage) FindByToken(value string) (*auth.Token, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var t token
+	err = conn.Tokens().Find(bson.M{"token": value}).One(&t)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			err = auth.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	authToken := auth.Token(t)
+	return &authToken, nil
+}
+
+func (s *TokenStorage) FindByUserEmail(email string) ([]auth.Token, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var tokens []token
+	err = conn.Tokens().Find(bson.M{"useremail": email}).Sort("creation").All(&tokens)
+	if err != nil {
+		return nil, err
+	}
+	authTokens := make([]auth.Token, len(tokens))
+	for i, t := range tokens {
+		authTokens[i] = auth.Token(t)
+	}
+	return authTokens, nil
+}
+
+func (s *TokenStorage) UpdateLastUse(value string, lastUse time.Time) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Tokens().Update(bson.M{"token": value}, bson.M{"$set": bson.M{"lastuse": lastUse}})
+	if err == mgo.ErrNotFound {
+		return auth.ErrTokenNotFound
+	}
+	return err
+}
+
+func (s *TokenStorage) Delete(t auth.Token) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Tokens().Remove(bson.M{"token": t.Token})
+	if err == mgo.ErrNotFound {
+		return auth.ErrTokenNotFound
+	}
+	return err
+}
+
+func (s *TokenStorage) DeleteByUserEmail(email string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Tokens().RemoveAll(bson.M{"useremail": email})
+	return err
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package redis provides storages backed by redis, which may be used in place
+// of the ones provided by the DB driver.
+package redis
+
+import (
+	"sync"
+
+	tsuruRedis "github.com/tsuru/tsuru/redis"
+	"github.com/tsuru/tsuru/storage"
+)
+
+const configPrefix = "auth:redis"
+
+var (
+	clientMu sync.Mutex
+	client   tsuruRedis.Client
+)
+
+func init() {
+	storage.RegisterTokenStorage("redis", &TokenStorage{})
+}
+
+func redisClient() (tsuruRedis.Client, error) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client != nil {
+		return client, nil
+	}
+	var err error
+	client, err = tsuruRedis.NewRedis(configPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
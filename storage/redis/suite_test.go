@@ -0,0 +1,38 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redis
+
+import (
+	"testing"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type redisBaseTest struct{}
+
+func (t *redisBaseTest) SetUpSuite(c *check.C) {
+	config.Set(configPrefix+":redis-server", "127.0.0.1:6379")
+	config.Set(configPrefix+":redis-db", 3)
+}
+
+func (t *redisBaseTest) SetUpTest(c *check.C) {
+	conn, err := redisClient()
+	c.Assert(err, check.IsNil)
+	keys, err := conn.Keys("tsuru:*").Result()
+	c.Assert(err, check.IsNil)
+	for _, key := range keys {
+		conn.Del(key)
+	}
+}
+
+func (t *redisBaseTest) TearDownTest(c *check.C) {
+}
+
+func (t *redisBaseTest) TearDownSuite(c *check.C) {
+	config.Unset(configPrefix)
+}
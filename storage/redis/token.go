@@ -0,0 +1,200 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redis
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/types/auth"
+	"gopkg.in/redis.v3"
+)
+
+// TokenStorage keeps each token in its own key, expiring along with the token,
+// and the tokens of each user in a list used to find them by email. Values of
+// expired tokens are lazily removed from the lists.
+type TokenStorage struct{}
+
+var _ auth.TokenStorage = &TokenStorage{}
+
+func tokenKey(token string) string {
+	return "tsuru:token:" + token
+}
+
+func userTokensKey(email string) string {
+	return "tsuru:user-tokens:" + email
+}
+
+// pushUserTokenScript adds a token to the list of tokens of a user, keeping
+// the list alive for as long as its longest-lived token. The TTL of the list
+// is only ever extended, and tokens that never expire, with a zero TTL, keep
+// the list forever.
+const pushUserTokenScript = `
+local current = redis.call("pttl", KEYS[1])
+redis.call("rpush", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl == 0 or current == -1 then
+	redis.call("persist", KEYS[1])
+elseif current < ttl then
+	redis.call("pexpire", KEYS[1], ttl)
+end
+return 1
+`
+
+func (s *TokenStorage) Insert(t auth.Token) error {
+	conn, err := redisClient()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	ttl, expired := tokenTTL(t)
+	if expired {
+		return nil
+	}
+	pipe := conn.Pipeline()
+	pipe.Set(tokenKey(t.Token), data, ttl)
+	if t.UserEmail != "" {
+		ttlMillis := strconv.FormatInt(int64((ttl+time.Millisecond-1)/time.Millisecond), 10)
+		pipe.Eval(pushUserTokenScript, []string{userTokensKey(t.UserEmail)}, []string{t.Token, ttlMillis})
+	}
+	_, err = pipe.Exec()
+	return err
+}
+
+// tokenTTL returns the time to live of the key of the token, zero for tokens
+// that never expire.
+func tokenTTL(t auth.Token) (time.Duration, bool) {
+	if t.Expires <= 0 {
+		return 0, false
+	}
+	ttl := time.Until(t.Creation.Add(t.Expires))
+	return ttl, ttl <= 0
+}
+
+func (s *TokenStorage) FindByToken(value string) (*auth.Token, error) {
+	conn, err := redisClient()
+	if err != nil {
+		return nil, err
+	}
+	data, err := conn.Get(tokenKey(value)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			err = auth.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	var t auth.Token
+	err = json.Unmarshal(data, &t)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *TokenStorage) FindByUserEmail(email string) ([]auth.Token, error) {
+	conn, err := redisClient()
+	if err != nil {
+		return nil, err
+	}
+	values, err := conn.LRange(userTokensKey(email), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	tokens := []auth.Token{}
+	for _, value := range values {
+		t, err := s.FindByToken(value)
+		if err == auth.ErrTokenNotFound {
+			conn.LRem(userTokensKey(email), 0, value)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Creation.Before(tokens[j].Creation)
+	})
+	return tokens, nil
+}
+
+func (s *TokenStorage) UpdateLastUse(value string, lastUse time.Time) error {
+	conn, err := redisClient()
+	if err != nil {
+		return err
+	}
+	t, err := s.FindByToken(value)
+	if err != nil {
+		return err
+	}
+	t.LastUse = lastUse
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	ttl, expired := tokenTTL(*t)
+	if expired {
+		return auth.ErrTokenNotFound
+	}
+	// XX ensures a token removed meanwhile is not brought back.
+	updated, err := conn.SetXX(tokenKey(value), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return auth.ErrTokenNotFound
+	}
+	return nil
+}
+
+func (s *TokenStorage) Delete(t auth.Token) error {
+	conn, err := redisClient()
+	if err != nil {
+		return err
+	}
+	if t.UserEmail == "" {
+		stored, err := s.FindByToken(t.Token)
+		if err != nil {
+			return err
+		}
+		t.UserEmail = stored.UserEmail
+	}
+	removed, err := conn.Del(tokenKey(t.Token)).Result()
+	if err != nil {
+		return err
+	}
+	if t.UserEmail != "" {
+		conn.LRem(userTokensKey(t.UserEmail), 0, t.Token)
+	}
+	if removed == 0 {
+		return auth.ErrTokenNotFound
+	}
+	return nil
+}
+
+func (s *TokenStorage) DeleteByUserEmail(email string) error {
+	conn, err := redisClient()
+	if err != nil {
+		return err
+	}
+	values, err := conn.LRange(userTokensKey(email), 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	// Keys are removed one by one, as they may live in different nodes of a
+	// redis cluster.
+	pipe := conn.Pipeline()
+	for _, value := range values {
+		pipe.Del(tokenKey(value))
+	}
+	pipe.Del(userTokensKey(email))
+	_, err = pipe.Exec()
+	return err
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redis
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/storage/storagetest"
+	"github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&storagetest.TokenSuite{
+	TokenStorage: &TokenStorage{},
+	SuiteHooks:   &redisBaseTest{},
+})
+
+type S struct {
+	redisBaseTest
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestTokenExpires(c *check.C) {
+	storage := &TokenStorage{}
+	err := storage.Insert(auth.Token{Token: "abc", Creation: time.Now(), Expires: time.Second, UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	_, err = storage.FindByToken("abc")
+	c.Assert(err, check.IsNil)
+	time.Sleep(1100 * time.Millisecond)
+	_, err = storage.FindByToken("abc")
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+	tokens, err := storage.FindByUserEmail("me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+}
+
+func (s *S) TestInsertExpiredToken(c *check.C) {
+	storage := &TokenStorage{}
+	err := storage.Insert(auth.Token{Token: "abc", Creation: time.Now().Add(-2 * time.Hour), Expires: time.Hour, UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	_, err = storage.FindByToken("abc")
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+}
+
+func (s *S) TestShortLivedTokenKeepsUserTokens(c *check.C) {
+	storage := &TokenStorage{}
+	err := storage.Insert(auth.Token{Token: "long", Creation: time.Now(), Expires: time.Hour, UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	err = storage.Insert(auth.Token{Token: "forever", UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	err = storage.Insert(auth.Token{Token: "short", Creation: time.Now(), Expires: time.Second, UserEmail: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	time.Sleep(1100 * time.Millisecond)
+	tokens, err := storage.FindByUserEmail("me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 2)
+	err = storage.DeleteByUserEmail("me@example.com")
+	c.Assert(err, check.IsNil)
+	_, err = storage.FindByToken("long")
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+	_, err = storage.FindByToken("forever")
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+}
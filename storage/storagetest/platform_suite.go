@@ -88,6 +88,18 @@ func (s *PlatformSuite) TestUpdatePlatform(c *check.C) {
 	c.Assert(p.Disabled, check.Equals, true)
 }
 
+func (s *PlatformSuite) TestUpdatePlatformDeprecated(c *check.C) {
+	platform := app.Platform{Name: "static", Disabled: true}
+	err := s.PlatformStorage.Insert(platform)
+	c.Assert(err, check.IsNil)
+	platform.Deprecated = true
+	err = s.PlatformStorage.Update(platform)
+	c.Assert(err, check.IsNil)
+	p, err := s.PlatformStorage.FindByName("static")
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, &app.Platform{Name: "static", Disabled: true, Deprecated: true})
+}
+
 func (s *PlatformSuite) TestUpdatePlatformNotFound(c *check.C) {
 	platform := app.Platform{Name: "static"}
 	err := s.PlatformStorage.Update(platform)
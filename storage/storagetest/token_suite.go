@@ -0,0 +1,120 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"time"
+
+	"github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+type TokenSuite struct {
+	SuiteHooks
+	TokenStorage auth.TokenStorage
+}
+
+func (s *TokenSuite) TestInsertToken(c *check.C) {
+	now := time.Now().Truncate(time.Second)
+	t := auth.Token{Token: "abc", Creation: now, Expires: time.Hour, UserEmail: "me@example.com", Client: "tsuru-client", IP: "10.0.0.1"}
+	err := s.TokenStorage.Insert(t)
+	c.Assert(err, check.IsNil)
+	token, err := s.TokenStorage.FindByToken("abc")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.Token, check.Equals, t.Token)
+	c.Assert(token.Creation.Equal(now), check.Equals, true)
+	c.Assert(token.Expires, check.Equals, t.Expires)
+	c.Assert(token.UserEmail, check.Equals, t.UserEmail)
+	c.Assert(token.AppName, check.Equals, "")
+	c.Assert(token.Client, check.Equals, t.Client)
+	c.Assert(token.IP, check.Equals, t.IP)
+	c.Assert(token.LastUse.IsZero(), check.Equals, true)
+}
+
+func (s *TokenSuite) TestInsertAppToken(c *check.C) {
+	t := auth.Token{Token: "abc", Creation: time.Now(), AppName: "myapp"}
+	err := s.TokenStorage.Insert(t)
+	c.Assert(err, check.IsNil)
+	token, err := s.TokenStorage.FindByToken("abc")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.AppName, check.Equals, "myapp")
+	c.Assert(token.Expires, check.Equals, time.Duration(0))
+}
+
+func (s *TokenSuite) TestFindByTokenNotFound(c *check.C) {
+	token, err := s.TokenStorage.FindByToken("abc")
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+	c.Assert(token, check.IsNil)
+}
+
+func (s *TokenSuite) TestFindByUserEmail(c *check.C) {
+	now := time.Now()
+	for i, value := range []string{"t2", "t1", "t3"} {
+		err := s.TokenStorage.Insert(auth.Token{
+			Token:     value,
+			Creation:  now.Add(-time.Duration(i) * time.Minute),
+			Expires:   time.Hour,
+			UserEmail: "me@example.com",
+		})
+		c.Assert(err, check.IsNil)
+	}
+	err := s.TokenStorage.Insert(auth.Token{Token: "other", Creation: now, Expires: time.Hour, UserEmail: "other@example.com"})
+	c.Assert(err, check.IsNil)
+	tokens, err := s.TokenStorage.FindByUserEmail("me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 3)
+	c.Assert([]string{tokens[0].Token, tokens[1].Token, tokens[2].Token}, check.DeepEquals, []string{"t3", "t1", "t2"})
+	tokens, err = s.TokenStorage.FindByUserEmail("nobody@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+}
+
+func (s *TokenSuite) TestUpdateLastUse(c *check.C) {
+	t := auth.Token{Token: "abc", Creation: time.Now(), Expires: time.Hour, UserEmail: "me@example.com"}
+	err := s.TokenStorage.Insert(t)
+	c.Assert(err, check.IsNil)
+	lastUse := time.Now().Truncate(time.Second)
+	err = s.TokenStorage.UpdateLastUse("abc", lastUse)
+	c.Assert(err, check.IsNil)
+	token, err := s.TokenStorage.FindByToken("abc")
+	c.Assert(err, check.IsNil)
+	c.Assert(token.LastUse.Equal(lastUse), check.Equals, true)
+	c.Assert(token.UserEmail, check.Equals, t.UserEmail)
+	err = s.TokenStorage.UpdateLastUse("xyz", lastUse)
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+}
+
+func (s *TokenSuite) TestDeleteToken(c *check.C) {
+	t := auth.Token{Token: "abc", Creation: time.Now(), Expires: time.Hour, UserEmail: "me@example.com"}
+	err := s.TokenStorage.Insert(t)
+	c.Assert(err, check.IsNil)
+	err = s.TokenStorage.Delete(auth.Token{Token: "abc"})
+	c.Assert(err, check.IsNil)
+	_, err = s.TokenStorage.FindByToken("abc")
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+	tokens, err := s.TokenStorage.FindByUserEmail("me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+	err = s.TokenStorage.Delete(auth.Token{Token: "abc"})
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+}
+
+func (s *TokenSuite) TestDeleteByUserEmail(c *check.C) {
+	for _, value := range []string{"t1", "t2"} {
+		err := s.TokenStorage.Insert(auth.Token{Token: value, Creation: time.Now(), Expires: time.Hour, UserEmail: "me@example.com"})
+		c.Assert(err, check.IsNil)
+	}
+	err := s.TokenStorage.Insert(auth.Token{Token: "other", Creation: time.Now(), Expires: time.Hour, UserEmail: "other@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.TokenStorage.DeleteByUserEmail("me@example.com")
+	c.Assert(err, check.IsNil)
+	tokens, err := s.TokenStorage.FindByUserEmail("me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 0)
+	_, err = s.TokenStorage.FindByToken("t1")
+	c.Assert(err, check.Equals, auth.ErrTokenNotFound)
+	_, err = s.TokenStorage.FindByToken("other")
+	c.Assert(err, check.IsNil)
+}
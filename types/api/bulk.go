@@ -0,0 +1,20 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+// BulkResult holds the outcome of a bulk operation for a single app.
+type BulkResult struct {
+	App   string `json:"app"`
+	Error string `json:"error,omitempty"`
+}
+
+// BulkReport is the consolidated report of a bulk operation applied to
+// multiple apps.
+type BulkReport struct {
+	Total     int          `json:"total"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []BulkResult `json:"results"`
+}
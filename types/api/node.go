@@ -5,6 +5,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/tsuru/tsuru/healer"
 	"github.com/tsuru/tsuru/iaas"
 	"github.com/tsuru/tsuru/provision"
@@ -16,7 +18,25 @@ type ListNodeResponse struct {
 }
 
 type InfoNodeResponse struct {
-	Node   provision.NodeSpec    `json:"node"`
-	Status healer.NodeStatusData `json:"status"`
-	Units  []provision.Unit      `json:"units"`
+	Node    provision.NodeSpec            `json:"node"`
+	Status  healer.NodeStatusData         `json:"status"`
+	Units   []provision.Unit              `json:"units"`
+	Breaker *provision.NodeCircuitBreaker `json:"breaker,omitempty"`
+}
+
+type ClusterTopology struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Nodes     []TopologyNode `json:"nodes"`
+}
+
+type TopologyNode struct {
+	Node           provision.NodeSpec      `json:"node"`
+	Status         healer.NodeStatusData   `json:"status"`
+	Units          []provision.Unit        `json:"units"`
+	NodeContainers []TopologyNodeContainer `json:"nodeContainers"`
+}
+
+type TopologyNodeContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
 }
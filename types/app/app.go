@@ -12,3 +12,11 @@ type AppRouter struct {
 	Status       string            `json:"status,omitempty" bson:"-"`
 	StatusDetail string            `json:"status-detail,omitempty" bson:"-"`
 }
+
+// Ownership holds information about the people responsible for an app, used
+// to speed up incident response.
+type Ownership struct {
+	OnCall        string `json:"oncall,omitempty"`
+	RunbookURL    string `json:"runbookURL,omitempty"`
+	RepositoryURL string `json:"repositoryURL,omitempty"`
+}
@@ -11,16 +11,40 @@ import (
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 )
 
+// Platform is the base image used to build apps. Deprecated platforms still
+// accept new apps and deploys, but users are warned to migrate their apps.
+// Disabled platforms can't be used by new apps.
 type Platform struct {
-	Name     string
-	Disabled bool
+	Name       string
+	Disabled   bool
+	Deprecated bool
 }
 
+// PlatformApp is an app using a platform, as listed in the platform
+// deprecation report.
+type PlatformApp struct {
+	Name      string `json:"name"`
+	TeamOwner string `json:"teamOwner"`
+	Pool      string `json:"pool"`
+}
+
+// DeprecatedPlatformApps lists the apps still using a deprecated platform.
+type DeprecatedPlatformApps struct {
+	Platform string        `json:"platform"`
+	Apps     []PlatformApp `json:"apps"`
+}
+
+// PlatformOptions holds the options used to add or update a platform. The
+// Dockerfile is either the Input or the URL in the dockerfile arg. BuildArgs
+// are passed to the Dockerfile and Proxy, when set, is exposed to the build
+// through the standard proxy variables.
 type PlatformOptions struct {
-	Name   string
-	Args   map[string]string
-	Input  io.Reader
-	Output io.Writer
+	Name      string
+	Args      map[string]string
+	BuildArgs map[string]string
+	Proxy     string
+	Input     io.Reader
+	Output    io.Writer
 }
 
 type PlatformService interface {
@@ -0,0 +1,37 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// Token is a session or application token, as kept by token storages. An
+// Expires of zero means the token never expires. Client and IP are the user
+// agent and address of the request that created the token.
+type Token struct {
+	Token     string
+	Creation  time.Time
+	Expires   time.Duration
+	UserEmail string
+	AppName   string
+	Client    string
+	IP        string
+	LastUse   time.Time
+}
+
+// TokenStorage keeps the tokens used to authenticate API requests. Storages
+// are not required to return expired tokens.
+type TokenStorage interface {
+	Insert(Token) error
+	FindByToken(string) (*Token, error)
+	FindByUserEmail(string) ([]Token, error)
+	UpdateLastUse(string, time.Time) error
+	Delete(Token) error
+	DeleteByUserEmail(string) error
+}
+
+var ErrTokenNotFound = errors.New("token not found")
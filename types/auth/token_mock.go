@@ -0,0 +1,43 @@
+// Copyright 2018 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import "time"
+
+var _ TokenStorage = &MockTokenStorage{}
+
+// MockTokenStorage implements TokenStorage interface
+type MockTokenStorage struct {
+	OnInsert            func(Token) error
+	OnFindByToken       func(string) (*Token, error)
+	OnFindByUserEmail   func(string) ([]Token, error)
+	OnUpdateLastUse     func(string, time.Time) error
+	OnDelete            func(Token) error
+	OnDeleteByUserEmail func(string) error
+}
+
+func (m *MockTokenStorage) Insert(t Token) error {
+	return m.OnInsert(t)
+}
+
+func (m *MockTokenStorage) FindByToken(token string) (*Token, error) {
+	return m.OnFindByToken(token)
+}
+
+func (m *MockTokenStorage) FindByUserEmail(email string) ([]Token, error) {
+	return m.OnFindByUserEmail(email)
+}
+
+func (m *MockTokenStorage) UpdateLastUse(token string, lastUse time.Time) error {
+	return m.OnUpdateLastUse(token, lastUse)
+}
+
+func (m *MockTokenStorage) Delete(t Token) error {
+	return m.OnDelete(t)
+}
+
+func (m *MockTokenStorage) DeleteByUserEmail(email string) error {
+	return m.OnDeleteByUserEmail(email)
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/tsuru/tsuru/quota"
 )
@@ -18,6 +19,11 @@ type User struct {
 	Password string
 	APIKey   string
 	Roles    []RoleInstance
+
+	PasswordChangedAt time.Time
+	PasswordHistory   []string
+
+	Disabled bool
 }
 
 type RoleInstance struct {
@@ -8,8 +8,9 @@ package validation
 import "regexp"
 
 var (
-	emailRegexp = regexp.MustCompile(`^([^@\s]+)@((?:[-a-z0-9]+\.)+[a-z]{2,})$`)
-	nameRegexp  = regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}$`)
+	emailRegexp   = regexp.MustCompile(`^([^@\s]+)@((?:[-a-z0-9]+\.)+[a-z]{2,})$`)
+	nameRegexp    = regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}$`)
+	envNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
 func ValidateEmail(email string) bool {
@@ -40,3 +41,10 @@ func ValidateLength(value string, min, max int) bool {
 func ValidateName(name string) bool {
 	return nameRegexp.MatchString(name)
 }
+
+// ValidateEnvName checks whether the given name is a valid environment
+// variable name, containing only letters, numbers or underscores and not
+// starting with a number.
+func ValidateEnvName(name string) bool {
+	return envNameRegexp.MatchString(name)
+}
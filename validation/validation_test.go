@@ -78,3 +78,22 @@ func (s *S) TestValidateName(c *check.C) {
 		c.Assert(ValidateName(d.input), check.Equals, d.expected)
 	}
 }
+
+func (s *S) TestValidateEnvName(c *check.C) {
+	var data = []struct {
+		input    string
+		expected bool
+	}{
+		{"DATABASE_URL", true},
+		{"_private", true},
+		{"lower_case1", true},
+		{"1VAR", false},
+		{"MY-VAR", false},
+		{"MY.VAR", false},
+		{"$where", false},
+		{"", false},
+	}
+	for _, d := range data {
+		c.Check(ValidateEnvName(d.input), check.Equals, d.expected, check.Commentf("input: %q", d.input))
+	}
+}